@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/api"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/diagnostics"
+)
+
+var (
+	bugReportOutput string
+	bugReportAddr   string
+)
+
+func init() {
+	bugReportCmd := &cobra.Command{
+		Use:   "bugreport",
+		Short: "Write a sanitized support bundle to attach to a GitHub issue",
+		Long: `Write a tarball with what is needed to look into a problem:
+
+  - version, platform and kubectl and docker versions
+  - the merged configuration, with credentials and query values removed from links
+  - the end of --log-file and of the daemon's log, which holds its status events
+  - status and control actions of a running kportforward, if one can be reached
+  - the most recent crash bundle
+
+URL credentials, query values and bearer tokens are redacted from logs, but please
+look through the archive before attaching it.
+
+Examples:
+  kportforward bugreport
+  kportforward bugreport --log-file ./kportforward.log --profile staging
+  kportforward bugreport --addr localhost:6062 -o /tmp/report.tar.gz`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Without --addr, the daemon is included when it is running
+			if !cmd.Flags().Changed("addr") {
+				if addr, ok := daemonRunning(); ok {
+					bugReportAddr = addr
+				}
+			}
+			return runBugReport()
+		},
+	}
+
+	bugReportCmd.Flags().StringVarP(&bugReportOutput, "output", "o", "", "Archive to write (default: kportforward-bugreport-<time>.tar.gz)")
+	bugReportCmd.Flags().StringVar(&bugReportAddr, "addr", "", "Debug or control API address of a running kportforward to include")
+	bugReportCmd.Flags().StringVar(&logFile, "log-file", "", "Log file of kportforward to include")
+	bugReportCmd.Flags().StringArrayVar(&profiles, "profile", nil, "Include the configuration of this profile (repeatable)")
+	bugReportCmd.Flags().StringVar(&apiToken, "api-token", "", "Control API token (default: $"+api.TokenEnvVar+" or the token in the config directory)")
+
+	rootCmd.AddCommand(bugReportCmd)
+}
+
+// runBugReport collects the report and writes the archive
+func runBugReport() error {
+	output := bugReportOutput
+	if output == "" {
+		output = fmt.Sprintf("kportforward-bugreport-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+
+	report := diagnostics.Report{Version: version}
+
+	// A config that fails to load is often what the report is about
+	config.SetRemoteConfigURL(configURL)
+	loadConfig := config.LoadConfig
+	if len(profiles) > 0 {
+		loadConfig = func() (*config.Config, error) { return config.LoadProfiles(profiles) }
+	}
+	if cfg, err := loadConfig(); err == nil {
+		report.Config = cfg
+	} else {
+		report.Problems = append(report.Problems, fmt.Sprintf("config: %v", err))
+	}
+
+	if logFile != "" {
+		report.LogFiles = append(report.LogFiles, logFile)
+	}
+	if socket, err := api.DefaultSocketPath(); err == nil {
+		daemonLog := filepath.Join(filepath.Dir(socket), "daemon.log")
+		if _, err := os.Stat(daemonLog); err == nil {
+			report.LogFiles = append(report.LogFiles, daemonLog)
+		}
+	}
+	if dir, err := diagnostics.CrashDir(); err == nil {
+		report.CrashDir = dir
+	}
+
+	if bugReportAddr != "" {
+		if err := fetchBugReportState(&report); err != nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("running instance at %s: %v", bugReportAddr, err))
+		}
+	}
+
+	if err := diagnostics.WriteReport(context.Background(), output, report); err != nil {
+		return err
+	}
+	fmt.Printf("Bug report written to %s\n", output)
+	fmt.Println("Please look through it before attaching it to an issue.")
+	return nil
+}
+
+// fetchBugReportState adds the status and, from a control API, the audit trail of the
+// instance at bugReportAddr
+func fetchBugReportState(report *diagnostics.Report) error {
+	token, err := api.ResolveToken(apiToken)
+	if err != nil {
+		return fmt.Errorf("no API token: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	observer := api.NewObserver(bugReportAddr, token, time.Second)
+	if err := observer.Fetch(ctx); err != nil {
+		return err
+	}
+	report.Status = <-observer.GetStatusChannel()
+
+	// Only the control API keeps an audit trail, a debug endpoint has none
+	if audit, err := observer.Audit(ctx); err == nil {
+		report.Audit = audit
+	}
+	return nil
+}
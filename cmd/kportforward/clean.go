@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/housekeeping"
+)
+
+var (
+	cleanDryRun    bool
+	cleanRetention time.Duration
+)
+
+func init() {
+	cleanCmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove stale logs, caches and orphaned Swagger UI containers",
+		Long: `Remove artifacts kportforward leaves behind:
+
+  - gRPC UI log files older than the retention period
+  - Swagger UI containers of kportforward instances that are no longer running
+  - the remote config cache if it is older than 30 days
+  - rotated copies of --log-file, of the logs in --log-dir and heap snapshots older
+    than the retention period
+
+A running kportforward does the same once a day.
+
+Examples:
+  kportforward clean --dry-run
+  kportforward clean --retention 72h --log-file /var/log/kportforward.log`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runClean()
+		},
+	}
+
+	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "List what would be removed without removing it")
+	cleanCmd.Flags().DurationVar(&cleanRetention, "retention", housekeeping.DefaultRetention, "Remove logs and snapshots older than this")
+	cleanCmd.Flags().StringVar(&logFile, "log-file", "", "Log file whose rotated copies should be removed")
+	cleanCmd.Flags().StringVar(&logDir, "log-dir", "", "Log directory whose rotated logs should be removed")
+	cleanCmd.Flags().StringVar(&heapSnapshotDir, "heap-snapshot-dir", "", "Directory with heap snapshots to prune")
+
+	rootCmd.AddCommand(cleanCmd)
+}
+
+// runClean removes stale artifacts and prints what was removed
+func runClean() error {
+	report := housekeeping.Clean(housekeeping.Options{
+		Retention:       cleanRetention,
+		DryRun:          cleanDryRun,
+		LogFile:         logFile,
+		LogDir:          logDir,
+		HeapSnapshotDir: heapSnapshotDir,
+	})
+
+	verb := "Removed"
+	if cleanDryRun {
+		verb = "Would remove"
+	}
+	for _, file := range report.Files {
+		fmt.Printf("%s %s\n", verb, file)
+	}
+	for _, container := range report.Containers {
+		fmt.Printf("%s container %s\n", verb, container)
+	}
+	if len(report.Files) == 0 && len(report.Containers) == 0 {
+		fmt.Println("Nothing to clean up")
+	}
+
+	for _, err := range report.Errors {
+		fmt.Printf("Error: %v\n", err)
+	}
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d items could not be cleaned up", len(report.Errors))
+	}
+	return nil
+}
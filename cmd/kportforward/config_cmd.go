@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	importNamespace string
+	importYes       bool
+	importDryRun    bool
+
+	exportFormat string
+	exportHost   string
+
+	showEffective bool
+
+	initContext string
+)
+
+// configCmd groups commands that inspect or edit the user configuration
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage kportforward configuration",
+}
+
+func init() {
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import port-forwards from a local development setup",
+		Long: `Import port-forwards from a docker-compose or .env file.
+
+Each published port is offered as a port-forward; confirm or adjust the Kubernetes
+target for each one and the accepted entries are added to your user config.
+
+Examples:
+  kportforward config import compose docker-compose.yaml
+  kportforward config import dotenv .env --namespace staging`,
+	}
+	importCmd.PersistentFlags().StringVarP(&importNamespace, "namespace", "n", "default", "Namespace for imported services")
+	importCmd.PersistentFlags().BoolVarP(&importYes, "yes", "y", false, "Accept all suggestions without prompting")
+	importCmd.PersistentFlags().BoolVar(&importDryRun, "dry-run", false, "Print the entries instead of writing them")
+
+	importCmd.AddCommand(&cobra.Command{
+		Use:   "compose <file>",
+		Short: "Import published ports from a docker-compose file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImport(args[0], config.ParseComposePorts)
+		},
+	})
+	importCmd.AddCommand(&cobra.Command{
+		Use:   "dotenv <file>",
+		Short: "Import *_PORT and localhost URL variables from a .env file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImport(args[0], config.ParseDotenvPorts)
+		},
+	})
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print forwarded endpoints as environment variables",
+		Long: `Print HOST, PORT and URL variables for every enabled service so containerized
+local apps can reach forwarded services without manual port bookkeeping.
+
+Formats:
+  compose     environment and extra_hosts block for a docker-compose service
+  kubernetes  container env list
+  dotenv      KEY=value lines, usable with telepresence --env-file
+
+Examples:
+  kportforward config export --format compose
+  kportforward config export --format dotenv --host localhost > .env.forwards`,
+		RunE: runExport,
+	}
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", config.ExportCompose, "Output format: compose, kubernetes or dotenv")
+	exportCmd.Flags().StringVar(&exportHost, "host", "", "Host to use in the variables (default: "+config.DockerHostGateway+", localhost for dotenv)")
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the config files that are merged, or the merged result",
+		Long: `Show the configuration layers, lowest precedence first: the remote (or built-in)
+defaults, the user config and the project config ` + config.ProjectConfigFile + ` in the
+current directory. Settings in a layer override those of the layers before it; services,
+templates, groups, service types and port offsets replace those of the same name as a whole.
+
+With --effective the merged configuration is printed, with the layer each setting comes
+from as a comment.
+
+Examples:
+  kportforward config show
+  kportforward config show --effective`,
+		Args: cobra.NoArgs,
+		RunE: runShow,
+	}
+	showCmd.Flags().BoolVar(&showEffective, "effective", false, "Print the merged configuration and the origin of each setting")
+
+	validateCmd := &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Check a config file for errors",
+		Long: `Check a config file without starting any port-forwards: the YAML must parse and
+only use known settings, the file must merge with the built-in defaults into a valid
+configuration, services need a known type and a local port no other service uses, and
+services whose type starts Swagger UI should have a swaggerPath.
+
+Without a file the user config is checked. A file named ` + config.ProjectConfigFile + ` is
+checked as a project config. Problems are printed with their line numbers, and the command
+fails if any of them is an error rather than a warning.
+
+Examples:
+  kportforward config validate
+  kportforward config validate ./` + config.ProjectConfigFile,
+		Args: cobra.MaximumNArgs(1),
+		// An invalid config is not a usage error
+		SilenceUsage: true,
+		RunE:         runValidate,
+	}
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create a starter user config with commented examples",
+		Long: `Create the user config with a service template for a kubectl context and commented
+example services and settings to start from. An existing config is never overwritten.
+
+Examples:
+  kportforward config init
+  kportforward config init --context staging-eu`,
+		Args: cobra.NoArgs,
+		RunE: runInit,
+	}
+	initCmd.Flags().StringVar(&initContext, "context", "", "kubectl context of the service template (default: follow the current context)")
+
+	configCmd.AddCommand(importCmd)
+	configCmd.AddCommand(exportCmd)
+	configCmd.AddCommand(showCmd)
+	configCmd.AddCommand(validateCmd)
+	configCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// runImport parses the file, lets the user review each candidate and saves the result
+func runImport(path string, parse func([]byte) ([]config.ImportCandidate, error)) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	candidates, err := parse(data)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		fmt.Printf("No ports found in %s\n", path)
+		return nil
+	}
+
+	prompt := &importPrompt{in: bufio.NewReader(os.Stdin), out: os.Stdout, acceptDefaults: importYes}
+	services := make(map[string]config.Service)
+	for _, candidate := range candidates {
+		name, service, ok := prompt.review(candidate, importNamespace)
+		if ok {
+			services[name] = service
+		}
+	}
+
+	if len(services) == 0 {
+		fmt.Println("Nothing imported")
+		return nil
+	}
+
+	if importDryRun {
+		fmt.Println()
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(map[string]interface{}{"portForwards": services}); err != nil {
+			return fmt.Errorf("failed to encode services: %w", err)
+		}
+		return encoder.Close()
+	}
+
+	configPath, err := config.AddUserServices(services)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added %d services to %s\n", len(services), configPath)
+	return nil
+}
+
+// importPrompt asks the user to confirm and adjust each import candidate
+type importPrompt struct {
+	in             *bufio.Reader
+	out            io.Writer
+	acceptDefaults bool
+}
+
+// review turns a candidate into a service, or returns false if the user skips it
+func (p *importPrompt) review(candidate config.ImportCandidate, namespace string) (string, config.Service, bool) {
+	fmt.Fprintf(p.out, "\n%s: localhost:%d -> port %d (%s)\n",
+		candidate.Name, candidate.LocalPort, candidate.TargetPort, candidate.Type)
+
+	if answer := p.ask("Import", "Y/n"); strings.HasPrefix(strings.ToLower(answer), "n") {
+		return "", config.Service{}, false
+	}
+
+	name := p.ask("Name", candidate.Name)
+	service := config.Service{
+		Target:     p.ask("Target", "service/"+candidate.Name),
+		Namespace:  p.ask("Namespace", namespace),
+		TargetPort: p.askPort("Target port", candidate.TargetPort),
+		LocalPort:  p.askPort("Local port", candidate.LocalPort),
+		Type:       p.ask("Type (rest, rpc, web, other)", candidate.Type),
+	}
+	return name, service, true
+}
+
+// ask prints a question and returns the answer, or the default on empty input
+func (p *importPrompt) ask(question, defaultValue string) string {
+	if p.acceptDefaults {
+		return defaultValue
+	}
+
+	fmt.Fprintf(p.out, "  %s [%s]: ", question, defaultValue)
+	// A read error (e.g. closed stdin) leaves the answer empty and keeps the default
+	answer, _ := p.in.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return defaultValue
+	}
+	return answer
+}
+
+// askPort asks for a port number until a valid one (or the default) is given
+func (p *importPrompt) askPort(question string, defaultValue int) int {
+	for {
+		answer := p.ask(question, strconv.Itoa(defaultValue))
+		port, err := strconv.Atoi(answer)
+		if err == nil && port > 0 && port <= 65535 {
+			return port
+		}
+		fmt.Fprintf(p.out, "  %q is not a valid port\n", answer)
+	}
+}
+
+// runExport prints the endpoint variables for the merged configuration
+func runExport(cmd *cobra.Command, args []string) error {
+	config.SetRemoteConfigURL(configURL)
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	host := exportHost
+	if host == "" {
+		host = config.DockerHostGateway
+		if exportFormat == config.ExportDotenv {
+			host = "localhost"
+		}
+	}
+
+	out, err := config.FormatEndpointEnv(config.EndpointEnv(cfg, host), exportFormat)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// runShow prints the configuration layers or, with --effective, their merged result
+func runShow(cmd *cobra.Command, args []string) error {
+	config.SetRemoteConfigURL(configURL)
+	layers, err := config.ConfigLayers()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if showEffective {
+		out, err := config.EffectiveYAML(layers)
+		if err != nil {
+			return fmt.Errorf("failed to merge configuration: %w", err)
+		}
+		fmt.Print(string(out))
+		return nil
+	}
+
+	fmt.Println("Configuration layers, lowest precedence first:")
+	for _, layer := range layers {
+		state := "not found"
+		if layer.Data != nil {
+			state = fmt.Sprintf("%d bytes", len(layer.Data))
+		}
+		fmt.Printf("  %-8s  %s (%s)\n", layer.Name, layer.Source, state)
+	}
+	return nil
+}
+
+// runValidate prints the problems of a config file and fails if any is an error
+func runValidate(cmd *cobra.Command, args []string) error {
+	path := ""
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		userPath, err := config.UserConfigPath()
+		if err != nil {
+			return err
+		}
+		path = userPath
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	layer := config.LayerUser
+	if filepath.Base(path) == config.ProjectConfigFile {
+		layer = config.LayerProject
+	}
+
+	errors, warnings := 0, 0
+	for _, issue := range config.ValidateConfigFile(data, layer) {
+		severity := "error"
+		if issue.Warning {
+			severity = "warning"
+			warnings++
+		} else {
+			errors++
+		}
+		if issue.Line > 0 {
+			fmt.Printf("%s:%d: %s: %s\n", path, issue.Line, severity, issue.Message)
+		} else {
+			fmt.Printf("%s: %s: %s\n", path, severity, issue.Message)
+		}
+	}
+	if errors > 0 {
+		return fmt.Errorf("%d errors in %s", errors, path)
+	}
+	if warnings > 0 {
+		fmt.Printf("%s is valid, %d warnings\n", path, warnings)
+	} else {
+		fmt.Printf("%s is valid\n", path)
+	}
+	return nil
+}
+
+// runInit writes the starter user config
+func runInit(cmd *cobra.Command, args []string) error {
+	path, err := config.CreateStarterConfig(initContext)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created %s\n", path)
+	return nil
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/api"
+	"github.com/victorkazakov/kportforward/internal/ui"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// daemonStartTimeout is how long daemon start waits for the control socket to answer
+const daemonStartTimeout = 15 * time.Second
+
+func init() {
+	daemonCmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run kportforward in the background and control it from the command line",
+		Long: `Run kportforward detached from the terminal. The daemon writes JSON status events
+to daemon.log in the config directory and is controlled through a unix socket
+(kportforward.sock, readable only by the current user) next to it.
+
+Arguments after -- are passed to the daemon, e.g. profiles or UI integrations.
+
+Examples:
+  kportforward daemon start -- --profile staging --grpcui
+  kportforward daemon status
+  kportforward daemon restart api-gateway
+  kportforward daemon reload
+  kportforward daemon resume
+  kportforward daemon stop
+
+  # The daemon can also be watched with a TUI
+  kportforward attach --addr unix:$HOME/.config/kportforward/kportforward.sock`,
+	}
+
+	startCmd := &cobra.Command{
+		Use:   "start [-- kportforward flags]",
+		Short: "Start the daemon",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDaemonStart(args)
+		},
+	}
+
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the daemon and its port-forwards",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			observer, err := daemonClient()
+			if err != nil {
+				return err
+			}
+			if err := observer.Shutdown(); err != nil {
+				return err
+			}
+			fmt.Println("kportforward daemon stopping")
+			return nil
+		},
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of the daemon's services",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			observer, err := daemonClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := observer.Fetch(ctx); err != nil {
+				return err
+			}
+			status := <-observer.GetStatusChannel()
+
+			// Same lines as --output plain, for every service
+			ui.NewHeadless(os.Stdout, ui.OutputPlain).ReportStatus(status, time.Now())
+			fmt.Println(api.SummarizeHealth(status))
+			return nil
+		},
+	}
+
+	restartCmd := &cobra.Command{
+		Use:   "restart <service>",
+		Short: "Restart a service in the daemon",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			observer, err := daemonClient()
+			if err != nil {
+				return err
+			}
+			if err := observer.RestartService(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Restarting %s\n", args[0])
+			return nil
+		},
+	}
+
+	reloadCmd := &cobra.Command{
+		Use:   "reload",
+		Short: "Reload the configuration; only added, removed and changed services are restarted",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			observer, err := daemonClient()
+			if err != nil {
+				return err
+			}
+			if err := observer.ReloadConfig(); err != nil {
+				return err
+			}
+			fmt.Println("Configuration reloaded")
+			return nil
+		},
+	}
+
+	resumeCmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Restart the services the daemon stopped after idleTimeout without connections",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			observer, err := daemonClient()
+			if err != nil {
+				return err
+			}
+			if err := observer.Resume(); err != nil {
+				return err
+			}
+			fmt.Println("Resuming services")
+			return nil
+		},
+	}
+
+	daemonCmd.PersistentFlags().StringVar(&apiToken, "api-token", "", "Control API token (default: $"+api.TokenEnvVar+" or the token in the config directory)")
+	daemonCmd.AddCommand(startCmd, stopCmd, statusCmd, restartCmd, reloadCmd, resumeCmd)
+	rootCmd.AddCommand(daemonCmd)
+}
+
+// runDaemonStart starts a detached kportforward serving the control API on the daemon
+// socket and waits until it answers
+func runDaemonStart(args []string) error {
+	socket, err := api.DefaultSocketPath()
+	if err != nil {
+		return err
+	}
+	if observer, err := daemonClient(); err == nil && observer.Fetch(context.Background()) == nil {
+		return fmt.Errorf("kportforward daemon is already running (%s)", socket)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate kportforward executable: %w", err)
+	}
+
+	logPath := filepath.Join(filepath.Dir(socket), "daemon.log")
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	output, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open daemon log: %w", err)
+	}
+	defer output.Close()
+
+	daemonArgs := append([]string{"--output", ui.OutputJSON, "--api-addr", api.SocketAddr(socket)}, args...)
+	if configFile != "" {
+		// The daemon runs in the same directory, but an absolute path reads better in ps
+		path, err := filepath.Abs(configFile)
+		if err != nil {
+			return fmt.Errorf("failed to resolve config file: %w", err)
+		}
+		daemonArgs = append(daemonArgs, "--config", path)
+	}
+	daemon := exec.Command(executable, daemonArgs...)
+	if apiToken != "" {
+		// In the environment rather than argv, where every local user could read it with ps
+		daemon.Env = append(os.Environ(), api.TokenEnvVar+"="+apiToken)
+	}
+	daemon.Stdout = output
+	daemon.Stderr = output
+	utils.DetachProcess(daemon)
+	if err := daemon.Start(); err != nil {
+		return fmt.Errorf("failed to start daemon: %w", err)
+	}
+	pid := daemon.Process.Pid
+
+	// Reap the daemon if it exits while we are still waiting for it
+	exited := make(chan error, 1)
+	go func() { exited <- daemon.Wait() }()
+
+	observer, err := daemonClient()
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(daemonStartTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-exited:
+			return fmt.Errorf("daemon exited during startup (%v), see %s", err, logPath)
+		case <-time.After(250 * time.Millisecond):
+		}
+		if observer.Fetch(context.Background()) == nil {
+			fmt.Printf("kportforward daemon started (PID %d), logging to %s\n", pid, logPath)
+			return nil
+		}
+	}
+	return fmt.Errorf("daemon (PID %d) did not answer on %s within %s, see %s", pid, socket, daemonStartTimeout, logPath)
+}
+
+// daemonClient returns a client for the daemon's control socket that can send actions
+func daemonClient() (*api.Observer, error) {
+	socket, err := api.DefaultSocketPath()
+	if err != nil {
+		return nil, err
+	}
+	token, err := api.ResolveToken(apiToken)
+	if err != nil {
+		return nil, fmt.Errorf("no API token: %w", err)
+	}
+
+	observer := api.NewObserver(api.SocketAddr(socket), token, time.Second)
+	observer.EnableControl(api.CurrentUser())
+	return observer, nil
+}
+
+// daemonRunning reports whether a daemon socket exists, without contacting it
+func daemonRunning() (string, bool) {
+	socket, err := api.DefaultSocketPath()
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(socket); err != nil {
+		return "", false
+	}
+	return api.SocketAddr(socket), true
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// discoverTimeout limits listing the services of all namespaces
+const discoverTimeout = 30 * time.Second
+
+var (
+	discoverNamespaces []string
+	discoverSelector   string
+	discoverContext    string
+	discoverDryRun     bool
+)
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Add port-forwards for annotated services in the cluster",
+	Long: `List the Services annotated with ` + config.AnnotationEnable + `: "true" (or those
+matching --selector) and add a port-forward for each to your user config. Services
+already in the config are left alone, so discover can be run again as teams annotate
+more services.
+
+By default the namespaces of the configured services are searched.
+
+Optional annotations:
+  ` + config.AnnotationName + `        entry name (default: the service's name)
+  ` + config.AnnotationPort + `        port to forward, by number or name (default: every TCP port)
+  ` + config.AnnotationLocalPort + `  local port (default: the service port, plus 8000 below 1024)
+  ` + config.AnnotationType + `        rest, rpc, web or other (default: guessed)
+
+Examples:
+  kportforward discover
+  kportforward discover -n staging -n platform --dry-run
+  kportforward discover -n debug -l app.kubernetes.io/part-of=checkout`,
+	Args: cobra.NoArgs,
+	RunE: runDiscover,
+}
+
+func init() {
+	discoverCmd.Flags().StringArrayVarP(&discoverNamespaces, "namespace", "n", nil, "Namespace to search (repeatable; default: the namespaces of the configured services)")
+	discoverCmd.Flags().StringVarP(&discoverSelector, "selector", "l", "", "Use the services matching this label selector instead of the annotated ones")
+	discoverCmd.Flags().StringVar(&discoverContext, "context", "", "kubectl context to search (default: the current context)")
+	discoverCmd.Flags().BoolVar(&discoverDryRun, "dry-run", false, "Print the entries instead of writing them")
+	rootCmd.AddCommand(discoverCmd)
+}
+
+// runDiscover lists the cluster's services and adds entries for the new ones
+func runDiscover(cmd *cobra.Command, args []string) error {
+	config.SetRemoteConfigURL(configURL)
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	namespaces := discoverNamespaces
+	if len(namespaces) == 0 {
+		namespaces = config.DiscoveryNamespaces(cfg.PortForwards)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), discoverTimeout)
+	defer cancel()
+	var services []utils.ClusterService
+	for _, namespace := range namespaces {
+		found, err := utils.ListServices(ctx, discoverContext, namespace, discoverSelector)
+		if err != nil {
+			return fmt.Errorf("failed to list services in %s: %w", namespace, err)
+		}
+		services = append(services, found...)
+	}
+
+	result := config.DiscoverServices(services, cfg.PortForwards, discoverSelector != "")
+	for _, name := range result.Existing {
+		fmt.Printf("Already configured: %s\n", name)
+	}
+	for _, skipped := range result.Skipped {
+		fmt.Printf("Skipped %s\n", skipped)
+	}
+	if len(result.Services) == 0 {
+		fmt.Printf("No new services found in %v\n", namespaces)
+		return nil
+	}
+
+	names := make([]string, 0, len(result.Services))
+	for name := range result.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		service := result.Services[name]
+		fmt.Printf("Found %s: localhost:%d -> %s/%s:%d (%s)\n",
+			name, service.LocalPort, service.Namespace, service.Target, service.TargetPort, service.Type)
+	}
+
+	if discoverDryRun {
+		fmt.Println()
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(map[string]interface{}{"portForwards": result.Services}); err != nil {
+			return fmt.Errorf("failed to encode services: %w", err)
+		}
+		return encoder.Close()
+	}
+
+	configPath, err := config.AddUserServices(result.Services)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added %d services to %s\n", len(result.Services), configPath)
+	return nil
+}
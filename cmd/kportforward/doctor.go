@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/diagnostics"
+)
+
+var doctorTimeout time.Duration
+
+func init() {
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that everything kportforward needs is in place",
+		Long: `Check the dependencies of the configured services before starting them and print
+a pass/fail line for each:
+
+  - kubectl is installed, and its version
+  - every kubectl context the services use is reachable
+  - you may get pods and port-forward in every configured namespace
+  - Docker is running, if any service uses Swagger UI (--swaggerui)
+  - grpcui is installed, if any service uses gRPC UI (--grpcui)
+  - the local ports are free
+
+Docker, grpcui and ports taken from services without strictPort are reported as
+warnings. doctor exits non-zero if any other check failed.
+
+Examples:
+  kportforward doctor
+  kportforward doctor --profile team-a`,
+		Args: cobra.NoArgs,
+		// A failed check is not a usage error
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor()
+		},
+	}
+
+	doctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", 30*time.Second, "Give up on checks that have not finished within this time")
+	doctorCmd.Flags().StringArrayVar(&profiles, "profile", nil, "Check the services of this profile (repeatable)")
+
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// runDoctor runs the checks and prints a line per check
+func runDoctor() error {
+	config.SetRemoteConfigURL(configURL)
+	loadConfig := config.LoadConfig
+	if len(profiles) > 0 {
+		loadConfig = func() (*config.Config, error) { return config.LoadProfiles(profiles) }
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+
+	fmt.Printf("Checking %d services\n", len(cfg.PortForwards))
+	failed, warnings := 0, 0
+	for _, check := range diagnostics.RunDoctor(ctx, cfg) {
+		switch {
+		case check.Skipped != "":
+			fmt.Printf("  %-36s skipped  %s\n", check.Name, check.Skipped)
+		case check.Err != nil && check.Optional:
+			warnings++
+			fmt.Printf("  %-36s warning  %v\n", check.Name, check.Err)
+		case check.Err != nil:
+			failed++
+			fmt.Printf("  %-36s FAILED   %v\n", check.Name, check.Err)
+		default:
+			fmt.Printf("  %-36s ok       %s\n", check.Name, check.Detail)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+	if warnings > 0 {
+		fmt.Printf("All required checks passed, %d warnings\n", warnings)
+	} else {
+		fmt.Println("All checks passed")
+	}
+	return nil
+}
@@ -12,12 +12,19 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/api"
 	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/diagnostics"
+	"github.com/victorkazakov/kportforward/internal/housekeeping"
 	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/proxy"
 	"github.com/victorkazakov/kportforward/internal/ui"
 	"github.com/victorkazakov/kportforward/internal/ui_handlers"
 	"github.com/victorkazakov/kportforward/internal/updater"
@@ -33,11 +40,46 @@ var (
 	enableGRPCUI         bool
 	enableSwaggerUI      bool
 	logFile              string
+	logDir               string
+	logMaxSize           int
+	logMaxAge            time.Duration
+	logFormat            string
 	configURL            string
+	configCAFile         string
+	configPins           []string
+	configTTL            time.Duration
+	insecureRemote       bool
+	configPublicKey      string
+	configFile           string
 	pprofAddr            string
 	memStatsInterval     time.Duration
 	heapSnapshotDir      string
 	heapSnapshotInterval time.Duration
+	debugAddr            string
+	apiToken             string
+	readOnly             bool
+	apiAddr              string
+	apiPort              int
+	portOffset           int
+	outputFormat         string
+	accessible           bool
+	profiles             []string
+	groups               []string
+	onlyServices         []string
+	excludeServices      []string
+	watchConfig          bool
+	heartbeatFile        string
+	downtimeFile         string
+	statusBuffer         int
+	proxyAddr            string
+	insecureProxy        bool
+	mouse                bool
+	theme                string
+	updateChannel        string
+	noUpdateCheck        bool
+	refreshRate          time.Duration
+	chaosInterval        time.Duration
+	chaosSeed            int64
 
 	// Global root command
 	rootCmd = &cobra.Command{
@@ -52,6 +94,9 @@ Examples:
 
   # With UI integrations
   kportforward --grpcui --swaggerui
+
+  # Only the services of the backend group, without one of them
+  kportforward --group backend --exclude search
   
   # Write logs to file
   kportforward --log-file ./kportforward.log
@@ -59,8 +104,20 @@ Examples:
   # Production setup with logging
   kportforward --grpcui --swaggerui --log-file /var/log/kportforward.log
 
+  # A log file per service, rotated daily or at 10 MB
+  kportforward --log-dir ~/.kportforward/logs --log-max-age 24h
+
+  # Use another config file
+  kportforward --config ~/work/kportforward.yaml
+
+  # Reach any cluster service by name, e.g. curl -x socks5h://localhost:1080 http://api.team:8080
+  kportforward --proxy localhost:1080
+
   # Performance profiling
   kportforward profile --cpuprofile=cpu.prof --duration=30s`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			config.SetConfigPath(configFile)
+		},
 		Run: runPortForward,
 	}
 )
@@ -71,11 +128,50 @@ func main() {
 	rootCmd.Flags().BoolVar(&enableGRPCUI, "grpcui", false, "Enable gRPC UI for RPC services")
 	rootCmd.Flags().BoolVar(&enableSwaggerUI, "swaggerui", false, "Enable Swagger UI for REST services")
 	rootCmd.Flags().StringVar(&logFile, "log-file", "", "Write logs to file (default: logs are discarded to avoid interfering with TUI)")
+	rootCmd.Flags().StringVar(&logDir, "log-dir", "", "Write kportforward.log and one log per service to this directory")
+	rootCmd.Flags().IntVar(&logMaxSize, "log-max-size", utils.DefaultLogMaxSize>>20, "Rotate log files at this size in MB")
+	rootCmd.Flags().DurationVar(&logMaxAge, "log-max-age", 0, "Rotate log files after this long (0 to rotate by size only)")
+	rootCmd.Flags().StringVar(&logFormat, "log-format", utils.LogFormatText, "Log format: text, or json with service, event and error_class fields for log shippers")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file to use instead of the default one (default: $"+config.ConfigEnvVar+" or config.yaml in the config directory)")
 	rootCmd.Flags().StringVar(&configURL, "config-url", config.DefaultRemoteConfigURL, "URL to fetch default config from (set to \"\" to use embedded defaults only)")
+	rootCmd.Flags().StringVar(&configCAFile, "config-ca-file", "", "PEM bundle to trust for --config-url, e.g. an internal CA (default: $"+config.RemoteCAFileEnvVar+")")
+	rootCmd.Flags().StringSliceVar(&configPins, "config-pin", nil, "Require the --config-url server to present one of these public keys, as sha256/<base64> (comma-separated; default: $"+config.RemotePinEnvVar+")")
+	rootCmd.Flags().DurationVar(&configTTL, "config-ttl", config.DefaultRemoteConfigTTL, "How long the --config-url config is used before checking it for changes, also while running (0 checks on every load only)")
+	rootCmd.Flags().BoolVar(&insecureRemote, "insecure-remote-config", false, "Use the --config-url config without checking it against its published .sha256 checksum and signature")
+	rootCmd.Flags().StringVar(&configPublicKey, "config-public-key", "", "Require the --config-url config to carry a .sig signature by this base64 Ed25519 public key (default: $"+config.RemotePublicKeyEnvVar+")")
 	rootCmd.Flags().StringVar(&pprofAddr, "pprof", "", "Start pprof HTTP server (e.g. localhost:6060)")
 	rootCmd.Flags().DurationVar(&memStatsInterval, "mem-stats-interval", 0, "Log memory stats every interval (0 to disable)")
 	rootCmd.Flags().StringVar(&heapSnapshotDir, "heap-snapshot-dir", "", "Directory to write periodic heap snapshots")
 	rootCmd.Flags().DurationVar(&heapSnapshotInterval, "heap-snapshot-interval", 0, "Interval for heap snapshots (0 to disable)")
+	rootCmd.Flags().StringVar(&debugAddr, "debug-addr", "", "Serve service status and runtime stats at /debug/vars (e.g. localhost:6061)")
+	rootCmd.Flags().StringVar(&apiAddr, "api-addr", "", "Serve the control API for attached clients, plus the debug endpoint (e.g. localhost:6062 or unix:/path/to.sock)")
+	rootCmd.Flags().IntVar(&apiPort, "api-port", 0, "Serve the control API on localhost at this port (shorthand for --api-addr localhost:<port>)")
+	rootCmd.Flags().BoolVar(&readOnly, "read-only", false, "Observer TUI: view status without being able to change services")
+	rootCmd.Flags().StringVar(&apiToken, "api-token", "", "Control API token (default: $"+api.TokenEnvVar+" or generated in the config directory)")
+	rootCmd.Flags().StringArrayVar(&profiles, "profile", nil, "Run the services of this profile (repeatable; see profiles/ in the config directory)")
+	rootCmd.Flags().StringArrayVar(&groups, "group", nil, "Run only the services of this group from the config's groups (repeatable)")
+	rootCmd.Flags().StringSliceVar(&onlyServices, "only", nil, "Run only these services, in addition to those of --group (comma-separated)")
+	rootCmd.Flags().StringSliceVar(&excludeServices, "exclude", nil, "Do not run these services (comma-separated)")
+	rootCmd.Flags().StringVar(&outputFormat, "output", ui.OutputTUI, "Output: tui, or plain/json status lines on stdout without the TUI (for CI, tmux and service managers)")
+	rootCmd.Flags().BoolVar(&accessible, "accessible", false, "Screen reader friendly output: status changes as plain sentences on stdout instead of the TUI")
+	rootCmd.Flags().BoolVar(&watchConfig, "watch-config", true, "Apply changes to the config and profile files without restarting")
+	rootCmd.Flags().IntVar(&portOffset, "port-offset", 0, "Add this to every local port, overriding portOffsets in the config (e.g. 1000 for a second instance)")
+	rootCmd.PersistentFlags().StringVar(&theme, "theme", "", "TUI colors: dark, light or no-color (default: uiOptions.theme, or dark)")
+	rootCmd.PersistentFlags().DurationVar(&refreshRate, "refresh-rate", 0, "How often the TUI redraws, e.g. 1s over slow SSH links (default: uiOptions.refreshRate, or 250ms)")
+	rootCmd.Flags().StringVar(&updateChannel, "update-channel", "", "Releases to check for updates: stable, or beta to include pre-releases (default: updates.channel, or stable)")
+	rootCmd.Flags().BoolVar(&noUpdateCheck, "no-update-check", false, "Do not check GitHub for updates, e.g. in air-gapped networks (default: updates.disabled)")
+	rootCmd.Flags().BoolVar(&mouse, "mouse", false, "Click to select services and open URLs in the TUI (the terminal's text selection then needs Shift or Option)")
+	rootCmd.Flags().StringVar(&proxyAddr, "proxy", "", "Serve a SOCKS5 and HTTP proxy to any cluster service by DNS name on this address (e.g. localhost:1080)")
+	rootCmd.Flags().BoolVar(&insecureProxy, "insecure-proxy", false, "Allow --proxy on an address reachable from the network, giving anyone there unauthenticated access to the cluster")
+	rootCmd.Flags().StringVar(&downtimeFile, "downtime-file", "", "Keep per-service downtime statistics in this JSON file across sessions (default: this session only)")
+	rootCmd.Flags().IntVar(&statusBuffer, "status-buffer", portforward.DefaultStatusBuffer, "Status updates buffered for the TUI or --output before the oldest are dropped")
+	rootCmd.Flags().StringVar(&heartbeatFile, "heartbeat-file", "", "Touch this file every monitoring interval for watchdogs (a .json file gets a status summary instead)")
+
+	// Failure injection for exercising recovery; intentionally undocumented in --help
+	rootCmd.Flags().DurationVar(&chaosInterval, "chaos", 0, "Inject random failures about every interval")
+	rootCmd.Flags().Int64Var(&chaosSeed, "chaos-seed", 0, "Seed for --chaos (default: random)")
+	_ = rootCmd.Flags().MarkHidden("chaos")
+	_ = rootCmd.Flags().MarkHidden("chaos-seed")
 
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "version",
@@ -93,16 +189,182 @@ func main() {
 	}
 }
 
+// localControl routes actions from the local TUI through the controller so they are
+// serialized with, and audited alongside, actions from attached clients
+type localControl struct {
+	*portforward.Manager
+	controller *api.Controller
+	user       string
+	profiles   *profileSelection
+	proxy      *proxy.Server
+	updates    *updater.Manager
+
+	// Set once an update was installed, to restart into it after shutting down
+	restart atomic.Bool
+}
+
+// RestartService restarts a service through the controller
+func (l *localControl) RestartService(name string) error {
+	return l.controller.Restart(l.user, name)
+}
+
+// SetServiceDisabled disables or enables a service through the controller
+func (l *localControl) SetServiceDisabled(name string, disabled bool) error {
+	return l.controller.SetDisabled(l.user, name, disabled)
+}
+
+// ResumeFromIdle resumes services through the controller
+func (l *localControl) ResumeFromIdle() {
+	l.controller.Resume(l.user)
+}
+
+// Profiles lists the profiles the TUI can switch to
+func (l *localControl) Profiles() ([]string, error) {
+	return config.ListProfiles()
+}
+
+// ActiveProfiles returns the running profiles
+func (l *localControl) ActiveProfiles() []string {
+	return l.profiles.Get()
+}
+
+// SwitchProfiles switches the running profiles through the controller
+func (l *localControl) SwitchProfiles(names []string) error {
+	return l.controller.SwitchProfiles(l.user, names)
+}
+
+// ApplyUpdate installs the available update and restarts into it once the TUI quits
+func (l *localControl) ApplyUpdate() error {
+	if err := l.updates.ApplyUpdate(l.updates.GetLastUpdateInfo()); err != nil {
+		return err
+	}
+	l.restart.Store(true)
+	return nil
+}
+
+// IssueReport describes a failing service in a markdown issue for the team owning it
+func (l *localControl) IssueReport(name string) (string, error) {
+	service, ok := l.ServiceConfig(name)
+	if !ok {
+		return "", fmt.Errorf("service %s not found", name)
+	}
+	kubeContext := service.Kubectl.Context
+	if kubeContext == "" {
+		kubeContext = l.GetKubernetesContext()
+	}
+	report := diagnostics.IssueMarkdown(context.Background(), diagnostics.Issue{
+		Version:  version,
+		Service:  name,
+		Config:   service,
+		Status:   l.GetLastStatus()[name],
+		Context:  kubeContext,
+		Timeline: l.Timeline(name),
+	})
+	return string(report), nil
+}
+
+// ProxyStatus returns the status of the cluster proxy, if --proxy started one
+func (l *localControl) ProxyStatus() (proxy.Status, bool) {
+	if l.proxy == nil {
+		return proxy.Status{}, false
+	}
+	return l.proxy.Status(), true
+}
+
+// profileSelection holds the profiles whose services run, which the TUI can switch
+type profileSelection struct {
+	mutex sync.Mutex
+	names []string
+}
+
+// Get returns the selected profiles
+func (p *profileSelection) Get() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.names
+}
+
+// Set selects other profiles
+func (p *profileSelection) Set(names []string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.names = names
+}
+
+// telemetry adds the update checker's state to what the debug endpoint and metrics report
+type telemetry struct {
+	*portforward.Manager
+	updates *updater.Manager
+}
+
+// UpdateState returns the outcome of the latest update check
+func (t *telemetry) UpdateState() updater.State {
+	return t.updates.State()
+}
+
+// logRotation returns the rotation options from the command line
+func logRotation() utils.RotationOptions {
+	return utils.RotationOptions{
+		MaxSize: int64(logMaxSize) << 20,
+		MaxAge:  logMaxAge,
+	}
+}
+
 // initializeLogger creates a logger with the appropriate output destination
-func initializeLogger(logFile string) (*utils.Logger, error) {
+// applyTheme sets the TUI colors from --theme, or from uiOptions.theme without it
+func applyTheme(options config.UIConfig) {
+	if theme != "" {
+		ui.SetTheme(theme)
+		return
+	}
+	ui.SetTheme(options.Theme)
+}
+
+// displayOptions returns uiOptions with --refresh-rate applied
+func displayOptions(options config.UIConfig) config.UIConfig {
+	if refreshRate > 0 {
+		options.RefreshRate = refreshRate
+	}
+	return options
+}
+
+// configureUpdates applies the updates section of the config and the update flags,
+// which take precedence over it
+func configureUpdates(updates *updater.Manager, options config.UpdatesConfig) error {
+	channel := options.Channel
+	if updateChannel != "" {
+		channel = updateChannel
+	}
+	if channel == "" {
+		channel = config.UpdateChannelStable
+	}
+	if err := updates.SetChannel(channel); err != nil {
+		return err
+	}
+	if options.CheckInterval > 0 {
+		if err := updates.SetCheckInterval(options.CheckInterval); err != nil {
+			return err
+		}
+	}
+	if noUpdateCheck || options.Disabled {
+		updates.Disable()
+	}
+	return nil
+}
+
+func initializeLogger(logFile string, headless bool) (*utils.Logger, error) {
 	if logFile == "" {
+		// Without the TUI, stdout carries status lines and logs can go to stderr
+		if headless {
+			return utils.NewLoggerWithOutput(utils.LevelInfo, os.Stderr), nil
+		}
 		// When no log file is specified, discard logs to avoid interfering with TUI
 		// The TUI provides visual status updates, so logging to stdout would corrupt the display
 		return utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard), nil
 	}
 
 	// Create logger with file output
-	logger, err := utils.NewLoggerWithFile(utils.LevelInfo, logFile)
+	logger, err := utils.NewLoggerWithRotation(utils.LevelInfo, logFile, logRotation())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file logger: %w", err)
 	}
@@ -113,19 +375,110 @@ func initializeLogger(logFile string) (*utils.Logger, error) {
 func runPortForward(cmd *cobra.Command, args []string) {
 	// Set remote config URL (may be overridden by --config-url flag)
 	config.SetRemoteConfigURL(configURL)
+	config.SetRemoteTLS(configCAFile, configPins)
+	config.SetRemoteVerification(insecureRemote, configPublicKey)
+	if configTTL < 0 {
+		log.Fatalf("--config-ttl cannot be negative")
+	}
+	config.SetRemoteConfigTTL(configTTL)
 
-	// Load configuration
-	cfg, err := config.LoadConfig()
+	if accessible {
+		if outputFormat != ui.OutputTUI {
+			log.Fatalf("--accessible cannot be combined with --output")
+		}
+		outputFormat = ui.OutputAccessible
+	}
+	headless := outputFormat != ui.OutputTUI
+	if headless && outputFormat != ui.OutputPlain && outputFormat != ui.OutputJSON && outputFormat != ui.OutputAccessible {
+		log.Fatalf("Unknown --output %q (expected tui, plain or json)", outputFormat)
+	}
+	if !config.IsValidTheme(theme) {
+		log.Fatalf("Unknown --theme %q (expected dark, light or no-color)", theme)
+	}
+	if refreshRate < 0 {
+		log.Fatalf("--refresh-rate cannot be negative")
+	}
+	if proxyAddr != "" {
+		if err := proxy.CheckAddress(proxyAddr, insecureProxy); err != nil {
+			log.Fatalf("--proxy: %v", err)
+		}
+	}
+	if !config.IsValidUpdateChannel(updateChannel) {
+		log.Fatalf("Unknown --update-channel %q (expected stable or beta)", updateChannel)
+	}
+	if statusBuffer < 1 {
+		log.Fatalf("--status-buffer must be at least 1")
+	}
+
+	if apiPort != 0 {
+		if apiAddr != "" {
+			log.Fatalf("--api-port and --api-addr cannot be combined")
+		}
+		apiAddr = fmt.Sprintf("localhost:%d", apiPort)
+	}
+
+	// Load configuration, replacing the services with those of the selected profiles
+	// and keeping only the selected groups and services
+	selection := config.Selection{Groups: groups, Only: onlyServices, Exclude: excludeServices}
+	selectedProfiles := &profileSelection{names: profiles}
+	// Without the TUI or a control API nothing could resume services stopped by the idle
+	// timeout, so it would end every port-forward for good
+	var ignoredIdleTimeout time.Duration
+	loadConfig := func() (*config.Config, error) {
+		load := config.LoadConfig
+		if names := selectedProfiles.Get(); len(names) > 0 {
+			load = func() (*config.Config, error) { return config.LoadProfiles(names) }
+		}
+		cfg, err := load()
+		if err != nil {
+			return nil, err
+		}
+		if err := config.Select(cfg, selection); err != nil {
+			return nil, err
+		}
+		if headless && apiAddr == "" && cfg.IdleTimeout > 0 {
+			ignoredIdleTimeout, cfg.IdleTimeout = cfg.IdleTimeout, 0
+		}
+		return cfg, nil
+	}
+	cfg, err := loadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize logger
-	logger, err := initializeLogger(logFile)
+	applyTheme(cfg.UIOptions)
+
+	// Without any configuration, guide new users instead of starting unknown defaults
+	if !headless && len(profiles) == 0 && config.IsFirstRun() {
+		cfg = runOnboarding(cfg, loadConfig)
+	}
+
+	// Initialize logger; with a log directory, the main log goes there unless --log-file is set
+	if logFile == "" && logDir != "" {
+		logFile = filepath.Join(logDir, "kportforward.log")
+	}
+	// Log lines on stderr would be read out between the sentences of --accessible
+	logger, err := initializeLogger(logFile, headless && outputFormat != ui.OutputAccessible)
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
+	if err := logger.SetFormat(logFormat); err != nil {
+		log.Fatalf("Invalid --log-format: %v", err)
+	}
+	var serviceLogs *utils.ServiceLogs
+	if logDir != "" {
+		if serviceLogs, err = utils.NewServiceLogs(logDir, logRotation(), logger); err != nil {
+			log.Fatalf("Failed to initialize service logs: %v", err)
+		}
+	}
 	logger.Info("Starting kportforward with %d services", len(cfg.PortForwards))
+	if warning := config.RemoteDefaultsWarning(); warning != "" {
+		logger.Warn("%s", warning)
+	}
+	logSkippedServices(logger, cfg)
+	if ignoredIdleTimeout > 0 {
+		logger.Warn("idleTimeout (%s) is ignored with --output %s, as nothing could resume the services; serve the control API with --api-addr to keep it", ignoredIdleTimeout, outputFormat)
+	}
 
 	// Optional pprof server for live profiling
 	if pprofAddr != "" {
@@ -196,6 +549,33 @@ func runPortForward(cmd *cobra.Command, args []string) {
 	// Create port forward manager
 	manager := portforward.NewManager(cfg, logger)
 
+	// Panics write a diagnostic bundle instead of leaving a corrupted terminal behind
+	panicHandler := diagnostics.NewPanicHandler(diagnostics.Source{
+		Version: version,
+		Logger:  logger,
+		Config:  cfg,
+		Status:  manager.GetLastStatus,
+	})
+	defer panicHandler.Recover()
+	if cmd.Flags().Changed("port-offset") {
+		manager.SetPortOffset(portOffset)
+	}
+	if serviceLogs != nil {
+		manager.SetServiceLogs(serviceLogs)
+	}
+	if heartbeatFile != "" {
+		manager.SetHeartbeatFile(heartbeatFile)
+	}
+	if downtimeFile != "" {
+		if err := manager.SetDowntimeFile(downtimeFile); err != nil {
+			logger.Warn("Downtime statistics start from zero: %v", err)
+		}
+	}
+	manager.SetStatusBuffer(statusBuffer)
+	if headless && apiAddr != "" {
+		manager.SetResumeHint(resumeHint(apiAddr))
+	}
+
 	// Set UI handlers on the manager
 	manager.SetUIHandlers(grpcUIManager, swaggerUIManager)
 
@@ -203,48 +583,255 @@ func runPortForward(cmd *cobra.Command, args []string) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// Subscribe the TUI before starting so the initial status is not missed
+	statusChan := manager.GetStatusChannel()
+	contextChan := manager.GetContextChannel()
+
 	// Start port forwarding
 	if err := manager.Start(); err != nil {
 		logger.Error("Failed to start port forwarding: %v", err)
 		os.Exit(1)
 	}
 
-	// Initialize and start update manager
+	// Optional proxy to services that are not in the config; its port-forwards are
+	// started again in the new context after a context switch
+	var clusterProxy *proxy.Server
+	if proxyAddr != "" {
+		clusterProxy = proxy.NewServer(proxyAddr, logger)
+		clusterProxy.SetInsecureExpose(insecureProxy)
+		if err := clusterProxy.Start(); err != nil {
+			logger.Warn("Cluster proxy disabled: %v", err)
+			clusterProxy = nil
+		} else {
+			proxyContexts := manager.SubscribeContext("proxy", 1)
+			go func() {
+				for range proxyContexts {
+					clusterProxy.Reset()
+				}
+			}()
+		}
+	}
+
+	if chaosInterval > 0 {
+		if chaosSeed == 0 {
+			chaosSeed = time.Now().UnixNano()
+		}
+		manager.EnableChaos(chaosInterval, chaosSeed)
+	}
+
+	// Initialize update manager
 	// Repository information for update checks - ensure this matches your GitHub repository
 	repoOwner := "catio-tech"
 	repoName := "kportforward"
 	updateManager := updater.NewManager(repoOwner, repoName, version, logger)
+	if err := configureUpdates(updateManager, cfg.Updates); err != nil {
+		log.Fatalf("Failed to configure update checks: %v", err)
+	}
+	provider := &telemetry{Manager: manager, updates: updateManager}
+
+	// Optional token-protected debug endpoint for support sessions
+	var debugServer *http.Server
+	if debugAddr != "" {
+		token, readOnlyToken, err := resolveAPITokens(logger)
+		if err != nil {
+			logger.Warn("Debug endpoint disabled, no API token: %v", err)
+		} else {
+			debugServer = api.NewDebugServer(debugAddr, token, readOnlyToken, provider)
+			go func() {
+				logger.Info("Starting debug endpoint on %s", debugAddr)
+				if err := serve(debugServer, debugAddr); err != nil && err != http.ErrServerClosed {
+					logger.Warn("Debug endpoint stopped: %v", err)
+				}
+			}()
+		}
+	}
+
+	// Actions from every client, including this TUI, are serialized and audited
+	controller := api.NewController(provider, logger)
+	var tui *ui.TUI
+	reloadConfig := func() error {
+		newCfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if err := manager.ReloadConfig(newCfg); err != nil {
+			return err
+		}
+		logSkippedServices(logger, newCfg)
+		if tui != nil {
+			tui.UpdateServiceConfigs(newCfg.PortForwards)
+			tui.UpdateDisabledServices(newCfg.Disabled)
+		}
+		return nil
+	}
+	controller.SetReloadFunc(reloadConfig)
+	apiShutdown := make(chan struct{}, 1)
+	controller.SetShutdownFunc(func() {
+		select {
+		case apiShutdown <- struct{}{}:
+		default:
+		}
+	})
+
+	// Optional control API for shared daemons with several attached clients
+	var controlServer *http.Server
+	if apiAddr != "" {
+		token, readOnlyToken, err := resolveAPITokens(logger)
+		if err != nil {
+			logger.Warn("Control API disabled, no API token: %v", err)
+		} else {
+			controlServer = api.NewControlServer(apiAddr, token, readOnlyToken, controller)
+			go func() {
+				logger.Info("Starting control API on %s", apiAddr)
+				if err := serve(controlServer, apiAddr); err != nil && err != http.ErrServerClosed {
+					logger.Warn("Control API stopped: %v", err)
+				}
+			}()
+		}
+	}
+
+	// Daily cleanup of logs, caches and containers left behind by earlier runs
+	housekeepingCtx, housekeepingCancel := context.WithCancel(context.Background())
+	defer housekeepingCancel()
+	go housekeeping.Run(housekeepingCtx, housekeeping.Options{
+		LogFile:         logFile,
+		LogDir:          logDir,
+		HeapSnapshotDir: heapSnapshotDir,
+	}, housekeeping.DefaultInterval, logger)
+
+	// Start update checks
 	if err := updateManager.Start(); err != nil {
 		logger.Error("Failed to start update manager: %v", err)
 		// Don't exit - updates are not critical
 	}
 
-	// Initialize and start TUI
-	tui := ui.NewTUI(manager.GetStatusChannel(), cfg.PortForwards, manager, manager.GetContextChannel())
-	if err := tui.Start(); err != nil {
-		logger.Error("Failed to start TUI: %v", err)
-		os.Exit(1)
+	// Without the TUI, status changes are streamed to stdout until a signal arrives
+	var tuiQuit <-chan bool
+	var control *localControl
+	headlessCtx, headlessCancel := context.WithCancel(context.Background())
+	defer headlessCancel()
+	if headless {
+		reporter := ui.NewHeadless(os.Stdout, outputFormat)
+		reporter.SetDisplayOptions(cfg.UIOptions)
+		go func() {
+			defer panicHandler.Recover()
+			reporter.Run(headlessCtx, statusChan, contextChan)
+		}()
+
+		go func() {
+			for updateInfo := range updateManager.GetUpdateChannel() {
+				if updateInfo != nil && updateInfo.Available {
+					logger.Info("Update available: %s", updateInfo.LatestVersion)
+				}
+			}
+		}()
+	} else {
+		// Initialize and start TUI
+		control = &localControl{Manager: manager, controller: controller, user: api.CurrentUser(), profiles: selectedProfiles, proxy: clusterProxy, updates: updateManager}
+		tui = ui.NewTUI(statusChan, cfg.PortForwards, control, contextChan)
+		tui.SetReadOnly(readOnly)
+		tui.SetMouse(mouse)
+		tui.SetDisplayOptions(displayOptions(cfg.UIOptions))
+		if state, err := config.LoadUIState(); err != nil {
+			logger.Warn("Pinned services not restored: %v", err)
+		} else {
+			tui.SetUIState(state)
+		}
+		tui.SetPanicRecovery(panicHandler.Recover)
+		panicHandler.OnPanic(func() { _ = tui.ReleaseTerminal() })
+		if err := tui.Start(); err != nil {
+			logger.Error("Failed to start TUI: %v", err)
+			os.Exit(1)
+		}
+		tuiQuit = tui.GetQuitChannel()
+
+		// Update TUI with initial context and UI handler status
+		tui.UpdateKubernetesContext(manager.GetKubernetesContext())
+		tui.UpdateDisabledServices(cfg.Disabled)
+		tui.UpdateUIHandlerStatus(grpcUIManager != nil, swaggerUIManager != nil)
+
+		// Listen for update notifications
+		go func() {
+			updateChan := updateManager.GetUpdateChannel()
+			for updateInfo := range updateChan {
+				tui.NotifyUpdateAvailable(updateInfo)
+			}
+		}()
 	}
 
-	// Update TUI with initial context and UI handler status
-	tui.UpdateKubernetesContext(manager.GetKubernetesContext())
-	tui.UpdateUIHandlerStatus(grpcUIManager != nil, swaggerUIManager != nil)
+	// Apply config file edits to the running services; invalid edits keep the current config.
+	// Switching profiles restarts watching with the new profiles' files.
+	var watchMutex sync.Mutex
+	watchCancel := func() {}
+	watchConfigFiles := func() {
+		watchMutex.Lock()
+		defer watchMutex.Unlock()
 
-	// Listen for update notifications
-	go func() {
-		updateChan := updateManager.GetUpdateChannel()
-		for updateInfo := range updateChan {
-			tui.NotifyUpdateAvailable(updateInfo)
+		watchCancel()
+		if !watchConfig {
+			return
+		}
+		files, err := config.ConfigFiles(selectedProfiles.Get())
+		if err != nil {
+			logger.Warn("Config file watching disabled: %v", err)
+			return
 		}
+		var watchCtx context.Context
+		watchCtx, watchCancel = context.WithCancel(context.Background())
+		go config.Watch(watchCtx, files, config.WatchInterval, func() {
+			logger.Info("Config file changed, reloading")
+			_ = controller.Reload("config-watcher")
+		})
+	}
+	watchConfigFiles()
+	defer func() {
+		watchMutex.Lock()
+		defer watchMutex.Unlock()
+		watchCancel()
 	}()
 
+	// Changed shared defaults are applied like config file edits, or pointed out when
+	// those are not applied either
+	remoteCtx, remoteCancel := context.WithCancel(context.Background())
+	defer remoteCancel()
+	go config.WatchRemote(remoteCtx, func() {
+		message := "Shared defaults changed, restart kportforward to apply them"
+		if watchConfig {
+			message = "Shared defaults changed and were applied"
+			if err := controller.Reload("remote-config"); err != nil {
+				message = fmt.Sprintf("Shared defaults changed but could not be applied: %v", err)
+			}
+		}
+		logger.Info("%s (%s)", message, configURL)
+		if tui != nil {
+			tui.ShowMessage(message)
+		}
+	})
+
+	// Profiles are switched by reloading with the new selection, so the terminal, the
+	// update checker and the control API keep running
+	controller.SetSwitchProfilesFunc(func(names []string) error {
+		previous := selectedProfiles.Get()
+		selectedProfiles.Set(names)
+		if err := reloadConfig(); err != nil {
+			selectedProfiles.Set(previous)
+			return err
+		}
+		logger.Info("Switched to profiles %v", names)
+		watchConfigFiles()
+		return nil
+	})
+
 	// Wait for shutdown signal or TUI quit
 	select {
 	case <-sigChan:
 		logger.Info("Received shutdown signal, stopping services...")
-	case <-tui.GetQuitChannel():
+	case <-tuiQuit:
 		logger.Info("TUI quit, stopping services...")
+	case <-apiShutdown:
+		logger.Info("Shutdown requested through the control API, stopping services...")
 	}
+	headlessCancel()
 
 	// Create a timeout context for graceful shutdown
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -257,8 +844,10 @@ func runPortForward(cmd *cobra.Command, args []string) {
 		// Graceful shutdown in proper order
 
 		// 1. Stop TUI first to prevent new UI interactions
-		if err := tui.Stop(); err != nil {
-			logger.Error("Error stopping TUI: %v", err)
+		if tui != nil {
+			if err := tui.Stop(); err != nil {
+				logger.Error("Error stopping TUI: %v", err)
+			}
 		}
 
 		// 2. Stop update manager
@@ -279,7 +868,23 @@ func runPortForward(cmd *cobra.Command, args []string) {
 			}
 		}
 
-		// 4. Stop port-forward manager last
+		if clusterProxy != nil {
+			clusterProxy.Stop()
+		}
+
+		// 4. Stop debug endpoint and control API
+		if debugServer != nil {
+			if err := debugServer.Shutdown(shutdownCtx); err != nil {
+				logger.Error("Error stopping debug endpoint: %v", err)
+			}
+		}
+		if controlServer != nil {
+			if err := controlServer.Shutdown(shutdownCtx); err != nil {
+				logger.Error("Error stopping control API: %v", err)
+			}
+		}
+
+		// 5. Stop port-forward manager last
 		if err := manager.Stop(); err != nil {
 			logger.Error("Error during shutdown: %v", err)
 		}
@@ -303,10 +908,46 @@ func runPortForward(cmd *cobra.Command, args []string) {
 		close(heapSnapStop)
 	}
 
-	// Close log file if it was opened
+	// Close log files if they were opened
+	if serviceLogs != nil {
+		if err := serviceLogs.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing service logs: %v\n", err)
+		}
+	}
 	if err := logger.Close(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error closing log file: %v\n", err)
 	}
+
+	// The services are stopped, so the updated binary can take over their ports
+	if control != nil && control.restart.Load() {
+		if err := updater.Restart(); err != nil {
+			fmt.Fprintf(os.Stderr, "Update installed, but %v; start kportforward again\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// resolveAPITokens returns the control API token and the read-only token. Without a
+// read-only token only the full token is accepted.
+func resolveAPITokens(logger *utils.Logger) (token, readOnlyToken string, err error) {
+	token, err = api.ResolveToken(apiToken)
+	if err != nil {
+		return "", "", err
+	}
+	readOnlyToken, err = api.ResolveReadOnlyToken()
+	if err != nil {
+		logger.Warn("Read-only API token disabled: %v", err)
+	}
+	return token, readOnlyToken, nil
+}
+
+// serve runs server on addr, which may be a "unix:<path>" control socket
+func serve(server *http.Server, addr string) error {
+	listener, err := api.Listen(addr)
+	if err != nil {
+		return err
+	}
+	return server.Serve(listener)
 }
 
 func displayStatus(status map[string]config.ServiceStatus, kubeContext string) {
@@ -331,6 +972,28 @@ func displayStatus(status map[string]config.ServiceStatus, kubeContext string) {
 	}
 }
 
+// resumeHint tells how to resume a headless kportforward serving the control API on
+// addr from idle: with daemon resume for the daemon, through the API otherwise
+func resumeHint(addr string) string {
+	if socket, err := api.DefaultSocketPath(); err == nil && addr == api.SocketAddr(socket) {
+		return "run kportforward daemon resume"
+	}
+	return fmt.Sprintf("press any key in kportforward attach --addr %s, or POST /api/resume on it", addr)
+}
+
+// logSkippedServices warns about the services the config left out, e.g. for an unset
+// environment variable, as they silently disappear from the table otherwise
+func logSkippedServices(logger *utils.Logger, cfg *config.Config) {
+	names := make([]string, 0, len(cfg.Skipped))
+	for name := range cfg.Skipped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		logger.Warn("Service %s is not started: %s", name, cfg.Skipped[name])
+	}
+}
+
 func logMemStats(logger *utils.Logger) {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
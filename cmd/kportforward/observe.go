@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/api"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/ui"
+)
+
+var (
+	observeAddr     string
+	observeInterval time.Duration
+)
+
+func init() {
+	observeCmd := &cobra.Command{
+		Use:   "observe",
+		Short: "Attach a read-only TUI to a running kportforward",
+		Long: `Attach a read-only TUI to a kportforward started with --debug-addr.
+
+The observer shows live status but cannot change services, which makes it suitable
+for screen-sharing sessions and for people attached to a shared forwarding host.
+
+Examples:
+  # On the forwarding host
+  kportforward --debug-addr localhost:6061
+
+  # In another terminal (uses the same API token)
+  kportforward observe --addr localhost:6061`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runObserve(true)
+		},
+	}
+
+	observeCmd.Flags().StringVar(&observeAddr, "addr", "localhost:6061", "Debug endpoint address of the running kportforward")
+	observeCmd.Flags().DurationVar(&observeInterval, "interval", time.Second, "How often to poll for status")
+	observeCmd.Flags().BoolVar(&mouse, "mouse", false, "Click to select services and open URLs")
+	observeCmd.Flags().StringVar(&apiToken, "api-token", "", "Control API token (default: $"+api.TokenEnvVar+" or the token in the config directory)")
+
+	attachCmd := &cobra.Command{
+		Use:   "attach",
+		Short: "Attach an interactive TUI to a shared kportforward daemon",
+		Long: `Attach a TUI to a kportforward started with --api-addr.
+
+Several clients can attach at once; each keeps its own sorting and selection.
+Restarts and resumes are applied by the daemon one at a time and recorded with
+the user who requested them (see /api/audit).
+
+Examples:
+  # On the jump box
+  kportforward --api-addr localhost:6062
+
+  # From each user's session
+  kportforward attach --addr localhost:6062`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runObserve(false)
+		},
+	}
+	attachCmd.Flags().StringVar(&observeAddr, "addr", "localhost:6062", "Control API address of the running kportforward")
+	attachCmd.Flags().DurationVar(&observeInterval, "interval", time.Second, "How often to poll for status")
+	attachCmd.Flags().BoolVar(&mouse, "mouse", false, "Click to select services and open URLs")
+	attachCmd.Flags().StringVar(&apiToken, "api-token", "", "Control API token (default: $"+api.TokenEnvVar+" or the token in the config directory)")
+
+	rootCmd.AddCommand(observeCmd)
+	rootCmd.AddCommand(attachCmd)
+}
+
+// runObserve polls the host and shows the result in a TUI that can only send
+// actions to the host when it is not read-only
+func runObserve(readOnly bool) error {
+	token, err := api.ResolveToken(apiToken)
+	if err != nil {
+		return fmt.Errorf("no API token: %w", err)
+	}
+
+	observer := api.NewObserver(observeAddr, token, observeInterval)
+	if !readOnly {
+		observer.EnableControl(api.CurrentUser())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Fail early instead of showing an empty TUI
+	if err := observer.Fetch(ctx); err != nil {
+		return err
+	}
+	go observer.Run(ctx)
+
+	// Service definitions only add type information; the host's config may differ
+	serviceConfigs := map[string]config.Service{}
+	var uiOptions config.UIConfig
+	config.SetRemoteConfigURL(configURL)
+	if cfg, err := config.LoadConfig(); err == nil {
+		serviceConfigs = cfg.PortForwards
+		uiOptions = cfg.UIOptions
+	}
+	if !config.IsValidTheme(theme) {
+		return fmt.Errorf("unknown --theme %q (expected dark, light or no-color)", theme)
+	}
+	applyTheme(uiOptions)
+
+	contextChan := make(chan string)
+	defer close(contextChan)
+
+	tui := ui.NewTUI(observer.GetStatusChannel(), serviceConfigs, observer, contextChan)
+	tui.SetReadOnly(readOnly)
+	tui.SetMouse(mouse)
+	tui.SetDisplayOptions(displayOptions(uiOptions))
+	// Pins are a local preference, so observers keep their own
+	if state, err := config.LoadUIState(); err == nil {
+		tui.SetUIState(state)
+	}
+	if err := tui.Start(); err != nil {
+		return fmt.Errorf("failed to start TUI: %w", err)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case <-sigChan:
+	case <-tui.GetQuitChannel():
+	}
+	return tui.Stop()
+}
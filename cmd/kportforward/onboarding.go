@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/ui"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// runOnboarding shows the first-run screen and returns the configuration to start with:
+// cfg itself, or cfg reloaded once a starter config was written. Leaving the screen
+// exits the program.
+func runOnboarding(cfg *config.Config, loadConfig func() (*config.Config, error)) *config.Config {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	contexts, current, contextErr := utils.ListKubeContexts(ctx)
+	cancel()
+
+	result, err := ui.RunOnboarding(ui.OnboardingOptions{
+		Contexts:       contexts,
+		CurrentContext: current,
+		ContextError:   contextErr,
+	})
+	if err != nil {
+		log.Printf("Skipping first-run setup: %v", err)
+		return cfg
+	}
+
+	switch result.Action {
+	case ui.OnboardingQuit:
+		os.Exit(0)
+
+	case ui.OnboardingImport:
+		fmt.Println("Import port-forwards from your local setup, then start kportforward again:")
+		fmt.Println()
+		fmt.Println("  kportforward config import compose docker-compose.yaml")
+		fmt.Println("  kportforward config import dotenv .env --namespace <namespace>")
+		os.Exit(0)
+
+	case ui.OnboardingCreated:
+		fmt.Printf("Created %s; add your services there.\n", result.ConfigPath)
+		newCfg, err := loadConfig()
+		if err != nil {
+			log.Fatalf("Failed to load the new configuration: %v", err)
+		}
+		return newCfg
+	}
+	return cfg
+}
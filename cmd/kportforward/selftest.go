@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+var (
+	selfTestDeep    bool
+	selfTestTimeout time.Duration
+	selfTestVerbose bool
+)
+
+func init() {
+	selfTestCmd := &cobra.Command{
+		Use:   "selftest [service]",
+		Short: "Start one port-forward, check it end to end and tear it down",
+		Long: `Start the port-forward of a single service (the first one by name if none is
+given), wait until its local port accepts connections and tear it down again,
+printing how long each step took. It exits non-zero if any step failed, which
+makes it a quick sanity check for support sessions and CI images.
+
+With --deep the service is also checked according to its type: web services must
+answer GET / and REST services GET <swaggerPath> with 2xx, and RPC services must
+list their services through gRPC server reflection (requires grpcurl).
+
+Examples:
+  kportforward selftest
+  kportforward selftest api-gateway --deep`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSelfTest(args)
+		},
+	}
+
+	selfTestCmd.Flags().BoolVar(&selfTestDeep, "deep", false, "Also run the type-specific check (HTTP 2xx, gRPC reflection)")
+	selfTestCmd.Flags().DurationVar(&selfTestTimeout, "timeout", 30*time.Second, "Give up when the test has not finished within this time")
+	selfTestCmd.Flags().StringArrayVar(&profiles, "profile", nil, "Test a service of this profile (repeatable)")
+	selfTestCmd.Flags().BoolVarP(&selfTestVerbose, "verbose", "v", false, "Show kubectl and port-forward logs on stderr")
+
+	rootCmd.AddCommand(selfTestCmd)
+}
+
+// runSelfTest tests one service and prints a line per step
+func runSelfTest(args []string) error {
+	loadConfig := config.LoadConfig
+	if len(profiles) > 0 {
+		loadConfig = func() (*config.Config, error) { return config.LoadProfiles(profiles) }
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if len(cfg.PortForwards) == 0 {
+		return fmt.Errorf("no services configured")
+	}
+
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		names := make([]string, 0, len(cfg.PortForwards))
+		for serviceName := range cfg.PortForwards {
+			names = append(names, serviceName)
+		}
+		sort.Strings(names)
+		name = names[0]
+	}
+	service, ok := cfg.PortForwards[name]
+	if !ok {
+		return fmt.Errorf("service %s not found", name)
+	}
+
+	var logOutput io.Writer = io.Discard
+	if selfTestVerbose {
+		logOutput = os.Stderr
+	}
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, logOutput)
+
+	ctx, cancel := context.WithTimeout(context.Background(), selfTestTimeout)
+	defer cancel()
+
+	fmt.Printf("Self-test of %s (%s:%d -> localhost:%d)\n", name, service.Target, service.TargetPort, service.LocalPort)
+	var total time.Duration
+	failed := false
+	for _, step := range portforward.SelfTest(ctx, name, service, cfg.PortForwardBackend, selfTestDeep, logger) {
+		total += step.Duration
+		switch {
+		case step.Skipped != "":
+			fmt.Printf("  %-16s skipped  %s\n", step.Name, step.Skipped)
+		case step.Err != nil:
+			failed = true
+			fmt.Printf("  %-16s FAILED   %8s  %v\n", step.Name, step.Duration.Round(time.Millisecond), step.Err)
+		default:
+			fmt.Printf("  %-16s ok       %8s\n", step.Name, step.Duration.Round(time.Millisecond))
+		}
+	}
+	fmt.Printf("  %-16s          %8s\n", "total", total.Round(time.Millisecond))
+
+	if failed {
+		return fmt.Errorf("self-test of %s failed", name)
+	}
+	return nil
+}
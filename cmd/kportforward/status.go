@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/api"
+)
+
+var (
+	statusAddr     string
+	statusExitCode bool
+	statusQuiet    bool
+	statusFormat   string
+)
+
+func init() {
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Summarize the health of a running kportforward",
+		Long: `Print a health summary of a kportforward started with --debug-addr or --api-addr,
+or of the background daemon (see "kportforward daemon") when --addr is not given.
+
+With --exit-code the command exits with 0 when all services are running, 1 when
+some are degraded or reconnecting, 2 when any has failed and 3 when kportforward
+could not be reached, so shell prompts and tmux status bars can reflect it.
+
+--format prompt prints a single compact line such as "kpf 12/14●" (running out of
+active services; ● healthy, ◐ degraded, ✗ failed) or "kpf ?" when unreachable.
+
+Examples:
+  kportforward status --addr localhost:6061
+  kportforward status --exit-code --quiet || echo "port-forwards unhealthy"
+
+  # tmux.conf
+  set -g status-right '#(kportforward status --format prompt)'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Without --addr, a running daemon is preferred over the default debug endpoint
+			if !cmd.Flags().Changed("addr") {
+				if addr, ok := daemonRunning(); ok {
+					statusAddr = addr
+				}
+			}
+			return runStatus()
+		},
+	}
+
+	statusCmd.Flags().StringVar(&statusAddr, "addr", "localhost:6061", "Debug endpoint address of the running kportforward")
+	statusCmd.Flags().BoolVar(&statusExitCode, "exit-code", false, "Exit with 0 (healthy), 1 (degraded), 2 (failed) or 3 (unreachable)")
+	statusCmd.Flags().BoolVarP(&statusQuiet, "quiet", "q", false, "Print nothing, only set the exit code")
+	statusCmd.Flags().StringVar(&statusFormat, "format", "text", "Output format: text or prompt")
+	statusCmd.Flags().StringVar(&apiToken, "api-token", "", "Control API token (default: $"+api.TokenEnvVar+" or the token in the config directory)")
+
+	rootCmd.AddCommand(statusCmd)
+}
+
+// runStatus fetches the status once and prints a summary with the services that need attention
+func runStatus() error {
+	if statusFormat != "text" && statusFormat != "prompt" {
+		return fmt.Errorf("unknown format %q (expected text or prompt)", statusFormat)
+	}
+
+	summary, err := fetchHealthSummary()
+	if err != nil {
+		switch {
+		case statusQuiet:
+		case statusFormat == "prompt":
+			// Prompts are redrawn constantly, so report an unreachable instance inline
+			fmt.Println(api.PromptUnknown)
+		case statusExitCode:
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		if statusExitCode {
+			os.Exit(api.ExitUnknown)
+		}
+		if statusFormat == "prompt" {
+			return nil
+		}
+		return err
+	}
+
+	switch {
+	case statusQuiet:
+	case statusFormat == "prompt":
+		fmt.Println(summary.Prompt())
+	default:
+		fmt.Println(summary)
+		for _, problem := range summary.Problems {
+			fmt.Printf("  %s\n", problem)
+		}
+	}
+
+	if statusExitCode {
+		os.Exit(summary.ExitCode())
+	}
+	return nil
+}
+
+// fetchHealthSummary reads the status of the kportforward at statusAddr
+func fetchHealthSummary() (api.HealthSummary, error) {
+	token, err := api.ResolveToken(apiToken)
+	if err != nil {
+		return api.HealthSummary{}, fmt.Errorf("no API token: %w", err)
+	}
+
+	observer := api.NewObserver(statusAddr, token, time.Second)
+	// Keep prompts responsive when the instance is not running
+	timeout := 5 * time.Second
+	if statusFormat == "prompt" {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := observer.Fetch(ctx); err != nil {
+		return api.HealthSummary{}, err
+	}
+	return api.SummarizeHealth(<-observer.GetStatusChannel()), nil
+}
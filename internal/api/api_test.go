@@ -0,0 +1,562 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/common"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/events"
+	"github.com/victorkazakov/kportforward/internal/updater"
+)
+
+type mockDebugProvider struct{}
+
+func (mockDebugProvider) GetLastStatus() map[string]config.ServiceStatus {
+	return map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Running", LocalPort: 8080, Downtime: config.DowntimeStats{
+			Outages: 2, Downtime: 90 * time.Second, Repaired: 2, RepairTime: 90 * time.Second,
+		}},
+	}
+}
+
+func (mockDebugProvider) QueueDepths() map[string]events.SubscriberStats {
+	return map[string]events.SubscriberStats{"status/tui": {Length: 1, Capacity: 16, Delivered: 12, Dropped: 3}}
+}
+
+func TestDebugEndpointRequiresToken(t *testing.T) {
+	server := httptest.NewServer(NewDebugServer("", "secret", "", mockDebugProvider{}).Handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/debug/vars")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/debug/vars", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 with token, got %d", resp.StatusCode)
+	}
+
+	var vars debugVars
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if vars.Services["api"].Status != "Running" {
+		t.Errorf("Expected service status in response, got %+v", vars.Services)
+	}
+	if vars.Queues["status/tui"].Capacity != 16 {
+		t.Errorf("Expected queue depths in response, got %+v", vars.Queues)
+	}
+	if vars.Runtime.Goroutines == 0 {
+		t.Error("Expected runtime stats in response")
+	}
+}
+
+func TestRedactCmdline(t *testing.T) {
+	args := []string{"kportforward", "--api-token", "secret", "--api-token=other", "--output", "json"}
+	want := []string{"kportforward", "--api-token", "REDACTED", "--api-token=REDACTED", "--output", "json"}
+	if got := redactCmdline(args); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if args[2] != "secret" {
+		t.Error("Expected os.Args to be left alone")
+	}
+}
+
+func TestLoadOrCreateToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kportforward", "api-token")
+
+	token, err := LoadOrCreateToken(path)
+	if err != nil {
+		t.Fatalf("LoadOrCreateToken returned error: %v", err)
+	}
+	if len(token) != 64 {
+		t.Errorf("Expected 64 hex characters, got %q", token)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Token file not written: %v", err)
+	}
+	if info.Mode().Perm()&0077 != 0 && os.PathSeparator == '/' {
+		t.Errorf("Expected token file to be private, got %v", info.Mode().Perm())
+	}
+
+	again, err := LoadOrCreateToken(path)
+	if err != nil || again != token {
+		t.Errorf("Expected the stored token to be reused, got %q (%v)", again, err)
+	}
+}
+
+func TestResolveTokenPrecedence(t *testing.T) {
+	t.Setenv(TokenEnvVar, "from-env")
+
+	if token, _ := ResolveToken("explicit"); token != "explicit" {
+		t.Errorf("Expected explicit token to win, got %q", token)
+	}
+	if token, _ := ResolveToken(""); token != "from-env" {
+		t.Errorf("Expected environment token, got %q", token)
+	}
+
+	t.Setenv(ReadOnlyTokenEnvVar, "viewer")
+	if token, _ := ResolveReadOnlyToken(); token != "viewer" {
+		t.Errorf("Expected environment read-only token, got %q", token)
+	}
+}
+
+func TestObserverFetch(t *testing.T) {
+	server := httptest.NewServer(NewDebugServer("", "secret", "", mockDebugProvider{}).Handler)
+	defer server.Close()
+
+	observer := NewObserver(server.URL, "wrong", time.Second)
+	if err := observer.Fetch(context.Background()); !errors.Is(err, common.ErrAuth) {
+		t.Errorf("Expected auth error for wrong token, got %v", err)
+	}
+
+	observer = NewObserver(strings.TrimPrefix(server.URL, "http://"), "secret", time.Second)
+	if err := observer.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	status := <-observer.GetStatusChannel()
+	if status["api"].Status != "Running" {
+		t.Errorf("Expected observed status, got %+v", status)
+	}
+	if observer.IsIdle() || !observer.GetGlobalAccessStatus() {
+		t.Error("Expected running, healthy host")
+	}
+}
+
+// mockControlProvider records restarts and how many ran at once
+type mockControlProvider struct {
+	mockDebugProvider
+	mutex      sync.Mutex
+	running    int
+	maxRunning int
+	restarts   []string
+}
+
+func (p *mockControlProvider) RestartService(name string) error {
+	p.mutex.Lock()
+	p.running++
+	if p.running > p.maxRunning {
+		p.maxRunning = p.running
+	}
+	p.restarts = append(p.restarts, name)
+	p.mutex.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	p.mutex.Lock()
+	p.running--
+	p.mutex.Unlock()
+	if name == "missing" {
+		return errors.New("service missing not found")
+	}
+	return nil
+}
+
+func (p *mockControlProvider) ResumeFromIdle() {}
+
+func TestControllerSerializesAndAudits(t *testing.T) {
+	provider := &mockControlProvider{}
+	controller := NewController(provider, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = controller.Restart("alice", "api")
+		}()
+	}
+	wg.Wait()
+
+	if provider.maxRunning != 1 {
+		t.Errorf("Expected actions to run one at a time, got %d concurrently", provider.maxRunning)
+	}
+
+	_ = controller.Restart("bob", "missing")
+	audit := controller.Audit()
+	if len(audit) != 6 {
+		t.Fatalf("Expected 6 audit entries, got %d", len(audit))
+	}
+	last := audit[len(audit)-1]
+	if last.User != "bob" || last.Action != "restart" || last.Error == "" {
+		t.Errorf("Expected failed restart by bob, got %+v", last)
+	}
+}
+
+// TestReadOnlyTokenCannotMutate tests that the read-only token can view status but gets
+// 403 from every mutating endpoint
+func TestReadOnlyTokenCannotMutate(t *testing.T) {
+	provider := &mockControlProvider{}
+	controller := NewController(provider, nil)
+	controller.SetReloadFunc(func() error { return nil })
+	controller.SetShutdownFunc(func() {})
+	server := httptest.NewServer(NewControlServer("", "secret", "viewer", controller).Handler)
+	defer server.Close()
+
+	request := func(method, path, token string) int {
+		req, err := http.NewRequest(method, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	for _, path := range []string{"/api/services", "/api/audit", "/debug/vars", "/metrics"} {
+		if status := request(http.MethodGet, path, "viewer"); status != http.StatusOK {
+			t.Errorf("Expected GET %s to be allowed, got %d", path, status)
+		}
+	}
+	for _, path := range []string{"/api/services/restart?name=api", "/api/services/api/restart", "/api/resume", "/api/reload", "/api/shutdown"} {
+		if status := request(http.MethodPost, path, "viewer"); status != http.StatusForbidden {
+			t.Errorf("Expected POST %s to be forbidden, got %d", path, status)
+		}
+	}
+	if len(controller.Audit()) != 0 {
+		t.Errorf("Expected no actions to be applied, got %+v", controller.Audit())
+	}
+	if status := request(http.MethodPost, "/api/resume", "secret"); status != http.StatusNoContent {
+		t.Errorf("Expected the full token to be allowed, got %d", status)
+	}
+	if status := request(http.MethodGet, "/api/services", ""); status != http.StatusUnauthorized {
+		t.Errorf("Expected an empty token to be rejected, got %d", status)
+	}
+}
+
+func TestAttachedObserverActions(t *testing.T) {
+	provider := &mockControlProvider{}
+	controller := NewController(provider, nil)
+	server := httptest.NewServer(NewControlServer("", "secret", "", controller).Handler)
+	defer server.Close()
+
+	readOnly := NewObserver(server.URL, "secret", time.Second)
+	if err := readOnly.RestartService("api"); err == nil {
+		t.Error("Expected read-only observer to refuse restarts")
+	}
+
+	attached := NewObserver(server.URL, "secret", time.Second)
+	attached.EnableControl("carol")
+	if err := attached.RestartService("api"); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+	if err := attached.RestartService("missing"); err == nil {
+		t.Error("Expected restart error to be reported to the client")
+	}
+	if err := readOnly.Resume(); err == nil {
+		t.Error("Expected read-only observer to refuse resuming")
+	}
+	if err := attached.Resume(); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+
+	audit := controller.Audit()
+	if len(audit) != 3 || audit[0].User != "carol" || audit[0].Service != "api" {
+		t.Errorf("Expected restart by carol in audit trail, got %+v", audit)
+	}
+	if len(audit) == 3 && (audit[2].User != "carol" || audit[2].Action != "resume") {
+		t.Errorf("Expected resume by carol in audit trail, got %+v", audit[2])
+	}
+}
+
+func TestSummarizeHealth(t *testing.T) {
+	summary := SummarizeHealth(map[string]config.ServiceStatus{
+		"api":    {Status: "Running"},
+		"web":    {Status: "Running"},
+		"batch":  {Status: "Scheduled"},
+		"search": {Status: "Reconnecting"},
+	})
+	if summary.ExitCode() != ExitDegraded {
+		t.Errorf("Expected degraded exit code, got %d", summary.ExitCode())
+	}
+	if summary.String() != "2 running, 1 degraded, 0 failed, 1 inactive" {
+		t.Errorf("Unexpected summary %q", summary.String())
+	}
+	if len(summary.Problems) != 1 || summary.Problems[0] != "search: Reconnecting" {
+		t.Errorf("Expected search to be listed as a problem, got %v", summary.Problems)
+	}
+	if summary.Prompt() != "kpf 2/3◐" {
+		t.Errorf("Unexpected prompt %q", summary.Prompt())
+	}
+
+	summary = SummarizeHealth(map[string]config.ServiceStatus{
+		"api":    {Status: "Suspended"},
+		"search": {Status: "Degraded"},
+	})
+	if summary.ExitCode() != ExitFailed {
+		t.Errorf("Expected failed exit code to win over degraded, got %d", summary.ExitCode())
+	}
+
+	if code := SummarizeHealth(map[string]config.ServiceStatus{"batch": {Status: "Idle"}}).ExitCode(); code != ExitHealthy {
+		t.Errorf("Expected inactive services not to count as unhealthy, got %d", code)
+	}
+}
+
+func TestControlAPIOverUnixSocket(t *testing.T) {
+	// Keep the path short; unix socket paths are limited to about 100 bytes
+	dir, err := os.MkdirTemp("", "kpf")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	addr := SocketAddr(filepath.Join(dir, "kportforward.sock"))
+
+	listener, err := Listen(addr)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	if _, err := Listen(addr); err == nil {
+		t.Error("Expected a second listener on a live socket to fail")
+	}
+
+	controller := NewController(&mockControlProvider{}, nil)
+	reloads := 0
+	controller.SetReloadFunc(func() error {
+		reloads++
+		return nil
+	})
+	shutdown := make(chan struct{}, 1)
+	controller.SetShutdownFunc(func() { shutdown <- struct{}{} })
+
+	server := NewControlServer("", "secret", "", controller)
+	go server.Serve(listener)
+	defer server.Close()
+
+	client := NewObserver(addr, "secret", time.Second)
+	if err := client.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch over unix socket failed: %v", err)
+	}
+	if err := client.ReloadConfig(); err == nil {
+		t.Error("Expected read-only client to refuse reloads")
+	}
+
+	client.EnableControl("dave")
+	if err := client.ReloadConfig(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if reloads != 1 {
+		t.Errorf("Expected one reload, got %d", reloads)
+	}
+	if err := client.Shutdown(); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	select {
+	case <-shutdown:
+	default:
+		t.Error("Expected shutdown to be requested")
+	}
+
+	audit := controller.Audit()
+	if len(audit) != 2 || audit[0].Action != "reload" || audit[1].Action != "shutdown" || audit[1].User != "dave" {
+		t.Errorf("Expected reload and shutdown by dave in audit trail, got %+v", audit)
+	}
+}
+
+func TestReloadWithoutReloadFunc(t *testing.T) {
+	controller := NewController(&mockControlProvider{}, nil)
+	if err := controller.Reload("erin"); err == nil {
+		t.Error("Expected reload to fail when not enabled")
+	}
+	if err := controller.Shutdown("erin"); err == nil {
+		t.Error("Expected shutdown to fail when not enabled")
+	}
+}
+
+type mockTelemetryProvider struct {
+	mockDebugProvider
+	state updater.State
+}
+
+func (p mockTelemetryProvider) UpdateState() updater.State {
+	return p.state
+}
+
+func TestMetricsIncludeUpdateState(t *testing.T) {
+	var plain strings.Builder
+	writeMetrics(&plain, mockDebugProvider{})
+	for _, want := range []string{
+		`kportforward_service_up{service="api"} 1`,
+		`kportforward_service_outages{service="api"} 2`,
+		`kportforward_service_downtime_seconds{service="api"} 90`,
+		`kportforward_service_mttr_seconds{service="api"} 45`,
+		`# TYPE kportforward_events_dropped_total counter`,
+		`kportforward_events_delivered_total{topic="status",subscriber="tui"} 12`,
+		`kportforward_events_dropped_total{topic="status",subscriber="tui"} 3`,
+		`kportforward_events_queue_capacity{topic="status",subscriber="tui"} 16`,
+	} {
+		if !strings.Contains(plain.String(), want) {
+			t.Errorf("Expected %q in metrics, got:\n%s", want, plain.String())
+		}
+	}
+	if strings.Contains(plain.String(), "kportforward_update_") {
+		t.Error("Expected no update metrics without an update state provider")
+	}
+
+	lastCheck := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var withUpdates strings.Builder
+	writeMetrics(&withUpdates, mockTelemetryProvider{state: updater.State{
+		LastCheck:       lastCheck,
+		UpdateAvailable: true,
+		CurrentVersion:  "v1.2.0",
+		LatestVersion:   "v1.3.0",
+	}})
+	for _, want := range []string{
+		`kportforward_update_available{current_version="v1.2.0",latest_version="v1.3.0"} 1`,
+		"kportforward_update_last_check_timestamp_seconds 1714564800",
+		"kportforward_update_last_error_timestamp_seconds 0",
+	} {
+		if !strings.Contains(withUpdates.String(), want) {
+			t.Errorf("Expected %q in metrics, got:\n%s", want, withUpdates.String())
+		}
+	}
+
+	var disabled strings.Builder
+	writeMetrics(&disabled, mockTelemetryProvider{state: updater.State{Disabled: true, CurrentVersion: "v1.2.0"}})
+	if strings.Contains(disabled.String(), "kportforward_update_") {
+		t.Error("Expected no update metrics with update checks disabled")
+	}
+}
+
+func TestRESTServiceEndpoints(t *testing.T) {
+	provider := &mockControlProvider{}
+	controller := NewController(provider, nil)
+	server := httptest.NewServer(NewControlServer("", "secret", "", controller).Handler)
+	defer server.Close()
+
+	do := func(method, path string) *http.Response {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set(UserHeader, "frank")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		return resp
+	}
+
+	resp := do(http.MethodGet, "/api/services")
+	var services map[string]config.ServiceStatus
+	if err := json.NewDecoder(resp.Body).Decode(&services); err != nil {
+		t.Fatalf("Failed to decode services: %v", err)
+	}
+	resp.Body.Close()
+	if services["api"].Status != "Running" {
+		t.Errorf("Expected service status, got %+v", services)
+	}
+
+	for _, tt := range []struct {
+		path string
+		want int
+	}{
+		{"/api/services/api/restart", http.StatusNoContent},
+		{"/api/services/staging/api/restart", http.StatusNoContent},
+		{"/api/services/missing/restart", http.StatusConflict},
+		{"/api/services/api", http.StatusNotFound},
+	} {
+		resp := do(http.MethodPost, tt.path)
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("POST %s: expected %d, got %d", tt.path, tt.want, resp.StatusCode)
+		}
+	}
+
+	resp = do(http.MethodGet, "/api/services/api/restart")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected GET restart to be rejected, got %d", resp.StatusCode)
+	}
+
+	audit := controller.Audit()
+	if len(audit) != 3 || audit[1].Service != "staging/api" || audit[0].User != "frank" {
+		t.Errorf("Expected restarts in the audit trail, got %+v", audit)
+	}
+}
+
+func TestControllerSetDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.ConfigEnvVar, path)
+
+	controller := NewController(&mockControlProvider{}, nil)
+	if err := controller.SetDisabled("frank", "api", true); err == nil {
+		t.Error("Expected disabling to fail without a reload func")
+	}
+
+	reloads := 0
+	controller.SetReloadFunc(func() error {
+		reloads++
+		return nil
+	})
+	if err := controller.SetDisabled("frank", "apu", true); err == nil {
+		t.Error("Expected an error for an unknown service")
+	}
+	if err := controller.SetDisabled("frank", "api", true); err != nil {
+		t.Fatalf("Failed to disable api: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "disabled: true") {
+		t.Errorf("Expected the choice to be written to the config file, got:\n%s", data)
+	}
+	if err := controller.SetDisabled("frank", "api", false); err != nil {
+		t.Fatalf("Failed to enable api: %v", err)
+	}
+	if reloads != 2 {
+		t.Errorf("Expected a reload per change, got %d", reloads)
+	}
+
+	audit := controller.Audit()
+	if len(audit) != 4 || audit[2].Action != "disable" || audit[3].Action != "enable" || audit[3].Service != "api" {
+		t.Errorf("Expected disable and enable in the audit trail, got %+v", audit)
+	}
+}
+
+func TestControllerSwitchProfiles(t *testing.T) {
+	controller := NewController(&mockControlProvider{}, nil)
+	if err := controller.SwitchProfiles("frank", []string{"staging"}); err == nil {
+		t.Error("Expected switching to fail without a switch func")
+	}
+
+	var switched [][]string
+	controller.SetSwitchProfilesFunc(func(profiles []string) error {
+		switched = append(switched, profiles)
+		return nil
+	})
+	if err := controller.SwitchProfiles("frank", []string{"staging"}); err != nil {
+		t.Fatalf("Failed to switch profiles: %v", err)
+	}
+	if len(switched) != 1 || switched[0][0] != "staging" {
+		t.Errorf("Expected a switch to staging, got %v", switched)
+	}
+
+	audit := controller.Audit()
+	if len(audit) != 2 || audit[1].Action != "switch-profile" || audit[1].Service != "staging" || audit[1].Error != "" {
+		t.Errorf("Expected the switch in the audit trail, got %+v", audit)
+	}
+}
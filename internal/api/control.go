@@ -0,0 +1,345 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// UserHeader identifies the person behind a control API request
+const UserHeader = "X-Kportforward-User"
+
+// auditLimit is the number of actions kept in the audit trail
+const auditLimit = 200
+
+// errNotSupported is returned for actions the hosting process did not enable
+var errNotSupported = errors.New("not supported by this kportforward instance")
+
+// ControlProvider is the manager state and actions exposed by the control API
+type ControlProvider interface {
+	DebugProvider
+	RestartService(name string) error
+	ResumeFromIdle()
+}
+
+// AuditEntry records one mutating action and who requested it
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Action  string    `json:"action"`
+	Service string    `json:"service,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Controller applies mutating actions from concurrently attached clients one at a
+// time and keeps an audit trail of who did what. View state such as sorting and
+// selection stays in each client.
+type Controller struct {
+	provider ControlProvider
+	logger   *utils.Logger
+
+	actionMutex sync.Mutex
+
+	// Optional actions of the hosting process, see SetReloadFunc, SetShutdownFunc and
+	// SetSwitchProfilesFunc
+	reload         func() error
+	shutdown       func()
+	switchProfiles func(profiles []string) error
+
+	auditMutex sync.RWMutex
+	audit      []AuditEntry
+}
+
+// NewController creates a controller for the provider
+func NewController(provider ControlProvider, logger *utils.Logger) *Controller {
+	return &Controller{
+		provider: provider,
+		logger:   logger,
+	}
+}
+
+// Restart restarts a single service on behalf of user
+func (c *Controller) Restart(user, service string) error {
+	c.actionMutex.Lock()
+	defer c.actionMutex.Unlock()
+
+	err := c.provider.RestartService(service)
+	c.record(AuditEntry{User: user, Action: "restart", Service: service}, err)
+	return err
+}
+
+// Resume resumes services stopped by the idle timeout on behalf of user
+func (c *Controller) Resume(user string) {
+	c.actionMutex.Lock()
+	defer c.actionMutex.Unlock()
+
+	c.provider.ResumeFromIdle()
+	c.record(AuditEntry{User: user, Action: "resume"}, nil)
+}
+
+// SetDisabled disables or re-enables a service on behalf of user. The choice is written
+// to the config file, so it survives restarts, and takes effect through a reload.
+func (c *Controller) SetDisabled(user, service string, disabled bool) error {
+	c.actionMutex.Lock()
+	defer c.actionMutex.Unlock()
+
+	action := "enable"
+	if disabled {
+		action = "disable"
+	}
+	err := c.setDisabled(service, disabled)
+	c.record(AuditEntry{User: user, Action: action, Service: service}, err)
+	return err
+}
+
+// setDisabled writes the disabled flag and reloads; the caller holds actionMutex
+func (c *Controller) setDisabled(service string, disabled bool) error {
+	if c.reload == nil {
+		return errNotSupported
+	}
+	if _, running := c.provider.GetLastStatus()[service]; disabled && !running {
+		// Keep typos out of the config file
+		return fmt.Errorf("unknown service %s", service)
+	}
+	if _, err := config.SetServiceDisabled(service, disabled); err != nil {
+		return err
+	}
+	return c.reload()
+}
+
+// SetReloadFunc enables config reloads through the controller
+func (c *Controller) SetReloadFunc(reload func() error) {
+	c.reload = reload
+}
+
+// SetShutdownFunc enables stopping the hosting process through the controller
+func (c *Controller) SetShutdownFunc(shutdown func()) {
+	c.shutdown = shutdown
+}
+
+// SetSwitchProfilesFunc enables switching the running profiles through the controller
+func (c *Controller) SetSwitchProfilesFunc(switchProfiles func(profiles []string) error) {
+	c.switchProfiles = switchProfiles
+}
+
+// SwitchProfiles replaces the running services with those of profiles, or of the
+// regular configuration if there are none, on behalf of user
+func (c *Controller) SwitchProfiles(user string, profiles []string) error {
+	c.actionMutex.Lock()
+	defer c.actionMutex.Unlock()
+
+	err := errNotSupported
+	if c.switchProfiles != nil {
+		err = c.switchProfiles(profiles)
+	}
+	c.record(AuditEntry{User: user, Action: "switch-profile", Service: strings.Join(profiles, ",")}, err)
+	return err
+}
+
+// Reload reloads the configuration on behalf of user
+func (c *Controller) Reload(user string) error {
+	c.actionMutex.Lock()
+	defer c.actionMutex.Unlock()
+
+	err := errNotSupported
+	if c.reload != nil {
+		err = c.reload()
+	}
+	c.record(AuditEntry{User: user, Action: "reload"}, err)
+	return err
+}
+
+// Shutdown stops the hosting process on behalf of user
+func (c *Controller) Shutdown(user string) error {
+	c.actionMutex.Lock()
+	defer c.actionMutex.Unlock()
+
+	if c.shutdown == nil {
+		c.record(AuditEntry{User: user, Action: "shutdown"}, errNotSupported)
+		return errNotSupported
+	}
+	c.record(AuditEntry{User: user, Action: "shutdown"}, nil)
+	c.shutdown()
+	return nil
+}
+
+// Audit returns the recorded actions, oldest first
+func (c *Controller) Audit() []AuditEntry {
+	c.auditMutex.RLock()
+	defer c.auditMutex.RUnlock()
+
+	entries := make([]AuditEntry, len(c.audit))
+	copy(entries, c.audit)
+	return entries
+}
+
+// record appends an action to the audit trail and the log
+func (c *Controller) record(entry AuditEntry, err error) {
+	entry.Time = time.Now()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	c.auditMutex.Lock()
+	c.audit = append(c.audit, entry)
+	if len(c.audit) > auditLimit {
+		c.audit = c.audit[len(c.audit)-auditLimit:]
+	}
+	c.auditMutex.Unlock()
+
+	if c.logger != nil {
+		if err != nil {
+			c.logger.Warn("Control API: %s %s %s failed: %v", entry.User, entry.Action, entry.Service, err)
+		} else {
+			c.logger.Info("Control API: %s %s %s", entry.User, entry.Action, entry.Service)
+		}
+	}
+}
+
+// Handler returns the HTTP handler for the /api/ endpoints
+func (c *Controller) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/api/services/restart", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		service := r.URL.Query().Get("name")
+		if service == "" {
+			http.Error(w, "missing service name", http.StatusBadRequest)
+			return
+		}
+		if err := c.Restart(requestUser(r), service); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/api/services", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err := json.NewEncoder(w).Encode(c.provider.GetLastStatus()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	// REST-style restart for editor plugins and dashboards: POST /api/services/{name}/restart.
+	// Profile services such as "staging/api" keep their slash.
+	mux.HandleFunc("/api/services/", func(w http.ResponseWriter, r *http.Request) {
+		service, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/services/"), "/restart")
+		if !ok || service == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := c.Restart(requestUser(r), service); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/api/resume", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		c.Resume(requestUser(r))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/api/reload", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := c.Reload(requestUser(r)); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/api/shutdown", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := c.Shutdown(requestUser(r)); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/api/audit", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err := json.NewEncoder(w).Encode(c.Audit()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	return mux
+}
+
+// requestUser returns the user a client identified itself as, or its address
+func requestUser(r *http.Request) string {
+	if user := r.Header.Get(UserHeader); user != "" {
+		return user
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return fmt.Sprintf("anonymous@%s", host)
+}
+
+// NewControlServer returns a server exposing the debug endpoint, metrics and the
+// control API, all guarded by the control API token. The read-only token, if set, can
+// view everything but not send actions.
+func NewControlServer(addr, token, readOnlyToken string, controller *Controller) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", RequireToken(token, readOnlyToken, NewDebugHandler(controller.provider)))
+	mux.Handle("/metrics", RequireToken(token, readOnlyToken, NewMetricsHandler(controller.provider)))
+	mux.Handle("/api/", RequireToken(token, readOnlyToken, controller.Handler()))
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// CurrentUser returns the name recorded in the audit trail for actions from this machine
+func CurrentUser() string {
+	host, _ := os.Hostname()
+	name := os.Getenv("USER")
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	if host == "" {
+		return name
+	}
+	return name + "@" + host
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/events"
+)
+
+// DebugProvider exposes the manager state published by the debug endpoint
+type DebugProvider interface {
+	GetLastStatus() map[string]config.ServiceStatus
+	QueueDepths() map[string]events.SubscriberStats
+}
+
+// runtimeStats is a subset of the Go runtime statistics
+type runtimeStats struct {
+	Goroutines   int    `json:"goroutines"`
+	HeapAlloc    uint64 `json:"heapAlloc"`
+	HeapInuse    uint64 `json:"heapInuse"`
+	HeapObjects  uint64 `json:"heapObjects"`
+	Sys          uint64 `json:"sys"`
+	NumGC        uint32 `json:"numGC"`
+	PauseTotalNs uint64 `json:"pauseTotalNs"`
+}
+
+// debugVars is the document served by the debug endpoint, in the spirit of expvar
+type debugVars struct {
+	Cmdline  []string                          `json:"cmdline"`
+	Uptime   string                            `json:"uptime"`
+	Services map[string]config.ServiceStatus   `json:"services"`
+	Queues   map[string]events.SubscriberStats `json:"queues"`
+	Runtime  runtimeStats                      `json:"runtime"`
+}
+
+// NewDebugHandler returns an expvar-style JSON handler for the provider's state.
+// Unlike the expvar package it does not register itself on http.DefaultServeMux,
+// so the unauthenticated pprof server never exposes it.
+func NewDebugHandler(provider DebugProvider) http.Handler {
+	started := time.Now()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var memStats runtime.MemStats
+		runtime.ReadMemStats(&memStats)
+
+		vars := debugVars{
+			Cmdline:  redactCmdline(os.Args),
+			Uptime:   time.Since(started).Round(time.Second).String(),
+			Services: provider.GetLastStatus(),
+			Queues:   provider.QueueDepths(),
+			Runtime: runtimeStats{
+				Goroutines:   runtime.NumGoroutine(),
+				HeapAlloc:    memStats.HeapAlloc,
+				HeapInuse:    memStats.HeapInuse,
+				HeapObjects:  memStats.HeapObjects,
+				Sys:          memStats.Sys,
+				NumGC:        memStats.NumGC,
+				PauseTotalNs: memStats.PauseTotalNs,
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(vars); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// NewDebugServer returns a server exposing the debug endpoint at /debug/vars and
+// Prometheus metrics at /metrics, guarded by the control API token or the read-only token
+func NewDebugServer(addr, token, readOnlyToken string, provider DebugProvider) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", RequireToken(token, readOnlyToken, NewDebugHandler(provider)))
+	mux.Handle("/metrics", RequireToken(token, readOnlyToken, NewMetricsHandler(provider)))
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// redactCmdline returns args with the value of --api-token replaced, so the token does
+// not leak to whoever can read the debug endpoint with a token of their own
+func redactCmdline(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i, arg := range redacted {
+		switch {
+		case arg == "--api-token" && i+1 < len(redacted):
+			redacted[i+1] = "REDACTED"
+		case strings.HasPrefix(arg, "--api-token="):
+			redacted[i] = "--api-token=REDACTED"
+		}
+	}
+	return redacted
+}
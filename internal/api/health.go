@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// Prompt indicators, chosen to stay readable without colors
+const (
+	promptHealthy  = "●"
+	promptDegraded = "◐"
+	promptFailed   = "✗"
+
+	// PromptUnknown is shown in prompts when kportforward could not be reached
+	PromptUnknown = "kpf ?"
+)
+
+// Exit codes of `kportforward status --exit-code`, following the Nagios plugin convention
+const (
+	ExitHealthy  = 0
+	ExitDegraded = 1
+	ExitFailed   = 2
+	ExitUnknown  = 3 // The running kportforward could not be reached
+)
+
+// HealthSummary counts services by how healthy they are
+type HealthSummary struct {
+	Healthy  int
+	Degraded int
+	Failed   int
+	Inactive int // Idle, Scheduled or Stopped on purpose
+
+	// Problems lists "name: status" for every degraded or failed service, sorted by name
+	Problems []string
+}
+
+// SummarizeHealth classifies each service status as healthy, degraded, failed or inactive
+func SummarizeHealth(statuses map[string]config.ServiceStatus) HealthSummary {
+	var summary HealthSummary
+	for name, status := range statuses {
+		switch status.Status {
+		case "Running":
+			summary.Healthy++
+		case "Idle", "Scheduled", "Stopped":
+			summary.Inactive++
+		case "Failed", "Suspended", "PortConflict":
+			summary.Failed++
+			summary.Problems = append(summary.Problems, fmt.Sprintf("%s: %s", name, status.Status))
+		default: // Starting, Waiting, Connecting, Reconnecting, Degraded, Cooldown
+			summary.Degraded++
+			summary.Problems = append(summary.Problems, fmt.Sprintf("%s: %s", name, status.Status))
+		}
+	}
+	sort.Strings(summary.Problems)
+	return summary
+}
+
+// ExitCode returns ExitFailed if any service failed, ExitDegraded if any is degraded, else ExitHealthy
+func (s HealthSummary) ExitCode() int {
+	switch {
+	case s.Failed > 0:
+		return ExitFailed
+	case s.Degraded > 0:
+		return ExitDegraded
+	default:
+		return ExitHealthy
+	}
+}
+
+// String returns a one-line summary such as "12 running, 1 degraded, 0 failed"
+func (s HealthSummary) String() string {
+	parts := []string{
+		fmt.Sprintf("%d running", s.Healthy),
+		fmt.Sprintf("%d degraded", s.Degraded),
+		fmt.Sprintf("%d failed", s.Failed),
+	}
+	if s.Inactive > 0 {
+		parts = append(parts, fmt.Sprintf("%d inactive", s.Inactive))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Prompt returns a compact summary for shell prompts and tmux status lines, such as
+// "kpf 12/14●": running services out of all active ones, followed by a health indicator
+func (s HealthSummary) Prompt() string {
+	indicator := promptHealthy
+	switch s.ExitCode() {
+	case ExitFailed:
+		indicator = promptFailed
+	case ExitDegraded:
+		indicator = promptDegraded
+	}
+	return fmt.Sprintf("kpf %d/%d%s", s.Healthy, s.Healthy+s.Degraded+s.Failed, indicator)
+}
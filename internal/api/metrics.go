@@ -0,0 +1,162 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/events"
+	"github.com/victorkazakov/kportforward/internal/updater"
+)
+
+// UpdateStateProvider is implemented by providers that also report the update checker's
+// state, which is then included in the metrics
+type UpdateStateProvider interface {
+	UpdateState() updater.State
+}
+
+// labelEscaper escapes label values for the Prometheus text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// NewMetricsHandler returns a handler serving the provider's state in the Prometheus
+// text format, written by hand to avoid pulling in the client library
+func NewMetricsHandler(provider DebugProvider) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w, provider)
+	})
+}
+
+// writeMetrics writes service and, if available, update checker metrics
+func writeMetrics(w io.Writer, provider DebugProvider) {
+	status := provider.GetLastStatus()
+	names := make([]string, 0, len(status))
+	counts := make(map[string]int)
+	for name, service := range status {
+		names = append(names, name)
+		counts[service.Status]++
+	}
+	sort.Strings(names)
+
+	statuses := make([]string, 0, len(counts))
+	for name := range counts {
+		statuses = append(statuses, name)
+	}
+	sort.Strings(statuses)
+
+	writeHeader(w, "kportforward_services", "Number of services by status")
+	for _, name := range statuses {
+		fmt.Fprintf(w, "kportforward_services{status=\"%s\"} %d\n", labelEscaper.Replace(name), counts[name])
+	}
+
+	writeHeader(w, "kportforward_service_up", "Whether the service's port-forward is running")
+	for _, name := range names {
+		fmt.Fprintf(w, "kportforward_service_up{service=\"%s\"} %d\n", labelEscaper.Replace(name), boolValue(status[name].Status == "Running"))
+	}
+
+	writeHeader(w, "kportforward_service_restarts", "Restarts of the service's port-forward")
+	for _, name := range names {
+		fmt.Fprintf(w, "kportforward_service_restarts{service=\"%s\"} %d\n", labelEscaper.Replace(name), status[name].RestartCount)
+	}
+
+	now := time.Now()
+	writeHeader(w, "kportforward_service_outages", "Outages of the service, including an ongoing one")
+	for _, name := range names {
+		fmt.Fprintf(w, "kportforward_service_outages{service=\"%s\"} %d\n", labelEscaper.Replace(name), status[name].Downtime.Outages)
+	}
+
+	writeHeader(w, "kportforward_service_downtime_seconds", "Total time the service was down")
+	for _, name := range names {
+		fmt.Fprintf(w, "kportforward_service_downtime_seconds{service=\"%s\"} %g\n", labelEscaper.Replace(name), status[name].Downtime.TotalDowntime(now).Seconds())
+	}
+
+	writeHeader(w, "kportforward_service_mttr_seconds", "Mean time until the service ran again after an outage (0 before the first)")
+	for _, name := range names {
+		fmt.Fprintf(w, "kportforward_service_mttr_seconds{service=\"%s\"} %g\n", labelEscaper.Replace(name), status[name].Downtime.MTTR().Seconds())
+	}
+
+	writeQueueMetrics(w, provider.QueueDepths())
+
+	updates, ok := provider.(UpdateStateProvider)
+	if !ok {
+		return
+	}
+	state := updates.UpdateState()
+	// Without checks the update gauges would only raise stale-check alerts
+	if state.Disabled {
+		return
+	}
+
+	writeHeader(w, "kportforward_update_available", "Whether a newer release is available")
+	fmt.Fprintf(w, "kportforward_update_available{current_version=\"%s\",latest_version=\"%s\"} %d\n",
+		labelEscaper.Replace(state.CurrentVersion), labelEscaper.Replace(state.LatestVersion), boolValue(state.UpdateAvailable))
+
+	writeHeader(w, "kportforward_update_last_check_timestamp_seconds", "Time of the last successful update check (0 if never)")
+	fmt.Fprintf(w, "kportforward_update_last_check_timestamp_seconds %d\n", unixSeconds(state.LastCheck))
+
+	writeHeader(w, "kportforward_update_last_error_timestamp_seconds", "Time of the last failed update check (0 if none)")
+	fmt.Fprintf(w, "kportforward_update_last_error_timestamp_seconds %d\n", unixSeconds(state.LastErrorTime))
+}
+
+// writeQueueMetrics writes how many updates each event subscriber received and how many
+// it missed, as a newer one replaced them while it fell behind
+func writeQueueMetrics(w io.Writer, queues map[string]events.SubscriberStats) {
+	keys := make([]string, 0, len(queues))
+	for key := range queues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	labels := func(key string) string {
+		// Keys are "<topic>/<subscriber>"
+		topic, subscriber, _ := strings.Cut(key, "/")
+		return fmt.Sprintf("topic=\"%s\",subscriber=\"%s\"", labelEscaper.Replace(topic), labelEscaper.Replace(subscriber))
+	}
+
+	writeTypedHeader(w, "kportforward_events_delivered_total", "Updates queued for the subscriber", "counter")
+	for _, key := range keys {
+		fmt.Fprintf(w, "kportforward_events_delivered_total{%s} %d\n", labels(key), queues[key].Delivered)
+	}
+
+	writeTypedHeader(w, "kportforward_events_dropped_total", "Updates replaced by a newer one before the subscriber read them", "counter")
+	for _, key := range keys {
+		fmt.Fprintf(w, "kportforward_events_dropped_total{%s} %d\n", labels(key), queues[key].Dropped)
+	}
+
+	writeHeader(w, "kportforward_events_queued", "Updates waiting for the subscriber")
+	for _, key := range keys {
+		fmt.Fprintf(w, "kportforward_events_queued{%s} %d\n", labels(key), queues[key].Length)
+	}
+
+	writeHeader(w, "kportforward_events_queue_capacity", "Updates buffered for the subscriber before the oldest are dropped")
+	for _, key := range keys {
+		fmt.Fprintf(w, "kportforward_events_queue_capacity{%s} %d\n", labels(key), queues[key].Capacity)
+	}
+}
+
+// writeHeader writes the HELP and TYPE lines of a gauge
+func writeHeader(w io.Writer, name, help string) {
+	writeTypedHeader(w, name, help, "gauge")
+}
+
+// writeTypedHeader writes the HELP and TYPE lines of a metric of type metricType, such
+// as counter
+func writeTypedHeader(w io.Writer, name, help, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func boolValue(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
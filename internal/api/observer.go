@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/common"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// Observer polls the debug endpoint of a running kportforward so a second TUI can
+// attach to it. It is read-only unless control is enabled. It satisfies ui.UIManagerProvider.
+type Observer struct {
+	baseURL  string
+	token    string
+	interval time.Duration
+	client   *http.Client
+
+	// user is sent with control actions; empty means read-only
+	user string
+
+	mutex      sync.RWMutex
+	lastStatus map[string]config.ServiceStatus
+	statusChan chan map[string]config.ServiceStatus
+}
+
+// NewObserver creates an observer for the debug endpoint at addr (host:port, URL or
+// "unix:<path>" for the daemon's control socket)
+func NewObserver(addr, token string, interval time.Duration) *Observer {
+	client := &http.Client{Timeout: 5 * time.Second}
+	if path := socketPath(addr); path != "" {
+		// The host name is ignored; every request goes to the socket
+		client.Transport = unixTransport(path)
+		addr = "http://kportforward"
+	} else if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+
+	return &Observer{
+		baseURL:    strings.TrimSuffix(addr, "/"),
+		token:      token,
+		interval:   interval,
+		client:     client,
+		statusChan: make(chan map[string]config.ServiceStatus, 1),
+	}
+}
+
+// Fetch retrieves the current service status once and publishes it
+func (o *Observer) Fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.baseURL+"/debug/vars", nil)
+	if err != nil {
+		return fmt.Errorf("invalid debug endpoint address: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+o.token)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return common.WithKind(common.ErrNetwork, fmt.Errorf("failed to reach %s: %w", o.baseURL, err))
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return common.WithKind(common.ErrAuth, fmt.Errorf("debug endpoint rejected the API token"))
+	default:
+		return fmt.Errorf("debug endpoint returned %s", resp.Status)
+	}
+
+	var vars debugVars
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		return fmt.Errorf("failed to decode debug endpoint response: %w", err)
+	}
+
+	o.mutex.Lock()
+	o.lastStatus = vars.Services
+	o.mutex.Unlock()
+
+	// Replace an unread update so the TUI always gets the latest state
+	select {
+	case <-o.statusChan:
+	default:
+	}
+	o.statusChan <- vars.Services
+	return nil
+}
+
+// Run polls the endpoint until ctx is cancelled. Failed polls keep the last known status.
+func (o *Observer) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = o.Fetch(ctx)
+		}
+	}
+}
+
+// GetStatusChannel returns the channel status updates are published on
+func (o *Observer) GetStatusChannel() <-chan map[string]config.ServiceStatus {
+	return o.statusChan
+}
+
+// GetGRPCUIURL returns "" since UI handlers belong to the observed host
+func (o *Observer) GetGRPCUIURL(serviceName string) string {
+	return ""
+}
+
+// GetSwaggerUIURL returns "" since UI handlers belong to the observed host
+func (o *Observer) GetSwaggerUIURL(serviceName string) string {
+	return ""
+}
+
+// GetGlobalAccessStatus reports whether the host has cluster access
+func (o *Observer) GetGlobalAccessStatus() bool {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	for _, status := range o.lastStatus {
+		if status.GlobalStatus == "auth_failure" || status.GlobalStatus == "network_failure" {
+			return false
+		}
+	}
+	return true
+}
+
+// IsIdle reports whether the host stopped all services for inactivity
+func (o *Observer) IsIdle() bool {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	if len(o.lastStatus) == 0 {
+		return false
+	}
+	for _, status := range o.lastStatus {
+		if status.Status != "Idle" {
+			return false
+		}
+	}
+	return true
+}
+
+// EnableControl lets the observer send actions to the host's control API as user
+func (o *Observer) EnableControl(user string) {
+	o.user = user
+}
+
+// ResumeFromIdle asks the host to resume; read-only observers do nothing
+func (o *Observer) ResumeFromIdle() {
+	_ = o.Resume()
+}
+
+// Resume asks the host to restart the services stopped by its idle timeout
+func (o *Observer) Resume() error {
+	if o.user == "" {
+		return fmt.Errorf("observer is read-only")
+	}
+	return o.post("/api/resume")
+}
+
+// RestartService asks the host to restart a service
+func (o *Observer) RestartService(name string) error {
+	if o.user == "" {
+		return fmt.Errorf("observer is read-only")
+	}
+	return o.post("/api/services/restart?name=" + url.QueryEscape(name))
+}
+
+// ReloadConfig asks the host to reload its configuration
+func (o *Observer) ReloadConfig() error {
+	if o.user == "" {
+		return fmt.Errorf("observer is read-only")
+	}
+	return o.post("/api/reload")
+}
+
+// Shutdown asks the host to stop all services and exit
+func (o *Observer) Shutdown() error {
+	if o.user == "" {
+		return fmt.Errorf("observer is read-only")
+	}
+	return o.post("/api/shutdown")
+}
+
+// Audit fetches the control API's audit trail, oldest first
+func (o *Observer) Audit(ctx context.Context) ([]AuditEntry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.baseURL+"/api/audit", nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid control API address: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+o.token)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, common.WithKind(common.ErrNetwork, fmt.Errorf("failed to reach %s: %w", o.baseURL, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("control API returned %s", resp.Status)
+	}
+	var entries []AuditEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode audit trail: %w", err)
+	}
+	return entries, nil
+}
+
+// post sends a control action to the host
+func (o *Observer) post(path string) error {
+	req, err := http.NewRequest(http.MethodPost, o.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("invalid control API address: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+o.token)
+	req.Header.Set(UserHeader, o.user)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return common.WithKind(common.ErrNetwork, fmt.Errorf("failed to reach %s: %w", o.baseURL, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("control API returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
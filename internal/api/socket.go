@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// unixPrefix marks an address as a unix domain socket path rather than host:port
+const unixPrefix = "unix:"
+
+// DefaultSocketPath returns the control socket of the background daemon, next to the token file
+func DefaultSocketPath() (string, error) {
+	tokenPath, err := DefaultTokenPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(tokenPath), "kportforward.sock"), nil
+}
+
+// SocketAddr returns the address form of a unix socket path, as accepted by Listen and NewObserver
+func SocketAddr(path string) string {
+	return unixPrefix + path
+}
+
+// socketPath returns the socket path of a "unix:" address, or "" for network addresses
+func socketPath(addr string) string {
+	if !strings.HasPrefix(addr, unixPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(addr, unixPrefix)
+}
+
+// Listen listens on a TCP host:port or, for "unix:<path>" addresses, on a unix socket
+// readable only by the current user. Unix sockets are also supported on Windows 10 and later.
+func Listen(addr string) (net.Listener, error) {
+	path := socketPath(addr)
+	if path == "" {
+		return net.Listen("tcp", addr)
+	}
+
+	// A socket left behind by a crashed daemon blocks the address; a live one answers
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s is in use by a running kportforward", path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	return listenUnix(path)
+}
+
+// unixTransport returns an HTTP transport that sends every request to the socket at path
+func unixTransport(path string) *http.Transport {
+	return &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", path)
+		},
+	}
+}
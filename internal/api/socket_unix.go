@@ -0,0 +1,18 @@
+//go:build !windows
+
+package api
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenUnix creates the socket at path readable only by the current user. The umask is
+// restricted while binding, so the socket never exists with wider permissions; it is
+// process-wide, so it is restored right after.
+func listenUnix(path string) (net.Listener, error) {
+	mask := syscall.Umask(0177)
+	listener, err := net.Listen("unix", path)
+	syscall.Umask(mask)
+	return listener, err
+}
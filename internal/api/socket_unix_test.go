@@ -0,0 +1,37 @@
+//go:build !windows
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// TestListenCreatesPrivateSocket tests that the socket is created readable only by the
+// current user, whatever the umask, instead of being restricted after it exists
+func TestListenCreatesPrivateSocket(t *testing.T) {
+	// Keep the path short; unix socket paths are limited to about 100 bytes
+	dir, err := os.MkdirTemp("", "kpf")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "kportforward.sock")
+
+	defer syscall.Umask(syscall.Umask(0))
+	listener, err := Listen(SocketAddr(path))
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("Expected mode 0600, got %o", mode)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package api
+
+import "net"
+
+// listenUnix creates the socket at path. Windows has no umask and ignores permission
+// bits; access follows the ACL of the directory, which is in the user's profile.
+func listenUnix(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// TokenEnvVar overrides the control API token when set
+const TokenEnvVar = "KPORTFORWARD_API_TOKEN"
+
+// ReadOnlyTokenEnvVar overrides the read-only control API token when set
+const ReadOnlyTokenEnvVar = "KPORTFORWARD_API_READONLY_TOKEN"
+
+// ResolveToken returns the control API token: the explicit value if given, then
+// the environment variable, then the token file (created on first use)
+func ResolveToken(explicit string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+	if token := os.Getenv(TokenEnvVar); token != "" {
+		return token, nil
+	}
+
+	path, err := DefaultTokenPath()
+	if err != nil {
+		return "", err
+	}
+	return LoadOrCreateToken(path)
+}
+
+// ResolveReadOnlyToken returns the token that can view status but not change anything:
+// the environment variable if set, otherwise the read-only token file (created on first use)
+func ResolveReadOnlyToken() (string, error) {
+	if token := os.Getenv(ReadOnlyTokenEnvVar); token != "" {
+		return token, nil
+	}
+
+	path, err := DefaultTokenPath()
+	if err != nil {
+		return "", err
+	}
+	return LoadOrCreateToken(path + "-readonly")
+}
+
+// DefaultTokenPath returns the platform-specific location of the token file
+func DefaultTokenPath() (string, error) {
+	var configDir string
+
+	switch runtime.GOOS {
+	case "windows":
+		configDir = os.Getenv("APPDATA")
+		if configDir == "" {
+			return "", fmt.Errorf("APPDATA environment variable not set")
+		}
+	default:
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config")
+	}
+
+	return filepath.Join(configDir, "kportforward", "api-token"), nil
+}
+
+// LoadOrCreateToken reads the token stored at path, generating a new random
+// token readable only by the current user if the file does not exist
+func LoadOrCreateToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read API token: %w", err)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write API token: %w", err)
+	}
+
+	return token, nil
+}
+
+// RequireToken rejects requests that do not carry the token or the read-only token as a
+// bearer token. The read-only token is refused for anything but GET and HEAD, so it
+// cannot reach a mutating endpoint.
+func RequireToken(token, readOnlyToken string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		switch {
+		case matchesToken(provided, token):
+		case matchesToken(provided, readOnlyToken):
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				http.Error(w, "forbidden: read-only API token", http.StatusForbidden)
+				return
+			}
+		default:
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// matchesToken reports whether provided is token, never matching an unset token
+func matchesToken(provided, token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
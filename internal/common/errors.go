@@ -0,0 +1,149 @@
+package common
+
+import (
+	"errors"
+	"strings"
+)
+
+// Error categories shared across packages. Check them with errors.Is.
+var (
+	ErrAuth              = errors.New("authentication failure")
+	ErrNetwork           = errors.New("network failure")
+	ErrNotFound          = errors.New("not found")
+	ErrPortConflict      = errors.New("port conflict")
+	ErrDockerUnavailable = errors.New("docker unavailable")
+)
+
+// classifiedError attaches an error category to an underlying error while
+// keeping the original message
+type classifiedError struct {
+	kind error
+	err  error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// WithKind marks err as belonging to the given category
+func WithKind(kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{kind: kind, err: err}
+}
+
+// authPatterns are lower-case fragments of kubectl and cloud provider messages
+// that indicate expired or missing credentials. They are kept specific: a bare
+// "auth", "session" or "permission denied" also matches local failures such as
+// binding a privileged port, which must not be retried as auth failures.
+var authPatterns = []string{
+	"unauthorized",
+	"authentication",
+	"token",
+	"credential",
+	"forbidden",
+	"invalid user",
+	"access denied",
+	"unable to load aws credentials",
+	"expired",
+	"sso",
+	"login",
+	"oauth",
+	"authoriz",
+	"invalid_grant",
+	"getting credentials",
+	"refresh failed",
+	"executable aws failed",
+	"unable to connect to the server",
+}
+
+// networkPatterns are lower-case fragments of connectivity failures
+var networkPatterns = []string{
+	"connection refused",
+	"timeout",
+	"network",
+	"no route to host",
+	"connection timed out",
+	"dial tcp",
+	"i/o timeout",
+}
+
+// notFoundPatterns are lower-case fragments of missing resources or executables
+var notFoundPatterns = []string{
+	"not found",
+	"executable file not found",
+	"no such file or directory",
+}
+
+// Classify returns err tagged with its category, detected from the message when
+// it is not already categorised. Unrecognised errors are returned unchanged.
+// Authentication is checked first because kubectl often reports expired
+// credentials together with network-sounding text.
+func Classify(err error) error {
+	if err == nil || Kind(err) != nil {
+		return err
+	}
+
+	message := strings.ToLower(err.Error())
+	switch {
+	case containsAny(message, authPatterns):
+		return WithKind(ErrAuth, err)
+	case containsAny(message, networkPatterns):
+		return WithKind(ErrNetwork, err)
+	case containsAny(message, notFoundPatterns):
+		return WithKind(ErrNotFound, err)
+	}
+	return err
+}
+
+// Kind returns the category of err, or nil if it has none
+func Kind(err error) error {
+	for _, kind := range []error{ErrAuth, ErrNetwork, ErrNotFound, ErrPortConflict, ErrDockerUnavailable} {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// kindNames are the names of the error categories in structured logs
+var kindNames = map[error]string{
+	ErrAuth:              "auth",
+	ErrNetwork:           "network",
+	ErrNotFound:          "not_found",
+	ErrPortConflict:      "port_conflict",
+	ErrDockerUnavailable: "docker_unavailable",
+}
+
+// KindName returns the name of the category of err, detected like Classify does, or
+// "other" if it has none
+func KindName(err error) string {
+	if kind := Kind(Classify(err)); kind != nil {
+		return kindNames[kind]
+	}
+	return "other"
+}
+
+// IsAuth reports whether err is, or looks like, an authentication failure
+func IsAuth(err error) bool {
+	return errors.Is(Classify(err), ErrAuth)
+}
+
+// IsNetwork reports whether err is, or looks like, a network failure
+func IsNetwork(err error) bool {
+	return errors.Is(Classify(err), ErrNetwork)
+}
+
+func containsAny(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(s, pattern) {
+			return true
+		}
+	}
+	return false
+}
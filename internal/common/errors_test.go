@@ -0,0 +1,66 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"nil", nil, nil},
+		{"auth", errors.New("error: You must be logged in to the server (Unauthorized)"), ErrAuth},
+		{"expired sso", errors.New("the SSO session has expired"), ErrAuth},
+		{"oauth", errors.New("oauth2: cannot fetch token: 400 Bad Request"), ErrAuth},
+		{"privileged port", errors.New("listen tcp4 127.0.0.1:80: bind: permission denied"), nil},
+		{"session closed", errors.New("lost connection to pod: session closed"), nil},
+		{"network", errors.New("dial tcp 10.0.0.1:443: connect: connection refused"), ErrNetwork},
+		{"not found", errors.New(`Error from server (NotFound): services "api" not found`), ErrNotFound},
+		{"unknown", errors.New("something else"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if kind := Kind(Classify(tt.err)); kind != tt.expected {
+				t.Errorf("Kind(Classify(%v)) = %v, expected %v", tt.err, kind, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWithKindPreservesMessageAndCause(t *testing.T) {
+	cause := errors.New("bind: address already in use")
+	err := fmt.Errorf("starting api: %w", WithKind(ErrPortConflict, cause))
+
+	if !errors.Is(err, ErrPortConflict) {
+		t.Error("Expected wrapped error to match ErrPortConflict")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected wrapped error to still match its cause")
+	}
+	if err.Error() != "starting api: bind: address already in use" {
+		t.Errorf("Unexpected message: %s", err.Error())
+	}
+
+	// Explicit categories win over message-based detection
+	docker := WithKind(ErrDockerUnavailable, errors.New("connection refused"))
+	if Kind(Classify(docker)) != ErrDockerUnavailable {
+		t.Error("Expected explicit category to be kept by Classify")
+	}
+}
+
+func TestKindName(t *testing.T) {
+	if name := KindName(errors.New("error: You must be logged in to the server (Unauthorized)")); name != "auth" {
+		t.Errorf("Expected auth, got %s", name)
+	}
+	if name := KindName(WithKind(ErrPortConflict, errors.New("in use"))); name != "port_conflict" {
+		t.Errorf("Expected port_conflict, got %s", name)
+	}
+	if name := KindName(errors.New("something else")); name != "other" {
+		t.Errorf("Expected other, got %s", name)
+	}
+}
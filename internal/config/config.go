@@ -5,45 +5,145 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 
+	"github.com/victorkazakov/kportforward/internal/utils"
 	"gopkg.in/yaml.v3"
 )
 
-// LoadConfig loads and merges configuration from embedded defaults and user config
+// LoadConfig loads and merges configuration from the defaults, the user config and the
+// project config in the working directory, see ConfigLayers
 func LoadConfig() (*Config, error) {
-	// Load default config: try remote → cached → embedded fallback
-	defaultYAML, err := loadDefaultsWithRemote()
+	layers, err := ConfigLayers()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load default config: %w", err)
+		return nil, err
 	}
+	return mergeConfigLayers(layers)
+}
 
-	config := &Config{}
-	if err := yaml.Unmarshal(defaultYAML, config); err != nil {
-		return nil, fmt.Errorf("failed to parse default config: %w", err)
+// finalizeConfig applies post-merge processing shared by all loaders
+func finalizeConfig(config *Config) (*Config, error) {
+	if err := expandTemplates(config); err != nil {
+		return nil, fmt.Errorf("failed to expand service templates: %w", err)
 	}
-
-	// Try to load user config and merge if it exists
-	userConfigPath, err := getUserConfigPath()
-	if err != nil {
-		return config, nil // Return default config if we can't determine user config path
+	expandVariables(config)
+	if err := normalizePorts(config); err != nil {
+		return nil, err
+	}
+	if err := validateExposure(config); err != nil {
+		return nil, err
+	}
+	if err := applyServiceTypes(config); err != nil {
+		return nil, err
+	}
+	if err := validateSchedules(config); err != nil {
+		return nil, err
+	}
+	if err := validateHealthChecks(config); err != nil {
+		return nil, err
+	}
+	if err := validateKubectlOptions(config); err != nil {
+		return nil, err
+	}
+	if err := validateLinks(config); err != nil {
+		return nil, err
+	}
+	if err := validateUIEnv(config); err != nil {
+		return nil, err
 	}
+	if err := validateSelectors(config); err != nil {
+		return nil, err
+	}
+	if err := validatePortOffsets(config); err != nil {
+		return nil, err
+	}
+	if err := validateMonitoringIntervals(config); err != nil {
+		return nil, err
+	}
+	if err := validateUIOptions(config); err != nil {
+		return nil, err
+	}
+	if err := validateRestartStagger(config); err != nil {
+		return nil, err
+	}
+	if err := validateGroups(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
 
-	if _, err := os.Stat(userConfigPath); os.IsNotExist(err) {
-		return config, nil // Return default config if user config doesn't exist
+// validateMonitoringIntervals rejects negative monitoring intervals
+func validateMonitoringIntervals(config *Config) error {
+	if config == nil {
+		return nil
 	}
+	if config.MonitoringIntervals.Connecting < 0 || config.MonitoringIntervals.Running < 0 {
+		return fmt.Errorf("negative monitoringIntervals")
+	}
+	return nil
+}
 
-	userConfig, err := loadUserConfig(userConfigPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load user config: %w", err)
+// validateUIOptions rejects unknown uptime and timestamp formats
+func validateUIOptions(config *Config) error {
+	if config == nil {
+		return nil
+	}
+	if !utils.IsValidUptimeFormat(config.UIOptions.UptimeFormat) {
+		return fmt.Errorf("unknown uiOptions.uptimeFormat %q (expected compact, hours, full or iso)", config.UIOptions.UptimeFormat)
+	}
+	if !utils.IsValidTimestampFormat(config.UIOptions.TimestampFormat) {
+		return fmt.Errorf("unknown uiOptions.timestampFormat %q (expected 24h, 12h or iso)", config.UIOptions.TimestampFormat)
+	}
+	if config.UIOptions.RefreshRate < 0 {
+		return fmt.Errorf("negative uiOptions.refreshRate")
+	}
+	if !IsValidTheme(config.UIOptions.Theme) {
+		return fmt.Errorf("unknown uiOptions.theme %q (expected dark, light or no-color)", config.UIOptions.Theme)
+	}
+	switch config.UIOptions.Alert {
+	case "", AlertOff, AlertBell, AlertFlash, AlertBoth:
+	default:
+		return fmt.Errorf("unknown uiOptions.alert %q (expected off, bell, flash or both)", config.UIOptions.Alert)
 	}
+	if !IsValidUpdateChannel(config.Updates.Channel) {
+		return fmt.Errorf("unknown updates.channel %q (expected stable or beta)", config.Updates.Channel)
+	}
+	if config.Updates.CheckInterval < 0 {
+		return fmt.Errorf("negative updates.checkInterval")
+	}
+	return nil
+}
+
+// ConfigEnvVar names a config file to use instead of the default one
+const ConfigEnvVar = "KPORTFORWARD_CONFIG"
+
+// configPath holds the config file selected with the --config flag
+var configPath string
 
-	// Merge user config into default config
-	mergedConfig := mergeConfigs(config, userConfig)
-	return mergedConfig, nil
+// SetConfigPath selects the config file to use instead of the default one; it takes
+// precedence over $KPORTFORWARD_CONFIG. Pass "" to fall back to the environment.
+func SetConfigPath(path string) {
+	configPath = path
 }
 
-// getUserConfigPath returns the appropriate config path for the current platform
+// explicitConfigPath returns the config file selected by flag or environment, if any
+func explicitConfigPath() string {
+	if configPath != "" {
+		return configPath
+	}
+	return os.Getenv(ConfigEnvVar)
+}
+
+// getUserConfigPath returns the selected config file, or the default one of the platform
 func getUserConfigPath() (string, error) {
+	if path := explicitConfigPath(); path != "" {
+		return path, nil
+	}
+	return getDefaultConfigPath()
+}
+
+// getDefaultConfigPath returns the appropriate config path for the current platform
+func getDefaultConfigPath() (string, error) {
 	var configDir string
 
 	switch runtime.GOOS {
@@ -82,9 +182,20 @@ func loadUserConfig(path string) (*Config, error) {
 // User config takes precedence for individual services and settings
 func mergeConfigs(defaultConfig, userConfig *Config) *Config {
 	merged := &Config{
-		PortForwards:       make(map[string]Service),
-		MonitoringInterval: defaultConfig.MonitoringInterval,
-		UIOptions:          defaultConfig.UIOptions,
+		PortForwards:        make(map[string]Service),
+		Templates:           make(map[string]Service),
+		MonitoringInterval:  defaultConfig.MonitoringInterval,
+		MonitoringIntervals: defaultConfig.MonitoringIntervals,
+		IdleTimeout:         defaultConfig.IdleTimeout,
+		RestartStorm:        defaultConfig.RestartStorm,
+		RestartStagger:      defaultConfig.RestartStagger,
+		Hooks:               defaultConfig.Hooks,
+		PortOffsets:         defaultConfig.PortOffsets,
+		Groups:              defaultConfig.Groups,
+		ServiceTypes:        defaultConfig.ServiceTypes,
+		Updates:             defaultConfig.Updates,
+		PortForwardBackend:  defaultConfig.PortForwardBackend,
+		UIOptions:           defaultConfig.UIOptions,
 	}
 
 	// Start with default port forwards
@@ -92,33 +203,142 @@ func mergeConfigs(defaultConfig, userConfig *Config) *Config {
 		merged.PortForwards[name] = service
 	}
 
-	// Override with user port forwards (additive)
-	if userConfig.PortForwards != nil {
-		for name, service := range userConfig.PortForwards {
-			merged.PortForwards[name] = service
-		}
+	// Templates are merged like port forwards: user templates replace defaults by name
+	for name, template := range defaultConfig.Templates {
+		merged.Templates[name] = template
 	}
+	overrideConfig(merged, userConfig)
 
-	// Override monitoring interval if specified by user
-	if userConfig.MonitoringInterval != 0 {
-		merged.MonitoringInterval = userConfig.MonitoringInterval
+	for name, service := range merged.PortForwards {
+		if service.Disabled {
+			delete(merged.PortForwards, name)
+			// Only the user can enable their services again, see SetServiceDisabled
+			if userConfig.PortForwards[name].Disabled {
+				merged.Disabled = append(merged.Disabled, name)
+			}
+		}
 	}
+	sort.Strings(merged.Disabled)
+
+	return merged
+}
 
-	// Override UI options if specified by user
-	if userConfig.UIOptions.RefreshRate != 0 {
-		merged.UIOptions.RefreshRate = userConfig.UIOptions.RefreshRate
+// overrideConfig applies the services and settings set in override over those of merged;
+// services and templates replace those of the same name
+func overrideConfig(merged, override *Config) {
+	for name, template := range override.Templates {
+		merged.Templates[name] = template
 	}
-	if userConfig.UIOptions.Theme != "" {
-		merged.UIOptions.Theme = userConfig.UIOptions.Theme
+
+	// Override port forwards (additive)
+	if override.PortForwards != nil {
+		for name, service := range override.PortForwards {
+			merged.PortForwards[name] = service
+		}
 	}
 
-	for name, service := range merged.PortForwards {
-		if service.Disabled {
-			delete(merged.PortForwards, name)
+	// Override monitoring interval if specified
+	if override.MonitoringInterval != 0 {
+		merged.MonitoringInterval = override.MonitoringInterval
+	}
+	if override.MonitoringIntervals.Connecting != 0 {
+		merged.MonitoringIntervals.Connecting = override.MonitoringIntervals.Connecting
+	}
+	if override.MonitoringIntervals.Running != 0 {
+		merged.MonitoringIntervals.Running = override.MonitoringIntervals.Running
+	}
+	if override.IdleTimeout != 0 {
+		merged.IdleTimeout = override.IdleTimeout
+	}
+	if override.PortForwardBackend != "" {
+		merged.PortForwardBackend = override.PortForwardBackend
+	}
+	if override.Updates.Channel != "" {
+		merged.Updates.Channel = override.Updates.Channel
+	}
+	if override.Updates.CheckInterval != 0 {
+		merged.Updates.CheckInterval = override.Updates.CheckInterval
+	}
+	if override.Updates.Disabled {
+		merged.Updates.Disabled = true
+	}
+	if override.RestartStorm.Threshold != 0 {
+		merged.RestartStorm.Threshold = override.RestartStorm.Threshold
+	}
+	if override.RestartStorm.Window != 0 {
+		merged.RestartStorm.Window = override.RestartStorm.Window
+	}
+	if override.RestartStagger.Interval != 0 {
+		merged.RestartStagger.Interval = override.RestartStagger.Interval
+	}
+	if override.RestartStagger.BatchSize != 0 {
+		merged.RestartStagger.BatchSize = override.RestartStagger.BatchSize
+	}
+	if override.RestartStagger.Order != "" {
+		merged.RestartStagger.Order = override.RestartStagger.Order
+	}
+	if override.Hooks.OnFailed != "" {
+		merged.Hooks.OnFailed = override.Hooks.OnFailed
+	}
+	if override.Hooks.OnRecovered != "" {
+		merged.Hooks.OnRecovered = override.Hooks.OnRecovered
+	}
+	if override.Hooks.OnSuspended != "" {
+		merged.Hooks.OnSuspended = override.Hooks.OnSuspended
+	}
+	if override.Hooks.OnRunning != "" {
+		merged.Hooks.OnRunning = override.Hooks.OnRunning
+	}
+	if len(override.PortOffsets) > 0 {
+		offsets := make(map[string]int, len(merged.PortOffsets)+len(override.PortOffsets))
+		for kubeContext, offset := range merged.PortOffsets {
+			offsets[kubeContext] = offset
+		}
+		for kubeContext, offset := range override.PortOffsets {
+			offsets[kubeContext] = offset
+		}
+		merged.PortOffsets = offsets
+	}
+	if len(override.Groups) > 0 {
+		groups := make(map[string][]string, len(merged.Groups)+len(override.Groups))
+		for name, services := range merged.Groups {
+			groups[name] = services
+		}
+		for name, services := range override.Groups {
+			groups[name] = services
 		}
+		merged.Groups = groups
+	}
+	if len(override.ServiceTypes) > 0 {
+		serviceTypes := make(map[string]ServiceType, len(merged.ServiceTypes)+len(override.ServiceTypes))
+		for name, serviceType := range merged.ServiceTypes {
+			serviceTypes[name] = serviceType
+		}
+		for name, serviceType := range override.ServiceTypes {
+			serviceTypes[name] = serviceType
+		}
+		merged.ServiceTypes = serviceTypes
 	}
 
-	return merged
+	// Override UI options if specified
+	if override.UIOptions.RefreshRate != 0 {
+		merged.UIOptions.RefreshRate = override.UIOptions.RefreshRate
+	}
+	if override.UIOptions.Theme != "" {
+		merged.UIOptions.Theme = override.UIOptions.Theme
+	}
+	if override.UIOptions.UptimeFormat != "" {
+		merged.UIOptions.UptimeFormat = override.UIOptions.UptimeFormat
+	}
+	if override.UIOptions.TimestampFormat != "" {
+		merged.UIOptions.TimestampFormat = override.UIOptions.TimestampFormat
+	}
+	if override.UIOptions.Alert != "" {
+		merged.UIOptions.Alert = override.UIOptions.Alert
+	}
+	if override.UIOptions.IssueThreshold != 0 {
+		merged.UIOptions.IssueThreshold = override.UIOptions.IssueThreshold
+	}
 }
 
 // CreateUserConfigDir creates the user config directory if it doesn't exist
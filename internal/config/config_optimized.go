@@ -1,6 +1,7 @@
 package config
 
 import (
+	"sort"
 	"sync"
 	"time"
 
@@ -64,6 +65,9 @@ func (ocl *OptimizedConfigLoader) LoadConfigOptimized() (*Config, error) {
 	userConfig, err := ocl.getUserConfigOptimized()
 	if err != nil {
 		// Return default config if user config fails
+		if _, err := finalizeConfig(defaultConfig); err != nil {
+			return nil, err
+		}
 		ocl.cache.config = defaultConfig
 		ocl.cache.loadTime = time.Now()
 		return defaultConfig, nil
@@ -71,6 +75,9 @@ func (ocl *OptimizedConfigLoader) LoadConfigOptimized() (*Config, error) {
 
 	// Merge configs
 	merged := ocl.mergeConfigsOptimized(defaultConfig, userConfig)
+	if _, err := finalizeConfig(merged); err != nil {
+		return nil, err
+	}
 
 	ocl.cache.config = merged
 	ocl.cache.loadTime = time.Now()
@@ -143,9 +150,20 @@ func (ocl *OptimizedConfigLoader) mergeConfigsOptimized(defaultConfig, userConfi
 	}
 
 	merged := &Config{
-		PortForwards:       make(map[string]Service, totalServices),
-		MonitoringInterval: defaultConfig.MonitoringInterval,
-		UIOptions:          defaultConfig.UIOptions,
+		PortForwards:        make(map[string]Service, totalServices),
+		Templates:           make(map[string]Service, len(defaultConfig.Templates)+len(userConfig.Templates)),
+		MonitoringInterval:  defaultConfig.MonitoringInterval,
+		MonitoringIntervals: defaultConfig.MonitoringIntervals,
+		IdleTimeout:         defaultConfig.IdleTimeout,
+		RestartStorm:        defaultConfig.RestartStorm,
+		RestartStagger:      defaultConfig.RestartStagger,
+		Hooks:               defaultConfig.Hooks,
+		PortOffsets:         defaultConfig.PortOffsets,
+		Groups:              defaultConfig.Groups,
+		ServiceTypes:        defaultConfig.ServiceTypes,
+		Updates:             defaultConfig.Updates,
+		PortForwardBackend:  defaultConfig.PortForwardBackend,
+		UIOptions:           defaultConfig.UIOptions,
 	}
 
 	// Copy default port forwards
@@ -153,6 +171,14 @@ func (ocl *OptimizedConfigLoader) mergeConfigsOptimized(defaultConfig, userConfi
 		merged.PortForwards[name] = service
 	}
 
+	// Merge templates, user templates win
+	for name, template := range defaultConfig.Templates {
+		merged.Templates[name] = template
+	}
+	for name, template := range userConfig.Templates {
+		merged.Templates[name] = template
+	}
+
 	// Add/override with user port forwards
 	if userConfig.PortForwards != nil {
 		for name, service := range userConfig.PortForwards {
@@ -164,6 +190,84 @@ func (ocl *OptimizedConfigLoader) mergeConfigsOptimized(defaultConfig, userConfi
 	if userConfig.MonitoringInterval != 0 {
 		merged.MonitoringInterval = userConfig.MonitoringInterval
 	}
+	if userConfig.MonitoringIntervals.Connecting != 0 {
+		merged.MonitoringIntervals.Connecting = userConfig.MonitoringIntervals.Connecting
+	}
+	if userConfig.MonitoringIntervals.Running != 0 {
+		merged.MonitoringIntervals.Running = userConfig.MonitoringIntervals.Running
+	}
+	if userConfig.IdleTimeout != 0 {
+		merged.IdleTimeout = userConfig.IdleTimeout
+	}
+	if userConfig.PortForwardBackend != "" {
+		merged.PortForwardBackend = userConfig.PortForwardBackend
+	}
+	if userConfig.Updates.Channel != "" {
+		merged.Updates.Channel = userConfig.Updates.Channel
+	}
+	if userConfig.Updates.CheckInterval != 0 {
+		merged.Updates.CheckInterval = userConfig.Updates.CheckInterval
+	}
+	if userConfig.Updates.Disabled {
+		merged.Updates.Disabled = true
+	}
+	if userConfig.RestartStorm.Threshold != 0 {
+		merged.RestartStorm.Threshold = userConfig.RestartStorm.Threshold
+	}
+	if userConfig.RestartStorm.Window != 0 {
+		merged.RestartStorm.Window = userConfig.RestartStorm.Window
+	}
+	if userConfig.RestartStagger.Interval != 0 {
+		merged.RestartStagger.Interval = userConfig.RestartStagger.Interval
+	}
+	if userConfig.RestartStagger.BatchSize != 0 {
+		merged.RestartStagger.BatchSize = userConfig.RestartStagger.BatchSize
+	}
+	if userConfig.RestartStagger.Order != "" {
+		merged.RestartStagger.Order = userConfig.RestartStagger.Order
+	}
+	if userConfig.Hooks.OnFailed != "" {
+		merged.Hooks.OnFailed = userConfig.Hooks.OnFailed
+	}
+	if userConfig.Hooks.OnRecovered != "" {
+		merged.Hooks.OnRecovered = userConfig.Hooks.OnRecovered
+	}
+	if userConfig.Hooks.OnSuspended != "" {
+		merged.Hooks.OnSuspended = userConfig.Hooks.OnSuspended
+	}
+	if userConfig.Hooks.OnRunning != "" {
+		merged.Hooks.OnRunning = userConfig.Hooks.OnRunning
+	}
+	if len(userConfig.PortOffsets) > 0 {
+		offsets := make(map[string]int, len(merged.PortOffsets)+len(userConfig.PortOffsets))
+		for kubeContext, offset := range merged.PortOffsets {
+			offsets[kubeContext] = offset
+		}
+		for kubeContext, offset := range userConfig.PortOffsets {
+			offsets[kubeContext] = offset
+		}
+		merged.PortOffsets = offsets
+	}
+	if len(userConfig.Groups) > 0 {
+		groups := make(map[string][]string, len(merged.Groups)+len(userConfig.Groups))
+		for name, services := range merged.Groups {
+			groups[name] = services
+		}
+		for name, services := range userConfig.Groups {
+			groups[name] = services
+		}
+		merged.Groups = groups
+	}
+	if len(userConfig.ServiceTypes) > 0 {
+		serviceTypes := make(map[string]ServiceType, len(merged.ServiceTypes)+len(userConfig.ServiceTypes))
+		for name, serviceType := range merged.ServiceTypes {
+			serviceTypes[name] = serviceType
+		}
+		for name, serviceType := range userConfig.ServiceTypes {
+			serviceTypes[name] = serviceType
+		}
+		merged.ServiceTypes = serviceTypes
+	}
 
 	if userConfig.UIOptions.RefreshRate != 0 {
 		merged.UIOptions.RefreshRate = userConfig.UIOptions.RefreshRate
@@ -171,12 +275,29 @@ func (ocl *OptimizedConfigLoader) mergeConfigsOptimized(defaultConfig, userConfi
 	if userConfig.UIOptions.Theme != "" {
 		merged.UIOptions.Theme = userConfig.UIOptions.Theme
 	}
+	if userConfig.UIOptions.UptimeFormat != "" {
+		merged.UIOptions.UptimeFormat = userConfig.UIOptions.UptimeFormat
+	}
+	if userConfig.UIOptions.TimestampFormat != "" {
+		merged.UIOptions.TimestampFormat = userConfig.UIOptions.TimestampFormat
+	}
+	if userConfig.UIOptions.Alert != "" {
+		merged.UIOptions.Alert = userConfig.UIOptions.Alert
+	}
+	if userConfig.UIOptions.IssueThreshold != 0 {
+		merged.UIOptions.IssueThreshold = userConfig.UIOptions.IssueThreshold
+	}
 
 	for name, service := range merged.PortForwards {
 		if service.Disabled {
 			delete(merged.PortForwards, name)
+			// Only the user can enable their services again, see SetServiceDisabled
+			if userConfig.PortForwards[name].Disabled {
+				merged.Disabled = append(merged.Disabled, name)
+			}
 		}
 	}
+	sort.Strings(merged.Disabled)
 
 	return merged
 }
@@ -188,14 +309,50 @@ func (ocl *OptimizedConfigLoader) copyConfig(original *Config) *Config {
 	}
 
 	copy := &Config{
-		PortForwards:       make(map[string]Service, len(original.PortForwards)),
-		MonitoringInterval: original.MonitoringInterval,
-		UIOptions:          original.UIOptions,
+		PortForwards:        make(map[string]Service, len(original.PortForwards)),
+		Templates:           make(map[string]Service, len(original.Templates)),
+		MonitoringInterval:  original.MonitoringInterval,
+		MonitoringIntervals: original.MonitoringIntervals,
+		IdleTimeout:         original.IdleTimeout,
+		RestartStorm:        original.RestartStorm,
+		RestartStagger:      original.RestartStagger,
+		Hooks:               original.Hooks,
+		UIOptions:           original.UIOptions,
+		Updates:             original.Updates,
+		PortForwardBackend:  original.PortForwardBackend,
 	}
 
 	for name, service := range original.PortForwards {
 		copy.PortForwards[name] = service
 	}
+	for name, template := range original.Templates {
+		copy.Templates[name] = template
+	}
+	if original.PortOffsets != nil {
+		copy.PortOffsets = make(map[string]int, len(original.PortOffsets))
+		for kubeContext, offset := range original.PortOffsets {
+			copy.PortOffsets[kubeContext] = offset
+		}
+	}
+	copy.Disabled = append([]string(nil), original.Disabled...)
+	if original.Skipped != nil {
+		copy.Skipped = make(map[string]string, len(original.Skipped))
+		for name, reason := range original.Skipped {
+			copy.Skipped[name] = reason
+		}
+	}
+	if original.Groups != nil {
+		copy.Groups = make(map[string][]string, len(original.Groups))
+		for name, services := range original.Groups {
+			copy.Groups[name] = append([]string(nil), services...)
+		}
+	}
+	if original.ServiceTypes != nil {
+		copy.ServiceTypes = make(map[string]ServiceType, len(original.ServiceTypes))
+		for name, serviceType := range original.ServiceTypes {
+			copy.ServiceTypes[name] = serviceType
+		}
+	}
 
 	return copy
 }
@@ -1,6 +1,10 @@
 package config
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -204,3 +208,58 @@ func TestDisabledServiceDeletionOptimized(t *testing.T) {
 		t.Errorf("expected 1 service after merge, got %d", len(merged.PortForwards))
 	}
 }
+
+func TestExplicitConfigPath(t *testing.T) {
+	SetRemoteConfigURL("")
+	defer SetRemoteConfigURL(DefaultRemoteConfigURL)
+	defer SetConfigPath("")
+
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "env.yaml")
+	flagFile := filepath.Join(dir, "flag.yaml")
+	writeConfig := func(path, service string, port int) {
+		content := fmt.Sprintf("portForwards:\n  %s:\n    target: service/%s\n    targetPort: 80\n    localPort: %d\n    namespace: default\n    type: web\n", service, service, port)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(envFile, "from-env", 18080)
+	writeConfig(flagFile, "from-flag", 18081)
+
+	t.Setenv(ConfigEnvVar, envFile)
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config from $%s: %v", ConfigEnvVar, err)
+	}
+	if _, ok := cfg.PortForwards["from-env"]; !ok {
+		t.Errorf("Expected the service of $%s, got %v", ConfigEnvVar, cfg.PortForwards)
+	}
+
+	// The flag wins over the environment
+	SetConfigPath(flagFile)
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config from --config: %v", err)
+	}
+	if _, ok := cfg.PortForwards["from-flag"]; !ok {
+		t.Errorf("Expected the service of --config, got %v", cfg.PortForwards)
+	}
+	if _, ok := cfg.PortForwards["from-env"]; ok {
+		t.Error("Expected $KPORTFORWARD_CONFIG to be ignored when --config is set")
+	}
+
+	// Unlike the default file, a selected one has to exist
+	SetConfigPath(filepath.Join(dir, "missing.yaml"))
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected an error for a missing config file, got %v", err)
+	}
+
+	// Profiles stay in the config directory
+	profiles, err := ProfilesDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(profiles) == dir {
+		t.Errorf("Expected profiles outside the selected file's directory, got %s", profiles)
+	}
+}
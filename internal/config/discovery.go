@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// Annotations on Kubernetes Services read by kportforward discover
+const (
+	DiscoveryAnnotationPrefix = "kportforward.catio.tech/"
+
+	AnnotationEnable    = DiscoveryAnnotationPrefix + "enable"     // "true" to discover the service
+	AnnotationName      = DiscoveryAnnotationPrefix + "name"       // Entry name (default: the service's name)
+	AnnotationPort      = DiscoveryAnnotationPrefix + "port"       // Port to forward, by number or name (default: every TCP port)
+	AnnotationLocalPort = DiscoveryAnnotationPrefix + "local-port" // Local port (default: the service port, plus 8000 below 1024)
+	AnnotationType      = DiscoveryAnnotationPrefix + "type"       // rest, rpc, web or other (default: guessed)
+)
+
+// DiscoveryResult holds the config entries generated for cluster Services
+type DiscoveryResult struct {
+	Services map[string]Service // New entries
+	Existing []string           // Entries already in the config, which are left alone
+	Skipped  []string           // Services that cannot be forwarded, as "namespace/name: reason"
+}
+
+// DiscoverServices generates config entries for cluster Services. Unless selected is
+// set, because the services were picked by a label selector, only those annotated with
+// kportforward.catio.tech/enable: "true" are used. Local ports avoid those of the
+// configured services and of each other.
+func DiscoverServices(services []utils.ClusterService, configured map[string]Service, selected bool) DiscoveryResult {
+	result := DiscoveryResult{Services: make(map[string]Service)}
+
+	usedPorts := make(map[int]bool)
+	for _, service := range configured {
+		usedPorts[service.LocalPort] = true
+		for _, port := range service.Ports {
+			usedPorts[port.Local] = true
+		}
+	}
+	freePort := func(port int) int {
+		for usedPorts[port] {
+			port++
+		}
+		usedPorts[port] = true
+		return port
+	}
+
+	// Services named alike in several namespaces keep the name in the first namespace
+	sorted := append([]utils.ClusterService(nil), services...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	for _, cluster := range sorted {
+		annotations := cluster.Annotations
+		if !selected && !strings.EqualFold(annotations[AnnotationEnable], "true") {
+			continue
+		}
+		skip := func(reason string) {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s/%s: %s", cluster.Namespace, cluster.Name, reason))
+		}
+
+		ports, err := discoveredPorts(cluster)
+		if err != nil {
+			skip(err.Error())
+			continue
+		}
+
+		name := cluster.Name
+		if annotations[AnnotationName] != "" {
+			name = annotations[AnnotationName]
+		}
+		if _, exists := configured[name]; exists {
+			result.Existing = append(result.Existing, name)
+			continue
+		}
+		if _, exists := result.Services[name]; exists {
+			name += "-" + cluster.Namespace
+		}
+
+		localPort := defaultLocalPort(ports[0])
+		if value := annotations[AnnotationLocalPort]; value != "" {
+			if localPort, err = strconv.Atoi(value); err != nil || localPort <= 0 || localPort > 65535 {
+				skip(fmt.Sprintf("invalid %s %q", AnnotationLocalPort, value))
+				continue
+			}
+		}
+
+		serviceType := annotations[AnnotationType]
+		if serviceType == "" {
+			serviceType = GuessServiceType(name, ports[0])
+		}
+		service := Service{
+			Target:     "service/" + cluster.Name,
+			Namespace:  cluster.Namespace,
+			TargetPort: ports[0],
+			LocalPort:  freePort(localPort),
+			Type:       serviceType,
+		}
+		for _, port := range ports[1:] {
+			service.Ports = append(service.Ports, PortMapping{Local: freePort(defaultLocalPort(port)), Target: port})
+		}
+		result.Services[name] = service
+	}
+	return result
+}
+
+// discoveredPorts returns the ports of a service to forward: the annotated one, or
+// every TCP port
+func discoveredPorts(service utils.ClusterService) ([]int, error) {
+	var tcpPorts []utils.ServicePort
+	for _, port := range service.Ports {
+		// kubectl port-forward only forwards TCP; an empty protocol means TCP
+		if port.Protocol == "" || strings.EqualFold(port.Protocol, "TCP") {
+			tcpPorts = append(tcpPorts, port)
+		}
+	}
+
+	annotated := service.Annotations[AnnotationPort]
+	if annotated == "" {
+		if len(tcpPorts) == 0 {
+			return nil, fmt.Errorf("no TCP ports")
+		}
+		ports := make([]int, 0, len(tcpPorts))
+		for _, port := range tcpPorts {
+			ports = append(ports, port.Port)
+		}
+		return ports, nil
+	}
+
+	for _, port := range tcpPorts {
+		if port.Name == annotated || strconv.Itoa(port.Port) == annotated {
+			return []int{port.Port}, nil
+		}
+	}
+	return nil, fmt.Errorf("no TCP port %q for %s", annotated, AnnotationPort)
+}
+
+// defaultLocalPort returns the local port for a service port, moving privileged ports
+// to 8000 and up so no root rights are needed
+func defaultLocalPort(port int) int {
+	if port < 1024 {
+		return port + 8000
+	}
+	return port
+}
+
+// DiscoveryNamespaces returns the namespaces of the configured services, sorted, or
+// "default" if there are none
+func DiscoveryNamespaces(services map[string]Service) []string {
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, service := range services {
+		if service.Namespace != "" && !seen[service.Namespace] {
+			seen[service.Namespace] = true
+			namespaces = append(namespaces, service.Namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		return []string{"default"}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestDiscoverServices(t *testing.T) {
+	enabled := map[string]string{AnnotationEnable: "true"}
+	services := []utils.ClusterService{
+		{Name: "api", Namespace: "team", Annotations: enabled, Ports: []utils.ServicePort{
+			{Name: "http", Port: 80, Protocol: "TCP"},
+			{Name: "metrics", Port: 9100, Protocol: "TCP"},
+			{Name: "dns", Port: 53, Protocol: "UDP"},
+		}},
+		{Name: "api", Namespace: "other", Annotations: map[string]string{
+			AnnotationEnable:    "true",
+			AnnotationPort:      "grpc",
+			AnnotationLocalPort: "9090",
+		}, Ports: []utils.ServicePort{{Name: "http", Port: 8080}, {Name: "grpc", Port: 9090}}},
+		{Name: "billing", Namespace: "team", Annotations: map[string]string{
+			AnnotationEnable: "true",
+			AnnotationName:   "payments",
+			AnnotationType:   "rpc",
+		}, Ports: []utils.ServicePort{{Port: 8080}}},
+		{Name: "postgres", Namespace: "team", Annotations: enabled, Ports: []utils.ServicePort{{Port: 5432}}},
+		{Name: "syslog", Namespace: "team", Annotations: enabled, Ports: []utils.ServicePort{{Port: 514, Protocol: "UDP"}}},
+		{Name: "internal", Namespace: "team", Ports: []utils.ServicePort{{Port: 8080}}},
+	}
+	configured := map[string]Service{
+		"postgres": {Target: "service/postgres", Namespace: "team", LocalPort: 5432},
+		"grafana":  {LocalPort: 8080},
+	}
+
+	result := DiscoverServices(services, configured, false)
+	expected := map[string]Service{
+		"api": {Target: "service/api", Namespace: "other", TargetPort: 9090, LocalPort: 9090, Type: "rpc"},
+		"api-team": {Target: "service/api", Namespace: "team", TargetPort: 80, LocalPort: 8081, Type: "rest",
+			Ports: []PortMapping{{Local: 9100, Target: 9100}}},
+		"payments": {Target: "service/billing", Namespace: "team", TargetPort: 8080, LocalPort: 8082, Type: "rpc"},
+	}
+	if !reflect.DeepEqual(result.Services, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result.Services)
+	}
+	if !reflect.DeepEqual(result.Existing, []string{"postgres"}) {
+		t.Errorf("Expected postgres to be left alone, got %v", result.Existing)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != "team/syslog: no TCP ports" {
+		t.Errorf("Expected syslog to be skipped, got %v", result.Skipped)
+	}
+
+	// Services picked by a label selector need no annotation
+	result = DiscoverServices(services[5:], configured, true)
+	if _, ok := result.Services["internal"]; !ok {
+		t.Errorf("Expected the selected service without annotation, got %+v", result.Services)
+	}
+}
+
+func TestDiscoveryNamespaces(t *testing.T) {
+	namespaces := DiscoveryNamespaces(map[string]Service{
+		"api": {Namespace: "team"},
+		"web": {Namespace: "frontend"},
+		"db":  {Namespace: "team"},
+	})
+	if !reflect.DeepEqual(namespaces, []string{"frontend", "team"}) {
+		t.Errorf("Expected the configured namespaces, got %v", namespaces)
+	}
+	if namespaces := DiscoveryNamespaces(nil); !reflect.DeepEqual(namespaces, []string{"default"}) {
+		t.Errorf("Expected the default namespace without services, got %v", namespaces)
+	}
+}
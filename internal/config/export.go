@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Export formats for describing forwarded endpoints to other tools
+const (
+	ExportCompose    = "compose"    // docker-compose environment and extra_hosts block
+	ExportKubernetes = "kubernetes" // container env list
+	ExportDotenv     = "dotenv"     // KEY=value file, usable with telepresence --env-file
+)
+
+// DockerHostGateway is the hostname containers use to reach ports forwarded on the host
+const DockerHostGateway = "host.docker.internal"
+
+// EnvVar is a single exported environment variable
+type EnvVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// EndpointEnv returns HOST, PORT, PORT_<target> for further ports and (for types with
+// a URL) URL variables for every enabled service, sorted by name. Containers should
+// use DockerHostGateway as host.
+func EndpointEnv(cfg *Config, host string) []EnvVar {
+	names := make([]string, 0, len(cfg.PortForwards))
+	for name, service := range cfg.PortForwards {
+		if !service.Disabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var vars []EnvVar
+	for _, name := range names {
+		service := cfg.PortForwards[name]
+		prefix := envName(name)
+		port := strconv.Itoa(service.LocalPort)
+
+		vars = append(vars,
+			EnvVar{Name: prefix + "_HOST", Value: host},
+			EnvVar{Name: prefix + "_PORT", Value: port},
+		)
+		for _, mapping := range service.Ports {
+			vars = append(vars, EnvVar{Name: fmt.Sprintf("%s_PORT_%d", prefix, mapping.Target), Value: strconv.Itoa(mapping.Local)})
+		}
+		if url := LookupServiceType(service.Type).ServiceURL(name, host, service.LocalPort); url != "" {
+			if service.APIPath != "" {
+				url = strings.TrimSuffix(url, "/") + "/" + strings.TrimPrefix(service.APIPath, "/")
+			}
+			vars = append(vars, EnvVar{Name: prefix + "_URL", Value: url})
+		}
+	}
+	return vars
+}
+
+// FormatEndpointEnv renders the variables in the given export format
+func FormatEndpointEnv(vars []EnvVar, format string) (string, error) {
+	switch format {
+	case ExportDotenv:
+		var b strings.Builder
+		for _, v := range vars {
+			fmt.Fprintf(&b, "%s=%s\n", v.Name, v.Value)
+		}
+		return b.String(), nil
+
+	case ExportKubernetes:
+		return encodeYAML(map[string][]EnvVar{"env": vars})
+
+	case ExportCompose:
+		environment := make(map[string]string, len(vars))
+		for _, v := range vars {
+			environment[v.Name] = v.Value
+		}
+		// host-gateway makes host.docker.internal resolve on Linux as well
+		return encodeYAML(map[string]interface{}{
+			"extra_hosts": []string{DockerHostGateway + ":host-gateway"},
+			"environment": environment,
+		})
+
+	default:
+		return "", fmt.Errorf("unknown export format %q (expected %s, %s or %s)",
+			format, ExportCompose, ExportKubernetes, ExportDotenv)
+	}
+}
+
+// envName converts a service name into an environment variable prefix
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// encodeYAML marshals value with the two-space indentation used in config files
+func encodeYAML(value interface{}) (string, error) {
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return "", fmt.Errorf("failed to encode snippet: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode snippet: %w", err)
+	}
+	return b.String(), nil
+}
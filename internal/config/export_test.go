@@ -0,0 +1,65 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEndpointEnv(t *testing.T) {
+	cfg := &Config{
+		PortForwards: map[string]Service{
+			"billing-api": {LocalPort: 8081, Type: "rest", APIPath: "/v1"},
+			"events":      {LocalPort: 9090, Type: "rpc", Ports: []PortMapping{{Local: 9190, Target: 9100}}},
+			"old":         {LocalPort: 7000, Type: "web", Disabled: true},
+		},
+	}
+
+	vars := EndpointEnv(cfg, DockerHostGateway)
+	got := make(map[string]string)
+	for _, v := range vars {
+		got[v.Name] = v.Value
+	}
+
+	expected := map[string]string{
+		"BILLING_API_HOST": "host.docker.internal",
+		"BILLING_API_PORT": "8081",
+		"BILLING_API_URL":  "http://host.docker.internal:8081/v1",
+		"EVENTS_HOST":      "host.docker.internal",
+		"EVENTS_PORT":      "9090",
+		"EVENTS_PORT_9100": "9190",
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d variables, got %v", len(expected), got)
+	}
+	for name, value := range expected {
+		if got[name] != value {
+			t.Errorf("Expected %s=%s, got %q", name, value, got[name])
+		}
+	}
+}
+
+func TestFormatEndpointEnv(t *testing.T) {
+	vars := []EnvVar{{Name: "API_PORT", Value: "8080"}}
+
+	tests := []struct {
+		format   string
+		contains string
+	}{
+		{ExportDotenv, "API_PORT=8080\n"},
+		{ExportKubernetes, "- name: API_PORT\n    value: \"8080\""},
+		{ExportCompose, "host.docker.internal:host-gateway"},
+	}
+	for _, tt := range tests {
+		out, err := FormatEndpointEnv(vars, tt.format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.format, err)
+		}
+		if !strings.Contains(out, tt.contains) {
+			t.Errorf("%s: expected output to contain %q, got:\n%s", tt.format, tt.contains, out)
+		}
+	}
+
+	if _, err := FormatEndpointEnv(vars, "xml"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// IsExposed reports whether the service listens on a non-loopback address
+func (s Service) IsExposed() bool {
+	return !utils.IsLoopbackAddress(s.BindAddress)
+}
+
+// validateExposure rejects services that would be reachable from the local
+// network without an explicit insecureExpose: true acknowledgement
+func validateExposure(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.PortForwards))
+	for name := range cfg.PortForwards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service := cfg.PortForwards[name]
+		if service.IsExposed() && !service.InsecureExpose {
+			return fmt.Errorf("service %s binds to non-loopback address %s; set insecureExpose: true to allow network access", name, service.BindAddress)
+		}
+	}
+
+	return nil
+}
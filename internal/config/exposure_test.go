@@ -0,0 +1,39 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateExposure(t *testing.T) {
+	cfg := &Config{
+		PortForwards: map[string]Service{
+			"local":   {BindAddress: "127.0.0.1"},
+			"allowed": {BindAddress: "0.0.0.0", InsecureExpose: true},
+		},
+	}
+	if err := validateExposure(cfg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cfg.PortForwards["admin"] = Service{BindAddress: "0.0.0.0"}
+	err := validateExposure(cfg)
+	if err == nil {
+		t.Fatal("Expected error for non-loopback bind without insecureExpose")
+	}
+	if !strings.Contains(err.Error(), "admin") || !strings.Contains(err.Error(), "insecureExpose") {
+		t.Errorf("Expected error to name the service and the opt-in, got: %v", err)
+	}
+}
+
+func TestInsecureExposeNotInheritedFromTemplate(t *testing.T) {
+	template := Service{BindAddress: "0.0.0.0", InsecureExpose: true}
+	service := applyTemplate("svc", Service{From: "shared"}, template)
+
+	if service.BindAddress != "0.0.0.0" {
+		t.Errorf("Expected bindAddress to be inherited, got %q", service.BindAddress)
+	}
+	if service.InsecureExpose {
+		t.Error("insecureExpose must be set on each service, not inherited")
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Selection narrows the configured services down to the ones needed right now
+type Selection struct {
+	Groups  []string // Names of groups in the config whose services run
+	Only    []string // Services that run, in addition to those of Groups
+	Exclude []string // Services that do not run, even if selected otherwise
+}
+
+// IsEmpty reports whether the selection keeps all services
+func (s Selection) IsEmpty() bool {
+	return len(s.Groups) == 0 && len(s.Only) == 0 && len(s.Exclude) == 0
+}
+
+// Select removes the services of cfg that the selection does not include. With
+// neither groups nor services to keep, all services except the excluded ones remain.
+// Unknown groups and services named directly are errors; group members that are not
+// configured, e.g. because they were disabled, are skipped.
+func Select(cfg *Config, selection Selection) error {
+	if cfg == nil || selection.IsEmpty() {
+		return nil
+	}
+
+	// Disabled services can be selected too, so they can be enabled from the TUI
+	known := make(map[string]bool, len(cfg.PortForwards)+len(cfg.Disabled))
+	for name := range cfg.PortForwards {
+		known[name] = true
+	}
+	for _, name := range cfg.Disabled {
+		known[name] = true
+	}
+	for _, name := range append(append([]string(nil), selection.Only...), selection.Exclude...) {
+		if !known[name] {
+			return fmt.Errorf("unknown service %q (available: %s)", name, strings.Join(serviceNames(cfg), ", "))
+		}
+	}
+
+	keep := make(map[string]bool)
+	for _, group := range selection.Groups {
+		services, exists := cfg.Groups[group]
+		if !exists {
+			return fmt.Errorf("unknown group %q (available: %s)", group, strings.Join(groupNames(cfg), ", "))
+		}
+		for _, name := range services {
+			keep[name] = true
+		}
+	}
+	for _, name := range selection.Only {
+		keep[name] = true
+	}
+
+	selected := len(selection.Groups) > 0 || len(selection.Only) > 0
+	for name := range cfg.PortForwards {
+		if selected && !keep[name] {
+			delete(cfg.PortForwards, name)
+		}
+	}
+	excluded := make(map[string]bool, len(selection.Exclude))
+	for _, name := range selection.Exclude {
+		excluded[name] = true
+		delete(cfg.PortForwards, name)
+	}
+
+	var disabled []string
+	for _, name := range cfg.Disabled {
+		if (!selected || keep[name]) && !excluded[name] {
+			disabled = append(disabled, name)
+		}
+	}
+	cfg.Disabled = disabled
+	return nil
+}
+
+// validateGroups rejects groups without services
+func validateGroups(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+	for _, name := range groupNames(cfg) {
+		if len(cfg.Groups[name]) == 0 {
+			return fmt.Errorf("group %s lists no services", name)
+		}
+	}
+	return nil
+}
+
+// serviceNames returns the names of the configured services, sorted
+func serviceNames(cfg *Config) []string {
+	names := make([]string, 0, len(cfg.PortForwards))
+	for name := range cfg.PortForwards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// groupNames returns the names of the configured groups, sorted
+func groupNames(cfg *Config) []string {
+	names := make([]string, 0, len(cfg.Groups))
+	for name := range cfg.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
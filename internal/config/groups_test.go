@@ -0,0 +1,91 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	newConfig := func() *Config {
+		return &Config{
+			PortForwards: map[string]Service{"api": {}, "worker": {}, "web": {}, "admin": {}},
+			Groups: map[string][]string{
+				"backend":  {"api", "worker", "disabled-service"},
+				"frontend": {"web"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		selection Selection
+		expected  []string
+		err       string
+	}{
+		{name: "empty selection keeps all", expected: []string{"admin", "api", "web", "worker"}},
+		{name: "group", selection: Selection{Groups: []string{"backend"}}, expected: []string{"api", "worker"}},
+		{name: "groups and only", selection: Selection{Groups: []string{"frontend"}, Only: []string{"admin"}}, expected: []string{"admin", "web"}},
+		{name: "exclude from group", selection: Selection{Groups: []string{"backend"}, Exclude: []string{"worker"}}, expected: []string{"api"}},
+		{name: "exclude only", selection: Selection{Exclude: []string{"admin"}}, expected: []string{"api", "web", "worker"}},
+		{name: "unknown group", selection: Selection{Groups: []string{"data"}}, err: `unknown group "data" (available: backend, frontend)`},
+		{name: "unknown service", selection: Selection{Only: []string{"apu"}}, err: `unknown service "apu"`},
+		{name: "unknown excluded service", selection: Selection{Exclude: []string{"apu"}}, err: `unknown service "apu"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := newConfig()
+			err := Select(cfg, test.selection)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("Expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if names := serviceNames(cfg); !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("Expected services %v, got %v", test.expected, names)
+			}
+		})
+	}
+}
+
+func TestValidateGroups(t *testing.T) {
+	if err := validateGroups(&Config{Groups: map[string][]string{"backend": {"api"}}}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := validateGroups(&Config{Groups: map[string][]string{"empty": nil}}); err == nil {
+		t.Error("Expected an error for a group without services")
+	}
+	if err := validateGroups(nil); err != nil {
+		t.Errorf("Expected nil config to be accepted, got %v", err)
+	}
+}
+
+func TestMergeConfigsGroups(t *testing.T) {
+	defaults := &Config{Groups: map[string][]string{"backend": {"api"}, "frontend": {"web"}}}
+	user := &Config{Groups: map[string][]string{"backend": {"api", "worker"}}}
+
+	merged := mergeConfigs(defaults, user)
+	expected := map[string][]string{"backend": {"api", "worker"}, "frontend": {"web"}}
+	if !reflect.DeepEqual(merged.Groups, expected) {
+		t.Errorf("Expected user groups to replace defaults by name, got %v", merged.Groups)
+	}
+}
+
+func TestSelectKeepsSelectedDisabledServices(t *testing.T) {
+	cfg := &Config{
+		PortForwards: map[string]Service{"api": {}, "web": {}},
+		Disabled:     []string{"admin", "worker"},
+		Groups:       map[string][]string{"backend": {"api", "worker"}},
+	}
+	if err := Select(cfg, Selection{Groups: []string{"backend"}, Only: []string{"admin"}, Exclude: []string{"admin"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Disabled, []string{"worker"}) {
+		t.Errorf("Expected only the selected disabled service, got %v", cfg.Disabled)
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Health check modes
+const (
+	HealthCheckStrict  = "strict"  // Default: Degraded on first failure, Failed after a few
+	HealthCheckLenient = "lenient" // Tolerate long stretches of unreachability before failing
+	HealthCheckOff     = "off"     // Only check that the kubectl process is alive
+)
+
+// Health check protocols; the default only checks that the forwarded port accepts connections
+const (
+	ProbeTCP      = "tcp"
+	ProbePostgres = "postgres" // SSLRequest, answered by the server before authentication
+	ProbeRedis    = "redis"    // PING
+	ProbeKafka    = "kafka"    // ApiVersions
+	ProbeHTTP     = "http"     // GET healthCheck.path, expecting a 2xx response
+	ProbeGRPC     = "grpc"     // grpc.health.v1.Health/Check through grpcurl
+	ProbeExec     = "exec"     // healthCheck.command, healthy when it exits with status 0
+)
+
+// HealthCheckConfig tunes how a service's port-forward is health checked
+type HealthCheckConfig struct {
+	Mode     string        `yaml:"mode,omitempty"`     // strict (default), lenient or off
+	Interval time.Duration `yaml:"interval,omitempty"` // Minimum time between checks (0 = every monitoring tick)
+	Protocol string        `yaml:"protocol,omitempty"` // tcp (default), postgres, redis, kafka, http, grpc or exec
+	Timeout  time.Duration `yaml:"timeout,omitempty"`  // Limit for each probe (0 = 1s for tcp, 2s otherwise)
+	// Path requested by http probes (default /), or the service name checked by grpc
+	// probes (default: the whole server)
+	Path string `yaml:"path,omitempty"`
+	// Command run in the shell by exec probes, with the local port in KPORTFORWARD_LOCAL_PORT
+	Command string `yaml:"command,omitempty"`
+
+	// Consecutive failed checks before a Running service is shown as Degraded (0 = 1)
+	DegradedAfter int `yaml:"degradedAfter,omitempty"`
+	// Consecutive failed checks before a service is Failed and restarted (0 = the mode's default)
+	FailedAfter int `yaml:"failedAfter,omitempty"`
+	// HideDegraded keeps the service Running until it fails, for a binary up/down view
+	HideDegraded bool `yaml:"hideDegraded,omitempty"`
+}
+
+// HasLatencyProbe reports whether the health check goes through the tunnel to the
+// server, so its duration is worth showing
+func (h HealthCheckConfig) HasLatencyProbe() bool {
+	return h.Protocol != "" && h.Protocol != ProbeTCP
+}
+
+// HealthCheckMode returns the effective health check mode for the service
+func (s Service) HealthCheckMode() string {
+	if s.HealthCheck.Mode == "" {
+		return HealthCheckStrict
+	}
+	return s.HealthCheck.Mode
+}
+
+// validateHealthChecks rejects unknown health check modes and protocols, negative intervals and
+// thresholds that would never show Degraded before Failed
+func validateHealthChecks(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.PortForwards))
+	for name := range cfg.PortForwards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		healthCheck := cfg.PortForwards[name].HealthCheck
+		switch healthCheck.Mode {
+		case "", HealthCheckStrict, HealthCheckLenient, HealthCheckOff:
+		default:
+			return fmt.Errorf("service %s has unknown healthCheck.mode %q (expected strict, lenient or off)", name, healthCheck.Mode)
+		}
+		switch healthCheck.Protocol {
+		case "", ProbeTCP, ProbePostgres, ProbeRedis, ProbeKafka, ProbeHTTP, ProbeGRPC, ProbeExec:
+		default:
+			return fmt.Errorf("service %s has unknown healthCheck.protocol %q (expected tcp, postgres, redis, kafka, http, grpc or exec)", name, healthCheck.Protocol)
+		}
+		if healthCheck.Path != "" && healthCheck.Protocol != ProbeHTTP && healthCheck.Protocol != ProbeGRPC {
+			return fmt.Errorf("service %s has healthCheck.path, which needs protocol http or grpc", name)
+		}
+		if (healthCheck.Command != "") != (healthCheck.Protocol == ProbeExec) {
+			return fmt.Errorf("service %s needs healthCheck.command with protocol exec, and only then", name)
+		}
+		if healthCheck.Interval < 0 || healthCheck.Timeout < 0 {
+			return fmt.Errorf("service %s has negative healthCheck.interval or timeout", name)
+		}
+		if healthCheck.DegradedAfter < 0 || healthCheck.FailedAfter < 0 {
+			return fmt.Errorf("service %s has a negative healthCheck threshold", name)
+		}
+		if healthCheck.FailedAfter > 0 && healthCheck.DegradedAfter >= healthCheck.FailedAfter && !healthCheck.HideDegraded {
+			return fmt.Errorf("service %s has healthCheck.degradedAfter %d, which must be below failedAfter %d",
+				name, healthCheck.DegradedAfter, healthCheck.FailedAfter)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateHealthChecks(t *testing.T) {
+	cfg := &Config{
+		PortForwards: map[string]Service{
+			"default": {},
+			"batch":   {HealthCheck: HealthCheckConfig{Mode: HealthCheckLenient}},
+			"admin":   {HealthCheck: HealthCheckConfig{Mode: HealthCheckOff}},
+		},
+	}
+	if err := validateHealthChecks(cfg); err != nil {
+		t.Fatalf("Expected valid modes to pass, got %v", err)
+	}
+	if mode := cfg.PortForwards["default"].HealthCheckMode(); mode != HealthCheckStrict {
+		t.Errorf("Expected strict as default mode, got %s", mode)
+	}
+
+	cfg.PortForwards["broken"] = Service{HealthCheck: HealthCheckConfig{Mode: "sometimes"}}
+	err := validateHealthChecks(cfg)
+	if err == nil || !strings.Contains(err.Error(), "sometimes") {
+		t.Errorf("Expected unknown mode error, got %v", err)
+	}
+}
+
+func TestValidateHealthCheckThresholds(t *testing.T) {
+	tests := []struct {
+		healthCheck HealthCheckConfig
+		valid       bool
+	}{
+		{HealthCheckConfig{DegradedAfter: 2, FailedAfter: 5}, true},
+		{HealthCheckConfig{DegradedAfter: 3}, true},
+		{HealthCheckConfig{HideDegraded: true, FailedAfter: 1}, true},
+		{HealthCheckConfig{DegradedAfter: 5, FailedAfter: 5}, false},
+		{HealthCheckConfig{FailedAfter: -1}, false},
+		{HealthCheckConfig{Protocol: ProbeRedis}, true},
+		{HealthCheckConfig{Protocol: "mysql"}, false},
+		{HealthCheckConfig{Protocol: ProbeHTTP, Path: "/healthz"}, true},
+		{HealthCheckConfig{Protocol: ProbeGRPC, Path: "billing.Billing"}, true},
+		{HealthCheckConfig{Protocol: ProbeRedis, Path: "/healthz"}, false},
+		{HealthCheckConfig{Protocol: ProbeExec, Command: "pg_isready -p $KPORTFORWARD_LOCAL_PORT"}, true},
+		{HealthCheckConfig{Protocol: ProbeExec}, false},
+		{HealthCheckConfig{Command: "true"}, false},
+		{HealthCheckConfig{Timeout: -time.Second}, false},
+	}
+
+	for _, tt := range tests {
+		cfg := &Config{PortForwards: map[string]Service{"api": {HealthCheck: tt.healthCheck}}}
+		if err := validateHealthChecks(cfg); (err == nil) != tt.valid {
+			t.Errorf("%+v: expected valid=%v, got %v", tt.healthCheck, tt.valid, err)
+		}
+	}
+}
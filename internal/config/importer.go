@@ -0,0 +1,217 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImportCandidate is a locally exposed port found in a docker-compose or .env file
+// that could become a port-forward
+type ImportCandidate struct {
+	Name       string // Suggested service name
+	LocalPort  int    // Port local apps currently use
+	TargetPort int    // Port inside the container, if known
+	Type       string // Guessed service type
+}
+
+// composeFile holds the parts of a docker-compose file needed for importing
+type composeFile struct {
+	Services map[string]struct {
+		Ports []yaml.Node `yaml:"ports"`
+	} `yaml:"services"`
+}
+
+// ParseComposePorts returns one candidate per published TCP port in a docker-compose file.
+// Port ranges and UDP ports cannot be port-forwarded one-to-one and are skipped.
+func ParseComposePorts(data []byte) ([]ImportCandidate, error) {
+	var compose composeFile
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("failed to parse compose file: %w", err)
+	}
+	if len(compose.Services) == 0 {
+		return nil, fmt.Errorf("compose file has no services")
+	}
+
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var candidates []ImportCandidate
+	for _, name := range names {
+		var ports [][2]int
+		for i := range compose.Services[name].Ports {
+			published, target, ok := parseComposePort(&compose.Services[name].Ports[i])
+			if ok {
+				ports = append(ports, [2]int{published, target})
+			}
+		}
+
+		for i, port := range ports {
+			candidate := ImportCandidate{
+				Name:       name,
+				LocalPort:  port[0],
+				TargetPort: port[1],
+				Type:       GuessServiceType(name, port[1]),
+			}
+			// Services publishing several ports get one entry per port
+			if len(ports) > 1 && i > 0 {
+				candidate.Name = fmt.Sprintf("%s-%d", name, port[1])
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
+
+// parseComposePort handles both the short ("127.0.0.1:8080:80/tcp") and long
+// ({target: 80, published: 8080}) port syntax
+func parseComposePort(node *yaml.Node) (published, target int, ok bool) {
+	if node.Kind == yaml.MappingNode {
+		var long struct {
+			Target    int    `yaml:"target"`
+			Published string `yaml:"published"`
+			Protocol  string `yaml:"protocol"`
+		}
+		if err := node.Decode(&long); err != nil || long.Target == 0 || isUDP(long.Protocol) {
+			return 0, 0, false
+		}
+		hostPort, err := strconv.Atoi(long.Published)
+		if err != nil {
+			hostPort = long.Target
+		}
+		return hostPort, long.Target, true
+	}
+
+	spec := node.Value
+	if protocol := strings.Index(spec, "/"); protocol >= 0 {
+		if isUDP(spec[protocol+1:]) {
+			return 0, 0, false
+		}
+		spec = spec[:protocol]
+	}
+
+	// The container port is always last; an optional host IP may precede the host port
+	hostPart, containerPart := "", spec
+	if sep := strings.LastIndex(spec, ":"); sep >= 0 {
+		hostPart, containerPart = spec[:sep], spec[sep+1:]
+		if sep := strings.LastIndex(hostPart, ":"); sep >= 0 {
+			hostPart = hostPart[sep+1:]
+		}
+	}
+
+	target, err := strconv.Atoi(containerPart)
+	if err != nil {
+		return 0, 0, false
+	}
+	if hostPart == "" {
+		return target, target, true
+	}
+	published, err = strconv.Atoi(hostPart)
+	if err != nil {
+		return 0, 0, false
+	}
+	return published, target, true
+}
+
+// isUDP reports whether a compose protocol is UDP, which kubectl cannot forward
+func isUDP(protocol string) bool {
+	return strings.EqualFold(protocol, "udp")
+}
+
+// ParseDotenvPorts returns candidates for variables that hold a local port, either
+// directly (API_PORT=8080) or as a localhost URL (API_URL=http://localhost:8080)
+func ParseDotenvPorts(data []byte) ([]ImportCandidate, error) {
+	var candidates []ImportCandidate
+	seen := make(map[int]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+
+		name, port := dotenvPort(key, value)
+		if name == "" || seen[port] {
+			continue
+		}
+		seen[port] = true
+		candidates = append(candidates, ImportCandidate{
+			Name:       name,
+			LocalPort:  port,
+			TargetPort: port,
+			Type:       GuessServiceType(name, port),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	return candidates, nil
+}
+
+// dotenvPort returns the service name and port for a port-like variable
+func dotenvPort(key, value string) (string, int) {
+	upper := strings.ToUpper(key)
+	for _, suffix := range []string{"_PORT", "_URL", "_ADDR", "_HOST"} {
+		if !strings.HasSuffix(upper, suffix) || len(upper) == len(suffix) {
+			continue
+		}
+		name := strings.ReplaceAll(strings.ToLower(key[:len(key)-len(suffix)]), "_", "-")
+
+		if suffix == "_PORT" {
+			port, err := strconv.Atoi(value)
+			if err != nil || port <= 0 || port > 65535 {
+				return "", 0
+			}
+			return name, port
+		}
+
+		// URLs and host:port addresses only count when they point at this machine
+		if !strings.Contains(value, "://") {
+			value = "tcp://" + value
+		}
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Hostname() != "localhost" && parsed.Hostname() != "127.0.0.1") {
+			return "", 0
+		}
+		port, err := strconv.Atoi(parsed.Port())
+		if err != nil || port <= 0 {
+			return "", 0
+		}
+		return name, port
+	}
+	return "", 0
+}
+
+// GuessServiceType picks a service type from common naming and port conventions
+func GuessServiceType(name string, port int) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.Contains(lower, "grpc") || strings.Contains(lower, "rpc") || port == 50051 || port == 9090:
+		return "rpc"
+	case strings.Contains(lower, "api") || strings.Contains(lower, "rest"):
+		return "rest"
+	case strings.Contains(lower, "web") || strings.Contains(lower, "ui") || strings.Contains(lower, "frontend") ||
+		port == 80 || port == 443 || port == 3000:
+		return "web"
+	default:
+		return "other"
+	}
+}
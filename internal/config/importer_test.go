@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseComposePorts(t *testing.T) {
+	compose := `
+services:
+  web:
+    image: nginx
+    ports:
+      - "8080:80"
+      - "127.0.0.1:8443:443/tcp"
+  grpc-gateway:
+    ports:
+      - target: 50051
+        published: "50052"
+  dns:
+    ports:
+      - "5353:53/udp"
+  worker:
+    image: worker
+`
+	candidates, err := ParseComposePorts([]byte(compose))
+	if err != nil {
+		t.Fatalf("Failed to parse compose file: %v", err)
+	}
+
+	expected := []ImportCandidate{
+		{Name: "grpc-gateway", LocalPort: 50052, TargetPort: 50051, Type: "rpc"},
+		{Name: "web", LocalPort: 8080, TargetPort: 80, Type: "web"},
+		{Name: "web-443", LocalPort: 8443, TargetPort: 443, Type: "web"},
+	}
+	if len(candidates) != len(expected) {
+		t.Fatalf("Expected %d candidates, got %+v", len(expected), candidates)
+	}
+	for i := range expected {
+		if candidates[i] != expected[i] {
+			t.Errorf("Candidate %d: expected %+v, got %+v", i, expected[i], candidates[i])
+		}
+	}
+}
+
+func TestParseDotenvPorts(t *testing.T) {
+	env := `# local stack
+API_PORT=8081
+export BILLING_URL="http://localhost:8082/v1"
+DATABASE_URL=postgres://db.example.com:5432/app
+DUPLICATE_PORT=8081
+NOT_A_PORT=abc
+`
+	candidates, err := ParseDotenvPorts([]byte(env))
+	if err != nil {
+		t.Fatalf("Failed to parse env file: %v", err)
+	}
+
+	if len(candidates) != 2 {
+		t.Fatalf("Expected 2 candidates, got %+v", candidates)
+	}
+	if candidates[0].Name != "api" || candidates[0].LocalPort != 8081 {
+		t.Errorf("Expected api on 8081, got %+v", candidates[0])
+	}
+	if candidates[1].Name != "billing" || candidates[1].LocalPort != 8082 {
+		t.Errorf("Expected billing on 8082, got %+v", candidates[1])
+	}
+}
+
+func TestAddServicesToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kportforward", "config.yaml")
+	existing := "# my services\nportForwards:\n  api:\n    target: service/api\n    targetPort: 80\n    localPort: 8080\n    namespace: default\n    type: rest\n"
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	web := Service{Target: "service/web", TargetPort: 80, LocalPort: 3000, Namespace: "default", Type: "web"}
+	if err := addServicesToFile(path, map[string]Service{"web": web}); err != nil {
+		t.Fatalf("Failed to add service: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "# my services") {
+		t.Error("Expected existing comments to be preserved")
+	}
+	config, err := loadUserConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if len(config.PortForwards) != 2 || config.PortForwards["web"].LocalPort != 3000 {
+		t.Errorf("Expected api and web services, got %+v", config.PortForwards)
+	}
+
+	if err := addServicesToFile(path, map[string]Service{"api": web}); err == nil {
+		t.Error("Expected error when adding an existing service")
+	}
+}
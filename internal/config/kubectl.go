@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Streaming protocols kubectl can use to tunnel port-forward traffic
+const (
+	StreamingWebSocket = "websocket"
+	StreamingSPDY      = "spdy"
+)
+
+// Port-forward backends, see Config.PortForwardBackend
+const (
+	BackendKubectl = "kubectl"
+	BackendNative  = "native"
+)
+
+// DefaultRequestTimeout is the kubectl request timeout used when a service does not set one
+const DefaultRequestTimeout = 30 * time.Second
+
+// KubectlConfig tunes the kubectl port-forward process of a service
+type KubectlConfig struct {
+	// RequestTimeout is passed as --request-timeout (unset = 30s, 0 = no timeout)
+	RequestTimeout *time.Duration `yaml:"requestTimeout,omitempty"`
+	// Keepalive opens a connection through the tunnel at this interval so idle
+	// long-lived streams are not dropped by load balancers (0 = off)
+	Keepalive time.Duration `yaml:"keepalive,omitempty"`
+	// Streaming selects the tunnel protocol: websocket, spdy or empty for kubectl's default
+	Streaming string `yaml:"streaming,omitempty"`
+	// PodRunningTimeout is passed as --pod-running-timeout when kubectl supports it (0 = kubectl's default)
+	PodRunningTimeout time.Duration `yaml:"podRunningTimeout,omitempty"`
+	// Context is passed as --context to run against a cluster other than the current context
+	Context string `yaml:"context,omitempty"`
+}
+
+// EffectiveRequestTimeout returns the request timeout to pass to kubectl, where 0 means none
+func (k KubectlConfig) EffectiveRequestTimeout() time.Duration {
+	if k.RequestTimeout == nil {
+		return DefaultRequestTimeout
+	}
+	return *k.RequestTimeout
+}
+
+// validateKubectlOptions rejects negative durations, unknown streaming protocols and
+// backends, and websocket streaming with the native backend, which only speaks SPDY
+func validateKubectlOptions(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+	switch cfg.PortForwardBackend {
+	case "", BackendKubectl, BackendNative:
+	default:
+		return fmt.Errorf("unknown portForwardBackend %q (expected kubectl or native)", cfg.PortForwardBackend)
+	}
+
+	names := make([]string, 0, len(cfg.PortForwards))
+	for name := range cfg.PortForwards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		kubectl := cfg.PortForwards[name].Kubectl
+		if kubectl.RequestTimeout != nil && *kubectl.RequestTimeout < 0 {
+			return fmt.Errorf("service %s has negative kubectl.requestTimeout", name)
+		}
+		if kubectl.Keepalive < 0 {
+			return fmt.Errorf("service %s has negative kubectl.keepalive", name)
+		}
+		if kubectl.PodRunningTimeout < 0 {
+			return fmt.Errorf("service %s has negative kubectl.podRunningTimeout", name)
+		}
+		switch kubectl.Streaming {
+		case "", StreamingWebSocket, StreamingSPDY:
+		default:
+			return fmt.Errorf("service %s has unknown kubectl.streaming %q (expected websocket or spdy)", name, kubectl.Streaming)
+		}
+		if kubectl.Streaming == StreamingWebSocket && cfg.PortForwardBackend == BackendNative {
+			return fmt.Errorf("service %s has kubectl.streaming websocket, which portForwardBackend native does not support", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestKubectlConfigParsing(t *testing.T) {
+	var service Service
+	data := "kubectl:\n  requestTimeout: 0s\n  keepalive: 20s\n  streaming: spdy\n"
+	if err := yaml.Unmarshal([]byte(data), &service); err != nil {
+		t.Fatalf("Failed to parse kubectl options: %v", err)
+	}
+
+	if timeout := service.Kubectl.EffectiveRequestTimeout(); timeout != 0 {
+		t.Errorf("Expected explicit 0s to disable the timeout, got %v", timeout)
+	}
+	if service.Kubectl.Keepalive != 20*time.Second {
+		t.Errorf("Expected keepalive of 20s, got %v", service.Kubectl.Keepalive)
+	}
+	if timeout := (KubectlConfig{}).EffectiveRequestTimeout(); timeout != DefaultRequestTimeout {
+		t.Errorf("Expected unset timeout to use the default, got %v", timeout)
+	}
+}
+
+func TestValidateKubectlOptions(t *testing.T) {
+	cfg := &Config{
+		PortForwards: map[string]Service{
+			"api": {Kubectl: KubectlConfig{Streaming: StreamingWebSocket, Keepalive: time.Minute}},
+		},
+	}
+	if err := validateKubectlOptions(cfg); err != nil {
+		t.Fatalf("Expected valid options to pass, got %v", err)
+	}
+
+	cfg.PortForwards["broken"] = Service{Kubectl: KubectlConfig{Streaming: "carrier-pigeon"}}
+	err := validateKubectlOptions(cfg)
+	if err == nil || !strings.Contains(err.Error(), "carrier-pigeon") {
+		t.Errorf("Expected unknown streaming error, got %v", err)
+	}
+}
+
+func TestValidatePortForwardBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		service Service
+		wantErr string
+	}{
+		{name: "default", service: Service{Kubectl: KubectlConfig{Streaming: StreamingWebSocket}}},
+		{name: "native", backend: BackendNative, service: Service{Kubectl: KubectlConfig{Streaming: StreamingSPDY}}},
+		{name: "unknown", backend: "ssh", wantErr: `unknown portForwardBackend "ssh"`},
+		{name: "native websocket", backend: BackendNative, service: Service{Kubectl: KubectlConfig{Streaming: StreamingWebSocket}}, wantErr: "api has kubectl.streaming websocket"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{PortForwardBackend: tt.backend, PortForwards: map[string]Service{"api": tt.service}}
+			err := validateKubectlOptions(cfg)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPortForwardBackendMerge(t *testing.T) {
+	defaults := &Config{PortForwards: map[string]Service{}}
+	user := &Config{PortForwardBackend: BackendNative}
+
+	if merged := mergeConfigs(defaults, user); merged.PortForwardBackend != BackendNative {
+		t.Errorf("Expected the user's backend, got %q", merged.PortForwardBackend)
+	}
+	if merged := mergeConfigs(defaults, &Config{}); merged.PortForwardBackend != "" {
+		t.Errorf("Expected no backend when unset, got %q", merged.PortForwardBackend)
+	}
+
+	loader := NewOptimizedConfigLoader()
+	merged := loader.mergeConfigsOptimized(defaults, user)
+	if merged.PortForwardBackend != BackendNative {
+		t.Errorf("Expected the user's backend from the optimized loader, got %q", merged.PortForwardBackend)
+	}
+	if copied := loader.copyConfig(merged); copied.PortForwardBackend != BackendNative {
+		t.Errorf("Expected the backend to be copied, got %q", copied.PortForwardBackend)
+	}
+}
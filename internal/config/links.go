@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// MaxServiceLinks is the number of links that can be opened with the number keys 1-9
+const MaxServiceLinks = 9
+
+// ServiceLink is a dashboard, runbook or repository related to a service
+type ServiceLink struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// validateLinks requires every link to have a name and an absolute http(s) URL
+func validateLinks(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.PortForwards))
+	for name := range cfg.PortForwards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		links := cfg.PortForwards[name].Links
+		if len(links) > MaxServiceLinks {
+			return fmt.Errorf("service %s has %d links (at most %d are supported)", name, len(links), MaxServiceLinks)
+		}
+		for i, link := range links {
+			if link.Name == "" {
+				return fmt.Errorf("service %s has a link without a name (links[%d])", name, i)
+			}
+			parsed, err := url.Parse(link.URL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("service %s link %q must be an http(s) URL, got %q", name, link.Name, link.URL)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateLinks(t *testing.T) {
+	cfg := &Config{
+		PortForwards: map[string]Service{
+			"none": {},
+			"api": {Links: []ServiceLink{
+				{Name: "Dashboard", URL: "https://grafana.example.com/d/api"},
+				{Name: "Runbook", URL: "http://wiki.example.com/runbooks/api"},
+			}},
+		},
+	}
+	if err := validateLinks(cfg); err != nil {
+		t.Fatalf("Expected valid links to pass, got %v", err)
+	}
+
+	cfg.PortForwards["broken"] = Service{Links: []ServiceLink{{Name: "Shell", URL: "file:///etc/passwd"}}}
+	err := validateLinks(cfg)
+	if err == nil || !strings.Contains(err.Error(), "Shell") {
+		t.Errorf("Expected non-http link error, got %v", err)
+	}
+
+	cfg.PortForwards["broken"] = Service{Links: []ServiceLink{{URL: "https://example.com"}}}
+	if err := validateLinks(cfg); err == nil {
+		t.Error("Expected error for link without a name")
+	}
+
+	cfg.PortForwards["broken"] = Service{Links: make([]ServiceLink, MaxServiceLinks+1)}
+	if err := validateLinks(cfg); err == nil || !strings.Contains(err.Error(), "at most") {
+		t.Errorf("Expected too many links error, got %v", err)
+	}
+}
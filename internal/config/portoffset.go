@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PortOffset returns the amount added to every local port while kubeContext is active
+func (c *Config) PortOffset(kubeContext string) int {
+	return c.PortOffsets[kubeContext]
+}
+
+// validatePortOffsets rejects offsets that move a service's local port out of range
+func validatePortOffsets(cfg *Config) error {
+	if cfg == nil || len(cfg.PortOffsets) == 0 {
+		return nil
+	}
+
+	contexts := make([]string, 0, len(cfg.PortOffsets))
+	for kubeContext := range cfg.PortOffsets {
+		contexts = append(contexts, kubeContext)
+	}
+	sort.Strings(contexts)
+
+	names := make([]string, 0, len(cfg.PortForwards))
+	for name := range cfg.PortForwards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, kubeContext := range contexts {
+		offset := cfg.PortOffsets[kubeContext]
+		for _, name := range names {
+			service := cfg.PortForwards[name]
+			if err := ValidatePortOffset(service.LocalPort, offset); err != nil {
+				return fmt.Errorf("portOffsets[%s] for service %s: %w", kubeContext, name, err)
+			}
+			for _, mapping := range service.Ports {
+				if err := ValidatePortOffset(mapping.Local, offset); err != nil {
+					return fmt.Errorf("portOffsets[%s] for service %s: %w", kubeContext, name, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidatePortOffset checks that localPort moved by offset is still a valid port
+func ValidatePortOffset(localPort, offset int) error {
+	if port := localPort + offset; port < 1 || port > 65535 {
+		return fmt.Errorf("local port %d%+d = %d is outside 1-65535", localPort, offset, port)
+	}
+	return nil
+}
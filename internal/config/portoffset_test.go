@@ -0,0 +1,31 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPortOffsets(t *testing.T) {
+	cfg := &Config{
+		PortForwards: map[string]Service{
+			"api": {LocalPort: 8080},
+			"db":  {LocalPort: 5432},
+		},
+		PortOffsets: map[string]int{"staging": 1000, "dev": -1000},
+	}
+	if err := validatePortOffsets(cfg); err != nil {
+		t.Fatalf("Expected offsets to be valid, got %v", err)
+	}
+	if offset := cfg.PortOffset("staging"); offset != 1000 {
+		t.Errorf("Expected staging offset 1000, got %d", offset)
+	}
+	if offset := cfg.PortOffset("production"); offset != 0 {
+		t.Errorf("Expected no offset for an unlisted context, got %d", offset)
+	}
+
+	cfg.PortOffsets["huge"] = 60000
+	err := validatePortOffsets(cfg)
+	if err == nil || !strings.Contains(err.Error(), "huge") || !strings.Contains(err.Error(), "api") {
+		t.Errorf("Expected out of range error naming context and service, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// normalizePorts moves the first of ports into localPort and targetPort when a service
+// only lists ports, and rejects mappings outside 1-65535, local ports used twice by a
+// service and ports combined with trackPod, which only translates the primary port.
+func normalizePorts(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.PortForwards))
+	for name := range cfg.PortForwards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service := cfg.PortForwards[name]
+		if len(service.Ports) == 0 {
+			continue
+		}
+		if service.TrackPod {
+			return fmt.Errorf("service %s cannot combine ports with trackPod", name)
+		}
+		if service.LocalPort == 0 && service.TargetPort == 0 {
+			service.LocalPort, service.TargetPort = service.Ports[0].Local, service.Ports[0].Target
+			service.Ports = service.Ports[1:]
+		}
+
+		used := map[int]bool{service.LocalPort: true}
+		for _, mapping := range service.Ports {
+			if mapping.Local < 1 || mapping.Local > 65535 || mapping.Target < 1 || mapping.Target > 65535 {
+				return fmt.Errorf("service %s has port %d:%d outside 1-65535", name, mapping.Local, mapping.Target)
+			}
+			if used[mapping.Local] {
+				return fmt.Errorf("service %s forwards local port %d twice", name, mapping.Local)
+			}
+			used[mapping.Local] = true
+		}
+		cfg.PortForwards[name] = service
+	}
+	return nil
+}
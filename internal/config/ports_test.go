@@ -0,0 +1,35 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePorts(t *testing.T) {
+	cfg := &Config{PortForwards: map[string]Service{
+		"postgres": {Ports: []PortMapping{{Local: 5432, Target: 5432}, {Local: 9187, Target: 9187}}},
+		"api":      {LocalPort: 8080, TargetPort: 80, Ports: []PortMapping{{Local: 8081, Target: 9090}}},
+	}}
+	if err := normalizePorts(cfg); err != nil {
+		t.Fatalf("Expected valid ports, got %v", err)
+	}
+
+	postgres := cfg.PortForwards["postgres"]
+	if postgres.LocalPort != 5432 || postgres.TargetPort != 5432 || !reflect.DeepEqual(postgres.Ports, []PortMapping{{Local: 9187, Target: 9187}}) {
+		t.Errorf("Expected the first port to become the primary one, got %+v", postgres)
+	}
+	if api := cfg.PortForwards["api"]; api.LocalPort != 8080 || len(api.Ports) != 1 {
+		t.Errorf("Expected ports next to localPort to stay further ports, got %+v", api)
+	}
+
+	invalid := []Service{
+		{LocalPort: 8080, TargetPort: 80, Ports: []PortMapping{{Local: 8080, Target: 81}}},
+		{LocalPort: 8080, TargetPort: 80, Ports: []PortMapping{{Local: 70000, Target: 81}}},
+		{LocalPort: 8080, TargetPort: 80, TrackPod: true, Ports: []PortMapping{{Local: 8081, Target: 81}}},
+	}
+	for _, service := range invalid {
+		if err := normalizePorts(&Config{PortForwards: map[string]Service{"api": service}}); err == nil {
+			t.Errorf("Expected %+v to be rejected", service)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profileNamePattern keeps profile names usable as file names and service name prefixes
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// profileFile is a profile's YAML file: services and templates, optionally for another cluster
+type profileFile struct {
+	KubeContext  string             `yaml:"kubeContext,omitempty"` // Default kubectl context for the profile's services
+	PortForwards map[string]Service `yaml:"portForwards"`
+	Templates    map[string]Service `yaml:"templates,omitempty"`
+}
+
+// ProfilesDir returns the directory profile files are read from. It stays in the config
+// directory when another config file is selected, so profiles are shared between them.
+func ProfilesDir() (string, error) {
+	path, err := getDefaultConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "profiles"), nil
+}
+
+// ListProfiles returns the names of the profiles in ProfilesDir, sorted
+func ListProfiles() ([]string, error) {
+	dir, err := ProfilesDir()
+	if err != nil {
+		return nil, err
+	}
+	return listProfilesIn(dir)
+}
+
+// listProfilesIn returns the names of the profile files in dir; a missing dir has none
+func listProfilesIn(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name, isYAML := strings.CutSuffix(entry.Name(), ".yaml")
+		if entry.IsDir() || !isYAML || !profileNamePattern.MatchString(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadProfiles loads the regular configuration and replaces its services with those of
+// the named profiles, each read from <ProfilesDir>/<name>.yaml. Services are renamed to
+// "<profile>/<service>" so profiles for different clusters can share service names.
+func LoadProfiles(names []string) (*Config, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := ProfilesDir()
+	if err != nil {
+		return nil, err
+	}
+	return loadProfilesFrom(cfg, dir, names)
+}
+
+// loadProfilesFrom replaces the services of cfg with those of the named profiles in dir
+func loadProfilesFrom(cfg *Config, dir string, names []string) (*Config, error) {
+	cfg.PortForwards = make(map[string]Service)
+	cfg.Disabled = nil
+	cfg.Skipped = nil
+	seen := make(map[string]bool, len(names))
+
+	for _, profile := range names {
+		if !profileNamePattern.MatchString(profile) {
+			return nil, fmt.Errorf("invalid profile name %q (use letters, digits, - and _)", profile)
+		}
+		if seen[profile] {
+			continue
+		}
+		seen[profile] = true
+
+		file, err := loadProfileFile(filepath.Join(dir, profile+".yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", profile, err)
+		}
+
+		// Profile templates extend the shared ones; expand them here since they are
+		// not visible to the rest of the configuration
+		templates := make(map[string]Service, len(cfg.Templates)+len(file.Templates))
+		for name, template := range cfg.Templates {
+			templates[name] = template
+		}
+		for name, template := range file.Templates {
+			templates[name] = template
+		}
+		profileConfig := &Config{PortForwards: file.PortForwards, Templates: templates}
+		if err := expandTemplates(profileConfig); err != nil {
+			return nil, fmt.Errorf("profile %s: failed to expand service templates: %w", profile, err)
+		}
+
+		for name, service := range profileConfig.PortForwards {
+			if service.Disabled {
+				cfg.Disabled = append(cfg.Disabled, profile+"/"+name)
+				continue
+			}
+			service.From = ""
+			service.Profile = profile
+			if service.Kubectl.Context == "" {
+				service.Kubectl.Context = file.KubeContext
+			}
+			cfg.PortForwards[profile+"/"+name] = service
+		}
+	}
+
+	sort.Strings(cfg.Disabled)
+	return finalizeConfig(cfg)
+}
+
+// loadProfileFile reads and parses a profile file
+func loadProfileFile(path string) (*profileFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no profile file at %s", path)
+		}
+		return nil, fmt.Errorf("failed to read profile file: %w", err)
+	}
+
+	file := &profileFile{}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse profile file: %w", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadProfilesFrom(t *testing.T) {
+	dir := t.TempDir()
+	writeProfile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeProfile("staging", `
+kubeContext: staging-cluster
+templates:
+  rest:
+    targetPort: 80
+    namespace: apps
+    type: rest
+portForwards:
+  api:
+    from: rest
+    target: service/api
+    localPort: 8080
+  legacy:
+    target: service/legacy
+    localPort: 8081
+    disabled: true
+`)
+	writeProfile("prod", `
+portForwards:
+  api:
+    target: service/api
+    targetPort: 80
+    localPort: 9080
+    namespace: apps
+    kubectl:
+      context: prod-readonly
+`)
+
+	base := &Config{
+		PortForwards: map[string]Service{"default-service": {Target: "service/x", LocalPort: 7000}},
+	}
+	cfg, err := loadProfilesFrom(base, dir, []string{"staging", "prod"})
+	if err != nil {
+		t.Fatalf("loadProfilesFrom failed: %v", err)
+	}
+
+	if len(cfg.PortForwards) != 2 {
+		t.Fatalf("Expected only the profiles' enabled services, got %v", cfg.PortForwards)
+	}
+	staging := cfg.PortForwards["staging/api"]
+	if staging.Profile != "staging" || staging.Kubectl.Context != "staging-cluster" || staging.TargetPort != 80 {
+		t.Errorf("Expected staging service with profile, context and template fields, got %+v", staging)
+	}
+	if prod := cfg.PortForwards["prod/api"]; prod.Kubectl.Context != "prod-readonly" {
+		t.Errorf("Expected a service's own context to win over the profile's, got %q", prod.Kubectl.Context)
+	}
+
+	if _, err := loadProfilesFrom(base, dir, []string{"missing"}); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected error for a missing profile, got %v", err)
+	}
+	if _, err := loadProfilesFrom(base, dir, []string{"../etc"}); err == nil {
+		t.Error("Expected error for a profile name with a path")
+	}
+}
+
+func TestListProfilesIn(t *testing.T) {
+	dir := t.TempDir()
+	if names, err := listProfilesIn(filepath.Join(dir, "missing")); err != nil || len(names) != 0 {
+		t.Fatalf("Expected no profiles in a missing directory, got %v, %v", names, err)
+	}
+
+	for _, name := range []string{"staging.yaml", "prod.yaml", "notes.txt", "bad name.yaml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "archive.yaml"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := listProfilesIn(dir)
+	if err != nil {
+		t.Fatalf("listProfilesIn failed: %v", err)
+	}
+	if strings.Join(names, ",") != "prod,staging" {
+		t.Errorf("Expected prod,staging, got %v", names)
+	}
+}
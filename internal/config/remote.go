@@ -1,12 +1,17 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -18,72 +23,215 @@ const DefaultRemoteConfigURL = "https://raw.githubusercontent.com/catio-tech/kpo
 // RemoteConfigTimeout is the HTTP timeout for fetching remote config
 const RemoteConfigTimeout = 5 * time.Second
 
+// RemoteCacheMaxAge is how old the cached remote config may be before it is no longer
+// used, so an outdated service list does not stay in use forever while offline
+const RemoteCacheMaxAge = 30 * 24 * time.Hour
+
+// DefaultRemoteConfigTTL is how long a fetched remote config is used without asking the
+// server whether it changed
+const DefaultRemoteConfigTTL = 15 * time.Minute
+
+// remoteCacheHeader starts the cache file; the source, fetch time, checksum and HTTP
+// validators of the config follow as comments, so the file is still a plain config
+const remoteCacheHeader = "# kportforward remote defaults cache\n"
+
 // remoteConfigURL holds the active remote URL (can be overridden via CLI flag)
 var remoteConfigURL = DefaultRemoteConfigURL
 
+// remoteConfigTTL holds the --config-ttl flag
+var remoteConfigTTL = DefaultRemoteConfigTTL
+
 // SetRemoteConfigURL sets the remote config URL. Pass "" to disable remote loading.
 func SetRemoteConfigURL(url string) {
 	remoteConfigURL = url
 }
 
+// SetRemoteConfigTTL sets how long a fetched remote config is used before the server is
+// asked again whether it changed, and how often WatchRemote asks. 0 asks on every load.
+func SetRemoteConfigTTL(ttl time.Duration) {
+	remoteConfigTTL = ttl
+}
+
+// remoteValidators are the HTTP validators of a fetched remote config, sent on the next
+// fetch so an unchanged config is not downloaded again
+type remoteValidators struct {
+	ETag         string
+	LastModified string
+}
+
+// remoteCache is the cached copy of the remote config, see cacheRemoteConfig
+type remoteCache struct {
+	data       []byte
+	fetched    time.Time // Last time the server returned or confirmed it
+	validators remoteValidators
+}
+
+// remoteDefaultsUnavailable is set when the last load fell back to the embedded defaults
+// because neither the remote config nor a cached copy could be used
+var remoteDefaultsUnavailable bool
+
+// remoteDefaultsWarning explains why the last load did not use the remote config, see
+// RemoteDefaultsWarning
+var remoteDefaultsWarning string
+
 // GetRemoteConfigURL returns the current remote config URL
 func GetRemoteConfigURL() string {
 	return remoteConfigURL
 }
 
+// RemoteDefaultsWarning describes the defaults the last load fell back to when the remote
+// config could not be fetched, such as the age of the cached copy used, or "" if the
+// remote config was used or is disabled
+func RemoteDefaultsWarning() string {
+	return remoteDefaultsWarning
+}
+
 // loadDefaultsWithRemote tries to load default config with the following fallback chain:
-//  1. Fetch from remote URL (with timeout)
-//  2. Use locally cached copy of last successful remote fetch
-//  3. Fall back to embedded default.yaml compiled into the binary
+//  1. Use the locally cached copy if it was fetched within the TTL
+//  2. Fetch from remote URL (with timeout), unless it did not change since it was cached
+//  3. Use locally cached copy of last successful remote fetch
+//  4. Fall back to embedded default.yaml compiled into the binary
 func loadDefaultsWithRemote() ([]byte, error) {
+	remoteDefaultsUnavailable = false
+	remoteDefaultsWarning = ""
+
 	// If remote URL is disabled, go straight to embedded defaults
 	if remoteConfigURL == "" {
 		return DefaultConfigYAML, nil
 	}
 
-	// Step 1: Try fetching from remote
-	data, err := fetchRemoteConfig(remoteConfigURL, RemoteConfigTimeout)
+	// Step 1: A recently fetched copy is used without asking the server
+	now := time.Now()
+	cached, cacheErr := getCachedRemoteConfig(remoteConfigURL, now)
+	if cacheErr == nil && now.Sub(cached.fetched) < remoteConfigTTL {
+		return cached.data, nil
+	}
+
+	// Step 2: Try fetching from remote
+	var previous *remoteCache
+	if cacheErr == nil {
+		previous = &cached
+	}
+	data, validators, err := fetchRemoteConfigIfChanged(remoteConfigURL, RemoteConfigTimeout, previous)
 	if err == nil {
 		// Cache for offline use (best-effort, don't fail on cache errors)
-		_ = cacheRemoteConfig(data)
+		_ = cacheRemoteConfig(data, remoteConfigURL, now, validators)
 		return data, nil
 	}
 
-	// Step 2: Remote failed — try local cache
-	cached, cacheErr := getCachedRemoteConfig()
+	// Step 3: Remote failed — try local cache
 	if cacheErr == nil {
-		return cached, nil
+		remoteDefaultsWarning = fmt.Sprintf("Using the remote config cached %s ago (%v)",
+			time.Since(cached.fetched).Round(time.Minute), err)
+		return cached.data, nil
 	}
 
-	// Step 3: Both failed — fall back to embedded defaults
+	// Step 4: Both failed — fall back to embedded defaults
+	remoteDefaultsUnavailable = true
+	remoteDefaultsWarning = fmt.Sprintf("Using the built-in defaults (%v; %v)", err, cacheErr)
 	return DefaultConfigYAML, nil
 }
 
-// fetchRemoteConfig performs an HTTP GET to retrieve config YAML from the given URL.
+// WatchRemote asks the remote config server every TTL whether the shared defaults
+// changed, until ctx is cancelled, and calls onChange once a changed config was cached,
+// so the next load uses it. Nothing is watched without a remote URL or TTL.
+func WatchRemote(ctx context.Context, onChange func()) {
+	url, ttl := remoteConfigURL, remoteConfigTTL
+	if url == "" || ttl <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if refreshRemoteConfig(url, time.Now()) {
+			onChange()
+		}
+	}
+}
+
+// refreshRemoteConfig caches the remote config at url again and reports whether it
+// changed. Failures leave the cache as it is, to be retried on the next refresh.
+func refreshRemoteConfig(url string, now time.Time) bool {
+	var previous *remoteCache
+	if cached, err := getCachedRemoteConfig(url, now); err == nil {
+		previous = &cached
+	}
+	data, validators, err := fetchRemoteConfigIfChanged(url, RemoteConfigTimeout, previous)
+	if err != nil || cacheRemoteConfig(data, url, now, validators) != nil {
+		return false
+	}
+	return previous == nil || remoteConfigChecksum(data) != remoteConfigChecksum(previous.data)
+}
+
+// fetchRemoteConfig performs an HTTP GET to retrieve config YAML from the given URL,
+// through the environment's proxy and trusting the CA file and pins of SetRemoteTLS,
+// and verifies it as set with SetRemoteVerification.
 func fetchRemoteConfig(url string, timeout time.Duration) ([]byte, error) {
-	client := &http.Client{Timeout: timeout}
+	data, _, err := fetchRemoteConfigIfChanged(url, timeout, nil)
+	return data, err
+}
 
-	resp, err := client.Get(url)
+// fetchRemoteConfigIfChanged is fetchRemoteConfig for a config cached as previous (if not
+// nil): the server only sends it again if it changed, otherwise the cached copy is
+// verified and returned. It also returns the validators to send on the next fetch.
+func fetchRemoteConfigIfChanged(url string, timeout time.Duration, previous *remoteCache) ([]byte, remoteValidators, error) {
+	client, err := remoteHTTPClient(timeout)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch remote config: %w", err)
+		return nil, remoteValidators{}, fmt.Errorf("failed to fetch remote config: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("remote config returned HTTP %d", resp.StatusCode)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, remoteValidators{}, fmt.Errorf("failed to fetch remote config: %w", err)
+	}
+	if previous != nil {
+		if previous.validators.ETag != "" {
+			req.Header.Set("If-None-Match", previous.validators.ETag)
+		}
+		if previous.validators.LastModified != "" {
+			req.Header.Set("If-Modified-Since", previous.validators.LastModified)
+		}
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read remote config body: %w", err)
+		return nil, remoteValidators{}, fmt.Errorf("failed to fetch remote config: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var data []byte
+	validators := remoteValidators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	switch {
+	case resp.StatusCode == http.StatusNotModified && previous != nil:
+		data = previous.data
+		if validators == (remoteValidators{}) {
+			validators = previous.validators
+		}
+	case resp.StatusCode != http.StatusOK:
+		return nil, remoteValidators{}, fmt.Errorf("remote config returned HTTP %d", resp.StatusCode)
+	default:
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return nil, remoteValidators{}, fmt.Errorf("failed to read remote config body: %w", err)
+		}
+	}
+
+	// Verify before parsing, so nothing is used or cached that was not published as is
+	if err := verifyRemoteConfig(client, url, data); err != nil {
+		return nil, remoteValidators{}, err
 	}
 
 	// Validate: ensure the fetched data is a parseable config with at least one service
 	if err := validateConfigYAML(data); err != nil {
-		return nil, fmt.Errorf("remote config validation failed: %w", err)
+		return nil, remoteValidators{}, fmt.Errorf("remote config validation failed: %w", err)
 	}
 
-	return data, nil
+	return data, validators, nil
 }
 
 // validateConfigYAML checks that raw YAML parses into a Config with at least one port forward.
@@ -98,6 +246,11 @@ func validateConfigYAML(data []byte) error {
 	return nil
 }
 
+// RemoteCachePath returns the path of the cached remote default config
+func RemoteCachePath() (string, error) {
+	return getRemoteCachePath()
+}
+
 // getRemoteCachePath returns the filesystem path for the cached remote config.
 // Uses the same base directory as the user config (%APPDATA% on Windows, ~/.config on Unix).
 func getRemoteCachePath() (string, error) {
@@ -120,27 +273,81 @@ func getRemoteCachePath() (string, error) {
 	return filepath.Join(configDir, "kportforward", "remote-defaults-cache.yaml"), nil
 }
 
-// getCachedRemoteConfig reads and validates the locally cached remote config.
-func getCachedRemoteConfig() ([]byte, error) {
+// getCachedRemoteConfig reads the locally cached remote config. A cache fetched from
+// another URL than url, changed since it was written or older than RemoteCacheMaxAge is
+// not used.
+func getCachedRemoteConfig(url string, now time.Time) (remoteCache, error) {
 	cachePath, err := getRemoteCachePath()
 	if err != nil {
-		return nil, err
+		return remoteCache{}, err
 	}
 
 	data, err := os.ReadFile(cachePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read cached remote config: %w", err)
+		return remoteCache{}, fmt.Errorf("failed to read cached remote config: %w", err)
 	}
 
-	if err := validateConfigYAML(data); err != nil {
-		return nil, fmt.Errorf("cached remote config is invalid: %w", err)
+	source, checksum, cache := parseRemoteCache(data)
+	switch {
+	case source == "" || cache.fetched.IsZero() || checksum == "":
+		return remoteCache{}, fmt.Errorf("cached remote config has no source, fetch time or checksum")
+	case source != url:
+		return remoteCache{}, fmt.Errorf("cached remote config is from %s, not %s", source, url)
+	case checksum != remoteConfigChecksum(cache.data):
+		return remoteCache{}, fmt.Errorf("cached remote config does not match its checksum")
+	case now.Sub(cache.fetched) > RemoteCacheMaxAge:
+		return remoteCache{}, fmt.Errorf("cached remote config is %d days old (at most %d are used)",
+			int(now.Sub(cache.fetched).Hours()/24), int(RemoteCacheMaxAge.Hours()/24))
+	}
+
+	if err := validateConfigYAML(cache.data); err != nil {
+		return remoteCache{}, fmt.Errorf("cached remote config is invalid: %w", err)
 	}
 
-	return data, nil
+	return cache, nil
 }
 
-// cacheRemoteConfig saves remote config data to the local cache file.
-func cacheRemoteConfig(data []byte) error {
+// parseRemoteCache splits a cache file into the source and checksum recorded in its
+// header and the cached config. Values missing from the header are left empty; caches
+// written before validators were recorded have none.
+func parseRemoteCache(data []byte) (source, checksum string, cache remoteCache) {
+	rest, ok := bytes.CutPrefix(data, []byte(remoteCacheHeader))
+	if !ok {
+		return "", "", remoteCache{data: data}
+	}
+	for _, key := range []string{"source", "fetched", "sha256", "etag", "last-modified"} {
+		line, remaining, _ := bytes.Cut(rest, []byte("\n"))
+		value, ok := strings.CutPrefix(string(line), "# "+key+": ")
+		if !ok {
+			break
+		}
+		rest = remaining
+		switch key {
+		case "source":
+			source = value
+		case "fetched":
+			cache.fetched, _ = time.Parse(time.RFC3339, value)
+		case "sha256":
+			checksum = value
+		case "etag":
+			cache.validators.ETag = value
+		case "last-modified":
+			cache.validators.LastModified = value
+		}
+	}
+	cache.data = rest
+	return source, checksum, cache
+}
+
+// remoteConfigChecksum returns the hex SHA-256 of a config
+func remoteConfigChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheRemoteConfig saves remote config data fetched from url to the local cache file,
+// preceded by its source, fetch time, checksum and validators.
+func cacheRemoteConfig(data []byte, url string, fetched time.Time, validators remoteValidators) error {
 	cachePath, err := getRemoteCachePath()
 	if err != nil {
 		return err
@@ -151,7 +358,18 @@ func cacheRemoteConfig(data []byte) error {
 		return fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	if err := os.WriteFile(cachePath, data, 0644); err != nil {
+	header := fmt.Sprintf("%s# source: %s\n# fetched: %s\n# sha256: %s\n# etag: %s\n# last-modified: %s\n",
+		remoteCacheHeader, url, fetched.UTC().Format(time.RFC3339), remoteConfigChecksum(data),
+		validators.ETag, validators.LastModified)
+
+	// Written next to the cache and renamed over it, so a concurrent load never reads
+	// half of it while WatchRemote refreshes it
+	temp := cachePath + ".tmp"
+	if err := os.WriteFile(temp, append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(temp, cachePath); err != nil {
+		os.Remove(temp)
 		return fmt.Errorf("failed to write cache file: %w", err)
 	}
 
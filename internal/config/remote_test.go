@@ -4,8 +4,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
-	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // validTestYAML is a minimal valid config for testing
@@ -24,10 +26,7 @@ uiOptions:
 
 func TestFetchRemoteConfigSuccess(t *testing.T) {
 	// Start a local HTTP server that returns valid YAML
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "text/plain")
-		w.Write([]byte(validTestYAML))
-	}))
+	server := httptest.NewServer(remoteConfigHandler(validTestYAML))
 	defer server.Close()
 
 	data, err := fetchRemoteConfig(server.URL, RemoteConfigTimeout)
@@ -63,9 +62,7 @@ func TestFetchRemoteConfigHTTPError(t *testing.T) {
 
 func TestFetchRemoteConfigInvalidYAML(t *testing.T) {
 	// Server that returns garbage instead of YAML
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("this is not valid yaml: [[["))
-	}))
+	server := httptest.NewServer(remoteConfigHandler("this is not valid yaml: [[["))
 	defer server.Close()
 
 	_, err := fetchRemoteConfig(server.URL, RemoteConfigTimeout)
@@ -76,9 +73,7 @@ func TestFetchRemoteConfigInvalidYAML(t *testing.T) {
 
 func TestFetchRemoteConfigEmptyPortForwards(t *testing.T) {
 	// Server returns valid YAML but with no port forwards
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("monitoringInterval: 1s\n"))
-	}))
+	server := httptest.NewServer(remoteConfigHandler("monitoringInterval: 1s\n"))
 	defer server.Close()
 
 	_, err := fetchRemoteConfig(server.URL, RemoteConfigTimeout)
@@ -126,41 +121,82 @@ func TestValidateConfigYAML(t *testing.T) {
 }
 
 func TestCacheRoundTrip(t *testing.T) {
-	// Use a temp directory for cache
-	tmpDir := t.TempDir()
-	cachePath := filepath.Join(tmpDir, "kportforward", "remote-defaults-cache.yaml")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+	const url = "https://config.example.com/defaults.yaml"
+	fetched := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
 
-	// Write cache
-	err := os.MkdirAll(filepath.Dir(cachePath), 0755)
-	if err != nil {
-		t.Fatalf("Failed to create cache dir: %v", err)
+	validators := remoteValidators{ETag: `"abc123"`, LastModified: "Sun, 01 Mar 2026 11:00:00 GMT"}
+	if err := cacheRemoteConfig([]byte(validTestYAML), url, fetched, validators); err != nil {
+		t.Fatalf("Failed to write cache: %v", err)
 	}
-
-	err = os.WriteFile(cachePath, []byte(validTestYAML), 0644)
+	cached, err := getCachedRemoteConfig(url, fetched.Add(24*time.Hour))
 	if err != nil {
-		t.Fatalf("Failed to write cache: %v", err)
+		t.Fatalf("Failed to read cache: %v", err)
+	}
+	if string(cached.data) != validTestYAML || !cached.fetched.Equal(fetched) || cached.validators != validators {
+		t.Errorf("Expected the cached config fetched at %v with %+v, got %v with %+v:\n%s",
+			fetched, validators, cached.fetched, cached.validators, cached.data)
 	}
+}
 
-	// Read it back and validate
-	data, err := os.ReadFile(cachePath)
+func TestCachedRemoteConfigIntegrity(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+	const url = "https://config.example.com/defaults.yaml"
+	fetched := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cachePath, err := getRemoteCachePath()
 	if err != nil {
-		t.Fatalf("Failed to read cache: %v", err)
+		t.Fatalf("Failed to get cache path: %v", err)
 	}
 
-	if err := validateConfigYAML(data); err != nil {
-		t.Fatalf("Cached data failed validation: %v", err)
+	tests := []struct {
+		name     string
+		write    func() error
+		url      string
+		now      time.Time
+		expected string
+	}{
+		{"other source", func() error { return cacheRemoteConfig([]byte(validTestYAML), url, fetched, remoteValidators{}) },
+			"https://other.example.com/defaults.yaml", fetched, "is from " + url},
+		{"expired", func() error { return cacheRemoteConfig([]byte(validTestYAML), url, fetched, remoteValidators{}) },
+			url, fetched.Add(RemoteCacheMaxAge + 24*time.Hour), "31 days old"},
+		{"changed", func() error {
+			if err := cacheRemoteConfig([]byte(validTestYAML), url, fetched, remoteValidators{}); err != nil {
+				return err
+			}
+			data, err := os.ReadFile(cachePath)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(cachePath, []byte(strings.Replace(string(data), "9090", "9091", 1)), 0644)
+		}, url, fetched, "does not match its checksum"},
+		{"without header", func() error { return os.WriteFile(cachePath, []byte(validTestYAML), 0644) },
+			url, fetched, "has no source"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.write(); err != nil {
+				t.Fatalf("Failed to write cache: %v", err)
+			}
+			if _, err := getCachedRemoteConfig(test.url, test.now); err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("Expected an error containing %q, got %v", test.expected, err)
+			}
+		})
 	}
 }
 
 func TestLoadDefaultsWithRemoteFallbackChain(t *testing.T) {
+	// Keep the remote cache written by this test out of the real user config dir
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+
 	// Save and restore the original remote URL
 	originalURL := GetRemoteConfigURL()
 	defer SetRemoteConfigURL(originalURL)
 
 	t.Run("remote succeeds", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Write([]byte(validTestYAML))
-		}))
+		server := httptest.NewServer(remoteConfigHandler(validTestYAML))
 		defer server.Close()
 
 		SetRemoteConfigURL(server.URL)
@@ -190,6 +226,10 @@ func TestLoadDefaultsWithRemoteFallbackChain(t *testing.T) {
 		if err := validateConfigYAML(data); err != nil {
 			t.Fatalf("Fallback data validation failed: %v", err)
 		}
+		// The cache of the first subtest is from another URL
+		if warning := RemoteDefaultsWarning(); !strings.Contains(warning, "built-in defaults") || !strings.Contains(warning, "is from") {
+			t.Errorf("Expected a warning about the built-in defaults, got %q", warning)
+		}
 	})
 
 	t.Run("remote disabled, uses embedded", func(t *testing.T) {
@@ -206,3 +246,67 @@ func TestLoadDefaultsWithRemoteFallbackChain(t *testing.T) {
 		}
 	})
 }
+
+func TestRemoteConfigRevalidation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+	originalURL := GetRemoteConfigURL()
+	defer SetRemoteConfigURL(originalURL)
+	defer SetRemoteConfigTTL(DefaultRemoteConfigTTL)
+
+	var mutex sync.Mutex
+	current, etag := validTestYAML, `"v1"`
+	downloads, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if strings.HasSuffix(r.URL.Path, remoteChecksumSuffix) {
+			w.Write([]byte(remoteConfigChecksum([]byte(current))))
+			return
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(current))
+	}))
+	defer server.Close()
+	url := server.URL + "/defaults.yaml"
+	SetRemoteConfigURL(url)
+
+	load := func() string {
+		t.Helper()
+		data, err := loadDefaultsWithRemote()
+		if err != nil || RemoteDefaultsWarning() != "" {
+			t.Fatalf("Expected the remote config, got %v (%s)", err, RemoteDefaultsWarning())
+		}
+		return string(data)
+	}
+
+	SetRemoteConfigTTL(time.Hour)
+	load()
+	if data := load(); data != validTestYAML || downloads != 1 || notModified != 0 {
+		t.Errorf("Expected the config fetched within the TTL to be used without asking, got %d downloads and %d revalidations", downloads, notModified)
+	}
+
+	SetRemoteConfigTTL(0)
+	if data := load(); data != validTestYAML || downloads != 1 || notModified != 1 {
+		t.Errorf("Expected the cached config to be revalidated rather than downloaded, got %d downloads and %d revalidations", downloads, notModified)
+	}
+
+	if refreshRemoteConfig(url, time.Now()) {
+		t.Error("Expected no change to be reported for an unchanged config")
+	}
+	mutex.Lock()
+	current, etag = strings.Replace(validTestYAML, "9090", "9091", 1), `"v2"`
+	mutex.Unlock()
+	if !refreshRemoteConfig(url, time.Now()) {
+		t.Fatal("Expected the changed config to be reported")
+	}
+	if data := load(); !strings.Contains(data, "9091") {
+		t.Errorf("Expected the changed config to be loaded, got:\n%s", data)
+	}
+}
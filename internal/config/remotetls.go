@@ -0,0 +1,117 @@
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Environment variables trusting an org-internal config server, used unless the
+// --config-ca-file and --config-pin flags are given
+const (
+	RemoteCAFileEnvVar = "KPORTFORWARD_CONFIG_CA_FILE" // PEM bundle trusted for the remote config
+	RemotePinEnvVar    = "KPORTFORWARD_CONFIG_PIN"     // Comma-separated public key pins
+)
+
+// remotePinPrefix starts a public key pin: the base64 SHA-256 of a certificate's
+// SubjectPublicKeyInfo, as printed by
+// openssl x509 -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+const remotePinPrefix = "sha256/"
+
+// remoteCAFile and remotePins hold the --config-ca-file and --config-pin flags
+var (
+	remoteCAFile string
+	remotePins   []string
+)
+
+// SetRemoteTLS trusts the certificates in the PEM file caFile, in addition to the system
+// roots, and requires the remote config's server to present one of the public keys
+// pinned in pins. Empty values fall back to the environment variables.
+func SetRemoteTLS(caFile string, pins []string) {
+	remoteCAFile, remotePins = caFile, pins
+}
+
+// remoteTLSSettings returns the CA file and pins from the flags or the environment
+func remoteTLSSettings() (caFile string, pins []string) {
+	caFile = remoteCAFile
+	if caFile == "" {
+		caFile = os.Getenv(RemoteCAFileEnvVar)
+	}
+	pins = remotePins
+	if len(pins) == 0 {
+		for _, pin := range strings.Split(os.Getenv(RemotePinEnvVar), ",") {
+			if pin = strings.TrimSpace(pin); pin != "" {
+				pins = append(pins, pin)
+			}
+		}
+	}
+	return caFile, pins
+}
+
+// remoteHTTPClient returns the client fetching the remote config. It honors
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY and applies the CA file and pins.
+func remoteHTTPClient(timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	caFile, pins := remoteTLSSettings()
+	if caFile == "" && len(pins) == 0 {
+		return &http.Client{Timeout: timeout, Transport: transport}, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA file %s contains no PEM certificates", caFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+	if len(pins) > 0 {
+		for _, pin := range pins {
+			if !strings.HasPrefix(pin, remotePinPrefix) {
+				return nil, fmt.Errorf("invalid pin %q (expected %s<base64 SHA-256 of the public key>)", pin, remotePinPrefix)
+			}
+		}
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPins(state, pins)
+		}
+	}
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+// verifyPins accepts a verified connection if a certificate of its chain has one of the
+// pinned public keys, so pinning the key of an intermediate CA survives server renewals
+func verifyPins(state tls.ConnectionState, pins []string) error {
+	chains := state.VerifiedChains
+	if len(chains) == 0 {
+		chains = [][]*x509.Certificate{state.PeerCertificates}
+	}
+	for _, chain := range chains {
+		for _, cert := range chain {
+			sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			pin := remotePinPrefix + base64.StdEncoding.EncodeToString(sum[:])
+			for _, pinned := range pins {
+				if pin == pinned {
+					return nil
+				}
+			}
+		}
+	}
+	return errors.New("server certificate matches none of the pinned public keys")
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFetchRemoteConfigTLS(t *testing.T) {
+	defer SetRemoteTLS("", nil)
+	t.Setenv(RemoteCAFileEnvVar, "")
+	t.Setenv(RemotePinEnvVar, "")
+
+	server := httptest.NewTLSServer(remoteConfigHandler(validTestYAML))
+	defer server.Close()
+	cert := server.Certificate()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	pin := "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+
+	if _, err := fetchRemoteConfig(server.URL, RemoteConfigTimeout); err == nil {
+		t.Fatal("Expected the test server's certificate to be untrusted without the CA file")
+	}
+
+	// The environment variables apply unless the flags are set
+	t.Setenv(RemoteCAFileEnvVar, caFile)
+	if _, err := fetchRemoteConfig(server.URL, RemoteConfigTimeout); err != nil {
+		t.Fatalf("Expected the CA file to be trusted, got %v", err)
+	}
+
+	SetRemoteTLS(caFile, []string{pin})
+	if _, err := fetchRemoteConfig(server.URL, RemoteConfigTimeout); err != nil {
+		t.Fatalf("Expected the pinned key to be accepted, got %v", err)
+	}
+
+	SetRemoteTLS(caFile, []string{"sha256/" + base64.StdEncoding.EncodeToString(make([]byte, 32))})
+	if _, err := fetchRemoteConfig(server.URL, RemoteConfigTimeout); err == nil || !strings.Contains(err.Error(), "pinned") {
+		t.Fatalf("Expected a pin mismatch, got %v", err)
+	}
+
+	SetRemoteTLS(caFile, []string{"not-a-pin"})
+	if _, err := fetchRemoteConfig(server.URL, RemoteConfigTimeout); err == nil || !strings.Contains(err.Error(), "invalid pin") {
+		t.Fatalf("Expected an invalid pin error, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// RemotePublicKeyEnvVar holds the key the remote config must be signed with, used unless
+// the --config-public-key flag is given
+const RemotePublicKeyEnvVar = "KPORTFORWARD_CONFIG_PUBLIC_KEY"
+
+// Files published next to the remote config: its SHA-256 in sha256sum format, and
+// optionally its base64 Ed25519 signature
+const (
+	remoteChecksumSuffix  = ".sha256"
+	remoteSignatureSuffix = ".sig"
+)
+
+// remoteManifestLimit bounds the size of the checksum and signature files
+const remoteManifestLimit = 4096
+
+// remoteInsecure and remotePublicKey hold the --insecure-remote-config and
+// --config-public-key flags
+var (
+	remoteInsecure  bool
+	remotePublicKey string
+)
+
+// SetRemoteVerification sets how the remote config is verified before it is used or
+// cached. It must match the checksum published next to it and, with publicKey (a base64
+// Ed25519 public key), carry a signature by that key. insecure skips both checks.
+func SetRemoteVerification(insecure bool, publicKey string) {
+	remoteInsecure, remotePublicKey = insecure, publicKey
+}
+
+// verifyRemoteConfig checks data fetched from configURL against the checksum and, if a
+// public key is set, the signature published next to it
+func verifyRemoteConfig(client *http.Client, configURL string, data []byte) error {
+	if remoteInsecure {
+		return nil
+	}
+
+	manifest, err := fetchRemoteSibling(client, configURL, remoteChecksumSuffix, "checksum file")
+	if err != nil {
+		return fmt.Errorf("%w (use --insecure-remote-config to skip verification)", err)
+	}
+	fields := strings.Fields(string(manifest))
+	if len(fields) == 0 {
+		return fmt.Errorf("remote config checksum file is empty")
+	}
+	if _, err := hex.DecodeString(fields[0]); err != nil || len(fields[0]) != 2*32 {
+		return fmt.Errorf("remote config checksum file does not start with a SHA-256")
+	}
+	if actual := remoteConfigChecksum(data); !strings.EqualFold(fields[0], actual) {
+		return fmt.Errorf("remote config has checksum %s, but %s%s lists %s", actual, configURL, remoteChecksumSuffix, fields[0])
+	}
+
+	publicKey := remotePublicKey
+	if publicKey == "" {
+		publicKey = os.Getenv(RemotePublicKeyEnvVar)
+	}
+	if publicKey == "" {
+		return nil
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid config public key (expected the base64 of a %d byte Ed25519 key)", ed25519.PublicKeySize)
+	}
+	encoded, err := fetchRemoteSibling(client, configURL, remoteSignatureSuffix, "signature")
+	if err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return fmt.Errorf("remote config signature is not base64: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), data, signature) {
+		return fmt.Errorf("remote config signature does not match the config public key")
+	}
+	return nil
+}
+
+// fetchRemoteSibling fetches the file published next to the remote config whose name
+// adds suffix to the config's; what names it in errors
+func fetchRemoteSibling(client *http.Client, configURL, suffix, what string) ([]byte, error) {
+	parsed, err := url.Parse(configURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote config URL: %w", err)
+	}
+	parsed.Path += suffix
+	parsed.RawPath = ""
+
+	resp, err := client.Get(parsed.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote config %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote config %s returned HTTP %d", what, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, remoteManifestLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote config %s: %w", what, err)
+	}
+	return data, nil
+}
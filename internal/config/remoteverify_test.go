@@ -0,0 +1,102 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// remoteConfigHandler serves data as the remote config along with its checksum file
+func remoteConfigHandler(data string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, remoteChecksumSuffix) {
+			w.Write([]byte(remoteConfigChecksum([]byte(data)) + "  default.yaml\n"))
+			return
+		}
+		w.Write([]byte(data))
+	})
+}
+
+func TestEmbeddedDefaultsChecksum(t *testing.T) {
+	manifest, err := os.ReadFile("default.yaml.sha256")
+	if err != nil {
+		t.Fatalf("Failed to read the checksum of the defaults: %v", err)
+	}
+	if fields := strings.Fields(string(manifest)); len(fields) != 2 || fields[0] != remoteConfigChecksum(DefaultConfigYAML) {
+		t.Errorf("default.yaml.sha256 does not match default.yaml, update it with: sha256sum default.yaml > default.yaml.sha256")
+	}
+}
+
+func TestVerifyRemoteConfig(t *testing.T) {
+	defer SetRemoteVerification(false, "")
+	t.Setenv(RemotePublicKeyEnvVar, "")
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	encodedKey := base64.StdEncoding.EncodeToString(publicKey)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(validTestYAML)))
+	otherSignature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte("other")))
+
+	tests := []struct {
+		name      string
+		checksum  string // Served as the checksum file, if set
+		signature string // Served as the signature file, if set
+		insecure  bool
+		publicKey string
+		wantErr   string
+	}{
+		{name: "checksum matches", checksum: remoteConfigChecksum([]byte(validTestYAML)) + "  default.yaml\n"},
+		{name: "checksum alone", checksum: strings.ToUpper(remoteConfigChecksum([]byte(validTestYAML)))},
+		{name: "missing checksum", wantErr: "--insecure-remote-config"},
+		{name: "checksum mismatch", checksum: remoteConfigChecksum([]byte("other")) + "  default.yaml\n", wantErr: "lists"},
+		{name: "not a checksum", checksum: "<html>", wantErr: "does not start with a SHA-256"},
+		{name: "insecure skips verification", insecure: true, publicKey: encodedKey},
+		{name: "signed", checksum: remoteConfigChecksum([]byte(validTestYAML)), signature: signature, publicKey: encodedKey},
+		{name: "missing signature", checksum: remoteConfigChecksum([]byte(validTestYAML)), publicKey: encodedKey, wantErr: "signature returned HTTP 404"},
+		{name: "wrong signature", checksum: remoteConfigChecksum([]byte(validTestYAML)), signature: otherSignature, publicKey: encodedKey, wantErr: "does not match"},
+		{name: "invalid public key", checksum: remoteConfigChecksum([]byte(validTestYAML)), signature: signature, publicKey: "c2hvcnQ=", wantErr: "invalid config public key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/defaults.yaml":
+					w.Write([]byte(validTestYAML))
+				case r.URL.Path == "/defaults.yaml"+remoteChecksumSuffix && tt.checksum != "":
+					w.Write([]byte(tt.checksum))
+				case r.URL.Path == "/defaults.yaml"+remoteSignatureSuffix && tt.signature != "":
+					w.Write([]byte(tt.signature + "\n"))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			SetRemoteVerification(tt.insecure, tt.publicKey)
+			_, err := fetchRemoteConfig(server.URL+"/defaults.yaml?ref=main", RemoteConfigTimeout)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Expected the config to be verified, got %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// The environment variable applies unless the flag is set
+	SetRemoteVerification(false, "")
+	t.Setenv(RemotePublicKeyEnvVar, encodedKey)
+	server := httptest.NewServer(remoteConfigHandler(validTestYAML))
+	defer server.Close()
+	if _, err := fetchRemoteConfig(server.URL, RemoteConfigTimeout); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("Expected the unsigned config to be rejected with the public key from the environment, got %v", err)
+	}
+}
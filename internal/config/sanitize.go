@@ -0,0 +1,60 @@
+package config
+
+import (
+	"net/url"
+)
+
+// redacted replaces secrets in sanitized configurations
+const redacted = "REDACTED"
+
+// Sanitized returns a copy of cfg that is safe to attach to bug reports: credentials
+// and query parameters are removed from link URLs, which is where tokens end up
+func Sanitized(cfg *Config) *Config {
+	if cfg == nil {
+		return nil
+	}
+
+	sanitized := *cfg
+	sanitized.PortForwards = sanitizeServices(cfg.PortForwards)
+	sanitized.Templates = sanitizeServices(cfg.Templates)
+	return &sanitized
+}
+
+// sanitizeServices copies services with their links sanitized
+func sanitizeServices(services map[string]Service) map[string]Service {
+	if services == nil {
+		return nil
+	}
+
+	sanitized := make(map[string]Service, len(services))
+	for name, service := range services {
+		if len(service.Links) > 0 {
+			links := make([]ServiceLink, len(service.Links))
+			for i, link := range service.Links {
+				links[i] = ServiceLink{Name: link.Name, URL: SanitizeURL(link.URL)}
+			}
+			service.Links = links
+		}
+		sanitized[name] = service
+	}
+	return sanitized
+}
+
+// SanitizeURL redacts the user info and query parameter values of rawURL
+func SanitizeURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return redacted
+	}
+	if parsed.User != nil {
+		parsed.User = url.User(redacted)
+	}
+	if parsed.RawQuery != "" {
+		query := parsed.Query()
+		for key := range query {
+			query[key] = []string{redacted}
+		}
+		parsed.RawQuery = query.Encode()
+	}
+	return parsed.String()
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ScheduleWindow is a recurring period during which a service should be forwarded.
+// An End at or before Start spans midnight; Days refer to the day the window opens.
+type ScheduleWindow struct {
+	Days  []string `yaml:"days,omitempty"` // e.g. [mon, tue] or [weekdays]; empty means every day
+	Start string   `yaml:"start"`          // "HH:MM" in local time
+	End   string   `yaml:"end"`            // "HH:MM" in local time
+}
+
+var scheduleDayNames = map[string][]time.Weekday{
+	"sun":      {time.Sunday},
+	"mon":      {time.Monday},
+	"tue":      {time.Tuesday},
+	"wed":      {time.Wednesday},
+	"thu":      {time.Thursday},
+	"fri":      {time.Friday},
+	"sat":      {time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekends": {time.Saturday, time.Sunday},
+}
+
+// HasSchedule reports whether the service is limited to availability windows
+func (s Service) HasSchedule() bool {
+	return len(s.Schedule) > 0
+}
+
+// IsScheduledAt reports whether the service should be running at the given time.
+// Services without a schedule are always available.
+func (s Service) IsScheduledAt(t time.Time) bool {
+	if !s.HasSchedule() {
+		return true
+	}
+
+	for _, window := range s.Schedule {
+		if window.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextScheduledStart returns the next time one of the service's windows opens after t,
+// or the zero time if the service has no schedule
+func (s Service) NextScheduledStart(t time.Time) time.Time {
+	var next time.Time
+	for _, window := range s.Schedule {
+		start, _, err := window.bounds()
+		if err != nil {
+			continue
+		}
+
+		// A week ahead is enough to find the next opening of any window
+		for offset := 0; offset <= 7; offset++ {
+			day := t.AddDate(0, 0, offset)
+			candidate := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, t.Location()).Add(start)
+			if candidate.After(t) && window.appliesTo(candidate.Weekday()) {
+				if next.IsZero() || candidate.Before(next) {
+					next = candidate
+				}
+				break
+			}
+		}
+	}
+	return next
+}
+
+// contains reports whether t falls inside the window
+func (w ScheduleWindow) contains(t time.Time) bool {
+	start, end, err := w.bounds()
+	if err != nil {
+		return false
+	}
+
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	sinceMidnight := t.Sub(midnight)
+
+	if start < end {
+		return w.appliesTo(t.Weekday()) && sinceMidnight >= start && sinceMidnight < end
+	}
+
+	// Overnight window: the evening part belongs to today, the morning part to yesterday
+	if sinceMidnight >= start {
+		return w.appliesTo(t.Weekday())
+	}
+	return sinceMidnight < end && w.appliesTo(t.AddDate(0, 0, -1).Weekday())
+}
+
+// appliesTo reports whether the window opens on the given weekday
+func (w ScheduleWindow) appliesTo(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+
+	for _, name := range w.Days {
+		for _, weekday := range scheduleDayNames[strings.ToLower(name)] {
+			if weekday == day {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bounds parses the start and end times as offsets from midnight
+func (w ScheduleWindow) bounds() (time.Duration, time.Duration, error) {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start %q: %w", w.Start, err)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid end %q: %w", w.End, err)
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("start and end are both %s", w.Start)
+	}
+	return start, end, nil
+}
+
+// parseClock parses an "HH:MM" time of day
+func parseClock(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM")
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// validateSchedules rejects availability windows that cannot be parsed
+func validateSchedules(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.PortForwards))
+	for name := range cfg.PortForwards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for i, window := range cfg.PortForwards[name].Schedule {
+			if _, _, err := window.bounds(); err != nil {
+				return fmt.Errorf("service %s schedule window %d: %w", name, i+1, err)
+			}
+			for _, day := range window.Days {
+				if _, ok := scheduleDayNames[strings.ToLower(day)]; !ok {
+					return fmt.Errorf("service %s schedule window %d: unknown day %q", name, i+1, day)
+				}
+			}
+		}
+	}
+
+	return nil
+}
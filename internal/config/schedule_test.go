@@ -0,0 +1,75 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduleWindows(t *testing.T) {
+	service := Service{
+		Schedule: []ScheduleWindow{
+			{Days: []string{"weekdays"}, Start: "08:00", End: "19:00"},
+			{Days: []string{"sat"}, Start: "22:00", End: "02:00"},
+		},
+	}
+
+	// 2024-01-01 is a Monday
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected bool
+	}{
+		{"weekday inside", time.Date(2024, 1, 1, 9, 30, 0, 0, time.Local), true},
+		{"weekday before start", time.Date(2024, 1, 1, 7, 59, 0, 0, time.Local), false},
+		{"weekday at end", time.Date(2024, 1, 1, 19, 0, 0, 0, time.Local), false},
+		{"sunday daytime", time.Date(2024, 1, 7, 12, 0, 0, 0, time.Local), false},
+		{"saturday overnight evening", time.Date(2024, 1, 6, 23, 0, 0, 0, time.Local), true},
+		{"saturday overnight morning after", time.Date(2024, 1, 7, 1, 0, 0, 0, time.Local), true},
+		{"friday night is not saturday", time.Date(2024, 1, 6, 1, 0, 0, 0, time.Local), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := service.IsScheduledAt(tt.at); got != tt.expected {
+				t.Errorf("IsScheduledAt(%v) = %v, expected %v", tt.at, got, tt.expected)
+			}
+		})
+	}
+
+	if !(Service{}).IsScheduledAt(time.Now()) {
+		t.Error("Services without a schedule should always be available")
+	}
+}
+
+func TestNextScheduledStart(t *testing.T) {
+	service := Service{
+		Schedule: []ScheduleWindow{{Days: []string{"weekdays"}, Start: "08:00", End: "19:00"}},
+	}
+
+	// Friday evening -> Monday morning
+	friday := time.Date(2024, 1, 5, 20, 0, 0, 0, time.Local)
+	expected := time.Date(2024, 1, 8, 8, 0, 0, 0, time.Local)
+	if next := service.NextScheduledStart(friday); !next.Equal(expected) {
+		t.Errorf("Expected next start %v, got %v", expected, next)
+	}
+}
+
+func TestValidateSchedules(t *testing.T) {
+	cfg := &Config{
+		PortForwards: map[string]Service{
+			"ok":  {Schedule: []ScheduleWindow{{Start: "08:00", End: "19:00"}}},
+			"bad": {Schedule: []ScheduleWindow{{Days: []string{"funday"}, Start: "08:00", End: "19:00"}}},
+		},
+	}
+
+	err := validateSchedules(cfg)
+	if err == nil || !strings.Contains(err.Error(), "funday") {
+		t.Errorf("Expected unknown day error, got %v", err)
+	}
+
+	cfg.PortForwards["bad"] = Service{Schedule: []ScheduleWindow{{Start: "8am", End: "19:00"}}}
+	if err := validateSchedules(cfg); err == nil {
+		t.Error("Expected error for malformed start time")
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// HasSelector reports whether the entry expands to several services at runtime through
+// labelSelector, namespaceSelector or a wildcard namespace
+func (s Service) HasSelector() bool {
+	return s.LabelSelector != "" || s.NamespaceSelector != "" || s.HasNamespacePattern()
+}
+
+// HasNamespacePattern reports whether the namespace is a wildcard pattern such as team-*
+func (s Service) HasNamespacePattern() bool {
+	return strings.ContainsAny(s.Namespace, "*?[")
+}
+
+// MatchesNamespace reports whether namespace matches the entry's namespace pattern
+func (s Service) MatchesNamespace(namespace string) bool {
+	matched, err := path.Match(s.Namespace, namespace)
+	return err == nil && matched
+}
+
+// ExpandedName returns the name of the service a selector entry runs for a namespace
+// and, with labelSelector, a pod: the entry's name followed by the namespace when the
+// namespace is selected, and by the pod
+func (s Service) ExpandedName(entry, namespace, pod string) string {
+	name := entry
+	if s.NamespaceSelector != "" || s.HasNamespacePattern() {
+		name += "-" + namespace
+	}
+	if pod != "" {
+		name += "-" + pod
+	}
+	return name
+}
+
+// validateSelectors rejects selector entries that cannot be expanded
+func validateSelectors(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+	for name, service := range cfg.PortForwards {
+		if !service.HasSelector() {
+			continue
+		}
+		switch {
+		case service.LabelSelector != "" && service.Target != "":
+			return fmt.Errorf("service %s: labelSelector forwards to the matching pods and cannot be combined with target", name)
+		case service.LabelSelector == "" && service.Target == "":
+			return fmt.Errorf("service %s: target or labelSelector is required", name)
+		case service.NamespaceSelector != "" && service.Namespace != "":
+			return fmt.Errorf("service %s: namespaceSelector cannot be combined with namespace", name)
+		}
+		if _, err := path.Match(service.Namespace, ""); err != nil {
+			return fmt.Errorf("service %s: invalid namespace pattern %q", name, service.Namespace)
+		}
+		if service.TrackPod || len(service.Ports) > 0 {
+			return fmt.Errorf("service %s: selectors cannot be combined with trackPod or ports", name)
+		}
+	}
+	return nil
+}
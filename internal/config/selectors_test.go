@@ -0,0 +1,48 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSelectors(t *testing.T) {
+	tests := []struct {
+		name    string
+		service Service
+		wantErr string
+	}{
+		{"plain service", Service{Target: "service/api"}, ""},
+		{"pods by label", Service{LabelSelector: "app=debug", Namespace: "team"}, ""},
+		{"namespace pattern", Service{Target: "service/api", Namespace: "team-*"}, ""},
+		{"namespace selector", Service{Target: "service/api", NamespaceSelector: "team=payments"}, ""},
+		{"label and target", Service{Target: "service/api", LabelSelector: "app=debug"}, "cannot be combined with target"},
+		{"no target", Service{NamespaceSelector: "team=payments"}, "target or labelSelector is required"},
+		{"both namespaces", Service{Target: "service/api", Namespace: "team", NamespaceSelector: "team=payments"}, "cannot be combined with namespace"},
+		{"bad pattern", Service{Target: "service/api", Namespace: "team-[a"}, "invalid namespace pattern"},
+		{"track pod", Service{LabelSelector: "app=debug", TrackPod: true}, "trackPod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSelectors(&Config{PortForwards: map[string]Service{"api": tt.service}})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestExpandedName(t *testing.T) {
+	if name := (Service{LabelSelector: "app=debug"}).ExpandedName("debug", "team", "debug-1"); name != "debug-debug-1" {
+		t.Errorf("Expected the pod after the entry, got %s", name)
+	}
+	if name := (Service{Namespace: "team-*"}).ExpandedName("api", "team-a", ""); name != "api-team-a" {
+		t.Errorf("Expected the namespace after the entry, got %s", name)
+	}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// UI handlers a service type can start, each enabled with its own flag
+const (
+	UIHandlerSwagger = "swagger" // Swagger UI container for swaggerPath (--swaggerui)
+	UIHandlerGRPCUI  = "grpcui"  // grpcui web UI using server reflection (--grpcui)
+)
+
+// ServiceType defines how services of a type are shown, probed and browsed. Services
+// name their type with type:; the built-in web, rest, rpc and other types can be
+// replaced and new ones added under serviceTypes.
+type ServiceType struct {
+	URL       string `yaml:"url,omitempty"`       // Shown for the service, with {{host}}, {{port}} and {{name}} replaced; "" shows none
+	Icon      string `yaml:"icon,omitempty"`      // Shown before the URL
+	Probe     string `yaml:"probe,omitempty"`     // healthCheck.protocol of services of this type that set none
+	UIHandler string `yaml:"uiHandler,omitempty"` // UI started for the service: swagger, grpcui or "" for none
+}
+
+// builtinServiceTypes are the types known without configuration
+var builtinServiceTypes = map[string]ServiceType{
+	"web":   {URL: "http://{{host}}:{{port}}", Icon: "🌐"},
+	"rest":  {URL: "http://{{host}}:{{port}}", Icon: "🔗", UIHandler: UIHandlerSwagger},
+	"rpc":   {UIHandler: UIHandlerGRPCUI},
+	"other": {},
+}
+
+// serviceTypes are the types of the last loaded config, see LookupServiceType
+var (
+	serviceTypesMutex sync.RWMutex
+	serviceTypes      = builtinServiceTypes
+)
+
+// LookupServiceType returns a service type from the last loaded config's serviceTypes
+// or the built-in ones. Unknown types show no URL and start no UI.
+func LookupServiceType(name string) ServiceType {
+	serviceTypesMutex.RLock()
+	defer serviceTypesMutex.RUnlock()
+	return serviceTypes[name]
+}
+
+// ServiceURL returns the type's URL for a service, or "" if the type shows none
+func (t ServiceType) ServiceURL(name, host string, port int) string {
+	if t.URL == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{{host}}", host,
+		"{{port}}", strconv.Itoa(port),
+		"{{name}}", name,
+	).Replace(t.URL)
+}
+
+// applyServiceTypes validates serviceTypes, gives services without a healthCheck.protocol
+// the probe of their type and makes the types the ones LookupServiceType returns
+func applyServiceTypes(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+
+	types := make(map[string]ServiceType, len(builtinServiceTypes)+len(cfg.ServiceTypes))
+	for name, serviceType := range builtinServiceTypes {
+		types[name] = serviceType
+	}
+	names := make([]string, 0, len(cfg.ServiceTypes))
+	for name := range cfg.ServiceTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		serviceType := cfg.ServiceTypes[name]
+		if name == "" {
+			return fmt.Errorf("serviceTypes has an entry without a name")
+		}
+		switch serviceType.Probe {
+		case "", ProbeTCP, ProbePostgres, ProbeRedis, ProbeKafka, ProbeHTTP, ProbeGRPC:
+		default:
+			return fmt.Errorf("service type %s has unknown probe %q (expected tcp, postgres, redis, kafka, http or grpc)", name, serviceType.Probe)
+		}
+		switch serviceType.UIHandler {
+		case "", UIHandlerSwagger, UIHandlerGRPCUI:
+		default:
+			return fmt.Errorf("service type %s has unknown uiHandler %q (expected swagger or grpcui)", name, serviceType.UIHandler)
+		}
+		types[name] = serviceType
+	}
+
+	for name, service := range cfg.PortForwards {
+		if probe := types[service.Type].Probe; probe != "" && service.HealthCheck.Protocol == "" {
+			service.HealthCheck.Protocol = probe
+			cfg.PortForwards[name] = service
+		}
+	}
+
+	serviceTypesMutex.Lock()
+	defer serviceTypesMutex.Unlock()
+	serviceTypes = types
+	return nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyServiceTypes(t *testing.T) {
+	defer func(original map[string]ServiceType) { serviceTypes = original }(serviceTypes)
+
+	cfg := &Config{
+		ServiceTypes: map[string]ServiceType{
+			"grafana": {URL: "http://{{host}}:{{port}}/d/{{name}}", Icon: "📈", Probe: ProbeHTTP},
+			"rest":    {URL: "https://{{host}}:{{port}}", UIHandler: UIHandlerSwagger},
+		},
+		PortForwards: map[string]Service{
+			"dashboards": {Type: "grafana"},
+			"metrics":    {Type: "grafana", HealthCheck: HealthCheckConfig{Protocol: ProbeTCP}},
+		},
+	}
+	if err := applyServiceTypes(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if protocol := cfg.PortForwards["dashboards"].HealthCheck.Protocol; protocol != ProbeHTTP {
+		t.Errorf("Expected the type's probe as default, got %q", protocol)
+	}
+	if protocol := cfg.PortForwards["metrics"].HealthCheck.Protocol; protocol != ProbeTCP {
+		t.Errorf("Expected the service's own probe to win, got %q", protocol)
+	}
+	if url := LookupServiceType("grafana").ServiceURL("dashboards", "localhost", 3000); url != "http://localhost:3000/d/dashboards" {
+		t.Errorf("Expected the expanded URL template, got %s", url)
+	}
+	if url := LookupServiceType("rest").ServiceURL("api", "localhost", 8080); url != "https://localhost:8080" {
+		t.Errorf("Expected the replaced built-in type, got %s", url)
+	}
+	if serviceType := LookupServiceType("rpc"); serviceType.UIHandler != UIHandlerGRPCUI {
+		t.Errorf("Expected the built-in rpc type to remain, got %+v", serviceType)
+	}
+	if url := LookupServiceType("unknown").ServiceURL("db", "localhost", 5432); url != "" {
+		t.Errorf("Expected no URL for an unknown type, got %s", url)
+	}
+
+	for _, tt := range []struct {
+		serviceType ServiceType
+		wantErr     string
+	}{
+		{ServiceType{Probe: ProbeExec}, "unknown probe"},
+		{ServiceType{UIHandler: "redoc"}, "unknown uiHandler"},
+	} {
+		err := applyServiceTypes(&Config{ServiceTypes: map[string]ServiceType{"custom": tt.serviceType}})
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Orders of restartStagger.order
+const (
+	RestartOrderName          = "name"           // Alphabetical
+	RestartOrderCriticalFirst = "critical-first" // Services with critical: true first, then by name
+	RestartOrderDependencies  = "dependencies"   // After the services in dependsOn, critical ones first
+)
+
+// defaultRestartStaggerInterval is the pause between restarts after a context change
+const defaultRestartStaggerInterval = 100 * time.Millisecond
+
+// RestartStaggerConfig spaces out restarting every service after a context change, so
+// VPN gateways and API servers are not hit by all port-forwards at once. Zero values
+// fall back to one service every 100ms, by name.
+type RestartStaggerConfig struct {
+	Interval  time.Duration `yaml:"interval,omitempty"`  // Pause between batches
+	BatchSize int           `yaml:"batchSize,omitempty"` // Services started together
+	Order     string        `yaml:"order,omitempty"`     // name (default), critical-first or dependencies
+}
+
+// Settings returns the interval and batch size, or their defaults
+func (s RestartStaggerConfig) Settings() (time.Duration, int) {
+	interval, batchSize := s.Interval, s.BatchSize
+	if interval == 0 {
+		interval = defaultRestartStaggerInterval
+	}
+	if batchSize == 0 {
+		batchSize = 1
+	}
+	return interval, batchSize
+}
+
+// RestartOrder returns the names of services in the order they are restarted. With the
+// dependencies order, services in a dependsOn cycle come last, by name; dependencies on
+// services that are not in services are ignored.
+func (s RestartStaggerConfig) RestartOrder(services map[string]Service) []string {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	critical := func(i, j int) bool {
+		if services[names[i]].Critical != services[names[j]].Critical {
+			return services[names[i]].Critical
+		}
+		return names[i] < names[j]
+	}
+
+	switch s.Order {
+	case RestartOrderCriticalFirst:
+		sort.Slice(names, critical)
+		return names
+	case RestartOrderDependencies:
+		sort.Slice(names, critical)
+		ordered, _ := dependencyOrder(names, services)
+		return ordered
+	default:
+		sort.Strings(names)
+		return names
+	}
+}
+
+// dependencyOrder orders names so every service comes after the services it depends on,
+// keeping the order of names otherwise. It also returns the services in cycles, which
+// are appended at the end.
+func dependencyOrder(names []string, services map[string]Service) ([]string, []string) {
+	started := make(map[string]bool, len(names))
+	ordered := make([]string, 0, len(names))
+	for len(ordered) < len(names) {
+		progressed := false
+		for _, name := range names {
+			if started[name] || !dependenciesStarted(services[name].DependsOn, services, started) {
+				continue
+			}
+			started[name] = true
+			ordered = append(ordered, name)
+			progressed = true
+			break // Restart from the front so critical services keep their priority
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	var cyclic []string
+	for _, name := range names {
+		if !started[name] {
+			cyclic = append(cyclic, name)
+		}
+	}
+	return append(ordered, cyclic...), cyclic
+}
+
+// dependenciesStarted reports whether every configured service in dependsOn has started
+func dependenciesStarted(dependsOn []string, services map[string]Service, started map[string]bool) bool {
+	for _, dependency := range dependsOn {
+		if _, exists := services[dependency]; exists && !started[dependency] {
+			return false
+		}
+	}
+	return true
+}
+
+// validateRestartStagger rejects unknown orders, negative settings and dependsOn cycles
+func validateRestartStagger(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+
+	stagger := cfg.RestartStagger
+	switch stagger.Order {
+	case "", RestartOrderName, RestartOrderCriticalFirst, RestartOrderDependencies:
+	default:
+		return fmt.Errorf("unknown restartStagger.order %q (expected name, critical-first or dependencies)", stagger.Order)
+	}
+	if stagger.Interval < 0 || stagger.BatchSize < 0 {
+		return fmt.Errorf("negative restartStagger.interval or batchSize")
+	}
+
+	names := make([]string, 0, len(cfg.PortForwards))
+	for name := range cfg.PortForwards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if _, cyclic := dependencyOrder(names, cfg.PortForwards); len(cyclic) > 0 {
+		return fmt.Errorf("services %s are in or depend on a dependsOn cycle", strings.Join(cyclic, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRestartOrder(t *testing.T) {
+	services := map[string]Service{
+		"api":     {DependsOn: []string{"auth", "db"}},
+		"auth":    {DependsOn: []string{"db", "removed"}},
+		"billing": {Critical: true, DependsOn: []string{"api"}},
+		"db":      {},
+		"vault":   {Critical: true},
+	}
+
+	tests := []struct {
+		order    string
+		expected []string
+	}{
+		{"", []string{"api", "auth", "billing", "db", "vault"}},
+		{RestartOrderCriticalFirst, []string{"billing", "vault", "api", "auth", "db"}},
+		{RestartOrderDependencies, []string{"vault", "db", "auth", "api", "billing"}},
+	}
+
+	for _, tt := range tests {
+		if order := (RestartStaggerConfig{Order: tt.order}).RestartOrder(services); !reflect.DeepEqual(order, tt.expected) {
+			t.Errorf("Order %q: expected %v, got %v", tt.order, tt.expected, order)
+		}
+	}
+}
+
+func TestRestartStaggerSettings(t *testing.T) {
+	if interval, batchSize := (RestartStaggerConfig{}).Settings(); interval != 100*time.Millisecond || batchSize != 1 {
+		t.Errorf("Expected one service every 100ms by default, got %d every %v", batchSize, interval)
+	}
+	if interval, batchSize := (RestartStaggerConfig{Interval: time.Second, BatchSize: 5}).Settings(); interval != time.Second || batchSize != 5 {
+		t.Errorf("Expected 5 services every 1s, got %d every %v", batchSize, interval)
+	}
+}
+
+func TestValidateRestartStagger(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   *Config
+		valid bool
+	}{
+		{"defaults", &Config{}, true},
+		{"dependencies", &Config{RestartStagger: RestartStaggerConfig{Order: RestartOrderDependencies, BatchSize: 5}}, true},
+		{"unknown order", &Config{RestartStagger: RestartStaggerConfig{Order: "random"}}, false},
+		{"negative interval", &Config{RestartStagger: RestartStaggerConfig{Interval: -time.Second}}, false},
+		{"cycle", &Config{PortForwards: map[string]Service{
+			"api":  {DependsOn: []string{"auth"}},
+			"auth": {DependsOn: []string{"api"}},
+			"db":   {},
+		}}, false},
+	}
+
+	for _, tt := range tests {
+		if err := validateRestartStagger(tt.cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid=%v, got %v", tt.name, tt.valid, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// starterConfig is the commented config written by CreateStarterConfig
+const starterConfig = `# kportforward user configuration. Services added here run alongside the defaults;
+# check edits with: kportforward config validate
+templates:
+  cluster:
+    kubectl:
+%s
+portForwards: {}
+  # Add a service by replacing "{}" above with entries like these:
+  # my-api:
+  #   from: cluster          # Use the context of the template above
+  #   target: service/my-api
+  #   targetPort: 80
+  #   localPort: 8080
+  #   namespace: default
+  #   type: rest             # rest, rpc, web or other
+  #   swaggerPath: /swagger/doc.json  # Shown in Swagger UI with --swaggerui
+  # my-db:
+  #   from: cluster
+  #   target: statefulset/postgres
+  #   targetPort: 5432
+  #   localPort: 15432
+  #   namespace: data
+  #   type: other
+  #   healthCheck:
+  #     protocol: postgres   # Probe the database rather than only the port
+  #   hooks:
+  #     onRunning: "make migrate DB_PORT=$KPORTFORWARD_LOCAL_PORT"
+
+# Settings here override the defaults, for example:
+# monitoringInterval: 5s
+# uiOptions:
+#   theme: light             # dark, light or no-color
+#   alert: bell              # off, bell, flash or both when a service fails
+# hooks:
+#   onFailed: "notify-send \"$KPORTFORWARD_SERVICE failed\""
+`
+
+// IsFirstRun reports whether kportforward has not been set up on this machine: there is
+// no user or project config and the last LoadConfig could neither fetch nor use a cached
+// copy of the remote defaults, so only the built-in services are known
+func IsFirstRun() bool {
+	if explicitConfigPath() != "" || !remoteDefaultsUnavailable {
+		return false
+	}
+	if _, err := os.Stat(ProjectConfigFile); err == nil {
+		return false
+	}
+	path, err := getDefaultConfigPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return os.IsNotExist(err)
+}
+
+// CreateStarterConfig writes a commented starter config whose service template uses
+// kubeContext, or the current context if it is "", and returns its path. An existing
+// config is never overwritten.
+func CreateStarterConfig(kubeContext string) (string, error) {
+	path, err := getUserConfigPath()
+	if err != nil {
+		return "", err
+	}
+	if err := writeStarterConfig(path, kubeContext); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// writeStarterConfig writes the starter config to path unless a file exists there
+func writeStarterConfig(path, kubeContext string) error {
+	contextLine := "      # context: my-cluster  # Follows the current kubectl context when unset"
+	if kubeContext != "" {
+		contextLine = fmt.Sprintf("      context: %q", kubeContext)
+	}
+	content := fmt.Sprintf(starterConfig, contextLine)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("config file %s already exists", path)
+		}
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(strings.TrimLeft(content, "\n")); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteStarterConfig(t *testing.T) {
+	for _, kubeContext := range []string{"", "staging-eu"} {
+		path := filepath.Join(t.TempDir(), "kportforward", "config.yaml")
+		if err := writeStarterConfig(path, kubeContext); err != nil {
+			t.Fatalf("Failed to write starter config: %v", err)
+		}
+
+		userConfig, err := loadUserConfig(path)
+		if err != nil {
+			t.Fatalf("Expected a loadable starter config, got %v", err)
+		}
+		if got := userConfig.Templates["cluster"].Kubectl.Context; got != kubeContext {
+			t.Errorf("Expected template context %q, got %q", kubeContext, got)
+		}
+		if _, err := finalizeConfig(userConfig); err != nil {
+			t.Errorf("Expected a valid starter config, got %v", err)
+		}
+
+		if err := writeStarterConfig(path, kubeContext); err == nil {
+			t.Error("Expected an existing config not to be overwritten")
+		}
+	}
+}
+
+func TestIsFirstRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+	t.Setenv(ConfigEnvVar, "")
+	defer func(previous bool) { remoteDefaultsUnavailable = previous }(remoteDefaultsUnavailable)
+
+	remoteDefaultsUnavailable = false
+	if IsFirstRun() {
+		t.Error("Expected no first run while remote defaults are available")
+	}
+
+	remoteDefaultsUnavailable = true
+	if !IsFirstRun() {
+		t.Error("Expected a first run without user config and remote defaults")
+	}
+
+	path, err := getDefaultConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("portForwards: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if IsFirstRun() {
+		t.Error("Expected no first run once a user config exists")
+	}
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// templateNamePlaceholder is replaced with the service name in string fields
+// inherited from (or set alongside) a template
+const templateNamePlaceholder = "{{name}}"
+
+// UnmarshalYAML decodes a service and records which settings it sets, including those
+// set to false, 0 or "" that a template must not fill in. It uses the callback form so
+// that the decoder's KnownFields check still applies to the service's fields.
+func (s *Service) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain Service
+	if err := unmarshal((*plain)(s)); err != nil {
+		return err
+	}
+
+	var fields map[string]interface{}
+	if err := unmarshal(&fields); err != nil {
+		return err
+	}
+	s.settings = make(map[string]bool)
+	collectSettings(s.settings, "", fields)
+	return nil
+}
+
+// collectSettings adds the keys of fields, and those of nested mappings, to settings
+func collectSettings(settings map[string]bool, prefix string, fields map[string]interface{}) {
+	for key, value := range fields {
+		settings[prefix+key] = true
+		if nested, ok := value.(map[string]interface{}); ok {
+			collectSettings(settings, prefix+key+".", nested)
+		}
+	}
+}
+
+// expandTemplates resolves `from:` references in port forwards by filling every
+// unset field of the service from the named template. String fields may use the
+// {{name}} placeholder, which expands to the service's own name.
+func expandTemplates(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+
+	// Sort names so that the first error reported is deterministic
+	names := make([]string, 0, len(cfg.PortForwards))
+	for name := range cfg.PortForwards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service := cfg.PortForwards[name]
+		if service.From == "" {
+			continue
+		}
+
+		template, exists := cfg.Templates[service.From]
+		if !exists {
+			return fmt.Errorf("service %s references unknown template %q", name, service.From)
+		}
+		if template.From != "" {
+			return fmt.Errorf("template %q cannot itself use from: (nested templates are not supported)", service.From)
+		}
+
+		cfg.PortForwards[name] = applyTemplate(name, service, template)
+	}
+
+	return nil
+}
+
+// applyTemplate returns the service with the fields it does not set copied from the
+// template and the {{name}} placeholder expanded in top-level string fields. Nested
+// settings such as healthCheck are merged field by field. A field set in the config
+// file is kept even when it is false or 0; for services built in code, which carry no
+// record of their settings, only zero-valued fields are filled in.
+func applyTemplate(name string, service, template Service) Service {
+	result := service
+	inheritFields(reflect.ValueOf(&result).Elem(), reflect.ValueOf(template), service.settings, "")
+
+	dst := reflect.ValueOf(&result).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Field(i)
+		if field.Kind() == reflect.String && field.CanSet() {
+			field.SetString(strings.ReplaceAll(field.String(), templateNamePlaceholder, name))
+		}
+	}
+
+	// A service inheriting from a template must never inherit its disabled state,
+	// and network exposure has to be acknowledged by each service individually
+	result.Disabled = service.Disabled
+	result.InsecureExpose = service.InsecureExpose
+	return result
+}
+
+// inheritFields fills the fields of the struct dst that are neither in settings nor set
+// from src, recursing into nested structs. Slices, maps and pointers are copied so that
+// services never share them with the template or each other.
+func inheritFields(dst, src reflect.Value, settings map[string]bool, prefix string) {
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Field(i)
+		key, ok := yamlKey(dst.Type().Field(i))
+		if !ok || !field.CanSet() {
+			continue
+		}
+
+		switch {
+		case field.Kind() == reflect.Struct:
+			inheritFields(field, src.Field(i), settings, prefix+key+".")
+		case settings[prefix+key]:
+		case field.IsZero():
+			field.Set(deepCopy(src.Field(i)))
+		}
+	}
+}
+
+// yamlKey returns the key a struct field is read from, or false if it is not read
+func yamlKey(field reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// deepCopy returns a copy of value that shares no slices, maps or pointers with it
+func deepCopy(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(deepCopy(value.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return copied
+	case reflect.Pointer:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().Set(deepCopy(value.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		for i := 0; i < copied.NumField(); i++ {
+			if copied.Field(i).CanSet() {
+				copied.Field(i).Set(deepCopy(value.Field(i)))
+			}
+		}
+		return copied
+	}
+	return value
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExpandTemplates(t *testing.T) {
+	data := `templates:
+  standard-rest:
+    target: "service/{{name}}"
+    targetPort: 80
+    namespace: "apps"
+    type: "rest"
+    swaggerPath: "docs/swagger"
+portForwards:
+  billing:
+    from: standard-rest
+    localPort: 9001
+  ledger:
+    from: standard-rest
+    localPort: 9002
+    namespace: "finance"
+  plain:
+    target: "service/plain"
+    targetPort: 8080
+    localPort: 9003
+    namespace: "default"
+`
+	cfg := &Config{}
+	if err := yaml.Unmarshal([]byte(data), cfg); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	if err := expandTemplates(cfg); err != nil {
+		t.Fatalf("expandTemplates returned error: %v", err)
+	}
+
+	billing := cfg.PortForwards["billing"]
+	if billing.Target != "service/billing" {
+		t.Errorf("Expected placeholder expansion to service/billing, got %s", billing.Target)
+	}
+	if billing.TargetPort != 80 || billing.Type != "rest" || billing.SwaggerPath != "docs/swagger" {
+		t.Errorf("Expected template fields to be inherited, got %+v", billing)
+	}
+	if billing.LocalPort != 9001 {
+		t.Errorf("Expected own local port 9001, got %d", billing.LocalPort)
+	}
+
+	ledger := cfg.PortForwards["ledger"]
+	if ledger.Namespace != "finance" {
+		t.Errorf("Expected explicit namespace to win over template, got %s", ledger.Namespace)
+	}
+
+	if cfg.PortForwards["plain"].Target != "service/plain" {
+		t.Error("Services without from: should be left untouched")
+	}
+}
+
+func TestExpandTemplatesUnknownTemplate(t *testing.T) {
+	cfg := &Config{
+		PortForwards: map[string]Service{
+			"svc": {From: "missing", LocalPort: 9000},
+		},
+	}
+
+	err := expandTemplates(cfg)
+	if err == nil {
+		t.Fatal("Expected error for unknown template")
+	}
+	if !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected error to mention template name, got: %v", err)
+	}
+}
+
+func TestTemplatesMergedFromUserConfig(t *testing.T) {
+	defaultCfg := &Config{
+		PortForwards: map[string]Service{},
+		Templates: map[string]Service{
+			"rpc": {TargetPort: 50051, Type: "rpc", Namespace: "default"},
+		},
+	}
+	userCfg := &Config{
+		PortForwards: map[string]Service{
+			"svc": {From: "rpc", Target: "service/svc", LocalPort: 9000},
+		},
+		Templates: map[string]Service{
+			"rpc": {TargetPort: 9090, Type: "rpc", Namespace: "user"},
+		},
+	}
+
+	merged, err := finalizeConfig(mergeConfigs(defaultCfg, userCfg))
+	if err != nil {
+		t.Fatalf("finalizeConfig returned error: %v", err)
+	}
+
+	svc := merged.PortForwards["svc"]
+	if svc.TargetPort != 9090 || svc.Namespace != "user" {
+		t.Errorf("Expected user template to override default template, got %+v", svc)
+	}
+}
+
+func TestExpandTemplatesKeepsExplicitFalseAndZero(t *testing.T) {
+	data := `templates:
+  strict:
+    target: "service/{{name}}"
+    targetPort: 80
+    namespace: "apps"
+    strictPort: true
+    trackPod: true
+    critical: true
+    dependsOn: [auth]
+    links:
+      - name: Runbook
+        url: https://runbooks.example.com/{{name}}
+    healthCheck:
+      path: /healthz
+      hideDegraded: true
+portForwards:
+  billing:
+    from: strict
+    localPort: 9001
+    strictPort: false
+    trackPod: false
+    healthCheck:
+      hideDegraded: false
+  ledger:
+    from: strict
+    localPort: 9002
+`
+	cfg := &Config{}
+	if err := yaml.Unmarshal([]byte(data), cfg); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	if err := expandTemplates(cfg); err != nil {
+		t.Fatalf("expandTemplates returned error: %v", err)
+	}
+
+	billing := cfg.PortForwards["billing"]
+	if billing.StrictPort || billing.TrackPod {
+		t.Errorf("Expected strictPort and trackPod set to false to be kept, got %+v", billing)
+	}
+	if !billing.Critical {
+		t.Error("Expected critical to be inherited")
+	}
+	if billing.HealthCheck.HideDegraded || billing.HealthCheck.Path != "/healthz" {
+		t.Errorf("Expected hideDegraded to be kept and path inherited, got %+v", billing.HealthCheck)
+	}
+
+	ledger := cfg.PortForwards["ledger"]
+	if !ledger.StrictPort || !ledger.TrackPod || !ledger.HealthCheck.HideDegraded {
+		t.Errorf("Expected unset fields to be inherited, got %+v", ledger)
+	}
+
+	// Inherited slices are copies, not shared with the template or other services
+	billing.DependsOn[0] = "changed"
+	billing.Links[0].Name = "changed"
+	if ledger.DependsOn[0] != "auth" || cfg.Templates["strict"].DependsOn[0] != "auth" {
+		t.Error("Expected dependsOn not to be shared between services")
+	}
+	if ledger.Links[0].Name != "Runbook" || cfg.Templates["strict"].Links[0].Name != "Runbook" {
+		t.Error("Expected links not to be shared between services")
+	}
+}
+
+func TestServiceSettingsKeepKnownFieldsCheck(t *testing.T) {
+	decoder := yaml.NewDecoder(strings.NewReader("portForwards:\n  api:\n    target: service/api\n    strictport: true\n"))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&Config{}); err == nil || !strings.Contains(err.Error(), "strictport") {
+		t.Errorf("Expected unknown service field to be reported, got %v", err)
+	}
+}
@@ -6,9 +6,47 @@ import (
 
 // Config represents the main configuration structure
 type Config struct {
-	PortForwards       map[string]Service `yaml:"portForwards"`
-	MonitoringInterval time.Duration      `yaml:"monitoringInterval"`
-	UIOptions          UIConfig           `yaml:"uiOptions"`
+	PortForwards        map[string]Service        `yaml:"portForwards"`
+	Templates           map[string]Service        `yaml:"templates,omitempty"`
+	MonitoringInterval  time.Duration             `yaml:"monitoringInterval"`
+	MonitoringIntervals MonitoringIntervalsConfig `yaml:"monitoringIntervals,omitempty"`
+	UIOptions           UIConfig                  `yaml:"uiOptions"`
+	IdleTimeout         time.Duration             `yaml:"idleTimeout,omitempty"` // Stop all forwards after this long without traffic (0 = disabled)
+	RestartStorm        RestartStormConfig        `yaml:"restartStorm,omitempty"`
+	RestartStagger      RestartStaggerConfig      `yaml:"restartStagger,omitempty"`
+	PortOffsets         map[string]int            `yaml:"portOffsets,omitempty"`        // Added to every local port while the kubectl context is active
+	Groups              map[string][]string       `yaml:"groups,omitempty"`             // Named sets of services, see Select
+	Hooks               HooksConfig               `yaml:"hooks,omitempty"`              // Run for every service without its own hook
+	ServiceTypes        map[string]ServiceType    `yaml:"serviceTypes,omitempty"`       // Added to or replacing the built-in types by name
+	Updates             UpdatesConfig             `yaml:"updates,omitempty"`            // Update check settings
+	PortForwardBackend  string                    `yaml:"portForwardBackend,omitempty"` // kubectl (default) or native, see BackendNative
+	Disabled            []string                  `yaml:"-"`                            // Services the user disabled, sorted; not in PortForwards
+	Skipped             map[string]string         `yaml:"-"`                            // Services that could not be loaded and why; not in PortForwards
+}
+
+// MonitoringIntervalsConfig adapts how often services are checked to their status, so
+// connecting services are shown as up quickly and stable ones cause little traffic.
+// Zero values fall back to monitoringInterval.
+type MonitoringIntervalsConfig struct {
+	Connecting time.Duration `yaml:"connecting,omitempty"` // While any service is starting, connecting or reconnecting
+	Running    time.Duration `yaml:"running,omitempty"`    // Between checks of Running services whose last check passed
+}
+
+// RestartStormConfig controls detection of many services failing at once.
+// Zero values fall back to the built-in defaults.
+type RestartStormConfig struct {
+	Threshold int           `yaml:"threshold,omitempty"` // Storm when more than this many services fail within Window
+	Window    time.Duration `yaml:"window,omitempty"`
+}
+
+// HooksConfig holds shell commands run when a service's health changes. The service
+// is passed in KPORTFORWARD_* environment variables. A hook starting with http:// or
+// https:// is instead called with a POST of the same values as JSON.
+type HooksConfig struct {
+	OnFailed    string `yaml:"onFailed,omitempty"`    // The service was marked as failed
+	OnRecovered string `yaml:"onRecovered,omitempty"` // The service is running again after failing or being suspended
+	OnSuspended string `yaml:"onSuspended,omitempty"` // The service was suspended as the cluster cannot be reached
+	OnRunning   string `yaml:"onRunning,omitempty"`   // The service became Running, including when it first starts
 }
 
 // Service represents a single port-forward service configuration
@@ -21,19 +59,140 @@ type Service struct {
 	SwaggerPath string `yaml:"swaggerPath,omitempty"`
 	APIPath     string `yaml:"apiPath,omitempty"`
 	Disabled    bool   `yaml:"disabled,omitempty"`
+	From        string `yaml:"from,omitempty"` // Name of a template to inherit unset fields from
+	Profile     string `yaml:"-"`              // Profile the service was loaded from, see LoadProfiles
+
+	// LabelSelector forwards to every ready pod matching it instead of to Target, and
+	// NamespaceSelector (a label selector on namespaces) or a * wildcard in Namespace
+	// repeats the entry in every matching namespace. Each match runs as a service of its
+	// own, see ExpandedName, refreshed every monitoring interval.
+	LabelSelector     string `yaml:"labelSelector,omitempty"`
+	NamespaceSelector string `yaml:"namespaceSelector,omitempty"`
+
+	// Ports are further local:target mappings forwarded by the same kubectl process, such
+	// as a metrics port. Without localPort and targetPort the first one takes their place.
+	Ports []PortMapping `yaml:"ports,omitempty"`
+
+	// StrictPort keeps the service on LocalPort: while another process holds it, the
+	// service waits in PortConflict instead of moving to the next free port
+	StrictPort bool `yaml:"strictPort,omitempty"`
+
+	// UIEnv is passed to the service's Swagger UI container or grpcui process, e.g.
+	// OAUTH_CLIENT_ID for Swagger UI's Authorize dialog. $VAR and ${VAR} in values are
+	// expanded from kportforward's environment, so secrets need not be in the config.
+	UIEnv map[string]string `yaml:"uiEnv,omitempty"`
+
+	// Description and Owner are free-form notes shown in the detail view
+	Description string `yaml:"description,omitempty"`
+	Owner       string `yaml:"owner,omitempty"`
+
+	// Links to dashboards, runbooks or repositories, opened from the detail view with 1-9
+	Links []ServiceLink `yaml:"links,omitempty"`
+
+	// BindAddress is an extra address to listen on besides localhost. Non-loopback
+	// addresses expose the service to the network and require InsecureExpose.
+	BindAddress    string `yaml:"bindAddress,omitempty"`
+	InsecureExpose bool   `yaml:"insecureExpose,omitempty"`
+
+	HealthCheck HealthCheckConfig `yaml:"healthCheck,omitempty"`
+	Kubectl     KubectlConfig     `yaml:"kubectl,omitempty"`
+
+	// WaitForReady keeps the service Connecting until the target pod reports Ready
+	WaitForReady bool `yaml:"waitForReady,omitempty"`
+
+	// TrackPod forwards to one ready pod of a service or workload target and moves to
+	// another one as soon as it is deleted, terminating or no longer ready
+	TrackPod bool `yaml:"trackPod,omitempty"`
+
+	// RestartOnEndpointChange restarts a service/ target once a pod leaves its ready
+	// endpoints, e.g. during a rollout, instead of waiting for kubectl's tunnel to break
+	RestartOnEndpointChange bool `yaml:"restartOnEndpointChange,omitempty"`
+
+	// Critical services and the services they depend on are restarted first after a
+	// context change, see RestartStaggerConfig. A service is only started, and its UI
+	// with it, once the services in DependsOn are Running.
+	Critical  bool     `yaml:"critical,omitempty"`
+	DependsOn []string `yaml:"dependsOn,omitempty"`
+
+	// Hooks replace the global hooks of the same event for this service
+	Hooks HooksConfig `yaml:"hooks,omitempty"`
+
+	// Schedule limits the service to recurring availability windows (empty = always on)
+	Schedule []ScheduleWindow `yaml:"schedule,omitempty"`
+
+	// settings holds the dotted keys set in the config file (e.g. healthCheck.path), so
+	// that a template only fills in fields the service leaves out, see applyTemplate
+	settings map[string]bool
+}
+
+// PortMapping is one local port forwarded to a port of the target
+type PortMapping struct {
+	Local  int `yaml:"local"`
+	Target int `yaml:"target"`
 }
 
 // UIConfig represents UI-specific configuration options
 type UIConfig struct {
-	RefreshRate time.Duration `yaml:"refreshRate"`
-	Theme       string        `yaml:"theme"`
+	RefreshRate     time.Duration `yaml:"refreshRate"`
+	Theme           string        `yaml:"theme"`                     // dark (default), light or no-color
+	UptimeFormat    string        `yaml:"uptimeFormat,omitempty"`    // compact (default), hours, full or iso
+	TimestampFormat string        `yaml:"timestampFormat,omitempty"` // 24h (default), 12h or iso
+	Alert           string        `yaml:"alert,omitempty"`           // off (default), bell, flash or both
+	IssueThreshold  int           `yaml:"issueThreshold,omitempty"`  // Offer an issue report after this many restarts of a service (default 5, -1 never)
+}
+
+// Themes, for uiOptions.theme
+const (
+	ThemeDark    = "dark"
+	ThemeLight   = "light"
+	ThemeNoColor = "no-color"
+)
+
+// IsValidTheme reports whether theme is a known uiOptions.theme; "" is the default
+func IsValidTheme(theme string) bool {
+	switch theme {
+	case "", ThemeDark, ThemeLight, ThemeNoColor:
+		return true
+	}
+	return false
+}
+
+// UpdatesConfig controls the update checks
+type UpdatesConfig struct {
+	Channel       string        `yaml:"channel,omitempty"`       // stable (default) or beta, which includes pre-releases
+	CheckInterval time.Duration `yaml:"checkInterval,omitempty"` // Between checks (default 24h)
+	Disabled      bool          `yaml:"disabled,omitempty"`      // No checks, as with --no-update-check
+}
+
+// Update channels, for updates.channel
+const (
+	UpdateChannelStable = "stable"
+	UpdateChannelBeta   = "beta"
+)
+
+// IsValidUpdateChannel reports whether channel is a known updates.channel; "" is the default
+func IsValidUpdateChannel(channel string) bool {
+	switch channel {
+	case "", UpdateChannelStable, UpdateChannelBeta:
+		return true
+	}
+	return false
 }
 
+// Alerts on critical transitions, for uiOptions.alert
+const (
+	AlertOff   = "off"
+	AlertBell  = "bell"
+	AlertFlash = "flash"
+	AlertBoth  = "both"
+)
+
 // ServiceStatus represents the runtime status of a service
 type ServiceStatus struct {
 	Name          string
-	Status        string // Possible values: "Starting", "Connecting", "Running", "Degraded", "Failed", "Suspended", "Reconnecting", "Stopped"
+	Status        string // Possible values: "Starting", "Connecting", "Running", "Degraded", "Failed", "Suspended", "Reconnecting", "Stopped", "Idle", "Scheduled", "PortConflict", "Waiting"
 	LocalPort     int    // Actual port being used (may differ from config if reassigned)
+	ExtraPorts    []int  `json:"extraPorts,omitempty"` // Local ports of the service's further ports, offset like LocalPort
 	PID           int    // Process ID of kubectl port-forward
 	StartTime     time.Time
 	RestartCount  int
@@ -41,5 +200,48 @@ type ServiceStatus struct {
 	StatusMessage string // Transient status message (e.g., "Starting gRPC UI...")
 	InCooldown    bool
 	CooldownUntil time.Time
-	GlobalStatus  string `json:"globalStatus,omitempty"` // Global access status: "healthy", "auth_failure", "network_failure"
+	Pod           string   `json:"pod,omitempty"`           // Pod forwarded to, for services with trackPod
+	KubectlOutput []string `json:"kubectlOutput,omitempty"` // Last lines kubectl wrote to stderr, oldest first
+	// Results of healthCheck.protocol probes other than tcp
+	ProbeLatency   time.Duration   `json:"probeLatency,omitempty"`   // Duration of the last successful probe
+	ProbeError     string          `json:"probeError,omitempty"`     // Why the last probe failed, "" if it succeeded
+	ProbeLatencies []time.Duration `json:"probeLatencies,omitempty"` // Recent probe durations, oldest first; 0 for failed probes
+	GlobalStatus   string          `json:"globalStatus,omitempty"`   // Global access status: "healthy", "degraded", "auth_failure", "network_failure"
+	LastHealthy    time.Time       `json:"lastHealthy"`              // Time of the last passing health check, kept across restarts; zero before the first
+	PortHolder     string          `json:"portHolder,omitempty"`     // Process holding the configured local port when it was taken at the last start
+	Downtime       DowntimeStats   `json:"downtime"`                 // Outages this session, or across sessions with --downtime-file
+}
+
+// StatusChange is an entry of a service's timeline: the status it entered and why
+type StatusChange struct {
+	Time   time.Time `json:"time"`
+	Status string    `json:"status"`
+	Error  string    `json:"error,omitempty"` // LastError when the status was entered
+}
+
+// DowntimeStats accumulates the outages of a service: periods in which it was Failed,
+// Suspended, Reconnecting, in Cooldown or PortConflict. An outage ends when the service
+// is Running or Degraded again, or is stopped on purpose.
+type DowntimeStats struct {
+	Outages    int           `json:"outages"`             // Outages begun, including an ongoing one
+	Downtime   time.Duration `json:"downtime"`            // Total length of the ended outages
+	Repaired   int           `json:"repaired"`            // Ended outages after which the service ran again
+	RepairTime time.Duration `json:"repairTime"`          // Total length of the repaired outages
+	DownSince  time.Time     `json:"downSince,omitempty"` // Start of the ongoing outage; zero when up
+}
+
+// TotalDowntime returns the downtime at now, including the ongoing outage
+func (d DowntimeStats) TotalDowntime(now time.Time) time.Duration {
+	if d.DownSince.IsZero() {
+		return d.Downtime
+	}
+	return d.Downtime + now.Sub(d.DownSince)
+}
+
+// MTTR returns the mean time to repair, or 0 before the first repaired outage
+func (d DowntimeStats) MTTR() time.Duration {
+	if d.Repaired == 0 {
+		return 0
+	}
+	return d.RepairTime / time.Duration(d.Repaired)
 }
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+// envNamePattern matches the environment variable names uiEnv accepts
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// UIEnvironment returns the service's uiEnv as sorted KEY=value pairs, with variables
+// in the values expanded
+func (s Service) UIEnvironment() []string {
+	names := make([]string, 0, len(s.UIEnv))
+	for name := range s.UIEnv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		env = append(env, name+"="+os.ExpandEnv(s.UIEnv[name]))
+	}
+	return env
+}
+
+// validateUIEnv rejects uiEnv names that are not valid environment variable names
+func validateUIEnv(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+	for serviceName, service := range cfg.PortForwards {
+		for name := range service.UIEnv {
+			if !envNamePattern.MatchString(name) {
+				return fmt.Errorf("service %s: invalid uiEnv name %q", serviceName, name)
+			}
+		}
+	}
+	return nil
+}
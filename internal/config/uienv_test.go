@@ -0,0 +1,41 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUIEnvironment(t *testing.T) {
+	t.Setenv("KPF_TEST_CLIENT_ID", "swagger-client")
+	service := Service{UIEnv: map[string]string{
+		"OAUTH_CLIENT_ID":       "${KPF_TEST_CLIENT_ID}",
+		"OAUTH2_REDIRECT_URL":   "http://localhost:9100/oauth2-redirect.html",
+		"PERSIST_AUTHORIZATION": "true",
+	}}
+
+	expected := []string{
+		"OAUTH2_REDIRECT_URL=http://localhost:9100/oauth2-redirect.html",
+		"OAUTH_CLIENT_ID=swagger-client",
+		"PERSIST_AUTHORIZATION=true",
+	}
+	if env := service.UIEnvironment(); !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %v, got %v", expected, env)
+	}
+	if env := (Service{}).UIEnvironment(); len(env) != 0 {
+		t.Errorf("Expected no environment without uiEnv, got %v", env)
+	}
+}
+
+func TestValidateUIEnv(t *testing.T) {
+	valid := &Config{PortForwards: map[string]Service{"api": {UIEnv: map[string]string{"BASE_URL": "/docs"}}}}
+	if err := validateUIEnv(valid); err != nil {
+		t.Errorf("Expected valid uiEnv, got %v", err)
+	}
+
+	for _, name := range []string{"", "OAUTH-CLIENT", "1ID", "A=B"} {
+		cfg := &Config{PortForwards: map[string]Service{"api": {UIEnv: map[string]string{name: "x"}}}}
+		if err := validateUIEnv(cfg); err == nil {
+			t.Errorf("Expected an error for uiEnv name %q", name)
+		}
+	}
+}
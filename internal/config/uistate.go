@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UIState is what the TUI remembers between runs. Unlike the config, it is written by
+// kportforward itself and not meant to be edited.
+type UIState struct {
+	Pinned        []string `yaml:"pinned,omitempty"`        // Services always shown at the top of the table
+	HiddenColumns []string `yaml:"hiddenColumns,omitempty"` // Table columns hidden with v
+	ShownColumns  []string `yaml:"shownColumns,omitempty"`  // Optional table columns shown with v
+}
+
+// UIStatePath returns the path of the TUI state file, next to the default config file
+func UIStatePath() (string, error) {
+	path, err := getDefaultConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "ui-state.yaml"), nil
+}
+
+// LoadUIState reads the TUI state. A missing file is an empty state.
+func LoadUIState() (UIState, error) {
+	path, err := UIStatePath()
+	if err != nil {
+		return UIState{}, err
+	}
+	return loadUIStateFile(path)
+}
+
+// SaveUIState writes the TUI state
+func SaveUIState(state UIState) error {
+	path, err := UIStatePath()
+	if err != nil {
+		return err
+	}
+	return saveUIStateFile(path, state)
+}
+
+// loadUIStateFile reads the TUI state from path
+func loadUIStateFile(path string) (UIState, error) {
+	var state UIState
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read UI state: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse UI state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// saveUIStateFile writes the TUI state to path, creating its directory if needed
+func saveUIStateFile(path string, state UIState) error {
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode UI state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write UI state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUIStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kportforward", "ui-state.yaml")
+
+	state, err := loadUIStateFile(path)
+	if err != nil || len(state.Pinned) != 0 {
+		t.Fatalf("Expected empty state for a missing file, got %+v, %v", state, err)
+	}
+
+	want := UIState{Pinned: []string{"api", "staging/db"}}
+	if err := saveUIStateFile(path, want); err != nil {
+		t.Fatalf("Failed to save UI state: %v", err)
+	}
+	got, err := loadUIStateFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load UI state: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
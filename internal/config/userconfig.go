@@ -0,0 +1,195 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UserConfigPath returns the path of the user's config file
+func UserConfigPath() (string, error) {
+	return getUserConfigPath()
+}
+
+// AddUserServices adds services to the user's config file and returns its path.
+// The file is edited as a YAML document so existing comments and ordering survive.
+// Nothing is written if any of the names is already present.
+func AddUserServices(services map[string]Service) (string, error) {
+	path, err := getUserConfigPath()
+	if err != nil {
+		return "", err
+	}
+	if err := addServicesToFile(path, services); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// addServicesToFile adds services under portForwards in the YAML file at path
+func addServicesToFile(path string, services map[string]Service) error {
+	document, portForwards, err := readPortForwards(path)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		if mappingValue(portForwards, name) != nil {
+			return fmt.Errorf("service %s already exists in %s", name, path)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var value yaml.Node
+		if err := value.Encode(services[name]); err != nil {
+			return fmt.Errorf("failed to encode service %s: %w", name, err)
+		}
+		portForwards.Content = append(portForwards.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: name}, &value)
+	}
+
+	return writeDocument(path, document)
+}
+
+// SetServiceDisabled sets or clears the disabled flag of a service and returns the path
+// of the file that was changed: the config file, or for "<profile>/<service>" the
+// profile's file. Disabling a service that only the defaults define adds an entry with
+// just the flag, and enabling it again removes that entry.
+func SetServiceDisabled(name string, disabled bool) (string, error) {
+	path, err := getUserConfigPath()
+	if err != nil {
+		return "", err
+	}
+	if profile, service, ok := strings.Cut(name, "/"); ok {
+		dir, err := ProfilesDir()
+		if err != nil {
+			return "", err
+		}
+		path, name = filepath.Join(dir, profile+".yaml"), service
+	}
+
+	if err := setDisabledInFile(path, name, disabled); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// setDisabledInFile sets or clears the disabled flag of a service in the YAML file at path
+func setDisabledInFile(path, name string, disabled bool) error {
+	document, portForwards, err := readPortForwards(path)
+	if err != nil {
+		return err
+	}
+
+	service := mappingValue(portForwards, name)
+	switch {
+	case service == nil && disabled:
+		service = &yaml.Node{Kind: yaml.MappingNode}
+		portForwards.Content = append(portForwards.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: name}, service)
+	case service == nil:
+		return fmt.Errorf("service %s is not disabled in %s", name, path)
+	case service.Kind != yaml.MappingNode:
+		return fmt.Errorf("service %s in %s is not a YAML mapping", name, path)
+	}
+
+	if disabled {
+		if value := mappingValue(service, "disabled"); value != nil {
+			value.Value, value.Tag = "true", "!!bool"
+		} else {
+			service.Content = append(service.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: "disabled"},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+		}
+		return writeDocument(path, document)
+	}
+
+	removeMappingKey(service, "disabled")
+	if len(service.Content) == 0 {
+		// The entry only disabled a default service
+		removeMappingKey(portForwards, name)
+	}
+	return writeDocument(path, document)
+}
+
+// readPortForwards parses the YAML file at path, which may not exist yet, and returns it
+// with its portForwards mapping, which is added if missing
+func readPortForwards(path string) (*yaml.Node, *yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	document := &yaml.Node{}
+	if err := yaml.Unmarshal(data, document); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if document.Kind == 0 {
+		document = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("config file %s is not a YAML mapping", path)
+	}
+
+	portForwards := mappingValue(root, "portForwards")
+	if portForwards == nil || portForwards.Kind != yaml.MappingNode {
+		// An empty "portForwards:" is a null scalar; replace it in place
+		if portForwards != nil {
+			*portForwards = yaml.Node{Kind: yaml.MappingNode}
+		} else {
+			portForwards = &yaml.Node{Kind: yaml.MappingNode}
+			root.Content = append(root.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: "portForwards"}, portForwards)
+		}
+	}
+	return document, portForwards, nil
+}
+
+// writeDocument writes a YAML document to path, creating its directory
+func writeDocument(path string, document *yaml.Node) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// removeMappingKey removes key and its value from a YAML mapping
+func removeMappingKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// mappingValue returns the value node for key in a YAML mapping, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
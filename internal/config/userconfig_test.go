@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetDisabledInFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	existing := "# my services\nportForwards:\n  api:\n    target: service/api\n    targetPort: 80\n    localPort: 8080\n    namespace: default\n    type: rest\n"
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+	load := func() map[string]Service {
+		t.Helper()
+		cfg, err := loadUserConfig(path)
+		if err != nil {
+			t.Fatalf("Failed to reload config: %v", err)
+		}
+		return cfg.PortForwards
+	}
+
+	// A service of the user's own is flagged in place
+	if err := setDisabledInFile(path, "api", true); err != nil {
+		t.Fatalf("Failed to disable api: %v", err)
+	}
+	if api := load()["api"]; !api.Disabled || api.LocalPort != 8080 {
+		t.Errorf("Expected api to be disabled and otherwise unchanged, got %+v", api)
+	}
+	if err := setDisabledInFile(path, "api", false); err != nil {
+		t.Fatalf("Failed to enable api: %v", err)
+	}
+	if api := load()["api"]; api.Disabled || api.LocalPort != 8080 {
+		t.Errorf("Expected api to be enabled and kept, got %+v", api)
+	}
+
+	// A default service gets an entry with just the flag, which enabling removes
+	if err := setDisabledInFile(path, "flyte-console", true); err != nil {
+		t.Fatalf("Failed to disable default service: %v", err)
+	}
+	if console, ok := load()["flyte-console"]; !ok || !reflect.DeepEqual(console, Service{Disabled: true, settings: map[string]bool{"disabled": true}}) {
+		t.Errorf("Expected an entry with only disabled set, got %+v", console)
+	}
+	if err := setDisabledInFile(path, "flyte-console", false); err != nil {
+		t.Fatalf("Failed to enable default service: %v", err)
+	}
+	if _, ok := load()["flyte-console"]; ok {
+		t.Error("Expected the entry to be removed when enabling the service again")
+	}
+
+	if err := setDisabledInFile(path, "unknown", false); err == nil {
+		t.Error("Expected an error when enabling a service that is not disabled")
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "# my services") {
+		t.Error("Expected existing comments to be preserved")
+	}
+}
+
+func TestSetDisabledInEmptyPortForwards(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("portForwards:\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := setDisabledInFile(path, "api", true); err != nil {
+		t.Fatalf("Failed to disable api: %v", err)
+	}
+	cfg, err := loadUserConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if !cfg.PortForwards["api"].Disabled {
+		t.Errorf("Expected api to be disabled, got %+v", cfg.PortForwards)
+	}
+}
+
+func TestMergeConfigsRecordsDisabled(t *testing.T) {
+	defaults := &Config{PortForwards: map[string]Service{
+		"api":    {Target: "service/api"},
+		"legacy": {Target: "service/legacy", Disabled: true},
+	}}
+	user := &Config{PortForwards: map[string]Service{"api": {Disabled: true}}}
+
+	merged := mergeConfigs(defaults, user)
+	if len(merged.PortForwards) != 0 {
+		t.Errorf("Expected disabled services to be removed, got %v", merged.PortForwards)
+	}
+	// Services disabled by the defaults cannot be enabled from the user config
+	if !reflect.DeepEqual(merged.Disabled, []string{"api"}) {
+		t.Errorf("Expected only the user's disabled service to be recorded, got %v", merged.Disabled)
+	}
+}
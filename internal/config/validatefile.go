@@ -0,0 +1,220 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigIssue is a problem ValidateConfigFile found in a config file
+type ConfigIssue struct {
+	Line    int    // Line it was found on, 0 if it concerns the file as a whole
+	Message string // What is wrong
+	Warning bool   // The config loads, but most likely does not do what was intended
+}
+
+// yamlErrorLine matches the line number yaml.v3 puts in its errors
+var yamlErrorLine = regexp.MustCompile(`line (\d+): (.*)`)
+
+// ValidateConfigFile checks data, a user or project config file (layer LayerUser or
+// LayerProject), without loading it: it must be valid YAML with only known settings,
+// merge with the built-in defaults into a valid config, and its services need types that
+// exist, local ports no other service uses and, for types with Swagger UI, a swaggerPath.
+// Errors from loading are reported on the line of the service or template they name.
+// Issues are sorted by line.
+func ValidateConfigFile(data []byte, layer string) []ConfigIssue {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return yamlIssues(err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	decoded := &Config{}
+	if err := decoder.Decode(decoded); err != nil && err != io.EOF {
+		return yamlIssues(err)
+	}
+
+	var services, templates *yaml.Node
+	if len(document.Content) > 0 {
+		services = mappingValue(document.Content[0], "portForwards")
+		templates = mappingValue(document.Content[0], "templates")
+	}
+	if layer == LayerProject {
+		if err := validateProjectConfig(decoded); err != nil {
+			return []ConfigIssue{{Line: entryLine(err.Error(), services, templates), Message: err.Error()}}
+		}
+	}
+
+	merged, err := mergeConfigLayers([]ConfigLayer{
+		{Name: LayerDefaults, Data: DefaultConfigYAML},
+		{Name: layer, Data: data},
+	})
+	if err != nil {
+		return []ConfigIssue{{Line: entryLine(err.Error(), services, templates), Message: err.Error()}}
+	}
+
+	var issues []ConfigIssue
+	if services == nil || services.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	ports := localPortOwners(merged)
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		key, entry := services.Content[i], services.Content[i+1]
+		name := key.Value
+		if reason, skipped := merged.Skipped[name]; skipped {
+			issues = append(issues, ConfigIssue{Line: key.Line, Message: fmt.Sprintf("%s is left out: %s", name, reason), Warning: true})
+			continue
+		}
+		service, enabled := merged.PortForwards[name]
+		if !enabled {
+			continue
+		}
+		// Settings inherited from a template are reported on the service's line
+		line := func(field string) int {
+			if value := mappingValue(entry, field); value != nil {
+				return value.Line
+			}
+			return key.Line
+		}
+
+		serviceType, known := builtinServiceTypes[service.Type]
+		if custom, ok := merged.ServiceTypes[service.Type]; ok {
+			serviceType, known = custom, true
+		}
+		switch {
+		case service.Type == "":
+			issues = append(issues, ConfigIssue{Line: key.Line, Message: fmt.Sprintf("%s has no type", name), Warning: true})
+		case !known:
+			issues = append(issues, ConfigIssue{Line: line("type"), Message: fmt.Sprintf("%s has unknown type %q (expected %s)", name, service.Type, knownServiceTypes(merged))})
+		case serviceType.UIHandler == UIHandlerSwagger && service.SwaggerPath == "":
+			issues = append(issues, ConfigIssue{Line: key.Line, Message: fmt.Sprintf("%s has type %s but no swaggerPath, so Swagger UI cannot start for it", name, service.Type), Warning: true})
+		}
+
+		for _, port := range serviceLocalPorts(service) {
+			var others []string
+			for _, owner := range ports[port] {
+				if owner != name {
+					others = append(others, owner)
+				}
+			}
+			if len(others) > 0 {
+				issues = append(issues, ConfigIssue{Line: line("localPort"), Message: fmt.Sprintf("%s uses local port %d, as does %s", name, port, strings.Join(others, ", "))})
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues
+}
+
+// yamlIssues turns a yaml.v3 error into issues, one per line it lists
+func yamlIssues(err error) []ConfigIssue {
+	messages := []string{err.Error()}
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	}
+
+	issues := make([]ConfigIssue, 0, len(messages))
+	for _, message := range messages {
+		issue := ConfigIssue{Message: strings.TrimPrefix(message, "yaml: ")}
+		if match := yamlErrorLine.FindStringSubmatch(message); match != nil {
+			issue.Line, _ = strconv.Atoi(match[1])
+			issue.Message = match[2]
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// entryLine returns the line of the service, or else the template, that message names
+// first, or 0 if it names none of the entries in the file. Of names starting at the same
+// place, such as api and api-v2, the longest wins.
+func entryLine(message string, entries ...*yaml.Node) int {
+	for _, mapping := range entries {
+		if mapping == nil || mapping.Kind != yaml.MappingNode {
+			continue
+		}
+		line, first, longest := 0, len(message), 0
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			key := mapping.Content[i]
+			at := namePosition(message, key.Value)
+			if at >= 0 && (at < first || at == first && len(key.Value) > longest) {
+				line, first, longest = key.Line, at, len(key.Value)
+			}
+		}
+		if line > 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+// namePosition returns where name first appears in message as a whole word, or -1.
+// Service names may contain - and /, so only letters, digits and _ continue a word.
+func namePosition(message, name string) int {
+	if name == "" {
+		return -1
+	}
+	isWordByte := func(b byte) bool {
+		return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+	}
+	for offset := 0; ; {
+		at := strings.Index(message[offset:], name)
+		if at < 0 {
+			return -1
+		}
+		start, end := offset+at, offset+at+len(name)
+		if (start == 0 || !isWordByte(message[start-1])) && (end == len(message) || !isWordByte(message[end])) {
+			return start
+		}
+		offset = start + 1
+	}
+}
+
+// localPortOwners returns the services using each local port, sorted by name
+func localPortOwners(cfg *Config) map[int][]string {
+	owners := make(map[int][]string)
+	for name, service := range cfg.PortForwards {
+		for _, port := range serviceLocalPorts(service) {
+			if port == 0 {
+				continue
+			}
+			owners[port] = append(owners[port], name)
+		}
+	}
+	for _, names := range owners {
+		sort.Strings(names)
+	}
+	return owners
+}
+
+// serviceLocalPorts returns the local ports a service forwards
+func serviceLocalPorts(service Service) []int {
+	ports := []int{service.LocalPort}
+	for _, mapping := range service.Ports {
+		ports = append(ports, mapping.Local)
+	}
+	return ports
+}
+
+// knownServiceTypes lists the built-in and configured service types
+func knownServiceTypes(cfg *Config) string {
+	names := make([]string, 0, len(builtinServiceTypes)+len(cfg.ServiceTypes))
+	for name := range builtinServiceTypes {
+		names = append(names, name)
+	}
+	for name := range cfg.ServiceTypes {
+		if _, builtin := builtinServiceTypes[name]; !builtin {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
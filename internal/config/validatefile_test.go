@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		layer string
+		want  []string // "line: message substring", "W" prefix for warnings
+	}{
+		{
+			name: "valid",
+			data: "portForwards:\n  my-api:\n    target: service/my-api\n    targetPort: 80\n    localPort: 18080\n    namespace: default\n    type: rest\n    swaggerPath: /swagger.json\n",
+		},
+		{
+			name: "syntax error",
+			data: "portForwards:\n  api:\n    target: [service/api\n",
+			want: []string{"2: did not find expected"},
+		},
+		{
+			name: "unknown fields",
+			data: "monitoringIntervall: 5s\nportForwards:\n  api:\n    targetport: 80\n",
+			want: []string{"1: field monitoringIntervall not found", "4: field targetport not found"},
+		},
+		{
+			name: "invalid merged config",
+			data: "uiOptions:\n  theme: pink\n",
+			want: []string{`0: unknown uiOptions.theme "pink"`},
+		},
+		{
+			name: "service checks",
+			data: `serviceTypes:
+  db: {}
+templates:
+  base:
+    namespace: default
+    targetPort: 80
+    type: rest
+portForwards:
+  api:
+    from: base
+    target: service/api
+    localPort: 18080
+  web:
+    target: service/web
+    targetPort: 80
+    localPort: 18080
+    namespace: default
+    type: wbe
+  pg:
+    target: service/pg
+    targetPort: 5432
+    localPort: 15432
+    namespace: default
+    type: db
+`,
+			want: []string{
+				"W9: api has type rest but no swaggerPath",
+				"12: api uses local port 18080, as does web",
+				"16: web uses local port 18080, as does api",
+				`18: web has unknown type "wbe" (expected db, other, rest, rpc, web)`,
+			},
+		},
+		{
+			name: "unset environment variable",
+			data: "portForwards:\n  api:\n    target: service/api\n    targetPort: 80\n    localPort: 18080\n    namespace: ${KPF_TEST_UNSET}-dev\n    type: web\n",
+			want: []string{"W2: api is left out: namespace: environment variable KPF_TEST_UNSET is not set"},
+		},
+		{
+			name: "invalid service",
+			data: "portForwards:\n  api:\n    target: service/api\n    targetPort: 80\n    localPort: 18080\n    namespace: default\n    type: web\n  api-v2:\n    target: service/api\n    targetPort: 80\n    localPort: 18081\n    namespace: default\n    type: web\n    dependsOn: [api-v2]\n",
+			want: []string{"8: services api-v2 are in or depend on a dependsOn cycle"},
+		},
+		{
+			name: "unknown template",
+			data: "templates:\n  base:\n    from: other\nportForwards:\n  api:\n    from: base\n    target: service/api\n",
+			want: []string{`2: failed to expand service templates: template "base" cannot itself use from:`},
+		},
+		{
+			name:  "hooks in a project config",
+			data:  "hooks:\n  onFailed: curl https://example.com\n",
+			layer: LayerProject,
+			want:  []string{"0: hooks can only be set in the user config"},
+		},
+		{
+			name:  "exec health check in a project config",
+			data:  "portForwards:\n  api:\n    target: service/api\n    targetPort: 80\n    localPort: 18080\n    namespace: default\n    type: web\n    healthCheck:\n      protocol: exec\n      command: ./probe.sh\n",
+			layer: LayerProject,
+			want:  []string{"2: api: exec health checks can only be set in the user config"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layer := tt.layer
+			if layer == "" {
+				layer = LayerUser
+			}
+			var got []string
+			for _, issue := range ValidateConfigFile([]byte(tt.data), layer) {
+				prefix := ""
+				if issue.Warning {
+					prefix = "W"
+				}
+				got = append(got, fmt.Sprintf("%s%d: %s", prefix, issue.Line, issue.Message))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d issues, got %q", len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(got[i], want) {
+					t.Errorf("Expected issue %q, got %q", want, got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStarterConfigValidates(t *testing.T) {
+	for _, kubeContext := range []string{"", "staging-eu"} {
+		data := fmt.Sprintf(starterConfig, fmt.Sprintf("      context: %q", kubeContext))
+		if issues := ValidateConfigFile([]byte(data), LayerUser); len(issues) > 0 {
+			t.Errorf("Expected the starter config to validate, got %+v", issues)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// expandVariables expands $VAR, ${VAR} and ${VAR:-default} in the target, namespace and
+// swaggerPath of every service from kportforward's environment, so one config can serve
+// developers with their own namespaces such as "${USER}-dev". Templates are expanded
+// first, so their values are expanded too. A service using a variable that is not set
+// and has no default is left out and recorded in Skipped, rather than started against
+// the wrong namespace or failing the whole config.
+func expandVariables(cfg *Config) {
+	if cfg == nil {
+		return
+	}
+
+	names := make([]string, 0, len(cfg.PortForwards))
+	for name := range cfg.PortForwards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service := cfg.PortForwards[name]
+		fields := []struct {
+			name  string
+			value *string
+		}{
+			{"target", &service.Target},
+			{"namespace", &service.Namespace},
+			{"swaggerPath", &service.SwaggerPath},
+		}
+		var err error
+		for _, field := range fields {
+			var expanded string
+			if expanded, err = expandEnv(*field.value); err != nil {
+				err = fmt.Errorf("%s: %w", field.name, err)
+				break
+			}
+			*field.value = expanded
+		}
+		if err != nil {
+			if cfg.Skipped == nil {
+				cfg.Skipped = make(map[string]string)
+			}
+			cfg.Skipped[name] = err.Error()
+			delete(cfg.PortForwards, name)
+			continue
+		}
+		cfg.PortForwards[name] = service
+	}
+}
+
+// expandEnv expands variables in value, failing on the first one that is neither set
+// nor given a default with ${VAR:-default}. Like in a shell, the default also replaces
+// a variable that is set but empty.
+func expandEnv(value string) (string, error) {
+	var missing string
+	expanded := os.Expand(value, func(name string) string {
+		name, fallback, hasDefault := strings.Cut(name, ":-")
+		variable, ok := os.LookupEnv(name)
+		switch {
+		case hasDefault && variable == "":
+			return fallback
+		case !ok && missing == "":
+			missing = name
+		}
+		return variable
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %s is not set", missing)
+	}
+	return expanded, nil
+}
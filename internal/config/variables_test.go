@@ -0,0 +1,89 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestExpandVariables(t *testing.T) {
+	t.Setenv("KPF_TEST_USER", "alice")
+	t.Setenv("KPF_TEST_EMPTY", "")
+
+	cfg := &Config{
+		Templates: map[string]Service{
+			"personal": {Namespace: "${KPF_TEST_USER}-dev", TargetPort: 80},
+		},
+		PortForwards: map[string]Service{
+			"api": {From: "personal", Target: "service/api-$KPF_TEST_USER", SwaggerPath: "/${KPF_TEST_USER}/swagger.json", LocalPort: 8080},
+			"web": {Target: "service/web${KPF_TEST_EMPTY}", Namespace: "shared", LocalPort: 8081},
+		},
+	}
+	if err := expandTemplates(cfg); err != nil {
+		t.Fatal(err)
+	}
+	expandVariables(cfg)
+	if api := cfg.PortForwards["api"]; api.Namespace != "alice-dev" || api.Target != "service/api-alice" || api.SwaggerPath != "/alice/swagger.json" {
+		t.Errorf("Expected the variables to be expanded, got %+v", api)
+	}
+	if web := cfg.PortForwards["web"]; web.Target != "service/web" || web.Namespace != "shared" {
+		t.Errorf("Expected set but empty variables to expand to nothing, got %+v", web)
+	}
+	if len(cfg.Skipped) != 0 {
+		t.Errorf("Expected no skipped services, got %v", cfg.Skipped)
+	}
+}
+
+func TestExpandVariablesDefaults(t *testing.T) {
+	t.Setenv("KPF_TEST_USER", "alice")
+	t.Setenv("KPF_TEST_EMPTY", "")
+
+	cfg := &Config{
+		PortForwards: map[string]Service{
+			"set":   {Target: "service/api", Namespace: "${KPF_TEST_USER:-shared}-dev"},
+			"unset": {Target: "service/api", Namespace: "${KPF_TEST_UNSET:-shared}-dev"},
+			"empty": {Target: "service/api", Namespace: "${KPF_TEST_EMPTY:-shared}"},
+		},
+	}
+	expandVariables(cfg)
+	expected := map[string]string{"set": "alice-dev", "unset": "shared-dev", "empty": "shared"}
+	for name, namespace := range expected {
+		if got := cfg.PortForwards[name].Namespace; got != namespace {
+			t.Errorf("Expected %s to get namespace %s, got %q", name, namespace, got)
+		}
+	}
+}
+
+func TestExpandVariablesSkipsServicesWithUnsetVariables(t *testing.T) {
+	cfg := &Config{
+		PortForwards: map[string]Service{
+			"db":  {Target: "service/db", Namespace: "${KPF_TEST_UNSET}-dev"},
+			"api": {Target: "service/api", Namespace: "default"},
+		},
+	}
+	expandVariables(cfg)
+
+	if _, ok := cfg.PortForwards["db"]; ok {
+		t.Error("Expected the service with an unset variable to be left out")
+	}
+	if _, ok := cfg.PortForwards["api"]; !ok {
+		t.Error("Expected the other services to be kept")
+	}
+	if reason := cfg.Skipped["db"]; reason != "namespace: environment variable KPF_TEST_UNSET is not set" {
+		t.Errorf("Expected the unset variable to be recorded, got %q", reason)
+	}
+}
+
+func TestLoadConfigSkipsServicesWithUnsetVariables(t *testing.T) {
+	cfg, err := mergeConfigLayers([]ConfigLayer{
+		{Name: LayerDefaults, Data: DefaultConfigYAML},
+		{Name: LayerUser, Data: []byte("portForwards:\n  mine:\n    target: service/mine\n    targetPort: 80\n    localPort: 9876\n    namespace: ${KPF_TEST_UNSET}-dev\n    type: rest\n")},
+	})
+	if err != nil {
+		t.Fatalf("Expected the config to load without the service, got %v", err)
+	}
+	if _, ok := cfg.PortForwards["mine"]; ok || cfg.Skipped["mine"] == "" {
+		t.Errorf("Expected mine to be skipped, got %v", cfg.Skipped)
+	}
+	if len(cfg.PortForwards) == 0 {
+		t.Error("Expected the default services to be kept")
+	}
+}
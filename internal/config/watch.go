@@ -0,0 +1,163 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchInterval is how often watched config files are checked for changes when OS file
+// notifications are not available
+const WatchInterval = 2 * time.Second
+
+// watchSettle is how long a notified file has to stay unchanged before it is reported
+const watchSettle = 200 * time.Millisecond
+
+// fileState is what a change of a watched file is detected by
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// ConfigFiles returns the files LoadConfig reads, plus those of the named profiles
+func ConfigFiles(profiles []string) ([]string, error) {
+	path, err := getUserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if dir, err := os.Getwd(); err == nil {
+		files = append(files, filepath.Join(dir, ProjectConfigFile))
+	}
+
+	if len(profiles) > 0 {
+		dir, err := ProfilesDir()
+		if err != nil {
+			return nil, err
+		}
+		for _, profile := range profiles {
+			files = append(files, filepath.Join(dir, profile+".yaml"))
+		}
+	}
+	return files, nil
+}
+
+// Watch calls onChange after any of paths is created, removed or modified, until ctx
+// is cancelled. Changes are picked up through OS file notifications on the files'
+// directories, so editors that replace a file are noticed as well. Where notifications
+// are not available, such as for a directory that does not exist yet, the files are
+// polled every interval instead. A change is only reported once the files have stopped
+// changing, so editors writing in several steps trigger a single reload.
+func Watch(ctx context.Context, paths []string, interval time.Duration, onChange func()) {
+	last := statFiles(paths)
+	if watcher, err := watchDirs(paths); err == nil {
+		last = watchNotify(ctx, watcher, paths, last, onChange)
+		watcher.Close()
+		if ctx.Err() != nil {
+			return
+		}
+	}
+	watchPoll(ctx, paths, interval, last, onChange)
+}
+
+// watchDirs returns a watcher for the directories of paths
+func watchDirs(paths []string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+	return watcher, nil
+}
+
+// watchNotify reports changes from watcher until ctx is cancelled, or until the watcher
+// fails and events may have been lost. It returns the last state of the files.
+func watchNotify(ctx context.Context, watcher *fsnotify.Watcher, paths []string, last []fileState, onChange func()) []fileState {
+	watched := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		watched[filepath.Clean(path)] = true
+	}
+
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return last
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return last
+			}
+			if watched[filepath.Clean(event.Name)] {
+				settled = time.After(watchSettle)
+			}
+		case <-watcher.Errors:
+			return last
+		case <-settled:
+			settled = nil
+			// Events such as chmod leave the contents alone
+			if current := statFiles(paths); !sameFiles(last, current) {
+				last = current
+				onChange()
+			}
+		}
+	}
+}
+
+// watchPoll checks paths every interval until ctx is cancelled
+func watchPoll(ctx context.Context, paths []string, interval time.Duration, last []fileState, onChange func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := statFiles(paths)
+		if !sameFiles(last, current) {
+			last = current
+			pending = true
+			continue
+		}
+		if pending {
+			pending = false
+			onChange()
+		}
+	}
+}
+
+// statFiles records the state of every path
+func statFiles(paths []string) []fileState {
+	states := make([]fileState, len(paths))
+	for i, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			states[i] = fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+		}
+	}
+	return states
+}
+
+// sameFiles reports whether no file changed between two snapshots
+func sameFiles(a, b []fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].exists != b[i].exists || a[i].size != b[i].size || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
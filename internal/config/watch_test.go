@@ -0,0 +1,98 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchReportsSettledChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{}, 10)
+	go Watch(ctx, []string{path}, 10*time.Millisecond, func() { changes <- struct{}{} })
+
+	// Let the watcher record the missing file first
+	time.Sleep(30 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("portForwards: {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected creating the file to be reported")
+	}
+
+	// Without further writes nothing more is reported
+	select {
+	case <-changes:
+		t.Error("Expected a single report per change")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatchUsesNotifications(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{}, 10)
+	// Polling this rarely would never see the change in time
+	go Watch(ctx, []string{path}, time.Hour, func() { changes <- struct{}{} })
+
+	time.Sleep(30 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("portForwards: {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the change to be reported without waiting for a poll")
+	}
+}
+
+func TestWatchFallsBackToPolling(t *testing.T) {
+	// A missing directory cannot be watched, so the file is polled
+	dir := filepath.Join(t.TempDir(), "profiles")
+	path := filepath.Join(dir, "staging.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{}, 10)
+	go Watch(ctx, []string{path}, 10*time.Millisecond, func() { changes <- struct{}{} })
+
+	time.Sleep(30 * time.Millisecond)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("portForwards: {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected creating the file to be reported")
+	}
+}
+
+func TestSameFiles(t *testing.T) {
+	now := time.Now()
+	a := []fileState{{exists: true, size: 10, modTime: now}}
+
+	if !sameFiles(a, []fileState{{exists: true, size: 10, modTime: now}}) {
+		t.Error("Expected identical snapshots to match")
+	}
+	if sameFiles(a, []fileState{{exists: true, size: 11, modTime: now}}) {
+		t.Error("Expected a size change to be detected")
+	}
+	if sameFiles(a, []fileState{{}}) {
+		t.Error("Expected a removed file to be detected")
+	}
+}
@@ -0,0 +1,82 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Source is the application state captured in a diagnostic bundle. Any field may be unset.
+type Source struct {
+	Version string
+	Logger  *utils.Logger
+	Config  *config.Config
+	Status  func() map[string]config.ServiceStatus
+}
+
+// CrashDir returns the directory crash bundles are written to
+func CrashDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "kportforward", "crashes"), nil
+}
+
+// WriteBundle writes a new bundle directory under dir with a summary (reason and
+// stack traces), recent logs, the sanitized configuration and a status snapshot, and
+// returns its path. Parts that cannot be collected are skipped.
+func WriteBundle(dir, reason string, stack []byte, source Source) (string, error) {
+	path := filepath.Join(dir, "kportforward-"+time.Now().Format("20060102-150405"))
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return "", fmt.Errorf("failed to create bundle directory: %w", err)
+	}
+
+	files := map[string][]byte{
+		"summary.txt": summary(reason, stack, source.Version),
+	}
+	if source.Logger != nil {
+		files["logs.txt"] = []byte(strings.Join(source.Logger.RecentLines(), "\n") + "\n")
+	}
+	if source.Config != nil {
+		if data, err := yaml.Marshal(config.Sanitized(source.Config)); err == nil {
+			files["config.yaml"] = data
+		}
+	}
+	if source.Status != nil {
+		if data, err := json.MarshalIndent(source.Status(), "", "  "); err == nil {
+			files["status.json"] = data
+		}
+	}
+
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(path, name), data, 0600); err != nil {
+			return path, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return path, nil
+}
+
+// summary describes the build and platform, the reason and the stacks of all goroutines
+func summary(reason string, stack []byte, version string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "kportforward %s (%s, %s/%s)\n", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "time: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "reason: %s\n", reason)
+	if len(stack) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", stack)
+	}
+
+	all := make([]byte, 1<<20)
+	all = all[:runtime.Stack(all, true)]
+	fmt.Fprintf(&b, "\nall goroutines:\n\n%s\n", all)
+	return []byte(b.String())
+}
@@ -0,0 +1,267 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// DoctorCheck is the outcome of one doctor check
+type DoctorCheck struct {
+	Name     string
+	Detail   string // What was found, such as a version; shown for passed checks
+	Err      error  // Why the check failed; nil if it passed
+	Optional bool   // Only needed for some features, so a failure is a warning
+	Skipped  string // Why the check did not run, if it did not
+}
+
+// lookPath finds an executable in PATH; replaced in tests
+var lookPath = exec.LookPath
+
+// RunDoctor checks what kportforward needs to forward the services of cfg: kubectl, each
+// cluster and namespace the services use, Docker and grpcui for the UIs, and the local
+// ports. Checks are independent except that the cluster checks need kubectl.
+func RunDoctor(ctx context.Context, cfg *config.Config) []DoctorCheck {
+	services := cfg.PortForwards
+	checks := []DoctorCheck{checkKubectl(ctx)}
+	if checks[0].Err == nil {
+		checks = append(checks, checkClusters(ctx, services)...)
+	} else {
+		checks = append(checks, DoctorCheck{Name: "cluster", Skipped: "kubectl is missing"})
+	}
+
+	checks = append(checks,
+		checkUITool(ctx, services, config.UIHandlerSwagger, "docker", "--swaggerui"),
+		checkUITool(ctx, services, config.UIHandlerGRPCUI, "grpcui", "--grpcui"),
+	)
+	return append(checks, checkLocalPorts(services)...)
+}
+
+// checkKubectl checks that kubectl is installed and reports its version
+func checkKubectl(ctx context.Context) DoctorCheck {
+	check := DoctorCheck{Name: "kubectl"}
+	if _, err := lookPath("kubectl"); err != nil {
+		check.Err = fmt.Errorf("kubectl not found in PATH; install it from https://kubernetes.io/docs/tasks/tools/")
+		return check
+	}
+	output, err := runCommand(ctx, "kubectl", "version", "--client")
+	if err != nil {
+		check.Err = fmt.Errorf("kubectl version failed: %s", commandError(output, err))
+		return check
+	}
+	check.Detail = firstLine(output)
+	return check
+}
+
+// clusterTarget is a context and namespace services are forwarded from
+type clusterTarget struct {
+	kubeContext string
+	namespace   string
+}
+
+// checkClusters checks that each context the services use is reachable and that the
+// namespaces allow port-forwarding. Namespaces given by a pattern or selector are only
+// known at runtime and are not checked.
+func checkClusters(ctx context.Context, services map[string]config.Service) []DoctorCheck {
+	contexts := make(map[string]bool)
+	targets := make(map[clusterTarget]bool)
+	for _, service := range services {
+		contexts[service.Kubectl.Context] = true
+		if service.NamespaceSelector != "" || service.HasNamespacePattern() {
+			continue
+		}
+		namespace := service.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		targets[clusterTarget{kubeContext: service.Kubectl.Context, namespace: namespace}] = true
+	}
+	if len(contexts) == 0 {
+		contexts[""] = true
+	}
+
+	var checks []DoctorCheck
+	reachable := make(map[string]bool)
+	for _, kubeContext := range sortedKeys(contexts) {
+		check := DoctorCheck{Name: "cluster " + contextName(kubeContext)}
+		output, err := runCommand(ctx, "kubectl", withContext(kubeContext, "get", "--raw", "/version", "--request-timeout=5s")...)
+		if err != nil {
+			check.Err = fmt.Errorf("not reachable: %s", commandError(output, err))
+		} else {
+			reachable[kubeContext] = true
+			check.Detail = "reachable"
+		}
+		checks = append(checks, check)
+	}
+
+	sorted := make([]clusterTarget, 0, len(targets))
+	for target := range targets {
+		sorted = append(sorted, target)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].kubeContext != sorted[j].kubeContext {
+			return sorted[i].kubeContext < sorted[j].kubeContext
+		}
+		return sorted[i].namespace < sorted[j].namespace
+	})
+	for _, target := range sorted {
+		check := DoctorCheck{Name: fmt.Sprintf("port-forward in %s", target.namespace)}
+		if target.kubeContext != "" {
+			check.Name += " (" + target.kubeContext + ")"
+		}
+		if !reachable[target.kubeContext] {
+			check.Skipped = "cluster not reachable"
+		} else if check.Err = checkPortForwardAccess(ctx, target); check.Err == nil {
+			check.Detail = "allowed"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkPortForwardAccess asks the cluster whether the user may look up pods and
+// port-forward to them in a namespace
+func checkPortForwardAccess(ctx context.Context, target clusterTarget) error {
+	var denied []string
+	for _, access := range [][]string{{"get", "pods"}, {"create", "pods/portforward"}} {
+		args := withContext(target.kubeContext, "auth", "can-i", access[0], access[1], "-n", target.namespace)
+		output, err := runCommand(ctx, "kubectl", args...)
+		answer := strings.TrimSpace(string(output))
+		if answer == "yes" {
+			continue
+		}
+		// kubectl auth can-i exits non-zero when the answer is no
+		if !strings.HasPrefix(answer, "no") {
+			return fmt.Errorf("kubectl auth can-i failed: %s", commandError(output, err))
+		}
+		denied = append(denied, access[0]+" "+access[1])
+	}
+	if len(denied) > 0 {
+		return fmt.Errorf("not allowed to %s", strings.Join(denied, " or "))
+	}
+	return nil
+}
+
+// checkUITool checks the tool a UI handler needs, if any service uses that handler.
+// The UIs are optional, so a missing tool is a warning.
+func checkUITool(ctx context.Context, services map[string]config.Service, handler, tool, flag string) DoctorCheck {
+	check := DoctorCheck{Name: tool, Optional: true}
+	used := false
+	for _, service := range services {
+		if config.LookupServiceType(service.Type).UIHandler == handler {
+			used = true
+			break
+		}
+	}
+	if !used {
+		check.Skipped = "no service uses " + flag
+		return check
+	}
+
+	if _, err := lookPath(tool); err != nil {
+		check.Err = fmt.Errorf("%s not found in PATH; needed for %s", tool, flag)
+		return check
+	}
+	if tool != "docker" {
+		check.Detail = "found"
+		return check
+	}
+	output, err := runCommand(ctx, "docker", "version", "--format", "{{.Server.Version}}")
+	if err != nil {
+		check.Err = fmt.Errorf("docker is not running; needed for %s: %s", flag, commandError(output, err))
+		return check
+	}
+	check.Detail = "server " + firstLine(output)
+	return check
+}
+
+// checkLocalPorts reports every local port a service would forward on that is taken.
+// Services move to the next free port unless they set strictPort, so only those fail.
+func checkLocalPorts(services map[string]config.Service) []DoctorCheck {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var checks []DoctorCheck
+	free := 0
+	for _, name := range names {
+		service := services[name]
+		ports := []int{service.LocalPort}
+		for _, mapping := range service.Ports {
+			ports = append(ports, mapping.Local)
+		}
+		for _, port := range ports {
+			if utils.IsPortAvailable(port) {
+				free++
+				continue
+			}
+			holder := "another process"
+			if owner, err := utils.FindProcessOnPort(port); err == nil {
+				holder = owner.String()
+			}
+			checks = append(checks, DoctorCheck{
+				Name:     fmt.Sprintf("port %d (%s)", port, name),
+				Err:      fmt.Errorf("in use by %s", holder),
+				Optional: !service.StrictPort,
+			})
+		}
+	}
+	if len(checks) == 0 {
+		checks = append(checks, DoctorCheck{Name: "local ports", Detail: fmt.Sprintf("all %d free", free)})
+	}
+	return checks
+}
+
+// withContext prepends --context to kubectl arguments unless kubeContext is the current one
+func withContext(kubeContext string, args ...string) []string {
+	if kubeContext == "" {
+		return args
+	}
+	return append([]string{"--context", kubeContext}, args...)
+}
+
+// contextName names a context for the report, "" being the current one
+func contextName(kubeContext string) string {
+	if kubeContext == "" {
+		return "(current context)"
+	}
+	return kubeContext
+}
+
+// commandError is the output of a failed command, or its error if it printed nothing
+func commandError(output []byte, err error) string {
+	if line := firstLine(output); line != "" {
+		return line
+	}
+	if err == nil {
+		return "no output"
+	}
+	return err.Error()
+}
+
+// firstLine returns the first non-empty line of output
+func firstLine(output []byte) string {
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// sortedKeys returns the keys of set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
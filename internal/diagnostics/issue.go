@@ -0,0 +1,103 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Issue is what an issue report about a failing service describes
+type Issue struct {
+	Version  string
+	Service  string
+	Config   config.Service
+	Status   config.ServiceStatus
+	Context  string                // kubectl context the service runs in
+	Timeline []config.StatusChange // Oldest first
+}
+
+// IssueMarkdown returns a markdown issue about a failing service for its owning team:
+// its last errors, sanitized configuration, timeline and the kportforward, kubectl and
+// cluster versions. Errors and kubectl output are redacted like logs.
+func IssueMarkdown(ctx context.Context, issue Issue) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## `%s` keeps failing\n\n", issue.Service)
+
+	target := issue.Config.Target
+	if issue.Config.LabelSelector != "" {
+		target = "pods matching " + issue.Config.LabelSelector
+	}
+	fmt.Fprintf(&b, "Port-forward to %s port %d in namespace `%s`", target, issue.Config.TargetPort, issue.Config.Namespace)
+	if issue.Context != "" {
+		fmt.Fprintf(&b, " (context `%s`)", issue.Context)
+	}
+	fmt.Fprintf(&b, " is %s after %d restarts.", issue.Status.Status, issue.Status.RestartCount)
+	if downtime := issue.Status.Downtime; downtime.Outages > 0 {
+		fmt.Fprintf(&b, " It was down for %s in %d outages", downtime.TotalDowntime(time.Now()).Round(time.Second), downtime.Outages)
+		if mttr := downtime.MTTR(); mttr > 0 {
+			fmt.Fprintf(&b, ", recovering after %s on average", mttr.Round(time.Second))
+		}
+		b.WriteString(".")
+	}
+	b.WriteString("\n\n")
+
+	lastErrors := issue.Status.KubectlOutput
+	if issue.Status.LastError != "" {
+		lastErrors = append([]string{issue.Status.LastError}, lastErrors...)
+	}
+	if len(lastErrors) > 0 {
+		b.WriteString("### Last errors\n\n```\n")
+		b.Write(redact([]byte(strings.Join(lastErrors, "\n"))))
+		b.WriteString("\n```\n\n")
+	}
+
+	b.WriteString("### Timeline\n\n")
+	if len(issue.Timeline) == 0 {
+		b.WriteString("No status changes recorded.\n\n")
+	} else {
+		b.WriteString("| Time | Status | Error |\n|---|---|---|\n")
+		for _, change := range issue.Timeline {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", change.Time.Format(time.RFC3339), change.Status, tableCell(change.Error))
+		}
+		b.WriteString("\n")
+	}
+
+	sanitized := config.Sanitized(&config.Config{PortForwards: map[string]config.Service{issue.Service: issue.Config}})
+	if data, err := yaml.Marshal(sanitized.PortForwards); err == nil {
+		b.WriteString("### Configuration\n\n```yaml\n")
+		b.Write(data)
+		b.WriteString("```\n\n")
+	}
+
+	b.WriteString("### Environment\n\n")
+	fmt.Fprintf(&b, "- kportforward %s (%s, %s/%s)\n", issue.Version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	args := []string{"version"}
+	if issue.Context != "" {
+		args = append([]string{"--context", issue.Context}, args...)
+	}
+	commandCtx, cancel := context.WithTimeout(ctx, reportCommandTimeout)
+	output, err := runCommand(commandCtx, "kubectl", args...)
+	cancel()
+	// kubectl version prints the client version and fails if the cluster cannot be reached
+	for _, line := range strings.Split(strings.TrimSpace(string(redact(output))), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(&b, "- kubectl version failed: %v\n", err)
+	}
+	return []byte(b.String())
+}
+
+// tableCell escapes text for a markdown table cell
+func tableCell(text string) string {
+	text = string(redact([]byte(text)))
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", " ")
+}
@@ -0,0 +1,94 @@
+package diagnostics
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"sync"
+)
+
+// PanicExitCode is the exit code after a recovered panic, matching Go's own
+const PanicExitCode = 2
+
+// PanicHandler turns a panic into a diagnostic bundle: it restores the terminal,
+// writes the bundle and prints its path before exiting. Recover must be deferred at
+// the top of every goroutine that should be covered.
+type PanicHandler struct {
+	source Source
+
+	mutex    sync.Mutex
+	restore  []func()
+	handling bool
+
+	// Replaced in tests
+	dir  func() (string, error)
+	out  io.Writer
+	exit func(code int)
+}
+
+// NewPanicHandler creates a panic handler capturing source
+func NewPanicHandler(source Source) *PanicHandler {
+	return &PanicHandler{
+		source: source,
+		dir:    CrashDir,
+		out:    os.Stderr,
+		exit:   os.Exit,
+	}
+}
+
+// OnPanic registers restore to run before the bundle is written, e.g. to give the
+// terminal back from the TUI so the message is readable
+func (h *PanicHandler) OnPanic(restore func()) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.restore = append(h.restore, restore)
+}
+
+// Recover handles a panic of the calling goroutine; use it as "defer h.Recover()"
+func (h *PanicHandler) Recover() {
+	if recovered := recover(); recovered != nil {
+		h.handle(recovered, debug.Stack())
+	}
+}
+
+// handle writes the bundle for a recovered panic and exits
+func (h *PanicHandler) handle(recovered interface{}, stack []byte) {
+	h.mutex.Lock()
+	if h.handling {
+		// Another goroutine is already writing a bundle and will exit
+		h.mutex.Unlock()
+		select {}
+	}
+	h.handling = true
+	restore := h.restore
+	h.mutex.Unlock()
+
+	for _, fn := range restore {
+		func() {
+			// A broken terminal must not prevent the bundle from being written
+			defer func() { _ = recover() }()
+			fn()
+		}()
+	}
+
+	reason := fmt.Sprint(recovered)
+	if h.source.Logger != nil {
+		h.source.Logger.Error("panic: %s", reason)
+	}
+
+	fmt.Fprintf(h.out, "\nkportforward crashed: %s\n", reason)
+	dir, err := h.dir()
+	if err == nil {
+		var path string
+		path, err = WriteBundle(dir, reason, stack, h.source)
+		if err == nil {
+			fmt.Fprintf(h.out, "A diagnostic bundle was written to %s\nPlease attach it when reporting the issue.\n", path)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(h.out, "Failed to write diagnostic bundle: %v\n\n%s\n", err, stack)
+	}
+
+	h.exit(PanicExitCode)
+}
@@ -0,0 +1,260 @@
+package diagnostics
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/api"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// reportLogBytes is how much of the end of each log file a bug report includes
+const reportLogBytes = 1 << 20
+
+// reportCommandTimeout bounds each command run for the environment details
+const reportCommandTimeout = 5 * time.Second
+
+// environmentCommands are run for the environment details of a bug report
+var environmentCommands = [][]string{
+	{"kubectl", "version", "--client"},
+	{"kubectl", "config", "current-context"},
+	{"docker", "version", "--format", "client {{.Client.Version}}, server {{.Server.Version}}"},
+}
+
+// runCommand runs an environment command; replaced in tests
+var runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+// urlPattern and bearerPattern find secrets in log lines
+var (
+	urlPattern    = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'<>]+`)
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)\S+`)
+)
+
+// Report is what a bug report collects besides version and environment details. Any
+// field may be unset.
+type Report struct {
+	Version  string
+	Config   *config.Config
+	LogFiles []string                        // Log files, of which the end is included
+	Status   map[string]config.ServiceStatus // From a running instance
+	Audit    []api.AuditEntry                // Control actions of a running instance
+	CrashDir string                          // Directory whose most recent crash bundle is included
+	Problems []string                        // What the caller could not collect
+}
+
+// archiveFile is one file of a bug report archive
+type archiveFile struct {
+	name string
+	data []byte
+}
+
+// WriteReport writes a gzipped tarball with the report to path. Log files and crash
+// bundles have URL credentials, query values and bearer tokens redacted, the config is
+// sanitized. Parts that cannot be collected are listed in summary.txt instead.
+func WriteReport(ctx context.Context, path string, report Report) error {
+	problems := append([]string(nil), report.Problems...)
+	var files []archiveFile
+	add := func(name string, data []byte) {
+		files = append(files, archiveFile{name: name, data: data})
+	}
+
+	add("environment.txt", environment(ctx))
+	if report.Config != nil {
+		if data, err := yaml.Marshal(config.Sanitized(report.Config)); err == nil {
+			add("config.yaml", data)
+		} else {
+			problems = append(problems, fmt.Sprintf("config: %v", err))
+		}
+	}
+	for _, logFile := range report.LogFiles {
+		data, err := readTail(logFile, reportLogBytes)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("log %s: %v", logFile, err))
+			continue
+		}
+		add("logs/"+filepath.Base(logFile), redact(data))
+	}
+	if report.Status != nil {
+		if data, err := json.MarshalIndent(report.Status, "", "  "); err == nil {
+			add("status.json", data)
+		}
+	}
+	if report.Audit != nil {
+		if data, err := json.MarshalIndent(report.Audit, "", "  "); err == nil {
+			add("audit.json", data)
+		}
+	}
+	if report.CrashDir != "" {
+		crashFiles, err := latestCrash(report.CrashDir)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("crash bundle: %v", err))
+		}
+		files = append(files, crashFiles...)
+	}
+
+	// The summary goes first so it is what people see when listing the archive
+	files = append([]archiveFile{{name: "summary.txt", data: reportSummary(report.Version, problems)}}, files...)
+	return writeArchive(path, files)
+}
+
+// writeArchive writes files into a gzipped tarball below a directory named like it
+func writeArchive(path string, files []archiveFile) error {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bug report: %w", err)
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	archive := tar.NewWriter(gz)
+	root := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".gz"), ".tar")
+	now := time.Now()
+	for _, file := range files {
+		header := &tar.Header{
+			Name:    root + "/" + file.name,
+			Mode:    0600,
+			Size:    int64(len(file.data)),
+			ModTime: now,
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+		if _, err := archive.Write(file.data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write bug report: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write bug report: %w", err)
+	}
+	return out.Close()
+}
+
+// reportSummary describes the build and platform and lists what could not be collected
+func reportSummary(version string, problems []string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "kportforward %s (%s, %s/%s)\n", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "time: %s\n", time.Now().Format(time.RFC3339))
+	if len(problems) > 0 {
+		b.WriteString("\nnot collected:\n")
+		for _, problem := range problems {
+			fmt.Fprintf(&b, "  %s\n", problem)
+		}
+	}
+	return []byte(b.String())
+}
+
+// environment runs each environment command and records its output or error
+func environment(ctx context.Context) []byte {
+	var b strings.Builder
+	for _, command := range environmentCommands {
+		commandCtx, cancel := context.WithTimeout(ctx, reportCommandTimeout)
+		output, err := runCommand(commandCtx, command[0], command[1:]...)
+		cancel()
+
+		fmt.Fprintf(&b, "$ %s\n", strings.Join(command, " "))
+		if len(output) > 0 {
+			b.WriteString(strings.TrimRight(string(redact(output)), "\n") + "\n")
+		}
+		if err != nil {
+			fmt.Fprintf(&b, "error: %v\n", err)
+		}
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// readTail reads up to limit bytes from the end of path, starting at a full line
+func readTail(path string, limit int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() <= limit {
+		return io.ReadAll(file)
+	}
+
+	if _, err := file.Seek(info.Size()-limit, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	if i := strings.IndexByte(string(data), '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return data, nil
+}
+
+// latestCrash returns the files of the most recent crash bundle in dir, redacted
+func latestCrash(dir string) ([]archiveFile, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Bundle names end in a sortable timestamp
+	var bundles []string
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), "kportforward-") {
+			bundles = append(bundles, entry.Name())
+		}
+	}
+	if len(bundles) == 0 {
+		return nil, nil
+	}
+	sort.Strings(bundles)
+	latest := bundles[len(bundles)-1]
+
+	bundleFiles, err := os.ReadDir(filepath.Join(dir, latest))
+	if err != nil {
+		return nil, err
+	}
+	var files []archiveFile
+	for _, entry := range bundleFiles {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, latest, entry.Name()))
+		if err != nil {
+			return files, err
+		}
+		files = append(files, archiveFile{name: "crash/" + latest + "/" + entry.Name(), data: redact(data)})
+	}
+	return files, nil
+}
+
+// redact removes credentials from URLs and bearer tokens in free text such as logs
+func redact(data []byte) []byte {
+	text := urlPattern.ReplaceAllStringFunc(string(data), config.SanitizeURL)
+	text = bearerPattern.ReplaceAllString(text, "${1}REDACTED")
+	return []byte(text)
+}
@@ -0,0 +1,128 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// SubscriberStats describes the buffering state of one subscriber
+type SubscriberStats struct {
+	Length    int    `json:"length"`
+	Capacity  int    `json:"capacity"`
+	Delivered uint64 `json:"delivered"`
+	Dropped   uint64 `json:"dropped"`
+}
+
+// subscriber is a named, buffered receiver of published values
+type subscriber[T any] struct {
+	ch        chan T
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// Bus fans out published values to any number of named subscribers. Publishing
+// never blocks: when a subscriber's buffer is full its oldest value is dropped
+// in favour of the newest one, and the drop is counted. Publishes are serialized,
+// so the last value published is always the last one every subscriber receives.
+type Bus[T any] struct {
+	mutex       sync.RWMutex
+	subscribers map[string]*subscriber[T]
+	closed      bool
+}
+
+// NewBus creates an empty bus
+func NewBus[T any]() *Bus[T] {
+	return &Bus[T]{subscribers: make(map[string]*subscriber[T])}
+}
+
+// Subscribe registers a subscriber with the given buffer size and returns its
+// channel. Subscribing again under the same name returns the existing channel.
+func (b *Bus[T]) Subscribe(name string, buffer int) <-chan T {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if sub, exists := b.subscribers[name]; exists {
+		return sub.ch
+	}
+
+	if buffer < 1 {
+		buffer = 1
+	}
+	sub := &subscriber[T]{ch: make(chan T, buffer)}
+	if b.closed {
+		close(sub.ch)
+	} else {
+		b.subscribers[name] = sub
+	}
+	return sub.ch
+}
+
+// Unsubscribe removes the subscriber and closes its channel
+func (b *Bus[T]) Unsubscribe(name string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if sub, exists := b.subscribers[name]; exists {
+		delete(b.subscribers, name)
+		close(sub.ch)
+	}
+}
+
+// Publish delivers value to every subscriber without blocking
+func (b *Bus[T]) Publish(value T) {
+	// Held exclusively so concurrent publishes cannot interleave and lose the newest value
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, sub := range b.subscribers {
+		select {
+		case sub.ch <- value:
+			sub.delivered.Add(1)
+			continue
+		default:
+		}
+
+		// Buffer full: make room by discarding the oldest value. Only receivers can take
+		// values meanwhile, so the send below cannot block.
+		select {
+		case <-sub.ch:
+			sub.dropped.Add(1)
+		default:
+		}
+
+		sub.ch <- value
+		sub.delivered.Add(1)
+	}
+}
+
+// Stats returns buffering and drop counters per subscriber
+func (b *Bus[T]) Stats() map[string]SubscriberStats {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	stats := make(map[string]SubscriberStats, len(b.subscribers))
+	for name, sub := range b.subscribers {
+		stats[name] = SubscriberStats{
+			Length:    len(sub.ch),
+			Capacity:  cap(sub.ch),
+			Delivered: sub.delivered.Load(),
+			Dropped:   sub.dropped.Load(),
+		}
+	}
+	return stats
+}
+
+// Close closes every subscriber channel; later subscriptions receive a closed channel
+func (b *Bus[T]) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for name, sub := range b.subscribers {
+		delete(b.subscribers, name)
+		close(sub.ch)
+	}
+}
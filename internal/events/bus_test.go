@@ -0,0 +1,95 @@
+package events
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestBusFanOut(t *testing.T) {
+	bus := NewBus[int]()
+	first := bus.Subscribe("first", 2)
+	second := bus.Subscribe("second", 2)
+
+	bus.Publish(1)
+
+	if got := <-first; got != 1 {
+		t.Errorf("first subscriber got %d, expected 1", got)
+	}
+	if got := <-second; got != 1 {
+		t.Errorf("second subscriber got %d, expected 1", got)
+	}
+
+	if again := bus.Subscribe("first", 10); again != first {
+		t.Error("Subscribing twice under the same name should return the same channel")
+	}
+}
+
+func TestBusDropsOldestWhenFull(t *testing.T) {
+	bus := NewBus[int]()
+	ch := bus.Subscribe("slow", 1)
+
+	bus.Publish(1)
+	bus.Publish(2)
+	bus.Publish(3)
+
+	if got := <-ch; got != 3 {
+		t.Errorf("Expected newest value 3 to be kept, got %d", got)
+	}
+
+	stats := bus.Stats()["slow"]
+	if stats.Dropped != 2 {
+		t.Errorf("Expected 2 dropped values, got %d", stats.Dropped)
+	}
+	if stats.Delivered != 3 || stats.Capacity != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestBusConcurrentPublishKeepsNewest(t *testing.T) {
+	bus := NewBus[int]()
+	ch := bus.Subscribe("slow", 2)
+
+	var publishers sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		publishers.Add(1)
+		go func(value int) {
+			defer publishers.Done()
+			bus.Publish(value)
+		}(i)
+	}
+	publishers.Wait()
+	bus.Publish(100)
+
+	// Every publish is either still buffered or counted as dropped, never lost silently
+	stats := bus.Stats()["slow"]
+	if stats.Delivered != 51 || stats.Dropped != 49 || stats.Length != 2 {
+		t.Errorf("Unexpected stats after concurrent publishes: %+v", stats)
+	}
+	<-ch
+	if got := <-ch; got != 100 {
+		t.Errorf("Expected the last published value 100 to be received last, got %d", got)
+	}
+}
+
+func TestBusUnsubscribeAndClose(t *testing.T) {
+	bus := NewBus[string]()
+	ch := bus.Subscribe("gone", 1)
+	bus.Unsubscribe("gone")
+
+	if _, ok := <-ch; ok {
+		t.Error("Expected channel to be closed after unsubscribe")
+	}
+
+	// Publishing without subscribers must not panic
+	bus.Publish("ignored")
+
+	remaining := bus.Subscribe("remaining", 1)
+	bus.Close()
+	if _, ok := <-remaining; ok {
+		t.Error("Expected channel to be closed after Close")
+	}
+	if _, ok := <-bus.Subscribe("late", 1); ok {
+		t.Error("Expected subscriptions after Close to be closed")
+	}
+	bus.Publish("after close")
+}
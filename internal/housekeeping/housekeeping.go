@@ -0,0 +1,134 @@
+package housekeeping
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/ui_handlers"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// DefaultRetention is how old logs and snapshots must be before they are removed
+const DefaultRetention = 7 * 24 * time.Hour
+
+// RemoteCacheRetention is how old the remote config cache must be before it is removed.
+// It is longer than DefaultRetention since the cache is the offline fallback, and older
+// caches are no longer used anyway.
+const RemoteCacheRetention = config.RemoteCacheMaxAge
+
+// DefaultInterval is how often a running kportforward cleans up
+const DefaultInterval = 24 * time.Hour
+
+// Options selects what Clean removes
+type Options struct {
+	Retention       time.Duration // Files older than this are removed (0 = DefaultRetention)
+	DryRun          bool          // Report what would be removed without removing it
+	LogFile         string        // Rotated copies next to it (<file>.*) are removed
+	LogDir          string        // Rotated copies of the logs in it (*.log.*) are removed
+	HeapSnapshotDir string        // Old heap-*.pb.gz snapshots in it are removed
+}
+
+// Report lists what was removed, or would be removed in a dry run
+type Report struct {
+	Files      []string
+	Containers []string
+	Errors     []error
+}
+
+// String summarizes the report in one line
+func (r Report) String() string {
+	return fmt.Sprintf("%d files, %d containers, %d errors", len(r.Files), len(r.Containers), len(r.Errors))
+}
+
+// Clean removes what kportforward leaves behind: old gRPC UI logs, Swagger UI containers
+// of instances that are no longer running, an outdated remote config cache, rotated log
+// files and heap snapshots
+func Clean(opts Options) Report {
+	retention := opts.Retention
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	now := time.Now()
+	cutoff := now.Add(-retention)
+
+	var report Report
+	report.collect(removeOldFiles(filepath.Join(ui_handlers.GRPCUILogDir(), ui_handlers.GRPCUILogPattern), cutoff, opts.DryRun))
+
+	if cachePath, err := config.RemoteCachePath(); err == nil {
+		report.collect(removeOldFiles(cachePath, now.Add(-RemoteCacheRetention), opts.DryRun))
+	}
+	if opts.LogFile != "" {
+		report.collect(removeOldFiles(opts.LogFile+".*", cutoff, opts.DryRun))
+	}
+	if opts.LogDir != "" {
+		report.collect(removeOldFiles(filepath.Join(opts.LogDir, "*.log.*"), cutoff, opts.DryRun))
+	}
+	if opts.HeapSnapshotDir != "" {
+		report.collect(removeOldFiles(filepath.Join(opts.HeapSnapshotDir, "heap-*.pb.gz"), cutoff, opts.DryRun))
+	}
+
+	containers, err := ui_handlers.RemoveOrphanedSwaggerContainers(opts.DryRun)
+	report.Containers = containers
+	if err != nil {
+		report.Errors = append(report.Errors, err)
+	}
+
+	return report
+}
+
+// Run cleans up now and then every interval until ctx is cancelled
+func Run(ctx context.Context, opts Options, interval time.Duration, logger *utils.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report := Clean(opts)
+		if len(report.Files) > 0 || len(report.Containers) > 0 {
+			logger.Info("Housekeeping removed %s", report)
+		}
+		for _, err := range report.Errors {
+			logger.Warn("Housekeeping: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collect adds the result of removeOldFiles to the report
+func (r *Report) collect(files []string, errs []error) {
+	r.Files = append(r.Files, files...)
+	r.Errors = append(r.Errors, errs...)
+}
+
+// removeOldFiles removes regular files matching pattern last modified before cutoff
+func removeOldFiles(pattern string, cutoff time.Time, dryRun bool) ([]string, []error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, []error{fmt.Errorf("invalid pattern %s: %w", pattern, err)}
+	}
+
+	var removed []string
+	var errs []error
+	for _, path := range matches {
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", path, err))
+				continue
+			}
+		}
+		removed = append(removed, path)
+	}
+	return removed, errs
+}
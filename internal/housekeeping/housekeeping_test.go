@@ -0,0 +1,52 @@
+package housekeeping
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRemoveOldFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	write := func(name string, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := now.Add(-age)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	current := write("kportforward.log", 30*24*time.Hour)
+	old := write("kportforward.log.1", 10*24*time.Hour)
+	recent := write("kportforward.log.2", time.Hour)
+
+	pattern := filepath.Join(dir, "kportforward.log.*")
+	cutoff := now.Add(-DefaultRetention)
+
+	removed, errs := removeOldFiles(pattern, cutoff, true)
+	if len(errs) != 0 || len(removed) != 1 || removed[0] != old {
+		t.Fatalf("Expected dry run to report only the old rotated log, got %v %v", removed, errs)
+	}
+	if _, err := os.Stat(old); err != nil {
+		t.Error("Expected dry run to keep the file")
+	}
+
+	removed, _ = removeOldFiles(pattern, cutoff, false)
+	if len(removed) != 1 {
+		t.Fatalf("Expected one file removed, got %v", removed)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("Expected old rotated log to be removed")
+	}
+	for _, path := range []string{current, recent} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be kept", path)
+		}
+	}
+}
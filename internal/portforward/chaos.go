@@ -0,0 +1,100 @@
+package portforward
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/common"
+)
+
+// chaosOutageDuration is how long a simulated auth or network failure lasts
+const chaosOutageDuration = 45 * time.Second
+
+// EnableChaos randomly kills kubectl processes and simulates auth and network
+// failures roughly every interval until the manager stops. It exists to exercise
+// and demo recovery, backoff and TUI states without breaking a real cluster.
+func (m *Manager) EnableChaos(interval time.Duration, seed int64) {
+	if interval <= 0 {
+		return
+	}
+
+	m.logger.Warn("Chaos mode enabled: injecting failures every ~%v (seed %d)", interval, seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	go func() {
+		for {
+			// Jitter of ±50% so failures do not line up with the monitoring ticks
+			wait := interval/2 + time.Duration(rng.Int63n(int64(interval)))
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+
+			switch rng.Intn(4) {
+			case 0:
+				m.simulateOutage(common.ErrAuth, chaosOutageDuration)
+			case 1:
+				m.simulateOutage(common.ErrNetwork, chaosOutageDuration)
+			default:
+				// Process crashes are the most common real failure
+				m.killRandomService(rng)
+			}
+		}
+	}()
+}
+
+// killRandomService stops the port-forward of a random running service as if it crashed
+func (m *Manager) killRandomService(rng *rand.Rand) {
+	m.mutex.RLock()
+	tunnels := make(map[string]tunnel)
+	for name, sm := range m.services {
+		sm.mutex.RLock()
+		if sm.tunnel != nil {
+			tunnels[name] = sm.tunnel
+		}
+		sm.mutex.RUnlock()
+	}
+	m.mutex.RUnlock()
+
+	if len(tunnels) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(tunnels))
+	for name := range tunnels {
+		names = append(names, name)
+	}
+	// Map iteration order is random; sort for reproducible runs with the same seed
+	sort.Strings(names)
+	name := names[rng.Intn(len(names))]
+
+	m.logger.Warn("Chaos: killing the port-forward of %s (PID %d)", name, tunnels[name].PID())
+	if err := tunnels[name].Stop(); err != nil {
+		m.logger.Debug("Chaos: failed to kill %s: %v", name, err)
+	}
+}
+
+// simulateOutage makes global access checks fail with the given kind for a while
+func (m *Manager) simulateOutage(kind error, duration time.Duration) {
+	m.chaosMutex.Lock()
+	defer m.chaosMutex.Unlock()
+
+	m.logger.Warn("Chaos: simulating %v for %v", kind, duration)
+	m.chaosFailure = kind
+	m.chaosUntil = time.Now().Add(duration)
+}
+
+// simulatedAccessFailure returns the injected global access error, if one is active
+func (m *Manager) simulatedAccessFailure() error {
+	m.chaosMutex.Lock()
+	defer m.chaosMutex.Unlock()
+
+	if m.chaosFailure == nil || time.Now().After(m.chaosUntil) {
+		m.chaosFailure = nil
+		return nil
+	}
+	return common.WithKind(m.chaosFailure, fmt.Errorf("chaos: simulated %v", m.chaosFailure))
+}
@@ -0,0 +1,60 @@
+package portforward
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/common"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestChaosSimulatedOutage tests that injected failures short-circuit global access checks
+func TestChaosSimulatedOutage(t *testing.T) {
+	manager := NewManager(&config.Config{PortForwards: map[string]config.Service{}},
+		utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard))
+
+	manager.simulateOutage(common.ErrAuth, time.Minute)
+	if err := manager.checkGlobalAccess(); !errors.Is(err, common.ErrAuth) {
+		t.Errorf("Expected simulated auth failure, got %v", err)
+	}
+
+	manager.simulateOutage(common.ErrNetwork, -time.Second)
+	if err := manager.simulatedAccessFailure(); err != nil {
+		t.Errorf("Expected expired outage to be cleared, got %v", err)
+	}
+}
+
+// TestChaosKillsService tests that chaos kills a running kubectl process
+func TestChaosKillsService(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	manager := NewManager(&config.Config{PortForwards: map[string]config.Service{}}, logger)
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start helper process: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	sm := NewServiceManager("victim", config.Service{}, logger)
+	sm.tunnel = &kubectlTunnel{cmd: cmd}
+	manager.services["victim"] = sm
+
+	manager.killRandomService(rand.New(rand.NewSource(1)))
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected chaos to kill the kubectl process")
+	}
+}
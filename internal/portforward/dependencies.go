@@ -0,0 +1,97 @@
+package portforward
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// setDependencies makes sm wait for the services in its dependsOn that the manager runs
+// from services. Dependencies on unknown or disabled services and on selector entries
+// are ignored, like in restartStagger's dependencies order; cycles are rejected when the
+// config is loaded.
+func (m *Manager) setDependencies(sm *ServiceManager, services map[string]config.Service) {
+	var dependencies []string
+	for _, name := range sm.config.DependsOn {
+		if service, exists := services[name]; exists && !service.HasSelector() {
+			dependencies = append(dependencies, name)
+		}
+	}
+	sm.SetDependencies(dependencies, m.publishedStatus)
+}
+
+// publishedStatus returns the status of a service in the last published status map,
+// or "" before it was published
+func (m *Manager) publishedStatus(name string) string {
+	m.lastStatusMutex.RLock()
+	defer m.lastStatusMutex.RUnlock()
+	return m.lastStatus[name].Status
+}
+
+// startWaitingServices starts the services waiting for dependencies that are all
+// Running now
+func (m *Manager) startWaitingServices() {
+	m.mutex.RLock()
+	services := make(map[string]*ServiceManager, len(m.services))
+	for name, sm := range m.services {
+		services[name] = sm
+	}
+	m.mutex.RUnlock()
+
+	for name, sm := range services {
+		sm.mutex.Lock()
+		if sm.status.Status != "Waiting" || len(sm.pendingDependencies()) > 0 {
+			sm.mutex.Unlock()
+			continue
+		}
+		m.logger.Info("Dependencies of %s are running, starting", name)
+		sm.status.Status = "Starting"
+		sm.status.StatusMessage = ""
+		sm.mutex.Unlock()
+
+		go func(serviceName string, serviceManager *ServiceManager) {
+			if m.isShuttingDown() {
+				return
+			}
+			if err := serviceManager.Start(); err != nil {
+				m.logger.Error("Failed to start service %s: %v", serviceName, err)
+			}
+		}(name, sm)
+	}
+}
+
+// SetDependencies makes Start wait until every service in dependencies is Running,
+// according to status
+func (sm *ServiceManager) SetDependencies(dependencies []string, status func(name string) string) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.dependencies = dependencies
+	sm.dependencyStatus = status
+}
+
+// pendingDependencies returns the dependencies that are not Running yet. Callers must
+// hold the mutex.
+func (sm *ServiceManager) pendingDependencies() []string {
+	var pending []string
+	for _, name := range sm.dependencies {
+		if sm.dependencyStatus(name) != "Running" {
+			pending = append(pending, name)
+		}
+	}
+	return pending
+}
+
+// markWaiting records that the service is down until pending are Running. Callers must
+// hold the mutex and have stopped the process.
+func (sm *ServiceManager) markWaiting(pending []string) {
+	if sm.status.Status != "Waiting" {
+		sm.logger.Info("Service %s waits for %s", sm.name, strings.Join(pending, ", "))
+	}
+	sm.status.Status = "Waiting"
+	sm.status.StatusMessage = fmt.Sprintf("Waiting for %s", strings.Join(pending, ", "))
+	sm.status.LastError = ""
+	sm.status.PID = 0
+	sm.status.StartTime = time.Time{}
+}
@@ -0,0 +1,49 @@
+package portforward
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestServiceWaitsForDependencies tests that a service starts once its dependencies run
+func TestServiceWaitsForDependencies(t *testing.T) {
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	services := map[string]config.Service{
+		"auth":    {Target: "service/auth", LocalPort: 8081},
+		"pods":    {LabelSelector: "app=worker", LocalPort: 8082},
+		"gateway": {Target: "service/gateway", LocalPort: 8080, DependsOn: []string{"auth", "pods", "removed"}},
+	}
+	manager := NewManager(&config.Config{PortForwards: services}, logger)
+	// Started services are not launched, kubectl is not available in tests
+	manager.shuttingDown = true
+
+	gateway := NewServiceManager("gateway", services["gateway"], logger)
+	manager.setDependencies(gateway, services)
+	manager.services["gateway"] = gateway
+	if !reflect.DeepEqual(gateway.dependencies, []string{"auth"}) {
+		t.Errorf("Expected selector entries and unknown services to be ignored, got %v", gateway.dependencies)
+	}
+
+	if err := gateway.Start(); err != nil {
+		t.Fatalf("Expected the service to wait without an error, got %v", err)
+	}
+	if status := gateway.GetStatus(); status.Status != "Waiting" || status.StatusMessage != "Waiting for auth" {
+		t.Errorf("Expected the service to wait for auth, got %s (%s)", status.Status, status.StatusMessage)
+	}
+
+	manager.publishStatus(map[string]config.ServiceStatus{"auth": {Name: "auth", Status: "Connecting"}})
+	manager.startWaitingServices()
+	if status := gateway.GetStatus(); status.Status != "Waiting" {
+		t.Errorf("Expected the service to wait while auth is connecting, got %s", status.Status)
+	}
+
+	manager.publishStatus(map[string]config.ServiceStatus{"auth": {Name: "auth", Status: "Running"}})
+	manager.startWaitingServices()
+	if status := gateway.GetStatus(); status.Status != "Starting" {
+		t.Errorf("Expected the service to start once auth is running, got %s", status.Status)
+	}
+}
@@ -0,0 +1,122 @@
+package portforward
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// isDownStatus reports whether a service in status cannot be reached although it should
+func isDownStatus(status string) bool {
+	switch status {
+	case "Failed", "Suspended", "Reconnecting", "Cooldown", "PortConflict":
+		return true
+	}
+	return false
+}
+
+// isStoppedStatus reports whether a service in status was stopped on purpose, which
+// ends an outage without repairing it
+func isStoppedStatus(status string) bool {
+	switch status {
+	case "Stopped", "Idle", "Scheduled":
+		return true
+	}
+	return false
+}
+
+// SetDowntimeFile keeps the downtime statistics in path across sessions: they are read
+// from it now, and written to it when an outage ends and when the manager stops. It
+// must be called before Start.
+func (m *Manager) SetDowntimeFile(path string) error {
+	m.downtimeMutex.Lock()
+	defer m.downtimeMutex.Unlock()
+
+	m.downtimePath = path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read downtime file: %w", err)
+	}
+
+	stats := make(map[string]config.DowntimeStats)
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return fmt.Errorf("failed to parse downtime file %s: %w", path, err)
+	}
+	for name, stat := range stats {
+		// Ongoing outages are saved as ended, as the next session cannot tell when they ended
+		stat.DownSince = time.Time{}
+		stats[name] = stat
+	}
+	m.downtime = stats
+	return nil
+}
+
+// recordDowntime updates the downtime statistics of a service seen in status at now and
+// returns them. Services that were never down have none.
+func (m *Manager) recordDowntime(name, status string, now time.Time) config.DowntimeStats {
+	m.downtimeMutex.Lock()
+	stats := m.downtime[name]
+	ended := false
+	switch {
+	case isDownStatus(status) && stats.DownSince.IsZero():
+		stats.Outages++
+		stats.DownSince = now
+	case !stats.DownSince.IsZero() && (status == "Running" || status == "Degraded"):
+		stats.Repaired++
+		stats.RepairTime += now.Sub(stats.DownSince)
+		fallthrough
+	case !stats.DownSince.IsZero() && isStoppedStatus(status):
+		stats.Downtime += now.Sub(stats.DownSince)
+		stats.DownSince = time.Time{}
+		ended = true
+	default:
+		m.downtimeMutex.Unlock()
+		return stats
+	}
+
+	if m.downtime == nil {
+		m.downtime = make(map[string]config.DowntimeStats)
+	}
+	m.downtime[name] = stats
+	m.downtimeMutex.Unlock()
+
+	if ended {
+		m.saveDowntime(now)
+	}
+	return stats
+}
+
+// saveDowntime writes the downtime statistics to the downtime file, if one is set.
+// Ongoing outages are saved as ended at now, as they cannot be followed once saved.
+func (m *Manager) saveDowntime(now time.Time) {
+	m.downtimeMutex.Lock()
+	if m.downtimePath == "" {
+		m.downtimeMutex.Unlock()
+		return
+	}
+	stats := make(map[string]config.DowntimeStats, len(m.downtime))
+	for name, stat := range m.downtime {
+		if !stat.DownSince.IsZero() {
+			stat.Downtime += now.Sub(stat.DownSince)
+			stat.DownSince = time.Time{}
+		}
+		stats[name] = stat
+	}
+	path := m.downtimePath
+	m.downtimeMutex.Unlock()
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err == nil {
+		err = replaceFile(path, append(data, '\n'))
+	}
+	if err != nil {
+		m.logger.Warn("Failed to save downtime statistics: %v", err)
+	}
+}
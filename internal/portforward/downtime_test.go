@@ -0,0 +1,56 @@
+package portforward
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestRecordDowntime tests that outages are counted, repaired and persisted
+func TestRecordDowntime(t *testing.T) {
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	manager := NewManager(&config.Config{}, logger)
+	path := filepath.Join(t.TempDir(), "downtime.json")
+	if err := manager.SetDowntimeFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(offset time.Duration) time.Time { return start.Add(offset) }
+
+	manager.recordDowntime("api", "Running", at(0))
+	manager.recordDowntime("api", "Failed", at(10*time.Second))
+	manager.recordDowntime("api", "Connecting", at(20*time.Second))
+	stats := manager.recordDowntime("api", "Running", at(40*time.Second))
+	if stats.Outages != 1 || stats.Repaired != 1 || stats.Downtime != 30*time.Second || !stats.DownSince.IsZero() {
+		t.Errorf("Expected one repaired 30s outage, got %+v", stats)
+	}
+
+	// Stopping ends an outage without repairing it
+	manager.recordDowntime("api", "Suspended", at(time.Minute))
+	stats = manager.recordDowntime("api", "Stopped", at(2*time.Minute))
+	if stats.Outages != 2 || stats.Repaired != 1 || stats.Downtime != 90*time.Second || stats.MTTR() != 30*time.Second {
+		t.Errorf("Expected 90s downtime in 2 outages and a 30s MTTR, got %+v", stats)
+	}
+
+	stats = manager.recordDowntime("web", "PortConflict", at(2*time.Minute))
+	if stats.TotalDowntime(at(3*time.Minute)) != time.Minute {
+		t.Errorf("Expected the ongoing outage in the total downtime, got %v", stats.TotalDowntime(at(3*time.Minute)))
+	}
+	manager.saveDowntime(at(3 * time.Minute))
+
+	restored := NewManager(&config.Config{}, logger)
+	if err := restored.SetDowntimeFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if stats := restored.recordDowntime("api", "Running", at(time.Hour)); stats.Outages != 2 || stats.Downtime != 90*time.Second {
+		t.Errorf("Expected the api statistics to be restored, got %+v", stats)
+	}
+	if stats := restored.recordDowntime("web", "Running", at(time.Hour)); stats.Downtime != time.Minute || !stats.DownSince.IsZero() {
+		t.Errorf("Expected the ongoing outage of web to be saved as ended, got %+v", stats)
+	}
+}
@@ -0,0 +1,82 @@
+package portforward
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// endpointCheckInterval is how often the endpoints of a service with restartOnEndpointChange are checked
+const endpointCheckInterval = 10 * time.Second
+
+// getEndpointPods is replaced in tests to avoid calling kubectl
+var getEndpointPods = utils.GetEndpointPods
+
+// WatchEndpoints restarts a service with restartOnEndpointChange once a pod leaves the
+// ready endpoints of its target service. kubectl does not say which pod it forwards to,
+// so any pod leaving counts; pods being added do not. Services with trackPod already
+// follow their pod and are skipped. Lookup errors are ignored, like those of TrackPod.
+func (sm *ServiceManager) WatchEndpoints() {
+	service, isService := strings.CutPrefix(sm.config.Target, "service/")
+	if !sm.config.RestartOnEndpointChange || sm.config.TrackPod || !isService {
+		return
+	}
+
+	sm.mutex.Lock()
+	if sm.status.Status != "Running" || time.Since(sm.lastEndpointCheck) < endpointCheckInterval {
+		sm.mutex.Unlock()
+		return
+	}
+	sm.lastEndpointCheck = time.Now()
+	previous := sm.endpointPods
+	sm.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pods, err := getEndpointPods(ctx, sm.config.Kubectl.Context, sm.config.Namespace, service)
+	if err != nil {
+		sm.logger.Debug("Could not check endpoints of %s: %v", sm.name, err)
+		return
+	}
+
+	if pods == nil {
+		pods = []string{} // Distinguishes no endpoints from not checked yet
+	}
+	sm.mutex.Lock()
+	sm.endpointPods = pods
+	sm.mutex.Unlock()
+
+	// The first check after a start only records the endpoints
+	if previous == nil {
+		return
+	}
+	removed := removedPods(previous, pods)
+	if len(removed) == 0 {
+		return
+	}
+
+	sm.logger.Event(utils.LevelInfo, "endpoints_changed", "Pods %s left the endpoints of %s, restarting",
+		strings.Join(removed, ", "), sm.name)
+	sm.SetStatusMessage("endpoints changed, reconnecting")
+	if err := sm.Restart(); err != nil {
+		sm.logger.Warn("Failed to restart %s after its endpoints changed: %v", sm.name, err)
+	}
+}
+
+// removedPods returns the pods of previous that are not in current
+func removedPods(previous, current []string) []string {
+	remaining := make(map[string]bool, len(current))
+	for _, pod := range current {
+		remaining[pod] = true
+	}
+	var removed []string
+	for _, pod := range previous {
+		if !remaining[pod] {
+			removed = append(removed, pod)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,68 @@
+package portforward
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestWatchEndpointsRecordsPods tests which services are checked and that added pods are
+// recorded without a restart
+func TestWatchEndpointsRecordsPods(t *testing.T) {
+	original := getEndpointPods
+	defer func() { getEndpointPods = original }()
+
+	endpoints := []string{"api-1"}
+	var checked []string
+	getEndpointPods = func(ctx context.Context, kubeContext, namespace, service string) ([]string, error) {
+		checked = append(checked, service)
+		return endpoints, nil
+	}
+
+	sm := newUnreachableService(t, config.HealthCheckConfig{})
+	sm.config.Target = "service/api"
+	sm.WatchEndpoints()
+	if len(checked) != 0 {
+		t.Errorf("Expected no check without restartOnEndpointChange, got %v", checked)
+	}
+
+	sm.config.RestartOnEndpointChange = true
+	sm.config.Target = "deployment/api"
+	sm.WatchEndpoints()
+	if len(checked) != 0 {
+		t.Errorf("Expected no check for a deployment target, got %v", checked)
+	}
+
+	sm.config.Target = "service/api"
+	sm.WatchEndpoints()
+	if len(checked) != 1 || checked[0] != "api" || !reflect.DeepEqual(sm.endpointPods, []string{"api-1"}) {
+		t.Errorf("Expected the endpoints of api to be recorded, got %v after %v", sm.endpointPods, checked)
+	}
+
+	sm.WatchEndpoints()
+	if len(checked) != 1 {
+		t.Errorf("Expected no check before the interval, got %v", checked)
+	}
+
+	endpoints = []string{"api-1", "api-2"}
+	sm.lastEndpointCheck = sm.lastEndpointCheck.AddDate(-1, 0, 0)
+	sm.WatchEndpoints()
+	if !reflect.DeepEqual(sm.endpointPods, endpoints) {
+		t.Errorf("Expected the added pod to be recorded, got %v", sm.endpointPods)
+	}
+	if status := sm.GetStatus(); status.Status != "Running" || status.RestartCount != 0 {
+		t.Errorf("Expected the service to keep running, got %s after %d restarts", status.Status, status.RestartCount)
+	}
+}
+
+// TestRemovedPods tests finding the pods that left the endpoints
+func TestRemovedPods(t *testing.T) {
+	if removed := removedPods([]string{"api-1", "api-2"}, []string{"api-2", "api-3"}); !reflect.DeepEqual(removed, []string{"api-1"}) {
+		t.Errorf("Expected api-1 to be removed, got %v", removed)
+	}
+	if removed := removedPods([]string{"api-1"}, []string{"api-1", "api-2"}); len(removed) != 0 {
+		t.Errorf("Expected no pods removed when one is added, got %v", removed)
+	}
+}
@@ -0,0 +1,229 @@
+package portforward
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// newUnreachableService returns a running service whose port does not accept connections
+func newUnreachableService(t *testing.T, healthCheck config.HealthCheckConfig) *ServiceManager {
+	t.Helper()
+
+	// Grab a free port and close it again so nothing is listening there
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve port: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	// Use the test process itself as the "kubectl" process so it is alive
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to find own process: %v", err)
+	}
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	sm := NewServiceManager("test-service", config.Service{LocalPort: port, HealthCheck: healthCheck}, logger)
+	sm.tunnel = &kubectlTunnel{cmd: &exec.Cmd{Process: process}}
+	sm.status.Status = "Running"
+	sm.status.LocalPort = port
+	sm.status.StartTime = time.Now().Add(-time.Minute)
+	sm.lastHealthCheckTime = time.Time{}
+	return sm
+}
+
+// TestHealthCheckModes tests how each mode reacts to an unreachable port
+func TestHealthCheckModes(t *testing.T) {
+	tests := []struct {
+		mode     string
+		expected string
+	}{
+		{"", "Degraded"},
+		{config.HealthCheckStrict, "Degraded"},
+		{config.HealthCheckLenient, "Running"},
+		{config.HealthCheckOff, "Running"},
+	}
+
+	for _, tt := range tests {
+		t.Run("mode="+tt.mode, func(t *testing.T) {
+			sm := newUnreachableService(t, config.HealthCheckConfig{Mode: tt.mode})
+			sm.EvaluateHealth()
+			if status := sm.GetStatus(); status.Status != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, status.Status)
+			}
+		})
+	}
+}
+
+// TestHealthCheckInterval tests that checks are skipped until the interval has passed
+func TestHealthCheckInterval(t *testing.T) {
+	sm := newUnreachableService(t, config.HealthCheckConfig{Interval: time.Hour})
+	sm.lastHealthCheckTime = time.Now()
+
+	sm.EvaluateHealth()
+	if status := sm.GetStatus(); status.Status != "Running" {
+		t.Errorf("Expected no health check before the interval, got %s", status.Status)
+	}
+
+	sm.lastHealthCheckTime = time.Now().Add(-2 * time.Hour)
+	sm.EvaluateHealth()
+	if status := sm.GetStatus(); status.Status != "Degraded" {
+		t.Errorf("Expected health check once the interval passed, got %s", status.Status)
+	}
+}
+
+// TestGetStatusDoesNotEvaluateHealth tests that reading status never probes the port
+func TestGetStatusDoesNotEvaluateHealth(t *testing.T) {
+	sm := newUnreachableService(t, config.HealthCheckConfig{})
+
+	if status := sm.GetStatus(); status.Status != "Running" {
+		t.Errorf("Expected GetStatus to leave status untouched, got %s", status.Status)
+	}
+	if !sm.lastHealthCheckTime.IsZero() {
+		t.Error("Expected GetStatus not to run a health check")
+	}
+}
+
+// TestHealthCheckThresholds tests degradedAfter, failedAfter and hideDegraded
+func TestHealthCheckThresholds(t *testing.T) {
+	tests := []struct {
+		name        string
+		healthCheck config.HealthCheckConfig
+		expected    []string // Status after each failed check
+	}{
+		{"default", config.HealthCheckConfig{}, []string{"Degraded", "Failed"}},
+		{"degradedAfter", config.HealthCheckConfig{DegradedAfter: 2, FailedAfter: 4}, []string{"Running", "Degraded", "Degraded", "Failed"}},
+		{"hideDegraded", config.HealthCheckConfig{HideDegraded: true, FailedAfter: 3}, []string{"Running", "Running", "Failed"}},
+		{"lenient failedAfter", config.HealthCheckConfig{Mode: config.HealthCheckLenient, FailedAfter: 2}, []string{"Running", "Failed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := newUnreachableService(t, tt.healthCheck)
+			for i, expected := range tt.expected {
+				sm.lastHealthCheckTime = time.Time{}
+				sm.EvaluateHealth()
+				if status := sm.GetStatus(); status.Status != expected {
+					t.Fatalf("Expected %s after %d failed checks, got %s", expected, i+1, status.Status)
+				}
+			}
+		})
+	}
+}
+
+// TestLastHealthy tests that passing health checks are timestamped and failing ones keep the time
+func TestLastHealthy(t *testing.T) {
+	sm := newUnreachableService(t, config.HealthCheckConfig{})
+	sm.EvaluateHealth()
+	if status := sm.GetStatus(); !status.LastHealthy.IsZero() {
+		t.Fatalf("Expected no passing check yet, got %v", status.LastHealthy)
+	}
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	sm.status.LocalPort = listener.Addr().(*net.TCPAddr).Port
+	sm.lastHealthCheckTime = time.Time{}
+	sm.EvaluateHealth()
+	passed := sm.GetStatus().LastHealthy
+	if passed.IsZero() {
+		t.Fatal("Expected the passing check to be timestamped")
+	}
+
+	listener.Close()
+	sm.lastHealthCheckTime = time.Time{}
+	sm.EvaluateHealth()
+	if status := sm.GetStatus(); status.Status != "Degraded" || !status.LastHealthy.Equal(passed) {
+		t.Errorf("Expected a degraded service to keep its last passing check, got %s %v", status.Status, status.LastHealthy)
+	}
+}
+
+// TestProbeLatency tests that http probes record their duration and failures
+func TestProbeLatency(t *testing.T) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy || r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	port := server.Listener.Addr().(*net.TCPAddr).Port
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	sm := NewServiceManager("api", config.Service{
+		HealthCheck: config.HealthCheckConfig{Protocol: config.ProbeHTTP, Path: "/healthz"},
+	}, logger)
+
+	if !sm.probePort(port) {
+		t.Fatal("Expected the http probe to pass")
+	}
+	status := sm.GetStatus()
+	if status.ProbeLatency <= 0 || status.ProbeError != "" || len(status.ProbeLatencies) != 1 {
+		t.Errorf("Expected a recorded latency, got %v %q %v", status.ProbeLatency, status.ProbeError, status.ProbeLatencies)
+	}
+
+	healthy = false
+	if sm.probePort(port) {
+		t.Fatal("Expected a 503 to fail the http probe")
+	}
+	status = sm.GetStatus()
+	if status.ProbeError == "" || len(status.ProbeLatencies) != 2 || status.ProbeLatencies[1] != 0 {
+		t.Errorf("Expected a recorded failure, got %q %v", status.ProbeError, status.ProbeLatencies)
+	}
+
+	for i := 0; i < probeHistorySize; i++ {
+		sm.probePort(port)
+	}
+	if status := sm.GetStatus(); len(status.ProbeLatencies) != probeHistorySize {
+		t.Errorf("Expected %d probes kept, got %d", probeHistorySize, len(status.ProbeLatencies))
+	}
+}
+
+// TestExecProbe tests that exec probes get the local port and report the command's output
+func TestExecProbe(t *testing.T) {
+	original := runProbeShellCommand
+	defer func() { runProbeShellCommand = original }()
+
+	var gotCommand string
+	var gotEnv []string
+	var output []byte
+	var err error
+	runProbeShellCommand = func(ctx context.Context, command string, env []string) ([]byte, error) {
+		gotCommand, gotEnv = command, env
+		return output, err
+	}
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	sm := NewServiceManager("db", config.Service{HealthCheck: config.HealthCheckConfig{
+		Protocol: config.ProbeExec, Command: "pg_isready -p $KPORTFORWARD_LOCAL_PORT",
+	}}, logger)
+
+	if !sm.probePort(5432) || gotCommand != "pg_isready -p $KPORTFORWARD_LOCAL_PORT" {
+		t.Fatalf("Expected the probe command to pass, ran %q", gotCommand)
+	}
+	if !slices.Contains(gotEnv, "KPORTFORWARD_LOCAL_PORT=5432") {
+		t.Errorf("Expected the local port in the environment, got %v", gotEnv)
+	}
+
+	output, err = []byte("localhost:5432 - no response\n"), errors.New("exit status 2")
+	if sm.probePort(5432) {
+		t.Fatal("Expected a failing command to fail the probe")
+	}
+	if status := sm.GetStatus(); status.ProbeError != "exit status 2: localhost:5432 - no response" {
+		t.Errorf("Expected the command's output in the probe error, got %q", status.ProbeError)
+	}
+}
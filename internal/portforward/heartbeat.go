@@ -0,0 +1,109 @@
+package portforward
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// heartbeat is written to the heartbeat file when it ends in .json
+type heartbeat struct {
+	Time         time.Time      `json:"time"`
+	PID          int            `json:"pid"`
+	Context      string         `json:"context"`
+	GlobalAccess bool           `json:"globalAccess"`
+	Idle         bool           `json:"idle"`
+	Services     map[string]int `json:"services"` // Number of services per status
+}
+
+// SetHeartbeatFile makes every monitoring tick touch path, or write a heartbeat JSON
+// to it when it ends in .json, so external supervisors can tell the monitoring loop
+// is still running. It must be called before Start.
+func (m *Manager) SetHeartbeatFile(path string) {
+	m.heartbeatPath = path
+}
+
+// writeHeartbeat updates the heartbeat file, if one is set. Only the first of
+// consecutive failures is logged, as it would otherwise be logged every tick.
+func (m *Manager) writeHeartbeat() {
+	if m.heartbeatPath == "" {
+		return
+	}
+
+	var err error
+	if strings.HasSuffix(m.heartbeatPath, ".json") {
+		err = writeHeartbeatJSON(m.heartbeatPath, m.currentHeartbeat(time.Now()))
+	} else {
+		err = touchFile(m.heartbeatPath, time.Now())
+	}
+
+	if err != nil && !m.heartbeatFailing {
+		m.logger.Warn("Failed to write heartbeat file: %v", err)
+	} else if err == nil && m.heartbeatFailing {
+		m.logger.Info("Heartbeat file written again")
+	}
+	m.heartbeatFailing = err != nil
+}
+
+// currentHeartbeat summarizes the most recently published status at now
+func (m *Manager) currentHeartbeat(now time.Time) heartbeat {
+	beat := heartbeat{
+		Time:         now,
+		PID:          os.Getpid(),
+		Context:      m.GetKubernetesContext(),
+		GlobalAccess: m.GetGlobalAccessStatus(),
+		Idle:         m.IsIdle(),
+		Services:     make(map[string]int),
+	}
+	for _, status := range m.GetLastStatus() {
+		beat.Services[status.Status]++
+	}
+	return beat
+}
+
+// writeHeartbeatJSON replaces the file at path with beat
+func writeHeartbeatJSON(path string, beat heartbeat) error {
+	data, err := json.Marshal(beat)
+	if err != nil {
+		return fmt.Errorf("failed to encode heartbeat: %w", err)
+	}
+	return replaceFile(path, append(data, '\n'))
+}
+
+// replaceFile replaces the file at path with data. It writes a temporary file and
+// renames it, so readers never see a partly written file.
+func replaceFile(path string, data []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// touchFile sets the modification time of path to now, creating it if needed
+func touchFile(path string, now time.Time) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create heartbeat file: %w", err)
+	}
+	file.Close()
+	if err := os.Chtimes(path, now, now); err != nil {
+		return fmt.Errorf("failed to touch heartbeat file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package portforward
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestWriteHeartbeat tests touching a plain heartbeat file and writing a JSON one
+func TestWriteHeartbeat(t *testing.T) {
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	manager := NewManager(&config.Config{MonitoringInterval: 5 * time.Second}, logger)
+	manager.lastStatus = map[string]config.ServiceStatus{
+		"api":    {Name: "api", Status: "Running"},
+		"db":     {Name: "db", Status: "Running"},
+		"search": {Name: "search", Status: "Failed"},
+	}
+	dir := t.TempDir()
+
+	// A plain file is created, then only has its modification time updated
+	touched := filepath.Join(dir, "kportforward.heartbeat")
+	manager.SetHeartbeatFile(touched)
+	manager.writeHeartbeat()
+	stale := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(touched, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	manager.writeHeartbeat()
+	info, err := os.Stat(touched)
+	if err != nil {
+		t.Fatalf("Expected heartbeat file, got %v", err)
+	}
+	if info.Size() != 0 || time.Since(info.ModTime()) > time.Minute {
+		t.Errorf("Expected an empty, freshly touched file, got %d bytes modified %v", info.Size(), info.ModTime())
+	}
+
+	// A .json file gets the status summary
+	summary := filepath.Join(dir, "heartbeat.json")
+	manager.SetHeartbeatFile(summary)
+	manager.writeHeartbeat()
+	data, err := os.ReadFile(summary)
+	if err != nil {
+		t.Fatalf("Expected heartbeat JSON, got %v", err)
+	}
+	var beat heartbeat
+	if err := json.Unmarshal(data, &beat); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, data)
+	}
+	if beat.PID != os.Getpid() || !beat.GlobalAccess || beat.Services["Running"] != 2 || beat.Services["Failed"] != 1 {
+		t.Errorf("Unexpected heartbeat %+v", beat)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected no temporary files left behind, got %d entries", len(entries))
+	}
+
+	// Failures are remembered so they are logged once
+	manager.SetHeartbeatFile(filepath.Join(dir, "missing", "heartbeat.json"))
+	manager.writeHeartbeat()
+	if !manager.heartbeatFailing {
+		t.Error("Expected writing into a missing directory to fail")
+	}
+}
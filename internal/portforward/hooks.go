@@ -0,0 +1,186 @@
+package portforward
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// hookTimeout is how long a hook may run before it is killed
+const hookTimeout = 30 * time.Second
+
+// Hook events, also passed to hooks in KPORTFORWARD_EVENT
+const (
+	hookFailed    = "failed"
+	hookRecovered = "recovered"
+	hookSuspended = "suspended"
+	hookRunning   = "running"
+)
+
+// hookHost is the host forwarded services are reached on, passed to hooks
+const hookHost = "localhost"
+
+// hookPayload is the JSON body posted to URL hooks, the same values commands get in
+// their environment
+type hookPayload struct {
+	Event     string `json:"event"`
+	Service   string `json:"service"`
+	Status    string `json:"status"`
+	Host      string `json:"host"`
+	LocalPort int    `json:"localPort"`
+	Namespace string `json:"namespace"`
+	Context   string `json:"context"`
+	Error     string `json:"error,omitempty"`
+}
+
+// runHookCommand is replaced in tests to avoid starting a shell
+var runHookCommand = func(command string, env []string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	return runShellCommand(ctx, command, env)
+}
+
+// postHookURL is replaced in tests to avoid HTTP requests
+var postHookURL = func(url string, payload hookPayload) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: hookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	output, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return output, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return output, nil
+}
+
+// checkHooks runs the hooks for the transition of a service into status, if any. A
+// service recovers once it is Running after the failed or suspended hook ran, so the
+// Starting and Connecting states in between do not matter. It becomes Running whenever
+// it is Running after being anything but Running or Degraded.
+func (m *Manager) checkHooks(sm *ServiceManager, status config.ServiceStatus) {
+	m.hookMutex.Lock()
+	if m.hookStates == nil {
+		m.hookStates = make(map[string]string)
+		m.hookRunning = make(map[string]bool)
+	}
+	last := m.hookStates[sm.name]
+
+	var events []string
+	switch {
+	case status.Status == "Failed" && last != hookFailed:
+		events = append(events, hookFailed)
+		m.hookStates[sm.name] = hookFailed
+	case status.Status == "Suspended" && last != hookSuspended:
+		events = append(events, hookSuspended)
+		m.hookStates[sm.name] = hookSuspended
+	case status.Status == "Running" && last != "":
+		events = append(events, hookRecovered)
+		delete(m.hookStates, sm.name)
+	}
+	if status.Status == "Running" && !m.hookRunning[sm.name] {
+		events = append(events, hookRunning)
+	}
+	m.hookRunning[sm.name] = status.Status == "Running" || status.Status == "Degraded"
+	m.hookMutex.Unlock()
+
+	for _, event := range events {
+		command := hookCommand(sm.config.Hooks, event)
+		if command == "" && m.config != nil {
+			command = hookCommand(m.config.Hooks, event)
+		}
+		if command != "" {
+			m.runHook(sm, status, event, command)
+		}
+	}
+}
+
+// runHook runs command, a shell command or URL, for event in the background
+func (m *Manager) runHook(sm *ServiceManager, status config.ServiceStatus, event, command string) {
+	payload := hookPayload{
+		Event:     event,
+		Service:   sm.name,
+		Status:    status.Status,
+		Host:      hookHost,
+		LocalPort: status.LocalPort,
+		Namespace: sm.config.Namespace,
+		Context:   m.GetKubernetesContext(),
+		Error:     status.LastError,
+	}
+
+	if isHookURL(command) {
+		go func() {
+			m.logger.Debug("Calling %s hook of %s: %s", event, sm.name, command)
+			output, err := postHookURL(command, payload)
+			if err != nil {
+				m.logger.Warn("The %s hook of %s failed: %v: %s", event, sm.name, err, strings.TrimSpace(string(output)))
+			}
+		}()
+		return
+	}
+
+	env := append(os.Environ(),
+		"KPORTFORWARD_EVENT="+payload.Event,
+		"KPORTFORWARD_SERVICE="+payload.Service,
+		"KPORTFORWARD_STATUS="+payload.Status,
+		"KPORTFORWARD_HOST="+payload.Host,
+		fmt.Sprintf("KPORTFORWARD_LOCAL_PORT=%d", payload.LocalPort),
+		"KPORTFORWARD_NAMESPACE="+payload.Namespace,
+		"KPORTFORWARD_CONTEXT="+payload.Context,
+		"KPORTFORWARD_ERROR="+payload.Error,
+	)
+	go func() {
+		m.logger.Debug("Running %s hook of %s: %s", event, sm.name, command)
+		output, err := runHookCommand(command, env)
+		if err != nil {
+			m.logger.Warn("The %s hook of %s failed: %v: %s", event, sm.name, err, strings.TrimSpace(string(output)))
+		}
+	}()
+}
+
+// isHookURL reports whether a hook is a URL to call rather than a command
+func isHookURL(command string) bool {
+	return strings.HasPrefix(command, "http://") || strings.HasPrefix(command, "https://")
+}
+
+// hookCommand returns the command of hooks for event, or ""
+func hookCommand(hooks config.HooksConfig, event string) string {
+	switch event {
+	case hookFailed:
+		return hooks.OnFailed
+	case hookRecovered:
+		return hooks.OnRecovered
+	case hookSuspended:
+		return hooks.OnSuspended
+	case hookRunning:
+		return hooks.OnRunning
+	}
+	return ""
+}
+
+// runShellCommand runs command in the system shell with env until ctx ends and returns
+// its output
+func runShellCommand(ctx context.Context, command string, env []string) ([]byte, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	cmd.Env = env
+	return cmd.CombinedOutput()
+}
@@ -0,0 +1,142 @@
+package portforward
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestCheckHooks tests which transitions run hooks and that service hooks replace global ones
+func TestCheckHooks(t *testing.T) {
+	original := runHookCommand
+	defer func() { runHookCommand = original }()
+
+	ran := make(chan string, 10)
+	runHookCommand = func(command string, env []string) ([]byte, error) {
+		for _, variable := range env {
+			if variable == "KPORTFORWARD_SERVICE=api" {
+				ran <- command
+				return nil, nil
+			}
+		}
+		t.Errorf("Expected the service in the environment of %q, got %v", command, env)
+		ran <- command
+		return nil, nil
+	}
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	manager := NewManager(&config.Config{Hooks: config.HooksConfig{
+		OnFailed:    "global-failed",
+		OnRecovered: "global-recovered",
+	}}, logger)
+	sm := NewServiceManager("api", config.Service{Hooks: config.HooksConfig{OnFailed: "api-failed"}}, logger)
+
+	expectHook := func(status, want string) {
+		t.Helper()
+		manager.checkHooks(sm, config.ServiceStatus{Name: "api", Status: status})
+		if want == "" {
+			select {
+			case command := <-ran:
+				t.Errorf("Expected no hook for %s, got %q", status, command)
+			case <-time.After(50 * time.Millisecond):
+			}
+			return
+		}
+		select {
+		case command := <-ran:
+			if command != want {
+				t.Errorf("Expected hook %q for %s, got %q", want, status, command)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("Expected hook %q for %s, got none", want, status)
+		}
+	}
+
+	expectHook("Running", "")
+	expectHook("Failed", "api-failed")
+	expectHook("Failed", "")
+	expectHook("Starting", "")
+	expectHook("Running", "global-recovered")
+	expectHook("Running", "")
+
+	// Without an onSuspended hook nothing runs, but the service still recovers afterwards
+	expectHook("Suspended", "")
+	expectHook("Running", "global-recovered")
+}
+
+// TestRunningHooks tests that onRunning fires each time a service comes up, and that URL
+// hooks are posted to rather than run
+func TestRunningHooks(t *testing.T) {
+	originalCommand, originalURL := runHookCommand, postHookURL
+	defer func() { runHookCommand, postHookURL = originalCommand, originalURL }()
+
+	ran := make(chan string, 10)
+	runHookCommand = func(command string, env []string) ([]byte, error) {
+		found := 0
+		for _, variable := range env {
+			if variable == "KPORTFORWARD_HOST=localhost" || variable == "KPORTFORWARD_LOCAL_PORT=5432" {
+				found++
+			}
+		}
+		if found != 2 {
+			t.Errorf("Expected the host and port in the environment of %q, got %v", command, env)
+		}
+		ran <- command
+		return nil, nil
+	}
+	posted := make(chan hookPayload, 10)
+	postHookURL = func(url string, payload hookPayload) ([]byte, error) {
+		if url != "http://localhost:3000/ready" {
+			t.Errorf("Expected the hook URL to be posted to, got %q", url)
+		}
+		posted <- payload
+		return nil, nil
+	}
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	manager := NewManager(&config.Config{Hooks: config.HooksConfig{OnRunning: "make migrate"}}, logger)
+	db := NewServiceManager("db", config.Service{Namespace: "data"}, logger)
+	api := NewServiceManager("api", config.Service{Hooks: config.HooksConfig{OnRunning: "http://localhost:3000/ready"}}, logger)
+
+	expectRuns := func(status string, want int) {
+		t.Helper()
+		manager.checkHooks(db, config.ServiceStatus{Name: "db", Status: status, LocalPort: 5432})
+		for i := 0; i < want; i++ {
+			select {
+			case command := <-ran:
+				if command != "make migrate" {
+					t.Errorf("Expected the onRunning hook for %s, got %q", status, command)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("Expected the onRunning hook for %s, got none", status)
+			}
+		}
+		select {
+		case command := <-ran:
+			t.Errorf("Expected no further hook for %s, got %q", status, command)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	expectRuns("Starting", 0)
+	expectRuns("Running", 1)
+	expectRuns("Running", 0)
+	expectRuns("Degraded", 0)
+	expectRuns("Running", 0)
+	expectRuns("Failed", 0)
+	expectRuns("Starting", 0)
+	expectRuns("Running", 1)
+
+	manager.checkHooks(api, config.ServiceStatus{Name: "api", Status: "Running", LocalPort: 8080})
+	select {
+	case payload := <-posted:
+		if payload.Event != hookRunning || payload.Service != "api" || payload.Host != "localhost" || payload.LocalPort != 8080 {
+			t.Errorf("Unexpected payload %+v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the URL hook to be posted to")
+	}
+}
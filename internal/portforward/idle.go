@@ -0,0 +1,131 @@
+package portforward
+
+import (
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// IsIdle reports whether all services were stopped by the idle timeout
+func (m *Manager) IsIdle() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.idle
+}
+
+// ResumeFromIdle restarts every service stopped by the idle timeout
+func (m *Manager) ResumeFromIdle() {
+	m.mutex.Lock()
+	if !m.idle {
+		m.mutex.Unlock()
+		return
+	}
+	m.idle = false
+	services := make(map[string]*ServiceManager, len(m.services))
+	for name, sm := range m.services {
+		services[name] = sm
+	}
+	m.mutex.Unlock()
+
+	m.logger.Info("Resuming services after idle period")
+
+	for name, sm := range services {
+		sm.mutex.Lock()
+		if sm.status.Status != "Idle" {
+			sm.mutex.Unlock()
+			continue
+		}
+		sm.status.Status = "Reconnecting"
+		sm.status.StatusMessage = "Resuming after idle period"
+		sm.mutex.Unlock()
+
+		go func(serviceName string, serviceManager *ServiceManager) {
+			if m.isShuttingDown() {
+				return
+			}
+			if err := serviceManager.Restart(); err != nil {
+				m.logger.Error("Failed to resume service %s: %v", serviceName, err)
+			}
+		}(name, sm)
+	}
+}
+
+// SetResumeHint sets how the user can resume services stopped by the idle timeout, such
+// as a command, for the log message written when they are stopped. It must be called
+// before Start.
+func (m *Manager) SetResumeHint(hint string) {
+	m.resumeHint = hint
+}
+
+// idleTimeout returns the configured idle timeout (0 = disabled)
+func (m *Manager) idleTimeout() time.Duration {
+	if m.config == nil {
+		return 0
+	}
+	return m.config.IdleTimeout
+}
+
+// checkIdle stops all services once no traffic has passed through any of them
+// for the configured idle timeout. Returns true if the manager went idle.
+func (m *Manager) checkIdle() bool {
+	timeout := m.idleTimeout()
+	if timeout <= 0 {
+		return false
+	}
+
+	m.mutex.RLock()
+	services := make(map[string]*ServiceManager, len(m.services))
+	for name, sm := range m.services {
+		services[name] = sm
+	}
+	m.mutex.RUnlock()
+
+	var lastActivity time.Time
+	for _, sm := range services {
+		if activity := sm.LastActivity(); activity.After(lastActivity) {
+			lastActivity = activity
+		}
+	}
+
+	if lastActivity.IsZero() || time.Since(lastActivity) < timeout {
+		return false
+	}
+
+	if m.resumeHint != "" {
+		m.logger.Info("No connections for %s, stopping all services until resumed (%s)", utils.FormatUptime(timeout), m.resumeHint)
+	} else {
+		m.logger.Info("No connections for %s, stopping all services until resumed", utils.FormatUptime(timeout))
+	}
+
+	m.mutex.Lock()
+	m.idle = true
+	m.mutex.Unlock()
+
+	for name, sm := range services {
+		sm.mutex.Lock()
+		if sm.tunnel != nil {
+			m.logger.Debug("Stopping port-forward for idle service %s (PID %d)", name, sm.tunnel.PID())
+			if err := sm.tunnel.Stop(); err != nil {
+				m.logger.Warn("Failed to stop port-forward for idle service %s: %v", name, err)
+			}
+			sm.tunnel = nil
+		}
+		sm.status.Status = "Idle"
+		sm.status.StatusMessage = "Stopped after inactivity"
+		sm.status.PID = 0
+		sm.status.StartTime = time.Time{}
+		sm.mutex.Unlock()
+	}
+
+	return true
+}
+
+// sendIdleStatus publishes the status of idle services without probing them
+func (m *Manager) sendIdleStatus() {
+	statusMap := m.GetCurrentStatus()
+
+	// Let UI handlers shut down their helpers for the stopped services
+	m.monitorUIHandlers(statusMap)
+
+	m.publishStatus(statusMap)
+}
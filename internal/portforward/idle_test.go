@@ -0,0 +1,116 @@
+package portforward
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestLastActivityIgnoresProbes tests that our own health probes do not count as traffic
+func TestLastActivityIgnoresProbes(t *testing.T) {
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	sm := NewServiceManager("test-service", config.Service{LocalPort: 8080}, logger)
+
+	start := time.Now().Add(-time.Hour)
+	sm.lastActivity = start
+
+	// Two probes, both logged by kubectl
+	sm.activity.probes.Add(2)
+	sm.activity.handled.Add(2)
+	if !sm.LastActivity().Equal(start) {
+		t.Error("Expected health probes not to count as activity")
+	}
+
+	// One real client connection
+	sm.activity.handled.Add(1)
+	if !sm.LastActivity().After(start) {
+		t.Error("Expected client connection to update last activity")
+	}
+}
+
+// TestIdleTimeoutStopsAndResumes tests entering and leaving the idle state
+func TestIdleTimeoutStopsAndResumes(t *testing.T) {
+	cfg := &config.Config{
+		PortForwards: map[string]config.Service{
+			"test-service": {Target: "service/test", TargetPort: 8080, LocalPort: 8080, Namespace: "default"},
+		},
+		MonitoringInterval: 5 * time.Second,
+		IdleTimeout:        time.Minute,
+	}
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	manager := NewManager(cfg, logger)
+
+	sm := NewServiceManager("test-service", cfg.PortForwards["test-service"], logger)
+	sm.status.Status = "Running"
+	manager.services["test-service"] = sm
+
+	// Recent activity keeps services running
+	if manager.checkIdle() {
+		t.Fatal("Expected manager not to go idle with recent activity")
+	}
+
+	sm.lastActivity = time.Now().Add(-2 * time.Minute)
+	if !manager.checkIdle() {
+		t.Fatal("Expected manager to go idle after the timeout")
+	}
+	if !manager.IsIdle() {
+		t.Error("Expected IsIdle to report true")
+	}
+	if status := sm.GetStatus(); status.Status != "Idle" {
+		t.Errorf("Expected service to be Idle, got %s", status.Status)
+	}
+
+	// Prevent the resume goroutine from actually starting kubectl
+	manager.mutex.Lock()
+	manager.shuttingDown = true
+	manager.mutex.Unlock()
+
+	manager.ResumeFromIdle()
+	if manager.IsIdle() {
+		t.Error("Expected manager to leave idle state after resume")
+	}
+	if status := sm.GetStatus(); status.Status != "Reconnecting" {
+		t.Errorf("Expected service to be Reconnecting after resume, got %s", status.Status)
+	}
+}
+
+// TestIdleTimeoutLogsResumeHint tests that going idle tells how to resume
+func TestIdleTimeoutLogsResumeHint(t *testing.T) {
+	cfg := &config.Config{
+		PortForwards: map[string]config.Service{
+			"test-service": {Target: "service/test", TargetPort: 8080, LocalPort: 8080, Namespace: "default"},
+		},
+		IdleTimeout: time.Minute,
+	}
+
+	var output bytes.Buffer
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, &output)
+	manager := NewManager(cfg, logger)
+	manager.SetResumeHint("run kportforward daemon resume")
+
+	sm := NewServiceManager("test-service", cfg.PortForwards["test-service"], logger)
+	sm.status.Status = "Running"
+	sm.lastActivity = time.Now().Add(-2 * time.Minute)
+	manager.services["test-service"] = sm
+
+	if !manager.checkIdle() {
+		t.Fatal("Expected manager to go idle after the timeout")
+	}
+	if !strings.Contains(output.String(), "until resumed (run kportforward daemon resume)") {
+		t.Errorf("Expected the resume hint in the log, got %q", output.String())
+	}
+}
+
+// TestIdleTimeoutDisabled tests that a zero timeout never goes idle
+func TestIdleTimeoutDisabled(t *testing.T) {
+	manager := NewManager(&config.Config{}, utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard))
+	if manager.checkIdle() {
+		t.Error("Expected idle detection to be disabled without idleTimeout")
+	}
+}
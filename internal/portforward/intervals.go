@@ -0,0 +1,48 @@
+package portforward
+
+import "time"
+
+// connectingStatuses are the statuses checked at monitoringIntervals.connecting
+var connectingStatuses = map[string]bool{"Starting": true, "Connecting": true, "Reconnecting": true, "Waiting": true}
+
+// nextMonitoringInterval returns the time until the next monitoring tick:
+// monitoringIntervals.connecting while any service is coming up, monitoringInterval otherwise
+func (m *Manager) nextMonitoringInterval() time.Duration {
+	interval := m.config.MonitoringInterval
+	connecting := m.config.MonitoringIntervals.Connecting
+	if connecting == 0 || connecting >= interval {
+		return interval
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	for _, sm := range m.services {
+		sm.mutex.RLock()
+		status := sm.status.Status
+		sm.mutex.RUnlock()
+		if connectingStatuses[status] {
+			return connecting
+		}
+	}
+	return interval
+}
+
+// SetRunningCheckInterval sets the time between health checks of the service while it
+// is Running and its last check passed. healthCheck.interval takes precedence.
+func (sm *ServiceManager) SetRunningCheckInterval(interval time.Duration) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.runningCheckInterval = interval
+}
+
+// healthCheckInterval returns the minimum time between health checks in the current
+// status. Callers must hold the mutex.
+func (sm *ServiceManager) healthCheckInterval() time.Duration {
+	if sm.config.HealthCheck.Interval > 0 {
+		return sm.config.HealthCheck.Interval
+	}
+	if sm.status.Status == "Running" && sm.failedChecks == 0 {
+		return sm.runningCheckInterval
+	}
+	return 0
+}
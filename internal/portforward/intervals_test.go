@@ -0,0 +1,56 @@
+package portforward
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestNextMonitoringInterval tests that monitoring speeds up while a service is connecting
+func TestNextMonitoringInterval(t *testing.T) {
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	manager := NewManager(&config.Config{
+		MonitoringInterval:  5 * time.Second,
+		MonitoringIntervals: config.MonitoringIntervalsConfig{Connecting: time.Second},
+	}, logger)
+	sm := NewServiceManager("api", config.Service{}, logger)
+	manager.services["api"] = sm
+
+	sm.status.Status = "Running"
+	if interval := manager.nextMonitoringInterval(); interval != 5*time.Second {
+		t.Errorf("Expected 5s while everything runs, got %v", interval)
+	}
+	sm.status.Status = "Reconnecting"
+	if interval := manager.nextMonitoringInterval(); interval != time.Second {
+		t.Errorf("Expected 1s while a service reconnects, got %v", interval)
+	}
+
+	manager.config.MonitoringIntervals.Connecting = 0
+	if interval := manager.nextMonitoringInterval(); interval != 5*time.Second {
+		t.Errorf("Expected monitoringInterval without monitoringIntervals.connecting, got %v", interval)
+	}
+}
+
+// TestRunningCheckInterval tests that stable Running services are checked less often
+func TestRunningCheckInterval(t *testing.T) {
+	sm := newUnreachableService(t, config.HealthCheckConfig{})
+	sm.SetRunningCheckInterval(time.Hour)
+	sm.lastHealthCheckTime = time.Now()
+
+	sm.EvaluateHealth()
+	if status := sm.GetStatus(); status.Status != "Running" {
+		t.Fatalf("Expected no check before monitoringIntervals.running passed, got %s", status.Status)
+	}
+
+	// Once a check failed, the service is checked on every tick again
+	sm.lastHealthCheckTime = time.Time{}
+	sm.EvaluateHealth()
+	sm.lastHealthCheckTime = time.Now()
+	sm.EvaluateHealth()
+	if status := sm.GetStatus(); status.Status != "Failed" {
+		t.Errorf("Expected a failing service to be checked every tick, got %s", status.Status)
+	}
+}
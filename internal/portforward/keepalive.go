@@ -0,0 +1,40 @@
+package portforward
+
+import (
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// requestTimeout converts the configured kubectl request timeout into port-forward options
+func requestTimeout(kubectl config.KubectlConfig) time.Duration {
+	timeout := kubectl.EffectiveRequestTimeout()
+	if timeout == 0 {
+		return utils.NoRequestTimeout
+	}
+	return timeout
+}
+
+// SendKeepalive opens a connection through the tunnel when the service has a keepalive
+// interval and nothing else went through it recently. Load balancers in front of some
+// API servers drop idle port-forward streams, taking long-lived gRPC streams with them.
+func (sm *ServiceManager) SendKeepalive() {
+	sm.mutex.Lock()
+	interval := sm.config.Kubectl.Keepalive
+	if interval <= 0 || sm.status.Status != "Running" || time.Since(sm.lastKeepaliveTime) < interval {
+		sm.mutex.Unlock()
+		return
+	}
+	sm.lastKeepaliveTime = time.Now()
+	port := sm.status.LocalPort
+	activity := sm.activity
+	sm.mutex.Unlock()
+
+	// Counted as a probe so keepalives never keep an idle session awake
+	if utils.CheckPortConnectivityQuick(port) {
+		activity.probes.Add(1)
+	} else {
+		sm.logger.Debug("Keepalive connection failed for %s on port %d", sm.name, port)
+	}
+}
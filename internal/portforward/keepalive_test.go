@@ -0,0 +1,51 @@
+package portforward
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestSendKeepalive tests that keepalives respect the interval and count as probes
+func TestSendKeepalive(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	sm := NewServiceManager("test-service", config.Service{
+		LocalPort: port,
+		Kubectl:   config.KubectlConfig{Keepalive: time.Hour},
+	}, logger)
+	sm.status.Status = "Running"
+	sm.status.LocalPort = port
+
+	sm.SendKeepalive()
+	if probes := sm.activity.probes.Load(); probes != 1 {
+		t.Fatalf("Expected first keepalive to connect once, got %d probes", probes)
+	}
+
+	sm.SendKeepalive()
+	if probes := sm.activity.probes.Load(); probes != 1 {
+		t.Errorf("Expected no keepalive before the interval elapsed, got %d probes", probes)
+	}
+}
+
+// TestRequestTimeout tests the mapping from config to port-forward options
+func TestRequestTimeout(t *testing.T) {
+	if timeout := requestTimeout(config.KubectlConfig{}); timeout != config.DefaultRequestTimeout {
+		t.Errorf("Expected default timeout, got %v", timeout)
+	}
+
+	zero := time.Duration(0)
+	if timeout := requestTimeout(config.KubectlConfig{RequestTimeout: &zero}); timeout != utils.NoRequestTimeout {
+		t.Errorf("Expected 0 to disable the timeout, got %v", timeout)
+	}
+}
@@ -3,17 +3,19 @@ package portforward
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/victorkazakov/kportforward/internal/common"
 	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/events"
 	"github.com/victorkazakov/kportforward/internal/utils"
 )
 
@@ -37,6 +39,8 @@ type Manager struct {
 	mutex             sync.RWMutex
 	kubernetesContext string
 	shuttingDown      bool
+	idle              bool   // All services stopped by the idle timeout
+	resumeHint        string // How to resume from idle, logged when going idle
 
 	// UI Handlers
 	grpcUIHandler    UIHandler
@@ -44,8 +48,15 @@ type Manager struct {
 
 	// Monitoring
 	monitoringTicker *time.Ticker
-	statusChan       chan map[string]config.ServiceStatus
-	contextChan      chan string
+	statusBus        *events.Bus[map[string]config.ServiceStatus]
+	contextBus       *events.Bus[string]
+	statusBuffer     int // 0 = DefaultStatusBuffer
+
+	// Most recently published status, served without re-running health checks.
+	// publishMutex keeps it in step with what was published last.
+	lastStatus      map[string]config.ServiceStatus
+	lastStatusMutex sync.RWMutex
+	publishMutex    sync.Mutex
 
 	// Global access state
 	globalAccessHealthy   bool
@@ -53,6 +64,47 @@ type Manager struct {
 	globalAccessFailCount int
 	globalAccessCooldown  time.Time
 	globalAccessMutex     sync.RWMutex
+
+	// Restart storm state, see storm.go
+	stormMutex        sync.Mutex
+	stormActive       bool
+	stormResuming     bool
+	stormStarted      time.Time
+	recentFailures    []time.Time
+	lastServiceStatus map[string]string
+
+	// Local port offset from --port-offset, overriding portOffsets in the config
+	portOffsetOverride *int
+
+	// Last hook event per service, see hooks.go
+	hookMutex   sync.Mutex
+	hookStates  map[string]string
+	hookRunning map[string]bool // Whether the service was up at the last check, for onRunning
+
+	// Outages per service, see downtime.go
+	downtimeMutex sync.Mutex
+	downtime      map[string]config.DowntimeStats
+	downtimePath  string
+
+	// Recent status changes per service, see timeline.go
+	timelineMutex sync.Mutex
+	timelines     map[string][]config.StatusChange
+
+	// Per-service log files from --log-dir (nil = services log to the main log only)
+	serviceLogs *utils.ServiceLogs
+
+	// Touched or rewritten every monitoring tick for external watchdogs, see heartbeat.go
+	heartbeatPath    string
+	heartbeatFailing bool
+
+	// Services expanded from selector entries by entry, see selectors.go
+	expanded           map[string]map[string]config.Service
+	selectorRefreshing atomic.Bool
+
+	// Injected global access failure, see chaos.go
+	chaosMutex   sync.Mutex
+	chaosFailure error
+	chaosUntil   time.Time
 }
 
 func (m *Manager) isShuttingDown() bool {
@@ -82,13 +134,13 @@ func NewManager(cfg *config.Config, logger *utils.Logger) *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Manager{
-		services:    make(map[string]*ServiceManager),
-		config:      cfg,
-		logger:      logger,
-		ctx:         ctx,
-		cancel:      cancel,
-		statusChan:  make(chan map[string]config.ServiceStatus, 1),
-		contextChan: make(chan string, 1),
+		services:   make(map[string]*ServiceManager),
+		config:     cfg,
+		logger:     logger,
+		ctx:        ctx,
+		cancel:     cancel,
+		statusBus:  events.NewBus[map[string]config.ServiceStatus](),
+		contextBus: events.NewBus[string](),
 
 		// Initialize global access state
 		globalAccessHealthy:   true, // Start optimistically
@@ -129,9 +181,25 @@ func (m *Manager) Start() error {
 		return fmt.Errorf("failed to get Kubernetes context: %w", err)
 	}
 
+	// Adapt kubectl arguments to the installed version before any port-forward starts
+	m.detectKubectlVersion()
+
 	// Create service managers
+	offset := m.portOffset(m.kubernetesContext)
+	if offset != 0 {
+		m.logger.Info("Offsetting local ports by %+d for context %s", offset, m.kubernetesContext)
+	}
 	for name, serviceConfig := range m.config.PortForwards {
-		sm := NewServiceManager(name, serviceConfig, m.logger)
+		if serviceConfig.HasSelector() {
+			// Expanded into services by refreshSelectors once started
+			continue
+		}
+		sm := NewServiceManager(name, serviceConfig, m.serviceLogger(name))
+		sm.SetPortOffset(offset)
+		sm.SetRunningCheckInterval(m.config.MonitoringIntervals.Running)
+		sm.SetBackend(m.config.PortForwardBackend)
+		sm.SetTunnelEnded(m.restartEndedTunnel)
+		m.setDependencies(sm, m.config.PortForwards)
 		m.services[name] = sm
 	}
 
@@ -164,13 +232,15 @@ func (m *Manager) Start() error {
 		}
 	}
 
-	// Start monitoring
+	// Start monitoring; the first heartbeat tells watchdogs the loop is starting
+	m.writeHeartbeat()
 	m.startMonitoring()
 
 	// Send immediate status update to populate TUI table
 	go func() {
 		// Send initial status immediately
 		m.sendInitialStatus()
+		m.refreshSelectors()
 
 		// Give services a moment to start, then trigger UI handler check
 		time.Sleep(2 * time.Second)
@@ -232,19 +302,48 @@ func (m *Manager) Stop() error {
 
 	m.cancel()
 	// close(m.statusChan)
+	m.saveDowntime(time.Now())
 
 	m.logger.Info("Stopped all port-forward services")
 	return nil
 }
 
-// GetStatusChannel returns a channel that receives status updates
+// tuiSubscriber is the event bus subscription used by the terminal UI
+const tuiSubscriber = "tui"
+
+// DefaultStatusBuffer is the number of status updates buffered for the TUI, enough for
+// the headless reporter to see every status change through a slow write
+const DefaultStatusBuffer = 16
+
+// SetStatusBuffer sets the number of status updates buffered for the TUI; when it falls
+// further behind the oldest are dropped. It must be called before GetStatusChannel.
+func (m *Manager) SetStatusBuffer(buffer int) {
+	m.statusBuffer = buffer
+}
+
+// GetStatusChannel returns the TUI's channel of status updates
 func (m *Manager) GetStatusChannel() <-chan map[string]config.ServiceStatus {
-	return m.statusChan
+	buffer := m.statusBuffer
+	if buffer == 0 {
+		buffer = DefaultStatusBuffer
+	}
+	return m.SubscribeStatus(tuiSubscriber, buffer)
 }
 
-// GetContextChannel returns a channel that receives context updates
+// GetContextChannel returns the TUI's channel of context updates
 func (m *Manager) GetContextChannel() <-chan string {
-	return m.contextChan
+	return m.SubscribeContext(tuiSubscriber, 1)
+}
+
+// SubscribeStatus registers a named subscriber for status updates. When the
+// subscriber falls behind, older updates are dropped in favour of newer ones.
+func (m *Manager) SubscribeStatus(name string, buffer int) <-chan map[string]config.ServiceStatus {
+	return m.statusBus.Subscribe(name, buffer)
+}
+
+// SubscribeContext registers a named subscriber for Kubernetes context updates
+func (m *Manager) SubscribeContext(name string, buffer int) <-chan string {
+	return m.contextBus.Subscribe(name, buffer)
 }
 
 // GetCurrentStatus returns the current status of all services
@@ -272,6 +371,42 @@ func (m *Manager) RestartService(name string) error {
 	return sm.Restart()
 }
 
+// SetPortOffset offsets all local ports by offset regardless of the kubectl context.
+// It must be called before Start.
+func (m *Manager) SetPortOffset(offset int) {
+	m.portOffsetOverride = &offset
+}
+
+// SetServiceLogs gives every service its own log file in addition to the main log.
+// It must be called before Start.
+func (m *Manager) SetServiceLogs(logs *utils.ServiceLogs) {
+	m.serviceLogs = logs
+}
+
+// ServiceLogPath returns the log file of a service, or "" if services have none
+func (m *Manager) ServiceLogPath(name string) string {
+	if m.serviceLogs == nil {
+		return ""
+	}
+	return m.serviceLogs.Path(name)
+}
+
+// serviceLogger returns the logger for a service
+func (m *Manager) serviceLogger(name string) *utils.Logger {
+	if m.serviceLogs == nil {
+		return m.logger.ForService(name)
+	}
+	return m.serviceLogs.Logger(name)
+}
+
+// portOffset returns the local port offset for kubeContext
+func (m *Manager) portOffset(kubeContext string) int {
+	if m.portOffsetOverride != nil {
+		return *m.portOffsetOverride
+	}
+	return m.config.PortOffset(kubeContext)
+}
+
 // GetKubernetesContext returns the current Kubernetes context
 func (m *Manager) GetKubernetesContext() string {
 	m.mutex.RLock()
@@ -281,7 +416,7 @@ func (m *Manager) GetKubernetesContext() string {
 
 // startMonitoring begins the monitoring loop for all services
 func (m *Manager) startMonitoring() {
-	m.monitoringTicker = time.NewTicker(m.config.MonitoringInterval)
+	m.monitoringTicker = time.NewTicker(m.nextMonitoringInterval())
 
 	go func() {
 		defer m.monitoringTicker.Stop()
@@ -293,6 +428,8 @@ func (m *Manager) startMonitoring() {
 			case <-m.monitoringTicker.C:
 				m.monitorServices()
 				m.checkKubernetesContext()
+				m.writeHeartbeat()
+				m.monitoringTicker.Reset(m.nextMonitoringInterval())
 			}
 		}
 	}()
@@ -304,6 +441,13 @@ func (m *Manager) monitorServices() {
 	if m.isShuttingDown() {
 		return
 	}
+
+	// While idle nothing is probed, restarted or checked against the cluster
+	if m.IsIdle() {
+		m.sendIdleStatus()
+		return
+	}
+
 	// Check global access first - if this fails, suspend all services
 	if !m.checkAndUpdateGlobalAccess() {
 		m.logger.Warn("Global kubectl access failed, suspending all service operations")
@@ -316,6 +460,10 @@ func (m *Manager) monitorServices() {
 		m.logger.Info("Global access recovered, resuming service operations")
 	}
 
+	m.applySchedules()
+	m.endRestartStormIfRecovered()
+	go m.refreshSelectors()
+
 	m.mutex.RLock()
 	services := make(map[string]*ServiceManager, len(m.services))
 	for name, sm := range m.services {
@@ -323,10 +471,23 @@ func (m *Manager) monitorServices() {
 	}
 	m.mutex.RUnlock()
 
+	// Probe all services concurrently so one slow dial does not delay the rest
+	var healthChecks sync.WaitGroup
+	for _, sm := range services {
+		healthChecks.Add(1)
+		go func(serviceManager *ServiceManager) {
+			defer healthChecks.Done()
+			serviceManager.EvaluateHealth()
+			serviceManager.SendKeepalive()
+			serviceManager.TrackPod()
+			serviceManager.WatchEndpoints()
+		}(sm)
+	}
+	healthChecks.Wait()
+
 	statusMap := make(map[string]config.ServiceStatus)
 
 	for name, sm := range services {
-		// Get status (this runs health checks internally)
 		status := sm.GetStatus()
 
 		// If status is Running but still has a status message about connectivity issues,
@@ -345,9 +506,11 @@ func (m *Manager) monitorServices() {
 		// Enhance status with global information
 		status.GlobalStatus = m.getGlobalStatusString()
 		statusMap[name] = status
+		m.recordServiceStatus(name, status.Status)
+		m.checkHooks(sm, status)
 
-		// Check if service needs to be restarted
-		if status.Status == "Failed" && !status.InCooldown {
+		// Check if service needs to be restarted (paused while many services are failing at once)
+		if status.Status == "Failed" && !status.InCooldown && !m.inRestartStorm() {
 			m.logger.Info("Restarting failed service: %s", name)
 			go func(serviceName string, serviceManager *ServiceManager) {
 				if m.isShuttingDown() {
@@ -358,17 +521,76 @@ func (m *Manager) monitorServices() {
 				}
 			}(name, sm)
 		}
+
+		// Services with strictPort retry their own port until it is free, one retry at a time
+		if status.Status == "PortConflict" {
+			go func(serviceManager *ServiceManager) {
+				if !m.isShuttingDown() {
+					serviceManager.RetryPort()
+				}
+			}(sm)
+		}
+	}
+
+	if m.checkIdle() {
+		m.sendIdleStatus()
+		return
 	}
 
 	// Monitor UI handlers
 	m.monitorUIHandlers(statusMap)
 
-	// Send status update (non-blocking)
-	select {
-	case m.statusChan <- statusMap:
-	default:
-		// Channel is full, skip this update
+	m.publishStatus(statusMap)
+	m.startWaitingServices()
+}
+
+// restartEndedTunnel restarts a service whose port-forward ended on its own right away,
+// instead of on the next monitoring tick
+func (m *Manager) restartEndedTunnel(sm *ServiceManager) {
+	if m.isShuttingDown() || m.IsIdle() {
+		return
+	}
+	status := sm.GetStatus()
+	if status.Status != "Failed" {
+		return
+	}
+	m.recordServiceStatus(sm.name, status.Status)
+	m.checkHooks(sm, status)
+	if status.InCooldown || m.inRestartStorm() {
+		return
+	}
+
+	m.logger.Info("Restarting failed service: %s", sm.name)
+	if err := sm.Restart(); err != nil {
+		m.logger.Error("Failed to restart service %s: %v", sm.name, err)
+	}
+}
+
+// detectKubectlVersion logs known kubectl version problems and limits port-forward
+// arguments to what the installed kubectl supports
+func (m *Manager) detectKubectlVersion() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	version, err := utils.DetectKubectlVersion(ctx)
+	if err != nil {
+		m.logger.Debug("Could not detect kubectl version, assuming a recent one: %v", err)
+		return
+	}
+
+	m.logger.Info("kubectl client %s, server %s", version.Client, version.Server)
+	for _, warning := range version.Warnings() {
+		m.logger.Warn("%s", warning)
+	}
+	capabilities := version.Capabilities()
+	if !capabilities.WebSocketStreaming {
+		for name, service := range m.config.PortForwards {
+			if service.Kubectl.Streaming != "" {
+				m.logger.Warn("kubectl %s cannot select a streaming protocol; ignoring kubectl.streaming for %s", version.Client, name)
+			}
+		}
 	}
+	utils.SetKubectlCapabilities(capabilities)
 }
 
 // monitorUIHandlers monitors UI handlers and manages their lifecycle
@@ -376,16 +598,17 @@ func (m *Manager) monitorUIHandlers(statusMap map[string]config.ServiceStatus) {
 	m.mutex.RLock()
 	grpcHandler := m.grpcUIHandler
 	swaggerHandler := m.swaggerUIHandler
+	configs := m.serviceConfigs() // Including the services expanded from selectors
 	m.mutex.RUnlock()
 
 	// Monitor gRPC UI handler - check both nil interface and nil concrete value
 	if grpcHandler != nil && !isNilInterface(grpcHandler) && grpcHandler.IsEnabled() {
-		grpcHandler.MonitorServices(statusMap, m.config.PortForwards)
+		grpcHandler.MonitorServices(statusMap, configs)
 	}
 
 	// Monitor Swagger UI handler - check both nil interface and nil concrete value
 	if swaggerHandler != nil && !isNilInterface(swaggerHandler) && swaggerHandler.IsEnabled() {
-		swaggerHandler.MonitorServices(statusMap, m.config.PortForwards)
+		swaggerHandler.MonitorServices(statusMap, configs)
 	}
 }
 
@@ -420,12 +643,7 @@ func (m *Manager) checkKubernetesContext() {
 	m.logger.Debug("Checking Kubernetes context - Current: %s, New: %s", currentContext, newContext)
 
 	// Always update context in TUI even if it hasn't changed (to ensure it's displayed)
-	select {
-	case m.contextChan <- newContext:
-		m.logger.Debug("Sent context update to TUI: %s", newContext)
-	default:
-		// Channel is full, skip this update
-	}
+	m.contextBus.Publish(newContext)
 
 	if newContext != currentContext && newContext != "N/A" {
 		m.logger.Info("Kubernetes context changed from %s to %s, restarting all services",
@@ -452,6 +670,11 @@ func (m *Manager) restartAllServices() {
 	m.globalAccessLastCheck = time.Time{} // Force immediate check
 	m.globalAccessMutex.Unlock()
 
+	// A context switch is deliberate user activity, so it also ends an idle period
+	m.mutex.Lock()
+	m.idle = false
+	m.mutex.Unlock()
+
 	m.mutex.RLock()
 	services := make([]*ServiceManager, 0, len(m.services))
 	for _, sm := range m.services {
@@ -494,6 +717,16 @@ func (m *Manager) restartAllServices() {
 	// Small delay to allow processes to fully terminate
 	time.Sleep(500 * time.Millisecond)
 
+	// The new context may use different local ports
+	m.mutex.RLock()
+	newContext := m.kubernetesContext
+	m.mutex.RUnlock()
+	offset := m.portOffset(newContext)
+	m.logger.Info("Using local port offset %+d for context %s", offset, newContext)
+	for _, sm := range services {
+		sm.SetPortOffset(offset)
+	}
+
 	// STEP 2: Check if new context is accessible
 	if !m.checkAndUpdateGlobalAccess() {
 		m.logger.Warn("New context has authentication issues - services will remain suspended")
@@ -507,24 +740,16 @@ func (m *Manager) restartAllServices() {
 			sm.status.StartTime = time.Time{}
 			sm.mutex.Unlock()
 		}
-		// Immediately suspend to prevent any monitoring from trying to restart
+		// Immediately suspend to prevent any monitoring from trying to restart; this also
+		// publishes the suspended state
 		m.logger.Info("Calling suspendAllServices() to ensure services stay suspended")
 		m.suspendAllServices()
-
-		// Send immediate status update to reflect suspended state
-		m.sendInitialStatus()
 		return
 	}
 
 	// STEP 3: Start all services fresh with new context (only if auth passed)
 	m.logger.Info("New context is accessible - starting all services fresh")
-	for _, sm := range services {
-		if err := sm.Start(); err != nil {
-			m.logger.Error("Failed to start service %s in new context: %v", sm.name, err)
-		}
-		// Small delay between starts to avoid overwhelming the system
-		time.Sleep(100 * time.Millisecond)
-	}
+	m.startStaggered(services)
 
 	// Give services a moment to establish, then trigger UI handler check
 	time.Sleep(2 * time.Second)
@@ -551,13 +776,53 @@ func (m *Manager) sendInitialStatus() {
 		statusMap[name] = sm.GetStatus()
 	}
 
-	// Send status update (non-blocking)
-	select {
-	case m.statusChan <- statusMap:
-		m.logger.Debug("Sent initial service status to TUI")
-	default:
-		// Channel is full, skip this update
+	m.publishStatus(statusMap)
+	m.logger.Debug("Published initial service status")
+}
+
+// publishStatus records the status map, along with the downtime and timeline of each
+// service, and publishes it to all subscribers
+func (m *Manager) publishStatus(statusMap map[string]config.ServiceStatus) {
+	m.publishMutex.Lock()
+	defer m.publishMutex.Unlock()
+
+	now := time.Now()
+	for name, status := range statusMap {
+		status.Downtime = m.recordDowntime(name, status.Status, now)
+		statusMap[name] = status
+		m.recordStatusChange(name, status, now)
 	}
+
+	m.lastStatusMutex.Lock()
+	m.lastStatus = statusMap
+	m.lastStatusMutex.Unlock()
+
+	m.statusBus.Publish(statusMap)
+}
+
+// GetLastStatus returns the most recently published status without probing services
+func (m *Manager) GetLastStatus() map[string]config.ServiceStatus {
+	m.lastStatusMutex.RLock()
+	defer m.lastStatusMutex.RUnlock()
+
+	status := make(map[string]config.ServiceStatus, len(m.lastStatus))
+	for name, serviceStatus := range m.lastStatus {
+		status[name] = serviceStatus
+	}
+	return status
+}
+
+// QueueDepths reports buffering and drop counters for every event subscriber,
+// keyed by "<topic>/<subscriber>"
+func (m *Manager) QueueDepths() map[string]events.SubscriberStats {
+	depths := make(map[string]events.SubscriberStats)
+	for name, stats := range m.statusBus.Stats() {
+		depths["status/"+name] = stats
+	}
+	for name, stats := range m.contextBus.Stats() {
+		depths["context/"+name] = stats
+	}
+	return depths
 }
 
 // updateKubernetesContext gets and stores the current Kubernetes context
@@ -614,6 +879,37 @@ func (m *Manager) GetGRPCUIURL(serviceName string) string {
 	return ""
 }
 
+// uiHandlerLogs is implemented by UI handlers that write a log file per service
+type uiHandlerLogs interface {
+	GetLogPath(serviceName string) string
+}
+
+// GetGRPCUILogPath returns the log file of a service's gRPC UI, or "" if it has none
+func (m *Manager) GetGRPCUILogPath(serviceName string) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.grpcUIHandler == nil || isNilInterface(m.grpcUIHandler) || !m.grpcUIHandler.IsEnabled() {
+		return ""
+	}
+	if logs, ok := m.grpcUIHandler.(uiHandlerLogs); ok {
+		return logs.GetLogPath(serviceName)
+	}
+	return ""
+}
+
+// ServiceOutput returns the last lines kubectl wrote to stdout and stderr for a
+// service, oldest first
+func (m *Manager) ServiceOutput(name string) []string {
+	m.mutex.RLock()
+	sm, exists := m.services[name]
+	m.mutex.RUnlock()
+	if !exists {
+		return nil
+	}
+	return sm.Output()
+}
+
 // GetSwaggerUIURL returns the Swagger UI URL for a service
 func (m *Manager) GetSwaggerUIURL(serviceName string) string {
 	m.mutex.RLock()
@@ -638,6 +934,10 @@ func (m *Manager) UpdateServiceStatusMessage(serviceName, message string) {
 
 // checkGlobalAccess performs a lightweight kubectl connectivity test
 func (m *Manager) checkGlobalAccess() error {
+	if err := m.simulatedAccessFailure(); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
@@ -662,12 +962,12 @@ func (m *Manager) checkGlobalAccess() error {
 
 		// Detect specific auth failures
 		if isAuthError(fmt.Errorf("%s", combinedError)) {
-			return fmt.Errorf("authentication failed: %s", errorOutput)
+			return common.WithKind(common.ErrAuth, fmt.Errorf("authentication failed: %s", errorOutput))
 		}
 
 		// Detect network failures
 		if isNetworkError(fmt.Errorf("%s", combinedError)) {
-			return fmt.Errorf("network connectivity failed: %s", errorOutput)
+			return common.WithKind(common.ErrNetwork, fmt.Errorf("network connectivity failed: %s", errorOutput))
 		}
 
 		return fmt.Errorf("kubectl access failed: %s", errorOutput)
@@ -679,44 +979,12 @@ func (m *Manager) checkGlobalAccess() error {
 
 // isAuthError detects authentication-related errors
 func isAuthError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := strings.ToLower(err.Error())
-	return strings.Contains(errStr, "unauthorized") ||
-		strings.Contains(errStr, "authentication") ||
-		strings.Contains(errStr, "token") ||
-		strings.Contains(errStr, "credential") ||
-		strings.Contains(errStr, "forbidden") ||
-		strings.Contains(errStr, "invalid user") ||
-		strings.Contains(errStr, "access denied") ||
-		strings.Contains(errStr, "unable to load aws credentials") ||
-		strings.Contains(errStr, "expired") ||
-		strings.Contains(errStr, "sso") ||
-		strings.Contains(errStr, "login") ||
-		strings.Contains(errStr, "auth") ||
-		strings.Contains(errStr, "permission denied") ||
-		strings.Contains(errStr, "invalid_grant") ||
-		strings.Contains(errStr, "session") ||
-		strings.Contains(errStr, "getting credentials") ||
-		strings.Contains(errStr, "refresh failed") ||
-		strings.Contains(errStr, "executable aws failed") ||
-		strings.Contains(errStr, "unable to connect to the server")
+	return common.IsAuth(err)
 }
 
 // isNetworkError detects network-related errors
 func isNetworkError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := strings.ToLower(err.Error())
-	return strings.Contains(errStr, "connection refused") ||
-		strings.Contains(errStr, "timeout") ||
-		strings.Contains(errStr, "network") ||
-		strings.Contains(errStr, "no route to host") ||
-		strings.Contains(errStr, "connection timed out") ||
-		strings.Contains(errStr, "dial tcp") ||
-		strings.Contains(errStr, "i/o timeout")
+	return common.IsNetwork(err)
 }
 
 // GetGlobalAccessStatus returns the current global access status
@@ -768,7 +1036,7 @@ func (m *Manager) checkAndUpdateGlobalAccess() bool {
 		var cooldowns []time.Duration
 		var errorType string
 
-		if strings.Contains(err.Error(), "authentication") {
+		if errors.Is(err, common.ErrAuth) {
 			// Long cooldown for auth failures (5, 10, 30 minutes)
 			cooldowns = []time.Duration{5 * time.Minute, 10 * time.Minute, 30 * time.Minute}
 			errorType = "authentication"
@@ -804,7 +1072,8 @@ func (m *Manager) checkAndUpdateGlobalAccess() bool {
 	return true
 }
 
-// suspendAllServices marks all services as suspended due to global access failure
+// suspendAllServices marks all services as suspended due to global access failure and
+// publishes their status
 func (m *Manager) suspendAllServices() {
 	m.mutex.RLock()
 	services := make(map[string]*ServiceManager, len(m.services))
@@ -813,6 +1082,8 @@ func (m *Manager) suspendAllServices() {
 	}
 	m.mutex.RUnlock()
 
+	statusMap := make(map[string]config.ServiceStatus, len(services))
+
 	for name, sm := range services {
 		sm.mutex.Lock()
 		// Only suspend services that are currently running or in other active states
@@ -822,12 +1093,12 @@ func (m *Manager) suspendAllServices() {
 			m.logger.Debug("Suspending service %s (was %s)", name, sm.status.Status)
 
 			// Actually stop the service process, don't just change status
-			if sm.cmd != nil && sm.cmd.Process != nil {
-				m.logger.Debug("Killing kubectl process for suspended service %s (PID %d)", name, sm.cmd.Process.Pid)
-				if err := utils.KillProcess(sm.cmd.Process.Pid); err != nil {
-					m.logger.Warn("Failed to kill process for suspended service %s: %v", name, err)
+			if sm.tunnel != nil {
+				m.logger.Debug("Stopping port-forward for suspended service %s (PID %d)", name, sm.tunnel.PID())
+				if err := sm.tunnel.Stop(); err != nil {
+					m.logger.Warn("Failed to stop port-forward for suspended service %s: %v", name, err)
 				}
-				sm.cmd = nil
+				sm.tunnel = nil
 			}
 
 			sm.status.Status = "Suspended"
@@ -836,7 +1107,16 @@ func (m *Manager) suspendAllServices() {
 			sm.status.StartTime = time.Time{}
 		}
 		sm.mutex.Unlock()
+
+		status := sm.GetStatus()
+		status.GlobalStatus = m.getGlobalStatusString()
+		statusMap[name] = status
+		m.checkHooks(sm, status)
 	}
+
+	// Published on every failed check, so the TUI and API never show services running
+	// that were suspended
+	m.publishStatus(statusMap)
 }
 
 // resumeServicesIfNeeded resumes suspended services when global access recovers
@@ -886,6 +1166,9 @@ func (m *Manager) getGlobalStatusString() string {
 	defer m.globalAccessMutex.RUnlock()
 
 	if m.globalAccessHealthy {
+		if m.inRestartStorm() {
+			return "degraded"
+		}
 		return "healthy"
 	}
 
@@ -24,6 +24,7 @@ func TestIsAuthError(t *testing.T) {
 		{"forbidden", errors.New("forbidden access"), true},
 		{"invalid user", errors.New("invalid user"), true},
 		{"access denied", errors.New("access denied"), true},
+		{"privileged port", errors.New("unable to listen on port 80: listen tcp4 127.0.0.1:80: bind: permission denied"), false},
 		{"network error", errors.New("connection refused"), false},
 		{"timeout error", errors.New("request timeout"), false},
 		{"generic error", errors.New("generic failure"), false},
@@ -132,6 +133,7 @@ func TestServiceSuspensionLogic(t *testing.T) {
 	manager.services["test-service-2"] = sm2
 
 	// Test suspension
+	statusChan := manager.GetStatusChannel()
 	manager.suspendAllServices()
 
 	// Check that services are suspended
@@ -149,6 +151,19 @@ func TestServiceSuspensionLogic(t *testing.T) {
 	if status1.StatusMessage != "Suspended due to global kubectl access failure" {
 		t.Errorf("Expected suspension message, got: %s", status1.StatusMessage)
 	}
+
+	// The suspended state is published right away rather than once access is back
+	select {
+	case published := <-statusChan:
+		if published["test-service-1"].Status != "Suspended" || published["test-service-2"].Status != "Suspended" {
+			t.Errorf("Expected the suspended services to be published, got %+v", published)
+		}
+	default:
+		t.Error("Expected the suspended state to be published")
+	}
+	if manager.GetLastStatus()["test-service-1"].Status != "Suspended" {
+		t.Error("Expected the API's last status to show the suspended service")
+	}
 }
 
 // TestGlobalStatusString tests the global status string generation
@@ -272,4 +287,9 @@ func TestServiceManagerAuthError(t *testing.T) {
 	if sm.isAuthError(networkErr) {
 		t.Error("Expected network error to not be detected as auth error")
 	}
+
+	bindErr := errors.New("listen tcp4 127.0.0.1:80: bind: permission denied")
+	if sm.isAuthError(bindErr) {
+		t.Error("Expected port bind error to not be detected as auth error")
+	}
 }
@@ -93,8 +93,8 @@ func TestNewManager(t *testing.T) {
 		t.Error("Manager services map should be initialized")
 	}
 
-	if manager.statusChan == nil {
-		t.Error("Manager status channel should be initialized")
+	if manager.statusBus == nil {
+		t.Error("Manager status bus should be initialized")
 	}
 }
 
@@ -282,3 +282,37 @@ func TestRestartDeduplication(t *testing.T) {
 		t.Errorf("Expected restart count 0 (restart was skipped), got %d", count)
 	}
 }
+
+func TestPortOffset(t *testing.T) {
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	cfg := &config.Config{
+		PortForwards: map[string]config.Service{},
+		PortOffsets:  map[string]int{"staging": 1000},
+	}
+
+	manager := NewManager(cfg, logger)
+	if offset := manager.portOffset("staging"); offset != 1000 {
+		t.Errorf("Expected offset from config, got %d", offset)
+	}
+	if offset := manager.portOffset("production"); offset != 0 {
+		t.Errorf("Expected no offset for unlisted context, got %d", offset)
+	}
+	manager.SetPortOffset(0)
+	if offset := manager.portOffset("staging"); offset != 0 {
+		t.Errorf("Expected --port-offset to override the config, got %d", offset)
+	}
+
+	sm := NewServiceManager("offset-test", config.Service{LocalPort: 18999}, logger)
+	sm.SetPortOffset(1000)
+	if port := sm.GetStatus().LocalPort; port != 19999 {
+		t.Errorf("Expected offset port in status, got %d", port)
+	}
+	if port, err := sm.resolvePort(); err != nil || port < 19999 {
+		t.Errorf("Expected port resolution to start at the offset port, got %d (%v)", port, err)
+	}
+
+	sm.SetPortOffset(60000)
+	if _, err := sm.resolvePort(); err == nil {
+		t.Error("Expected error for an offset port out of range")
+	}
+}
@@ -0,0 +1,139 @@
+package portforward
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// Performance envelope for one monitoring tick with budgetServiceCount services.
+// The ceilings are several times what a laptop needs so they only trip on real
+// regressions, such as serialized probes or a goroutine leak per tick.
+const (
+	budgetServiceCount     = 200
+	budgetTickDuration     = 500 * time.Millisecond
+	budgetAllocsPerTick    = 40000
+	budgetLeakedGoroutines = 10
+)
+
+// newFakeBackendManager returns a manager whose services all look like healthy,
+// running port-forwards backed by a local listener, so the monitoring loop runs
+// its full path without kubectl or a cluster
+func newFakeBackendManager(tb testing.TB, services int) *Manager {
+	tb.Helper()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		tb.Fatalf("Failed to start fake backend: %v", err)
+	}
+	tb.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	// The test process stands in for every kubectl process
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		tb.Fatalf("Failed to find own process: %v", err)
+	}
+
+	cfg := &config.Config{
+		PortForwards:       make(map[string]config.Service, services),
+		MonitoringInterval: time.Second,
+	}
+	for i := 0; i < services; i++ {
+		cfg.PortForwards[fmt.Sprintf("service-%d", i)] = config.Service{
+			Target:     fmt.Sprintf("service/service-%d", i),
+			TargetPort: 8080,
+			LocalPort:  port,
+			Namespace:  "default",
+			Type:       []string{"web", "rest", "rpc"}[i%3],
+		}
+	}
+
+	manager := NewManager(cfg, utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard))
+	tb.Cleanup(func() { manager.cancel() })
+
+	// Skip the kubectl global access check for the duration of the run
+	manager.globalAccessHealthy = true
+	manager.globalAccessCooldown = time.Now().Add(time.Hour)
+
+	for name, service := range cfg.PortForwards {
+		sm := NewServiceManager(name, service, manager.logger)
+		manager.services[name] = sm
+		sm.tunnel = &kubectlTunnel{cmd: &exec.Cmd{Process: process}}
+		sm.status.Status = "Running"
+		sm.status.LocalPort = port
+		sm.status.StartTime = time.Now().Add(-time.Minute)
+	}
+
+	return manager
+}
+
+// BenchmarkMonitoringTick measures one monitoring tick over budgetServiceCount services
+func BenchmarkMonitoringTick(b *testing.B) {
+	manager := newFakeBackendManager(b, budgetServiceCount)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		manager.monitorServices()
+	}
+}
+
+// TestMonitoringTickBudget enforces the performance envelope of the monitoring loop
+func TestMonitoringTickBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Performance budget is not checked in short mode")
+	}
+
+	manager := newFakeBackendManager(t, budgetServiceCount)
+
+	// Warm up connection and allocation paths before measuring
+	manager.monitorServices()
+	goroutinesBefore := runtime.NumGoroutine()
+
+	const ticks = 5
+	started := time.Now()
+	allocs := testing.AllocsPerRun(ticks, manager.monitorServices)
+	// AllocsPerRun performs one extra warm-up run
+	perTick := time.Since(started) / (ticks + 1)
+
+	if perTick > budgetTickDuration {
+		t.Errorf("Monitoring tick took %v for %d services, budget is %v", perTick, budgetServiceCount, budgetTickDuration)
+	}
+	if allocs > budgetAllocsPerTick {
+		t.Errorf("Monitoring tick made %.0f allocations for %d services, budget is %d", allocs, budgetServiceCount, budgetAllocsPerTick)
+	}
+
+	// Probe goroutines must have finished once a tick returns
+	time.Sleep(100 * time.Millisecond)
+	if leaked := runtime.NumGoroutine() - goroutinesBefore; leaked > budgetLeakedGoroutines {
+		t.Errorf("%d goroutines left behind after %d ticks, budget is %d", leaked, ticks, budgetLeakedGoroutines)
+	}
+
+	statuses := manager.GetLastStatus()
+	if len(statuses) != budgetServiceCount {
+		t.Fatalf("Expected status for %d services, got %d", budgetServiceCount, len(statuses))
+	}
+	for name, status := range statuses {
+		if status.Status != "Running" {
+			t.Fatalf("Expected fake backend to keep %s Running, got %s (%s)", name, status.Status, status.StatusMessage)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package portforward
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// podTrackingInterval is how often the pod of a service with trackPod is checked
+const podTrackingInterval = 10 * time.Second
+
+// resolvePodTarget and checkPodServing are replaced in tests to avoid calling kubectl
+var (
+	resolvePodTarget = utils.ResolvePodTarget
+	checkPodServing  = utils.CheckPodServing
+)
+
+// resolveTrackedPod picks the pod a service with trackPod forwards to. It runs kubectl,
+// so it is called before Start takes the lock. ok is false when the service does not
+// track a pod or none could be resolved, in which case kubectl picks one as usual.
+func (sm *ServiceManager) resolveTrackedPod() (utils.PodTarget, bool) {
+	if !sm.config.TrackPod || strings.HasPrefix(sm.config.Target, "pod/") {
+		return utils.PodTarget{}, false
+	}
+	sm.mutex.RLock()
+	skip := sm.isInCooldown() || !sm.config.IsScheduledAt(time.Now())
+	sm.mutex.RUnlock()
+	if skip {
+		return utils.PodTarget{}, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	podTarget, err := resolvePodTarget(ctx, sm.config.Kubectl.Context, sm.config.Namespace, sm.config.Target, sm.config.TargetPort)
+	if err != nil {
+		sm.logger.Warn("Could not resolve a ready pod for %s, letting kubectl pick one: %v", sm.name, err)
+		return utils.PodTarget{}, false
+	}
+	return podTarget, true
+}
+
+// TrackPod restarts a service with trackPod once the pod it forwards to is deleted,
+// terminating or no longer ready, so it moves to a healthy pod before kubectl notices
+// the old one is gone. Lookup errors are ignored, like those of the readiness check.
+func (sm *ServiceManager) TrackPod() {
+	sm.mutex.Lock()
+	pod := sm.status.Pod
+	if pod == "" || sm.status.Status != "Running" || time.Since(sm.lastPodCheckTime) < podTrackingInterval {
+		sm.mutex.Unlock()
+		return
+	}
+	sm.lastPodCheckTime = time.Now()
+	sm.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reason, err := checkPodServing(ctx, sm.config.Kubectl.Context, sm.config.Namespace, pod)
+	if err != nil {
+		sm.logger.Debug("Could not check pod %s of %s: %v", pod, sm.name, err)
+		return
+	}
+	if reason == "" {
+		return
+	}
+
+	sm.logger.Event(utils.LevelInfo, "pod_switch", "Pod %s of %s is %s, switching to another pod", pod, sm.name, reason)
+	sm.SetStatusMessage("pod " + pod + " " + reason + ", switching pods")
+	if err := sm.Restart(); err != nil {
+		sm.logger.Warn("Failed to switch pods for %s: %v", sm.name, err)
+	}
+}
@@ -0,0 +1,71 @@
+package portforward
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestResolveTrackedPod tests which services have their pod resolved before starting
+func TestResolveTrackedPod(t *testing.T) {
+	original := resolvePodTarget
+	defer func() { resolvePodTarget = original }()
+
+	resolvePodTarget = func(ctx context.Context, kubeContext, namespace, target string, port int) (utils.PodTarget, error) {
+		return utils.PodTarget{Pod: "api-2", Port: 8080}, nil
+	}
+
+	sm := newUnreachableService(t, config.HealthCheckConfig{})
+	sm.config.Target = "service/api"
+	if _, ok := sm.resolveTrackedPod(); ok {
+		t.Error("Expected no pod to be resolved without trackPod")
+	}
+
+	sm.config.TrackPod = true
+	podTarget, ok := sm.resolveTrackedPod()
+	if !ok || podTarget.Target() != "pod/api-2" || podTarget.Port != 8080 {
+		t.Errorf("Expected pod/api-2 on 8080, got %+v", podTarget)
+	}
+
+	sm.config.Target = "pod/api-1"
+	if _, ok := sm.resolveTrackedPod(); ok {
+		t.Error("Expected pod targets to be forwarded as configured")
+	}
+}
+
+// TestTrackPodKeepsServingPod tests that a healthy tracked pod is left alone
+func TestTrackPodKeepsServingPod(t *testing.T) {
+	original := checkPodServing
+	defer func() { checkPodServing = original }()
+
+	var checked []string
+	checkPodServing = func(ctx context.Context, kubeContext, namespace, pod string) (string, error) {
+		checked = append(checked, pod)
+		return "", nil
+	}
+
+	sm := newUnreachableService(t, config.HealthCheckConfig{})
+	sm.TrackPod()
+	if len(checked) != 0 {
+		t.Errorf("Expected no check without a tracked pod, got %v", checked)
+	}
+
+	sm.status.Pod = "api-2"
+	sm.lastPodCheckTime = time.Now()
+	sm.TrackPod()
+	if len(checked) != 0 {
+		t.Errorf("Expected no check before the interval, got %v", checked)
+	}
+
+	sm.lastPodCheckTime = sm.lastPodCheckTime.AddDate(-1, 0, 0)
+	sm.TrackPod()
+	if len(checked) != 1 || checked[0] != "api-2" {
+		t.Errorf("Expected api-2 to be checked once, got %v", checked)
+	}
+	if status := sm.GetStatus(); status.Status != "Running" || status.RestartCount != 0 {
+		t.Errorf("Expected the service to keep running, got %s after %d restarts", status.Status, status.RestartCount)
+	}
+}
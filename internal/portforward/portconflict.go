@@ -0,0 +1,135 @@
+package portforward
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/common"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// portReleaseTimeout limits waiting for a killed process to release its port
+const portReleaseTimeout = 2 * time.Second
+
+// findPortOwner and killPortOwner are replaced in tests to leave real processes alone
+var (
+	findPortOwner = utils.FindProcessOnPort
+	killPortOwner = func(pid int) error {
+		process, err := os.FindProcess(pid)
+		if err != nil {
+			return err
+		}
+		return process.Kill()
+	}
+)
+
+// describePortHolder names the process holding port, telling a kubectl left behind by an
+// earlier kportforward apart from other applications
+func describePortHolder(port int) string {
+	owner, err := findPortOwner(port)
+	if err != nil {
+		return "another process"
+	}
+	command := strings.ToLower(strings.TrimSuffix(filepath.Base(owner.Command), ".exe"))
+	switch {
+	case command == "kubectl":
+		return owner.String() + ", likely a stale kportforward"
+	case strings.HasPrefix(command, "kportforward"):
+		return owner.String() + ", another kportforward"
+	}
+	return owner.String()
+}
+
+// portConflictError describes what holds the local port of a strictPort service
+func (sm *ServiceManager) portConflictError(port int) error {
+	sm.status.PortHolder = describePortHolder(port)
+	return common.WithKind(common.ErrPortConflict,
+		fmt.Errorf("port %d is in use by %s (strictPort)", port, sm.status.PortHolder))
+}
+
+// markPortConflict leaves a strictPort service waiting for its port; the manager retries
+// it every monitoring interval. Callers must hold the mutex.
+func (sm *ServiceManager) markPortConflict(err error) {
+	if sm.status.Status != "PortConflict" {
+		sm.logger.Event(utils.LevelWarn, "port_conflict", "Service %s is waiting for its local port: %v", sm.name, err)
+	}
+	sm.status.Status = "PortConflict"
+	sm.status.LocalPort = sm.configuredPort()
+	sm.status.StatusMessage = ""
+}
+
+// RetryPort starts a service in PortConflict again, unless an earlier retry is still
+// running; looking up the port holder can take longer than a monitoring interval
+func (sm *ServiceManager) RetryPort() {
+	if !sm.retryingPort.CompareAndSwap(false, true) {
+		return
+	}
+	defer sm.retryingPort.Store(false)
+	_ = sm.Start()
+}
+
+// PortOwner returns the process holding the service's configured local port
+func (sm *ServiceManager) PortOwner() (*utils.ProcessInfo, error) {
+	sm.mutex.RLock()
+	port := sm.configuredPort()
+	sm.mutex.RUnlock()
+	return findPortOwner(port)
+}
+
+// KillPortOwner kills the process holding the local port of a service in PortConflict,
+// provided it is still pid, and starts the service once the port is free
+func (sm *ServiceManager) KillPortOwner(pid int) error {
+	sm.mutex.RLock()
+	port := sm.configuredPort()
+	conflict := sm.status.Status == "PortConflict"
+	sm.mutex.RUnlock()
+	if !conflict {
+		return fmt.Errorf("%s is not waiting for its port", sm.name)
+	}
+
+	owner, err := findPortOwner(port)
+	if err != nil {
+		return err
+	}
+	if owner.PID != pid {
+		return fmt.Errorf("port %d is now held by %s", port, owner)
+	}
+	sm.logger.Event(utils.LevelWarn, "port_owner_killed", "Killing %s to free port %d for %s", owner, port, sm.name)
+	if err := killPortOwner(pid); err != nil {
+		return fmt.Errorf("failed to kill %s: %w", owner, err)
+	}
+
+	deadline := time.Now().Add(portReleaseTimeout)
+	for !utils.IsPortAvailable(port) && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	return sm.Start()
+}
+
+// PortOwner returns the process holding the local port of a service
+func (m *Manager) PortOwner(name string) (*utils.ProcessInfo, error) {
+	m.mutex.RLock()
+	sm, exists := m.services[name]
+	m.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("service %s not found", name)
+	}
+	return sm.PortOwner()
+}
+
+// KillPortOwner kills process pid holding the local port of a service in PortConflict
+// and starts the service
+func (m *Manager) KillPortOwner(name string, pid int) error {
+	m.mutex.RLock()
+	sm, exists := m.services[name]
+	m.mutex.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("service %s not found", name)
+	}
+	return sm.KillPortOwner(pid)
+}
@@ -0,0 +1,170 @@
+package portforward
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestStrictPort tests that a strictPort service waits for its taken port and that its
+// owner is only killed if it still holds the port
+func TestStrictPort(t *testing.T) {
+	originalFind, originalKill := findPortOwner, killPortOwner
+	defer func() { findPortOwner, killPortOwner = originalFind, originalKill }()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	owner := &utils.ProcessInfo{PID: 4242, Command: "node"}
+	findPortOwner = func(int) (*utils.ProcessInfo, error) {
+		if owner == nil {
+			return nil, fmt.Errorf("no process found listening on port %d", port)
+		}
+		return owner, nil
+	}
+	var killed []int
+	killPortOwner = func(pid int) error {
+		killed = append(killed, pid)
+		listener.Close()
+		owner = nil
+		return nil
+	}
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	sm := NewServiceManager("api", config.Service{Target: "service/api", LocalPort: port, TargetPort: 80, StrictPort: true}, logger)
+	if err := sm.Start(); err == nil {
+		t.Fatal("Expected Start to fail while the port is taken")
+	}
+	status := sm.GetStatus()
+	if status.Status != "PortConflict" || status.LocalPort != port || !strings.Contains(status.LastError, "node (pid 4242)") {
+		t.Fatalf("Expected PortConflict on port %d naming the owner, got %s %d %q", port, status.Status, status.LocalPort, status.LastError)
+	}
+
+	if err := sm.KillPortOwner(1); err == nil || len(killed) != 0 {
+		t.Fatalf("Expected no kill when another process holds the port, got %v %v", err, killed)
+	}
+
+	// Start fails in cooldown before it runs kubectl
+	sm.cooldownUntil = time.Now().Add(time.Hour)
+	sm.KillPortOwner(4242)
+	if len(killed) != 1 || killed[0] != 4242 {
+		t.Errorf("Expected pid 4242 to be killed, got %v", killed)
+	}
+	if status := sm.GetStatus(); status.Status == "PortConflict" {
+		t.Error("Expected the service to start once the port was freed")
+	}
+}
+
+// TestPortHolder tests that the process holding a taken port is recorded and described
+func TestPortHolder(t *testing.T) {
+	originalFind := findPortOwner
+	defer func() { findPortOwner = originalFind }()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	var owner *utils.ProcessInfo
+	findPortOwner = func(int) (*utils.ProcessInfo, error) {
+		if owner == nil {
+			return nil, fmt.Errorf("no process found listening on port %d", port)
+		}
+		return owner, nil
+	}
+	tests := []struct {
+		owner    *utils.ProcessInfo
+		expected string
+	}{
+		{&utils.ProcessInfo{PID: 7, Command: "kubectl"}, "kubectl (pid 7), likely a stale kportforward"},
+		{&utils.ProcessInfo{PID: 8, Command: "kportforward.exe"}, "kportforward.exe (pid 8), another kportforward"},
+		{&utils.ProcessInfo{PID: 9, Command: "node"}, "node (pid 9)"},
+		{nil, "another process"},
+	}
+	for _, test := range tests {
+		owner = test.owner
+		if holder := describePortHolder(port); holder != test.expected {
+			t.Errorf("Expected %q, got %q", test.expected, holder)
+		}
+	}
+
+	// A service moved off its port records what holds it until the port is free again
+	owner = &utils.ProcessInfo{PID: 9, Command: "node"}
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	sm := NewServiceManager("api", config.Service{Target: "service/api", LocalPort: port, TargetPort: 80}, logger)
+	if actual, err := sm.resolvePort(); err != nil || actual == port {
+		t.Fatalf("Expected another port than %d, got %d %v", port, actual, err)
+	}
+	if holder := sm.GetStatus().PortHolder; holder != "node (pid 9)" {
+		t.Errorf("Expected the holder to be recorded, got %q", holder)
+	}
+	listener.Close()
+	if actual, err := sm.resolvePort(); err != nil || actual != port {
+		t.Fatalf("Expected port %d once free, got %d %v", port, actual, err)
+	}
+	if holder := sm.GetStatus().PortHolder; holder != "" {
+		t.Errorf("Expected no holder once the port is free, got %q", holder)
+	}
+}
+
+// TestRetryPortOneAtATime tests that a strictPort service does not start another retry
+// while the previous one is still running
+func TestRetryPortOneAtATime(t *testing.T) {
+	originalFind := findPortOwner
+	defer func() { findPortOwner = originalFind }()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	// Looking up the port holder hangs until released
+	lookups := make(chan struct{}, 10)
+	release := make(chan struct{})
+	findPortOwner = func(int) (*utils.ProcessInfo, error) {
+		lookups <- struct{}{}
+		<-release
+		return &utils.ProcessInfo{PID: 4242, Command: "node"}, nil
+	}
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	sm := NewServiceManager("api", config.Service{Target: "service/api", LocalPort: port, TargetPort: 80, StrictPort: true}, logger)
+	done := make(chan struct{})
+	go func() {
+		sm.RetryPort()
+		close(done)
+	}()
+	<-lookups
+
+	sm.RetryPort()
+	sm.RetryPort()
+	close(release)
+	<-done
+
+	if len(lookups) != 0 {
+		t.Errorf("Expected a single retry in flight, got %d more", len(lookups))
+	}
+	if status := sm.GetStatus(); status.Status != "PortConflict" {
+		t.Errorf("Expected PortConflict, got %s", status.Status)
+	}
+
+	// Once the retry is done the next one runs
+	sm.RetryPort()
+	if len(lookups) != 1 {
+		t.Errorf("Expected the next retry to run, got %d lookups", len(lookups))
+	}
+}
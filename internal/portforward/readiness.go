@@ -0,0 +1,37 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// getPodReadiness is replaced in tests to avoid calling kubectl
+var getPodReadiness = utils.GetPodReadiness
+
+// checkPodReadiness reports whether the service's target pod is Ready. Lookup errors are
+// treated as ready so a missing RBAC permission never blocks the service forever.
+func (sm *ServiceManager) checkPodReadiness() (utils.PodReadiness, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	readiness, err := getPodReadiness(ctx, sm.config.Kubectl.Context, sm.config.Namespace, sm.config.Target)
+	if err != nil {
+		sm.logger.Debug("Could not check pod readiness for %s: %v", sm.name, err)
+		return readiness, true
+	}
+	if !readiness.IsReady() {
+		sm.logger.Debug("Waiting for pod of %s to become ready (%s)", sm.name, readiness)
+	}
+	return readiness, readiness.IsReady()
+}
+
+// podNotReadyMessage describes why a service is still Connecting
+func podNotReadyMessage(readiness utils.PodReadiness) string {
+	if readiness.Total == 0 {
+		return "pod not ready (no pods found)"
+	}
+	return fmt.Sprintf("pod not ready (%s)", readiness)
+}
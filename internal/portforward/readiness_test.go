@@ -0,0 +1,42 @@
+package portforward
+
+import (
+	"context"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestWaitForReadyKeepsConnecting tests that an unready pod holds the service in Connecting
+func TestWaitForReadyKeepsConnecting(t *testing.T) {
+	original := getPodReadiness
+	defer func() { getPodReadiness = original }()
+
+	readiness := utils.PodReadiness{Pod: "api-1", Ready: 0, Total: 1}
+	getPodReadiness = func(ctx context.Context, kubeContext, namespace, target string) (utils.PodReadiness, error) {
+		return readiness, nil
+	}
+
+	sm := newUnreachableService(t, config.HealthCheckConfig{})
+	sm.config.WaitForReady = true
+	sm.status.Status = "Connecting"
+
+	for i := 0; i < 5; i++ {
+		sm.lastHealthCheckTime = sm.lastHealthCheckTime.AddDate(-1, 0, 0)
+		sm.EvaluateHealth()
+	}
+
+	status := sm.GetStatus()
+	if status.Status != "Connecting" || status.StatusMessage != "pod not ready (0/1)" {
+		t.Errorf("Expected Connecting with pod not ready message, got %s %q", status.Status, status.StatusMessage)
+	}
+
+	// Once ready, the regular health checks take over
+	readiness.Ready = 1
+	sm.lastHealthCheckTime = sm.lastHealthCheckTime.AddDate(-1, 0, 0)
+	sm.EvaluateHealth()
+	if status := sm.GetStatus(); status.StatusMessage == "pod not ready (0/1)" {
+		t.Error("Expected readiness message to be replaced once the pod is ready")
+	}
+}
@@ -0,0 +1,139 @@
+package portforward
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// ReloadConfig applies a new configuration to the running manager. Removed services are
+// stopped, added ones started and changed ones restarted with their new settings, while
+// unchanged services keep their connections. The monitoring interval and UI options
+// only take effect on the next start.
+func (m *Manager) ReloadConfig(cfg *config.Config) error {
+	if cfg == nil {
+		return fmt.Errorf("no configuration to reload")
+	}
+
+	m.mutex.Lock()
+	if m.shuttingDown {
+		m.mutex.Unlock()
+		return fmt.Errorf("manager is shutting down")
+	}
+
+	added, removed, changed := diffServices(m.config.PortForwards, cfg.PortForwards)
+	m.config = cfg
+	offset := m.portOffset(m.kubernetesContext)
+	idle := m.idle
+
+	var stale []*ServiceManager
+	for _, name := range append(removed, changed...) {
+		if sm, exists := m.services[name]; exists {
+			stale = append(stale, sm)
+			delete(m.services, name)
+		}
+		// Services of a selector entry are expanded again with its new settings
+		for instance := range m.expanded[name] {
+			stale = append(stale, m.services[instance])
+			delete(m.services, instance)
+		}
+		delete(m.expanded, name)
+	}
+	var fresh []*ServiceManager
+	for _, name := range append(added, changed...) {
+		if cfg.PortForwards[name].HasSelector() {
+			continue
+		}
+		sm := NewServiceManager(name, cfg.PortForwards[name], m.serviceLogger(name))
+		sm.SetPortOffset(offset)
+		m.services[name] = sm
+		fresh = append(fresh, sm)
+	}
+	for _, sm := range m.services {
+		sm.SetRunningCheckInterval(cfg.MonitoringIntervals.Running)
+		sm.SetBackend(cfg.PortForwardBackend)
+		m.setDependencies(sm, cfg.PortForwards)
+	}
+	m.mutex.Unlock()
+
+	m.logger.Info("Reloaded configuration: %d added, %d removed, %d changed", len(added), len(removed), len(changed))
+
+	// Stop everything first so new services can take over ports of removed ones
+	m.retireServices(stale)
+	m.launchServices(fresh, idle)
+	go m.refreshSelectors()
+
+	m.sendInitialStatus()
+	return nil
+}
+
+// retireServices stops services that were removed from the manager, and their UIs
+func (m *Manager) retireServices(stale []*ServiceManager) {
+	m.mutex.RLock()
+	grpcHandler := m.grpcUIHandler
+	swaggerHandler := m.swaggerUIHandler
+	m.mutex.RUnlock()
+
+	for _, sm := range stale {
+		for _, handler := range []UIHandler{grpcHandler, swaggerHandler} {
+			if handler != nil && !isNilInterface(handler) && handler.IsEnabled() {
+				if err := handler.StopService(sm.name); err != nil {
+					m.logger.Warn("Failed to stop UI for %s: %v", sm.name, err)
+				}
+			}
+		}
+		sm.Shutdown()
+	}
+}
+
+// launchServices starts services that were added to the manager, or marks them idle or
+// suspended like the others
+func (m *Manager) launchServices(fresh []*ServiceManager, idle bool) {
+	for _, sm := range fresh {
+		switch {
+		case idle:
+			// Started with the others when the manager resumes
+			sm.mutex.Lock()
+			sm.status.Status = "Idle"
+			sm.status.StatusMessage = "Stopped after inactivity"
+			sm.mutex.Unlock()
+		case !m.GetGlobalAccessStatus():
+			sm.mutex.Lock()
+			sm.status.Status = "Suspended"
+			sm.status.StatusMessage = "Suspended due to global kubectl access failure"
+			sm.status.StartTime = time.Time{}
+			sm.mutex.Unlock()
+		default:
+			if err := sm.Start(); err != nil {
+				m.logger.Error("Failed to start service %s: %v", sm.name, err)
+			}
+		}
+	}
+}
+
+// diffServices returns the sorted names of services that were added, removed or whose
+// configuration changed between two sets of port forwards
+func diffServices(previous, next map[string]config.Service) (added, removed, changed []string) {
+	for name, service := range next {
+		old, exists := previous[name]
+		switch {
+		case !exists:
+			added = append(added, name)
+		case !reflect.DeepEqual(old, service):
+			changed = append(changed, name)
+		}
+	}
+	for name := range previous {
+		if _, exists := next[name]; !exists {
+			removed = append(removed, name)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed
+}
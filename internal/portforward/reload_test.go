@@ -0,0 +1,80 @@
+package portforward
+
+import (
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestDiffServices(t *testing.T) {
+	previous := map[string]config.Service{
+		"api":    {Target: "service/api", TargetPort: 80, LocalPort: 8080},
+		"db":     {Target: "service/db", TargetPort: 5432, LocalPort: 5432},
+		"legacy": {Target: "service/legacy", TargetPort: 80, LocalPort: 8081},
+	}
+	next := map[string]config.Service{
+		"api":   {Target: "service/api", TargetPort: 80, LocalPort: 8080},
+		"db":    {Target: "service/db", TargetPort: 5432, LocalPort: 15432},
+		"cache": {Target: "service/cache", TargetPort: 6379, LocalPort: 6379},
+	}
+
+	added, removed, changed := diffServices(previous, next)
+	if !reflect.DeepEqual(added, []string{"cache"}) {
+		t.Errorf("Expected cache to be added, got %v", added)
+	}
+	if !reflect.DeepEqual(removed, []string{"legacy"}) {
+		t.Errorf("Expected legacy to be removed, got %v", removed)
+	}
+	if !reflect.DeepEqual(changed, []string{"db"}) {
+		t.Errorf("Expected db to be changed, got %v", changed)
+	}
+}
+
+// TestReloadConfigKeepsUnchangedServices tests that only affected services are replaced
+func TestReloadConfigKeepsUnchangedServices(t *testing.T) {
+	cfg := &config.Config{
+		PortForwards: map[string]config.Service{
+			"api":    {Target: "service/api", TargetPort: 80, LocalPort: 8080},
+			"legacy": {Target: "service/legacy", TargetPort: 80, LocalPort: 8081},
+		},
+		MonitoringInterval: 5 * time.Second,
+	}
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	manager := NewManager(cfg, logger)
+	for name, service := range cfg.PortForwards {
+		manager.services[name] = NewServiceManager(name, service, logger)
+	}
+	// Idle managers do not start new services, so no kubectl is needed
+	manager.idle = true
+	api := manager.services["api"]
+
+	err := manager.ReloadConfig(&config.Config{
+		PortForwards: map[string]config.Service{
+			"api":   {Target: "service/api", TargetPort: 80, LocalPort: 8080},
+			"cache": {Target: "service/cache", TargetPort: 6379, LocalPort: 6379},
+		},
+		MonitoringInterval: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("ReloadConfig returned error: %v", err)
+	}
+
+	if manager.services["api"] != api {
+		t.Error("Expected unchanged service to keep running")
+	}
+	if _, exists := manager.services["legacy"]; exists {
+		t.Error("Expected removed service to be dropped")
+	}
+	cache, exists := manager.services["cache"]
+	if !exists {
+		t.Fatal("Expected added service to be created")
+	}
+	if status := cache.GetStatus().Status; status != "Idle" {
+		t.Errorf("Expected added service to wait for resume while idle, got %s", status)
+	}
+}
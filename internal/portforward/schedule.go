@@ -0,0 +1,56 @@
+package portforward
+
+import (
+	"time"
+)
+
+// applySchedules stops services whose availability window has closed and
+// restarts those whose window has opened again
+func (m *Manager) applySchedules() {
+	m.mutex.RLock()
+	services := make(map[string]*ServiceManager, len(m.services))
+	for name, sm := range m.services {
+		services[name] = sm
+	}
+	m.mutex.RUnlock()
+
+	now := time.Now()
+	for name, sm := range services {
+		if !sm.config.HasSchedule() {
+			continue
+		}
+		active := sm.config.IsScheduledAt(now)
+
+		sm.mutex.Lock()
+		switch {
+		case !active && sm.status.Status != "Scheduled" && sm.status.Status != "Suspended":
+			m.logger.Info("Availability window closed for %s, stopping", name)
+			if sm.tunnel != nil {
+				if err := sm.tunnel.Stop(); err != nil {
+					m.logger.Warn("Failed to stop port-forward for %s: %v", name, err)
+				}
+				sm.tunnel = nil
+			}
+			sm.markOutsideSchedule()
+			sm.mutex.Unlock()
+
+		case active && sm.status.Status == "Scheduled":
+			m.logger.Info("Availability window opened for %s, starting", name)
+			sm.status.Status = "Reconnecting"
+			sm.status.StatusMessage = "Starting for availability window"
+			sm.mutex.Unlock()
+
+			go func(serviceName string, serviceManager *ServiceManager) {
+				if m.isShuttingDown() {
+					return
+				}
+				if err := serviceManager.Restart(); err != nil {
+					m.logger.Error("Failed to start scheduled service %s: %v", serviceName, err)
+				}
+			}(name, sm)
+
+		default:
+			sm.mutex.Unlock()
+		}
+	}
+}
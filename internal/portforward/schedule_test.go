@@ -0,0 +1,41 @@
+package portforward
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestApplySchedulesStopsClosedWindow tests that services are parked outside their window
+func TestApplySchedulesStopsClosedWindow(t *testing.T) {
+	// A window that opened and closed an hour ago, on every day
+	now := time.Now()
+	closed := config.ScheduleWindow{
+		Start: now.Add(-3 * time.Hour).Format("15:04"),
+		End:   now.Add(-time.Hour).Format("15:04"),
+	}
+	if closed.Start > closed.End {
+		t.Skip("window would wrap around midnight")
+	}
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	service := config.Service{Target: "service/test", LocalPort: 8080, Schedule: []config.ScheduleWindow{closed}}
+	manager := NewManager(&config.Config{PortForwards: map[string]config.Service{"test-service": service}}, logger)
+
+	sm := NewServiceManager("test-service", service, logger)
+	sm.status.Status = "Running"
+	manager.services["test-service"] = sm
+
+	manager.applySchedules()
+
+	status := sm.GetStatus()
+	if status.Status != "Scheduled" {
+		t.Errorf("Expected service to be Scheduled, got %s", status.Status)
+	}
+	if status.StatusMessage == "" {
+		t.Error("Expected a message explaining when the service resumes")
+	}
+}
@@ -0,0 +1,215 @@
+package portforward
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// selectorTimeout limits listing the namespaces and pods of all selector entries
+const selectorTimeout = 20 * time.Second
+
+// listNamespaces and listServingPods are replaced in tests to avoid calling kubectl
+var (
+	listNamespaces  = utils.ListNamespaces
+	listServingPods = utils.ListServingPods
+)
+
+// refreshSelectors expands every entry with a labelSelector, namespaceSelector or
+// namespace pattern into its matching services, starting services for new matches and
+// stopping those that no longer match. Only one refresh runs at a time; an entry that
+// cannot be listed keeps its services until the next refresh.
+func (m *Manager) refreshSelectors() {
+	if !m.selectorRefreshing.CompareAndSwap(false, true) {
+		return
+	}
+	defer m.selectorRefreshing.Store(false)
+
+	m.mutex.RLock()
+	entries := make(map[string]config.Service)
+	for name, service := range m.config.PortForwards {
+		if service.HasSelector() {
+			entries[name] = service
+		}
+	}
+	m.mutex.RUnlock()
+	if len(entries) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, selectorTimeout)
+	defer cancel()
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		expanded, err := expandSelector(ctx, name, entries[name])
+		if err != nil {
+			m.logger.Warn("Failed to expand the selectors of %s: %v", name, err)
+			continue
+		}
+		m.applyExpansion(name, entries[name], expanded)
+	}
+}
+
+// expandSelector returns the services of a selector entry by name: one per matching
+// namespace and, with a labelSelector, per ready pod. Local ports are assigned later.
+func expandSelector(ctx context.Context, entry string, service config.Service) (map[string]config.Service, error) {
+	kubeContext := service.Kubectl.Context
+	namespaces := []string{service.Namespace}
+	switch {
+	case service.NamespaceSelector != "":
+		selected, err := listNamespaces(ctx, kubeContext, service.NamespaceSelector)
+		if err != nil {
+			return nil, err
+		}
+		namespaces = selected
+	case service.HasNamespacePattern():
+		all, err := listNamespaces(ctx, kubeContext, "")
+		if err != nil {
+			return nil, err
+		}
+		namespaces = nil
+		for _, namespace := range all {
+			if service.MatchesNamespace(namespace) {
+				namespaces = append(namespaces, namespace)
+			}
+		}
+	}
+
+	expanded := make(map[string]config.Service)
+	for _, namespace := range namespaces {
+		instance := service
+		instance.Namespace = namespace
+		instance.LabelSelector, instance.NamespaceSelector = "", ""
+		if service.LabelSelector == "" {
+			expanded[service.ExpandedName(entry, namespace, "")] = instance
+			continue
+		}
+
+		pods, err := listServingPods(ctx, kubeContext, namespace, service.LabelSelector)
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods {
+			podInstance := instance
+			podInstance.Target = "pod/" + pod
+			expanded[service.ExpandedName(entry, namespace, pod)] = podInstance
+		}
+	}
+	return expanded, nil
+}
+
+// applyExpansion replaces the services of a selector entry with expanded. Services that
+// still match keep running on their local port; new ones get the lowest port from the
+// entry's localPort up that no other service uses.
+func (m *Manager) applyExpansion(entry string, service config.Service, expanded map[string]config.Service) {
+	m.mutex.Lock()
+	// A reload may have removed or changed the entry while it was being listed
+	if m.shuttingDown || !reflect.DeepEqual(m.config.PortForwards[entry], service) {
+		m.mutex.Unlock()
+		return
+	}
+	if m.expanded == nil {
+		m.expanded = make(map[string]map[string]config.Service)
+	}
+	previous := m.expanded[entry]
+	offset := m.portOffset(m.kubernetesContext)
+
+	// Local ports are compared with the offset applied, as the services use them, so new
+	// matches avoid every other service and not just the other matches of this entry
+	usedPorts := make(map[int]bool)
+	for name, sm := range m.services {
+		if _, own := previous[name]; own {
+			continue
+		}
+		for _, port := range sm.localPorts() {
+			usedPorts[port] = true
+		}
+	}
+	for other, services := range m.expanded {
+		if other == entry {
+			continue
+		}
+		for _, instance := range services {
+			usedPorts[instance.LocalPort+offset] = true
+		}
+	}
+
+	var added []string
+	for name := range expanded {
+		if _, configured := m.config.PortForwards[name]; configured {
+			m.logger.Warn("Selectors of %s match %s, which is already a configured service", entry, name)
+			delete(expanded, name)
+			continue
+		}
+		if kept, exists := previous[name]; exists {
+			expanded[name] = kept
+			usedPorts[kept.LocalPort+offset] = true
+		} else {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+
+	var stale []*ServiceManager
+	for name := range previous {
+		if _, exists := expanded[name]; !exists {
+			stale = append(stale, m.services[name])
+			delete(m.services, name)
+		}
+	}
+	var fresh []*ServiceManager
+	for _, name := range added {
+		instance := expanded[name]
+		for usedPorts[instance.LocalPort+offset] {
+			instance.LocalPort++
+		}
+		usedPorts[instance.LocalPort+offset] = true
+		expanded[name] = instance
+
+		sm := NewServiceManager(name, instance, m.serviceLogger(name))
+		sm.SetPortOffset(offset)
+		sm.SetRunningCheckInterval(m.config.MonitoringIntervals.Running)
+		sm.SetBackend(m.config.PortForwardBackend)
+		sm.SetTunnelEnded(m.restartEndedTunnel)
+		m.setDependencies(sm, m.config.PortForwards)
+		m.services[name] = sm
+		fresh = append(fresh, sm)
+	}
+	m.expanded[entry] = expanded
+	idle := m.idle
+	m.mutex.Unlock()
+
+	if len(added) == 0 && len(stale) == 0 {
+		return
+	}
+	m.logger.Info("Selectors of %s: %d services added, %d removed", entry, len(added), len(stale))
+	m.retireServices(stale)
+	m.launchServices(fresh, idle)
+	m.sendInitialStatus()
+}
+
+// serviceConfigs returns the configuration of every running service: the configured
+// ones and those expanded from selector entries
+func (m *Manager) serviceConfigs() map[string]config.Service {
+	if len(m.expanded) == 0 {
+		return m.config.PortForwards
+	}
+	configs := make(map[string]config.Service, len(m.config.PortForwards))
+	for name, service := range m.config.PortForwards {
+		configs[name] = service
+	}
+	for _, expanded := range m.expanded {
+		for name, service := range expanded {
+			configs[name] = service
+		}
+	}
+	return configs
+}
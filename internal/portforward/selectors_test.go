@@ -0,0 +1,145 @@
+package portforward
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestRefreshSelectors tests expanding selector entries and following their matches
+func TestRefreshSelectors(t *testing.T) {
+	originalNamespaces, originalPods := listNamespaces, listServingPods
+	defer func() { listNamespaces, listServingPods = originalNamespaces, originalPods }()
+
+	pods := map[string][]string{"team-a": {"debug-1", "debug-2"}, "team-b": {"debug-3"}}
+	listNamespaces = func(ctx context.Context, kubeContext, selector string) ([]string, error) {
+		return []string{"other", "team-a", "team-b"}, nil
+	}
+	listServingPods = func(ctx context.Context, kubeContext, namespace, selector string) ([]string, error) {
+		if selector != "app=debug" {
+			t.Errorf("Expected the entry's label selector, got %q", selector)
+		}
+		return pods[namespace], nil
+	}
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	manager := NewManager(&config.Config{PortForwards: map[string]config.Service{
+		"debug": {Namespace: "team-*", LabelSelector: "app=debug", TargetPort: 6060, LocalPort: 6060},
+	}}, logger)
+	// Idle services are created without starting kubectl
+	manager.idle = true
+
+	ports := func() map[string]int {
+		manager.mutex.RLock()
+		defer manager.mutex.RUnlock()
+		ports := make(map[string]int)
+		for name, sm := range manager.services {
+			ports[name] = sm.config.LocalPort
+			if name == "debug-team-a-debug-1" && sm.config.Target != "pod/debug-1" {
+				t.Errorf("Expected %s to forward to its pod, got %s", name, sm.config.Target)
+			}
+		}
+		return ports
+	}
+
+	manager.refreshSelectors()
+	expected := map[string]int{"debug-team-a-debug-1": 6060, "debug-team-a-debug-2": 6061, "debug-team-b-debug-3": 6062}
+	if got := ports(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+
+	// Remaining matches keep their ports and a new one takes the lowest free port
+	pods["team-a"] = []string{"debug-2", "debug-4"}
+	manager.refreshSelectors()
+	expected = map[string]int{"debug-team-a-debug-2": 6061, "debug-team-a-debug-4": 6060, "debug-team-b-debug-3": 6062}
+	if got := ports(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+
+	// Removing the entry stops its services
+	if err := manager.ReloadConfig(&config.Config{PortForwards: map[string]config.Service{}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := ports(); len(got) != 0 {
+		t.Errorf("Expected no services after removing the entry, got %v", got)
+	}
+}
+
+// TestRefreshSelectorsAvoidsOtherServices tests that new matches skip the local ports of
+// configured services, including one moved to another port, with the port offset applied
+func TestRefreshSelectorsAvoidsOtherServices(t *testing.T) {
+	originalServingPods := listServingPods
+	defer func() { listServingPods = originalServingPods }()
+	listServingPods = func(ctx context.Context, kubeContext, namespace, selector string) ([]string, error) {
+		return []string{"debug-1", "debug-2", "debug-3"}, nil
+	}
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	configured := map[string]config.Service{
+		"metrics": {Target: "service/metrics", Namespace: "team-a", TargetPort: 9090, LocalPort: 6061},
+		"moved":   {Target: "service/moved", Namespace: "team-a", TargetPort: 80, LocalPort: 5000},
+	}
+	manager := NewManager(&config.Config{PortForwards: map[string]config.Service{
+		"debug":   {Namespace: "team-a", LabelSelector: "app=debug", TargetPort: 6060, LocalPort: 6060},
+		"metrics": configured["metrics"],
+		"moved":   configured["moved"],
+	}}, logger)
+	manager.idle = true
+	offset := 1000
+	manager.portOffsetOverride = &offset
+	for name, service := range configured {
+		sm := NewServiceManager(name, service, logger)
+		sm.SetPortOffset(offset)
+		manager.services[name] = sm
+	}
+	// moved found its port taken and runs on 7063, i.e. 6063 before the offset
+	manager.services["moved"].status.LocalPort = 7063
+
+	manager.refreshSelectors()
+
+	manager.mutex.RLock()
+	ports := make(map[string]int)
+	for name := range manager.expanded["debug"] {
+		ports[name] = manager.services[name].config.LocalPort
+	}
+	manager.mutex.RUnlock()
+	expected := map[string]int{"debug-debug-1": 6060, "debug-debug-2": 6062, "debug-debug-3": 6064}
+	if !reflect.DeepEqual(ports, expected) {
+		t.Errorf("Expected %v, got %v", expected, ports)
+	}
+}
+
+// TestExpandSelectorNamespaces tests repeating a target in the namespaces of a namespaceSelector
+func TestExpandSelectorNamespaces(t *testing.T) {
+	original := listNamespaces
+	defer func() { listNamespaces = original }()
+	listNamespaces = func(ctx context.Context, kubeContext, selector string) ([]string, error) {
+		if selector != "team=payments" {
+			t.Errorf("Expected the namespace selector, got %q", selector)
+		}
+		return []string{"payments-eu", "payments-us"}, nil
+	}
+
+	expanded, err := expandSelector(context.Background(), "api", config.Service{
+		Target: "service/api", NamespaceSelector: "team=payments", TargetPort: 80, LocalPort: 8080,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name, service := range expanded {
+		names = append(names, name)
+		if service.Target != "service/api" || service.NamespaceSelector != "" || service.HasSelector() {
+			t.Errorf("Expected %s to be a plain service, got %+v", name, service)
+		}
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"api-payments-eu", "api-payments-us"}) {
+		t.Errorf("Expected a service per namespace, got %v", names)
+	}
+}
@@ -0,0 +1,182 @@
+package portforward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// SelfTestStep is one timed phase of a self-test
+type SelfTestStep struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+	Skipped  string // Why the step did not run, if it did not
+}
+
+// selfTestPollInterval is how often the self-test dials the local port while connecting
+const selfTestPollInterval = 250 * time.Millisecond
+
+// grpcurlCommand runs grpcurl for the gRPC deep check; replaced in tests
+var grpcurlCommand = func(ctx context.Context, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath("grpcurl"); err != nil {
+		return nil, errGRPCurlMissing
+	}
+	return exec.CommandContext(ctx, "grpcurl", args...).CombinedOutput()
+}
+
+// errGRPCurlMissing skips the gRPC deep check when grpcurl is not installed
+var errGRPCurlMissing = errors.New("grpcurl not found in PATH")
+
+// SelfTest starts the port-forward of a single service, waits until its local port accepts
+// connections, optionally runs a type-specific deep check and tears it down again,
+// timing each step. It stops at the first failed step but always tears down.
+func SelfTest(ctx context.Context, name string, service config.Service, backend string, deep bool, logger *utils.Logger) []SelfTestStep {
+	var steps []SelfTestStep
+	run := func(step string, fn func() error) bool {
+		started := time.Now()
+		err := fn()
+		steps = append(steps, SelfTestStep{Name: step, Duration: time.Since(started), Err: err})
+		return err == nil
+	}
+
+	// A self-test is explicit, so availability windows do not apply
+	service.Schedule = nil
+	sm := NewServiceManager(name, service, logger)
+	sm.SetBackend(backend)
+	if run("start", sm.Start) && run("connect", func() error { return sm.waitForConnection(ctx) }) && deep {
+		steps = append(steps, deepCheck(ctx, service, sm.GetStatus().LocalPort))
+	}
+
+	run("teardown", func() error {
+		sm.Shutdown()
+		return nil
+	})
+	return steps
+}
+
+// deepCheck runs the check for the service's type against the local port: gRPC
+// reflection for types using gRPC UI, or a GET for types with an http URL
+func deepCheck(ctx context.Context, service config.Service, port int) SelfTestStep {
+	started := time.Now()
+	timed := func(name string, err error) SelfTestStep {
+		return SelfTestStep{Name: name, Duration: time.Since(started), Err: err}
+	}
+
+	serviceType := config.LookupServiceType(service.Type)
+	switch {
+	case serviceType.UIHandler == config.UIHandlerGRPCUI:
+		err := checkGRPCReflection(ctx, port)
+		if errors.Is(err, errGRPCurlMissing) {
+			return SelfTestStep{Name: "grpc reflection", Skipped: err.Error()}
+		}
+		return timed("grpc reflection", err)
+	case strings.HasPrefix(serviceType.URL, "http"):
+		// The Swagger document is the one path every REST service is known to serve
+		path := "/"
+		if serviceType.UIHandler == config.UIHandlerSwagger && service.SwaggerPath != "" {
+			path = service.SwaggerPath
+		}
+		return timed("http", checkHTTP(ctx, port, path))
+	default:
+		return SelfTestStep{Name: "deep check", Skipped: fmt.Sprintf("no deep check for type %q", service.Type)}
+	}
+}
+
+// waitForConnection dials the local port until it answers, the port-forward exits or ctx ends
+func (sm *ServiceManager) waitForConnection(ctx context.Context) error {
+	ticker := time.NewTicker(selfTestPollInterval)
+	defer ticker.Stop()
+
+	for {
+		sm.mutex.RLock()
+		tunnel := sm.tunnel
+		port := sm.status.LocalPort
+		activity := sm.activity
+		sm.mutex.RUnlock()
+
+		if tunnel == nil || !tunnel.Running() {
+			if activity == nil {
+				return fmt.Errorf("port-forward is not running")
+			}
+			if line := activity.lastError.Load(); line != nil {
+				return fmt.Errorf("port-forward exited: %s", sm.describeKubectlError(*line))
+			}
+			return fmt.Errorf("port-forward exited")
+		}
+		if utils.CheckPortConnectivityQuick(port) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("local port %d did not accept connections: %w", port, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkHTTP expects a 2xx response from path on the local port
+func checkHTTP(ctx context.Context, port int, path string) error {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://localhost:%d%s", port, path), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("GET %s returned %s", path, resp.Status)
+	}
+	return nil
+}
+
+// checkGRPCReflection lists the services exposed through server reflection
+func checkGRPCReflection(ctx context.Context, port int) error {
+	output, err := grpcurlCommand(ctx, "-plaintext", fmt.Sprintf("localhost:%d", port), "list")
+	if errors.Is(err, errGRPCurlMissing) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("reflection failed: %s", strings.TrimSpace(string(output)))
+	}
+	if strings.TrimSpace(string(output)) == "" {
+		return fmt.Errorf("reflection returned no services")
+	}
+	return nil
+}
+
+// checkGRPCHealth calls the standard grpc.health.v1 Health service for the whole server,
+// or for service if it is set, and expects SERVING
+func checkGRPCHealth(ctx context.Context, port int, service string) error {
+	args := []string{"-plaintext"}
+	if service != "" {
+		args = append(args, "-d", fmt.Sprintf(`{"service":%q}`, service))
+	}
+	args = append(args, fmt.Sprintf("localhost:%d", port), "grpc.health.v1.Health/Check")
+
+	output, err := grpcurlCommand(ctx, args...)
+	if errors.Is(err, errGRPCurlMissing) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("health check failed: %s", strings.TrimSpace(string(output)))
+	}
+	if !strings.Contains(string(output), `"SERVING"`) {
+		return fmt.Errorf("health check returned %s", strings.Join(strings.Fields(string(output)), " "))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package portforward
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCheckHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/swagger.json" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	port := server.Listener.Addr().(*net.TCPAddr).Port
+
+	if err := checkHTTP(context.Background(), port, "swagger.json"); err != nil {
+		t.Errorf("Expected 200 from /swagger.json, got %v", err)
+	}
+	if err := checkHTTP(context.Background(), port, "/missing"); err == nil {
+		t.Error("Expected 404 to fail the check")
+	}
+}
+
+func TestCheckGRPCReflection(t *testing.T) {
+	original := grpcurlCommand
+	defer func() { grpcurlCommand = original }()
+
+	tests := []struct {
+		name    string
+		output  string
+		err     error
+		wantErr bool
+		skipped bool
+	}{
+		{name: "services listed", output: "grpc.health.v1.Health\n"},
+		{name: "no services", output: "\n", wantErr: true},
+		{name: "reflection disabled", output: "server does not support the reflection API", err: errors.New("exit status 1"), wantErr: true},
+		{name: "grpcurl missing", err: errGRPCurlMissing, wantErr: true, skipped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grpcurlCommand = func(ctx context.Context, args ...string) ([]byte, error) {
+				return []byte(tt.output), tt.err
+			}
+
+			err := checkGRPCReflection(context.Background(), 9090)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkGRPCReflection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, errGRPCurlMissing) != tt.skipped {
+				t.Errorf("Expected skipped = %v, got error %v", tt.skipped, err)
+			}
+		})
+	}
+}
+
+func TestCheckGRPCHealth(t *testing.T) {
+	original := grpcurlCommand
+	defer func() { grpcurlCommand = original }()
+
+	var gotArgs []string
+	output := "{\n  \"status\": \"SERVING\"\n}\n"
+	grpcurlCommand = func(ctx context.Context, args ...string) ([]byte, error) {
+		gotArgs = args
+		return []byte(output), nil
+	}
+
+	if err := checkGRPCHealth(context.Background(), 9090, "billing.Billing"); err != nil {
+		t.Errorf("Expected SERVING to pass, got %v", err)
+	}
+	if want := []string{"-plaintext", "-d", `{"service":"billing.Billing"}`, "localhost:9090", "grpc.health.v1.Health/Check"}; !reflect.DeepEqual(gotArgs, want) {
+		t.Errorf("Expected grpcurl %v, got %v", want, gotArgs)
+	}
+
+	output = "{\n  \"status\": \"NOT_SERVING\"\n}\n"
+	if err := checkGRPCHealth(context.Background(), 9090, ""); err == nil || len(gotArgs) != 3 {
+		t.Errorf("Expected NOT_SERVING of the whole server to fail, got %v for %v", err, gotArgs)
+	}
+}
@@ -2,24 +2,39 @@ package portforward
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"os"
 	"strings"
 	"sync"
 	"time"
 
 	"sync/atomic"
 
+	"github.com/victorkazakov/kportforward/internal/common"
 	"github.com/victorkazakov/kportforward/internal/config"
 	"github.com/victorkazakov/kportforward/internal/utils"
 )
 
+// probeTimeout limits each protocol health check
+const probeTimeout = 2 * time.Second
+
+// runProbeShellCommand runs exec health checks; replaced in tests
+var runProbeShellCommand = runShellCommand
+
+// probeHistorySize is the number of protocol probe durations kept for the sparkline
+const probeHistorySize = 30
+
+// lenientFailureThreshold is the number of consecutive failed port checks
+// tolerated for services with healthCheck.mode: lenient
+const lenientFailureThreshold = 20
+
 // ServiceManager manages the lifecycle of a single port-forward service
 type ServiceManager struct {
 	name   string
 	config config.Service
 	status *config.ServiceStatus
-	cmd    *exec.Cmd
+	tunnel tunnel
 	logger *utils.Logger
 	mutex  sync.RWMutex
 	ctx    context.Context
@@ -31,12 +46,60 @@ type ServiceManager struct {
 	backoffSeconds []int
 
 	// Health check fields
-	healthCheckFailures int
-	consecutiveFailures int
-	maxFailureThreshold int
-	lastHealthCheckTime time.Time
+	healthCheckFailures  int
+	consecutiveFailures  int
+	failedChecks         int // Consecutive failed checks, for healthCheck thresholds
+	maxFailureThreshold  int
+	lastHealthCheckTime  time.Time
+	runningCheckInterval time.Duration // From monitoringIntervals.running, see healthCheckInterval
+	lastKeepaliveTime    time.Time
+	lastPodCheckTime     time.Time
+	lastEndpointCheck    time.Time
+	endpointPods         []string        // Ready pods behind the service at the last endpoint check
+	probeLatencies       []time.Duration // Recent protocol probe durations, 0 for failures
 	// Restart deduplication
 	restarting atomic.Bool
+	// Set while a strictPort service retries its port, see RetryPort
+	retryingPort atomic.Bool
+
+	// Connection activity tracking, used by the idle timeout
+	activity          *connectionActivity
+	lastActivity      time.Time
+	externalHighWater int64
+
+	// Last lines kubectl wrote to stderr, shown in the detail view, and to both stdout
+	// and stderr, for its output pane
+	kubectlOutput *lineBuffer
+	consoleOutput *lineBuffer
+
+	// Cached "did you mean" hint for the last NotFound error
+	notFoundError string
+	notFoundHint  string
+
+	// portOffset is added to the configured local port, see Manager.portOffset
+	portOffset int
+
+	// backend runs the port-forward, kubectl or native, see SetBackend
+	backend string
+
+	// tunnelEnded is called when a port-forward ended on its own and failed the
+	// service, see watchTunnel
+	tunnelEnded func(sm *ServiceManager)
+
+	// Services that must be Running before this one starts and where their status is
+	// looked up, see SetDependencies
+	dependencies     []string
+	dependencyStatus func(name string) string
+}
+
+// connectionActivity counts connections handled by one kubectl process.
+// Successful health probes are counted separately so they can be discounted.
+type connectionActivity struct {
+	handled atomic.Int64
+	probes  atomic.Int64
+
+	// lastError is the last line kubectl wrote to stderr
+	lastError atomic.Pointer[string]
 }
 
 // NewServiceManager creates a new service manager
@@ -54,6 +117,10 @@ func NewServiceManager(name string, service config.Service, logger *utils.Logger
 		consecutiveFailures: 0,
 		maxFailureThreshold: 3, // Require 3 consecutive failures before marking as failed
 		lastHealthCheckTime: time.Now(),
+		activity:            &connectionActivity{},
+		kubectlOutput:       &lineBuffer{},
+		consoleOutput:       &lineBuffer{size: consoleOutputLines},
+		lastActivity:        time.Now(),
 		status: &config.ServiceStatus{
 			Name:         name,
 			Status:       "Starting",
@@ -66,6 +133,8 @@ func NewServiceManager(name string, service config.Service, logger *utils.Logger
 
 // Start begins the port-forward process
 func (sm *ServiceManager) Start() error {
+	podTarget, trackingPod := sm.resolveTrackedPod()
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
@@ -76,39 +145,85 @@ func (sm *ServiceManager) Start() error {
 		return fmt.Errorf("service %s is in cooldown until %v", sm.name, sm.cooldownUntil)
 	}
 
+	// Services outside their availability window stay down until it opens
+	if !sm.config.IsScheduledAt(time.Now()) {
+		sm.markOutsideSchedule()
+		return nil
+	}
+
+	// Services whose dependencies are not up yet are started by startWaitingServices
+	if pending := sm.pendingDependencies(); len(pending) > 0 {
+		sm.markWaiting(pending)
+		return nil
+	}
+
 	// Resolve port conflicts
 	actualPort, err := sm.resolvePort()
 	if err != nil {
-		sm.status.Status = "Failed"
+		if sm.config.StrictPort && errors.Is(err, common.ErrPortConflict) {
+			sm.markPortConflict(err)
+		} else {
+			sm.status.Status = "Failed"
+		}
 		sm.status.LastError = err.Error()
 		return fmt.Errorf("port resolution failed for %s: %w", sm.name, err)
 	}
 	sm.status.LocalPort = actualPort
+	sm.status.ExtraPorts = nil
+	for _, pair := range sm.extraPorts() {
+		sm.status.ExtraPorts = append(sm.status.ExtraPorts, pair.Local)
+	}
 
 	// Kill any orphaned process still holding the port (e.g. zombie kubectl from a previous session)
 	if !utils.IsPortAvailable(actualPort) {
-		sm.logger.Warn("Port %d is already in use for %s — killing the occupying process", actualPort, sm.name)
+		sm.logger.Warn("Port %d is already in use for %s by %s — killing the occupying process",
+			actualPort, sm.name, describePortHolder(actualPort))
 		if err := utils.KillProcessOnPort(actualPort); err != nil {
 			sm.logger.Warn("Failed to kill process on port %d: %v", actualPort, err)
 		}
 	}
 
 	// Start kubectl port-forward
-	cmd, err := utils.StartKubectlPortForward(
-		sm.config.Namespace,
-		sm.config.Target,
-		actualPort,
-		sm.config.TargetPort,
-		sm.logger,
-		sm.name,
-	)
+	if sm.config.IsExposed() {
+		sm.logger.Warn("Service %s is exposed on %s:%d (insecureExpose enabled)", sm.name, sm.config.BindAddress, actualPort)
+	}
+
+	// Fresh counters per process so output from a previous kubectl is ignored
+	activity := &connectionActivity{}
+	kubectlOutput, consoleOutput := sm.kubectlOutput, sm.consoleOutput
+	target, targetPort := sm.config.Target, sm.config.TargetPort
+	if trackingPod {
+		target, targetPort = podTarget.Target(), podTarget.Port
+	}
+	tunnel, err := startTunnel(sm.backend, utils.PortForwardOptions{
+		KubeContext:       sm.config.Kubectl.Context,
+		Namespace:         sm.config.Namespace,
+		Target:            target,
+		LocalPort:         actualPort,
+		TargetPort:        targetPort,
+		TargetPortName:    podTarget.PortName,
+		ExtraPorts:        sm.extraPorts(),
+		BindAddress:       sm.config.BindAddress,
+		RequestTimeout:    requestTimeout(sm.config.Kubectl),
+		Streaming:         sm.config.Kubectl.Streaming,
+		PodRunningTimeout: sm.config.Kubectl.PodRunningTimeout,
+		OnOutput: func(line string, isErr bool) {
+			consoleOutput.Add(line)
+			if isErr {
+				activity.lastError.Store(&line)
+				kubectlOutput.Add(line)
+			} else if strings.HasPrefix(line, "Handling connection for") {
+				activity.handled.Add(1)
+			}
+		},
+	}, sm.logger, sm.name)
 	if err != nil {
 		sm.status.Status = "Failed"
 
 		// Enhanced error classification
 		if sm.isAuthError(err) {
 			sm.status.LastError = "Authentication failure - check kubectl credentials"
-			sm.logger.Warn("Authentication error for service %s: %v", sm.name, err)
+			sm.logger.Event(utils.LevelWarn, "auth_failure", "Authentication error for service %s: %v", sm.name, err)
 			// Don't set individual service cooldown for auth errors - let global handler manage this
 		} else {
 			sm.status.LastError = err.Error()
@@ -118,9 +233,18 @@ func (sm *ServiceManager) Start() error {
 		return fmt.Errorf("failed to start port-forward for %s: %w", sm.name, err)
 	}
 
-	sm.cmd = cmd
-	sm.status.PID = cmd.Process.Pid
+	sm.tunnel = tunnel
+	if ending, ok := tunnel.(endingTunnel); ok {
+		go sm.watchTunnel(tunnel, ending.Done())
+	}
+	sm.status.PID = tunnel.PID()
 	sm.status.StartTime = time.Now()
+	sm.activity = activity
+	sm.externalHighWater = 0
+	sm.lastActivity = time.Now()
+	sm.status.Pod = podTarget.Pod
+	sm.lastPodCheckTime = time.Now()
+	sm.endpointPods = nil
 
 	// Set initial status to "Connecting" until health checks confirm it's running
 	// This provides better feedback during the connection establishment phase
@@ -132,9 +256,10 @@ func (sm *ServiceManager) Start() error {
 	// Reset health check counters
 	sm.healthCheckFailures = 0
 	sm.consecutiveFailures = 0
+	sm.failedChecks = 0
 	sm.lastHealthCheckTime = time.Now()
 
-	sm.logger.Info("Started port-forward for %s: %s:%d -> %d",
+	sm.logger.Event(utils.LevelInfo, "started", "Started port-forward for %s: %s:%d -> %d",
 		sm.name, sm.config.Target, sm.config.TargetPort, actualPort)
 
 	return nil
@@ -145,21 +270,22 @@ func (sm *ServiceManager) Stop() error {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	if sm.cmd != nil && sm.cmd.Process != nil {
-		if err := utils.KillProcess(sm.cmd.Process.Pid); err != nil {
-			sm.logger.Warn("Failed to kill process for %s: %v", sm.name, err)
+	if sm.tunnel != nil {
+		if err := sm.tunnel.Stop(); err != nil {
+			sm.logger.Warn("Failed to stop port-forward for %s: %v", sm.name, err)
 		}
-		sm.cmd = nil
+		sm.tunnel = nil
 	}
 
 	// Release reassigned port so others can use it
-	if sm.status.LocalPort != sm.config.LocalPort {
+	if sm.status.LocalPort != sm.configuredPort() {
 		utils.ReleasePort(sm.status.LocalPort)
 	}
 
 	sm.status.Status = "Stopped"
 	sm.status.PID = 0
-	sm.logger.Info("Stopped port-forward for %s", sm.name)
+	sm.status.Pod = ""
+	sm.logger.Event(utils.LevelInfo, "stopped", "Stopped port-forward for %s", sm.name)
 
 	return nil
 }
@@ -174,7 +300,7 @@ func (sm *ServiceManager) Restart() error {
 	}
 	defer sm.restarting.Store(false)
 
-	sm.logger.Info("Restarting service %s", sm.name)
+	sm.logger.Event(utils.LevelInfo, "restarting", "Restarting service %s", sm.name)
 
 	if err := sm.Stop(); err != nil {
 		sm.logger.Warn("Error stopping service %s during restart: %v", sm.name, err)
@@ -190,198 +316,388 @@ func (sm *ServiceManager) Restart() error {
 	return sm.Start()
 }
 
-// IsHealthy checks if the service is running and responding
-// This is a simplified version as the main health tracking logic is now in GetStatus
-// to avoid mutex deadlocks
+// IsHealthy checks if the service is running and responding, with retries.
+// It does not change the status; see EvaluateHealth for the state machine.
 func (sm *ServiceManager) IsHealthy() bool {
-	// Check if process is running
-	if sm.cmd == nil || sm.cmd.Process == nil {
+	sm.mutex.RLock()
+	tunnel := sm.tunnel
+	port := sm.status.LocalPort
+	activity := sm.activity
+	sm.mutex.RUnlock()
+
+	// Check if the port-forward is running
+	if tunnel == nil || !tunnel.Running() {
 		return false
 	}
 
-	if !utils.IsProcessRunning(sm.cmd.Process.Pid) {
+	// Check port connectivity with retries built into the CheckPortConnectivity function
+	if !utils.CheckPortConnectivity(port) {
 		return false
 	}
+	activity.probes.Add(1)
+	return true
+}
 
-	// Check port connectivity with retries built into the CheckPortConnectivity function
-	return utils.CheckPortConnectivity(sm.status.LocalPort)
+// Output returns the last lines kubectl wrote to stdout and stderr, oldest first
+func (sm *ServiceManager) Output() []string {
+	return sm.consoleOutput.Lines()
 }
 
-// GetStatus returns the current status of the service
+// probePort checks the local port with the service's healthCheck.protocol. Without one,
+// the port only has to accept a connection. Other probes reach the server, so their
+// duration is recorded for the UI.
+func (sm *ServiceManager) probePort(port int) bool {
+	healthCheck := sm.config.HealthCheck
+	if !healthCheck.HasLatencyProbe() {
+		if !sm.checkPort(port) {
+			sm.logger.Debug("Port connectivity check failed for %s on port %d", sm.name, port)
+			return false
+		}
+		return true
+	}
+
+	timeout := healthCheck.Timeout
+	if timeout == 0 {
+		timeout = probeTimeout
+	}
+	ctx, cancel := context.WithTimeout(sm.ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	var err error
+	switch healthCheck.Protocol {
+	case config.ProbeHTTP:
+		err = checkHTTP(ctx, port, healthCheck.Path)
+	case config.ProbeGRPC:
+		err = checkGRPCHealth(ctx, port, healthCheck.Path)
+		if errors.Is(err, errGRPCurlMissing) {
+			sm.logger.Debug("Cannot run the grpc health check of %s: %v; checking the port only", sm.name, err)
+			return sm.checkPort(port)
+		}
+	case config.ProbeExec:
+		err = sm.runProbeCommand(ctx, port)
+	default:
+		err = utils.ProbeProtocol(port, healthCheck.Protocol, timeout)
+	}
+	sm.recordProbe(time.Since(started), err)
+
+	if err != nil {
+		sm.logger.Debug("%s health check failed for %s on port %d: %v", healthCheck.Protocol, sm.name, port, err)
+		return false
+	}
+	return true
+}
+
+// checkPort checks that the local port accepts a connection within healthCheck.timeout
+func (sm *ServiceManager) checkPort(port int) bool {
+	if timeout := sm.config.HealthCheck.Timeout; timeout > 0 {
+		return utils.CheckPortConnectivityWithRetries(port, 1, 0, timeout)
+	}
+	return utils.CheckPortConnectivityQuick(port)
+}
+
+// runProbeCommand runs healthCheck.command with the service's local port in its environment
+func (sm *ServiceManager) runProbeCommand(ctx context.Context, port int) error {
+	env := append(os.Environ(),
+		"KPORTFORWARD_SERVICE="+sm.name,
+		fmt.Sprintf("KPORTFORWARD_LOCAL_PORT=%d", port),
+	)
+	output, err := runProbeShellCommand(ctx, sm.config.HealthCheck.Command, env)
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+	return nil
+}
+
+// recordProbe keeps the outcome of a protocol probe for the status
+func (sm *ServiceManager) recordProbe(latency time.Duration, err error) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.status.ProbeError = ""
+	if err != nil {
+		sm.status.ProbeError = err.Error()
+		latency = 0
+	} else {
+		sm.status.ProbeLatency = latency
+	}
+	sm.probeLatencies = append(sm.probeLatencies, latency)
+	if len(sm.probeLatencies) > probeHistorySize {
+		sm.probeLatencies = sm.probeLatencies[len(sm.probeLatencies)-probeHistorySize:]
+	}
+}
+
+// GetStatus returns a snapshot of the service status. It never probes the
+// service; health is evaluated separately by EvaluateHealth.
 func (sm *ServiceManager) GetStatus() config.ServiceStatus {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	status := *sm.status
+	status.KubectlOutput = sm.kubectlOutput.Lines()
+	status.ProbeLatencies = append([]time.Duration(nil), sm.probeLatencies...)
+	return status
+}
+
+// EvaluateHealth probes the port-forward and updates the status accordingly.
+// The probes run without holding the mutex so Stop and Restart are never
+// blocked behind a slow dial; results are discarded if the process changed
+// in the meantime.
+func (sm *ServiceManager) EvaluateHealth() {
+	sm.mutex.Lock()
+
+	// Only active services are health checked
+	if sm.status.Status != "Running" && sm.status.Status != "Degraded" &&
+		sm.status.Status != "Connecting" && sm.status.Status != "Reconnecting" {
+		sm.mutex.Unlock()
+		return
+	}
+
+	// Give service 5 seconds grace period after startup before health checking
+	gracePeriod := 5 * time.Second
+	healthCheckDue := time.Since(sm.lastHealthCheckTime) >= sm.healthCheckInterval()
+	if time.Since(sm.status.StartTime) <= gracePeriod || !healthCheckDue {
+		sm.mutex.Unlock()
+		return
+	}
+	sm.lastHealthCheckTime = time.Now()
+
+	healthCheckMode := sm.config.HealthCheckMode()
+	tunnel := sm.tunnel
+	port := sm.status.LocalPort
+	activity := sm.activity
+	waitForReady := sm.config.WaitForReady &&
+		(sm.status.Status == "Connecting" || sm.status.Status == "Reconnecting")
+	sm.mutex.Unlock()
+
+	// Check process running
+	isProcessRunning := tunnel != nil && tunnel.Running()
+
+	// Check port connectivity
+	isPortConnected := false
+	if isProcessRunning && healthCheckMode == config.HealthCheckOff {
+		// Only the process is checked for services that are expected to be unreachable
+		isPortConnected = true
+	} else if isProcessRunning {
+		isPortConnected = sm.probePort(port)
+		if isPortConnected {
+			activity.probes.Add(1)
+		}
+	}
+
+	var kubectlError string
+	if !isProcessRunning && activity != nil {
+		if line := activity.lastError.Load(); line != nil {
+			kubectlError = sm.describeKubectlError(*line)
+		}
+	}
+
+	// Probing a pod that is still starting only produces flapping failures
+	var readiness utils.PodReadiness
+	podReady := true
+	if waitForReady && isProcessRunning {
+		readiness, podReady = sm.checkPodReadiness()
+	}
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	// Make a copy of the status to return
-	statusCopy := *sm.status
-
-	// Update status based on health check, but allow grace period for startup
-	if sm.status.Status == "Running" || sm.status.Status == "Degraded" ||
-		sm.status.Status == "Connecting" || sm.status.Status == "Reconnecting" {
-		// Give service 5 seconds grace period after startup before health checking
-		gracePeriod := 5 * time.Second
-		if time.Since(sm.status.StartTime) > gracePeriod {
-			// This check doesn't call IsHealthy() directly to avoid deadlock
-			// as IsHealthy already acquires the lock
-
-			// Check process running
-			isProcessRunning := true
-			if sm.cmd == nil || sm.cmd.Process == nil || !utils.IsProcessRunning(sm.cmd.Process.Pid) {
-				isProcessRunning = false
-			}
+	// The service was stopped or restarted while probing; the result is stale
+	if sm.tunnel != tunnel {
+		return
+	}
 
-			// Check port connectivity - with retries built in
-			isPortConnected := false
-			if isProcessRunning {
-				isPortConnected = utils.CheckPortConnectivityQuick(sm.status.LocalPort)
-				if !isPortConnected {
-					sm.logger.Debug("Port connectivity check failed for %s on port %d", sm.name, sm.status.LocalPort)
-				}
-			}
+	if !podReady {
+		sm.status.StatusMessage = podNotReadyMessage(readiness)
+		return
+	}
 
-			// Update consecutive failures
-			isHealthy := isProcessRunning && isPortConnected
-
-			// Update consecutive failure counter
-			if isHealthy {
-				// Only consider it truly recovered if we have multiple successful checks
-				// This avoids flapping between Running/Failed for unstable connections
-				if sm.status.Status == "Failed" {
-					// For previously failed services, require 3 consecutive successful checks
-					// before marking as recovered (stay in Failed state during this period)
-					sm.consecutiveFailures--
-					if sm.consecutiveFailures <= 0 {
-						sm.logger.Info("Service %s confirmed recovered after multiple successful health checks",
-							sm.name)
-						sm.status.Status = "Running"
-						sm.status.LastError = ""
-						sm.status.StatusMessage = ""
-						sm.resetFailureCount() // Reset exponential backoff
-
-						// Update the copy we'll return
-						statusCopy = *sm.status
-					} else {
-						sm.logger.Debug("Service %s shows signs of recovery (%d more checks needed)",
-							sm.name, sm.consecutiveFailures)
-					}
-				} else if sm.status.Status == "Degraded" {
-					// For services that were in Degraded state but now passing health checks
-					sm.consecutiveFailures--
-					if sm.consecutiveFailures <= 0 {
-						sm.logger.Info("Service %s recovered from degraded state",
-							sm.name)
-						sm.status.Status = "Running"
-						sm.status.StatusMessage = ""
-						sm.status.LastError = ""
-
-						// Update the copy we'll return
-						statusCopy = *sm.status
-					}
-				} else if sm.status.Status == "Connecting" {
-					// For services that just completed initial connection
-					sm.logger.Info("Service %s successfully connected",
-						sm.name)
-					sm.status.Status = "Running"
-					sm.status.StatusMessage = ""
-					sm.status.LastError = ""
-
-					// Update the copy we'll return
-					statusCopy = *sm.status
-				} else if sm.status.Status == "Reconnecting" {
-					// For services that just completed reconnection
-					sm.logger.Info("Service %s successfully reconnected",
-						sm.name)
-					sm.status.Status = "Running"
-					sm.status.StatusMessage = ""
-					sm.status.LastError = ""
-
-					// Update the copy we'll return
-					statusCopy = *sm.status
-				} else {
-					// For services that are running normally
-					if sm.consecutiveFailures > 0 {
-						sm.logger.Debug("Health check recovered for %s after %d consecutive failures",
-							sm.name, sm.consecutiveFailures)
-					}
-					sm.consecutiveFailures = 0
-
-					// Always clear any lingering status messages when the service is healthy
-					if sm.status.StatusMessage != "" {
-						sm.logger.Debug("Clearing status message for %s: \"%s\"", sm.name, sm.status.StatusMessage)
-						sm.status.StatusMessage = ""
-						// Update the copy we'll return
-						statusCopy = *sm.status
-					}
-				}
+	// A successful probe went through the tunnel, so it doubles as a keepalive
+	if isPortConnected && healthCheckMode != config.HealthCheckOff {
+		sm.lastKeepaliveTime = time.Now()
+	}
+	if isProcessRunning && isPortConnected {
+		sm.status.LastHealthy = time.Now()
+	}
+
+	sm.applyHealthResult(healthCheckMode, isProcessRunning, isPortConnected)
+
+	// kubectl's own error says more than "process not running"
+	if sm.status.Status == "Failed" && kubectlError != "" {
+		sm.status.LastError = kubectlError
+	}
+}
+
+// applyHealthResult advances the status state machine with the outcome of one
+// health check. Callers must hold the mutex.
+func (sm *ServiceManager) applyHealthResult(healthCheckMode string, isProcessRunning, isPortConnected bool) {
+	// Update consecutive failures
+	isHealthy := isProcessRunning && isPortConnected
+
+	// Update consecutive failure counter
+	thresholds := sm.config.HealthCheck
+	if isHealthy {
+		sm.failedChecks = 0
+		// Only consider it truly recovered if we have multiple successful checks
+		// This avoids flapping between Running/Failed for unstable connections
+		if sm.status.Status == "Failed" {
+			// For previously failed services, require 3 consecutive successful checks
+			// before marking as recovered (stay in Failed state during this period)
+			sm.consecutiveFailures--
+			if sm.consecutiveFailures <= 0 {
+				sm.logger.Event(utils.LevelInfo, "recovered", "Service %s confirmed recovered after multiple successful health checks",
+					sm.name)
+				sm.status.Status = "Running"
+				sm.status.LastError = ""
+				sm.status.StatusMessage = ""
+				sm.resetFailureCount() // Reset exponential backoff
 			} else {
-				sm.consecutiveFailures++
-				sm.healthCheckFailures++
-
-				// Log why the health check failed (process or port)
-				if !isProcessRunning {
-					sm.logger.Debug("Health check failed for %s: process not running (PID %d)",
-						sm.name, sm.status.PID)
-				} else if !isPortConnected {
-					sm.logger.Debug("Health check failed for %s: port %d not responding (%d consecutive failures, %d total)",
-						sm.name, sm.status.LocalPort, sm.consecutiveFailures, sm.healthCheckFailures)
-				}
-
-				// On first health check failure, update status appropriately
-				// Handle each possible current state
-				if sm.status.Status == "Running" {
-					// Standard case - mark as Degraded
-					sm.status.Status = "Degraded"
-					sm.status.StatusMessage = "Port connectivity issues"
-					sm.logger.Warn("Service %s is degraded - health check failing on port %d",
-						sm.name, sm.status.LocalPort)
-
-					// Set the consecutive failures to 2 so it takes 2 successful checks to recover
-					sm.consecutiveFailures = 2
-
-					// Update the copy we'll return
-					statusCopy = *sm.status
-				} else if sm.status.Status == "Connecting" {
-					// For new connections, just leave as Connecting but update message
-					// This provides better feedback during initial connection phase
-					sm.status.StatusMessage = "Connection in progress..."
-
-					// Update the copy we'll return
-					statusCopy = *sm.status
-				} else if sm.status.Status == "Reconnecting" {
-					// For reconnections, just leave as Reconnecting but update message
-					sm.status.StatusMessage = "Reconnection in progress..."
-
-					// Update the copy we'll return
-					statusCopy = *sm.status
-				}
+				sm.logger.Debug("Service %s shows signs of recovery (%d more checks needed)",
+					sm.name, sm.consecutiveFailures)
+			}
+		} else if sm.status.Status == "Degraded" {
+			// For services that were in Degraded state but now passing health checks
+			sm.consecutiveFailures--
+			if sm.consecutiveFailures <= 0 {
+				sm.logger.Event(utils.LevelInfo, "recovered", "Service %s recovered from degraded state",
+					sm.name)
+				sm.status.Status = "Running"
+				sm.status.StatusMessage = ""
+				sm.status.LastError = ""
+			}
+		} else if sm.status.Status == "Connecting" {
+			// For services that just completed initial connection
+			sm.logger.Event(utils.LevelInfo, "connected", "Service %s successfully connected",
+				sm.name)
+			sm.status.Status = "Running"
+			sm.status.StatusMessage = ""
+			sm.status.LastError = ""
+		} else if sm.status.Status == "Reconnecting" {
+			// For services that just completed reconnection
+			sm.logger.Event(utils.LevelInfo, "reconnected", "Service %s successfully reconnected",
+				sm.name)
+			sm.status.Status = "Running"
+			sm.status.StatusMessage = ""
+			sm.status.LastError = ""
+		} else {
+			// For services that are running normally
+			if sm.consecutiveFailures > 0 {
+				sm.logger.Debug("Health check recovered for %s after %d consecutive failures",
+					sm.name, sm.consecutiveFailures)
 			}
+			sm.consecutiveFailures = 0
 
-			// Only mark as failed if we've exceeded the consecutive failure threshold
-			if !isHealthy && sm.consecutiveFailures >= sm.maxFailureThreshold && sm.status.Status != "Failed" {
-				// Set higher value to require more successful checks to recover
-				// This creates a hysteresis effect to prevent status flapping
-				sm.consecutiveFailures = 3
-
-				sm.status.Status = "Failed"
-				// Add more details about the failure reason
-				if !isProcessRunning {
-					sm.status.LastError = fmt.Sprintf("Process not running (PID %d)", sm.status.PID)
-				} else if !isPortConnected {
-					sm.status.LastError = fmt.Sprintf("Port %d not responding after multiple attempts", sm.status.LocalPort)
-				} else {
-					sm.status.LastError = fmt.Sprintf("Health check failed after %d consecutive failures", sm.consecutiveFailures)
-				}
-
-				sm.logger.Warn("Service %s marked as failed: %s", sm.name, sm.status.LastError)
-
-				// Update the copy we'll return
-				statusCopy = *sm.status
+			// Always clear any lingering status messages when the service is healthy
+			if sm.status.StatusMessage != "" {
+				sm.logger.Debug("Clearing status message for %s: \"%s\"", sm.name, sm.status.StatusMessage)
+				sm.status.StatusMessage = ""
 			}
-			// Note: Recovery is now handled in the isHealthy block above to require multiple successful checks
+		}
+	} else {
+		sm.consecutiveFailures++
+		sm.healthCheckFailures++
+		sm.failedChecks++
+
+		// Log why the health check failed (process or port)
+		if !isProcessRunning {
+			sm.logger.Debug("Health check failed for %s: process not running (PID %d)",
+				sm.name, sm.status.PID)
+		} else if !isPortConnected {
+			sm.logger.Debug("Health check failed for %s: port %d not responding (%d consecutive failures, %d total)",
+				sm.name, sm.status.LocalPort, sm.consecutiveFailures, sm.healthCheckFailures)
+		}
+
+		// On first health check failure, update status appropriately
+		// Handle each possible current state
+		if sm.status.Status == "Running" && healthCheckMode == config.HealthCheckLenient {
+			// Lenient services stay Running while intermittently unreachable
+			sm.status.StatusMessage = fmt.Sprintf("Port not responding (%d checks)", sm.consecutiveFailures)
+		} else if sm.status.Status == "Running" && (thresholds.HideDegraded || sm.failedChecks < max(thresholds.DegradedAfter, 1)) {
+			// Below degradedAfter, or Degraded is hidden: stay Running until Failed
+			sm.status.StatusMessage = fmt.Sprintf("Port not responding (%d checks)", sm.failedChecks)
+		} else if sm.status.Status == "Running" {
+			// Standard case - mark as Degraded
+			sm.status.Status = "Degraded"
+			sm.status.StatusMessage = "Port connectivity issues"
+			sm.logger.Event(utils.LevelWarn, "degraded", "Service %s is degraded - health check failing on port %d",
+				sm.name, sm.status.LocalPort)
+
+			// Set the consecutive failures to 2 so it takes 2 successful checks to recover
+			sm.consecutiveFailures = 2
+		} else if sm.status.Status == "Connecting" {
+			// For new connections, just leave as Connecting but update message
+			// This provides better feedback during initial connection phase
+			sm.status.StatusMessage = "Connection in progress..."
+		} else if sm.status.Status == "Reconnecting" {
+			// For reconnections, just leave as Reconnecting but update message
+			sm.status.StatusMessage = "Reconnection in progress..."
 		}
 	}
 
-	return statusCopy
+	// Only mark as failed if we've exceeded the consecutive failure threshold.
+	// Lenient services get more room as long as the process itself is alive.
+	// A configured failedAfter counts every failed check the same way instead.
+	failureThreshold := sm.maxFailureThreshold
+	if healthCheckMode == config.HealthCheckLenient && isProcessRunning {
+		failureThreshold = lenientFailureThreshold
+	}
+	failing := sm.consecutiveFailures >= failureThreshold
+	if thresholds.FailedAfter > 0 {
+		failing = sm.failedChecks >= thresholds.FailedAfter
+	}
+	if !isHealthy && failing && sm.status.Status != "Failed" {
+		// Set higher value to require more successful checks to recover
+		// This creates a hysteresis effect to prevent status flapping
+		sm.consecutiveFailures = 3
+
+		sm.status.Status = "Failed"
+		// Add more details about the failure reason
+		if !isProcessRunning && sm.status.PID == 0 {
+			// An in-process port-forward, see nativeTunnel
+			sm.status.LastError = "Port-forward not running"
+		} else if !isProcessRunning {
+			sm.status.LastError = fmt.Sprintf("Process not running (PID %d)", sm.status.PID)
+		} else if !isPortConnected {
+			sm.status.LastError = fmt.Sprintf("Port %d not responding after multiple attempts", sm.status.LocalPort)
+		} else {
+			sm.status.LastError = fmt.Sprintf("Health check failed after %d consecutive failures", sm.consecutiveFailures)
+		}
+
+		sm.logger.Event(utils.LevelWarn, "failed", "Service %s marked as failed: %s", sm.name, sm.status.LastError)
+	}
+}
+
+// LastActivity returns when a client other than our own health checks last
+// opened a connection through the port-forward
+func (sm *ServiceManager) LastActivity() time.Time {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	// kubectl logs every accepted connection, including our probes. A probe may be
+	// logged after it was counted, so only growth beyond the previous high-water
+	// mark is treated as real traffic.
+	external := sm.activity.handled.Load() - sm.activity.probes.Load()
+	if external > sm.externalHighWater {
+		sm.externalHighWater = external
+		sm.lastActivity = time.Now()
+	}
+
+	return sm.lastActivity
+}
+
+// markOutsideSchedule records that the service is down because its availability
+// window is closed. Callers must hold the mutex and have stopped the process.
+func (sm *ServiceManager) markOutsideSchedule() {
+	sm.status.Status = "Scheduled"
+	sm.status.StatusMessage = "Outside availability window"
+	if next := sm.config.NextScheduledStart(time.Now()); !next.IsZero() {
+		sm.status.StatusMessage = fmt.Sprintf("Outside availability window, resumes %s", next.Format("Mon 15:04"))
+	}
+	sm.status.LastError = ""
+	sm.status.PID = 0
+	sm.status.StartTime = time.Time{}
 }
 
 // SetStatusMessage sets a transient status message for the service
@@ -397,20 +713,68 @@ func (sm *ServiceManager) Shutdown() {
 	sm.Stop()
 }
 
-// resolvePort finds an available port, starting from the configured port
+// SetPortOffset moves the local port by offset from the next start on
+func (sm *ServiceManager) SetPortOffset(offset int) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.portOffset = offset
+	if sm.tunnel == nil {
+		sm.status.LocalPort = sm.configuredPort()
+	}
+}
+
+// configuredPort returns the configured local port moved by the port offset
+func (sm *ServiceManager) configuredPort() int {
+	return sm.config.LocalPort + sm.portOffset
+}
+
+// extraPorts returns the service's further port mappings, with their local ports offset
+func (sm *ServiceManager) extraPorts() []utils.PortPair {
+	var pairs []utils.PortPair
+	for _, mapping := range sm.config.Ports {
+		pairs = append(pairs, utils.PortPair{Local: mapping.Local + sm.portOffset, Target: mapping.Target})
+	}
+	return pairs
+}
+
+// localPorts returns the local ports the service uses or will use: its configured ones
+// moved by the port offset and the port it was moved to when that one was taken
+func (sm *ServiceManager) localPorts() []int {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	ports := []int{sm.configuredPort(), sm.status.LocalPort}
+	for _, pair := range sm.extraPorts() {
+		ports = append(ports, pair.Local)
+	}
+	return ports
+}
+
+// resolvePort finds an available port, starting from the configured port, and records
+// what holds the configured port if it is taken. Callers must hold the mutex.
 func (sm *ServiceManager) resolvePort() (int, error) {
-	if utils.IsPortAvailable(sm.config.LocalPort) {
-		return sm.config.LocalPort, nil
+	port := sm.configuredPort()
+	if err := config.ValidatePortOffset(sm.config.LocalPort, sm.portOffset); err != nil {
+		return 0, err
+	}
+	if utils.IsPortAvailable(port) {
+		sm.status.PortHolder = ""
+		return port, nil
+	}
+	if sm.config.StrictPort {
+		return 0, sm.portConflictError(port)
 	}
 
 	// Port is in use, find an alternative
-	newPort, err := utils.FindAvailablePortSafe(sm.config.LocalPort + 1)
+	sm.status.PortHolder = describePortHolder(port)
+	newPort, err := utils.FindAvailablePortSafe(port + 1)
 	if err != nil {
-		return 0, err
+		return 0, common.WithKind(common.ErrPortConflict, fmt.Errorf("port %d is in use by %s: %w", port, sm.status.PortHolder, err))
 	}
 
-	sm.logger.Warn("Port %d is in use for %s, using port %d instead",
-		sm.config.LocalPort, sm.name, newPort)
+	sm.logger.Event(utils.LevelWarn, "port_reassigned", "Port %d is in use for %s by %s, using port %d instead",
+		port, sm.name, sm.status.PortHolder, newPort)
 
 	return newPort, nil
 }
@@ -433,7 +797,7 @@ func (sm *ServiceManager) handleFailure() {
 	cooldownDuration := time.Duration(sm.backoffSeconds[backoffIndex]) * time.Second
 	sm.cooldownUntil = time.Now().Add(cooldownDuration)
 
-	sm.logger.Warn("Service %s failed %d times, entering cooldown for %v",
+	sm.logger.Event(utils.LevelWarn, "cooldown", "Service %s failed %d times, entering cooldown for %v",
 		sm.name, sm.failureCount, cooldownDuration)
 }
 
@@ -453,15 +817,5 @@ func (sm *ServiceManager) resetFailureCount() {
 
 // isAuthError detects authentication-related errors at the service level
 func (sm *ServiceManager) isAuthError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := strings.ToLower(err.Error())
-	return strings.Contains(errStr, "unauthorized") ||
-		strings.Contains(errStr, "authentication") ||
-		strings.Contains(errStr, "token") ||
-		strings.Contains(errStr, "credential") ||
-		strings.Contains(errStr, "forbidden") ||
-		strings.Contains(errStr, "invalid user") ||
-		strings.Contains(errStr, "access denied")
+	return common.IsAuth(err)
 }
@@ -0,0 +1,40 @@
+package portforward
+
+import (
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// staggerSleep is replaced in tests to avoid waiting between batches
+var staggerSleep = time.Sleep
+
+// startStaggered starts services in restartStagger.order, restartStagger.batchSize at a
+// time with restartStagger.interval in between, so the cluster and VPN gateways are not
+// hit by every port-forward at once
+func (m *Manager) startStaggered(services []*ServiceManager) {
+	var stagger config.RestartStaggerConfig
+	if m.config != nil {
+		stagger = m.config.RestartStagger
+	}
+	interval, batchSize := stagger.Settings()
+
+	byName := make(map[string]*ServiceManager, len(services))
+	configs := make(map[string]config.Service, len(services))
+	for _, sm := range services {
+		byName[sm.name] = sm
+		configs[sm.name] = sm.config
+	}
+
+	for i, name := range stagger.RestartOrder(configs) {
+		if i > 0 && i%batchSize == 0 {
+			staggerSleep(interval)
+		}
+		if m.isShuttingDown() {
+			return
+		}
+		if err := byName[name].Start(); err != nil {
+			m.logger.Error("Failed to start service %s in new context: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package portforward
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestStartStaggered tests that services are started in batches with a pause in between
+func TestStartStaggered(t *testing.T) {
+	original := staggerSleep
+	defer func() { staggerSleep = original }()
+
+	var sleeps []time.Duration
+	staggerSleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	manager := NewManager(&config.Config{RestartStagger: config.RestartStaggerConfig{
+		Interval: time.Second, BatchSize: 2,
+	}}, logger)
+
+	var services []*ServiceManager
+	for i := 0; i < 5; i++ {
+		sm := NewServiceManager(fmt.Sprintf("service-%d", i), config.Service{}, logger)
+		// Services in cooldown refuse to start without running kubectl
+		sm.cooldownUntil = time.Now().Add(time.Hour)
+		services = append(services, sm)
+	}
+
+	manager.startStaggered(services)
+	if len(sleeps) != 2 || sleeps[0] != time.Second {
+		t.Errorf("Expected two pauses of 1s between three batches, got %v", sleeps)
+	}
+	for _, sm := range services {
+		if status := sm.GetStatus(); status.Status != "Cooldown" {
+			t.Errorf("Expected %s to be started, got %s", sm.name, status.Status)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package portforward
+
+import (
+	"sync"
+	"time"
+)
+
+// kubectlOutputLines is how many lines of kubectl stderr each service keeps
+const kubectlOutputLines = 10
+
+// consoleOutputLines is how many lines of kubectl stdout and stderr each service keeps
+// for the output pane of the detail view
+const consoleOutputLines = 500
+
+// lineBuffer keeps the last lines kubectl wrote, across restarts, so the cause of a
+// failure is still visible after the process is gone
+type lineBuffer struct {
+	mutex sync.Mutex
+	size  int // Lines kept; 0 keeps kubectlOutputLines
+	lines []string
+	next  int // Index the next line is written to once the buffer is full
+}
+
+// Add appends a line, prefixed with the time, dropping the oldest one when full
+func (b *lineBuffer) Add(line string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	size := b.size
+	if size == 0 {
+		size = kubectlOutputLines
+	}
+	line = time.Now().Format("15:04:05") + " " + line
+	if len(b.lines) < size {
+		b.lines = append(b.lines, line)
+		return
+	}
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % size
+}
+
+// Lines returns a copy of the buffered lines, oldest first
+func (b *lineBuffer) Lines() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if len(b.lines) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(b.lines))
+	lines = append(lines, b.lines[b.next:]...)
+	return append(lines, b.lines[:b.next]...)
+}
@@ -0,0 +1,40 @@
+package portforward
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestLineBuffer tests that the buffer keeps the most recent lines in order
+func TestLineBuffer(t *testing.T) {
+	buffer := &lineBuffer{}
+	if lines := buffer.Lines(); lines != nil {
+		t.Errorf("Expected no lines, got %v", lines)
+	}
+
+	for i := 1; i <= kubectlOutputLines+3; i++ {
+		buffer.Add(fmt.Sprintf("line %d", i))
+	}
+
+	lines := buffer.Lines()
+	if len(lines) != kubectlOutputLines {
+		t.Fatalf("Expected %d lines, got %d", kubectlOutputLines, len(lines))
+	}
+	if !strings.HasSuffix(lines[0], " line 4") || !strings.HasSuffix(lines[len(lines)-1], fmt.Sprintf(" line %d", kubectlOutputLines+3)) {
+		t.Errorf("Expected lines 4 to %d, got %v", kubectlOutputLines+3, lines)
+	}
+}
+
+// TestGetStatusKubectlOutput tests that stderr lines are part of the status snapshot
+func TestGetStatusKubectlOutput(t *testing.T) {
+	sm := newUnreachableService(t, config.HealthCheckConfig{})
+	sm.kubectlOutput.Add(`error: unable to forward port because pod is not running. Current status=Pending`)
+
+	status := sm.GetStatus()
+	if len(status.KubectlOutput) != 1 || !strings.Contains(status.KubectlOutput[0], "pod is not running") {
+		t.Errorf("Expected kubectl output in status, got %v", status.KubectlOutput)
+	}
+}
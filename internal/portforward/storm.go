@@ -0,0 +1,125 @@
+package portforward
+
+import (
+	"time"
+)
+
+const (
+	// defaultStormThreshold is the number of services that may fail within the
+	// storm window before failures are attributed to the cluster or network
+	defaultStormThreshold = 3
+	defaultStormWindow    = 30 * time.Second
+
+	// stormRestartStagger spaces out restarts when leaving a storm so the
+	// recovering cluster is not hit by every port-forward at once
+	stormRestartStagger = 2 * time.Second
+)
+
+// stormSettings returns the configured threshold and window, or the defaults
+func (m *Manager) stormSettings() (int, time.Duration) {
+	threshold, window := defaultStormThreshold, defaultStormWindow
+	if m.config != nil {
+		if m.config.RestartStorm.Threshold > 0 {
+			threshold = m.config.RestartStorm.Threshold
+		}
+		if m.config.RestartStorm.Window > 0 {
+			window = m.config.RestartStorm.Window
+		}
+	}
+	return threshold, window
+}
+
+// recordServiceStatus tracks transitions into Failed and enters storm mode when
+// more services than the threshold fail within the window
+func (m *Manager) recordServiceStatus(name, status string) {
+	m.stormMutex.Lock()
+	defer m.stormMutex.Unlock()
+
+	if m.lastServiceStatus == nil {
+		m.lastServiceStatus = make(map[string]string)
+	}
+	previous := m.lastServiceStatus[name]
+	m.lastServiceStatus[name] = status
+
+	if status != "Failed" || previous == "Failed" {
+		return
+	}
+
+	threshold, window := m.stormSettings()
+	now := time.Now()
+	m.recentFailures = append(m.recentFailures, now)
+
+	// Drop failures that fell out of the window
+	recent := m.recentFailures[:0]
+	for _, failedAt := range m.recentFailures {
+		if now.Sub(failedAt) <= window {
+			recent = append(recent, failedAt)
+		}
+	}
+	m.recentFailures = recent
+
+	if !m.stormActive && len(m.recentFailures) > threshold {
+		m.stormActive = true
+		m.stormStarted = now
+		m.logger.Warn("%d services failed within %v - cluster connectivity degraded, pausing restarts",
+			len(m.recentFailures), window)
+	}
+}
+
+// inRestartStorm reports whether per-service restarts are currently paused
+func (m *Manager) inRestartStorm() bool {
+	m.stormMutex.Lock()
+	defer m.stormMutex.Unlock()
+	return m.stormActive
+}
+
+// endRestartStormIfRecovered restarts failed services one at a time once the storm
+// window has passed. It must only be called after the global probe succeeded.
+// Storm mode stays active until the staggered restarts are done so the regular
+// monitoring loop does not restart everything at once.
+func (m *Manager) endRestartStormIfRecovered() {
+	_, window := m.stormSettings()
+
+	m.stormMutex.Lock()
+	if !m.stormActive || m.stormResuming || time.Since(m.stormStarted) < window {
+		m.stormMutex.Unlock()
+		return
+	}
+	m.stormResuming = true
+	m.stormMutex.Unlock()
+
+	m.mutex.RLock()
+	failed := make([]*ServiceManager, 0, len(m.services))
+	for _, sm := range m.services {
+		sm.mutex.RLock()
+		if sm.status.Status == "Failed" {
+			failed = append(failed, sm)
+		}
+		sm.mutex.RUnlock()
+	}
+	m.mutex.RUnlock()
+
+	m.logger.Info("Cluster connectivity recovered, restarting %d failed services", len(failed))
+
+	go func() {
+		defer func() {
+			m.stormMutex.Lock()
+			m.stormActive = false
+			m.stormResuming = false
+			m.recentFailures = nil
+			m.stormMutex.Unlock()
+		}()
+
+		for i, sm := range failed {
+			if m.isShuttingDown() {
+				return
+			}
+			if i > 0 {
+				time.Sleep(stormRestartStagger)
+			}
+			if err := sm.Restart(); err != nil {
+				m.logger.Error("Failed to restart service %s after storm: %v", sm.name, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,73 @@
+package portforward
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestRestartStormDetection tests that many simultaneous failures pause restarts
+func TestRestartStormDetection(t *testing.T) {
+	cfg := &config.Config{
+		RestartStorm: config.RestartStormConfig{Threshold: 2, Window: time.Minute},
+	}
+	manager := NewManager(cfg, utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard))
+	manager.globalAccessHealthy = true
+
+	// Repeated Failed reports for the same service count once
+	manager.recordServiceStatus("svc-1", "Failed")
+	manager.recordServiceStatus("svc-1", "Failed")
+	manager.recordServiceStatus("svc-2", "Failed")
+	if manager.inRestartStorm() {
+		t.Fatal("Expected no storm at the threshold")
+	}
+	if got := manager.getGlobalStatusString(); got != "healthy" {
+		t.Errorf("Expected healthy global status, got %s", got)
+	}
+
+	manager.recordServiceStatus("svc-3", "Failed")
+	if !manager.inRestartStorm() {
+		t.Fatal("Expected storm once failures exceed the threshold")
+	}
+	if got := manager.getGlobalStatusString(); got != "degraded" {
+		t.Errorf("Expected degraded global status during storm, got %s", got)
+	}
+}
+
+// TestRestartStormWaitsForWindow tests that a storm is not lifted before the window passes
+func TestRestartStormWaitsForWindow(t *testing.T) {
+	cfg := &config.Config{
+		RestartStorm: config.RestartStormConfig{Threshold: 1, Window: time.Hour},
+	}
+	manager := NewManager(cfg, utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard))
+
+	for i := 1; i <= 2; i++ {
+		manager.recordServiceStatus(fmt.Sprintf("svc-%d", i), "Failed")
+	}
+	if !manager.inRestartStorm() {
+		t.Fatal("Expected storm to be active")
+	}
+
+	manager.endRestartStormIfRecovered()
+	if !manager.inRestartStorm() {
+		t.Error("Expected storm to stay active until the window has passed")
+	}
+
+	// Once the window has passed and there is nothing to restart, the storm ends
+	manager.stormMutex.Lock()
+	manager.stormStarted = time.Now().Add(-2 * time.Hour)
+	manager.stormMutex.Unlock()
+
+	manager.endRestartStormIfRecovered()
+	deadline := time.Now().Add(time.Second)
+	for manager.inRestartStorm() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if manager.inRestartStorm() {
+		t.Error("Expected storm to end after recovery")
+	}
+}
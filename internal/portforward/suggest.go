@@ -0,0 +1,44 @@
+package portforward
+
+import (
+	"context"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// suggestName is replaced in tests to avoid calling kubectl
+var suggestName = utils.SuggestName
+
+// describeKubectlError appends a "did you mean" hint to NotFound errors, since most of
+// them are typos in the namespace or target. Hints are cached per error so kubectl is
+// only asked once while a service keeps failing the same way.
+func (sm *ServiceManager) describeKubectlError(line string) string {
+	resource, name, ok := utils.ParseNotFound(line)
+	if !ok {
+		return line
+	}
+
+	sm.mutex.RLock()
+	cachedError, cachedHint := sm.notFoundError, sm.notFoundHint
+	sm.mutex.RUnlock()
+	if cachedError == line {
+		return line + cachedHint
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hint := ""
+	suggestion, err := suggestName(ctx, sm.config.Namespace, resource, name)
+	if err != nil {
+		sm.logger.Debug("Could not look up suggestions for %s: %v", sm.name, err)
+	} else if suggestion != "" {
+		hint = " - did you mean " + suggestion + "?"
+	}
+
+	sm.mutex.Lock()
+	sm.notFoundError, sm.notFoundHint = line, hint
+	sm.mutex.Unlock()
+	return line + hint
+}
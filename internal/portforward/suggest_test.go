@@ -0,0 +1,52 @@
+package portforward
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestNotFoundSuggestion tests that a typo in the target surfaces a "did you mean" hint
+func TestNotFoundSuggestion(t *testing.T) {
+	original := suggestName
+	defer func() { suggestName = original }()
+
+	lookups := 0
+	suggestName = func(ctx context.Context, namespace, resource, name string) (string, error) {
+		lookups++
+		if resource != "services" || name != "stagin-api" {
+			t.Errorf("Unexpected lookup for %s %q", resource, name)
+		}
+		return "staging-api", nil
+	}
+
+	// A kubectl process that already exited
+	cmd := exec.Command("go", "version")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("Cannot run helper process: %v", err)
+	}
+
+	sm := newUnreachableService(t, config.HealthCheckConfig{})
+	sm.tunnel = &kubectlTunnel{cmd: cmd}
+	line := `Error from server (NotFound): services "stagin-api" not found`
+	sm.activity.lastError.Store(&line)
+
+	for i := 0; i < sm.maxFailureThreshold; i++ {
+		sm.lastHealthCheckTime = sm.lastHealthCheckTime.AddDate(-1, 0, 0)
+		sm.EvaluateHealth()
+	}
+
+	status := sm.GetStatus()
+	if status.Status != "Failed" {
+		t.Fatalf("Expected Failed, got %s", status.Status)
+	}
+	if !strings.HasSuffix(status.LastError, "did you mean staging-api?") {
+		t.Errorf("Expected suggestion in error, got %q", status.LastError)
+	}
+	if lookups != 1 {
+		t.Errorf("Expected suggestions to be looked up once, got %d", lookups)
+	}
+}
@@ -0,0 +1,45 @@
+package portforward
+
+import (
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// timelineSize is the number of status changes kept per service
+const timelineSize = 50
+
+// recordStatusChange adds status to the timeline of a service if it entered a new status
+func (m *Manager) recordStatusChange(name string, status config.ServiceStatus, now time.Time) {
+	m.timelineMutex.Lock()
+	defer m.timelineMutex.Unlock()
+
+	timeline := m.timelines[name]
+	if len(timeline) > 0 && timeline[len(timeline)-1].Status == status.Status {
+		return
+	}
+	if m.timelines == nil {
+		m.timelines = make(map[string][]config.StatusChange)
+	}
+	timeline = append(timeline, config.StatusChange{Time: now, Status: status.Status, Error: status.LastError})
+	if len(timeline) > timelineSize {
+		timeline = timeline[len(timeline)-timelineSize:]
+	}
+	m.timelines[name] = timeline
+}
+
+// Timeline returns the status changes of a service this session, oldest first; only
+// the most recent ones are kept
+func (m *Manager) Timeline(name string) []config.StatusChange {
+	m.timelineMutex.Lock()
+	defer m.timelineMutex.Unlock()
+	return append([]config.StatusChange(nil), m.timelines[name]...)
+}
+
+// ServiceConfig returns the configuration of a running service
+func (m *Manager) ServiceConfig(name string) (config.Service, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	service, ok := m.serviceConfigs()[name]
+	return service, ok
+}
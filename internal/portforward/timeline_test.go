@@ -0,0 +1,35 @@
+package portforward
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestTimeline tests that only status changes are recorded and old ones are dropped
+func TestTimeline(t *testing.T) {
+	manager := NewManager(&config.Config{}, utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard))
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	manager.recordStatusChange("api", config.ServiceStatus{Status: "Running"}, start)
+	manager.recordStatusChange("api", config.ServiceStatus{Status: "Running"}, start.Add(time.Second))
+	manager.recordStatusChange("api", config.ServiceStatus{Status: "Failed", LastError: "connection refused"}, start.Add(2*time.Second))
+	timeline := manager.Timeline("api")
+	if len(timeline) != 2 || timeline[1].Status != "Failed" || timeline[1].Error != "connection refused" || !timeline[1].Time.Equal(start.Add(2*time.Second)) {
+		t.Fatalf("Expected Running then Failed, got %+v", timeline)
+	}
+
+	for i := 0; i < timelineSize*2; i++ {
+		status := "Running"
+		if i%2 == 0 {
+			status = "Reconnecting"
+		}
+		manager.recordStatusChange("api", config.ServiceStatus{Status: status}, start.Add(time.Duration(i)*time.Minute))
+	}
+	if timeline := manager.Timeline("api"); len(timeline) != timelineSize || timeline[len(timeline)-1].Status != "Running" {
+		t.Errorf("Expected the last %d changes, got %d", timelineSize, len(timeline))
+	}
+}
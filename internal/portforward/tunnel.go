@@ -0,0 +1,132 @@
+package portforward
+
+import (
+	"os/exec"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// tunnel is a running port-forward of a service: a kubectl process, or a client-go
+// port-forward with portForwardBackend: native
+type tunnel interface {
+	// PID is the ID of the kubectl process, or 0 for a port-forward run in-process
+	PID() int
+	// Running reports whether the port-forward has not exited
+	Running() bool
+	// Stop ends the port-forward
+	Stop() error
+}
+
+// endingTunnel is a tunnel that reports when it ended, so the service fails right away
+// instead of on the next health check
+type endingTunnel interface {
+	// Done is closed once the port-forward has ended
+	Done() <-chan struct{}
+}
+
+// kubectlTunnel is a kubectl port-forward process
+type kubectlTunnel struct {
+	cmd *exec.Cmd
+}
+
+// PID returns the process ID of kubectl
+func (t *kubectlTunnel) PID() int {
+	if t.cmd == nil || t.cmd.Process == nil {
+		return 0
+	}
+	return t.cmd.Process.Pid
+}
+
+// Running reports whether the kubectl process is still alive
+func (t *kubectlTunnel) Running() bool {
+	pid := t.PID()
+	return pid != 0 && utils.IsProcessRunning(pid)
+}
+
+// Stop kills the kubectl process
+func (t *kubectlTunnel) Stop() error {
+	pid := t.PID()
+	if pid == 0 {
+		return nil
+	}
+	return utils.KillProcess(pid)
+}
+
+// nativeTunnel is a port-forward run in-process with client-go
+type nativeTunnel struct {
+	*utils.NativePortForward
+}
+
+// PID returns 0 as there is no process of its own
+func (t nativeTunnel) PID() int {
+	return 0
+}
+
+// startTunnel starts the port-forward described by opts with the given backend
+func startTunnel(backend string, opts utils.PortForwardOptions, logger *utils.Logger, serviceName string) (tunnel, error) {
+	if backend == config.BackendNative {
+		forward, err := utils.StartNativePortForward(opts, logger, serviceName)
+		if err != nil {
+			return nil, err
+		}
+		return nativeTunnel{forward}, nil
+	}
+
+	cmd, err := utils.StartKubectlPortForwardWithOptions(opts, logger, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return &kubectlTunnel{cmd: cmd}, nil
+}
+
+// SetBackend selects how the port-forward runs from the next start on, see
+// config.Config.PortForwardBackend
+func (sm *ServiceManager) SetBackend(backend string) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.backend = backend
+}
+
+// SetTunnelEnded sets the function called when a port-forward ended on its own
+func (sm *ServiceManager) SetTunnelEnded(handler func(sm *ServiceManager)) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.tunnelEnded = handler
+}
+
+// watchTunnel fails the service as soon as t ends, unless it was stopped or replaced
+// in the meantime
+func (sm *ServiceManager) watchTunnel(t tunnel, done <-chan struct{}) {
+	select {
+	case <-done:
+	case <-sm.ctx.Done():
+		return
+	}
+
+	sm.mutex.RLock()
+	activity := sm.activity
+	sm.mutex.RUnlock()
+	lastError := "Port-forward not running"
+	if activity != nil {
+		if line := activity.lastError.Load(); line != nil {
+			lastError = sm.describeKubectlError(*line)
+		}
+	}
+
+	sm.mutex.Lock()
+	if sm.tunnel != t {
+		sm.mutex.Unlock()
+		return
+	}
+	sm.status.Status = "Failed"
+	sm.status.StatusMessage = ""
+	sm.status.LastError = lastError
+	handler := sm.tunnelEnded
+	sm.logger.Event(utils.LevelWarn, "failed", "Service %s marked as failed: %s", sm.name, lastError)
+	sm.mutex.Unlock()
+
+	if handler != nil {
+		handler(sm)
+	}
+}
@@ -0,0 +1,167 @@
+package portforward
+
+import (
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// fakeTunnel is an in-process tunnel that runs until stopped or ended
+type fakeTunnel struct {
+	running bool
+	stopped bool
+}
+
+func (t *fakeTunnel) PID() int      { return 0 }
+func (t *fakeTunnel) Running() bool { return t.running }
+func (t *fakeTunnel) Stop() error {
+	t.running, t.stopped = false, true
+	return nil
+}
+
+// TestEndedTunnelFailsService tests that a port-forward without a process of its own
+// fails the service once it ends, with kubectl's last error
+func TestEndedTunnelFailsService(t *testing.T) {
+	sm := newUnreachableService(t, config.HealthCheckConfig{})
+	sm.tunnel = &fakeTunnel{}
+	sm.status.PID = 0
+	line := "error: lost connection to pod"
+	sm.activity.lastError.Store(&line)
+
+	for i := 0; i < sm.maxFailureThreshold; i++ {
+		sm.lastHealthCheckTime = sm.lastHealthCheckTime.AddDate(-1, 0, 0)
+		sm.EvaluateHealth()
+	}
+
+	status := sm.GetStatus()
+	if status.Status != "Failed" {
+		t.Fatalf("Expected Failed, got %s", status.Status)
+	}
+	if status.LastError != line {
+		t.Errorf("Expected the port-forward's error, got %q", status.LastError)
+	}
+}
+
+// TestEndedTunnelFailsServiceImmediately tests that a port-forward reporting its end
+// fails the service without waiting for a health check
+func TestEndedTunnelFailsServiceImmediately(t *testing.T) {
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	sm := NewServiceManager("api", config.Service{LocalPort: 8080}, logger)
+	defer sm.cancel()
+	tunnel := &fakeTunnel{running: true}
+	done := make(chan struct{})
+	sm.tunnel = tunnel
+	sm.activity = &connectionActivity{}
+	sm.status.Status = "Running"
+	ended := make(chan *ServiceManager, 1)
+	sm.SetTunnelEnded(func(sm *ServiceManager) { ended <- sm })
+	go sm.watchTunnel(tunnel, done)
+
+	line := "error: lost connection to pod"
+	sm.activity.lastError.Store(&line)
+	tunnel.running = false
+	close(done)
+
+	select {
+	case <-ended:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the manager to be told that the port-forward ended")
+	}
+	status := sm.GetStatus()
+	if status.Status != "Failed" {
+		t.Fatalf("Expected Failed, got %s", status.Status)
+	}
+	if status.LastError != line {
+		t.Errorf("Expected the port-forward's error, got %q", status.LastError)
+	}
+}
+
+// TestStoppedTunnelKeepsStatus tests that ending a port-forward on purpose does not
+// fail the service
+func TestStoppedTunnelKeepsStatus(t *testing.T) {
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	sm := NewServiceManager("api", config.Service{LocalPort: 8080}, logger)
+	defer sm.cancel()
+	tunnel := &fakeTunnel{running: true}
+	done := make(chan struct{})
+	sm.tunnel = tunnel
+	watched := make(chan struct{})
+	go func() {
+		sm.watchTunnel(tunnel, done)
+		close(watched)
+	}()
+
+	if err := sm.Stop(); err != nil {
+		t.Fatalf("Failed to stop: %v", err)
+	}
+	close(done)
+	<-watched
+
+	if status := sm.GetStatus(); status.Status != "Stopped" {
+		t.Errorf("Expected Stopped, got %s", status.Status)
+	}
+}
+
+// TestStopStopsTunnel tests that stopping a service ends its port-forward
+func TestStopStopsTunnel(t *testing.T) {
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	sm := NewServiceManager("api", config.Service{LocalPort: 8080}, logger)
+	tunnel := &fakeTunnel{running: true}
+	sm.tunnel = tunnel
+
+	if err := sm.Stop(); err != nil {
+		t.Fatalf("Failed to stop: %v", err)
+	}
+	if !tunnel.stopped {
+		t.Error("Expected the port-forward to be stopped")
+	}
+	if sm.tunnel != nil {
+		t.Error("Expected the service to forget the stopped port-forward")
+	}
+}
+
+// TestChaosStopsInProcessTunnel tests that chaos also ends port-forwards without a process
+func TestChaosStopsInProcessTunnel(t *testing.T) {
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	manager := NewManager(&config.Config{PortForwards: map[string]config.Service{}}, logger)
+	defer manager.cancel()
+
+	sm := NewServiceManager("victim", config.Service{}, logger)
+	tunnel := &fakeTunnel{running: true}
+	sm.tunnel = tunnel
+	manager.services["victim"] = sm
+
+	manager.killRandomService(rand.New(rand.NewSource(1)))
+	if !tunnel.stopped {
+		t.Error("Expected chaos to stop the port-forward")
+	}
+}
+
+// TestReloadConfigSetsBackend tests that a reload switches the backend of all services,
+// from their next start on
+func TestReloadConfigSetsBackend(t *testing.T) {
+	cfg := &config.Config{PortForwards: map[string]config.Service{
+		"api": {Target: "service/api", TargetPort: 80, LocalPort: 8080},
+	}}
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	manager := NewManager(cfg, logger)
+	defer manager.cancel()
+	manager.services["api"] = NewServiceManager("api", cfg.PortForwards["api"], logger)
+	// Idle managers do not start new services, so no kubectl is needed
+	manager.idle = true
+
+	err := manager.ReloadConfig(&config.Config{
+		PortForwards:       cfg.PortForwards,
+		PortForwardBackend: config.BackendNative,
+	})
+	if err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if backend := manager.services["api"].backend; backend != config.BackendNative {
+		t.Errorf("Expected the native backend, got %q", backend)
+	}
+}
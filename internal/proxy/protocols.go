@@ -0,0 +1,176 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// SOCKS5 protocol values from RFC 1928
+const (
+	socksVersion         = 0x05
+	socksNoAuth          = 0x00
+	socksNoAcceptable    = 0xff
+	socksConnect         = 0x01
+	socksAddrIPv4        = 0x01
+	socksAddrDomain      = 0x03
+	socksAddrIPv6        = 0x04
+	socksSucceeded       = 0x00
+	socksHostUnreachable = 0x04
+	socksCmdUnsupported  = 0x07
+	socksAddrUnsupported = 0x08
+)
+
+// serveSOCKS serves a SOCKS5 client without authentication. Only CONNECT is supported.
+func (s *Server) serveSOCKS(client *bufferedConn) {
+	// Greeting: version, number of methods, methods
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(client, header); err != nil {
+		return
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(client, methods); err != nil {
+		return
+	}
+	if !slices.Contains(methods, socksNoAuth) {
+		_, _ = client.Write([]byte{socksVersion, socksNoAcceptable})
+		return
+	}
+	if _, err := client.Write([]byte{socksVersion, socksNoAuth}); err != nil {
+		return
+	}
+
+	// Request: version, command, reserved, address type, address, port
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(client, request); err != nil {
+		return
+	}
+	var host string
+	switch request[3] {
+	case socksAddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(client, length); err != nil {
+			return
+		}
+		name := make([]byte, length[0])
+		if _, err := io.ReadFull(client, name); err != nil {
+			return
+		}
+		host = string(name)
+	case socksAddrIPv4, socksAddrIPv6:
+		size := net.IPv4len
+		if request[3] == socksAddrIPv6 {
+			size = net.IPv6len
+		}
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(client, ip); err != nil {
+			return
+		}
+		host = net.IP(ip).String()
+	default:
+		_ = socksReply(client, socksAddrUnsupported)
+		return
+	}
+	portBytes := make([]byte, 2)
+	if _, err := io.ReadFull(client, portBytes); err != nil {
+		return
+	}
+	if request[1] != socksConnect {
+		_ = socksReply(client, socksCmdUnsupported)
+		return
+	}
+
+	service, release, err := s.dial(host, int(binary.BigEndian.Uint16(portBytes)))
+	if err != nil {
+		_ = socksReply(client, socksHostUnreachable)
+		return
+	}
+	defer release()
+	if socksReply(client, socksSucceeded) != nil {
+		_ = service.Close()
+		return
+	}
+	pipe(client, service)
+}
+
+// socksReply sends the reply to a request with a zero bound address
+func socksReply(client io.Writer, reply byte) error {
+	_, err := client.Write([]byte{socksVersion, reply, 0x00, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// serveHTTP serves an HTTP proxy client: CONNECT tunnels, and plain requests with an
+// absolute URL, which are sent with Connection: close
+func (s *Server) serveHTTP(client *bufferedConn) {
+	request, err := http.ReadRequest(client.Reader)
+	if err != nil {
+		return
+	}
+
+	address := request.Host
+	if request.Method != http.MethodConnect {
+		if !request.URL.IsAbs() {
+			httpError(client, http.StatusBadRequest, "kportforward is a proxy; request an absolute URL")
+			return
+		}
+		address = request.URL.Host
+	}
+	host, port, err := splitHostPort(address, request.URL.Scheme)
+	if err != nil {
+		httpError(client, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	service, release, err := s.dial(host, port)
+	if err != nil {
+		httpError(client, http.StatusBadGateway, err.Error())
+		return
+	}
+	defer release()
+
+	if request.Method == http.MethodConnect {
+		if _, err := io.WriteString(client, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+			_ = service.Close()
+			return
+		}
+		pipe(client, service)
+		return
+	}
+
+	request.Header.Del("Proxy-Connection")
+	request.Header.Del("Proxy-Authorization")
+	request.Close = true
+	if err := request.Write(service); err != nil {
+		_ = service.Close()
+		httpError(client, http.StatusBadGateway, err.Error())
+		return
+	}
+	pipe(client, service)
+}
+
+// splitHostPort splits a request's host, defaulting the port by scheme
+func splitHostPort(address, scheme string) (string, int, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		// No port in the address
+		host, port = address, "80"
+		if scheme == "https" {
+			port = "443"
+		}
+	}
+	number, err := strconv.Atoi(port)
+	if err != nil || number <= 0 || number > 65535 {
+		return "", 0, fmt.Errorf("invalid port in %q", address)
+	}
+	return host, number, nil
+}
+
+// httpError answers an HTTP proxy client with status and message
+func httpError(client io.Writer, status int, message string) {
+	_, _ = fmt.Fprintf(client, "HTTP/1.1 %d %s\r\nContent-Type: text/plain\r\nContent-Length: %d\r\nConnection: close\r\n\r\n%s",
+		status, http.StatusText(status), len(message)+1, message+"\n")
+}
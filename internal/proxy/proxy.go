@@ -0,0 +1,466 @@
+// Package proxy serves a local SOCKS5 and HTTP proxy that reaches cluster services by
+// their DNS names, so they need not be declared in the config. Each service gets a
+// kubectl port-forward on first use, stopped again after it has been idle for a while.
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+const (
+	// idleTimeout is how long a port-forward without connections is kept
+	idleTimeout = 10 * time.Minute
+
+	// reapInterval is how often idle port-forwards are looked for
+	reapInterval = time.Minute
+
+	// forwardTimeout is how long kubectl may take to start listening
+	forwardTimeout = 15 * time.Second
+
+	// dialTimeout limits connecting to a started port-forward
+	dialTimeout = 5 * time.Second
+)
+
+// Status describes the proxy for the TUI
+type Status struct {
+	Address     string
+	Forwards    []string // Services with a port-forward, as service.namespace:port
+	Connections int64    // Open client connections
+	LastError   string
+}
+
+// target is a port of a cluster service
+type target struct {
+	service   string
+	namespace string
+	port      int
+}
+
+func (t target) String() string {
+	return fmt.Sprintf("%s.%s:%d", t.service, t.namespace, t.port)
+}
+
+// forward is a port-forward started for the proxy
+type forward struct {
+	port     int    // Local port kubectl listens on
+	stop     func() // Kills kubectl
+	active   int    // Open connections through the forward
+	lastUsed time.Time
+}
+
+// startLock serializes starting the port-forward of one target
+type startLock struct {
+	sync.Mutex
+	users int // Connections holding or waiting for the lock
+}
+
+// Server is the proxy. It is safe for concurrent use.
+type Server struct {
+	address  string
+	logger   *utils.Logger
+	listener net.Listener
+	cancel   context.CancelFunc
+
+	mutex     sync.Mutex
+	forwards  map[target]*forward
+	starting  map[target]*startLock // Held while starting the port-forward of a target
+	lastError string
+
+	connections atomic.Int64
+
+	// insecureExpose allows listening on an address reachable from the network
+	insecureExpose bool
+}
+
+// NewServer creates a proxy listening on address once started
+func NewServer(address string, logger *utils.Logger) *Server {
+	return &Server{
+		address:  address,
+		logger:   logger,
+		forwards: make(map[target]*forward),
+		starting: make(map[target]*startLock),
+	}
+}
+
+// SetInsecureExpose allows the proxy to listen on an address reachable from the network,
+// see CheckAddress
+func (s *Server) SetInsecureExpose(allow bool) {
+	s.insecureExpose = allow
+}
+
+// CheckAddress rejects proxy addresses other machines can connect to, such as :1080 or
+// 0.0.0.0:1080, unless insecureExpose acknowledges that anyone reaching them gets
+// unauthenticated access to the cluster
+func CheckAddress(address string, insecureExpose bool) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid proxy address %s: %w", address, err)
+	}
+	// An empty host listens on all interfaces, unlike an empty bindAddress
+	if host != "" && utils.IsLoopbackAddress(host) || insecureExpose {
+		return nil
+	}
+	return fmt.Errorf("proxy address %s is reachable from the network and gives anyone there access to the cluster; use localhost or pass --insecure-proxy", address)
+}
+
+// Start listens on the proxy's address and serves clients until Stop
+func (s *Server) Start() error {
+	if err := CheckAddress(s.address, s.insecureExpose); err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.address, err)
+	}
+	if CheckAddress(s.address, false) != nil {
+		s.logger.Warn("The proxy on %s lets anyone on the network reach the cluster", s.address)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.listener, s.cancel = listener, cancel
+	go s.reapIdle(ctx)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Warn("Proxy stopped accepting connections: %v", err)
+				}
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+	s.logger.Info("Serving the cluster proxy on %s", listener.Addr())
+	return nil
+}
+
+// Stop closes the listener and stops every port-forward. Open connections end with them.
+func (s *Server) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+		_ = s.listener.Close()
+	}
+	s.Reset()
+}
+
+// Reset stops every port-forward, so the next connections reach the current context
+func (s *Server) Reset() {
+	s.mutex.Lock()
+	forwards := s.forwards
+	s.forwards = make(map[target]*forward)
+	s.mutex.Unlock()
+
+	for _, fw := range forwards {
+		fw.stop()
+	}
+}
+
+// Addr returns the address the proxy listens on, with the port chosen for port 0
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.address
+	}
+	return s.listener.Addr().String()
+}
+
+// Status returns the proxy's address, port-forwards and open connections
+func (s *Server) Status() Status {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	status := Status{
+		Address:     s.Addr(),
+		Connections: s.connections.Load(),
+		LastError:   s.lastError,
+	}
+	for t := range s.forwards {
+		status.Forwards = append(status.Forwards, t.String())
+	}
+	slices.Sort(status.Forwards)
+	return status
+}
+
+// handle serves one client, telling SOCKS5 from HTTP by the first byte
+func (s *Server) handle(conn net.Conn) {
+	s.connections.Add(1)
+	defer s.connections.Add(-1)
+	defer conn.Close()
+
+	client := &bufferedConn{Reader: bufio.NewReader(conn), Conn: conn}
+	first, err := client.Peek(1)
+	if err != nil {
+		return
+	}
+	if first[0] == socksVersion {
+		s.serveSOCKS(client)
+	} else {
+		s.serveHTTP(client)
+	}
+}
+
+// dial connects to a port of a cluster service through its port-forward, starting the
+// port-forward first if needed. release must be called once the connection is closed.
+func (s *Server) dial(host string, port int) (conn net.Conn, release func(), err error) {
+	defer func() {
+		if err != nil {
+			s.setError(err)
+		}
+	}()
+
+	t, err := parseTarget(host, port)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fw, err := s.acquire(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err = net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(fw.port)), dialTimeout)
+	if err != nil {
+		// kubectl has probably exited, start it again on the next connection
+		s.remove(t, fw)
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", t, err)
+	}
+	return conn, func() { s.releaseForward(fw) }, nil
+}
+
+// acquire returns the port-forward of t, starting it if needed, and counts a connection
+// through it
+func (s *Server) acquire(t target) (*forward, error) {
+	if fw := s.use(t); fw != nil {
+		return fw, nil
+	}
+
+	// Starting is serialized per target so a burst of connections to a new service
+	// starts kubectl once, without holding mutex or delaying other services while
+	// kubectl starts
+	defer s.lockStart(t)()
+	if fw := s.use(t); fw != nil {
+		return fw, nil
+	}
+
+	s.logger.Info("Proxy: starting a port-forward to %s", t)
+	fw, err := startPortForward(t, s.logger)
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.forwards[t] = fw
+	fw.active++
+	fw.lastUsed = time.Now()
+	return fw, nil
+}
+
+// lockStart locks starting the port-forward of t and returns the function unlocking it
+func (s *Server) lockStart(t target) (unlock func()) {
+	s.mutex.Lock()
+	lock := s.starting[t]
+	if lock == nil {
+		lock = &startLock{}
+		s.starting[t] = lock
+	}
+	lock.users++
+	s.mutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if lock.users--; lock.users == 0 {
+			delete(s.starting, t)
+		}
+	}
+}
+
+// use counts a connection through the port-forward of t and returns it, or nil if t has none
+func (s *Server) use(t target) *forward {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	fw := s.forwards[t]
+	if fw != nil {
+		fw.active++
+		fw.lastUsed = time.Now()
+	}
+	return fw
+}
+
+// releaseForward counts a closed connection through fw
+func (s *Server) releaseForward(fw *forward) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	fw.active--
+	fw.lastUsed = time.Now()
+}
+
+// remove stops fw and forgets it if it still is the port-forward of t
+func (s *Server) remove(t target, fw *forward) {
+	s.mutex.Lock()
+	if s.forwards[t] == fw {
+		delete(s.forwards, t)
+	}
+	fw.active--
+	s.mutex.Unlock()
+	fw.stop()
+}
+
+// reapIdle stops port-forwards without connections for idleTimeout until ctx ends
+func (s *Server) reapIdle(ctx context.Context) {
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.stopIdle(now)
+		}
+	}
+}
+
+// stopIdle stops port-forwards that have been idle for idleTimeout at now
+func (s *Server) stopIdle(now time.Time) {
+	s.mutex.Lock()
+	var idle []*forward
+	for t, fw := range s.forwards {
+		if fw.active == 0 && now.Sub(fw.lastUsed) >= idleTimeout {
+			s.logger.Debug("Proxy: stopping the idle port-forward to %s", t)
+			delete(s.forwards, t)
+			idle = append(idle, fw)
+		}
+	}
+	s.mutex.Unlock()
+
+	for _, fw := range idle {
+		fw.stop()
+	}
+}
+
+// setError records the last failed connection for the TUI
+func (s *Server) setError(err error) {
+	s.logger.Warn("Proxy: %v", err)
+	s.mutex.Lock()
+	s.lastError = err.Error()
+	s.mutex.Unlock()
+}
+
+// parseTarget resolves a cluster DNS name: service, service.namespace or
+// service.namespace.svc with any cluster domain. A bare service is in the default namespace.
+func parseTarget(host string, port int) (target, error) {
+	host = strings.TrimSuffix(host, ".")
+	if net.ParseIP(host) != nil {
+		return target{}, fmt.Errorf("%s is an IP address; connect by the service's DNS name", host)
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) > 2 && labels[2] != "svc" || slices.Contains(labels, "") {
+		return target{}, fmt.Errorf("%s is not the DNS name of a cluster service", host)
+	}
+	t := target{service: labels[0], namespace: "default", port: port}
+	if len(labels) > 1 {
+		t.namespace = labels[1]
+	}
+	return t, nil
+}
+
+// startPortForward starts kubectl forwarding a free local port to t and waits until it
+// listens. It is replaced in tests.
+var startPortForward = func(t target, logger *utils.Logger) (*forward, error) {
+	ports := make(chan int, 1)
+	errs := make(chan string, 1)
+	cmd, err := utils.StartKubectlPortForwardWithOptions(utils.PortForwardOptions{
+		Namespace:  t.namespace,
+		Target:     "service/" + t.service,
+		LocalPort:  0, // kubectl picks a free port and reports it
+		TargetPort: t.port,
+		OnOutput: func(line string, isErr bool) {
+			if port, ok := forwardingPort(line); ok {
+				select {
+				case ports <- port:
+				default:
+				}
+			} else if isErr && strings.HasPrefix(strings.ToLower(line), "error") {
+				select {
+				case errs <- line:
+				default:
+				}
+			}
+		},
+	}, logger, "proxy "+t.String())
+	if err != nil {
+		return nil, err
+	}
+	stop := func() {
+		if err := utils.KillProcess(cmd.Process.Pid); err != nil {
+			logger.Debug("Failed to kill the proxy port-forward to %s: %v", t, err)
+		}
+	}
+
+	select {
+	case port := <-ports:
+		return &forward{port: port, stop: stop}, nil
+	case line := <-errs:
+		stop()
+		return nil, fmt.Errorf("port-forward to %s failed: %s", t, line)
+	case <-time.After(forwardTimeout):
+		stop()
+		return nil, fmt.Errorf("port-forward to %s did not start within %s", t, forwardTimeout)
+	}
+}
+
+// forwardingPort returns the local port of kubectl's "Forwarding from 127.0.0.1:port -> ..." line
+func forwardingPort(line string) (int, bool) {
+	address, found := strings.CutPrefix(line, "Forwarding from ")
+	if !found {
+		return 0, false
+	}
+	address, _, _ = strings.Cut(address, " ")
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return 0, false
+	}
+	number, err := strconv.Atoi(port)
+	return number, err == nil && number > 0
+}
+
+// bufferedConn reads a connection through the reader used to tell its protocol
+type bufferedConn struct {
+	*bufio.Reader
+	net.Conn
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.Reader.Read(p)
+}
+
+// pipe copies between the client and the service until either side closes
+func pipe(client io.ReadWriteCloser, service net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(service, client)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(client, service)
+		done <- struct{}{}
+	}()
+	<-done
+	_ = client.Close()
+	_ = service.Close()
+	<-done
+}
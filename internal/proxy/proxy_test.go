@@ -0,0 +1,351 @@
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// TestCheckAddress tests that the proxy only listens where other machines cannot reach
+// it unless that is explicitly allowed
+func TestCheckAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		insecure bool
+		allowed  bool
+	}{
+		{"localhost:1080", false, true},
+		{"127.0.0.1:1080", false, true},
+		{"[::1]:1080", false, true},
+		{":1080", false, false},
+		{"0.0.0.0:1080", false, false},
+		{"192.168.1.20:1080", false, false},
+		{":1080", true, true},
+		{"1080", true, false},
+	}
+
+	for _, tt := range tests {
+		err := CheckAddress(tt.address, tt.insecure)
+		if (err == nil) != tt.allowed {
+			t.Errorf("CheckAddress(%q, %v) = %v, expected allowed %v", tt.address, tt.insecure, err, tt.allowed)
+		}
+	}
+
+	server := NewServer(":0", utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard))
+	if err := server.Start(); err == nil {
+		server.Stop()
+		t.Error("Expected the proxy to refuse listening on all interfaces")
+	}
+}
+
+// TestParseTarget tests resolving cluster DNS names
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"api", "api.default:80"},
+		{"api.team", "api.team:80"},
+		{"api.team.svc", "api.team:80"},
+		{"api.team.svc.cluster.local.", "api.team:80"},
+		{"api.example.com", ""},
+		{"api..svc", ""},
+		{"10.0.0.1", ""},
+	}
+
+	for _, tt := range tests {
+		target, err := parseTarget(tt.host, 80)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("Expected an error for %q, got %s", tt.host, target)
+			}
+			continue
+		}
+		if err != nil || target.String() != tt.want {
+			t.Errorf("parseTarget(%q) = %s, %v; want %s", tt.host, target, err, tt.want)
+		}
+	}
+}
+
+// TestForwardingPort tests reading the local port from kubectl's output
+func TestForwardingPort(t *testing.T) {
+	if port, ok := forwardingPort("Forwarding from 127.0.0.1:54321 -> 8080"); !ok || port != 54321 {
+		t.Errorf("Expected port 54321, got %d, %v", port, ok)
+	}
+	if port, ok := forwardingPort("Forwarding from [::1]:54321 -> 8080"); !ok || port != 54321 {
+		t.Errorf("Expected port 54321 for IPv6, got %d, %v", port, ok)
+	}
+	if _, ok := forwardingPort("Handling connection for 54321"); ok {
+		t.Error("Expected no port for other output")
+	}
+}
+
+// fakeForwards replaces startPortForward with port-forwards to backend and counts the
+// started and stopped ones
+func fakeForwards(t *testing.T, backend string) (started, stopped *atomic.Int32) {
+	t.Helper()
+	_, portString, err := net.SplitHostPort(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(portString)
+
+	started, stopped = &atomic.Int32{}, &atomic.Int32{}
+	original := startPortForward
+	t.Cleanup(func() { startPortForward = original })
+	startPortForward = func(target target, logger *utils.Logger) (*forward, error) {
+		if target.service == "missing" {
+			return nil, fmt.Errorf("services %q not found", target.service)
+		}
+		started.Add(1)
+		return &forward{port: port, stop: func() { stopped.Add(1) }}, nil
+	}
+	return started, stopped
+}
+
+// startProxy starts a proxy on a free port, stopped when the test ends
+func startProxy(t *testing.T) *Server {
+	t.Helper()
+	server := NewServer("127.0.0.1:0", utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard))
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+	return server
+}
+
+// startEcho starts a TCP server echoing every connection and returns its address
+func startEcho(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// dialSOCKS connects to host:port through the SOCKS5 proxy at address and returns
+// the connection and the proxy's reply code
+func dialSOCKS(t *testing.T, address, host string, port int) (net.Conn, byte) {
+	t.Helper()
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	greeting := make([]byte, 2)
+	if _, err := conn.Write([]byte{socksVersion, 1, socksNoAuth}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(conn, greeting); err != nil || greeting[1] != socksNoAuth {
+		t.Fatalf("Expected no authentication, got %v, %v", greeting, err)
+	}
+
+	request := []byte{socksVersion, socksConnect, 0, socksAddrDomain, byte(len(host))}
+	request = append(request, host...)
+	request = append(request, byte(port>>8), byte(port))
+	if _, err := conn.Write(request); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, 10)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		t.Fatal(err)
+	}
+	return conn, reply[1]
+}
+
+// TestSOCKSProxy tests connecting to services through SOCKS5, sharing their port-forwards
+func TestSOCKSProxy(t *testing.T) {
+	started, _ := fakeForwards(t, startEcho(t))
+	server := startProxy(t)
+
+	for i := 0; i < 2; i++ {
+		conn, reply := dialSOCKS(t, server.Addr(), "api.team.svc.cluster.local", 8080)
+		if reply != socksSucceeded {
+			t.Fatalf("Expected the connection to succeed, got reply %d", reply)
+		}
+		if _, err := conn.Write([]byte("ping")); err != nil {
+			t.Fatal(err)
+		}
+		echo := make([]byte, 4)
+		if _, err := io.ReadFull(conn, echo); err != nil || string(echo) != "ping" {
+			t.Fatalf("Expected the echo server's reply, got %q, %v", echo, err)
+		}
+	}
+	if started.Load() != 1 {
+		t.Errorf("Expected one port-forward for both connections, got %d", started.Load())
+	}
+	status := server.Status()
+	if len(status.Forwards) != 1 || status.Forwards[0] != "api.team:8080" {
+		t.Errorf("Expected the port-forward in the status, got %v", status.Forwards)
+	}
+
+	if _, reply := dialSOCKS(t, server.Addr(), "missing.team", 80); reply != socksHostUnreachable {
+		t.Errorf("Expected host unreachable for a missing service, got reply %d", reply)
+	}
+	if status := server.Status(); status.LastError == "" {
+		t.Error("Expected the failed connection in the status")
+	}
+}
+
+// TestHTTPProxy tests plain requests and CONNECT tunnels through the HTTP proxy
+func TestHTTPProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", r.Host, r.URL.Path)
+	}))
+	defer backend.Close()
+	fakeForwards(t, backend.Listener.Addr().String())
+	server := startProxy(t)
+
+	proxyURL, _ := url.Parse("http://" + server.Addr())
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}, Timeout: 5 * time.Second}
+	resp, err := client.Get("http://api.team:8080/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "api.team:8080 /hello" {
+		t.Errorf("Expected the backend's reply, got %q", body)
+	}
+
+	resp, err = client.Get("http://missing.team/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected 502 for a missing service, got %d", resp.StatusCode)
+	}
+
+	// A CONNECT tunnel, as used for HTTPS
+	conn, err := net.Dial("tcp", server.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	fmt.Fprintf(conn, "CONNECT api.team:8080 HTTP/1.1\r\nHost: api.team:8080\r\n\r\n")
+	reader := bufio.NewReader(conn)
+	connectResp, err := http.ReadResponse(reader, nil)
+	if err != nil || connectResp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the tunnel to be established, got %v, %v", connectResp, err)
+	}
+	fmt.Fprintf(conn, "GET /tunnel HTTP/1.1\r\nHost: api.team:8080\r\n\r\n")
+	tunnelResp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(io.LimitReader(tunnelResp.Body, 100))
+	if string(body) != "api.team:8080 /tunnel" {
+		t.Errorf("Expected the backend's reply through the tunnel, got %q", body)
+	}
+}
+
+// TestStopIdle tests that only port-forwards idle for idleTimeout are stopped
+func TestStopIdle(t *testing.T) {
+	started, stopped := fakeForwards(t, "127.0.0.1:1")
+	server := NewServer("127.0.0.1:0", utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard))
+
+	active, err := server.acquire(target{service: "api", namespace: "team", port: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+	idle, err := server.acquire(target{service: "web", namespace: "team", port: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.releaseForward(idle)
+
+	server.stopIdle(time.Now())
+	if stopped.Load() != 0 {
+		t.Fatalf("Expected no port-forward stopped before idleTimeout, got %d", stopped.Load())
+	}
+	server.stopIdle(time.Now().Add(idleTimeout))
+	if stopped.Load() != 1 || len(server.Status().Forwards) != 1 {
+		t.Fatalf("Expected only the idle port-forward stopped, got %d stopped, %v", stopped.Load(), server.Status().Forwards)
+	}
+
+	server.releaseForward(active)
+	server.Reset()
+	if stopped.Load() != 2 || started.Load() != 2 {
+		t.Errorf("Expected Reset to stop the remaining port-forward, got %d of %d stopped", stopped.Load(), started.Load())
+	}
+}
+
+// TestStartingDoesNotBlockOtherTargets tests that a slow port-forward only holds up
+// connections to its own target
+func TestStartingDoesNotBlockOtherTargets(t *testing.T) {
+	slow := make(chan struct{})
+	var started atomic.Int32
+	original := startPortForward
+	t.Cleanup(func() { startPortForward = original })
+	startPortForward = func(target target, logger *utils.Logger) (*forward, error) {
+		if target.service == "slow" {
+			<-slow
+		}
+		started.Add(1)
+		return &forward{port: 1, stop: func() {}}, nil
+	}
+	server := NewServer("127.0.0.1:0", utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard))
+
+	slowDone := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := server.acquire(target{service: "slow", namespace: "team", port: 80})
+			slowDone <- err
+		}()
+	}
+
+	fast := make(chan error, 1)
+	go func() {
+		_, err := server.acquire(target{service: "fast", namespace: "team", port: 80})
+		fast <- err
+	}()
+	select {
+	case err := <-fast:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected another target to start while the slow one is starting")
+	}
+
+	close(slow)
+	for i := 0; i < 2; i++ {
+		if err := <-slowDone; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if started.Load() != 2 {
+		t.Errorf("Expected one port-forward per target, got %d", started.Load())
+	}
+	if len(server.starting) != 0 {
+		t.Errorf("Expected no start locks left, got %d", len(server.starting))
+	}
+}
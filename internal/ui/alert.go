@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// alertFlashDuration is how long the header flashes after a critical transition
+const alertFlashDuration = 3 * time.Second
+
+// alertFlashPeriod is how long the header stays highlighted or plain while flashing
+const alertFlashPeriod = 500 * time.Millisecond
+
+// ringBell writes the terminal bell; replaced in tests
+var ringBell = func() {
+	os.Stdout.Write([]byte("\a"))
+}
+
+// alertOnTransitions rings the bell and/or starts flashing the header, as set in
+// uiOptions.alert, when a service entered Failed or global access was lost since the
+// previous status update. Services seen for the first time do not alert.
+func (m *Model) alertOnTransitions(previous map[string]config.ServiceStatus, wasHealthy bool) tea.Cmd {
+	if m.alert == "" || m.alert == config.AlertOff {
+		return nil
+	}
+
+	var failed []string
+	for name, service := range m.services {
+		if before, exists := previous[name]; exists && service.Status == "Failed" && before.Status != "Failed" {
+			failed = append(failed, name)
+		}
+	}
+	sort.Strings(failed)
+
+	var message string
+	switch {
+	case wasHealthy && !m.globalAccessHealthy:
+		message = "kubectl access failed, services suspended"
+	case len(failed) == 1:
+		message = fmt.Sprintf("%s failed", failed[0])
+	case len(failed) > 1:
+		message = fmt.Sprintf("%d services failed", len(failed))
+	default:
+		return nil
+	}
+
+	if m.alert == config.AlertFlash || m.alert == config.AlertBoth {
+		m.alertMessage = message
+		m.flashUntil = time.Now().Add(alertFlashDuration)
+	}
+	if m.alert == config.AlertBell || m.alert == config.AlertBoth {
+		return func() tea.Msg {
+			ringBell()
+			return nil
+		}
+	}
+	return nil
+}
+
+// flashing reports whether the header is flashing at now, and if so whether it is
+// in its highlighted phase
+func (m *Model) flashing(now time.Time) (active, highlighted bool) {
+	remaining := m.flashUntil.Sub(now)
+	if remaining <= 0 {
+		return false, false
+	}
+	return true, (remaining/alertFlashPeriod)%2 == 0
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestModelAlertOnTransitions tests the bell and header flash on critical transitions
+func TestModelAlertOnTransitions(t *testing.T) {
+	rings := 0
+	defer func(original func()) { ringBell = original }(ringBell)
+	ringBell = func() { rings++ }
+
+	mockManager := &MockUIManagerProvider{globalAccessHealthy: true}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, mockManager)
+	model.alert = config.AlertBoth
+	model.width = 200
+	model.height = 40
+
+	update := func(statuses map[string]config.ServiceStatus) {
+		_, cmd := model.Update(StatusUpdateMsg(statuses))
+		if cmd != nil {
+			cmd()
+		}
+	}
+
+	// Services that start out failed do not alert
+	update(map[string]config.ServiceStatus{"api": {Name: "api", Status: "Running"}, "db": {Name: "db", Status: "Failed"}})
+	if rings != 0 || !model.flashUntil.IsZero() {
+		t.Fatalf("Expected no alert for the initial status, got %d rings", rings)
+	}
+
+	update(map[string]config.ServiceStatus{"api": {Name: "api", Status: "Failed"}, "db": {Name: "db", Status: "Failed"}})
+	if rings != 1 || model.alertMessage != "api failed" {
+		t.Errorf("Expected one ring for api, got %d rings and %q", rings, model.alertMessage)
+	}
+	if view := model.renderHeader(); !strings.Contains(view, "api failed") {
+		t.Errorf("Expected the alert in the header, got:\n%s", view)
+	}
+
+	// Staying failed does not alert again, losing global access does
+	mockManager.globalAccessHealthy = false
+	update(map[string]config.ServiceStatus{"api": {Name: "api", Status: "Suspended"}, "db": {Name: "db", Status: "Failed"}})
+	if rings != 2 || !strings.Contains(model.alertMessage, "suspended") {
+		t.Errorf("Expected a ring for lost access, got %d rings and %q", rings, model.alertMessage)
+	}
+
+	// Off by default
+	model.alert = ""
+	mockManager.globalAccessHealthy = true
+	update(map[string]config.ServiceStatus{"api": {Name: "api", Status: "Running"}, "db": {Name: "db", Status: "Running"}})
+	update(map[string]config.ServiceStatus{"api": {Name: "api", Status: "Failed"}, "db": {Name: "db", Status: "Running"}})
+	if rings != 2 {
+		t.Errorf("Expected no ring without alert set, got %d", rings)
+	}
+}
@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// tableColumn names a column that can be hidden; the names are saved in the UI state
+type tableColumn string
+
+const (
+	columnURL     tableColumn = "url"
+	columnType    tableColumn = "type"
+	columnPort    tableColumn = "port"
+	columnUptime  tableColumn = "uptime"
+	columnError   tableColumn = "error"
+	columnLatency tableColumn = "latency"
+	columnLastOK  tableColumn = "lastok"
+)
+
+// toggleableColumns are the columns v and a number show or hide, numbered in this order
+var toggleableColumns = []struct {
+	column tableColumn
+	title  string
+}{
+	{columnURL, "URL"},
+	{columnType, "Type"},
+	{columnPort, "Port"},
+	{columnUptime, "Uptime"},
+	{columnError, "Error/Status"},
+	{columnLatency, "Latency"},
+	{columnLastOK, "Last OK"},
+}
+
+// optionalColumns are hidden until shown with v; the others are shown until hidden
+var optionalColumns = map[tableColumn]bool{columnLastOK: true}
+
+// tableChrome is the width taken by the container's border and padding around the table
+const tableChrome = 8
+
+// minErrorWidth is the narrowest Error/Status column worth showing
+const minErrorWidth = 10
+
+// tableLayout holds the width of each table column; hidden columns have width 0
+type tableLayout struct {
+	profile, name, status, url, serviceType, port, latency, uptime, lastOK, errorStatus int
+}
+
+// widths returns the widths of all columns in display order
+func (l tableLayout) widths() []int {
+	return []int{l.profile, l.name, l.status, l.url, l.serviceType, l.port, l.latency, l.uptime, l.lastOK, l.errorStatus}
+}
+
+// width returns the width of a row: the shown columns and a space between each
+func (l tableLayout) width() int {
+	total, shown := 0, 0
+	for _, width := range l.widths() {
+		if width > 0 {
+			total += width
+			shown++
+		}
+	}
+	if shown > 1 {
+		total += shown - 1
+	}
+	return total
+}
+
+// layoutCache holds the table layout computed for a terminal width
+type layoutCache struct {
+	valid  bool
+	width  int
+	layout tableLayout
+}
+
+// invalidateLayout makes the next render lay out the table again, after the columns or
+// the services they depend on changed
+func (m *Model) invalidateLayout() {
+	m.layout.valid = false
+}
+
+// tableLayout returns the table layout for the current width, computed again only once
+// the width settles or invalidateLayout is called
+func (m *Model) tableLayout() tableLayout {
+	if !m.layout.valid || m.layout.width != m.width {
+		m.layout = layoutCache{valid: true, width: m.width, layout: m.computeTableLayout()}
+	}
+	return m.layout.layout
+}
+
+// computeTableLayout fits the columns into the terminal width. The columns the user did not
+// hide get their preferred widths; when they do not fit next to a useful Error/Status
+// column, Last OK is dropped and the URL column shrinks, then Uptime, Latency, Type, URL and Port are dropped
+// in turn.
+func (m *Model) computeTableLayout() tableLayout {
+	layout := tableLayout{
+		profile:     m.profileColumnWidth(), // Only shown when services were loaded from profiles
+		name:        25,
+		status:      15, // Fits "Reconnecting" after the status symbol
+		url:         35, // Room for the emoji icons of UI links
+		serviceType: 8,
+		port:        6,
+		latency:     m.latencyColumnWidth(), // Only shown for services with protocol health checks
+		uptime:      10,
+		lastOK:      12, // "10h23m ago"
+	}
+	if m.uptimeFormat == utils.UptimeFull {
+		layout.uptime = 16 // "10 days 23 hours"
+		layout.lastOK = 20
+	}
+	for column, width := range map[tableColumn]*int{
+		columnURL:     &layout.url,
+		columnType:    &layout.serviceType,
+		columnPort:    &layout.port,
+		columnUptime:  &layout.uptime,
+		columnLatency: &layout.latency,
+		columnLastOK:  &layout.lastOK,
+	} {
+		if !m.columnShown(column) {
+			*width = 0
+		}
+	}
+
+	available := m.width - tableChrome
+	errorShown := m.columnShown(columnError)
+	fits := func() bool {
+		if !errorShown {
+			return layout.width() <= available
+		}
+		return layout.width()+1+minErrorWidth <= available
+	}
+	for _, drop := range []func(){
+		func() { layout.lastOK = 0 },
+		func() { layout.url = min(layout.url, 24) },
+		func() { layout.uptime = 0 },
+		func() { layout.latency = 0 },
+		func() { layout.serviceType = 0 },
+		func() { layout.name = min(layout.name, 18) },
+		func() { layout.url = 0 },
+		func() { layout.port = 0 },
+	} {
+		if fits() {
+			break
+		}
+		drop()
+	}
+
+	if errorShown {
+		layout.errorStatus = max(available-layout.width()-1, minErrorWidth)
+	}
+	return layout
+}
+
+// columnShown reports whether the user left a column shown, or showed an optional one
+func (m *Model) columnShown(column tableColumn) bool {
+	if optionalColumns[column] {
+		return m.shownColumns[column]
+	}
+	return !m.hiddenColumns[column]
+}
+
+// toggleColumn shows or hides a column and returns a command saving the choice
+func (m *Model) toggleColumn(column tableColumn) tea.Cmd {
+	m.invalidateLayout()
+	if optionalColumns[column] {
+		if m.shownColumns == nil {
+			m.shownColumns = make(map[tableColumn]bool)
+		}
+		if m.shownColumns[column] {
+			delete(m.shownColumns, column)
+		} else {
+			m.shownColumns[column] = true
+		}
+		return m.persistUIState("")
+	}
+	if m.hiddenColumns == nil {
+		m.hiddenColumns = make(map[tableColumn]bool)
+	}
+	if m.hiddenColumns[column] {
+		delete(m.hiddenColumns, column)
+	} else {
+		m.hiddenColumns[column] = true
+	}
+	return m.persistUIState("")
+}
+
+// handleColumnKeyPress toggles columns by number until Esc or Enter
+func (m *Model) handleColumnKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "enter", "v":
+		m.pickingColumns = false
+
+	default:
+		if len(key) == 1 && key[0] >= '1' && int(key[0]-'1') < len(toggleableColumns) {
+			return m, m.toggleColumn(toggleableColumns[key[0]-'1'].column)
+		}
+	}
+	return m, nil
+}
+
+// columnPickerHelp lists the toggleable columns with their numbers and whether they are shown
+func (m *Model) columnPickerHelp() string {
+	entries := make([]string, 0, len(toggleableColumns))
+	for i, toggleable := range toggleableColumns {
+		mark := "x"
+		if !m.columnShown(toggleable.column) {
+			mark = " "
+		}
+		entries = append(entries, fmt.Sprintf("[%d] [%s] %s", i+1, mark, toggleable.title))
+	}
+	return "Columns: " + strings.Join(entries, "  ") + "  [ESC] Done"
+}
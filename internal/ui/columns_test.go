@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestModelTableLayout tests that columns are dropped in order as the terminal narrows
+func TestModelTableLayout(t *testing.T) {
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+
+	tests := []struct {
+		width    int
+		expected tableLayout
+	}{
+		{200, tableLayout{name: 25, status: 15, url: 35, serviceType: 8, port: 6, uptime: 10, errorStatus: 87}},
+		{120, tableLayout{name: 25, status: 15, url: 24, serviceType: 8, port: 6, uptime: 10, errorStatus: 18}},
+		{100, tableLayout{name: 25, status: 15, url: 24, port: 6, errorStatus: 18}},
+		{70, tableLayout{name: 18, status: 15, port: 6, errorStatus: 20}},
+		{40, tableLayout{name: 18, status: 15, errorStatus: 10}},
+	}
+	for _, test := range tests {
+		model.width = test.width
+		if layout := model.tableLayout(); layout != test.expected {
+			t.Errorf("Width %d: expected %+v, got %+v", test.width, test.expected, layout)
+		}
+	}
+}
+
+// TestModelHideColumns tests hiding a column with v and saving the choice
+func TestModelHideColumns(t *testing.T) {
+	var saved []config.UIState
+	defer func(original func(config.UIState) error) { saveUIState = original }(saveUIState)
+	saveUIState = func(state config.UIState) error {
+		saved = append(saved, state)
+		return nil
+	}
+
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	model.width = 200
+	model.height = 40
+	model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Running", LocalPort: 8080},
+	}))
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	if view := model.View(); !strings.Contains(view, "[2] [x] Type") {
+		t.Errorf("Expected the column picker in the footer, got:\n%s", view)
+	}
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+	if cmd == nil {
+		t.Fatal("Expected a command saving the hidden columns")
+	}
+	cmd()
+	if len(saved) != 1 || !reflect.DeepEqual(saved[0].HiddenColumns, []string{"type"}) {
+		t.Errorf("Expected the type column to be saved as hidden, got %+v", saved)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.pickingColumns {
+		t.Error("Expected Esc to close the column picker")
+	}
+	if view := model.View(); strings.Contains(view, "Type") || !strings.Contains(view, "Port") {
+		t.Errorf("Expected the Type column hidden and Port shown, got:\n%s", view)
+	}
+}
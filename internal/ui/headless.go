@@ -0,0 +1,245 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/victorkazakov/kportforward/internal/api"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// Headless output formats
+const (
+	OutputTUI   = "tui"
+	OutputPlain = "plain"
+	OutputJSON  = "json"
+
+	// OutputAccessible writes plain sentences without padding or symbols, for screen
+	// readers and braille terminals (--accessible)
+	OutputAccessible = "accessible"
+)
+
+// headlessSummaryInterval is how often a health summary is written even without changes
+const headlessSummaryInterval = time.Minute
+
+// Headless writes service status changes to out as plain text lines or JSON events,
+// for CI jobs, tmux panes and service managers where the TUI is of no use
+type Headless struct {
+	out             io.Writer
+	format          string
+	timestampFormat string
+
+	// Last reported state per service, so only changes are written
+	lastState map[string]headlessState
+	latest    map[string]config.ServiceStatus
+}
+
+// headlessState is the part of a service status whose changes are reported
+type headlessState struct {
+	status    string
+	localPort int
+	lastError string
+}
+
+// headlessEvent is one line of JSON output
+type headlessEvent struct {
+	Time      time.Time `json:"time"`
+	Event     string    `json:"event"` // status, context or summary
+	Service   string    `json:"service,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	LocalPort int       `json:"localPort,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Context   string    `json:"context,omitempty"`
+
+	Running  *int `json:"running,omitempty"`
+	Degraded *int `json:"degraded,omitempty"`
+	Failed   *int `json:"failed,omitempty"`
+	Inactive *int `json:"inactive,omitempty"`
+}
+
+// NewHeadless creates a headless reporter writing format (OutputPlain, OutputJSON or
+// OutputAccessible) to out
+func NewHeadless(out io.Writer, format string) *Headless {
+	return &Headless{
+		out:       out,
+		format:    format,
+		lastState: make(map[string]headlessState),
+	}
+}
+
+// SetDisplayOptions applies the timestamp format from uiOptions to plain text output
+func (h *Headless) SetDisplayOptions(options config.UIConfig) {
+	h.timestampFormat = options.TimestampFormat
+}
+
+// Run reports status and context changes until ctx is cancelled
+func (h *Headless) Run(ctx context.Context, statusChan <-chan map[string]config.ServiceStatus, contextChan <-chan string) {
+	ticker := time.NewTicker(headlessSummaryInterval)
+	defer ticker.Stop()
+
+	lastContext := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case status, ok := <-statusChan:
+			if !ok {
+				return
+			}
+			h.ReportStatus(status, time.Now())
+		case kubeContext, ok := <-contextChan:
+			if !ok {
+				contextChan = nil
+				continue
+			}
+			// The manager republishes the context on every check
+			if kubeContext != lastContext {
+				lastContext = kubeContext
+				h.reportContext(kubeContext, time.Now())
+			}
+		case now := <-ticker.C:
+			h.reportSummary(now)
+		}
+	}
+}
+
+// ReportStatus writes one line per service whose status, port or error changed
+func (h *Headless) ReportStatus(status map[string]config.ServiceStatus, now time.Time) {
+	h.latest = status
+
+	names := make([]string, 0, len(status))
+	for name := range status {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service := status[name]
+		state := headlessState{status: service.Status, localPort: service.LocalPort, lastError: service.LastError}
+		if previous, seen := h.lastState[name]; seen && previous == state {
+			continue
+		}
+		h.lastState[name] = state
+
+		if h.format == OutputJSON {
+			h.writeJSON(headlessEvent{
+				Time:      now,
+				Event:     "status",
+				Service:   name,
+				Status:    service.Status,
+				LocalPort: service.LocalPort,
+				Message:   service.StatusMessage,
+				Error:     service.LastError,
+			})
+			continue
+		}
+		if h.format == OutputAccessible {
+			h.writeSentence(now, accessibleStatus(name, service))
+			continue
+		}
+
+		line := fmt.Sprintf("%s %-30s %-12s :%-5d", utils.FormatTimestamp(now, h.timestampFormat), name, service.Status, service.LocalPort)
+		if service.LastError != "" {
+			line += " " + service.LastError
+		} else if service.StatusMessage != "" {
+			line += " " + service.StatusMessage
+		}
+		fmt.Fprintln(h.out, line)
+	}
+}
+
+// reportContext writes a Kubernetes context change
+func (h *Headless) reportContext(kubeContext string, now time.Time) {
+	if h.format == OutputJSON {
+		h.writeJSON(headlessEvent{Time: now, Event: "context", Context: kubeContext})
+		return
+	}
+	if h.format == OutputAccessible {
+		h.writeSentence(now, fmt.Sprintf("Kubernetes context is %s.", kubeContext))
+		return
+	}
+	fmt.Fprintf(h.out, "%s context: %s\n", utils.FormatTimestamp(now, h.timestampFormat), kubeContext)
+}
+
+// reportSummary writes the health summary of the latest status
+func (h *Headless) reportSummary(now time.Time) {
+	if h.latest == nil {
+		return
+	}
+
+	summary := api.SummarizeHealth(h.latest)
+	if h.format == OutputJSON {
+		h.writeJSON(headlessEvent{
+			Time:     now,
+			Event:    "summary",
+			Running:  &summary.Healthy,
+			Degraded: &summary.Degraded,
+			Failed:   &summary.Failed,
+			Inactive: &summary.Inactive,
+		})
+		return
+	}
+	if h.format == OutputAccessible {
+		h.writeSentence(now, fmt.Sprintf("Summary: %s.", summary))
+		return
+	}
+	fmt.Fprintf(h.out, "%s summary: %s\n", utils.FormatTimestamp(now, h.timestampFormat), summary)
+}
+
+// writeJSON writes event as a single line of JSON
+func (h *Headless) writeJSON(event headlessEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(h.out, "%s\n", data)
+}
+
+// writeSentence writes one line of accessible output, with the time after the news so a
+// screen reader starts with what changed
+func (h *Headless) writeSentence(now time.Time, sentence string) {
+	fmt.Fprintf(h.out, "%s Time %s.\n", sentence, utils.FormatTimestamp(now, h.timestampFormat))
+}
+
+// accessibleStatus describes a service status in words, such as
+// "api: running on port 8080."
+func accessibleStatus(name string, service config.ServiceStatus) string {
+	sentence := fmt.Sprintf("%s: %s", name, statusWords(service.Status))
+	if service.LocalPort != 0 {
+		sentence += fmt.Sprintf(" on port %d", service.LocalPort)
+	}
+	sentence += "."
+	if service.LastError != "" {
+		sentence += " Error: " + strings.TrimSuffix(service.LastError, ".") + "."
+	} else if service.StatusMessage != "" {
+		sentence += " " + strings.TrimSuffix(service.StatusMessage, ".") + "."
+	}
+	return sentence
+}
+
+// statusWords spells out a status in lower case words, such as "port conflict" for
+// PortConflict
+func statusWords(status string) string {
+	if status == "" {
+		return "unknown"
+	}
+	var words strings.Builder
+	for i, r := range status {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				words.WriteByte(' ')
+			}
+			r = unicode.ToLower(r)
+		}
+		words.WriteRune(r)
+	}
+	return words.String()
+}
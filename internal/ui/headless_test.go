@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+func TestHeadlessPlainReportsChangesOnly(t *testing.T) {
+	var out bytes.Buffer
+	headless := NewHeadless(&out, OutputPlain)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	headless.ReportStatus(map[string]config.ServiceStatus{
+		"api": {Status: "Running", LocalPort: 8080},
+		"db":  {Status: "Connecting", LocalPort: 5432, StatusMessage: "Waiting for port-forward to establish"},
+	}, now)
+	headless.ReportStatus(map[string]config.ServiceStatus{
+		"api": {Status: "Running", LocalPort: 8080},
+		"db":  {Status: "Failed", LocalPort: 5432, LastError: "connection refused"},
+	}, now)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines (api once, db twice), got %d:\n%s", len(lines), out.String())
+	}
+	if !strings.HasPrefix(lines[0], "2026-01-02 03:04:05 api") || !strings.Contains(lines[0], "Running") {
+		t.Errorf("Unexpected first line %q", lines[0])
+	}
+	if !strings.Contains(lines[2], "Failed") || !strings.HasSuffix(lines[2], "connection refused") {
+		t.Errorf("Expected failure with error, got %q", lines[2])
+	}
+}
+
+func TestHeadlessJSONEvents(t *testing.T) {
+	var out bytes.Buffer
+	headless := NewHeadless(&out, OutputJSON)
+	now := time.Now()
+
+	headless.ReportStatus(map[string]config.ServiceStatus{
+		"api": {Status: "Degraded", LocalPort: 8080, LastError: "timeout"},
+	}, now)
+	headless.reportSummary(now)
+
+	decoder := json.NewDecoder(&out)
+	var status, summary headlessEvent
+	if err := decoder.Decode(&status); err != nil {
+		t.Fatalf("Failed to decode status event: %v", err)
+	}
+	if status.Event != "status" || status.Service != "api" || status.Error != "timeout" {
+		t.Errorf("Unexpected status event %+v", status)
+	}
+	if err := decoder.Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode summary event: %v", err)
+	}
+	if summary.Event != "summary" || summary.Degraded == nil || *summary.Degraded != 1 {
+		t.Errorf("Unexpected summary event %+v", summary)
+	}
+}
+
+func TestHeadlessAccessibleSentences(t *testing.T) {
+	var out bytes.Buffer
+	headless := NewHeadless(&out, OutputAccessible)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	headless.ReportStatus(map[string]config.ServiceStatus{
+		"web": {Status: "PortConflict", LocalPort: 3000, LastError: "port 3000 is in use by node (pid 7) (strictPort)"},
+		"api": {Status: "Running", LocalPort: 8080},
+	}, now)
+	headless.reportSummary(now)
+
+	expected := []string{
+		"api: running on port 8080. Time 2026-01-02 03:04:05.",
+		"web: port conflict on port 3000. Error: port 3000 is in use by node (pid 7) (strictPort). Time 2026-01-02 03:04:05.",
+		"Summary: 1 running, 0 degraded, 1 failed. Time 2026-01-02 03:04:05.",
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), out.String())
+	}
+}
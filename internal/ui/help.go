@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// helpEntry is a key or symbol and what it does or means
+type helpEntry struct {
+	key         string
+	description string
+}
+
+// helpSection is a titled group of entries in the help overlay
+type helpSection struct {
+	title   string
+	entries []helpEntry
+}
+
+// statusMeanings describes each status, in the order the help overlay lists them
+var statusMeanings = []helpEntry{
+	{"Running", "forwarding and answering health checks"},
+	{"Degraded", "forwarding, but the local port stopped responding"},
+	{"Connecting", "kubectl started, waiting for the port or pod to be ready"},
+	{"Reconnecting", "restarting after a context change, schedule or idle period"},
+	{"Starting", "kubectl is being started"},
+	{"Waiting", "not started until the services in dependsOn are Running"},
+	{"Failed", "forwarding failed; restarted after a backoff"},
+	{"PortConflict", "another process holds the local port of a strictPort service; retried until it is free"},
+	{"Cooldown", "restarted too often; waiting before the next attempt"},
+	{"Suspended", "kubectl cannot reach the cluster; resumed once it can"},
+	{"Scheduled", "outside the service's availability window"},
+	{"Idle", "stopped after a period without connections"},
+	{"Disabled", "disabled in the config"},
+}
+
+// openHelp shows the help overlay over the current view
+func (m *Model) openHelp() {
+	m.helpReturnMode = m.viewMode
+	m.viewMode = ViewHelp
+}
+
+// handleHelpKeyPress handles keys in the help overlay
+func (m *Model) handleHelpKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "backspace", "?":
+		m.viewMode = m.helpReturnMode
+	}
+
+	return m, nil
+}
+
+// helpSections returns the keybindings, leaving out actions unavailable to observers
+func (m *Model) helpSections() []helpSection {
+	services := []helpEntry{
+		{"↑↓ / j k", "Move the selection"},
+		{"PgUp / PgDn", "Move a page up or down; Home and End jump to the first or last service"},
+		{"Enter / Space", "Show details of the selected service"},
+		{"/", "Filter by name, description, owner, namespace, type or status (Enter keeps it, Esc clears it)"},
+	}
+	if !m.readOnly {
+		services = append(services,
+			helpEntry{"x / R", "Restart the selected service"},
+			helpEntry{"d", "Disable the selected service, or enable a disabled one"},
+			helpEntry{"K", "Kill the process holding the port of a service in PortConflict, after confirming"},
+			helpEntry{"I", "Prepare an issue report about the selected service to copy or save for its owners"},
+			helpEntry{"w", "Switch to another profile without restarting kportforward"},
+			helpEntry{"U", "Install an available update and restart into it"},
+		)
+	}
+	if m.mouse {
+		services = append(services, helpEntry{"Click", "Select a service; double-click shows its details, clicking its URL opens it"})
+	}
+	services = append(services,
+		helpEntry{"P", "Pin the selected service to the top of the table, or unpin it"},
+		helpEntry{"v", "Show or hide columns; narrow terminals drop some automatically"},
+		helpEntry{"+ / -", "Refresh faster or slower, e.g. over a slow SSH connection"},
+		helpEntry{"?", "Show or close this help"},
+		helpEntry{"q / Ctrl+C", "Quit"},
+	)
+
+	sorting := make([]helpEntry, 0, len(sortFieldNames)+1)
+	for _, sort := range []struct {
+		key   string
+		field SortField
+	}{
+		{"!", SortByProblems},
+		{"n", SortByName},
+		{"s", SortByStatus},
+		{"t", SortByType},
+		{"p", SortByPort},
+		{"u", SortByUptime},
+		{"c", SortByRestarts},
+		{"e", SortByErrors},
+	} {
+		description := "Sort by " + strings.ToLower(sortFieldNames[sort.field])
+		if sort.field == SortByProblems {
+			description = "Sort problems first (default)"
+		}
+		if sort.field == m.sortField {
+			description += " (current)"
+		}
+		sorting = append(sorting, helpEntry{sort.key, description})
+	}
+	sorting = append(sorting, helpEntry{"r", "Reverse the sort order"})
+
+	return []helpSection{
+		{"Services", services},
+		{"Sorting", sorting},
+		{"Details", []helpEntry{
+			{"1-9", "Open one of the service's links"},
+			{"↑↓ / PgUp / PgDn", "Scroll the live kubectl output; End follows it again"},
+			{"Tab", "Switch between kubectl output and the gRPC UI log (with --grpcui)"},
+			{"l", "Show the end of the service's log (with --log-dir)"},
+			{"Esc", "Back to the table"},
+		}},
+		{"Log", []helpEntry{
+			{"o", "Open the log file in the default viewer"},
+			{"Esc", "Back to the details"},
+		}},
+	}
+}
+
+// renderHelpView renders the full-screen help overlay
+func (m *Model) renderHelpView() string {
+	content := []string{titleStyle.Render("Help"), ""}
+
+	for _, section := range m.helpSections() {
+		content = append(content, tableHeaderStyle.Render(section.title))
+		for _, entry := range section.entries {
+			content = append(content, fmt.Sprintf("  %-14s %s", entry.key, entry.description))
+		}
+		content = append(content, "")
+	}
+
+	content = append(content, tableHeaderStyle.Render("Status"))
+	for _, status := range statusMeanings {
+		// Pad before styling; the style's escape codes would throw off the width
+		content = append(content, fmt.Sprintf("  %s %s %s", GetStatusIndicator(status.key),
+			GetStatusStyle(status.key).Render(fmt.Sprintf("%-13s", status.key)), status.description))
+	}
+
+	content = append(content, "", helpStyle.Render("[?/ESC] Close help  [q] Quit"))
+
+	return containerStyle.
+		Width(m.width - 4).
+		Height(m.height - 2).
+		Render(strings.Join(content, "\n"))
+}
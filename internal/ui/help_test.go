@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestModelHelpOverlay tests that ? opens the help overlay and closes it to the previous view
+func TestModelHelpOverlay(t *testing.T) {
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	model.width = 120
+	model.height = 60
+	model.readOnly = true
+	model.viewMode = ViewDetail
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	if model.viewMode != ViewHelp {
+		t.Fatalf("Expected help view, got %v", model.viewMode)
+	}
+	view := model.View()
+	for _, expected := range []string{"Sort by restarts", "Degraded", "outside the service's availability window"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected help to contain %q", expected)
+		}
+	}
+	if strings.Contains(view, "Restart the selected service") {
+		t.Error("Expected help of an observer not to list restarts")
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.viewMode != ViewDetail {
+		t.Errorf("Expected to return to the detail view, got %v", model.viewMode)
+	}
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// defaultIssueThreshold is the number of restarts after which an issue report is offered
+const defaultIssueThreshold = 5
+
+// IssueReporter is implemented by providers that can describe a failing service in an
+// issue report for the team owning it
+type IssueReporter interface {
+	// IssueReport returns a markdown issue with the service's configuration, last errors,
+	// timeline and the kubectl and cluster versions
+	IssueReport(name string) (string, error)
+}
+
+// issueReportMsg carries the issue report prepared after pressing I
+type issueReportMsg struct {
+	service string
+	report  string
+	err     error
+}
+
+// pendingIssue is an issue report waiting to be copied or written to a file
+type pendingIssue struct {
+	service string
+	report  string
+}
+
+// copyToClipboard copies text to the clipboard; replaced in tests
+var copyToClipboard = utils.CopyToClipboard
+
+// offerIssueReports suggests an issue report, once per service, when a service was
+// restarted uiOptions.issueThreshold times
+func (m *Model) offerIssueReports() {
+	threshold := m.issueThreshold
+	if threshold == 0 {
+		threshold = defaultIssueThreshold
+	}
+	if _, ok := m.manager.(IssueReporter); threshold < 0 || !ok || m.readOnly {
+		return
+	}
+
+	var failing []string
+	for name, service := range m.services {
+		if service.RestartCount >= threshold && !m.issueOffered[name] {
+			failing = append(failing, name)
+		}
+	}
+	if len(failing) == 0 {
+		return
+	}
+	sort.Strings(failing)
+	if m.issueOffered == nil {
+		m.issueOffered = make(map[string]bool)
+	}
+	for _, name := range failing {
+		m.issueOffered[name] = true
+	}
+	m.showActionMessage(fmt.Sprintf("%s was restarted %d times: select it and press I to prepare an issue report",
+		failing[0], m.services[failing[0]].RestartCount))
+}
+
+// prepareIssueReport returns a command preparing an issue report about the selected service
+func (m *Model) prepareIssueReport() tea.Cmd {
+	if m.selectedIndex >= len(m.serviceNames) {
+		return nil
+	}
+	name := m.serviceNames[m.selectedIndex]
+	reporter, ok := m.manager.(IssueReporter)
+	if m.readOnly || !ok {
+		m.showActionMessage("Read-only: issue reports cannot be prepared from this view")
+		return nil
+	}
+
+	m.actionMessage = fmt.Sprintf("Preparing an issue report for %s…", name)
+	m.actionMessageExpiry = time.Time{}
+	return func() tea.Msg {
+		report, err := reporter.IssueReport(name)
+		return issueReportMsg{service: name, report: report, err: err}
+	}
+}
+
+// handleIssueReport asks where a prepared issue report should go
+func (m *Model) handleIssueReport(msg issueReportMsg) {
+	if msg.err != nil {
+		m.showActionMessage(fmt.Sprintf("Cannot prepare an issue report for %s: %v", msg.service, msg.err))
+		return
+	}
+	m.actionMessage = ""
+	m.pendingIssue = &pendingIssue{service: msg.service, report: msg.report}
+}
+
+// handleIssueKeyPress copies the pending issue report on c, writes it to a file on f and
+// drops it on any other key
+func (m *Model) handleIssueKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	issue := m.pendingIssue
+	m.pendingIssue = nil
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "c":
+		return m, func() tea.Msg {
+			if err := copyToClipboard(issue.report); err != nil {
+				return ServiceActionMsg(fmt.Sprintf("Could not copy the issue report, press I and f to write it to a file instead: %v", err))
+			}
+			return ServiceActionMsg(fmt.Sprintf("Issue report for %s copied to the clipboard", issue.service))
+		}
+
+	case "f":
+		return m, func() tea.Msg {
+			path, err := writeIssueReport(issue, time.Now())
+			if err != nil {
+				return ServiceActionMsg(fmt.Sprintf("Could not write the issue report: %v", err))
+			}
+			return ServiceActionMsg(fmt.Sprintf("Issue report for %s written to %s", issue.service, path))
+		}
+	}
+
+	m.showActionMessage("Issue report discarded")
+	return m, nil
+}
+
+// writeIssueReport writes an issue report to a new file in the working directory and
+// returns its path
+func writeIssueReport(issue *pendingIssue, now time.Time) (string, error) {
+	service := strings.NewReplacer("/", "-", `\`, "-").Replace(issue.service)
+	name := fmt.Sprintf("kportforward-issue-%s-%s.md", service, now.Format("20060102-150405"))
+	path, err := filepath.Abs(name)
+	if err != nil {
+		path = name
+	}
+	if err := os.WriteFile(path, []byte(issue.report), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// issuePrompt asks where the pending issue report should go in the footer
+func (m *Model) issuePrompt() string {
+	return fmt.Sprintf("Issue report for %s ready:  [c] Copy to clipboard  [f] Write to file  [any other key] Discard",
+		m.pendingIssue.service)
+}
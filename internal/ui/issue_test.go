@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// mockIssueReporter prepares issue reports
+type mockIssueReporter struct {
+	*MockUIManagerProvider
+}
+
+func (m *mockIssueReporter) IssueReport(name string) (string, error) {
+	return "## `" + name + "` keeps failing", nil
+}
+
+// TestModelIssueReport tests suggesting an issue report after repeated restarts and
+// copying it to the clipboard with I and c
+func TestModelIssueReport(t *testing.T) {
+	defer func(original func(string) error) { copyToClipboard = original }(copyToClipboard)
+	var copied string
+	copyToClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+
+	manager := &mockIssueReporter{MockUIManagerProvider: &MockUIManagerProvider{}}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, manager)
+	model.width = 200
+	model.height = 40
+	model.issueThreshold = 3
+
+	model.Update(StatusUpdateMsg{"api": {Name: "api", Status: "Failed", RestartCount: 2}})
+	if model.actionMessage != "" {
+		t.Fatalf("Expected no suggestion below the threshold, got %q", model.actionMessage)
+	}
+	model.Update(StatusUpdateMsg{"api": {Name: "api", Status: "Failed", RestartCount: 3}})
+	if !strings.Contains(model.actionMessage, "press I to prepare an issue report") {
+		t.Fatalf("Expected an issue report to be suggested, got %q", model.actionMessage)
+	}
+	model.actionMessage = ""
+	model.Update(StatusUpdateMsg{"api": {Name: "api", Status: "Failed", RestartCount: 4}})
+	if model.actionMessage != "" {
+		t.Errorf("Expected the suggestion only once, got %q", model.actionMessage)
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'I'}})
+	if cmd == nil {
+		t.Fatal("Expected a command preparing the issue report")
+	}
+	model.Update(cmd())
+	if view := model.View(); !strings.Contains(view, "[c] Copy to clipboard") {
+		t.Fatalf("Expected the footer to ask where the report goes, got:\n%s", view)
+	}
+
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	model.Update(cmd())
+	if copied != "## `api` keeps failing" || !strings.Contains(model.actionMessage, "copied to the clipboard") {
+		t.Errorf("Expected the report on the clipboard, got %q and %q", copied, model.actionMessage)
+	}
+	if model.pendingIssue != nil {
+		t.Error("Expected the report to be handled")
+	}
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// sparkBlocks are the bars of a sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// latencyColumnWidth returns the width of the Latency column, or 0 if no service has a
+// health check that reaches the server
+func (m *Model) latencyColumnWidth() int {
+	for _, serviceConfig := range m.serviceConfigs {
+		if serviceConfig.HealthCheck.HasLatencyProbe() {
+			return 8
+		}
+	}
+	return 0
+}
+
+// formatLatency returns the Latency column of a service: the duration of its last probe,
+// "failed" if that probe failed, or "-" before the first one
+func formatLatency(status config.ServiceStatus) string {
+	switch {
+	case status.ProbeError != "":
+		return "failed"
+	case len(status.ProbeLatencies) == 0:
+		return "-"
+	}
+	return formatDuration(status.ProbeLatency)
+}
+
+// formatLastHealthy returns how long ago the last health check of a service passed, or
+// "-" before the first one
+func (m *Model) formatLastHealthy(status config.ServiceStatus) string {
+	if status.LastHealthy.IsZero() {
+		return "-"
+	}
+	return utils.FormatUptimeAs(time.Since(status.LastHealthy), m.uptimeFormat) + " ago"
+}
+
+// downtimeDetails summarizes the outages of a service for the detail view
+func (m *Model) downtimeDetails(downtime config.DowntimeStats) []string {
+	if downtime.Outages == 0 {
+		return nil
+	}
+	outages := "outages"
+	if downtime.Outages == 1 {
+		outages = "outage"
+	}
+	summary := fmt.Sprintf("Downtime: %s in %d %s",
+		utils.FormatUptimeAs(downtime.TotalDowntime(time.Now()), m.uptimeFormat), downtime.Outages, outages)
+	if mttr := downtime.MTTR(); mttr > 0 {
+		summary += fmt.Sprintf(", MTTR %s", utils.FormatUptimeAs(mttr, m.uptimeFormat))
+	}
+	details := []string{summary}
+	if !downtime.DownSince.IsZero() {
+		details = append(details, fmt.Sprintf("Down Since: %s (%s)",
+			utils.FormatTimestamp(downtime.DownSince, m.timestampFormat),
+			utils.FormatUptimeAs(time.Since(downtime.DownSince), m.uptimeFormat)))
+	}
+	return details
+}
+
+// formatDuration formats a probe duration in milliseconds, or seconds from 1s
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return "<1ms"
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// sparkline draws latencies scaled to the slowest one; failed probes (0) are drawn as ✗
+func sparkline(latencies []time.Duration) string {
+	slowest := slowestLatency(latencies)
+	var line strings.Builder
+	for _, latency := range latencies {
+		if latency == 0 {
+			line.WriteRune('✗')
+			continue
+		}
+		line.WriteRune(sparkBlocks[int(latency*time.Duration(len(sparkBlocks)-1)/slowest)])
+	}
+	return line.String()
+}
+
+// probeDetails describes the protocol health check of a service for the detail view
+func (m *Model) probeDetails(serviceName string) []string {
+	healthCheck := m.serviceConfigs[serviceName].HealthCheck
+	if !healthCheck.HasLatencyProbe() {
+		return nil
+	}
+
+	probe := healthCheck.Protocol
+	if healthCheck.Path != "" {
+		probe += " " + healthCheck.Path
+	}
+	status := m.statusOf(serviceName)
+	details := []string{fmt.Sprintf("Health Probe: %s, last %s", probe, formatLatency(status))}
+	if len(status.ProbeLatencies) > 0 {
+		details = append(details, fmt.Sprintf("Latency: %s (slowest %s)",
+			sparkline(status.ProbeLatencies), formatDuration(slowestLatency(status.ProbeLatencies))))
+	}
+	if status.ProbeError != "" {
+		details = append(details, errorMessageStyle.Render("Probe Error: "+status.ProbeError))
+	}
+	return details
+}
+
+// slowestLatency returns the longest of latencies
+func slowestLatency(latencies []time.Duration) time.Duration {
+	slowest := time.Duration(0)
+	for _, latency := range latencies {
+		slowest = max(slowest, latency)
+	}
+	return slowest
+}
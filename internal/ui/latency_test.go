@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestModelProbeLatency tests the Latency column and the latency sparkline in the detail view
+func TestModelProbeLatency(t *testing.T) {
+	configs := map[string]config.Service{
+		"api": {HealthCheck: config.HealthCheckConfig{Protocol: config.ProbeHTTP, Path: "/healthz"}},
+		"db":  {},
+	}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), configs, &MockUIManagerProvider{})
+	model.width = 200
+	model.height = 40
+	model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Running", LocalPort: 8080, ProbeLatency: 12 * time.Millisecond,
+			ProbeLatencies: []time.Duration{4 * time.Millisecond, 0, 32 * time.Millisecond, 12 * time.Millisecond}},
+		"db": {Name: "db", Status: "Running", LocalPort: 5432},
+	}))
+
+	if view := model.View(); !strings.Contains(view, "Latency") || !strings.Contains(view, "12ms") {
+		t.Errorf("Expected the Latency column with 12ms, got:\n%s", view)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	view := model.View()
+	if !strings.Contains(view, "Health Probe: http /healthz, last 12ms") || !strings.Contains(view, "▁✗█▃ (slowest 32ms)") {
+		t.Errorf("Expected the probe and its sparkline in the details, got:\n%s", view)
+	}
+
+	if line := sparkline([]time.Duration{time.Millisecond, 0, 8 * time.Millisecond}); line != "▁✗█" {
+		t.Errorf("Expected ▁✗█, got %s", line)
+	}
+}
+
+// TestModelLastHealthy tests the optional Last OK column and the last passing check in the detail view
+func TestModelLastHealthy(t *testing.T) {
+	var saved []config.UIState
+	defer func(original func(config.UIState) error) { saveUIState = original }(saveUIState)
+	saveUIState = func(state config.UIState) error {
+		saved = append(saved, state)
+		return nil
+	}
+
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	model.width = 200
+	model.height = 40
+	model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Degraded", LocalPort: 8080, LastHealthy: time.Now().Add(-10 * time.Minute)},
+		"db":  {Name: "db", Status: "Failed", LocalPort: 5432},
+	}))
+
+	if view := model.View(); strings.Contains(view, "Last OK") {
+		t.Errorf("Expected the Last OK column hidden by default, got:\n%s", view)
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'7'}})
+	cmd()
+	if len(saved) != 1 || !reflect.DeepEqual(saved[0].ShownColumns, []string{"lastok"}) || len(saved[0].HiddenColumns) != 0 {
+		t.Errorf("Expected the Last OK column to be saved as shown, got %+v", saved)
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view := model.View(); !strings.Contains(view, "Last OK") || !strings.Contains(view, "10m ago") {
+		t.Errorf("Expected the Last OK column with 10m ago, got:\n%s", view)
+	}
+
+	// Failed services are sorted first
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if view := model.View(); !strings.Contains(view, "Last OK: never") {
+		t.Errorf("Expected a failed service without passing checks to say so, got:\n%s", view)
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if view := model.View(); !strings.Contains(view, "Last OK: ") || !strings.Contains(view, "(10m ago)") {
+		t.Errorf("Expected the last passing check in the details, got:\n%s", view)
+	}
+}
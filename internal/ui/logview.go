@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// logTailBytes is how much of the end of a service log the log view reads
+const logTailBytes = 64 << 10
+
+// ServiceLogProvider is implemented by providers that write a log file per service
+type ServiceLogProvider interface {
+	// ServiceLogPath returns the log file of a service, or "" if it has none
+	ServiceLogPath(name string) string
+}
+
+// selectedLogPath returns the log file of the selected service, or ""
+func (m *Model) selectedLogPath() string {
+	provider, ok := m.manager.(ServiceLogProvider)
+	if !ok || m.selectedIndex >= len(m.serviceNames) {
+		return ""
+	}
+	return provider.ServiceLogPath(m.serviceNames[m.selectedIndex])
+}
+
+// openLogView switches to the log of the selected service, if it has one
+func (m *Model) openLogView() {
+	path := m.selectedLogPath()
+	if path == "" {
+		m.showActionMessage("No log file for this service; start kportforward with --log-dir")
+		return
+	}
+	m.logPath = path
+	m.viewMode = ViewLog
+	m.refreshLog()
+}
+
+// refreshLog reads the end of the shown log file again
+func (m *Model) refreshLog() {
+	lines, err := readLogTail(m.logPath, logTailBytes)
+	m.logLines = lines
+	m.logError = ""
+	if err != nil {
+		m.logError = err.Error()
+	}
+}
+
+// handleLogKeyPress handles keys in the log view
+func (m *Model) handleLogKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "backspace":
+		m.viewMode = ViewDetail
+		m.logLines = nil
+
+	case "o":
+		path := m.logPath
+		return m, func() tea.Msg {
+			if err := openURL(path); err != nil {
+				return ServiceActionMsg(fmt.Sprintf("Could not open %s: %v", path, err))
+			}
+			return ServiceActionMsg(fmt.Sprintf("Opened %s", path))
+		}
+	}
+
+	return m, nil
+}
+
+// renderLogView renders the last lines of the selected service's log that fit the screen
+func (m *Model) renderLogView() string {
+	name := ""
+	if m.selectedIndex < len(m.serviceNames) {
+		name = m.serviceNames[m.selectedIndex]
+	}
+
+	content := []string{
+		titleStyle.Render(fmt.Sprintf("Log: %s", name)),
+		helpStyle.Render(m.logPath),
+		"",
+	}
+
+	// Room for the title, path, help and the container's border and padding
+	available := m.height - 10
+	if available < 1 {
+		available = 1
+	}
+	lines := m.logLines
+	if len(lines) > available {
+		lines = lines[len(lines)-available:]
+	}
+	switch {
+	case m.logError != "":
+		content = append(content, errorMessageStyle.Render(m.logError))
+	case len(lines) == 0:
+		content = append(content, "(empty)")
+	}
+	for _, line := range lines {
+		content = append(content, truncateString(line, m.width-8))
+	}
+
+	content = append(content, "")
+	if m.actionMessage != "" {
+		content = append(content, m.actionMessage)
+	}
+	content = append(content, helpStyle.Render("[o] Open in viewer  [ESC] Back to details  [?] Help  [q] Quit"))
+
+	return containerStyle.
+		Width(m.width - 4).
+		Height(m.height - 2).
+		Render(strings.Join(content, "\n"))
+}
+
+// readLogTail returns the lines in up to limit bytes from the end of path
+func readLogTail(path string, limit int64) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	partial := info.Size() > limit
+	if partial {
+		if _, err := file.Seek(info.Size()-limit, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimRight(string(data), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	lines := strings.Split(text, "\n")
+	// The first line is cut off when reading from the middle of the file
+	if partial && len(lines) > 1 {
+		lines = lines[1:]
+	}
+	return lines, nil
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestModelLogView tests showing the end of the selected service's log file
+func TestModelLogView(t *testing.T) {
+	mockManager := &MockUIManagerProvider{}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, mockManager)
+	model.width = 200
+	model.height = 40
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Running"},
+	}))
+	model = updatedModel.(*Model)
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Without a log file the view stays on the details
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	if model.viewMode != ViewDetail || !strings.Contains(model.actionMessage, "--log-dir") {
+		t.Errorf("Expected a hint to use --log-dir, got view %d and %q", model.viewMode, model.actionMessage)
+	}
+
+	path := filepath.Join(t.TempDir(), "api.log")
+	if err := os.WriteFile(path, []byte("[2024-01-01 10:00:00] INFO: Started port-forward for api\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mockManager.logPath = path
+	if view := model.renderDetailView(); !strings.Contains(view, "[l] Log") {
+		t.Errorf("Expected the log key in the detail help, got:\n%s", view)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	if model.viewMode != ViewLog {
+		t.Fatalf("Expected log view, got %d", model.viewMode)
+	}
+	if view := model.View(); !strings.Contains(view, "Started port-forward for api") {
+		t.Errorf("Expected the log line in the log view, got:\n%s", view)
+	}
+
+	// New lines show up on the next tick
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("[2024-01-01 10:00:05] WARN: Port 8080 is already in use\n")
+	file.Close()
+	model.Update(TickMsg(time.Now()))
+	if view := model.View(); !strings.Contains(view, "Port 8080 is already in use") {
+		t.Errorf("Expected the appended line after a tick, got:\n%s", view)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.viewMode != ViewDetail {
+		t.Errorf("Expected Esc to go back to the details, got %d", model.viewMode)
+	}
+}
+
+// TestReadLogTail tests that reading from the middle of a file skips the cut-off line
+func TestReadLogTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	if err := os.WriteFile(path, []byte("first line\nsecond\nthird\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := readLogTail(path, 15)
+	if err != nil || strings.Join(lines, ",") != "second,third" {
+		t.Errorf("Expected second and third, got %v (%v)", lines, err)
+	}
+	if lines, _ := readLogTail(path, logTailBytes); len(lines) != 3 {
+		t.Errorf("Expected all lines of a small file, got %v", lines)
+	}
+}
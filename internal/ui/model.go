@@ -2,7 +2,6 @@ package ui
 
 import (
 	"fmt"
-	"os"
 	"sort"
 	"strings"
 	"time"
@@ -23,22 +22,37 @@ const (
 	SortByType
 	SortByPort
 	SortByUptime
+	SortByRestarts
+	SortByErrors
+	SortByProblems
 )
 
 var sortFieldNames = map[SortField]string{
-	SortByName:   "Name",
-	SortByStatus: "Status",
-	SortByType:   "Type",
-	SortByPort:   "Port",
-	SortByUptime: "Uptime",
+	SortByName:     "Name",
+	SortByStatus:   "Status",
+	SortByType:     "Type",
+	SortByPort:     "Port",
+	SortByUptime:   "Uptime",
+	SortByRestarts: "Restarts",
+	SortByErrors:   "Errors",
+	SortByProblems: "Problems",
 }
 
+// actionMessageDuration is how long action results stay in the footer
+const actionMessageDuration = 5 * time.Second
+
+// openURL opens service links in the browser; replaced in tests
+var openURL = utils.OpenURL
+
 // ViewMode represents different view modes
 type ViewMode int
 
 const (
 	ViewTable ViewMode = iota
 	ViewDetail
+	ViewLog
+	ViewHelp
+	ViewProfiles
 )
 
 // UIURLProvider interface for accessing UI handler URLs
@@ -46,6 +60,18 @@ type UIManagerProvider interface {
 	GetGRPCUIURL(serviceName string) string
 	GetSwaggerUIURL(serviceName string) string
 	GetGlobalAccessStatus() bool
+	IsIdle() bool
+	ResumeFromIdle()
+}
+
+// ServiceRestarter is implemented by providers that can restart a single service
+type ServiceRestarter interface {
+	RestartService(name string) error
+}
+
+// ServiceToggler is implemented by providers that can disable and re-enable services
+type ServiceToggler interface {
+	SetServiceDisabled(name string, disabled bool) error
 }
 
 // Model represents the main TUI model
@@ -54,6 +80,7 @@ type Model struct {
 	services        map[string]config.ServiceStatus
 	serviceConfigs  map[string]config.Service
 	serviceNames    []string
+	disabled        map[string]bool // Services the user disabled; listed so they can be enabled again
 	kubeContext     string
 	lastUpdate      time.Time
 	updateAvailable bool
@@ -62,6 +89,26 @@ type Model struct {
 	// Global access status
 	globalAccessHealthy bool
 
+	// Set while all services are stopped by the idle timeout
+	idle bool
+
+	// Observer mode: status can be viewed but services cannot be changed
+	readOnly bool
+
+	// Result of the last action taken from this TUI, cleared after actionMessageExpiry
+	// (a zero expiry keeps it until the action finishes)
+	actionMessage       string
+	actionMessageExpiry time.Time
+
+	// Display formats from uiOptions
+	uptimeFormat    string
+	timestampFormat string
+
+	// Alert on critical transitions from uiOptions, see alert.go
+	alert        string
+	alertMessage string
+	flashUntil   time.Time
+
 	// UI Handler status
 	grpcUIEnabled    bool
 	swaggerUIEnabled bool
@@ -70,10 +117,59 @@ type Model struct {
 	manager UIManagerProvider
 
 	// UI state
-	selectedIndex int
-	sortField     SortField
-	sortReverse   bool
-	viewMode      ViewMode
+	selectedIndex  int
+	scrollOffset   int // First service row shown in the table, see scroll.go
+	sortField      SortField
+	sortReverse    bool
+	viewMode       ViewMode
+	helpReturnMode ViewMode // View to go back to when the help overlay closes
+
+	// Log view of the selected service, see logview.go
+	logPath  string
+	logLines []string
+	logError string
+
+	// Output pane of the detail view, see outputpane.go
+	output outputPane
+
+	// Filter matches service names, descriptions and owners; filtering is true while typing it
+	filterText string
+	filtering  bool
+
+	// Profile picker overlay, see profiles.go
+	profiles profilePicker
+
+	// Pinned services are listed first and shown regardless of the filter, see pins.go
+	pinned map[string]bool
+
+	// Columns hidden by the user and optional ones shown; pickingColumns is true while
+	// choosing them, see columns.go
+	hiddenColumns  map[tableColumn]bool
+	shownColumns   map[tableColumn]bool
+	pickingColumns bool
+
+	// Process holding a strictPort service's port, killed once confirmed, see portconflict.go
+	pendingKill *pendingKill
+
+	// Whether an update is being installed after pressing U, see update.go
+	updating bool
+
+	// Issue report waiting to be copied or written after pressing I, and the services
+	// an issue report was suggested for, see issue.go
+	pendingIssue   *pendingIssue
+	issueOffered   map[string]bool
+	issueThreshold int
+
+	// Terminal size waiting to settle, see resize.go, and the table layout for the
+	// current width, see columns.go
+	pendingSize *tea.WindowSizeMsg
+	resizeSeq   int
+	layout      layoutCache
+
+	// Mouse support, only enabled with --mouse as it takes over text selection, see mouse.go
+	mouse        bool
+	lastClick    time.Time
+	lastClickRow int
 
 	// Display settings
 	width       int
@@ -100,6 +196,15 @@ type UIHandlerStatusMsg struct {
 	SwaggerUIEnabled bool
 }
 
+// ServiceConfigsMsg replaces the service configurations after a config reload
+type ServiceConfigsMsg map[string]config.Service
+
+// DisabledServicesMsg replaces the list of disabled services
+type DisabledServicesMsg []string
+
+// ServiceActionMsg reports the outcome of an action such as a restart
+type ServiceActionMsg string
+
 // TickMsg represents a timer tick
 type TickMsg time.Time
 
@@ -110,10 +215,10 @@ func NewModel(statusChan <-chan map[string]config.ServiceStatus, serviceConfigs
 		serviceConfigs:      serviceConfigs,
 		serviceNames:        make([]string, 0),
 		selectedIndex:       0,
-		sortField:           SortByName,
+		sortField:           SortByProblems,
 		sortReverse:         false,
 		viewMode:            ViewTable,
-		refreshRate:         250 * time.Millisecond,
+		refreshRate:         defaultRefreshRate,
 		statusChan:          statusChan,
 		manager:             manager,
 		globalAccessHealthy: true, // Start optimistically
@@ -122,21 +227,28 @@ func NewModel(statusChan <-chan map[string]config.ServiceStatus, serviceConfigs
 
 // Init initializes the model
 func (m *Model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		m.listenForStatusUpdates(),
 		m.tickEvery(),
-	)
+	}
+	if m.mouse {
+		cmds = append(cmds, tea.EnableMouseCellMotion)
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update handles messages and updates the model
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
+		return m, m.handleWindowSize(msg)
+
+	case resizeSettledMsg:
+		m.applySettledSize(msg)
 		return m, nil
 
 	case StatusUpdateMsg:
+		previous, wasHealthy := m.services, m.globalAccessHealthy
 		m.services = map[string]config.ServiceStatus(msg)
 		m.updateServiceNames()
 		m.lastUpdate = time.Now()
@@ -144,18 +256,49 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update global access status if we have a manager
 		if m.manager != nil {
 			m.globalAccessHealthy = m.manager.GetGlobalAccessStatus()
+			m.idle = m.manager.IsIdle()
 		}
 
-		return m, nil
+		m.offerIssueReports()
+		return m, m.alertOnTransitions(previous, wasHealthy)
 
 	case ContextUpdateMsg:
 		m.kubeContext = string(msg)
 		return m, nil
 
+	case ServiceActionMsg:
+		m.showActionMessage(string(msg))
+		return m, nil
+
+	case portOwnerMsg:
+		m.handlePortOwner(msg)
+		return m, nil
+
+	case issueReportMsg:
+		m.handleIssueReport(msg)
+		return m, nil
+
+	case ServiceConfigsMsg:
+		m.serviceConfigs = map[string]config.Service(msg)
+		m.invalidateLayout()
+		m.updateServiceNames()
+		return m, nil
+
+	case DisabledServicesMsg:
+		m.disabled = make(map[string]bool, len(msg))
+		for _, name := range msg {
+			m.disabled[name] = true
+		}
+		m.updateServiceNames()
+		return m, nil
+
 	case UpdateAvailableMsg:
 		m.updateAvailable = bool(msg)
 		return m, nil
 
+	case updateAppliedMsg:
+		return m, m.handleUpdateApplied(msg)
+
 	case UIHandlerStatusMsg:
 		m.grpcUIEnabled = msg.GRPCUIEnabled
 		m.swaggerUIEnabled = msg.SwaggerUIEnabled
@@ -170,6 +313,15 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case TickMsg:
+		if m.actionMessage != "" && !m.actionMessageExpiry.IsZero() && time.Time(msg).After(m.actionMessageExpiry) {
+			m.actionMessage = ""
+		}
+		switch m.viewMode {
+		case ViewLog:
+			m.refreshLog()
+		case ViewDetail:
+			m.refreshOutput()
+		}
 		return m, tea.Batch(
 			m.listenForStatusUpdates(),
 			m.tickEvery(),
@@ -177,6 +329,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		return m.handleKeyPress(msg)
+
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
 	}
 
 	return m, nil
@@ -188,9 +343,19 @@ func (m *Model) View() string {
 		return "Initializing..."
 	}
 
+	if m.idle {
+		return m.renderIdleView()
+	}
+
 	switch m.viewMode {
 	case ViewDetail:
 		return m.renderDetailView()
+	case ViewLog:
+		return m.renderLogView()
+	case ViewHelp:
+		return m.renderHelpView()
+	case ViewProfiles:
+		return m.renderProfileView()
 	default:
 		return m.renderTableView()
 	}
@@ -198,9 +363,36 @@ func (m *Model) View() string {
 
 // handleKeyPress processes keyboard input
 func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.idle {
+		return m.handleIdleKeyPress(msg)
+	}
+
+	if msg.String() == "?" && m.viewMode != ViewHelp && !m.filtering {
+		m.openHelp()
+		return m, nil
+	}
+
+	// + refreshes faster, - slower, in every view
+	if !m.filtering {
+		switch msg.String() {
+		case "+":
+			m.changeRefreshRate(0.5)
+			return m, nil
+		case "-":
+			m.changeRefreshRate(2)
+			return m, nil
+		}
+	}
+
 	switch m.viewMode {
 	case ViewDetail:
 		return m.handleDetailKeyPress(msg)
+	case ViewLog:
+		return m.handleLogKeyPress(msg)
+	case ViewHelp:
+		return m.handleHelpKeyPress(msg)
+	case ViewProfiles:
+		return m.handleProfileKeyPress(msg)
 	default:
 		return m.handleTableKeyPress(msg)
 	}
@@ -208,22 +400,58 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // handleTableKeyPress handles keys in table view
 func (m *Model) handleTableKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.filtering {
+		return m.handleFilterKeyPress(msg)
+	}
+	if m.pickingColumns {
+		return m.handleColumnKeyPress(msg)
+	}
+	if m.pendingKill != nil {
+		return m.handleKillKeyPress(msg)
+	}
+	if m.pendingIssue != nil {
+		return m.handleIssueKeyPress(msg)
+	}
+
 	switch msg.String() {
 	case "q", "ctrl+c":
 		return m, tea.Quit
 
-	case "up", "k":
-		if m.selectedIndex > 0 {
-			m.selectedIndex--
+	case "/":
+		m.filtering = true
+
+	case "esc":
+		if m.filterText != "" {
+			m.filterText = ""
+			m.updateServiceNames()
 		}
 
+	case "up", "k":
+		m.moveSelection(-1)
+		m.followSelection()
+
 	case "down", "j":
-		if m.selectedIndex < len(m.serviceNames)-1 {
-			m.selectedIndex++
-		}
+		m.moveSelection(1)
+		m.followSelection()
+
+	case "pgup":
+		m.moveSelection(-m.visibleTableRows())
+		m.followSelection()
+
+	case "pgdown":
+		m.moveSelection(m.visibleTableRows())
+		m.followSelection()
+
+	case "home":
+		m.selectedIndex = 0
+		m.followSelection()
+
+	case "end":
+		m.moveSelection(len(m.serviceNames))
+		m.followSelection()
 
 	case "enter", " ":
-		m.viewMode = ViewDetail
+		m.openDetail()
 		return m, nil
 
 	case "n":
@@ -246,14 +474,177 @@ func (m *Model) handleTableKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.sortField = SortByUptime
 		m.updateServiceNames()
 
+	case "c":
+		m.sortField = SortByRestarts
+		m.updateServiceNames()
+
+	case "e":
+		m.sortField = SortByErrors
+		m.updateServiceNames()
+
+	case "!":
+		m.sortField = SortByProblems
+		m.updateServiceNames()
+
 	case "r":
 		m.sortReverse = !m.sortReverse
 		m.updateServiceNames()
+
+	case "x", "R":
+		return m, m.restartSelected()
+
+	case "d":
+		return m, m.toggleSelected()
+
+	case "K":
+		return m, m.findPortOwnerOfSelected()
+
+	case "I":
+		return m, m.prepareIssueReport()
+
+	case "U":
+		return m, m.applyUpdate()
+
+	case "P":
+		return m, m.togglePin()
+
+	case "w":
+		m.openProfilePicker()
+
+	case "v":
+		m.pickingColumns = true
+	}
+
+	return m, nil
+}
+
+// handleFilterKeyPress edits the filter while it is being typed
+func (m *Model) handleFilterKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filterText = ""
+
+	case tea.KeyEnter:
+		m.filtering = false
+
+	case tea.KeyBackspace:
+		if runes := []rune(m.filterText); len(runes) > 0 {
+			m.filterText = string(runes[:len(runes)-1])
+		}
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.filterText += string(msg.Runes)
+
+	default:
+		return m, nil
 	}
 
+	m.selectedIndex = 0
+	m.updateServiceNames()
 	return m, nil
 }
 
+// matchesFilter reports whether a service name, description, owner, namespace, type or
+// status contains the filter text, or the name contains its characters in order, so
+// "bapi" finds "billing-api"
+func (m *Model) matchesFilter(name string) bool {
+	if m.filterText == "" {
+		return true
+	}
+	filter := strings.ToLower(m.filterText)
+	serviceConfig := m.serviceConfigs[name]
+	fields := []string{
+		name,
+		serviceConfig.Description,
+		serviceConfig.Owner,
+		serviceConfig.Namespace,
+		m.getServiceType(name),
+		m.statusOf(name).Status,
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), filter) {
+			return true
+		}
+	}
+	return fuzzyMatch(strings.ToLower(name), filter)
+}
+
+// fuzzyMatch reports whether text contains the characters of pattern in order
+func fuzzyMatch(text, pattern string) bool {
+	remaining := []rune(pattern)
+	for _, r := range text {
+		if len(remaining) == 0 {
+			break
+		}
+		if r == remaining[0] {
+			remaining = remaining[1:]
+		}
+	}
+	return len(remaining) == 0
+}
+
+// showActionMessage shows msg in the footer for actionMessageDuration
+func (m *Model) showActionMessage(msg string) {
+	m.actionMessage = msg
+	m.actionMessageExpiry = time.Now().Add(actionMessageDuration)
+}
+
+// restartSelected returns a command restarting the selected service, if allowed
+func (m *Model) restartSelected() tea.Cmd {
+	if m.selectedIndex >= len(m.serviceNames) {
+		return nil
+	}
+	name := m.serviceNames[m.selectedIndex]
+
+	restarter, ok := m.manager.(ServiceRestarter)
+	if m.readOnly || !ok {
+		m.showActionMessage("Read-only: services cannot be restarted from this view")
+		return nil
+	}
+
+	m.actionMessage = fmt.Sprintf("Restarting %s…", name)
+	m.actionMessageExpiry = time.Time{}
+	return func() tea.Msg {
+		if err := restarter.RestartService(name); err != nil {
+			return ServiceActionMsg(fmt.Sprintf("Restart of %s failed: %v", name, err))
+		}
+		return ServiceActionMsg(fmt.Sprintf("Restarted %s", name))
+	}
+}
+
+// toggleSelected returns a command disabling the selected service, or enabling it if it
+// is disabled, if allowed
+func (m *Model) toggleSelected() tea.Cmd {
+	if m.selectedIndex >= len(m.serviceNames) {
+		return nil
+	}
+	name := m.serviceNames[m.selectedIndex]
+
+	toggler, ok := m.manager.(ServiceToggler)
+	if m.readOnly || !ok {
+		m.showActionMessage("Read-only: services cannot be disabled from this view")
+		return nil
+	}
+
+	disable := !m.disabled[name]
+	verb, done := "Enabling", "Enabled"
+	if disable {
+		verb, done = "Disabling", "Disabled"
+	}
+	m.actionMessage = fmt.Sprintf("%s %s…", verb, name)
+	m.actionMessageExpiry = time.Time{}
+	return func() tea.Msg {
+		if err := toggler.SetServiceDisabled(name, disable); err != nil {
+			return ServiceActionMsg(fmt.Sprintf("%s %s failed: %v", verb, name, err))
+		}
+		return ServiceActionMsg(fmt.Sprintf("%s %s (saved to config)", done, name))
+	}
+}
+
 // handleDetailKeyPress handles keys in detail view
 func (m *Model) handleDetailKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -263,11 +654,83 @@ func (m *Model) handleDetailKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc", "backspace":
 		m.viewMode = ViewTable
 		return m, nil
+
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		return m, m.openLink(int(msg.String()[0] - '1'))
+
+	case "l":
+		m.openLogView()
+
+	default:
+		m.handleOutputKeyPress(msg, m.outputPaneLines())
+	}
+
+	return m, nil
+}
+
+// openLink returns a command opening the selected service's link at index in the browser
+func (m *Model) openLink(index int) tea.Cmd {
+	if m.selectedIndex >= len(m.serviceNames) {
+		return nil
+	}
+	links := m.serviceConfigs[m.serviceNames[m.selectedIndex]].Links
+	if index >= len(links) {
+		return nil
+	}
+
+	link := links[index]
+	return func() tea.Msg {
+		if err := openURL(link.URL); err != nil {
+			return ServiceActionMsg(fmt.Sprintf("Could not open %s: %v", link.Name, err))
+		}
+		return ServiceActionMsg(fmt.Sprintf("Opened %s", link.Name))
+	}
+}
+
+// handleIdleKeyPress resumes all services on any key except quit
+func (m *Model) handleIdleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	}
+
+	// Observers wait for the host to resume
+	if m.readOnly {
+		return m, nil
 	}
 
+	if m.manager != nil {
+		m.manager.ResumeFromIdle()
+	}
+	m.idle = false
 	return m, nil
 }
 
+// renderIdleView renders the screen shown while services are stopped for inactivity
+func (m *Model) renderIdleView() string {
+	message := lipgloss.JoinVertical(
+		lipgloss.Center,
+		titleStyle.Render("💤 Idle"),
+		"",
+		fmt.Sprintf("No connections to any of the %d services — all port-forwards were stopped.", len(m.serviceNames)),
+		"",
+		helpStyle.Render(m.idleHelp()),
+	)
+
+	return containerStyle.
+		Width(m.width - 4).
+		Height(m.height - 2).
+		Render(lipgloss.Place(m.width-8, m.height-4, lipgloss.Center, lipgloss.Center, message))
+}
+
+// idleHelp returns the key help shown on the idle screen
+func (m *Model) idleHelp() string {
+	if m.readOnly {
+		return "Read-only: services resume when the host does, [q] to quit"
+	}
+	return "Press any key to resume, [q] to quit"
+}
+
 // renderTableView renders the main table view
 func (m *Model) renderTableView() string {
 	// Header
@@ -302,11 +765,26 @@ func (m *Model) renderDetailView() string {
 	}
 
 	serviceName := m.serviceNames[m.selectedIndex]
-	service, exists := m.services[serviceName]
-	if !exists {
+	if _, exists := m.services[serviceName]; !exists && !m.disabled[serviceName] {
 		return "Service not found"
 	}
 
+	details := append(m.detailLines(serviceName), "")
+	details = append(details, m.renderOutputPane(m.outputPaneLines())...)
+	details = append(details, m.detailFooter(serviceName)...)
+
+	content := strings.Join(details, "\n")
+
+	return containerStyle.
+		Width(m.width - 4).
+		Height(m.height - 2).
+		Render(content)
+}
+
+// detailLines returns the details of a service shown above the output pane
+func (m *Model) detailLines(serviceName string) []string {
+	service := m.statusOf(serviceName)
+
 	// Service details
 	details := []string{
 		titleStyle.Render(fmt.Sprintf("Service Details: %s", serviceName)),
@@ -317,36 +795,73 @@ func (m *Model) renderDetailView() string {
 		fmt.Sprintf("Restart Count: %d", service.RestartCount),
 	}
 
+	if len(service.ExtraPorts) > 0 {
+		details = append(details, fmt.Sprintf("Extra Ports: %s", m.formatExtraPorts(serviceName, service)))
+	}
+	if service.PortHolder != "" {
+		details = append(details, fmt.Sprintf("Configured Port Held By: %s", service.PortHolder))
+	}
+
+	if service.Pod != "" {
+		details = append(details, fmt.Sprintf("Pod: %s", service.Pod))
+	}
+
+	serviceConfig := m.serviceConfigs[serviceName]
+	if serviceConfig.Profile != "" {
+		details = append(details, fmt.Sprintf("Profile: %s", serviceConfig.Profile))
+	}
+	if serviceConfig.Kubectl.Context != "" {
+		details = append(details, fmt.Sprintf("Context: %s", serviceConfig.Kubectl.Context))
+	}
+	if serviceConfig.Description != "" {
+		details = append(details, fmt.Sprintf("Description: %s", serviceConfig.Description))
+	}
+	if serviceConfig.Owner != "" {
+		details = append(details, fmt.Sprintf("Owner: %s", serviceConfig.Owner))
+	}
+	if len(serviceConfig.Links) > 0 {
+		details = append(details, "", "Links:")
+		for i, link := range serviceConfig.Links {
+			details = append(details, fmt.Sprintf("  [%d] %s: %s", i+1, link.Name, link.URL))
+		}
+	}
+
+	if m.isServiceExposed(serviceName) {
+		bindAddress := m.serviceConfigs[serviceName].BindAddress
+		details = append(details, fmt.Sprintf("Bind Address: %s %s", bindAddress,
+			FormatExposedBadge()+" reachable from the network (insecureExpose)"))
+	}
+
 	if !service.StartTime.IsZero() {
 		uptime := time.Since(service.StartTime)
-		details = append(details, fmt.Sprintf("Uptime: %s", utils.FormatUptime(uptime)))
+		details = append(details,
+			fmt.Sprintf("Started: %s", utils.FormatTimestamp(service.StartTime, m.timestampFormat)),
+			fmt.Sprintf("Uptime: %s", utils.FormatUptimeAs(uptime, m.uptimeFormat)))
 	}
 
 	// Add URL information if service is running
 	if service.Status == "Running" {
-		serviceType := m.getServiceType(serviceName)
-		switch serviceType {
-		case "web":
-			details = append(details, fmt.Sprintf("🌐 Web URL: http://localhost:%d", service.LocalPort))
-		case "rest":
-			if m.swaggerUIEnabled && m.manager != nil {
-				swaggerURL := m.manager.GetSwaggerUIURL(serviceName)
-				if swaggerURL != "" {
-					details = append(details, fmt.Sprintf("📋 Swagger UI: %s", swaggerURL))
-				} else {
-					details = append(details, fmt.Sprintf("🔗 REST API: http://localhost:%d", service.LocalPort))
-				}
-			}
-		case "rpc":
-			if m.grpcUIEnabled && m.manager != nil {
-				grpcURL := m.manager.GetGRPCUIURL(serviceName)
-				if grpcURL != "" {
-					details = append(details, fmt.Sprintf("⚡ gRPC UI: %s", grpcURL))
-				}
+		if icon, url := m.serviceURL(service, serviceName); url != "" {
+			label := "URL"
+			switch icon {
+			case swaggerIcon:
+				label = "Swagger UI"
+			case grpcUIIcon:
+				label = "gRPC UI"
 			}
+			details = append(details, strings.TrimSpace(fmt.Sprintf("%s %s: %s", icon, label, url)))
 		}
 	}
 
+	if !service.LastHealthy.IsZero() {
+		details = append(details, fmt.Sprintf("Last OK: %s (%s)",
+			utils.FormatTimestamp(service.LastHealthy, m.timestampFormat), m.formatLastHealthy(service)))
+	} else if service.Status == "Degraded" || service.Status == "Failed" || service.Status == "Reconnecting" {
+		details = append(details, "Last OK: never")
+	}
+	details = append(details, m.downtimeDetails(service.Downtime)...)
+	details = append(details, m.probeDetails(serviceName)...)
+
 	if service.LastError != "" {
 		details = append(details,
 			"",
@@ -363,17 +878,25 @@ func (m *Model) renderDetailView() string {
 		)
 	}
 
-	details = append(details,
-		"",
-		helpStyle.Render("[ESC] Back to table view  [q] Quit"),
-	)
+	return details
+}
 
-	content := strings.Join(details, "\n")
+// detailFooter returns the action message and help line below the output pane
+func (m *Model) detailFooter(serviceName string) []string {
+	help := "[ESC] Back to table view  [?] Help  [q] Quit"
+	if m.selectedLogPath() != "" {
+		help = "[l] Log  " + help
+	}
+	if len(m.serviceConfigs[serviceName].Links) > 0 {
+		help = "[1-9] Open link  " + help
+	}
+	help = m.outputPaneHelp() + "  " + help
 
-	return containerStyle.
-		Width(m.width - 4).
-		Height(m.height - 2).
-		Render(content)
+	footer := []string{""}
+	if m.actionMessage != "" {
+		footer = append(footer, m.actionMessage)
+	}
+	return append(footer, helpStyle.Render(help))
 }
 
 // renderHeader renders the header section
@@ -391,7 +914,13 @@ func (m *Model) renderHeader() string {
 
 	// Global access status
 	globalStatus := ""
-	if m.globalAccessHealthy {
+	if m.globalAccessHealthy && m.isClusterDegraded() {
+		// Many services failed at once; a single banner replaces per-service noise
+		globalStatus = lipgloss.NewStyle().
+			Foreground(warningColor).
+			Bold(true).
+			Render("⚠ Cluster connectivity degraded - restarts paused")
+	} else if m.globalAccessHealthy {
 		globalStatus = lipgloss.NewStyle().
 			Foreground(successColor).
 			Render("✅ Connected")
@@ -407,18 +936,16 @@ func (m *Model) renderHeader() string {
 		// Create basic update notice
 		basicNotice := "Update Available!"
 
-		// Check if this is a Homebrew installation
-		execPath, _ := os.Executable()
-		isHomebrew := strings.Contains(execPath, "/Cellar/kportforward") ||
-			strings.Contains(execPath, "/opt/homebrew")
-
-		// If we have detailed update info and it's a Homebrew installation,
-		// include the version and update method
+		// If we have detailed update info, include the version and update method
 		if m.UpdateInfo != nil {
-			if isHomebrew {
+			switch {
+			case isHomebrewExecutable():
 				basicNotice = fmt.Sprintf("Update Available! (%s → %s via brew upgrade)",
 					m.UpdateInfo.CurrentVersion, m.UpdateInfo.LatestVersion)
-			} else {
+			case m.canApplyUpdate():
+				basicNotice = fmt.Sprintf("Update Available! (%s → %s) Press U to update and restart",
+					m.UpdateInfo.CurrentVersion, m.UpdateInfo.LatestVersion)
+			default:
 				basicNotice = fmt.Sprintf("Update Available! (%s → %s)",
 					m.UpdateInfo.CurrentVersion, m.UpdateInfo.LatestVersion)
 			}
@@ -438,11 +965,26 @@ func (m *Model) renderHeader() string {
 
 	status := fmt.Sprintf("Services (%d/%d running)", running, total)
 
-	return headerStyle.Render(
+	readOnly := ""
+	if m.readOnly {
+		readOnly = readOnlyBadgeStyle.Render(readOnlyBadgeText)
+	}
+
+	style := headerStyle
+	if flashing, highlighted := m.flashing(time.Now()); flashing {
+		status += "  🔔 " + m.alertMessage
+		if highlighted {
+			style = headerAlertStyle
+		}
+	}
+
+	return style.Render(
 		lipgloss.JoinHorizontal(
 			lipgloss.Left,
 			title,
 			"  ",
+			readOnly,
+			"  ",
 			context,
 			"  ",
 			globalStatus,
@@ -460,102 +1002,140 @@ func (m *Model) renderTable() string {
 		return "No services configured"
 	}
 
-	// Calculate column widths based on terminal width
-	nameWidth := 25
-	statusWidth := 15 // Increased to fit "Reconnecting" status
-	urlWidth := 35    // Increased to account for emoji icons
-	typeWidth := 8
-	portWidth := 6 // Width for port number
-	uptimeWidth := 10
-	errorWidth := m.width - nameWidth - statusWidth - urlWidth - typeWidth - portWidth - uptimeWidth - 24
-
-	// Ensure minimum widths to prevent negative values
-	if errorWidth < 10 {
-		errorWidth = 10
-		urlWidth = m.width - nameWidth - statusWidth - typeWidth - portWidth - uptimeWidth - errorWidth - 24
-	}
-
-	// Ensure urlWidth is never negative or too small
-	if urlWidth < 5 {
-		urlWidth = 5
-	}
+	layout := m.tableLayout()
 
 	// Table header
-	headers := []string{
-		FormatTableHeader(fmt.Sprintf("%-*s", nameWidth, "Name")),
-		FormatTableHeader(fmt.Sprintf("%-*s", statusWidth, "Status")),
-		FormatTableHeader(fmt.Sprintf("%-*s", urlWidth, "URL")),
-		FormatTableHeader(fmt.Sprintf("%-*s", typeWidth, "Type")),
-		FormatTableHeader(fmt.Sprintf("%-*s", portWidth, "Port")),
-		FormatTableHeader(fmt.Sprintf("%-*s", uptimeWidth, "Uptime")),
-		FormatTableHeader(fmt.Sprintf("%-*s", errorWidth, "Error/Status")),
+	var headers []string
+	for _, column := range []struct {
+		title string
+		width int
+	}{
+		{"Profile", layout.profile},
+		{"Name", layout.name},
+		{"Status", layout.status},
+		{"URL", layout.url},
+		{"Type", layout.serviceType},
+		{"Port", layout.port},
+		{"Latency", layout.latency},
+		{"Uptime", layout.uptime},
+		{"Last OK", layout.lastOK},
+		{"Error/Status", layout.errorStatus},
+	} {
+		if column.width > 0 {
+			headers = append(headers, FormatTableHeader(fmt.Sprintf("%-*.*s", column.width, column.width, column.title)))
+		}
 	}
 
 	headerRow := strings.Join(headers, " ")
 
-	// Table rows
+	// Table rows; only those that fit the screen are rendered
 	rows := []string{headerRow}
 
-	for i, serviceName := range m.serviceNames {
-		service := m.services[serviceName]
+	start, end := m.visibleRange()
+	for i := start; i < end; i++ {
+		serviceName := m.serviceNames[i]
+		service := m.statusOf(serviceName)
 		selected := (i == m.selectedIndex)
 
-		// Get raw content for each column
-		nameContent := truncateString(serviceName, nameWidth)
-		statusContent := service.Status
-		urlContent := m.formatServiceURL(service, serviceName, urlWidth)
-		typeContent := truncateString(m.getServiceType(serviceName), typeWidth)
-
-		// Port column content
-		portContent := fmt.Sprintf("%d", service.LocalPort)
-		if service.LocalPort == 0 {
-			portContent = "-"
-		}
-
-		uptimeContent := "-"
-		if !service.StartTime.IsZero() {
-			uptime := time.Since(service.StartTime)
-			uptimeContent = utils.FormatUptime(uptime)
-		}
-
-		// Show status message if no error, otherwise show error
-		errorContent := service.LastError
-		if errorContent == "" && service.StatusMessage != "" {
-			errorContent = service.StatusMessage
-		}
-		errorContent = truncateString(errorContent, errorWidth)
-
-		// Create columns with exact width (pad first, then style)
-		nameCol := fmt.Sprintf("%-*s", nameWidth, nameContent)
-		statusCol := fmt.Sprintf("%s %-*s", GetStatusIndicator(service.Status), statusWidth-2, statusContent)
-
-		// Handle URL with proper width - style only the actual URL part
-		var urlCol string
-		if service.Status == "Running" || service.Status == "Degraded" ||
-			service.Status == "Connecting" || service.Status == "Reconnecting" {
-			// Only style if it's an actual URL, then pad to correct width using visual width
-			styledURL := FormatURL(urlContent)
-			visualWidthOfContent := visualWidth(urlContent)
-			padding := urlWidth - visualWidthOfContent
-			if padding < 0 {
-				padding = 0
+		// Get raw content for each column; the profile prefix is shown in its own column
+		profile := m.serviceConfigs[serviceName].Profile
+		displayName := serviceName
+		if layout.profile > 0 && profile != "" {
+			displayName = strings.TrimPrefix(serviceName, profile+"/")
+		}
+		if m.pinned[serviceName] {
+			displayName = pinnedMarker + displayName
+		}
+
+		// Create columns with exact width (pad first, then style), skipping hidden ones
+		var columns []string
+		if layout.profile > 0 {
+			columns = append(columns, fmt.Sprintf("%-*s", layout.profile, profile))
+		}
+
+		nameContent := truncateString(displayName, layout.name)
+		nameCol := fmt.Sprintf("%-*s", layout.name, nameContent)
+		if m.isServiceExposed(serviceName) {
+			// Keep room for the badge so the column width stays fixed
+			badgeWidth := len(exposedBadgeText) + 1
+			nameContent = truncateString(displayName, layout.name-badgeWidth)
+			nameCol = nameContent + " " + FormatExposedBadge() +
+				strings.Repeat(" ", max(layout.name-len(nameContent)-badgeWidth, 0))
+		}
+		columns = append(columns,
+			nameCol,
+			fmt.Sprintf("%s %-*s", GetStatusIndicator(service.Status), layout.status-2, service.Status),
+		)
+
+		if layout.url > 0 {
+			// Handle URL with proper width - style only the actual URL part
+			urlContent := m.formatServiceURL(service, serviceName, layout.url)
+			if service.Status == "Running" || service.Status == "Degraded" ||
+				service.Status == "Connecting" || service.Status == "Reconnecting" {
+				// Only style if it's an actual URL, then pad to correct width using visual width
+				padding := max(layout.url-visualWidth(urlContent), 0)
+				columns = append(columns, FormatURL(urlContent)+strings.Repeat(" ", padding))
+			} else {
+				columns = append(columns, fmt.Sprintf("%-*s", layout.url, urlContent))
 			}
-			urlCol = styledURL + strings.Repeat(" ", padding)
-		} else {
-			urlCol = fmt.Sprintf("%-*s", urlWidth, urlContent)
 		}
 
-		typeCol := fmt.Sprintf("%-*s", typeWidth, typeContent)
-		portCol := fmt.Sprintf("%-*s", portWidth, portContent)
-		uptimeCol := fmt.Sprintf("%-*s", uptimeWidth, uptimeContent)
-		errorCol := fmt.Sprintf("%-*s", errorWidth, errorContent)
+		if layout.serviceType > 0 {
+			typeContent := truncateString(m.getServiceType(serviceName), layout.serviceType)
+			columns = append(columns, fmt.Sprintf("%-*s", layout.serviceType, typeContent))
+		}
 
-		// Combine row with single spaces between columns
-		rowContent := nameCol + " " + statusCol + " " + urlCol + " " + typeCol + " " + portCol + " " + uptimeCol + " " + errorCol
+		if layout.port > 0 {
+			portContent := fmt.Sprintf("%d", service.LocalPort)
+			if service.LocalPort == 0 {
+				portContent = "-"
+			}
+			columns = append(columns, fmt.Sprintf("%-*s", layout.port, portContent))
+		}
 
+		if layout.latency > 0 {
+			latencyContent := "-"
+			if m.serviceConfigs[serviceName].HealthCheck.HasLatencyProbe() {
+				latencyContent = formatLatency(service)
+			}
+			columns = append(columns, fmt.Sprintf("%-*s", layout.latency, latencyContent))
+		}
+
+		if layout.uptime > 0 {
+			uptimeContent := "-"
+			if !service.StartTime.IsZero() {
+				uptime := time.Since(service.StartTime)
+				uptimeContent = utils.FormatUptimeAs(uptime, m.uptimeFormat)
+			}
+			columns = append(columns, fmt.Sprintf("%-*s", layout.uptime, uptimeContent))
+		}
+
+		if layout.lastOK > 0 {
+			columns = append(columns, fmt.Sprintf("%-*s", layout.lastOK, m.formatLastHealthy(service)))
+		}
+
+		if layout.errorStatus > 0 {
+			// Show status message if no error, otherwise show error
+			errorContent := service.LastError
+			if errorContent == "" && service.StatusMessage != "" {
+				errorContent = service.StatusMessage
+			}
+			errorContent = truncateString(errorContent, layout.errorStatus)
+			columns = append(columns, fmt.Sprintf("%-*s", layout.errorStatus, errorContent))
+		}
+
+		// Combine row with single spaces between columns
+		rowContent := strings.Join(columns, " ")
 		rows = append(rows, FormatTableRow(rowContent, selected))
 	}
 
+	if proxyRow := m.renderProxyRow(layout); proxyRow != "" {
+		rows = append(rows, proxyRow)
+	}
+	if indicator := m.renderScrollIndicator(start, end); indicator != "" {
+		rows = append(rows, indicator)
+	}
+
 	return strings.Join(rows, "\n")
 }
 
@@ -566,109 +1146,159 @@ func (m *Model) renderFooter() string {
 		sortInfo += " (desc)"
 	}
 
+	if m.filtering || m.filterText != "" {
+		sortInfo += fmt.Sprintf("  •  Filter: %s", m.filterText)
+		if m.filtering {
+			sortInfo += "▏"
+		}
+	}
+
+	// The full list of keys, sort options and status symbols is in the help overlay
 	help := []string{
 		"[↑↓] Navigate",
 		"[Enter] Details",
-		"[n/s/t/p/u] Sort by Name/Status/Type/Port/Uptime",
-		"[r] Reverse",
-		"[q] Quit",
+		"[/] Filter",
+	}
+	if !m.readOnly {
+		help = append(help, "[x/R] Restart")
+	}
+	help = append(help, "[?] Help", "[q] Quit")
+	if m.pickingColumns {
+		help = []string{m.columnPickerHelp()}
+	}
+	if m.pendingKill != nil {
+		help = []string{m.killPrompt()}
+	}
+	if m.pendingIssue != nil {
+		help = []string{m.issuePrompt()}
 	}
 
-	return footerStyle.Render(
-		lipgloss.JoinHorizontal(
-			lipgloss.Left,
-			sortInfo,
-			"  •  ",
-			strings.Join(help, "  "),
-		),
+	footer := lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		sortInfo,
+		"  •  ",
+		strings.Join(help, "  "),
 	)
+	if m.actionMessage != "" {
+		footer = lipgloss.JoinVertical(lipgloss.Left, m.actionMessage, footer)
+	}
+
+	return footerStyle.Render(footer)
 }
 
 // formatServiceURL formats the URL for a service based on type and UI handler status
 func (m *Model) formatServiceURL(service config.ServiceStatus, serviceName string, maxWidth int) string {
-	if service.Status != "Running" && service.Status != "Degraded" &&
-		service.Status != "Connecting" && service.Status != "Reconnecting" {
+	icon, url := m.serviceURL(service, serviceName)
+	if url == "" {
 		return "-"
 	}
 
-	serviceType := m.getServiceType(serviceName)
-
-	// Determine URL and icon based on service type and UI handler status
-	var url, icon string
-	switch serviceType {
-	case "web":
-		// Always show URL for web services (direct port-forward)
-		icon = "🌐" // Globe icon for web pages
-		url = fmt.Sprintf("%s http://localhost:%d", icon, service.LocalPort)
-	case "rest":
-		// Show Swagger UI URL if enabled, otherwise show direct port-forward
-		if m.swaggerUIEnabled && m.manager != nil {
-			swaggerURL := m.manager.GetSwaggerUIURL(serviceName)
-			if swaggerURL != "" {
-				icon = "📋" // Clipboard icon for Swagger UI documentation
-				url = fmt.Sprintf("%s %s", icon, swaggerURL)
-			} else {
-				icon = "🔗" // Link icon for direct REST API access
-				url = fmt.Sprintf("%s http://localhost:%d", icon, service.LocalPort)
-			}
-		} else {
-			return "-"
-		}
-	case "rpc":
-		// Show gRPC UI URL if enabled, otherwise don't show URL
-		if m.grpcUIEnabled && m.manager != nil {
-			grpcURL := m.manager.GetGRPCUIURL(serviceName)
-			if grpcURL != "" {
-				icon = "⚡" // Lightning bolt icon for gRPC UI (fast RPC calls)
-				url = fmt.Sprintf("%s %s", icon, grpcURL)
-			} else {
-				return "-"
-			}
-		} else {
-			return "-"
-		}
-	default:
-		// For other service types, don't show URL
-		return "-"
+	formatted := fmt.Sprintf("%s %s", icon, url)
+	if len(formatted) > maxWidth {
+		formatted = truncateString(formatted, maxWidth)
 	}
 
-	if len(url) > maxWidth {
-		url = truncateString(url, maxWidth)
+	return formatted
+}
+
+// Icons shown before the URLs of the UI handlers
+const (
+	swaggerIcon = "📋" // Clipboard for Swagger UI documentation
+	grpcUIIcon  = "⚡" // Lightning bolt for gRPC UI (fast RPC calls)
+)
+
+// serviceURL returns the URL shown for a service and its icon, or "" if it has none
+func (m *Model) serviceURL(service config.ServiceStatus, serviceName string) (icon, url string) {
+	if service.Status != "Running" && service.Status != "Degraded" &&
+		service.Status != "Connecting" && service.Status != "Reconnecting" {
+		return "", ""
 	}
 
-	return url
+	// Determine URL and icon based on the service type and its UI handler's status
+	serviceType := config.LookupServiceType(m.getServiceType(serviceName))
+	switch serviceType.UIHandler {
+	case config.UIHandlerSwagger:
+		// Show Swagger UI URL if enabled, otherwise the type's URL
+		if !m.swaggerUIEnabled || m.manager == nil {
+			return "", ""
+		}
+		if swaggerURL := m.manager.GetSwaggerUIURL(serviceName); swaggerURL != "" {
+			return swaggerIcon, swaggerURL
+		}
+	case config.UIHandlerGRPCUI:
+		// Show gRPC UI URL if enabled, otherwise the type's URL
+		if !m.grpcUIEnabled || m.manager == nil {
+			return "", ""
+		}
+		if grpcURL := m.manager.GetGRPCUIURL(serviceName); grpcURL != "" {
+			return grpcUIIcon, grpcURL
+		}
+	}
+	return serviceType.Icon, serviceType.ServiceURL(serviceName, "localhost", service.LocalPort)
 }
 
 // updateServiceNames updates and sorts the service names list
 func (m *Model) updateServiceNames() {
-	m.serviceNames = make([]string, 0, len(m.services))
+	m.serviceNames = make([]string, 0, len(m.services)+len(m.disabled))
 	for name := range m.services {
-		m.serviceNames = append(m.serviceNames, name)
+		if m.pinned[name] || m.matchesFilter(name) {
+			m.serviceNames = append(m.serviceNames, name)
+		}
+	}
+	for name := range m.disabled {
+		if _, running := m.services[name]; !running && (m.pinned[name] || m.matchesFilter(name)) {
+			m.serviceNames = append(m.serviceNames, name)
+		}
 	}
 
-	// Sort based on current field
+	// Sort based on current field, falling back to the name so equal rows keep a stable order.
+	// Services from several profiles are grouped by profile first.
+	now := time.Now()
 	sort.Slice(m.serviceNames, func(i, j int) bool {
-		a, b := m.services[m.serviceNames[i]], m.services[m.serviceNames[j]]
+		nameA, nameB := m.serviceNames[i], m.serviceNames[j]
+		if profileA, profileB := m.serviceConfigs[nameA].Profile, m.serviceConfigs[nameB].Profile; profileA != profileB {
+			return profileA < profileB
+		}
+		if m.sortReverse {
+			nameA, nameB = nameB, nameA
+		}
+		a, b := m.statusOf(nameA), m.statusOf(nameB)
 
-		var less bool
 		switch m.sortField {
 		case SortByStatus:
-			less = a.Status < b.Status
+			if severityA, severityB := statusSeverity(a.Status), statusSeverity(b.Status); severityA != severityB {
+				return severityA < severityB
+			}
 		case SortByType:
-			less = m.getServiceType(m.serviceNames[i]) < m.getServiceType(m.serviceNames[j])
+			if typeA, typeB := m.getServiceType(nameA), m.getServiceType(nameB); typeA != typeB {
+				return typeA < typeB
+			}
 		case SortByPort:
-			less = a.LocalPort < b.LocalPort
+			if a.LocalPort != b.LocalPort {
+				return a.LocalPort < b.LocalPort
+			}
 		case SortByUptime:
-			less = a.StartTime.Before(b.StartTime)
-		default: // SortByName
-			less = m.serviceNames[i] < m.serviceNames[j]
-		}
-
-		if m.sortReverse {
-			return !less
+			// Compare durations, not rendered strings, so "2h" sorts above "45m"
+			if uptimeA, uptimeB := serviceUptime(a, now), serviceUptime(b, now); uptimeA != uptimeB {
+				return uptimeA < uptimeB
+			}
+		case SortByRestarts:
+			// Most restarts first
+			if a.RestartCount != b.RestartCount {
+				return a.RestartCount > b.RestartCount
+			}
+		case SortByErrors:
+			if hasErrorA, hasErrorB := a.LastError != "", b.LastError != ""; hasErrorA != hasErrorB {
+				return hasErrorA
+			}
+		case SortByProblems:
+			if less, decided := compareProblems(a, b); decided {
+				return less
+			}
 		}
-		return less
+		return nameA < nameB
 	})
+	m.movePinnedFirst()
 
 	// Ensure selected index is still valid
 	if m.selectedIndex >= len(m.serviceNames) {
@@ -679,6 +1309,72 @@ func (m *Model) updateServiceNames() {
 	}
 }
 
+// statusOf returns the status of a service, which for a disabled service is "Disabled"
+func (m *Model) statusOf(name string) config.ServiceStatus {
+	if service, exists := m.services[name]; exists {
+		return service
+	}
+	if m.disabled[name] {
+		return config.ServiceStatus{Name: name, Status: "Disabled"}
+	}
+	return config.ServiceStatus{}
+}
+
+// formatExtraPorts lists the further local ports of a service with the target ports
+// they forward to, as far as the configuration is known
+func (m *Model) formatExtraPorts(serviceName string, service config.ServiceStatus) string {
+	mappings := m.serviceConfigs[serviceName].Ports
+	ports := make([]string, 0, len(service.ExtraPorts))
+	for i, local := range service.ExtraPorts {
+		if i < len(mappings) {
+			ports = append(ports, fmt.Sprintf("%d -> %d", local, mappings[i].Target))
+		} else {
+			ports = append(ports, fmt.Sprint(local))
+		}
+	}
+	return strings.Join(ports, ", ")
+}
+
+// profileColumnWidth returns the width of the Profile column, or 0 if no service comes from a profile
+func (m *Model) profileColumnWidth() int {
+	width := 0
+	for _, serviceConfig := range m.serviceConfigs {
+		if serviceConfig.Profile == "" {
+			continue
+		}
+		width = max(width, len("Profile"), len(serviceConfig.Profile))
+	}
+	return width
+}
+
+// needsAttention reports whether a service is unhealthy or has an error to show
+func needsAttention(status config.ServiceStatus) bool {
+	return statusSeverity(status.Status) < statusSeverity("Running") || status.LastError != ""
+}
+
+// compareProblems orders services that need attention first, then by status severity and
+// by restart count, most first. decided is false if a and b are equally troublesome.
+func compareProblems(a, b config.ServiceStatus) (less, decided bool) {
+	if attentionA, attentionB := needsAttention(a), needsAttention(b); attentionA != attentionB {
+		return attentionA, true
+	}
+	if severityA, severityB := statusSeverity(a.Status), statusSeverity(b.Status); severityA != severityB {
+		return severityA < severityB, true
+	}
+	if a.RestartCount != b.RestartCount {
+		return a.RestartCount > b.RestartCount, true
+	}
+	return false, false
+}
+
+// serviceUptime returns how long a service has been up, or 0 if it is not running
+func serviceUptime(service config.ServiceStatus, now time.Time) time.Duration {
+	if service.StartTime.IsZero() {
+		return 0
+	}
+	return now.Sub(service.StartTime)
+}
+
 // getServiceType returns the type of a service from the service configs
 func (m *Model) getServiceType(serviceName string) string {
 	if serviceConfig, exists := m.serviceConfigs[serviceName]; exists {
@@ -687,6 +1383,24 @@ func (m *Model) getServiceType(serviceName string) string {
 	return "unknown"
 }
 
+// isClusterDegraded reports whether the manager detected a restart storm
+func (m *Model) isClusterDegraded() bool {
+	for _, service := range m.services {
+		if service.GlobalStatus == "degraded" {
+			return true
+		}
+	}
+	return false
+}
+
+// isServiceExposed reports whether the service listens on a non-loopback address
+func (m *Model) isServiceExposed(serviceName string) bool {
+	if serviceConfig, exists := m.serviceConfigs[serviceName]; exists {
+		return serviceConfig.IsExposed()
+	}
+	return false
+}
+
 // visualWidth calculates the visual width of a string accounting for Unicode emojis
 func visualWidth(s string) int {
 	// Simple approximation: count emojis as 2 characters, regular chars as 1
@@ -724,14 +1438,24 @@ func truncateString(s string, width int) string {
 	return s[:width-3] + "..."
 }
 
-// listenForStatusUpdates listens for status updates
+// listenForStatusUpdates listens for status updates, skipping to the newest buffered one
+// so the view never lags behind the manager
 func (m *Model) listenForStatusUpdates() tea.Cmd {
 	return func() tea.Msg {
-		select {
-		case status := <-m.statusChan:
-			return StatusUpdateMsg(status)
-		default:
-			return nil
+		var latest map[string]config.ServiceStatus
+		for {
+			select {
+			case status, ok := <-m.statusChan:
+				if ok {
+					latest = status
+					continue
+				}
+			default:
+			}
+			if latest == nil {
+				return nil
+			}
+			return StatusUpdateMsg(latest)
 		}
 	}
 }
@@ -13,6 +13,13 @@ type MockUIManagerProvider struct {
 	globalAccessHealthy bool
 	grpcUIURL           string
 	swaggerUIURL        string
+	idle                bool
+	resumed             bool
+	restarted           []string
+	toggled             []string
+	logPath             string
+	profiles            []string
+	activeProfiles      []string
 }
 
 func (m *MockUIManagerProvider) GetGRPCUIURL(serviceName string) string {
@@ -27,6 +34,42 @@ func (m *MockUIManagerProvider) GetGlobalAccessStatus() bool {
 	return m.globalAccessHealthy
 }
 
+func (m *MockUIManagerProvider) IsIdle() bool {
+	return m.idle
+}
+
+func (m *MockUIManagerProvider) ResumeFromIdle() {
+	m.resumed = true
+	m.idle = false
+}
+
+func (m *MockUIManagerProvider) RestartService(name string) error {
+	m.restarted = append(m.restarted, name)
+	return nil
+}
+
+func (m *MockUIManagerProvider) ServiceLogPath(name string) string {
+	return m.logPath
+}
+
+func (m *MockUIManagerProvider) SetServiceDisabled(name string, disabled bool) error {
+	m.toggled = append(m.toggled, fmt.Sprintf("%s=%t", name, disabled))
+	return nil
+}
+
+func (m *MockUIManagerProvider) Profiles() ([]string, error) {
+	return m.profiles, nil
+}
+
+func (m *MockUIManagerProvider) ActiveProfiles() []string {
+	return m.activeProfiles
+}
+
+func (m *MockUIManagerProvider) SwitchProfiles(profiles []string) error {
+	m.activeProfiles = profiles
+	return nil
+}
+
 // TestModelGlobalStatusUpdate tests that the model correctly updates global status
 func TestModelGlobalStatusUpdate(t *testing.T) {
 	// Create mock manager
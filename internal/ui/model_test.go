@@ -1,9 +1,14 @@
 package ui
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
 )
 
 func TestTruncateString(t *testing.T) {
@@ -135,3 +140,483 @@ func TestFormatServiceURLWithSmallWidth(t *testing.T) {
 		})
 	}
 }
+
+// TestModelIdleScreen tests that the idle screen is shown and any key resumes
+func TestModelIdleScreen(t *testing.T) {
+	mockManager := &MockUIManagerProvider{globalAccessHealthy: true, idle: true}
+	statusChan := make(chan map[string]config.ServiceStatus, 1)
+
+	model := NewModel(statusChan, map[string]config.Service{}, mockManager)
+	model.width = 100
+	model.height = 30
+
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"test-service": {Name: "test-service", Status: "Idle"},
+	}))
+	model = updatedModel.(*Model)
+
+	if !model.idle {
+		t.Fatal("Expected model to pick up idle state from manager")
+	}
+	if !strings.Contains(model.View(), "Press any key to resume") {
+		t.Error("Expected idle view to be rendered")
+	}
+
+	updatedModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	model = updatedModel.(*Model)
+
+	if !mockManager.resumed {
+		t.Error("Expected key press to resume services")
+	}
+	if model.idle {
+		t.Error("Expected model to leave idle view after key press")
+	}
+}
+
+// TestModelClusterDegradedBanner tests the single banner shown during a restart storm
+func TestModelClusterDegradedBanner(t *testing.T) {
+	mockManager := &MockUIManagerProvider{globalAccessHealthy: true}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, mockManager)
+	model.width = 200
+	model.height = 30
+
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"svc-1": {Name: "svc-1", Status: "Failed", GlobalStatus: "degraded"},
+		"svc-2": {Name: "svc-2", Status: "Failed", GlobalStatus: "degraded"},
+	}))
+	model = updatedModel.(*Model)
+
+	header := model.renderHeader()
+	if strings.Count(header, "Cluster connectivity degraded") != 1 {
+		t.Errorf("Expected exactly one degraded banner in header, got: %s", header)
+	}
+}
+
+// TestModelReadOnly tests that an observer TUI cannot resume services
+func TestModelReadOnly(t *testing.T) {
+	mockManager := &MockUIManagerProvider{globalAccessHealthy: true, idle: true}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, mockManager)
+	model.readOnly = true
+	model.width = 200
+	model.height = 30
+
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"test-service": {Name: "test-service", Status: "Idle"},
+	}))
+	model = updatedModel.(*Model)
+
+	updatedModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	model = updatedModel.(*Model)
+
+	if mockManager.resumed || !model.idle {
+		t.Error("Expected read-only model not to resume services")
+	}
+	if !strings.Contains(model.View(), "Read-only") {
+		t.Error("Expected idle view to explain that the observer cannot resume")
+	}
+
+	model.idle = false
+	if !strings.Contains(model.renderHeader(), readOnlyBadgeText) {
+		t.Error("Expected read-only badge in header")
+	}
+}
+
+// TestModelRestartSelected tests restarting the selected service and that observers cannot
+func TestModelRestartSelected(t *testing.T) {
+	mockManager := &MockUIManagerProvider{globalAccessHealthy: true}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, mockManager)
+
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Failed"},
+	}))
+	model = updatedModel.(*Model)
+
+	updatedModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	model = updatedModel.(*Model)
+	if cmd == nil {
+		t.Fatal("Expected a restart command")
+	}
+	updatedModel, _ = model.Update(cmd())
+	model = updatedModel.(*Model)
+
+	if len(mockManager.restarted) != 1 || mockManager.restarted[0] != "api" {
+		t.Errorf("Expected api to be restarted, got %v", mockManager.restarted)
+	}
+	if model.actionMessage != "Restarted api" {
+		t.Errorf("Expected restart result in footer, got %q", model.actionMessage)
+	}
+
+	model.readOnly = true
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}}); cmd != nil {
+		t.Error("Expected read-only model not to restart services")
+	}
+}
+
+// TestModelSortByUptimeDescending tests that uptime sorts by duration, not by its rendered text
+func TestModelSortByUptimeDescending(t *testing.T) {
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	model.sortField = SortByUptime
+	model.sortReverse = true
+
+	now := time.Now()
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"seconds": {Name: "seconds", Status: "Running", StartTime: now.Add(-30 * time.Second)},
+		"minutes": {Name: "minutes", Status: "Running", StartTime: now.Add(-45 * time.Minute)},
+		"hours":   {Name: "hours", Status: "Running", StartTime: now.Add(-2 * time.Hour)},
+		"days":    {Name: "days", Status: "Running", StartTime: now.Add(-26 * time.Hour)},
+		"failed":  {Name: "failed", Status: "Failed"},
+	}))
+	model = updatedModel.(*Model)
+
+	expected := []string{"days", "hours", "minutes", "seconds", "failed"}
+	if strings.Join(model.serviceNames, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, model.serviceNames)
+	}
+}
+
+// TestModelDisplayFormats tests that uiOptions formats are used in the detail view
+func TestModelDisplayFormats(t *testing.T) {
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	model.uptimeFormat = utils.UptimeFull
+	model.timestampFormat = utils.TimestampISO
+	model.width = 200
+	model.height = 40
+
+	start := time.Now().Add(-26 * time.Hour)
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Running", StartTime: start},
+	}))
+	model = updatedModel.(*Model)
+
+	view := model.renderDetailView()
+	if !strings.Contains(view, "Uptime: 1 day 2 hours") {
+		t.Errorf("Expected full uptime in detail view, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Started: "+start.Format(time.RFC3339)) {
+		t.Errorf("Expected ISO start time in detail view, got:\n%s", view)
+	}
+}
+
+// TestModelFilter tests filtering services by name, description, owner, namespace, type and status
+func TestModelFilter(t *testing.T) {
+	serviceConfigs := map[string]config.Service{
+		"billing-api": {Description: "Invoices and payments", Owner: "team-money", Namespace: "finance", Type: "rest"},
+		"search":      {Description: "Full-text search", Owner: "team-discovery", Namespace: "discovery", Type: "rpc"},
+		"web":         {Namespace: "frontend", Type: "web"},
+	}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), serviceConfigs, &MockUIManagerProvider{})
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"billing-api": {Name: "billing-api", Status: "Running"},
+		"search":      {Name: "search", Status: "Running"},
+		"web":         {Name: "web", Status: "Failed"},
+	}))
+	model = updatedModel.(*Model)
+
+	typeFilter := func(text string) {
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	typeFilter("DISCOVERY")
+	if strings.Join(model.serviceNames, ",") != "search" {
+		t.Errorf("Expected owner match, got %v", model.serviceNames)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	typeFilter("payments")
+	if strings.Join(model.serviceNames, ",") != "billing-api" {
+		t.Errorf("Expected description match, got %v", model.serviceNames)
+	}
+
+	for filter, expected := range map[string]string{
+		"finance": "billing-api", // namespace
+		"rpc":     "search",      // type
+		"fail":    "web",         // status
+		"bapi":    "billing-api", // fuzzy name
+	} {
+		model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		typeFilter(filter)
+		if strings.Join(model.serviceNames, ",") != expected {
+			t.Errorf("Expected %q to match %s, got %v", filter, expected, model.serviceNames)
+		}
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if len(model.serviceNames) != 3 {
+		t.Errorf("Expected Esc to clear the filter, got %v", model.serviceNames)
+	}
+
+	model.width = 200
+	model.height = 40
+	model.sortField = SortByName
+	model.updateServiceNames()
+	model.selectedIndex = 0
+	view := model.renderDetailView()
+	if !strings.Contains(view, "Description: Invoices and payments") || !strings.Contains(view, "Owner: team-money") {
+		t.Errorf("Expected description and owner in detail view, got:\n%s", view)
+	}
+}
+
+// TestModelOpenLink tests opening a service link with a number key in the detail view
+func TestModelOpenLink(t *testing.T) {
+	var opened []string
+	defer func(original func(string) error) { openURL = original }(openURL)
+	openURL = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+
+	serviceConfigs := map[string]config.Service{
+		"api": {Links: []config.ServiceLink{
+			{Name: "Dashboard", URL: "https://grafana.example.com/d/api"},
+			{Name: "Runbook", URL: "https://wiki.example.com/api"},
+		}},
+	}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), serviceConfigs, &MockUIManagerProvider{})
+	model.width = 200
+	model.height = 40
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Running"},
+	}))
+	model = updatedModel.(*Model)
+	model.viewMode = ViewDetail
+
+	if view := model.renderDetailView(); !strings.Contains(view, "[2] Runbook: https://wiki.example.com/api") {
+		t.Errorf("Expected numbered links in detail view, got:\n%s", view)
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+	if cmd == nil {
+		t.Fatal("Expected a command to open the link")
+	}
+	model.Update(cmd())
+	if len(opened) != 1 || opened[0] != "https://wiki.example.com/api" {
+		t.Errorf("Expected runbook to be opened, got %v", opened)
+	}
+	if model.actionMessage != "Opened Runbook" {
+		t.Errorf("Expected result message, got %q", model.actionMessage)
+	}
+
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'3'}}); cmd != nil {
+		t.Error("Expected no command for a missing link")
+	}
+}
+
+// TestModelRestartMessageIsTransient tests the x binding and that the result leaves the footer
+func TestModelRestartMessageIsTransient(t *testing.T) {
+	mockManager := &MockUIManagerProvider{globalAccessHealthy: true}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, mockManager)
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Degraded"},
+	}))
+	model = updatedModel.(*Model)
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if cmd == nil {
+		t.Fatal("Expected x to restart the selected service")
+	}
+	if model.actionMessage != "Restarting api…" {
+		t.Errorf("Expected restarting message, got %q", model.actionMessage)
+	}
+
+	// The restarting message stays until the restart finishes
+	model.Update(TickMsg(time.Now().Add(time.Hour)))
+	if model.actionMessage == "" {
+		t.Error("Expected restarting message to stay while the restart runs")
+	}
+
+	model.Update(cmd())
+	if model.actionMessage != "Restarted api" {
+		t.Errorf("Expected restart result, got %q", model.actionMessage)
+	}
+	model.Update(TickMsg(time.Now()))
+	if model.actionMessage == "" {
+		t.Error("Expected result to be shown for a while")
+	}
+	model.Update(TickMsg(time.Now().Add(actionMessageDuration + time.Second)))
+	if model.actionMessage != "" {
+		t.Errorf("Expected result to be cleared, got %q", model.actionMessage)
+	}
+}
+
+func TestModelServiceConfigsReload(t *testing.T) {
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	model.width = 200
+	model.height = 40
+
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Running"},
+	}))
+	model = updatedModel.(*Model)
+
+	updatedModel, _ = model.Update(ServiceConfigsMsg(map[string]config.Service{
+		"api": {Owner: "team-payments"},
+	}))
+	model = updatedModel.(*Model)
+
+	model.filterText = "payments"
+	model.updateServiceNames()
+	if len(model.serviceNames) != 1 {
+		t.Errorf("Expected reloaded owner to be matched by the filter, got %v", model.serviceNames)
+	}
+}
+
+// TestModelToggleDisabled tests that disabled services are listed and can be enabled again
+func TestModelToggleDisabled(t *testing.T) {
+	mockManager := &MockUIManagerProvider{globalAccessHealthy: true}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, mockManager)
+	model.width = 200
+	model.height = 40
+
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Running"},
+	}))
+	model = updatedModel.(*Model)
+	updatedModel, _ = model.Update(DisabledServicesMsg{"worker"})
+	model = updatedModel.(*Model)
+
+	if len(model.serviceNames) != 2 || model.serviceNames[1] != "worker" {
+		t.Fatalf("Expected the disabled service to be listed, got %v", model.serviceNames)
+	}
+	if !strings.Contains(model.renderTable(), "Disabled") {
+		t.Error("Expected the disabled service's status in the table")
+	}
+	if !strings.Contains(model.renderHeader(), "1/1 running") {
+		t.Errorf("Expected disabled services not to be counted, got %s", model.renderHeader())
+	}
+
+	for _, index := range []int{0, 1} {
+		model.selectedIndex = index
+		updatedModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+		model = updatedModel.(*Model)
+		if cmd == nil {
+			t.Fatal("Expected a toggle command")
+		}
+		updatedModel, _ = model.Update(cmd())
+		model = updatedModel.(*Model)
+	}
+	if expected := []string{"api=true", "worker=false"}; !reflect.DeepEqual(mockManager.toggled, expected) {
+		t.Errorf("Expected %v, got %v", expected, mockManager.toggled)
+	}
+	if !strings.Contains(model.actionMessage, "Enabled worker") {
+		t.Errorf("Expected the result in the footer, got %q", model.actionMessage)
+	}
+
+	model.readOnly = true
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}}); cmd != nil {
+		t.Error("Expected observers not to toggle services")
+	}
+}
+
+// TestModelDetailKubectlOutput tests that recent kubectl output is shown in the detail view
+func TestModelDetailKubectlOutput(t *testing.T) {
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	model.width = 200
+	model.height = 40
+
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Failed", KubectlOutput: []string{
+			`12:00:01 Error from server (Forbidden): pods "api-1" is forbidden`,
+		}},
+	}))
+	model = updatedModel.(*Model)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	view := model.renderDetailView()
+	if !strings.Contains(view, "kubectl Output") || !strings.Contains(view, `pods "api-1" is forbidden`) {
+		t.Errorf("Expected kubectl output in detail view, got:\n%s", view)
+	}
+}
+
+// TestModelSortByStatusSeverity tests that status sorts the least healthy services first
+func TestModelSortByStatusSeverity(t *testing.T) {
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	model.sortField = SortByStatus
+
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api":    {Name: "api", Status: "Running"},
+		"cache":  {Name: "cache", Status: "Degraded"},
+		"db":     {Name: "db", Status: "Failed"},
+		"jobs":   {Name: "jobs", Status: "Connecting"},
+		"search": {Name: "search", Status: "Disabled"},
+	}))
+	model = updatedModel.(*Model)
+
+	expected := []string{"db", "cache", "jobs", "api", "search"}
+	if strings.Join(model.serviceNames, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, model.serviceNames)
+	}
+}
+
+// TestModelSortByProblems tests that the default sort puts the most troublesome services first
+func TestModelSortByProblems(t *testing.T) {
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	if model.sortField != SortByProblems {
+		t.Fatalf("Expected problems first as default sort, got %s", sortFieldNames[model.sortField])
+	}
+
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api":     {Name: "api", Status: "Running"},
+		"billing": {Name: "billing", Status: "Running", RestartCount: 4, LastError: "connection reset"},
+		"cache":   {Name: "cache", Status: "Degraded", RestartCount: 1},
+		"db":      {Name: "db", Status: "Failed"},
+		"jobs":    {Name: "jobs", Status: "Running", RestartCount: 2},
+	}))
+	model = updatedModel.(*Model)
+
+	expected := []string{"db", "cache", "billing", "jobs", "api"}
+	if strings.Join(model.serviceNames, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, model.serviceNames)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	expected = []string{"billing", "jobs", "cache", "api", "db"}
+	if strings.Join(model.serviceNames, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v sorted by restarts, got %v", expected, model.serviceNames)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	if model.serviceNames[0] != "billing" {
+		t.Errorf("Expected the service with an error first, got %v", model.serviceNames)
+	}
+}
+
+// TestModelDetailExtraPorts tests listing a service's further ports in the detail view
+func TestModelDetailExtraPorts(t *testing.T) {
+	configs := map[string]config.Service{
+		"postgres": {Ports: []config.PortMapping{{Local: 9187, Target: 9100}}},
+	}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), configs, &MockUIManagerProvider{})
+	model.width = 200
+	model.height = 40
+	model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"postgres": {Name: "postgres", Status: "Running", LocalPort: 5432, ExtraPorts: []int{9187}},
+	}))
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if view := model.View(); !strings.Contains(view, "Extra Ports: 9187 -> 9100") {
+		t.Errorf("Expected the further port in the details, got:\n%s", view)
+	}
+}
+
+// TestListenForStatusUpdatesSkipsToNewest tests that a TUI behind the manager shows the newest status
+func TestListenForStatusUpdatesSkipsToNewest(t *testing.T) {
+	statusChan := make(chan map[string]config.ServiceStatus, 3)
+	model := NewModel(statusChan, map[string]config.Service{}, nil)
+
+	if msg := model.listenForStatusUpdates()(); msg != nil {
+		t.Fatalf("Expected no message without updates, got %#v", msg)
+	}
+
+	for _, status := range []string{"Connecting", "Failed", "Running"} {
+		statusChan <- map[string]config.ServiceStatus{"api": {Name: "api", Status: status}}
+	}
+	msg, ok := model.listenForStatusUpdates()().(StatusUpdateMsg)
+	if !ok || msg["api"].Status != "Running" {
+		t.Fatalf("Expected the newest status update, got %#v", msg)
+	}
+	if len(statusChan) != 0 {
+		t.Errorf("Expected the older updates to be skipped, %d left", len(statusChan))
+	}
+}
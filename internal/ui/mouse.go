@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// doubleClickInterval is the longest time between two clicks on a row that opens its details
+const doubleClickInterval = 400 * time.Millisecond
+
+// tableLeft is the screen column where the table starts: the container's border and padding
+const tableLeft = 2
+
+// handleMouse selects a service on click, opens its details on double-click and its URL
+// when the URL is clicked. The wheel moves the selection. Only the table takes the mouse.
+func (m *Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.idle || m.viewMode != ViewTable || m.filtering || m.pickingColumns || m.pendingKill != nil || m.pendingIssue != nil {
+		return m, nil
+	}
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.moveSelection(-1)
+		m.followSelection()
+
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.moveSelection(1)
+		m.followSelection()
+
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		index := m.rowAt(msg.Y)
+		if index < 0 {
+			return m, nil
+		}
+		if m.urlColumnAt(msg.X) {
+			m.selectedIndex = index
+			return m, m.openServiceURL(m.serviceNames[index])
+		}
+
+		now := time.Now()
+		doubleClick := index == m.lastClickRow && now.Sub(m.lastClick) <= doubleClickInterval
+		m.selectedIndex, m.lastClickRow, m.lastClick = index, index, now
+		if doubleClick {
+			m.lastClick = time.Time{}
+			m.openDetail()
+		}
+	}
+
+	return m, nil
+}
+
+// rowAt returns the index in serviceNames of the service shown on screen line y, or -1
+func (m *Model) rowAt(y int) int {
+	// The container's top border, the header, a blank line and the table's header row
+	first := 1 + lipgloss.Height(m.renderHeader()) + 1 + 1
+	start, end := m.visibleRange()
+	index := start + y - first
+	if y < first || index >= end {
+		return -1
+	}
+	return index
+}
+
+// urlColumnAt reports whether screen column x is in the URL column of the table
+func (m *Model) urlColumnAt(x int) bool {
+	layout := m.tableLayout()
+	if layout.url == 0 {
+		return false
+	}
+	left := tableLeft
+	for _, width := range []int{layout.profile, layout.name, layout.status} {
+		if width > 0 {
+			left += width + 1
+		}
+	}
+	return x >= left && x < left+layout.url
+}
+
+// openServiceURL returns a command opening the URL shown for a service, if it has one
+func (m *Model) openServiceURL(name string) tea.Cmd {
+	_, url := m.serviceURL(m.statusOf(name), name)
+	if url == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := openURL(url); err != nil {
+			return ServiceActionMsg(fmt.Sprintf("Could not open %s: %v", url, err))
+		}
+		return ServiceActionMsg(fmt.Sprintf("Opened %s", url))
+	}
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestModelMouse tests selecting, opening and following URLs of services with the mouse
+func TestModelMouse(t *testing.T) {
+	var opened []string
+	defer func(original func(string) error) { openURL = original }(openURL)
+	openURL = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+
+	serviceConfigs := map[string]config.Service{"api": {Type: "web"}, "db": {Type: "web"}}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), serviceConfigs, &MockUIManagerProvider{})
+	model.mouse = true
+	model.sortField = SortByName
+	model.width = 160
+	model.height = 30
+	model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "Running", LocalPort: 8080},
+		"db":  {Name: "db", Status: "Running", LocalPort: 5432},
+	}))
+
+	var row int
+	for i, line := range strings.Split(model.View(), "\n") {
+		if strings.Contains(line, "db ") {
+			row = i
+		}
+	}
+	click := tea.MouseMsg{X: tableLeft + 1, Y: row, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+
+	model.Update(click)
+	if model.selectedIndex != 1 || model.viewMode != ViewTable {
+		t.Fatalf("Expected a click to select db, got %d in view %v", model.selectedIndex, model.viewMode)
+	}
+	model.Update(click)
+	if model.viewMode != ViewDetail {
+		t.Errorf("Expected a double-click to open the details, got view %v", model.viewMode)
+	}
+
+	model.viewMode = ViewTable
+	model.Update(tea.MouseMsg{Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress})
+	if model.selectedIndex != 0 {
+		t.Errorf("Expected the wheel to move the selection up, got %d", model.selectedIndex)
+	}
+
+	layout := model.tableLayout()
+	click.X = tableLeft + layout.name + 1 + layout.status + 1
+	_, cmd := model.Update(click)
+	if cmd == nil {
+		t.Fatal("Expected a command opening the URL")
+	}
+	cmd()
+	if len(opened) != 1 || opened[0] != "http://localhost:5432" || model.selectedIndex != 1 {
+		t.Errorf("Expected the URL of db to be opened, got %v", opened)
+	}
+}
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// OnboardingAction is what the user chose on the first-run screen
+type OnboardingAction int
+
+const (
+	OnboardingQuit     OnboardingAction = iota // Leave kportforward
+	OnboardingContinue                         // Start with the built-in defaults
+	OnboardingCreated                          // A starter config was written
+	OnboardingImport                           // Leave to run kportforward config import
+)
+
+// OnboardingResult is the outcome of the first-run screen
+type OnboardingResult struct {
+	Action     OnboardingAction
+	ConfigPath string // Written starter config, for OnboardingCreated
+}
+
+// OnboardingOptions describes the kubectl contexts to offer for the starter config
+type OnboardingOptions struct {
+	Contexts       []string
+	CurrentContext string
+	ContextError   error // Why the contexts could not be listed, if they could not
+}
+
+// createStarterConfig writes the starter config; replaced in tests
+var createStarterConfig = config.CreateStarterConfig
+
+// onboardingChoices are the actions offered on the first screen, in order
+var onboardingChoices = []struct {
+	label  string
+	action OnboardingAction
+}{
+	{"Create a starter config for one of your kubectl contexts", OnboardingCreated},
+	{"Import services from a docker-compose or .env file", OnboardingImport},
+	{"Continue with the built-in defaults", OnboardingContinue},
+}
+
+// onboardingModel is the first-run screen: a menu of actions, followed by a context
+// picker when creating a starter config
+type onboardingModel struct {
+	options  OnboardingOptions
+	result   OnboardingResult
+	selected int
+
+	pickingContext bool
+	contexts       []string // Offered contexts; "" follows the current context
+	err            string
+
+	width int
+}
+
+// newOnboardingModel creates the first-run screen
+func newOnboardingModel(options OnboardingOptions) *onboardingModel {
+	return &onboardingModel{
+		options:  options,
+		contexts: append([]string{""}, options.Contexts...),
+	}
+}
+
+// RunOnboarding shows the first-run screen until the user picks an action
+func RunOnboarding(options OnboardingOptions) (OnboardingResult, error) {
+	model := newOnboardingModel(options)
+	if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
+		return OnboardingResult{}, fmt.Errorf("first-run screen failed: %w", err)
+	}
+	return model.result, nil
+}
+
+// Init starts the first-run screen
+func (m *onboardingModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles keys on the first-run screen
+func (m *onboardingModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+
+	case tea.KeyMsg:
+		count := len(onboardingChoices)
+		if m.pickingContext {
+			count = len(m.contexts)
+		}
+
+		switch msg.String() {
+		case "q", "ctrl+c":
+			m.result = OnboardingResult{Action: OnboardingQuit}
+			return m, tea.Quit
+
+		case "esc":
+			m.pickingContext, m.selected, m.err = false, 0, ""
+
+		case "up", "k":
+			if m.selected > 0 {
+				m.selected--
+			}
+
+		case "down", "j":
+			if m.selected < count-1 {
+				m.selected++
+			}
+
+		case "enter":
+			return m, m.choose()
+		}
+	}
+	return m, nil
+}
+
+// choose applies the selected menu entry or context
+func (m *onboardingModel) choose() tea.Cmd {
+	if m.pickingContext {
+		path, err := createStarterConfig(m.contexts[m.selected])
+		if err != nil {
+			m.err = err.Error()
+			return nil
+		}
+		m.result = OnboardingResult{Action: OnboardingCreated, ConfigPath: path}
+		return tea.Quit
+	}
+
+	action := onboardingChoices[m.selected].action
+	if action == OnboardingCreated {
+		m.pickingContext, m.selected = true, 0
+		return nil
+	}
+	m.result = OnboardingResult{Action: action}
+	return tea.Quit
+}
+
+// View renders the first-run screen
+func (m *onboardingModel) View() string {
+	content := []string{
+		titleStyle.Render("Welcome to kportforward"),
+		"",
+		"No configuration was found and the shared defaults could not be downloaded,",
+		"so only the built-in services are known. How would you like to start?",
+		"",
+	}
+
+	if m.pickingContext {
+		content = append(content, tableHeaderStyle.Render("Which kubectl context should your services use?"))
+		for i, kubeContext := range m.contexts {
+			label := kubeContext
+			switch {
+			case kubeContext == "" && m.options.CurrentContext != "":
+				label = fmt.Sprintf("Follow the current context (now %s)", m.options.CurrentContext)
+			case kubeContext == "":
+				label = "Follow the current context"
+			case kubeContext == m.options.CurrentContext:
+				label += " (current)"
+			}
+			content = append(content, FormatTableRow("  "+label, i == m.selected))
+		}
+		if m.options.ContextError != nil {
+			content = append(content, "", helpStyle.Render("Contexts could not be listed: "+m.options.ContextError.Error()))
+		}
+	} else {
+		for i, choice := range onboardingChoices {
+			content = append(content, FormatTableRow("  "+choice.label, i == m.selected))
+		}
+	}
+
+	if m.err != "" {
+		content = append(content, "", errorMessageStyle.Render(m.err))
+	}
+	help := "[↑↓] Choose  [Enter] Select  [q] Quit"
+	if m.pickingContext {
+		help = "[↑↓] Choose  [Enter] Create config  [ESC] Back  [q] Quit"
+	}
+	content = append(content, "", helpStyle.Render(help))
+
+	style := containerStyle
+	if m.width > 4 {
+		style = style.Copy().Width(m.width - 4)
+	}
+	return style.Render(strings.Join(content, "\n"))
+}
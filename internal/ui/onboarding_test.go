@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestOnboardingCreatesStarterConfig tests picking a context on the first-run screen
+func TestOnboardingCreatesStarterConfig(t *testing.T) {
+	var createdFor []string
+	defer func(original func(string) (string, error)) { createStarterConfig = original }(createStarterConfig)
+	createStarterConfig = func(kubeContext string) (string, error) {
+		createdFor = append(createdFor, kubeContext)
+		return "/tmp/config.yaml", nil
+	}
+
+	model := newOnboardingModel(OnboardingOptions{Contexts: []string{"kind-local", "staging"}, CurrentContext: "staging"})
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || !model.pickingContext {
+		t.Fatal("Expected the first choice to ask for a context")
+	}
+	if view := model.View(); !strings.Contains(view, "Follow the current context (now staging)") || !strings.Contains(view, "staging (current)") {
+		t.Errorf("Expected the contexts with the current one marked, got:\n%s", view)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil {
+		t.Error("Expected the screen to close once the config is created")
+	}
+	if strings.Join(createdFor, ",") != "staging" {
+		t.Errorf("Expected a starter config for staging, got %v", createdFor)
+	}
+	if model.result.Action != OnboardingCreated || model.result.ConfigPath != "/tmp/config.yaml" {
+		t.Errorf("Expected created result, got %+v", model.result)
+	}
+
+	model = newOnboardingModel(OnboardingOptions{})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.result.Action != OnboardingContinue {
+		t.Errorf("Expected to continue with the defaults, got %+v", model.result)
+	}
+}
@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// minOutputPaneLines is the smallest output pane shown below the service details
+const minOutputPaneLines = 3
+
+// ServiceOutputProvider is implemented by providers that keep the output of kubectl and
+// the gRPC UI of each service, tailed in the detail view's output pane
+type ServiceOutputProvider interface {
+	// ServiceOutput returns the last lines kubectl wrote to stdout and stderr, oldest first
+	ServiceOutput(name string) []string
+	// GetGRPCUILogPath returns the log file of the service's gRPC UI, or "" if it has none
+	GetGRPCUILogPath(serviceName string) string
+}
+
+// outputPane is the state of the output pane in the detail view
+type outputPane struct {
+	showGRPCUI bool     // Tail the gRPC UI log instead of kubectl's output
+	scroll     int      // Lines scrolled up from the end; 0 follows new output
+	lines      []string // Output as of the last refresh
+	err        string
+}
+
+// openDetail shows the details of the selected service, following its kubectl output
+func (m *Model) openDetail() {
+	m.viewMode = ViewDetail
+	m.output = outputPane{}
+	m.refreshOutput()
+}
+
+// refreshOutput reads the output shown in the pane again
+func (m *Model) refreshOutput() {
+	if m.selectedIndex >= len(m.serviceNames) {
+		m.output.lines = nil
+		return
+	}
+	name := m.serviceNames[m.selectedIndex]
+
+	provider, ok := m.manager.(ServiceOutputProvider)
+	if !ok {
+		// Observers only get the last lines of stderr with the status
+		m.output.lines = m.statusOf(name).KubectlOutput
+		return
+	}
+	if !m.output.showGRPCUI {
+		m.output.lines = provider.ServiceOutput(name)
+		return
+	}
+
+	lines, err := readLogTail(provider.GetGRPCUILogPath(name), logTailBytes)
+	m.output.lines, m.output.err = lines, ""
+	if err != nil {
+		m.output.err = err.Error()
+	}
+}
+
+// grpcUILogPath returns the gRPC UI log of the selected service, or ""
+func (m *Model) grpcUILogPath() string {
+	provider, ok := m.manager.(ServiceOutputProvider)
+	if !ok || m.selectedIndex >= len(m.serviceNames) {
+		return ""
+	}
+	return provider.GetGRPCUILogPath(m.serviceNames[m.selectedIndex])
+}
+
+// outputPaneLines returns how many output lines fit between the selected service's
+// details and the help line
+func (m *Model) outputPaneLines() int {
+	if m.selectedIndex >= len(m.serviceNames) {
+		return minOutputPaneLines
+	}
+	name := m.serviceNames[m.selectedIndex]
+	// The container's border, and the blank line and title above the pane
+	lines := m.height - 2 - len(m.detailLines(name)) - 2 - len(m.detailFooter(name))
+	return max(lines, minOutputPaneLines)
+}
+
+// handleOutputKeyPress scrolls the output pane or switches its source
+func (m *Model) handleOutputKeyPress(msg tea.KeyMsg, paneLines int) {
+	switch msg.String() {
+	case "up", "k":
+		m.scrollOutput(1, paneLines)
+	case "down", "j":
+		m.scrollOutput(-1, paneLines)
+	case "pgup":
+		m.scrollOutput(paneLines, paneLines)
+	case "pgdown":
+		m.scrollOutput(-paneLines, paneLines)
+	case "end", "G":
+		m.output.scroll = 0
+	case "tab":
+		if m.grpcUILogPath() == "" {
+			m.showActionMessage("No gRPC UI log for this service; start kportforward with --grpcui")
+			return
+		}
+		m.output = outputPane{showGRPCUI: !m.output.showGRPCUI}
+		m.refreshOutput()
+	}
+}
+
+// scrollOutput scrolls the pane up by delta lines, or down for negative delta, without
+// scrolling past either end
+func (m *Model) scrollOutput(delta, paneLines int) {
+	m.output.scroll = min(max(m.output.scroll+delta, 0), max(len(m.output.lines)-paneLines, 0))
+}
+
+// renderOutputPane renders the title and the lines of the output pane, paneLines high
+func (m *Model) renderOutputPane(paneLines int) []string {
+	source := "kubectl Output"
+	if m.output.showGRPCUI {
+		source = "gRPC UI Log"
+	}
+	title := source + " (live)"
+	if m.output.scroll > 0 {
+		title = fmt.Sprintf("%s (%d lines up, [End] follow)", source, m.output.scroll)
+	}
+	content := []string{tableHeaderStyle.Render(title)}
+
+	end := max(len(m.output.lines)-m.output.scroll, 0)
+	start := max(end-paneLines, 0)
+	switch {
+	case m.output.err != "":
+		content = append(content, errorMessageStyle.Render(m.output.err))
+	case end == 0:
+		content = append(content, helpStyle.Render("(no output yet)"))
+	}
+	for _, line := range m.output.lines[start:end] {
+		content = append(content, "  "+truncateString(line, m.width-10))
+	}
+	return content
+}
+
+// outputPaneHelp lists the output pane's keys for the detail view's help line
+func (m *Model) outputPaneHelp() string {
+	help := []string{"[↑↓/PgUp/PgDn] Scroll output"}
+	if m.grpcUILogPath() != "" {
+		if m.output.showGRPCUI {
+			help = append(help, "[Tab] kubectl output")
+		} else {
+			help = append(help, "[Tab] gRPC UI log")
+		}
+	}
+	return strings.Join(help, "  ")
+}
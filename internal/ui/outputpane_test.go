@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// mockOutputProvider adds the output of kubectl and gRPC UI logs to the mock manager
+type mockOutputProvider struct {
+	*MockUIManagerProvider
+	output    []string
+	grpcUILog string
+}
+
+func (m *mockOutputProvider) ServiceOutput(name string) []string {
+	return m.output
+}
+
+func (m *mockOutputProvider) GetGRPCUILogPath(serviceName string) string {
+	return m.grpcUILog
+}
+
+// TestModelDetailOutputPane tests following, scrolling and switching the output pane
+func TestModelDetailOutputPane(t *testing.T) {
+	grpcUILog := filepath.Join(t.TempDir(), "grpcui.log")
+	if err := os.WriteFile(grpcUILog, []byte("grpcui listening on 9001\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manager := &mockOutputProvider{MockUIManagerProvider: &MockUIManagerProvider{}, grpcUILog: grpcUILog}
+	for i := 1; i <= 100; i++ {
+		manager.output = append(manager.output, fmt.Sprintf("Handling connection for 8080 #%03d", i))
+	}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, manager)
+	model.width = 200
+	model.height = 40
+	model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{"api": {Name: "api", Status: "Running"}}))
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	view := model.View()
+	if !strings.Contains(view, "#100") || strings.Contains(view, "#050") || !strings.Contains(view, "(live)") {
+		t.Errorf("Expected the pane to follow the end of the output, got:\n%s", view)
+	}
+
+	manager.output = append(manager.output, "Handling connection for 8080 #101")
+	model.Update(TickMsg(time.Now()))
+	if view := model.View(); !strings.Contains(view, "#101") {
+		t.Errorf("Expected new output after a refresh, got:\n%s", view)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	view = model.View()
+	if model.output.scroll == 0 || strings.Contains(view, "#101") || !strings.Contains(view, "lines up") {
+		t.Errorf("Expected PgUp to scroll back, got:\n%s", view)
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	if model.output.scroll != 0 {
+		t.Errorf("Expected End to follow the output again, got %d lines up", model.output.scroll)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if view := model.View(); !strings.Contains(view, "gRPC UI Log") || !strings.Contains(view, "grpcui listening on 9001") {
+		t.Errorf("Expected Tab to show the gRPC UI log, got:\n%s", view)
+	}
+}
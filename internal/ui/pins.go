@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// pinnedMarker prefixes the names of pinned services in the table
+const pinnedMarker = "* "
+
+// saveUIState persists pinned services and hidden columns; replaced in tests
+var saveUIState = config.SaveUIState
+
+// togglePin pins or unpins the selected service and returns a command saving the UI state
+func (m *Model) togglePin() tea.Cmd {
+	if m.selectedIndex >= len(m.serviceNames) {
+		return nil
+	}
+	name := m.serviceNames[m.selectedIndex]
+
+	if m.pinned == nil {
+		m.pinned = make(map[string]bool)
+	}
+	verb := "Pinned"
+	if m.pinned[name] {
+		delete(m.pinned, name)
+		verb = "Unpinned"
+	} else {
+		m.pinned[name] = true
+	}
+	m.updateServiceNames()
+	m.selectService(name)
+	return m.persistUIState(fmt.Sprintf("%s %s", verb, name))
+}
+
+// persistUIState returns a command saving the pins and the hidden and shown columns. It
+// reports done once saved, if it is not "".
+func (m *Model) persistUIState(done string) tea.Cmd {
+	state := config.UIState{Pinned: m.pinnedNames()}
+	for _, toggleable := range toggleableColumns {
+		switch {
+		case optionalColumns[toggleable.column] && m.shownColumns[toggleable.column]:
+			state.ShownColumns = append(state.ShownColumns, string(toggleable.column))
+		case m.hiddenColumns[toggleable.column]:
+			state.HiddenColumns = append(state.HiddenColumns, string(toggleable.column))
+		}
+	}
+	return func() tea.Msg {
+		if err := saveUIState(state); err != nil {
+			return ServiceActionMsg(fmt.Sprintf("Could not save the UI state: %v", err))
+		}
+		if done == "" {
+			return nil
+		}
+		return ServiceActionMsg(done)
+	}
+}
+
+// pinnedNames returns the pinned services in name order
+func (m *Model) pinnedNames() []string {
+	names := make([]string, 0, len(m.pinned))
+	for name := range m.pinned {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// movePinnedFirst moves pinned services to the top of the sorted service names,
+// keeping the sort order within pinned and unpinned services
+func (m *Model) movePinnedFirst() {
+	if len(m.pinned) == 0 {
+		return
+	}
+	sort.SliceStable(m.serviceNames, func(i, j int) bool {
+		return m.pinned[m.serviceNames[i]] && !m.pinned[m.serviceNames[j]]
+	})
+}
+
+// selectService moves the selection to name, if it is listed
+func (m *Model) selectService(name string) {
+	for i, listed := range m.serviceNames {
+		if listed == name {
+			m.selectedIndex = i
+			return
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestModelPinnedServices tests that pinned services stay on top regardless of sort and filter
+func TestModelPinnedServices(t *testing.T) {
+	var saved []config.UIState
+	defer func(original func(config.UIState) error) { saveUIState = original }(saveUIState)
+	saveUIState = func(state config.UIState) error {
+		saved = append(saved, state)
+		return nil
+	}
+
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	model.sortField = SortByName
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api":    {Name: "api", Status: "Running"},
+		"db":     {Name: "db", Status: "Running"},
+		"search": {Name: "search", Status: "Running"},
+	}))
+	model = updatedModel.(*Model)
+
+	model.selectedIndex = 2
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+	if cmd == nil {
+		t.Fatal("Expected a command saving the pins")
+	}
+	cmd()
+	if len(saved) != 1 || !reflect.DeepEqual(saved[0].Pinned, []string{"search"}) {
+		t.Errorf("Expected search to be saved as pinned, got %+v", saved)
+	}
+	if strings.Join(model.serviceNames, ",") != "search,api,db" || model.selectedIndex != 0 {
+		t.Errorf("Expected pinned search first and selected, got %v at %d", model.serviceNames, model.selectedIndex)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("db")})
+	if strings.Join(model.serviceNames, ",") != "search,db" {
+		t.Errorf("Expected pinned service to survive the filter, got %v", model.serviceNames)
+	}
+}
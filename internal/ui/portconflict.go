@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// PortConflictResolver is implemented by providers that can free the local port of a
+// strictPort service waiting in PortConflict
+type PortConflictResolver interface {
+	// PortOwner returns the process holding the service's local port
+	PortOwner(name string) (*utils.ProcessInfo, error)
+	// KillPortOwner kills process pid, if it still holds the port, and starts the service
+	KillPortOwner(name string, pid int) error
+}
+
+// portOwnerMsg carries the process holding a service's port, found after pressing K
+type portOwnerMsg struct {
+	service string
+	owner   *utils.ProcessInfo
+	err     error
+}
+
+// pendingKill is a process holding a service's port that the user is asked to kill
+type pendingKill struct {
+	service string
+	owner   *utils.ProcessInfo
+}
+
+// findPortOwnerOfSelected returns a command looking up the process holding the port of
+// the selected service, if it is in PortConflict and the provider can free it
+func (m *Model) findPortOwnerOfSelected() tea.Cmd {
+	if m.selectedIndex >= len(m.serviceNames) {
+		return nil
+	}
+	name := m.serviceNames[m.selectedIndex]
+	if m.statusOf(name).Status != "PortConflict" {
+		m.showActionMessage(fmt.Sprintf("%s is not waiting for its local port", name))
+		return nil
+	}
+
+	resolver, ok := m.manager.(PortConflictResolver)
+	if m.readOnly || !ok {
+		m.showActionMessage("Read-only: port conflicts cannot be resolved from this view")
+		return nil
+	}
+	return func() tea.Msg {
+		owner, err := resolver.PortOwner(name)
+		return portOwnerMsg{service: name, owner: owner, err: err}
+	}
+}
+
+// handlePortOwner asks to confirm killing the process holding a service's port
+func (m *Model) handlePortOwner(msg portOwnerMsg) {
+	if msg.err != nil {
+		m.showActionMessage(fmt.Sprintf("Cannot tell what holds the port of %s: %v", msg.service, msg.err))
+		return
+	}
+	m.pendingKill = &pendingKill{service: msg.service, owner: msg.owner}
+}
+
+// handleKillKeyPress kills the pending process on y and keeps it on any other key
+func (m *Model) handleKillKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	kill := m.pendingKill
+	m.pendingKill = nil
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "y", "Y":
+		resolver, ok := m.manager.(PortConflictResolver)
+		if !ok {
+			return m, nil
+		}
+		m.actionMessage = fmt.Sprintf("Killing %s…", kill.owner)
+		m.actionMessageExpiry = time.Time{}
+		return m, func() tea.Msg {
+			if err := resolver.KillPortOwner(kill.service, kill.owner.PID); err != nil {
+				return ServiceActionMsg(fmt.Sprintf("Could not free the port of %s: %v", kill.service, err))
+			}
+			return ServiceActionMsg(fmt.Sprintf("Killed %s; starting %s", kill.owner, kill.service))
+		}
+	}
+
+	m.showActionMessage(fmt.Sprintf("Left %s running", kill.owner))
+	return m, nil
+}
+
+// killPrompt asks to confirm the pending kill in the footer
+func (m *Model) killPrompt() string {
+	return fmt.Sprintf("Kill %s, which holds the port of %s?  [y] Kill  [any other key] Cancel",
+		m.pendingKill.owner, m.pendingKill.service)
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// mockPortConflictResolver adds freeing ports to the mock manager
+type mockPortConflictResolver struct {
+	*MockUIManagerProvider
+	killed []int
+}
+
+func (m *mockPortConflictResolver) PortOwner(name string) (*utils.ProcessInfo, error) {
+	return &utils.ProcessInfo{PID: 4242, Command: "node"}, nil
+}
+
+func (m *mockPortConflictResolver) KillPortOwner(name string, pid int) error {
+	m.killed = append(m.killed, pid)
+	return nil
+}
+
+// TestModelKillPortOwner tests killing the process holding a strictPort service's port with K
+func TestModelKillPortOwner(t *testing.T) {
+	manager := &mockPortConflictResolver{MockUIManagerProvider: &MockUIManagerProvider{}}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, manager)
+	model.width = 200
+	model.height = 40
+	model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"api": {Name: "api", Status: "PortConflict", LocalPort: 8080, LastError: "port 8080 is in use by node (pid 4242) (strictPort)"},
+	}))
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'K'}})
+	if cmd == nil {
+		t.Fatal("Expected a command looking up the port's owner")
+	}
+	model.Update(cmd())
+	if view := model.View(); !strings.Contains(view, "Kill node (pid 4242), which holds the port of api?") {
+		t.Fatalf("Expected a confirmation prompt, got:\n%s", view)
+	}
+
+	// Any other key cancels
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if model.pendingKill != nil || len(manager.killed) != 0 {
+		t.Fatal("Expected n to cancel the kill")
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'K'}})
+	model.Update(portOwnerMsg{service: "api", owner: &utils.ProcessInfo{PID: 4242, Command: "node"}})
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if cmd == nil {
+		t.Fatal("Expected a command killing the process")
+	}
+	cmd()
+	if !reflect.DeepEqual(manager.killed, []int{4242}) {
+		t.Errorf("Expected pid 4242 to be killed, got %v", manager.killed)
+	}
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultProfileLabel stands for running the regular configuration without a profile
+const defaultProfileLabel = "(default config)"
+
+// ProfileSwitcher is implemented by providers that can replace the running services
+// with those of another profile
+type ProfileSwitcher interface {
+	// Profiles returns the names of the available profiles
+	Profiles() ([]string, error)
+	// ActiveProfiles returns the running profiles, or none for the regular configuration
+	ActiveProfiles() []string
+	// SwitchProfiles stops the running services and starts those of profiles
+	SwitchProfiles(profiles []string) error
+}
+
+// profilePicker is the state of the profile picker overlay
+type profilePicker struct {
+	choices  []string // Profile names; "" is the regular configuration
+	selected int
+	active   string
+	err      string
+}
+
+// openProfilePicker lists the profiles to switch to, if the provider supports it
+func (m *Model) openProfilePicker() {
+	switcher, ok := m.manager.(ProfileSwitcher)
+	if !ok || m.readOnly {
+		m.showActionMessage("Switching profiles is not available here")
+		return
+	}
+
+	picker := profilePicker{choices: []string{""}}
+	profiles, err := switcher.Profiles()
+	if err != nil {
+		picker.err = err.Error()
+	}
+	picker.choices = append(picker.choices, profiles...)
+
+	// Several profiles can be combined with --profile; the picker switches to one
+	if active := switcher.ActiveProfiles(); len(active) > 0 {
+		picker.active = strings.Join(active, ",")
+	}
+	for i, choice := range picker.choices {
+		if choice == picker.active {
+			picker.selected = i
+		}
+	}
+
+	m.profiles = picker
+	m.viewMode = ViewProfiles
+}
+
+// handleProfileKeyPress handles keys in the profile picker
+func (m *Model) handleProfileKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "backspace":
+		m.viewMode = ViewTable
+
+	case "up", "k":
+		if m.profiles.selected > 0 {
+			m.profiles.selected--
+		}
+
+	case "down", "j":
+		if m.profiles.selected < len(m.profiles.choices)-1 {
+			m.profiles.selected++
+		}
+
+	case "enter":
+		m.viewMode = ViewTable
+		return m, m.switchProfile(m.profiles.choices[m.profiles.selected])
+	}
+
+	return m, nil
+}
+
+// switchProfile returns a command switching to profile, or to the regular configuration for ""
+func (m *Model) switchProfile(profile string) tea.Cmd {
+	switcher, ok := m.manager.(ProfileSwitcher)
+	if !ok || profile == m.profiles.active {
+		return nil
+	}
+
+	var profiles []string
+	label := defaultProfileLabel
+	if profile != "" {
+		profiles, label = []string{profile}, profile
+	}
+	m.actionMessage = fmt.Sprintf("Switching to %s…", label)
+	m.actionMessageExpiry = time.Time{}
+	m.selectedIndex = 0
+	return func() tea.Msg {
+		if err := switcher.SwitchProfiles(profiles); err != nil {
+			return ServiceActionMsg(fmt.Sprintf("Switching to %s failed: %v", label, err))
+		}
+		return ServiceActionMsg(fmt.Sprintf("Switched to %s", label))
+	}
+}
+
+// renderProfileView renders the profile picker overlay
+func (m *Model) renderProfileView() string {
+	content := []string{
+		titleStyle.Render("Switch Profile"),
+		helpStyle.Render("Stops the running services and starts those of the chosen profile"),
+		"",
+	}
+
+	for i, choice := range m.profiles.choices {
+		label := choice
+		if choice == "" {
+			label = defaultProfileLabel
+		}
+		if choice == m.profiles.active {
+			label += " (running)"
+		}
+		content = append(content, FormatTableRow("  "+label, i == m.profiles.selected))
+	}
+	if len(m.profiles.choices) == 1 && m.profiles.err == "" {
+		content = append(content, "", helpStyle.Render("No profiles found; add them as <name>.yaml to the profiles directory"))
+	}
+	if m.profiles.err != "" {
+		content = append(content, "", errorMessageStyle.Render(m.profiles.err))
+	}
+
+	content = append(content, "", helpStyle.Render("[↑↓] Choose  [Enter] Switch  [ESC] Cancel  [q] Quit"))
+
+	return containerStyle.
+		Width(m.width - 4).
+		Height(m.height - 2).
+		Render(strings.Join(content, "\n"))
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestModelProfiles tests grouping by profile and the Profile column
+func TestModelProfiles(t *testing.T) {
+	serviceConfigs := map[string]config.Service{
+		"staging/api": {Profile: "staging"},
+		"staging/web": {Profile: "staging"},
+		"prod/api":    {Profile: "prod"},
+	}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), serviceConfigs, &MockUIManagerProvider{})
+	model.width = 200
+	model.height = 40
+	model.sortReverse = true
+
+	updatedModel, _ := model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{
+		"staging/api": {Name: "staging/api", Status: "Running"},
+		"staging/web": {Name: "staging/web", Status: "Running"},
+		"prod/api":    {Name: "prod/api", Status: "Running"},
+	}))
+	model = updatedModel.(*Model)
+
+	expected := "prod/api,staging/web,staging/api"
+	if got := strings.Join(model.serviceNames, ","); got != expected {
+		t.Errorf("Expected profiles grouped with reverse order inside each, got %s", got)
+	}
+
+	table := model.renderTable()
+	if !strings.Contains(table, "Profile") || strings.Contains(table, "staging/web") {
+		t.Errorf("Expected Profile column and names without the profile prefix, got:\n%s", table)
+	}
+}
+
+// TestModelSwitchProfile tests picking another profile in the profile picker
+func TestModelSwitchProfile(t *testing.T) {
+	manager := &MockUIManagerProvider{profiles: []string{"prod", "staging"}, activeProfiles: []string{"staging"}}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, manager)
+	model.width = 120
+	model.height = 40
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	if model.viewMode != ViewProfiles {
+		t.Fatalf("Expected profile picker, got %v", model.viewMode)
+	}
+	if view := model.View(); !strings.Contains(view, "staging (running)") || !strings.Contains(view, defaultProfileLabel) {
+		t.Errorf("Expected profiles with the running one marked, got:\n%s", view)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected a command switching profiles")
+	}
+	if msg := cmd(); !strings.Contains(string(msg.(ServiceActionMsg)), "Switched to prod") {
+		t.Errorf("Expected switch confirmation, got %v", msg)
+	}
+	if strings.Join(manager.activeProfiles, ",") != "prod" || model.viewMode != ViewTable {
+		t.Errorf("Expected prod to run and the table to show, got %v in %v", manager.activeProfiles, model.viewMode)
+	}
+
+	model.readOnly = true
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	if model.viewMode == ViewProfiles {
+		t.Error("Expected observers not to switch profiles")
+	}
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/victorkazakov/kportforward/internal/proxy"
+)
+
+// ProxyStatusProvider is implemented by providers running the cluster proxy (--proxy),
+// shown as a row below the services
+type ProxyStatusProvider interface {
+	// ProxyStatus returns the proxy's status, or false if it is not running
+	ProxyStatus() (proxy.Status, bool)
+}
+
+// proxyStatus returns the status of the cluster proxy, or false if there is none
+func (m *Model) proxyStatus() (proxy.Status, bool) {
+	provider, ok := m.manager.(ProxyStatusProvider)
+	if !ok {
+		return proxy.Status{}, false
+	}
+	return provider.ProxyStatus()
+}
+
+// renderProxyRow renders the cluster proxy in the table's columns, or returns "" if it
+// is not running. It cannot be selected.
+func (m *Model) renderProxyRow(layout tableLayout) string {
+	status, ok := m.proxyStatus()
+	if !ok {
+		return ""
+	}
+
+	state := "Running"
+	if len(status.Forwards) == 0 && status.LastError != "" {
+		state = "Degraded"
+	}
+	_, port, _ := net.SplitHostPort(status.Address)
+	details := fmt.Sprintf("%d forwards, %d connections", len(status.Forwards), status.Connections)
+	if len(status.Forwards) > 0 {
+		details += ": " + strings.Join(status.Forwards, ", ")
+	} else if status.LastError != "" {
+		details = status.LastError
+	}
+
+	columns := []string{fmt.Sprintf("%-*s", layout.name, truncateString("⇄ cluster proxy", layout.name)),
+		fmt.Sprintf("%s %-*s", GetStatusIndicator(state), layout.status-2, state)}
+	if layout.profile > 0 {
+		columns = append([]string{strings.Repeat(" ", layout.profile)}, columns...)
+	}
+	for _, column := range []struct {
+		width   int
+		content string
+	}{
+		{layout.url, "socks5h://" + status.Address},
+		{layout.serviceType, "proxy"},
+		{layout.port, port},
+		{layout.latency, "-"},
+		{layout.uptime, "-"},
+		{layout.lastOK, "-"},
+		{layout.errorStatus, details},
+	} {
+		if column.width > 0 {
+			columns = append(columns, fmt.Sprintf("%-*s", column.width, truncateString(column.content, column.width)))
+		}
+	}
+	return helpStyle.Render(strings.Join(columns, " "))
+}
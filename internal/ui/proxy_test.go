@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/proxy"
+)
+
+// mockProxyProvider adds the cluster proxy to the mock manager
+type mockProxyProvider struct {
+	*MockUIManagerProvider
+	status proxy.Status
+}
+
+func (m *mockProxyProvider) ProxyStatus() (proxy.Status, bool) {
+	return m.status, true
+}
+
+// TestModelProxyRow tests the cluster proxy's row below the services
+func TestModelProxyRow(t *testing.T) {
+	manager := &mockProxyProvider{MockUIManagerProvider: &MockUIManagerProvider{}, status: proxy.Status{
+		Address:  "localhost:1080",
+		Forwards: []string{"api.team:8080"},
+	}}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, manager)
+	model.width = 200
+	model.height = 40
+	model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{"web": {Name: "web", Status: "Running"}}))
+
+	view := model.View()
+	for _, want := range []string{"cluster proxy", "socks5h://localhost:1080", "1 forwards, 0 connections: api.team:8080"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the proxy row, got:\n%s", want, view)
+		}
+	}
+
+	// Without a proxy there is no row
+	model = NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	model.width = 200
+	model.height = 40
+	model.Update(StatusUpdateMsg(map[string]config.ServiceStatus{"web": {Name: "web", Status: "Running"}}))
+	if view := model.View(); strings.Contains(view, "cluster proxy") {
+		t.Errorf("Expected no proxy row without a proxy, got:\n%s", view)
+	}
+}
@@ -0,0 +1,23 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+)
+
+// Bounds of the refresh rate set with + and -
+const (
+	minRefreshRate = 50 * time.Millisecond
+	maxRefreshRate = 5 * time.Second
+)
+
+// defaultRefreshRate is used unless uiOptions.refreshRate or --refresh-rate set another
+const defaultRefreshRate = 250 * time.Millisecond
+
+// changeRefreshRate multiplies the refresh rate by factor, within minRefreshRate and
+// maxRefreshRate, and shows the new rate. Slower rates redraw less over slow SSH links.
+func (m *Model) changeRefreshRate(factor float64) {
+	rate := time.Duration(float64(m.refreshRate) * factor).Round(10 * time.Millisecond)
+	m.refreshRate = min(max(rate, minRefreshRate), maxRefreshRate)
+	m.showActionMessage(fmt.Sprintf("Refreshing every %s", m.refreshRate))
+}
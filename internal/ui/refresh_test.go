@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestModelRefreshRate tests speeding up and slowing down the refresh with + and -
+func TestModelRefreshRate(t *testing.T) {
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	if model.refreshRate != 2*defaultRefreshRate || !strings.Contains(model.actionMessage, "500ms") {
+		t.Errorf("Expected - to halve the refresh rate, got %s (%q)", model.refreshRate, model.actionMessage)
+	}
+
+	for i := 0; i < 10; i++ {
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'+'}})
+	}
+	if model.refreshRate != minRefreshRate {
+		t.Errorf("Expected + to stop at %s, got %s", minRefreshRate, model.refreshRate)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	if model.refreshRate != minRefreshRate || model.filterText != "-" {
+		t.Errorf("Expected - to be typed into the filter, got %s and %q", model.refreshRate, model.filterText)
+	}
+}
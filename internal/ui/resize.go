@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resizeDebounce is how long the terminal size has to stay the same before the view
+// follows it. Dragging a window edge sends a burst of sizes; laying out the table for
+// each of them made large tables flicker.
+const resizeDebounce = 100 * time.Millisecond
+
+// resizeSettledMsg applies the pending terminal size if no newer one arrived since it
+// was scheduled
+type resizeSettledMsg struct {
+	seq int
+}
+
+// handleWindowSize keeps a new terminal size pending and returns a command applying it
+// once it settles. The first size is applied at once so the table can be drawn.
+func (m *Model) handleWindowSize(msg tea.WindowSizeMsg) tea.Cmd {
+	if m.width == 0 {
+		m.width, m.height = msg.Width, msg.Height
+		return nil
+	}
+
+	m.pendingSize = &msg
+	m.resizeSeq++
+	seq := m.resizeSeq
+	return tea.Tick(resizeDebounce, func(time.Time) tea.Msg {
+		return resizeSettledMsg{seq: seq}
+	})
+}
+
+// applySettledSize applies the pending size, unless a newer one superseded msg
+func (m *Model) applySettledSize(msg resizeSettledMsg) {
+	if msg.seq != m.resizeSeq || m.pendingSize == nil {
+		return
+	}
+	m.width, m.height = m.pendingSize.Width, m.pendingSize.Height
+	m.pendingSize = nil
+	m.followSelection()
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestModelResizeDebounce tests that only the last of a burst of sizes is applied, once settled
+func TestModelResizeDebounce(t *testing.T) {
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	if _, cmd := model.Update(tea.WindowSizeMsg{Width: 200, Height: 40}); cmd != nil || model.width != 200 {
+		t.Fatalf("Expected the first size to apply at once, got width %d", model.width)
+	}
+	wide := model.tableLayout()
+
+	var settled []tea.Msg
+	for _, width := range []int{150, 120, 100} {
+		_, cmd := model.Update(tea.WindowSizeMsg{Width: width, Height: 30})
+		if cmd == nil {
+			t.Fatal("Expected a command waiting for the size to settle")
+		}
+		settled = append(settled, cmd())
+	}
+	if model.width != 200 || model.tableLayout() != wide {
+		t.Fatalf("Expected the size and layout to wait for the burst to settle, got width %d", model.width)
+	}
+
+	// Superseded sizes are ignored; the last one applies
+	model.Update(settled[0])
+	if model.width != 200 {
+		t.Errorf("Expected a superseded size to be ignored, got width %d", model.width)
+	}
+	model.Update(settled[2])
+	if model.width != 100 || model.height != 30 {
+		t.Errorf("Expected the last size once settled, got %dx%d", model.width, model.height)
+	}
+	if layout := model.tableLayout(); layout == wide || layout.serviceType != 0 {
+		t.Errorf("Expected the layout to follow the new width, got %+v", layout)
+	}
+}
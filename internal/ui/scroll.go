@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// visibleTableRows returns how many service rows fit between the table header and the footer
+func (m *Model) visibleTableRows() int {
+	// The container's border, the blank lines around the table and the table's header row
+	rows := m.height - 2 - 3 - lipgloss.Height(m.renderHeader()) - lipgloss.Height(m.renderFooter())
+	if _, ok := m.proxyStatus(); ok {
+		rows--
+	}
+	if len(m.serviceNames) > rows {
+		// Room for the scroll indicator
+		rows--
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// visibleRange returns the services shown in the table, serviceNames[start:end]. It
+// starts at scrollOffset, moved just enough to keep the selected service on screen.
+func (m *Model) visibleRange() (start, end int) {
+	rows := m.visibleTableRows()
+	if m.height == 0 || len(m.serviceNames) <= rows {
+		return 0, len(m.serviceNames)
+	}
+
+	start = m.scrollOffset
+	if m.selectedIndex < start {
+		start = m.selectedIndex
+	}
+	if m.selectedIndex >= start+rows {
+		start = m.selectedIndex - rows + 1
+	}
+	if start > len(m.serviceNames)-rows {
+		start = len(m.serviceNames) - rows
+	}
+	if start < 0 {
+		start = 0
+	}
+	return start, start + rows
+}
+
+// followSelection scrolls the table so the selected service is visible
+func (m *Model) followSelection() {
+	m.scrollOffset, _ = m.visibleRange()
+}
+
+// moveSelection moves the selection by delta services, staying within the table
+func (m *Model) moveSelection(delta int) {
+	m.selectedIndex += delta
+	if m.selectedIndex >= len(m.serviceNames) {
+		m.selectedIndex = len(m.serviceNames) - 1
+	}
+	if m.selectedIndex < 0 {
+		m.selectedIndex = 0
+	}
+}
+
+// renderScrollIndicator describes the services above and below the visible rows, or
+// returns "" if all are shown
+func (m *Model) renderScrollIndicator(start, end int) string {
+	if start == 0 && end == len(m.serviceNames) {
+		return ""
+	}
+	return helpStyle.Render(fmt.Sprintf("↑ %d more  ↓ %d more  (%d-%d of %d, [PgUp/PgDn] Page)",
+		start, len(m.serviceNames)-end, start+1, end, len(m.serviceNames)))
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TestModelTableScrolling tests that only the rows that fit are rendered and the selection stays visible
+func TestModelTableScrolling(t *testing.T) {
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, &MockUIManagerProvider{})
+	model.sortField = SortByName
+	model.width = 160
+	model.height = 20
+
+	statuses := make(map[string]config.ServiceStatus)
+	for i := 0; i < 40; i++ {
+		name := fmt.Sprintf("service-%02d", i)
+		statuses[name] = config.ServiceStatus{Name: name, Status: "Running"}
+	}
+	model.Update(StatusUpdateMsg(statuses))
+
+	if lines := strings.Count(model.View(), "\n") + 1; lines > model.height {
+		t.Errorf("Expected the view to fit %d lines, got %d", model.height, lines)
+	}
+	view := model.View()
+	if !strings.Contains(view, "service-00") || strings.Contains(view, "service-39") || !strings.Contains(view, "of 40") {
+		t.Errorf("Expected the first page with a scroll indicator, got:\n%s", view)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	if model.selectedIndex != 39 {
+		t.Fatalf("Expected the last service selected, got %d", model.selectedIndex)
+	}
+	view = model.View()
+	if !strings.Contains(view, "service-39") || strings.Contains(view, "service-00") {
+		t.Errorf("Expected the last page, got:\n%s", view)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if start, _ := model.visibleRange(); start != model.scrollOffset || model.selectedIndex != 38 {
+		t.Errorf("Expected the table not to scroll while the selection is visible, got offset %d", start)
+	}
+}
@@ -2,132 +2,221 @@ package ui
 
 import (
 	"github.com/charmbracelet/lipgloss"
+	"github.com/victorkazakov/kportforward/internal/config"
 )
 
-// Color palette for dark terminal theme
+// palette holds the colors of a theme. Colors carry 256 and 16 color fallbacks, used
+// instead of approximating the true colors on terminals that lack them.
+type palette struct {
+	primary, secondary, accent     lipgloss.TerminalColor
+	success, warning, error, muted lipgloss.TerminalColor
+	text, selectedBg, border       lipgloss.TerminalColor
+	alertText                      lipgloss.TerminalColor // On the error color, while the header flashes
+
+	// reverse highlights the selected row and the flashing header by swapping the
+	// foreground and background, for themes without background colors
+	reverse bool
+}
+
+// Palettes for uiOptions.theme
+var (
+	// darkPalette is for terminals with a dark background, the default
+	darkPalette = palette{
+		primary:    lipgloss.CompleteColor{TrueColor: "#00D4AA", ANSI256: "43", ANSI: "14"}, // Bright teal
+		secondary:  lipgloss.CompleteColor{TrueColor: "#FF6B6B", ANSI256: "203", ANSI: "9"}, // Coral red
+		accent:     lipgloss.CompleteColor{TrueColor: "#4ECDC4", ANSI256: "80", ANSI: "6"},  // Light teal
+		success:    lipgloss.CompleteColor{TrueColor: "#55FF55", ANSI256: "83", ANSI: "10"}, // Bright green
+		warning:    lipgloss.CompleteColor{TrueColor: "#FFAA00", ANSI256: "214", ANSI: "11"},
+		error:      lipgloss.CompleteColor{TrueColor: "#FF5555", ANSI256: "203", ANSI: "9"},
+		muted:      lipgloss.CompleteColor{TrueColor: "#888888", ANSI256: "245", ANSI: "8"},
+		text:       lipgloss.CompleteColor{TrueColor: "#FFFFFF", ANSI256: "15", ANSI: "15"},
+		selectedBg: lipgloss.CompleteColor{TrueColor: "#2A2A2A", ANSI256: "235", ANSI: "8"},
+		border:     lipgloss.CompleteColor{TrueColor: "#444444", ANSI256: "238", ANSI: "8"},
+		alertText:  lipgloss.Color("#000000"),
+	}
+
+	// lightPalette uses darker shades that stay readable on a light background
+	lightPalette = palette{
+		primary:    lipgloss.CompleteColor{TrueColor: "#00806A", ANSI256: "29", ANSI: "6"},
+		secondary:  lipgloss.CompleteColor{TrueColor: "#D1495B", ANSI256: "167", ANSI: "1"},
+		accent:     lipgloss.CompleteColor{TrueColor: "#007A8A", ANSI256: "30", ANSI: "6"},
+		success:    lipgloss.CompleteColor{TrueColor: "#1A7F37", ANSI256: "28", ANSI: "2"},
+		warning:    lipgloss.CompleteColor{TrueColor: "#B35900", ANSI256: "130", ANSI: "3"},
+		error:      lipgloss.CompleteColor{TrueColor: "#CF222E", ANSI256: "160", ANSI: "1"},
+		muted:      lipgloss.CompleteColor{TrueColor: "#6E7781", ANSI256: "243", ANSI: "8"},
+		text:       lipgloss.CompleteColor{TrueColor: "#1F2328", ANSI256: "235", ANSI: "0"},
+		selectedBg: lipgloss.CompleteColor{TrueColor: "#DDE3EA", ANSI256: "254", ANSI: "7"},
+		border:     lipgloss.CompleteColor{TrueColor: "#B0B7C0", ANSI256: "249", ANSI: "7"},
+		alertText:  lipgloss.Color("#FFFFFF"),
+	}
+
+	// noColorPalette keeps bold, italic and underline but leaves colors to the terminal
+	noColorPalette = palette{
+		primary: lipgloss.NoColor{}, secondary: lipgloss.NoColor{}, accent: lipgloss.NoColor{},
+		success: lipgloss.NoColor{}, warning: lipgloss.NoColor{}, error: lipgloss.NoColor{},
+		muted: lipgloss.NoColor{}, text: lipgloss.NoColor{}, selectedBg: lipgloss.NoColor{},
+		border: lipgloss.NoColor{}, alertText: lipgloss.NoColor{}, reverse: true,
+	}
+)
+
+// Colors of the current theme, see SetTheme
 var (
 	// Primary colors
-	primaryColor   = lipgloss.Color("#00D4AA") // Bright teal
-	secondaryColor = lipgloss.Color("#FF6B6B") // Coral red
-	accentColor    = lipgloss.Color("#4ECDC4") // Light teal
+	primaryColor   lipgloss.TerminalColor
+	secondaryColor lipgloss.TerminalColor
+	accentColor    lipgloss.TerminalColor
 
 	// Status colors
-	successColor = lipgloss.Color("#55FF55") // Bright green
-	warningColor = lipgloss.Color("#FFAA00") // Orange
-	errorColor   = lipgloss.Color("#FF5555") // Bright red
-	mutedColor   = lipgloss.Color("#888888") // Gray
-
-	// Background colors
-	selectedBg  = lipgloss.Color("#2A2A2A") // Dark gray
-	borderColor = lipgloss.Color("#444444") // Medium gray
+	successColor lipgloss.TerminalColor
+	warningColor lipgloss.TerminalColor
+	errorColor   lipgloss.TerminalColor
+	mutedColor   lipgloss.TerminalColor
+
+	// Text and background colors
+	textColor   lipgloss.TerminalColor
+	selectedBg  lipgloss.TerminalColor
+	borderColor lipgloss.TerminalColor
 )
 
-// Base styles
+// Base styles, built from the theme's colors by applyPalette
 var (
-	// Main container style
+	containerStyle          lipgloss.Style // Main container
+	headerStyle             lipgloss.Style
+	titleStyle              lipgloss.Style
+	contextStyle            lipgloss.Style // Context info
+	updateStyle             lipgloss.Style // Update notification
+	statusRunningStyle      lipgloss.Style
+	statusFailedStyle       lipgloss.Style
+	statusStartingStyle     lipgloss.Style
+	statusCooldownStyle     lipgloss.Style
+	statusDegradedStyle     lipgloss.Style
+	statusConnectingStyle   lipgloss.Style
+	statusReconnectingStyle lipgloss.Style
+	statusSuspendedStyle    lipgloss.Style
+	statusIdleStyle         lipgloss.Style
+	tableHeaderStyle        lipgloss.Style
+	tableRowStyle           lipgloss.Style
+	tableSelectedRowStyle   lipgloss.Style
+	urlStyle                lipgloss.Style // URL links
+	helpStyle               lipgloss.Style // Help text
+	errorMessageStyle       lipgloss.Style
+	exposedBadgeStyle       lipgloss.Style // Badge for services reachable from the local network
+	readOnlyBadgeStyle      lipgloss.Style // Badge shown in the header of an observer TUI
+	footerStyle             lipgloss.Style
+	headerAlertStyle        lipgloss.Style // Highlights the header while it flashes, see alert.go
+)
+
+func init() {
+	applyPalette(darkPalette)
+}
+
+// SetTheme switches all styles to a uiOptions.theme: dark, light or no-color. Unknown
+// themes, including "", get the dark default. Styles are shared by the whole process,
+// so it must be called before a TUI starts.
+func SetTheme(theme string) {
+	switch theme {
+	case config.ThemeLight:
+		applyPalette(lightPalette)
+	case config.ThemeNoColor:
+		applyPalette(noColorPalette)
+	default:
+		applyPalette(darkPalette)
+	}
+}
+
+// applyPalette sets the theme colors and rebuilds the styles from them
+func applyPalette(p palette) {
+	primaryColor, secondaryColor, accentColor = p.primary, p.secondary, p.accent
+	successColor, warningColor, errorColor, mutedColor = p.success, p.warning, p.error, p.muted
+	textColor, selectedBg, borderColor = p.text, p.selectedBg, p.border
+
 	containerStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(borderColor).
-			Padding(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Padding(0, 1)
 
-	// Header style
 	headerStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true).
-			Padding(0, 1)
+		Foreground(primaryColor).
+		Bold(true).
+		Padding(0, 1)
 
-	// Title style
 	titleStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true)
+		Foreground(primaryColor).
+		Bold(true)
 
-	// Context info style
 	contextStyle = lipgloss.NewStyle().
-			Foreground(accentColor).
-			Italic(true)
+		Foreground(accentColor).
+		Italic(true)
 
-	// Update notification style
 	updateStyle = lipgloss.NewStyle().
-			Foreground(warningColor).
-			Bold(true)
+		Foreground(warningColor).
+		Bold(true)
 
 	// Status indicator styles
-	statusRunningStyle = lipgloss.NewStyle().
-				Foreground(successColor).
-				Bold(true)
-
-	statusFailedStyle = lipgloss.NewStyle().
-				Foreground(errorColor).
-				Bold(true)
-
-	statusStartingStyle = lipgloss.NewStyle().
-				Foreground(warningColor).
-				Bold(true)
-
-	statusCooldownStyle = lipgloss.NewStyle().
-				Foreground(mutedColor).
-				Bold(true)
-
-	statusDegradedStyle = lipgloss.NewStyle().
-				Foreground(warningColor).
-				Bold(true)
-
-	statusConnectingStyle = lipgloss.NewStyle().
-				Foreground(accentColor).
-				Bold(true)
-
-	statusReconnectingStyle = lipgloss.NewStyle().
-				Foreground(accentColor).
-				Bold(true)
-
-	statusSuspendedStyle = lipgloss.NewStyle().
-				Foreground(mutedColor).
-				Bold(true)
+	statusRunningStyle = lipgloss.NewStyle().Foreground(successColor).Bold(true)
+	statusFailedStyle = lipgloss.NewStyle().Foreground(errorColor).Bold(true)
+	statusStartingStyle = lipgloss.NewStyle().Foreground(warningColor).Bold(true)
+	statusCooldownStyle = lipgloss.NewStyle().Foreground(mutedColor).Bold(true)
+	statusDegradedStyle = lipgloss.NewStyle().Foreground(warningColor).Bold(true)
+	statusConnectingStyle = lipgloss.NewStyle().Foreground(accentColor).Bold(true)
+	statusReconnectingStyle = lipgloss.NewStyle().Foreground(accentColor).Bold(true)
+	statusSuspendedStyle = lipgloss.NewStyle().Foreground(mutedColor).Bold(true)
+	statusIdleStyle = lipgloss.NewStyle().Foreground(mutedColor)
 
 	// Table styles
 	tableHeaderStyle = lipgloss.NewStyle().
-				Foreground(primaryColor).
-				Bold(true).
-				Underline(true)
+		Foreground(primaryColor).
+		Bold(true).
+		Underline(true)
 
 	tableRowStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF"))
+		Foreground(textColor)
 
 	tableSelectedRowStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFFFFF")).
-				Background(selectedBg).
-				Bold(true)
+		Foreground(textColor).
+		Background(selectedBg).
+		Bold(true).
+		Reverse(p.reverse)
 
-	// URL link style
 	urlStyle = lipgloss.NewStyle().
-			Foreground(accentColor).
-			Bold(false)
+		Foreground(accentColor).
+		Bold(false)
 
-	// Help text style
 	helpStyle = lipgloss.NewStyle().
-			Foreground(mutedColor).
-			Italic(true)
+		Foreground(mutedColor).
+		Italic(true)
 
-	// Error message style
 	errorMessageStyle = lipgloss.NewStyle().
-				Foreground(errorColor).
-				Italic(true)
+		Foreground(errorColor).
+		Italic(true)
+
+	exposedBadgeStyle = lipgloss.NewStyle().
+		Foreground(warningColor).
+		Bold(true)
+
+	readOnlyBadgeStyle = lipgloss.NewStyle().
+		Foreground(primaryColor).
+		Bold(true)
 
-	// Footer style
 	footerStyle = lipgloss.NewStyle().
-			Foreground(mutedColor).
-			Italic(true).
-			Padding(0, 1)
-)
+		Foreground(mutedColor).
+		Italic(true).
+		Padding(0, 1)
+
+	headerAlertStyle = headerStyle.Copy().
+		Foreground(p.alertText).
+		Background(errorColor).
+		Reverse(p.reverse)
+}
 
 // GetStatusStyle returns the appropriate style for a service status
 func GetStatusStyle(status string) lipgloss.Style {
 	switch status {
 	case "Running":
 		return statusRunningStyle
-	case "Failed":
+	case "Failed", "PortConflict":
 		return statusFailedStyle
-	case "Starting":
+	case "Starting", "Waiting":
 		return statusStartingStyle
 	case "Cooldown":
 		return statusCooldownStyle
@@ -139,11 +228,44 @@ func GetStatusStyle(status string) lipgloss.Style {
 		return statusReconnectingStyle
 	case "Suspended":
 		return statusSuspendedStyle
+	case "Idle", "Scheduled", "Disabled":
+		return statusIdleStyle
 	default:
 		return statusStartingStyle
 	}
 }
 
+// statusSeverity orders statuses from the most to the least in need of attention, so
+// sorting by status puts Failed first and Degraded next to the other unhealthy states
+func statusSeverity(status string) int {
+	switch status {
+	case "Failed", "PortConflict":
+		return 0
+	case "Suspended":
+		return 1
+	case "Cooldown":
+		return 2
+	case "Degraded":
+		return 3
+	case "Reconnecting":
+		return 4
+	case "Connecting":
+		return 5
+	case "Starting", "Waiting":
+		return 6
+	case "Running":
+		return 7
+	case "Scheduled":
+		return 8
+	case "Idle":
+		return 9
+	case "Disabled":
+		return 10
+	default:
+		return 11
+	}
+}
+
 // GetStatusIndicator returns a colored status indicator with appropriate symbol
 func GetStatusIndicator(status string) string {
 	style := GetStatusStyle(status)
@@ -153,16 +275,26 @@ func GetStatusIndicator(status string) string {
 		return style.Render("●")
 	case "Failed":
 		return style.Render("✗")
+	case "PortConflict":
+		return style.Render("⊗")
 	case "Suspended":
 		return style.Render("⏸")
 	case "Connecting", "Reconnecting":
 		return style.Render("◐")
 	case "Starting":
 		return style.Render("◯")
+	case "Waiting":
+		return style.Render("⋯")
 	case "Degraded":
 		return style.Render("⚠")
 	case "Cooldown":
 		return style.Render("◦")
+	case "Idle":
+		return style.Render("◌")
+	case "Scheduled":
+		return style.Render("◷")
+	case "Disabled":
+		return style.Render("⊘")
 	default:
 		return style.Render("●")
 	}
@@ -173,6 +305,17 @@ func FormatURL(url string) string {
 	return urlStyle.Render(url)
 }
 
+// exposedBadgeText is the plain-text badge shown next to exposed services
+const exposedBadgeText = "EXPOSED"
+
+// readOnlyBadgeText is shown in the header while the TUI is in observer mode
+const readOnlyBadgeText = "👁 READ-ONLY"
+
+// FormatExposedBadge formats the warning badge for services bound to a non-loopback address
+func FormatExposedBadge() string {
+	return exposedBadgeStyle.Render(exposedBadgeText)
+}
+
 // FormatTableHeader formats table headers
 func FormatTableHeader(text string) string {
 	return tableHeaderStyle.Render(text)
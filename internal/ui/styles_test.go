@@ -3,6 +3,9 @@ package ui
 import (
 	"strings"
 	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/victorkazakov/kportforward/internal/config"
 )
 
 // TestGetStatusIndicator tests the status indicator functionality
@@ -20,6 +23,9 @@ func TestGetStatusIndicator(t *testing.T) {
 		{"Starting status", "Starting", "◯"},
 		{"Degraded status", "Degraded", "⚠"},
 		{"Cooldown status", "Cooldown", "◦"},
+		{"Idle status", "Idle", "◌"},
+		{"Scheduled status", "Scheduled", "◷"},
+		{"Disabled status", "Disabled", "⊘"},
 		{"Unknown status", "Unknown", "●"}, // Should default to ●
 	}
 
@@ -125,6 +131,9 @@ func TestAllStatusSymbolsUnique(t *testing.T) {
 		"Starting",     // ◯
 		"Degraded",     // ⚠
 		"Cooldown",     // ◦
+		"Idle",         // ◌
+		"Scheduled",    // ◷
+		"Disabled",     // ⊘
 	}
 
 	symbolMap := make(map[string][]string)
@@ -188,3 +197,26 @@ func BenchmarkGetStatusStyle(b *testing.B) {
 		GetStatusStyle(status)
 	}
 }
+
+// TestSetTheme tests switching the colors of all styles
+func TestSetTheme(t *testing.T) {
+	defer SetTheme(config.ThemeDark)
+
+	SetTheme(config.ThemeLight)
+	if primaryColor != lightPalette.primary || tableRowStyle.GetForeground() != lightPalette.text {
+		t.Errorf("Expected the light palette, got primary %v", primaryColor)
+	}
+
+	SetTheme(config.ThemeNoColor)
+	if _, ok := statusFailedStyle.GetForeground().(lipgloss.NoColor); !ok {
+		t.Errorf("Expected no color for failed services, got %v", statusFailedStyle.GetForeground())
+	}
+	if !tableSelectedRowStyle.GetReverse() {
+		t.Error("Expected the selected row to be reversed without colors")
+	}
+
+	SetTheme("")
+	if primaryColor != darkPalette.primary || tableSelectedRowStyle.GetReverse() {
+		t.Errorf("Expected the dark default, got primary %v", primaryColor)
+	}
+}
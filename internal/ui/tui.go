@@ -17,6 +17,9 @@ type TUI struct {
 	ctx        context.Context
 	cancel     context.CancelFunc
 	quitChan   chan bool
+
+	// recoverPanic is deferred in the event loop goroutine, see SetPanicRecovery
+	recoverPanic func()
 }
 
 // NewTUI creates a new terminal user interface
@@ -28,7 +31,9 @@ func NewTUI(statusChan <-chan map[string]config.ServiceStatus, serviceConfigs ma
 	program := tea.NewProgram(
 		model,
 		tea.WithAltScreen(), // Use alternate screen buffer
-		// Mouse support removed to enable text selection in terminal
+		// Panics reach SetPanicRecovery's handler, which restores the terminal itself
+		tea.WithoutCatchPanics(),
+		// Mouse events are only enabled with SetMouse, as they prevent text selection in the terminal
 	)
 
 	// Start listening for context updates
@@ -54,6 +59,9 @@ func NewTUI(statusChan <-chan map[string]config.ServiceStatus, serviceConfigs ma
 func (t *TUI) Start() error {
 	// Start the program in a goroutine and track it
 	go func() {
+		if t.recoverPanic != nil {
+			defer t.recoverPanic()
+		}
 		if _, err := t.program.Run(); err != nil {
 			// Don't log error - it might be a normal quit
 		}
@@ -82,6 +90,65 @@ func (t *TUI) Stop() error {
 	return nil
 }
 
+// SetReadOnly switches the TUI to observer mode. It must be called before Start.
+func (t *TUI) SetReadOnly(readOnly bool) {
+	t.model.readOnly = readOnly
+}
+
+// SetMouse enables clicking rows and URLs. The terminal's own text selection does not
+// work while it is enabled. It must be called before Start.
+func (t *TUI) SetMouse(enabled bool) {
+	t.model.mouse = enabled
+}
+
+// SetUIState restores the pinned services and the hidden and shown columns remembered
+// in the UI state. It must be called before Start.
+func (t *TUI) SetUIState(state config.UIState) {
+	t.model.invalidateLayout()
+	t.model.pinned = make(map[string]bool, len(state.Pinned))
+	for _, name := range state.Pinned {
+		t.model.pinned[name] = true
+	}
+	t.model.hiddenColumns = make(map[tableColumn]bool, len(state.HiddenColumns))
+	t.model.shownColumns = make(map[tableColumn]bool, len(state.ShownColumns))
+	for _, toggleable := range toggleableColumns {
+		for _, hidden := range state.HiddenColumns {
+			if string(toggleable.column) == hidden {
+				t.model.hiddenColumns[toggleable.column] = true
+			}
+		}
+		for _, shown := range state.ShownColumns {
+			if string(toggleable.column) == shown && optionalColumns[toggleable.column] {
+				t.model.shownColumns[toggleable.column] = true
+			}
+		}
+	}
+}
+
+// SetDisplayOptions applies the uptime and timestamp formats, the alert setting and the
+// refresh rate from uiOptions. It must be called before Start.
+func (t *TUI) SetDisplayOptions(options config.UIConfig) {
+	t.model.uptimeFormat = options.UptimeFormat
+	t.model.invalidateLayout()
+	t.model.timestampFormat = options.TimestampFormat
+	t.model.alert = options.Alert
+	t.model.issueThreshold = options.IssueThreshold
+	if options.RefreshRate > 0 {
+		t.model.refreshRate = options.RefreshRate
+	}
+}
+
+// SetPanicRecovery sets the function deferred in the event loop, which must call
+// recover itself. Without one, a panic crashes the process. It must be called before Start.
+func (t *TUI) SetPanicRecovery(recoverPanic func()) {
+	t.recoverPanic = recoverPanic
+}
+
+// ReleaseTerminal gives the terminal back, leaving the alternate screen and raw mode
+func (t *TUI) ReleaseTerminal() error {
+	return t.program.ReleaseTerminal()
+}
+
 // UpdateKubernetesContext sends a context update to the TUI
 func (t *TUI) UpdateKubernetesContext(context string) {
 	if t.program != nil {
@@ -89,6 +156,28 @@ func (t *TUI) UpdateKubernetesContext(context string) {
 	}
 }
 
+// UpdateServiceConfigs replaces the service configurations after a config reload
+func (t *TUI) UpdateServiceConfigs(serviceConfigs map[string]config.Service) {
+	if t.program != nil {
+		t.program.Send(ServiceConfigsMsg(serviceConfigs))
+	}
+}
+
+// UpdateDisabledServices replaces the list of disabled services, which are shown so
+// they can be enabled again
+func (t *TUI) UpdateDisabledServices(names []string) {
+	if t.program != nil {
+		t.program.Send(DisabledServicesMsg(names))
+	}
+}
+
+// ShowMessage shows a message in the footer for a few seconds
+func (t *TUI) ShowMessage(message string) {
+	if t.program != nil {
+		t.program.Send(ServiceActionMsg(message))
+	}
+}
+
 // NotifyUpdateAvailable sends an update notification to the TUI
 func (t *TUI) NotifyUpdateAvailable(updateInfo *updater.UpdateInfo) {
 	if t.program != nil {
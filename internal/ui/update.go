@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// UpdateApplier is implemented by providers that can install an available update
+type UpdateApplier interface {
+	// ApplyUpdate installs the latest release, which kportforward restarts into once the
+	// TUI quits
+	ApplyUpdate() error
+}
+
+// updateAppliedMsg carries the outcome of installing an update after pressing U
+type updateAppliedMsg struct {
+	err error
+}
+
+// canApplyUpdate reports whether U installs the available update from this view
+func (m *Model) canApplyUpdate() bool {
+	_, ok := m.manager.(UpdateApplier)
+	return ok && !m.readOnly && m.updateAvailable && !isHomebrewExecutable()
+}
+
+// applyUpdate returns a command installing the available update, if allowed
+func (m *Model) applyUpdate() tea.Cmd {
+	if !m.updateAvailable || m.UpdateInfo == nil {
+		m.showActionMessage("No update available")
+		return nil
+	}
+	if m.updating {
+		return nil
+	}
+	if isHomebrewExecutable() {
+		m.showActionMessage("Installed with Homebrew: update with 'brew upgrade kportforward'")
+		return nil
+	}
+	applier, ok := m.manager.(UpdateApplier)
+	if m.readOnly || !ok {
+		m.showActionMessage("Read-only: updates cannot be installed from this view")
+		return nil
+	}
+
+	m.updating = true
+	m.actionMessage = fmt.Sprintf("Downloading %s…", m.UpdateInfo.LatestVersion)
+	m.actionMessageExpiry = time.Time{}
+	return func() tea.Msg {
+		return updateAppliedMsg{err: applier.ApplyUpdate()}
+	}
+}
+
+// handleUpdateApplied quits to restart into an installed update, or reports why it failed
+func (m *Model) handleUpdateApplied(msg updateAppliedMsg) tea.Cmd {
+	m.updating = false
+	if msg.err != nil {
+		m.showActionMessage(fmt.Sprintf("Update failed: %v", msg.err))
+		return nil
+	}
+	m.actionMessage = "Update installed, restarting…"
+	m.actionMessageExpiry = time.Time{}
+	return tea.Quit
+}
+
+// isHomebrewExecutable reports whether kportforward runs from a Homebrew installation,
+// which is updated with brew instead
+func isHomebrewExecutable() bool {
+	execPath, _ := os.Executable()
+	return strings.Contains(execPath, "/Cellar/kportforward") ||
+		strings.Contains(execPath, "/opt/homebrew")
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/updater"
+)
+
+// mockUpdateApplier installs updates, failing with err
+type mockUpdateApplier struct {
+	*MockUIManagerProvider
+	err     error
+	applied int
+}
+
+func (m *mockUpdateApplier) ApplyUpdate() error {
+	m.applied++
+	return m.err
+}
+
+// TestModelApplyUpdate tests installing an update with U and quitting to restart into it
+func TestModelApplyUpdate(t *testing.T) {
+	manager := &mockUpdateApplier{MockUIManagerProvider: &MockUIManagerProvider{}, err: errors.New("checksum mismatch")}
+	model := NewModel(make(chan map[string]config.ServiceStatus, 1), map[string]config.Service{}, manager)
+	model.width = 200
+	model.height = 40
+	model.UpdateInfo = &updater.UpdateInfo{Available: true, CurrentVersion: "v1.0.0", LatestVersion: "v1.1.0"}
+	model.Update(UpdateAvailableMsg(true))
+	if view := model.View(); !strings.Contains(view, "Press U to update and restart") {
+		t.Fatalf("Expected the update notice to offer U, got:\n%s", view)
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'U'}})
+	if cmd == nil {
+		t.Fatal("Expected a command installing the update")
+	}
+	if _, quit := model.Update(cmd()); quit != nil || !strings.Contains(model.actionMessage, "Update failed: checksum mismatch") {
+		t.Fatalf("Expected the failure to be shown without quitting, got %q", model.actionMessage)
+	}
+
+	manager.err = nil
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'U'}})
+	_, quit := model.Update(cmd())
+	if quit == nil {
+		t.Fatal("Expected the TUI to quit once the update is installed")
+	}
+	if _, ok := quit().(tea.QuitMsg); !ok || manager.applied != 2 {
+		t.Errorf("Expected a quit after the second attempt, got %d attempts", manager.applied)
+	}
+}
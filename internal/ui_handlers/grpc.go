@@ -2,6 +2,7 @@ package ui_handlers
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
@@ -31,6 +32,7 @@ type GRPCUIService struct {
 	localPort    int
 	grpcuiPort   int
 	cmd          *exec.Cmd
+	job          io.Closer // Ends grpcui and its children on Windows, nil elsewhere
 	logFile      string
 	startTime    time.Time
 	restartCount int
@@ -50,7 +52,8 @@ func NewGRPCUIManager(logger *utils.Logger) *GRPCUIManager {
 func (gm *GRPCUIManager) Enable() error {
 	// Check if grpcui is available
 	if !gm.isGRPCUIAvailable() {
-		return fmt.Errorf("grpcui not found in PATH. Install with: go install github.com/fullstorydev/grpcui/cmd/grpcui@latest")
+		return common.WithKind(common.ErrNotFound,
+			fmt.Errorf("grpcui not found in PATH. Install with: go install github.com/fullstorydev/grpcui/cmd/grpcui@latest"))
 	}
 
 	gm.enabled = true
@@ -80,8 +83,8 @@ func (gm *GRPCUIManager) StartService(serviceName string, serviceStatus config.S
 		return nil
 	}
 
-	// Only start for RPC services that are running
-	if serviceConfig.Type != "rpc" || serviceStatus.Status != "Running" {
+	// Only start for running services whose type uses gRPC UI
+	if config.LookupServiceType(serviceConfig.Type).UIHandler != config.UIHandlerGRPCUI || serviceStatus.Status != "Running" {
 		return nil
 	}
 
@@ -119,7 +122,7 @@ func (gm *GRPCUIManager) StartService(serviceName string, serviceStatus config.S
 
 	// Start grpcui process
 	gm.logger.Debug("Starting gRPC UI for %s: connecting to localhost:%d, serving on port %d", serviceName, serviceStatus.LocalPort, grpcuiPort)
-	cmd, err := gm.startGRPCUIProcess(serviceName, serviceStatus.LocalPort, grpcuiPort, logFile)
+	cmd, job, err := gm.startGRPCUIProcess(serviceName, serviceStatus.LocalPort, grpcuiPort, logFile, serviceConfig.UIEnvironment())
 	if err != nil {
 		utils.ReleasePort(grpcuiPort) // Release allocated port on failure
 		gm.logger.Error("Failed to start grpcui process for %s: %v", serviceName, err)
@@ -132,6 +135,7 @@ func (gm *GRPCUIManager) StartService(serviceName string, serviceStatus config.S
 		localPort:    serviceStatus.LocalPort,
 		grpcuiPort:   grpcuiPort,
 		cmd:          cmd,
+		job:          job,
 		logFile:      logFile,
 		startTime:    time.Now(),
 		restartCount: 0,
@@ -175,6 +179,11 @@ func (gm *GRPCUIManager) stopService(serviceName string) error {
 		return nil
 	}
 
+	if service.job != nil {
+		if err := service.job.Close(); err != nil {
+			gm.logger.Warn("Failed to end gRPC UI job for %s: %v", serviceName, err)
+		}
+	}
 	if service.cmd != nil && service.cmd.Process != nil {
 		if err := utils.KillProcess(service.cmd.Process.Pid); err != nil {
 			gm.logger.Warn("Failed to kill gRPC UI process for %s: %v", serviceName, err)
@@ -247,8 +256,9 @@ func (gm *GRPCUIManager) isGRPCUIAvailable() bool {
 	return err == nil
 }
 
-// startGRPCUIProcess starts the grpcui process
-func (gm *GRPCUIManager) startGRPCUIProcess(serviceName string, targetPort, grpcuiPort int, logFile string) (*exec.Cmd, error) {
+// startGRPCUIProcess starts the grpcui process with env added to ours, returning the job
+// that ends its process tree where the platform has one
+func (gm *GRPCUIManager) startGRPCUIProcess(serviceName string, targetPort, grpcuiPort int, logFile string, env []string) (*exec.Cmd, io.Closer, error) {
 	// grpcui arguments
 	args := []string{
 		"-bind", "localhost",
@@ -260,36 +270,62 @@ func (gm *GRPCUIManager) startGRPCUIProcess(serviceName string, targetPort, grpc
 	}
 
 	cmd := exec.Command("grpcui", args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
 	// Set up logging
 	logFileHandle, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %w", err)
+		return nil, nil, fmt.Errorf("failed to open log file: %w", err)
 	}
 
 	// Platform-specific process setup
-	if err := gm.startGRPCUIProcessPlatform(cmd, logFileHandle); err != nil {
-		return nil, err
+	job, err := gm.startGRPCUIProcessPlatform(cmd, logFileHandle)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Reap the process when it exits to prevent zombie processes.
-	// cmd.Wait() releases the OS process table entry once the process dies.
+	// cmd.Wait() releases the OS process table entry once the process dies,
+	// and closing the job ends anything grpcui left running.
 	go func() {
 		cmd.Wait()
 		logFileHandle.Close()
+		if job != nil {
+			job.Close()
+		}
 	}()
 
-	return cmd, nil
+	return cmd, job, nil
 }
 
-// getLogFilePath returns the log file path for a service
-func (gm *GRPCUIManager) getLogFilePath(serviceName string) string {
-	logDir := "/tmp"
+// GRPCUILogPattern matches the gRPC UI log files in GRPCUILogDir
+const GRPCUILogPattern = "kpf_grpcui_*.log"
+
+// GRPCUILogDir returns the directory gRPC UI log files are written to
+func GRPCUILogDir() string {
 	if runtime.GOOS == "windows" {
-		logDir = os.TempDir()
+		return os.TempDir()
+	}
+	return "/tmp"
+}
+
+// GetLogPath returns the log file of a service's gRPC UI, or "" if none was started
+func (gm *GRPCUIManager) GetLogPath(serviceName string) string {
+	service := gm.GetServiceInfo(serviceName)
+	if service == nil {
+		return ""
 	}
+	return service.logFile
+}
+
+// getLogFilePath returns the log file path for a service
+func (gm *GRPCUIManager) getLogFilePath(serviceName string) string {
+	logDir := GRPCUILogDir()
 
-	filename := fmt.Sprintf("kpf_grpcui_%s.log", strings.ReplaceAll(serviceName, "-", "_"))
+	// Profile services are named "<profile>/<service>"
+	filename := fmt.Sprintf("kpf_grpcui_%s.log", strings.NewReplacer("-", "_", "/", "_").Replace(serviceName))
 	return filepath.Join(logDir, filename)
 }
 
@@ -311,7 +347,7 @@ func (gm *GRPCUIManager) MonitorServices(services map[string]config.ServiceStatu
 	// Start gRPC UI for new RPC services, and restart failed ones
 	for serviceName, serviceStatus := range services {
 		if serviceConfig, exists := configs[serviceName]; exists {
-			if serviceConfig.Type == "rpc" && serviceStatus.Status == "Running" {
+			if config.LookupServiceType(serviceConfig.Type).UIHandler == config.UIHandlerGRPCUI && serviceStatus.Status == "Running" {
 				existing, uiExists := gm.services[serviceName]
 				needsStart := !uiExists
 
@@ -4,13 +4,15 @@ package ui_handlers
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"syscall"
 )
 
-// startGRPCUIProcess starts the grpcui process with Unix-specific settings
-func (gm *GRPCUIManager) startGRPCUIProcessPlatform(cmd *exec.Cmd, logFileHandle *os.File) error {
+// startGRPCUIProcess starts the grpcui process with Unix-specific settings. The process
+// group is enough to stop it with its children, so no job is returned.
+func (gm *GRPCUIManager) startGRPCUIProcessPlatform(cmd *exec.Cmd, logFileHandle *os.File) (io.Closer, error) {
 	cmd.Stdout = logFileHandle
 	cmd.Stderr = logFileHandle
 
@@ -21,8 +23,8 @@ func (gm *GRPCUIManager) startGRPCUIProcessPlatform(cmd *exec.Cmd, logFileHandle
 
 	if err := cmd.Start(); err != nil {
 		logFileHandle.Close()
-		return fmt.Errorf("failed to start grpcui: %w", err)
+		return nil, fmt.Errorf("failed to start grpcui: %w", err)
 	}
 
-	return nil
+	return nil, nil
 }
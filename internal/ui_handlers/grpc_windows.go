@@ -4,21 +4,34 @@ package ui_handlers
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"syscall"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
 )
 
-// startGRPCUIProcess starts the grpcui process with Windows-specific settings
-func (gm *GRPCUIManager) startGRPCUIProcessPlatform(cmd *exec.Cmd, logFileHandle *os.File) error {
+// createNoWindow is the CREATE_NO_WINDOW creation flag: grpcui gets no console host
+const createNoWindow = 0x08000000
+
+// startGRPCUIProcess starts the grpcui process with Windows-specific settings. grpcui is
+// put into a job object, so closing the returned job ends it together with anything it
+// started; if that fails, stopping falls back to taskkill /T.
+func (gm *GRPCUIManager) startGRPCUIProcessPlatform(cmd *exec.Cmd, logFileHandle *os.File) (io.Closer, error) {
 	cmd.Stdout = logFileHandle
 	cmd.Stderr = logFileHandle
-
-	// No special process group setup needed on Windows
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: createNoWindow}
 
 	if err := cmd.Start(); err != nil {
 		logFileHandle.Close()
-		return fmt.Errorf("failed to start grpcui: %w", err)
+		return nil, fmt.Errorf("failed to start grpcui: %w", err)
 	}
 
-	return nil
+	job, err := utils.NewProcessJob(cmd.Process.Pid)
+	if err != nil {
+		gm.logger.Debug("Stopping grpcui (PID %d) will fall back to taskkill: %v", cmd.Process.Pid, err)
+		return nil, nil
+	}
+	return job, nil
 }
@@ -3,7 +3,9 @@ package ui_handlers
 import (
 	"fmt"
 	"net"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -13,6 +15,12 @@ import (
 	"github.com/victorkazakov/kportforward/internal/utils"
 )
 
+// Labels on Swagger UI containers, so containers left behind by a crashed kportforward can be found
+const (
+	SwaggerContainerLabel = "kportforward=swagger-ui"
+	SwaggerOwnerLabel     = "kportforward.pid" // PID of the kportforward that started the container
+)
+
 // SwaggerUIManager manages Swagger UI containers for REST services
 type SwaggerUIManager struct {
 	services       map[string]*SwaggerUIService
@@ -20,6 +28,7 @@ type SwaggerUIManager struct {
 	mutex          sync.RWMutex
 	enabled        bool
 	statusCallback common.StatusCallback
+	specFailures   map[string]time.Time // When the spec of a service last failed checkSpec
 }
 
 // SwaggerUIService represents a single Swagger UI instance
@@ -39,9 +48,10 @@ type SwaggerUIService struct {
 // NewSwaggerUIManager creates a new Swagger UI manager
 func NewSwaggerUIManager(logger *utils.Logger) *SwaggerUIManager {
 	return &SwaggerUIManager{
-		services: make(map[string]*SwaggerUIService),
-		logger:   logger,
-		enabled:  false,
+		services:     make(map[string]*SwaggerUIService),
+		logger:       logger,
+		enabled:      false,
+		specFailures: make(map[string]time.Time),
 	}
 }
 
@@ -49,7 +59,8 @@ func NewSwaggerUIManager(logger *utils.Logger) *SwaggerUIManager {
 func (sm *SwaggerUIManager) Enable() error {
 	// Check if Docker is available
 	if !sm.isDockerAvailable() {
-		return fmt.Errorf("docker not found or not running. Please install and start Docker Desktop")
+		return common.WithKind(common.ErrDockerUnavailable,
+			fmt.Errorf("docker not found or not running. Please install and start Docker Desktop"))
 	}
 
 	sm.enabled = true
@@ -79,8 +90,8 @@ func (sm *SwaggerUIManager) StartService(serviceName string, serviceStatus confi
 		return nil
 	}
 
-	// Only start for REST services that are running and have a swaggerPath configured
-	if serviceConfig.Type != "rest" || serviceStatus.Status != "Running" {
+	// Only start for running services whose type uses Swagger UI and that have a swaggerPath configured
+	if config.LookupServiceType(serviceConfig.Type).UIHandler != config.UIHandlerSwagger || serviceStatus.Status != "Running" {
 		return nil
 	}
 
@@ -137,9 +148,21 @@ func (sm *SwaggerUIManager) StartService(serviceName string, serviceStatus confi
 		return fmt.Errorf("port-forward not ready on port %d", serviceStatus.LocalPort)
 	}
 
+	// A container pointed at a missing or broken spec only renders an error page
+	if err := checkSpec(specURL(serviceStatus.LocalPort, apiPath, swaggerPath)); err != nil {
+		utils.ReleasePort(swaggerPort)
+		sm.specFailures[serviceName] = time.Now()
+		sm.logger.Warn("Swagger UI for %s not started: %v", serviceName, err)
+		if sm.statusCallback != nil {
+			sm.statusCallback.UpdateServiceStatusMessage(serviceName, specStatusMessage(err))
+		}
+		return fmt.Errorf("swagger spec check failed: %w", err)
+	}
+	delete(sm.specFailures, serviceName)
+
 	// Start Docker container
 	sm.logger.Info("Starting Swagger UI for %s: connecting to localhost:%d, serving on port %d", serviceName, serviceStatus.LocalPort, swaggerPort)
-	containerID, containerName, err := sm.startSwaggerContainer(serviceName, serviceStatus.LocalPort, swaggerPort, swaggerPath, apiPath)
+	containerID, containerName, err := sm.startSwaggerContainer(serviceName, serviceStatus.LocalPort, swaggerPort, swaggerPath, apiPath, serviceConfig.UIEnvironment())
 	if err != nil {
 		utils.ReleasePort(swaggerPort) // Release allocated port on failure
 		sm.logger.Error("Failed to start Swagger UI container for %s: %v", serviceName, err)
@@ -271,8 +294,9 @@ func (sm *SwaggerUIManager) isDockerAvailable() bool {
 	return err == nil
 }
 
-// startSwaggerContainer starts a Docker container with Swagger UI
-func (sm *SwaggerUIManager) startSwaggerContainer(serviceName string, targetPort, swaggerPort int, swaggerPath, apiPath string) (string, string, error) {
+// startSwaggerContainer starts a Docker container with Swagger UI and env, given as
+// KEY=value pairs
+func (sm *SwaggerUIManager) startSwaggerContainer(serviceName string, targetPort, swaggerPort int, swaggerPath, apiPath string, env []string) (string, string, error) {
 	containerName := fmt.Sprintf("kpf-swagger-%s", strings.ReplaceAll(serviceName, "_", "-"))
 
 	// Kill any existing container using the same port (like the working bash code)
@@ -281,21 +305,30 @@ func (sm *SwaggerUIManager) startSwaggerContainer(serviceName string, targetPort
 	// Stop any existing container with the same name
 	sm.stopContainerByName(containerName)
 
-	// Build URL like the working bash code: http://localhost:${lport}/${api_path}/${swagger_path}
-	swaggerURL := fmt.Sprintf("http://localhost:%d/%s/%s", targetPort, apiPath, swaggerPath)
+	swaggerURL := specURL(targetPort, apiPath, swaggerPath)
 
 	// Docker run arguments (simplified to match working bash code)
 	args := []string{
 		"run",
 		"--rm",
 		"-d",
+		"--label", SwaggerContainerLabel,
+		"--label", fmt.Sprintf("%s=%d", SwaggerOwnerLabel, os.Getpid()),
 		"-p", fmt.Sprintf("%d:8080", swaggerPort),
-		"-e", fmt.Sprintf("URL=%s", swaggerURL),
-		"swaggerapi/swagger-ui",
 	}
+	// Values are passed through docker's environment so secrets stay out of the logged
+	// command; URL comes last so uiEnv cannot point the container at another spec
+	for _, variable := range env {
+		name, _, _ := strings.Cut(variable, "=")
+		args = append(args, "-e", name)
+	}
+	args = append(args, "-e", fmt.Sprintf("URL=%s", swaggerURL), "swaggerapi/swagger-ui")
 
 	sm.logger.Info("Starting Docker container with command: docker %s", strings.Join(args, " "))
 	cmd := exec.Command("docker", args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	output, err := cmd.Output()
 	if err != nil {
 		sm.logger.Error("Docker container startup failed: %v", err)
@@ -372,7 +405,7 @@ func (sm *SwaggerUIManager) MonitorServices(services map[string]config.ServiceSt
 	runningRestServices := 0
 	for serviceName, serviceStatus := range services {
 		if serviceConfig, exists := configs[serviceName]; exists {
-			if serviceConfig.Type == "rest" {
+			if config.LookupServiceType(serviceConfig.Type).UIHandler == config.UIHandlerSwagger {
 				restServicesFound++
 				sm.logger.Info("Found REST service %s with status: %s", serviceName, serviceStatus.Status)
 				if serviceStatus.Status == "Running" {
@@ -388,6 +421,11 @@ func (sm *SwaggerUIManager) MonitorServices(services map[string]config.ServiceSt
 						needsStart = true
 					}
 
+					// A broken spec is only checked again after a while
+					if failedAt, failed := sm.specFailures[serviceName]; failed && time.Since(failedAt) < specRetryInterval {
+						needsStart = false
+					}
+
 					if needsStart {
 						sm.logger.Info("Starting Swagger UI for REST service: %s", serviceName)
 						go func(name string, status config.ServiceStatus, config config.Service) {
@@ -416,3 +454,40 @@ func (sm *SwaggerUIManager) MonitorServices(services map[string]config.ServiceSt
 		}
 	}
 }
+
+// RemoveOrphanedSwaggerContainers removes Swagger UI containers whose kportforward is no
+// longer running and returns their IDs. Nothing is removed when dryRun is set. Without
+// Docker there is nothing to clean up and no error is returned.
+func RemoveOrphanedSwaggerContainers(dryRun bool) ([]string, error) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return nil, nil
+	}
+
+	output, err := exec.Command("docker", "ps", "-a",
+		"--filter", "label="+SwaggerContainerLabel,
+		"--format", fmt.Sprintf("{{.ID}} {{.Label %q}}", SwaggerOwnerLabel)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Swagger UI containers: %w", err)
+	}
+
+	var removed []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) == 2 {
+			if pid, err := strconv.Atoi(fields[1]); err == nil && utils.IsProcessRunning(pid) {
+				continue
+			}
+		}
+
+		if !dryRun {
+			if err := exec.Command("docker", "rm", "-f", fields[0]).Run(); err != nil {
+				return removed, fmt.Errorf("failed to remove container %s: %w", fields[0], err)
+			}
+		}
+		removed = append(removed, fields[0])
+	}
+	return removed, nil
+}
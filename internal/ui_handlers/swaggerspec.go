@@ -0,0 +1,68 @@
+package ui_handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// specRetryInterval is how long a service whose spec failed checkSpec waits before the
+// Swagger UI is tried again
+const specRetryInterval = 30 * time.Second
+
+// maxSpecSize limits how much of a spec is read
+const maxSpecSize = 10 << 20
+
+// errSpecNotFound is returned by checkSpec when the spec URL answers 404
+var errSpecNotFound = errors.New("spec not found (404)")
+
+// specHTTPClient fetches specs; it does not wait long for a service that hangs
+var specHTTPClient = &http.Client{Timeout: 5 * time.Second}
+
+// specURL returns the URL of a service's spec on its forwarded port
+func specURL(localPort int, apiPath, swaggerPath string) string {
+	return fmt.Sprintf("http://localhost:%d/%s/%s", localPort, apiPath, swaggerPath)
+}
+
+// checkSpec fetches the spec at url and checks that it is an OpenAPI or Swagger
+// document in JSON or YAML
+func checkSpec(url string) error {
+	resp, err := specHTTPClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("could not fetch spec: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errSpecNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("spec returned %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSpecSize))
+	if err != nil {
+		return fmt.Errorf("could not read spec: %w", err)
+	}
+	// YAML is a superset of JSON, so this parses both
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(body, &document); err != nil {
+		return fmt.Errorf("spec is not valid JSON or YAML")
+	}
+	if document["openapi"] == nil && document["swagger"] == nil {
+		return fmt.Errorf("spec has no openapi or swagger version")
+	}
+	return nil
+}
+
+// specStatusMessage returns the status message shown for a spec that failed checkSpec
+func specStatusMessage(err error) string {
+	if errors.Is(err, errSpecNotFound) {
+		return "Swagger spec not found (404)"
+	}
+	return "Swagger spec invalid: " + err.Error()
+}
@@ -0,0 +1,56 @@
+package ui_handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckSpec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/openapi.json":
+			w.Write([]byte(`{"openapi": "3.0.0", "paths": {}}`))
+		case "/api/swagger.yaml":
+			w.Write([]byte("swagger: \"2.0\"\npaths: {}\n"))
+		case "/api/index.html":
+			w.Write([]byte("<html><body>Not a spec</body></html>"))
+		case "/api/config.json":
+			w.Write([]byte(`{"name": "billing"}`))
+		case "/api/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path    string
+		valid   bool
+		message string
+	}{
+		{"/api/openapi.json", true, ""},
+		{"/api/swagger.yaml", true, ""},
+		{"/api/missing.json", false, "Swagger spec not found (404)"},
+		{"/api/index.html", false, "Swagger spec invalid: spec is not valid JSON or YAML"},
+		{"/api/config.json", false, "Swagger spec invalid: spec has no openapi or swagger version"},
+		{"/api/error", false, "Swagger spec invalid: spec returned 500 Internal Server Error"},
+	}
+
+	for _, tt := range tests {
+		err := checkSpec(server.URL + tt.path)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid=%v, got %v", tt.path, tt.valid, err)
+			continue
+		}
+		if err != nil && specStatusMessage(err) != tt.message {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.message, specStatusMessage(err))
+		}
+	}
+
+	if err := checkSpec(server.URL + "/api/missing.json"); !errors.Is(err, errSpecNotFound) {
+		t.Errorf("Expected errSpecNotFound, got %v", err)
+	}
+}
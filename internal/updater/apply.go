@@ -0,0 +1,179 @@
+package updater
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/common"
+)
+
+// checksumsAsset is the release asset with the SHA-256 of every binary, written by
+// scripts/build.sh in sha256sum format
+const checksumsAsset = "checksums.txt"
+
+// downloadTimeout limits downloading a release binary
+const downloadTimeout = 5 * time.Minute
+
+// ApplyUpdate downloads the release binary for this platform, verifies it against the
+// release's checksums and replaces the running executable with it. The new version runs
+// from the next start, see Restart. Homebrew installations are left to brew.
+func (m *Manager) ApplyUpdate(updateInfo *UpdateInfo) error {
+	if updateInfo == nil || !updateInfo.Available {
+		return fmt.Errorf("no update available")
+	}
+	if isHomebrewInstalled() {
+		return fmt.Errorf("installed with Homebrew, update with 'brew upgrade kportforward'")
+	}
+	if updateInfo.DownloadURL == "" {
+		return common.WithKind(common.ErrNotFound, fmt.Errorf("release %s has no binary for %s/%s",
+			updateInfo.LatestVersion, runtime.GOOS, runtime.GOARCH))
+	}
+	if updateInfo.ChecksumURL == "" {
+		return common.WithKind(common.ErrNotFound, fmt.Errorf("release %s has no %s to verify the download against",
+			updateInfo.LatestVersion, checksumsAsset))
+	}
+
+	execPath, err := executablePath()
+	if err != nil {
+		return err
+	}
+
+	client := newHTTPClient(downloadTimeout)
+	expected, err := fetchChecksum(client, updateInfo.ChecksumURL, updateInfo.AssetName)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Info("Downloading %s from %s", updateInfo.LatestVersion, updateInfo.DownloadURL)
+	// Staged next to the executable so the final rename does not cross file systems
+	staged, err := downloadVerified(client, updateInfo.DownloadURL, filepath.Dir(execPath), expected)
+	if err != nil {
+		return err
+	}
+	if err := replaceExecutable(execPath, staged); err != nil {
+		os.Remove(staged)
+		return err
+	}
+
+	m.logger.Info("Updated %s from %s to %s", execPath, updateInfo.CurrentVersion, updateInfo.LatestVersion)
+	return nil
+}
+
+// executablePath returns the path of the running executable with symlinks resolved, so
+// the binary itself is replaced rather than a link to it
+func executablePath() (string, error) {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the executable: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(execPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", execPath, err)
+	}
+	return resolved, nil
+}
+
+// fetchChecksum returns the SHA-256 listed for asset in a checksums file
+func fetchChecksum(client *http.Client, url, asset string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", common.WithKind(common.ErrNetwork, fmt.Errorf("failed to download %s: %w", checksumsAsset, err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned HTTP %d", checksumsAsset, resp.StatusCode)
+	}
+
+	checksum, err := findChecksum(resp.Body, asset)
+	if err != nil {
+		return "", err
+	}
+	return checksum, nil
+}
+
+// findChecksum finds the checksum of asset in sha256sum output: "<hex>  <name>" lines,
+// where binary mode prefixes the name with "*"
+func findChecksum(r io.Reader, asset string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == asset {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", checksumsAsset, err)
+	}
+	return "", common.WithKind(common.ErrNotFound, fmt.Errorf("%s lists no checksum for %s", checksumsAsset, asset))
+}
+
+// downloadVerified downloads url into a new executable file in dir and returns its path,
+// provided its SHA-256 is expected. Nothing is left behind on failure.
+func downloadVerified(client *http.Client, url, dir, expected string) (path string, err error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", common.WithKind(common.ErrNetwork, fmt.Errorf("failed to download the update: %w", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("update download returned HTTP %d", resp.StatusCode)
+	}
+
+	file, err := os.CreateTemp(dir, ".kportforward-update-*")
+	if err != nil {
+		return "", fmt.Errorf("cannot write to %s, update kportforward where it is installed manually: %w", dir, err)
+	}
+	defer func() {
+		file.Close()
+		if err != nil {
+			os.Remove(file.Name())
+		}
+	}()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hash), resp.Body); err != nil {
+		return "", common.WithKind(common.ErrNetwork, fmt.Errorf("failed to download the update: %w", err))
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return "", fmt.Errorf("downloaded update has checksum %s, expected %s", actual, expected)
+	}
+	if err := file.Chmod(0755); err != nil {
+		return "", fmt.Errorf("failed to make the update executable: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write the update: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// replaceExecutable moves staged over execPath in one rename. Windows cannot replace a
+// running executable but can rename it, so the old one is moved aside to <name>.old first.
+func replaceExecutable(execPath, staged string) error {
+	if runtime.GOOS != "windows" {
+		if err := os.Rename(staged, execPath); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", execPath, err)
+		}
+		return nil
+	}
+
+	old := execPath + ".old"
+	// Left behind by the previous update, whose process has exited since
+	_ = os.Remove(old)
+	if err := os.Rename(execPath, old); err != nil {
+		return fmt.Errorf("failed to move %s aside: %w", execPath, err)
+	}
+	if err := os.Rename(staged, execPath); err != nil {
+		_ = os.Rename(old, execPath)
+		return fmt.Errorf("failed to replace %s: %w", execPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package updater
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFindChecksum(t *testing.T) {
+	checksums := "0a1b  kportforward-darwin-arm64\nFFEE *kportforward-linux-amd64\n"
+	if checksum, err := findChecksum(strings.NewReader(checksums), "kportforward-linux-amd64"); err != nil || checksum != "ffee" {
+		t.Errorf("Expected ffee, got %q %v", checksum, err)
+	}
+	if _, err := findChecksum(strings.NewReader(checksums), "kportforward-windows-amd64.exe"); err == nil {
+		t.Error("Expected an error for an asset without checksum")
+	}
+}
+
+func TestDownloadVerifiedAndReplace(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho new\n")
+	sum := sha256.Sum256(binary)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if _, err := downloadVerified(server.Client(), server.URL, dir, strings.Repeat("0", 64)); err == nil {
+		t.Fatal("Expected a checksum mismatch")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("Expected the rejected download to be removed, found %d files", len(entries))
+	}
+
+	staged, err := downloadVerified(server.Client(), server.URL, dir, hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatalf("Expected the download to verify, got %v", err)
+	}
+	execPath := filepath.Join(dir, "kportforward")
+	if err := os.WriteFile(execPath, []byte("old"), 0755); err != nil {
+		t.Fatalf("Failed to write the executable: %v", err)
+	}
+	if err := replaceExecutable(execPath, staged); err != nil {
+		t.Fatalf("Failed to replace the executable: %v", err)
+	}
+	if data, err := os.ReadFile(execPath); err != nil || string(data) != string(binary) {
+		t.Errorf("Expected the new binary in place, got %q %v", data, err)
+	}
+	if info, err := os.Stat(execPath); runtime.GOOS != "windows" && (err != nil || info.Mode().Perm()&0100 == 0) {
+		t.Errorf("Expected the new binary to be executable, got %v %v", info.Mode(), err)
+	}
+}
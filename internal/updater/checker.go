@@ -8,9 +8,11 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/victorkazakov/kportforward/internal/common"
 	"github.com/victorkazakov/kportforward/internal/utils"
 )
 
@@ -26,12 +28,18 @@ func NewChecker(config *UpdateConfig, logger *utils.Logger) *Checker {
 	return &Checker{
 		config: config,
 		logger: logger,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client: newHTTPClient(30 * time.Second),
 	}
 }
 
+// newHTTPClient returns a client for GitHub that honors HTTPS_PROXY, HTTP_PROXY and
+// NO_PROXY, as corporate networks often only reach it through a proxy
+func newHTTPClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
 // CheckForUpdates checks if a new version is available
 func (c *Checker) CheckForUpdates() (*UpdateInfo, error) {
 	c.logger.Info("Checking for updates...")
@@ -66,32 +74,77 @@ func (c *Checker) CheckForUpdates() (*UpdateInfo, error) {
 	return updateInfo, nil
 }
 
-// getLatestRelease fetches the latest release from GitHub API
+// githubAPI is the GitHub REST API; replaced in tests
+var githubAPI = "https://api.github.com"
+
+// Update channels, see UpdateConfig.UpdateChannel
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+)
+
+// betaReleasesPerPage is how many of the most recent releases the beta channel considers
+const betaReleasesPerPage = 30
+
+// getLatestRelease fetches the newest release of the update channel from the GitHub API:
+// the latest release for stable, or the newest of the recent releases including
+// pre-releases for beta
 func (c *Checker) getLatestRelease() (*Release, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest",
-		c.config.RepoOwner, c.config.RepoName)
+	if c.config.UpdateChannel != ChannelBeta {
+		var release Release
+		if err := c.getReleases("/releases/latest", &release); err != nil {
+			return nil, err
+		}
+		return &release, nil
+	}
+
+	var releases []Release
+	if err := c.getReleases(fmt.Sprintf("/releases?per_page=%d", betaReleasesPerPage), &releases); err != nil {
+		return nil, err
+	}
+	var latest *Release
+	for i := range releases {
+		release := &releases[i]
+		if release.Draft {
+			continue
+		}
+		if latest == nil || c.isNewerVersion(release.TagName, latest.TagName) {
+			latest = release
+		}
+	}
+	if latest == nil {
+		return nil, common.WithKind(common.ErrNotFound, fmt.Errorf("no releases found for %s/%s", c.config.RepoOwner, c.config.RepoName))
+	}
+	return latest, nil
+}
+
+// getReleases decodes a GitHub API response about the repository's releases into v
+func (c *Checker) getReleases(path string, v interface{}) error {
+	url := fmt.Sprintf("%s/repos/%s/%s%s", githubAPI, c.config.RepoOwner, c.config.RepoName, path)
 
 	resp, err := c.client.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch release data: %w", err)
+		return common.WithKind(common.ErrNetwork, fmt.Errorf("failed to fetch release data: %w", err))
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return common.WithKind(common.ErrNotFound, fmt.Errorf("no releases found for %s/%s", c.config.RepoOwner, c.config.RepoName))
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+		return fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	var release Release
-	if err := json.Unmarshal(body, &release); err != nil {
-		return nil, fmt.Errorf("failed to parse release data: %w", err)
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse release data: %w", err)
 	}
 
-	return &release, nil
+	return nil
 }
 
 // compareVersions compares current version with latest release
@@ -111,14 +164,21 @@ func (c *Checker) compareVersions(release *Release) *UpdateInfo {
 		asset := c.findAssetForPlatform(release.Assets)
 		if asset != nil {
 			updateInfo.DownloadURL = asset.BrowserDownloadURL
+			updateInfo.AssetName = asset.Name
 			updateInfo.AssetSize = asset.Size
 		}
+		for _, asset := range release.Assets {
+			if asset.Name == checksumsAsset {
+				updateInfo.ChecksumURL = asset.BrowserDownloadURL
+			}
+		}
 	}
 
 	return updateInfo
 }
 
-// isNewerVersion checks if version A is newer than version B using semantic versioning rules
+// isNewerVersion checks if version A is newer than version B using semantic versioning rules.
+// A pre-release such as 1.2.0-beta.1 is older than 1.2.0 but newer than 1.1.x.
 func (c *Checker) isNewerVersion(versionA, versionB string) bool {
 	// Remove 'v' prefix if present
 	versionA = strings.TrimPrefix(versionA, "v")
@@ -129,6 +189,10 @@ func (c *Checker) isNewerVersion(versionA, versionB string) bool {
 		return true
 	}
 
+	// Split off pre-release and build metadata
+	versionA, preA := splitPrerelease(versionA)
+	versionB, preB := splitPrerelease(versionB)
+
 	// Parse versions into segments
 	segmentsA := strings.Split(versionA, ".")
 	segmentsB := strings.Split(versionB, ".")
@@ -153,8 +217,53 @@ func (c *Checker) isNewerVersion(versionA, versionB string) bool {
 		// If equal, continue to next segment
 	}
 
-	// All segments are equal
-	return false
+	// A release is newer than its pre-releases
+	if preA == "" || preB == "" {
+		return preA == "" && preB != ""
+	}
+	return comparePrerelease(preA, preB) > 0
+}
+
+// splitPrerelease splits "1.2.0-beta.1+build" into "1.2.0" and "beta.1"
+func splitPrerelease(version string) (string, string) {
+	if i := strings.Index(version, "+"); i >= 0 {
+		version = version[:i]
+	}
+	if i := strings.Index(version, "-"); i >= 0 {
+		return version[:i], version[i+1:]
+	}
+	return version, ""
+}
+
+// comparePrerelease orders pre-release identifiers the semantic versioning way:
+// dot-separated fields compare numerically if both are numbers and as text otherwise,
+// and more fields win when all others are equal (beta.1 < beta.1.1 < beta.2)
+func comparePrerelease(a, b string) int {
+	fieldsA := strings.Split(a, ".")
+	fieldsB := strings.Split(b, ".")
+	for i := 0; i < len(fieldsA) && i < len(fieldsB); i++ {
+		numA, errA := strconv.Atoi(fieldsA[i])
+		numB, errB := strconv.Atoi(fieldsB[i])
+		switch {
+		case errA == nil && errB == nil:
+			if numA != numB {
+				if numA > numB {
+					return 1
+				}
+				return -1
+			}
+		case errA == nil:
+			// Numeric identifiers sort before alphanumeric ones
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if cmp := strings.Compare(fieldsA[i], fieldsB[i]); cmp != 0 {
+				return cmp
+			}
+		}
+	}
+	return len(fieldsA) - len(fieldsB)
 }
 
 // findAssetForPlatform finds the appropriate asset for the current platform
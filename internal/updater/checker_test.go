@@ -0,0 +1,89 @@
+package updater
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestIsNewerVersionPrerelease(t *testing.T) {
+	checker := NewChecker(&UpdateConfig{}, utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard))
+	tests := []struct {
+		a, b  string
+		newer bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.2.0", "v1.2.0-beta.2", true},
+		{"v1.2.0-beta.2", "v1.2.0", false},
+		{"v1.2.0-beta.2", "v1.2.0-beta.1", true},
+		{"v1.2.0-beta.10", "v1.2.0-beta.9", true},
+		{"v1.2.0-rc.1", "v1.2.0-beta.3", true},
+		{"v1.2.0-beta.1.1", "v1.2.0-beta.1", true},
+		{"v1.2.0-beta.1", "v1.1.0", true},
+		{"v1.2.0", "v1.2.0", false},
+	}
+	for _, test := range tests {
+		if newer := checker.isNewerVersion(test.a, test.b); newer != test.newer {
+			t.Errorf("isNewerVersion(%s, %s) = %v, expected %v", test.a, test.b, newer, test.newer)
+		}
+	}
+}
+
+func TestGetLatestReleaseChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/releases/latest":
+			w.Write([]byte(`{"tag_name": "v1.1.0"}`))
+		case "/repos/owner/repo/releases":
+			w.Write([]byte(`[
+				{"tag_name": "v1.3.0-beta.1", "draft": true, "prerelease": true},
+				{"tag_name": "v1.2.0-beta.2", "prerelease": true},
+				{"tag_name": "v1.2.0-beta.1", "prerelease": true},
+				{"tag_name": "v1.1.0"}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(original string) { githubAPI = original }(githubAPI)
+	githubAPI = server.URL
+
+	logger := utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard)
+	for channel, expected := range map[string]string{ChannelStable: "v1.1.0", ChannelBeta: "v1.2.0-beta.2"} {
+		checker := NewChecker(&UpdateConfig{RepoOwner: "owner", RepoName: "repo", UpdateChannel: channel}, logger)
+		release, err := checker.getLatestRelease()
+		if err != nil {
+			t.Fatalf("%s: %v", channel, err)
+		}
+		if release.TagName != expected {
+			t.Errorf("%s: expected %s, got %s", channel, expected, release.TagName)
+		}
+	}
+}
+
+func TestManagerDisabled(t *testing.T) {
+	manager := NewManager("owner", "repo", "v1.0.0", utils.NewLoggerWithOutput(utils.LevelInfo, io.Discard))
+	if err := manager.SetCheckInterval(0); err == nil {
+		t.Error("Expected an error for a zero check interval")
+	}
+	if err := manager.SetCheckInterval(time.Hour); err != nil || manager.config.CheckInterval != time.Hour {
+		t.Errorf("Expected a 1h check interval, got %v %v", manager.config.CheckInterval, err)
+	}
+
+	manager.Disable()
+	if err := manager.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if manager.checkTicker != nil {
+		t.Error("Expected no periodic checks while disabled")
+	}
+	if !manager.State().Disabled {
+		t.Error("Expected the state to report checks as disabled")
+	}
+	manager.Stop()
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/victorkazakov/kportforward/internal/utils"
@@ -26,6 +27,13 @@ type Manager struct {
 
 	// State
 	lastUpdateInfo *UpdateInfo
+
+	// Outcome of the latest check, see State
+	stateMutex    sync.RWMutex
+	latestVersion string
+	available     bool
+	lastError     string
+	lastErrorTime time.Time
 }
 
 // NewManager creates a new update manager
@@ -45,7 +53,7 @@ func NewManager(repoOwner, repoName, currentVersion string, logger *utils.Logger
 		CurrentVersion: currentVersion,
 		CheckInterval:  24 * time.Hour, // Daily checks
 		LastCheckFile:  filepath.Join(cacheDir, "kportforward", "last_update_check"),
-		UpdateChannel:  "stable",
+		UpdateChannel:  ChannelStable,
 	}
 
 	checker := NewChecker(config, logger)
@@ -60,8 +68,42 @@ func NewManager(repoOwner, repoName, currentVersion string, logger *utils.Logger
 	}
 }
 
+// SetChannel selects the update channel, stable or beta, before Start. Each channel
+// remembers its own last check, so switching channels checks right away.
+func (m *Manager) SetChannel(channel string) error {
+	switch channel {
+	case ChannelStable:
+		m.config.LastCheckFile = filepath.Join(filepath.Dir(m.config.LastCheckFile), "last_update_check")
+	case ChannelBeta:
+		m.config.LastCheckFile = filepath.Join(filepath.Dir(m.config.LastCheckFile), "last_update_check_beta")
+	default:
+		return fmt.Errorf("unknown update channel %q (expected stable or beta)", channel)
+	}
+	m.config.UpdateChannel = channel
+	return nil
+}
+
+// SetCheckInterval sets the time between checks, before Start
+func (m *Manager) SetCheckInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("update check interval must be positive, got %v", interval)
+	}
+	m.config.CheckInterval = interval
+	return nil
+}
+
+// Disable turns off automatic checks, for networks that cannot reach GitHub. Start then
+// does nothing; ForceCheck and ApplyUpdate still work.
+func (m *Manager) Disable() {
+	m.config.Disabled = true
+}
+
 // Start begins the update checking process
 func (m *Manager) Start() error {
+	if m.config.Disabled {
+		m.logger.Info("Update checks are disabled")
+		return nil
+	}
 	m.logger.Info("Starting update manager")
 
 	// Start periodic checking (which will do an initial check after a short delay)
@@ -72,6 +114,7 @@ func (m *Manager) Start() error {
 	go func() {
 		time.Sleep(2 * time.Second) // Wait 2 seconds before first update check
 		updateInfo, err := m.checker.CheckForUpdates()
+		m.recordCheck(updateInfo, err)
 		if err != nil {
 			m.logger.Error("Initial update check failed: %v", err)
 			return
@@ -112,6 +155,7 @@ func (m *Manager) ForceCheck() (*UpdateInfo, error) {
 	m.logger.Info("Manual update check requested")
 
 	updateInfo, err := m.checker.ForceCheck()
+	m.recordCheck(updateInfo, err)
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +174,44 @@ func (m *Manager) IsUpdateAvailable() bool {
 	return m.lastUpdateInfo != nil && m.lastUpdateInfo.Available
 }
 
+// State returns the outcome of the latest update check
+func (m *Manager) State() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+
+	state := State{
+		LastErrorTime:   m.lastErrorTime,
+		LastError:       m.lastError,
+		UpdateAvailable: m.available,
+		CurrentVersion:  m.config.CurrentVersion,
+		LatestVersion:   m.latestVersion,
+		Disabled:        m.config.Disabled,
+	}
+	// Checks are skipped within the interval of an earlier run's check, so the file is authoritative
+	if lastCheck, err := m.checker.getLastCheckTime(); err == nil {
+		state.LastCheck = lastCheck
+	}
+	return state
+}
+
+// recordCheck records the outcome of an update check for State
+func (m *Manager) recordCheck(updateInfo *UpdateInfo, err error) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	if err != nil {
+		m.lastError = err.Error()
+		m.lastErrorTime = time.Now()
+		return
+	}
+	// Skipped checks carry no versions and leave the state unchanged
+	if updateInfo != nil && updateInfo.LatestVersion != "" {
+		m.available = updateInfo.Available
+		m.latestVersion = updateInfo.LatestVersion
+		m.lastError = ""
+	}
+}
+
 // periodicCheck runs the periodic update checking loop
 func (m *Manager) periodicCheck() {
 	defer m.checkTicker.Stop()
@@ -141,6 +223,7 @@ func (m *Manager) periodicCheck() {
 
 		case <-m.checkTicker.C:
 			updateInfo, err := m.checker.CheckForUpdates()
+			m.recordCheck(updateInfo, err)
 			if err != nil {
 				m.logger.Error("Periodic update check failed: %v", err)
 				continue
@@ -161,28 +244,6 @@ func (m *Manager) periodicCheck() {
 	}
 }
 
-// PrepareUpdate downloads and prepares the update (but doesn't apply it)
-func (m *Manager) PrepareUpdate(updateInfo *UpdateInfo) error {
-	if updateInfo.DownloadURL == "" {
-		return fmt.Errorf("no download URL available")
-	}
-
-	m.logger.Info("Preparing update %s", updateInfo.LatestVersion)
-
-	// Check for installation method (Homebrew vs direct download)
-	if isHomebrewInstalled() {
-		m.logger.Info("Homebrew installation detected, recommending update using 'brew upgrade kportforward'")
-		return nil
-	}
-
-	// TODO: Implement download and verification
-	// For now, just log that we would download
-	m.logger.Info("Would download from: %s", updateInfo.DownloadURL)
-	m.logger.Info("Asset size: %d bytes", updateInfo.AssetSize)
-
-	return nil
-}
-
 // isHomebrewInstalled checks if the application was installed via Homebrew
 func isHomebrewInstalled() bool {
 	// Look for Homebrew cellar path in executable path
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package updater
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Restart replaces the current process with the executable at its path, which
+// ApplyUpdate replaced, started with the same arguments and environment. It only returns
+// on failure.
+func Restart() error {
+	execPath, err := executablePath()
+	if err != nil {
+		return err
+	}
+	if err := syscall.Exec(execPath, os.Args, os.Environ()); err != nil {
+		return fmt.Errorf("failed to restart %s: %w", execPath, err)
+	}
+	return nil
+}
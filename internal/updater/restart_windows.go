@@ -0,0 +1,32 @@
+//go:build windows
+
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Restart runs the executable at the current one's path, which ApplyUpdate replaced, with
+// the same arguments in the same console and exits with its exit code once it ends.
+// Windows has no exec, so the current process waits to keep the console's shell waiting.
+// It only returns on failure.
+func Restart() error {
+	execPath, err := executablePath()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(execPath, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		return fmt.Errorf("failed to restart %s: %w", execPath, err)
+	}
+	os.Exit(0)
+	return nil
+}
@@ -30,10 +30,23 @@ type UpdateInfo struct {
 	LatestVersion  string
 	ReleaseNotes   string
 	DownloadURL    string
+	AssetName      string // Name of the binary at DownloadURL, as listed in the checksums
 	AssetSize      int64
+	ChecksumURL    string // checksums.txt of the release; "" if it has none
 	PublishedAt    time.Time
 }
 
+// State is the update checker's state as reported to monitoring
+type State struct {
+	LastCheck       time.Time // Last successful check, including those of earlier runs
+	LastErrorTime   time.Time // Zero when no check of this run failed
+	LastError       string
+	UpdateAvailable bool
+	CurrentVersion  string
+	LatestVersion   string // Empty until a check of this run completed
+	Disabled        bool   // Automatic checks are turned off
+}
+
 // UpdateConfig contains configuration for the updater
 type UpdateConfig struct {
 	RepoOwner      string
@@ -41,7 +54,8 @@ type UpdateConfig struct {
 	CurrentVersion string
 	CheckInterval  time.Duration
 	LastCheckFile  string
-	UpdateChannel  string // "stable" or "beta"
+	UpdateChannel  string // ChannelStable, or ChannelBeta to include pre-releases
+	Disabled       bool   // No automatic checks; ForceCheck still checks
 }
 
 // UpdateStatus represents the current update status
@@ -0,0 +1,25 @@
+package utils
+
+import "testing"
+
+func TestBrowserCommand(t *testing.T) {
+	tests := []struct {
+		goos string
+		name string
+	}{
+		{"darwin", "open"},
+		{"linux", "xdg-open"},
+		{"freebsd", "xdg-open"},
+		{"windows", "rundll32"},
+	}
+
+	for _, test := range tests {
+		name, args := browserCommand(test.goos, "https://example.com")
+		if name != test.name {
+			t.Errorf("%s: expected %s, got %s", test.goos, test.name, name)
+		}
+		if args[len(args)-1] != "https://example.com" {
+			t.Errorf("%s: expected URL as last argument, got %v", test.goos, args)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommand returns the command that copies its standard input to the clipboard
+// on goos; on Linux and BSD it depends on the display server
+func clipboardCommand(goos string, wayland bool) (string, []string) {
+	switch {
+	case goos == "darwin":
+		return "pbcopy", nil
+	case goos == "windows":
+		return "clip", nil
+	case wayland:
+		return "wl-copy", nil
+	default:
+		return "xclip", []string{"-selection", "clipboard"}
+	}
+}
+
+// CopyToClipboard puts text on the system clipboard
+func CopyToClipboard(text string) error {
+	name, args := clipboardCommand(runtime.GOOS, os.Getenv("WAYLAND_DISPLAY") != "")
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(text)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("%s failed: %s", name, message)
+		}
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
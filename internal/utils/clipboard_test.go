@@ -0,0 +1,23 @@
+package utils
+
+import "testing"
+
+func TestClipboardCommand(t *testing.T) {
+	tests := []struct {
+		goos    string
+		wayland bool
+		name    string
+	}{
+		{"darwin", false, "pbcopy"},
+		{"windows", false, "clip"},
+		{"linux", false, "xclip"},
+		{"linux", true, "wl-copy"},
+		{"freebsd", false, "xclip"},
+	}
+
+	for _, test := range tests {
+		if name, _ := clipboardCommand(test.goos, test.wayland); name != test.name {
+			t.Errorf("%s (wayland %v): expected %s, got %s", test.goos, test.wayland, test.name, name)
+		}
+	}
+}
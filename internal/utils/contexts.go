@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ListKubeContexts returns the contexts in the kubeconfig and the current one, which is
+// "" if none is set
+func ListKubeContexts(ctx context.Context) (contexts []string, current string, err error) {
+	output, err := exec.CommandContext(ctx, "kubectl", "config", "get-contexts", "-o", "name").Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, "", fmt.Errorf("kubectl config get-contexts failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, "", fmt.Errorf("kubectl config get-contexts failed: %w", err)
+	}
+	contexts = parseKubeContexts(string(output))
+
+	// Without a current context, kubectl exits with an error; that is not a failure here
+	if output, err := exec.CommandContext(ctx, "kubectl", "config", "current-context").Output(); err == nil {
+		current = strings.TrimSpace(string(output))
+	}
+	return contexts, current, nil
+}
+
+// parseKubeContexts returns the non-empty lines of kubectl config get-contexts -o name
+func parseKubeContexts(output string) []string {
+	var contexts []string
+	for _, line := range strings.Split(output, "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			contexts = append(contexts, name)
+		}
+	}
+	return contexts
+}
@@ -0,0 +1,133 @@
+//go:build windows
+
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procCreateJobObjectW         = kernel32.NewProc("CreateJobObjectW")
+	procSetInformationJobObject  = kernel32.NewProc("SetInformationJobObject")
+	procAssignProcessToJobObject = kernel32.NewProc("AssignProcessToJobObject")
+)
+
+const (
+	jobObjectExtendedLimitInformationClass = 9
+	jobObjectLimitKillOnJobClose           = 0x00002000
+	processSetQuota                        = 0x0100
+	processTerminate                       = 0x0001
+)
+
+// jobObjectBasicLimitInformation mirrors JOBOBJECT_BASIC_LIMIT_INFORMATION
+type jobObjectBasicLimitInformation struct {
+	PerProcessUserTimeLimit int64
+	PerJobUserTimeLimit     int64
+	LimitFlags              uint32
+	MinimumWorkingSetSize   uintptr
+	MaximumWorkingSetSize   uintptr
+	ActiveProcessLimit      uint32
+	Affinity                uintptr
+	PriorityClass           uint32
+	SchedulingClass         uint32
+}
+
+// jobObjectExtendedLimitInformation mirrors JOBOBJECT_EXTENDED_LIMIT_INFORMATION
+type jobObjectExtendedLimitInformation struct {
+	BasicLimitInformation jobObjectBasicLimitInformation
+	IoInfo                [6]uint64 // IO_COUNTERS
+	ProcessMemoryLimit    uintptr
+	JobMemoryLimit        uintptr
+	PeakProcessMemoryUsed uintptr
+	PeakJobMemoryUsed     uintptr
+}
+
+// ProcessJob is a Windows job object that terminates a process and every process it
+// started when closed. Unlike taskkill /T, it also reaches children whose parent has
+// already exited, such as console hosts left behind by grpcui.
+type ProcessJob struct {
+	handle    syscall.Handle
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewProcessJob puts the process with pid into a new job object. Processes it starts
+// from then on join the job too.
+func NewProcessJob(pid int) (*ProcessJob, error) {
+	handle, _, err := procCreateJobObjectW.Call(0, 0)
+	if handle == 0 {
+		return nil, fmt.Errorf("failed to create job object: %w", err)
+	}
+	job := &ProcessJob{handle: syscall.Handle(handle)}
+
+	info := jobObjectExtendedLimitInformation{}
+	info.BasicLimitInformation.LimitFlags = jobObjectLimitKillOnJobClose
+	if ok, _, err := procSetInformationJobObject.Call(
+		handle,
+		jobObjectExtendedLimitInformationClass,
+		uintptr(unsafe.Pointer(&info)),
+		unsafe.Sizeof(info),
+	); ok == 0 {
+		syscall.CloseHandle(job.handle)
+		return nil, fmt.Errorf("failed to configure job object: %w", err)
+	}
+
+	process, err := syscall.OpenProcess(processSetQuota|processTerminate, false, uint32(pid))
+	if err != nil {
+		syscall.CloseHandle(job.handle)
+		return nil, fmt.Errorf("failed to open process %d: %w", pid, err)
+	}
+	defer syscall.CloseHandle(process)
+
+	if ok, _, err := procAssignProcessToJobObject.Call(handle, uintptr(process)); ok == 0 {
+		syscall.CloseHandle(job.handle)
+		return nil, fmt.Errorf("failed to assign process %d to job object: %w", pid, err)
+	}
+	return job, nil
+}
+
+// Close terminates all processes in the job. It is safe to call more than once.
+func (j *ProcessJob) Close() error {
+	j.closeOnce.Do(func() {
+		j.closeErr = syscall.CloseHandle(j.handle)
+	})
+	return j.closeErr
+}
+
+// processJobs holds the job object of each kubectl process started by this process, by PID
+var (
+	processJobsMutex sync.Mutex
+	processJobs      = make(map[int]*ProcessJob)
+)
+
+// trackProcessJob puts the process with pid into a job object, so KillProcessGroup ends
+// it with all its children. The job handle is also closed when kportforward exits, so
+// the processes cannot outlive it even after a crash.
+func trackProcessJob(pid int) error {
+	job, err := NewProcessJob(pid)
+	if err != nil {
+		return err
+	}
+	processJobsMutex.Lock()
+	processJobs[pid] = job
+	processJobsMutex.Unlock()
+	return nil
+}
+
+// closeProcessJob terminates the job of the process with pid and forgets it. It reports
+// whether the process had a job.
+func closeProcessJob(pid int) (bool, error) {
+	processJobsMutex.Lock()
+	job, exists := processJobs[pid]
+	delete(processJobs, pid)
+	processJobsMutex.Unlock()
+
+	if !exists {
+		return false, nil
+	}
+	return true, job.Close()
+}
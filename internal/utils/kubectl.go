@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// PortPair is a local port forwarded to a port of the target
+type PortPair struct {
+	Local, Target int
+}
+
+// NoRequestTimeout disables kubectl's request timeout when used as PortForwardOptions.RequestTimeout
+const NoRequestTimeout time.Duration = -1
+
+// PortForwardOptions describes a single kubectl port-forward invocation
+type PortForwardOptions struct {
+	KubeContext    string // Passed as --context ("" = current context)
+	Namespace      string
+	Target         string
+	LocalPort      int
+	TargetPort     int
+	TargetPortName string        // Named container port of a pod, used instead of TargetPort when set
+	ExtraPorts     []PortPair    // Further local:target mappings forwarded by the same process
+	BindAddress    string        // Extra address to listen on in addition to localhost ("" = localhost only)
+	RequestTimeout time.Duration // Passed as --request-timeout (0 = 30s, NoRequestTimeout = none)
+	Streaming      string        // "websocket", "spdy" or "" for kubectl's default
+
+	// PodRunningTimeout is how long kubectl waits for a pod to be running (0 = kubectl's default)
+	PodRunningTimeout time.Duration
+
+	// OnOutput, if set, receives every line kubectl writes to stdout or stderr
+	OnOutput func(line string, isErr bool)
+}
+
+// buildPortForwardArgs returns the kubectl arguments for the given options, leaving out
+// flags the installed kubectl does not support
+func buildPortForwardArgs(opts PortForwardOptions) []string {
+	capabilities := GetKubectlCapabilities()
+
+	targetPort := fmt.Sprint(opts.TargetPort)
+	if opts.TargetPortName != "" {
+		targetPort = opts.TargetPortName
+	}
+	args := []string{
+		"port-forward",
+		"-n", opts.Namespace,
+		opts.Target,
+		fmt.Sprintf("%d:%s", opts.LocalPort, targetPort),
+	}
+	for _, pair := range opts.ExtraPorts {
+		args = append(args, fmt.Sprintf("%d:%d", pair.Local, pair.Target))
+	}
+	args = append(args, "--request-timeout="+requestTimeoutValue(opts.RequestTimeout))
+
+	if opts.KubeContext != "" {
+		args = append(args, "--context", opts.KubeContext)
+	}
+
+	if opts.PodRunningTimeout > 0 && capabilities.PodRunningTimeout {
+		args = append(args, fmt.Sprintf("--pod-running-timeout=%.0fs", opts.PodRunningTimeout.Seconds()))
+	}
+
+	if address := listenAddresses(opts.BindAddress); address != "" && capabilities.Address {
+		args = append(args, "--address", address)
+	}
+
+	return args
+}
+
+// requestTimeoutValue formats a timeout for kubectl's --request-timeout flag
+func requestTimeoutValue(timeout time.Duration) string {
+	if timeout < 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%.0fs", timeout.Seconds())
+}
+
+// portForwardEnv returns the environment for kubectl, or nil to inherit ours unchanged
+func portForwardEnv(opts PortForwardOptions) []string {
+	var websockets string
+	switch opts.Streaming {
+	case "websocket":
+		websockets = "true"
+	case "spdy":
+		websockets = "false"
+	default:
+		return nil
+	}
+	if !GetKubectlCapabilities().WebSocketStreaming {
+		return nil
+	}
+	return append(os.Environ(), "KUBECTL_PORT_FORWARD_WEBSOCKETS="+websockets)
+}
+
+// listenAddresses converts a bind address into the value for kubectl's --address flag.
+// Loopback is always kept so local health checks keep working; wildcard addresses
+// already include it and cannot be combined with an explicit loopback bind.
+func listenAddresses(bindAddress string) string {
+	if bindAddress == "" || IsLoopbackAddress(bindAddress) {
+		return ""
+	}
+
+	if ip := net.ParseIP(bindAddress); ip != nil && ip.IsUnspecified() {
+		return bindAddress
+	}
+
+	return "localhost," + bindAddress
+}
+
+// IsLoopbackAddress reports whether the address only accepts local connections
+func IsLoopbackAddress(address string) bool {
+	if address == "" || address == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsLoopback()
+}
+
+// streamKubectlOutput logs kubectl output line by line and forwards it to onLine.
+// The reader is always drained so kubectl never blocks on a full pipe.
+func streamKubectlOutput(r io.Reader, logger *Logger, serviceName string, isErr bool, onLine func(line string, isErr bool)) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if onLine != nil {
+			onLine(line, isErr)
+		}
+		if logger == nil {
+			continue
+		}
+		if isErr {
+			logger.Warn("kubectl[%s] %s", serviceName, line)
+		} else {
+			logger.Debug("kubectl[%s] %s", serviceName, line)
+		}
+	}
+	if err := scanner.Err(); err != nil && logger != nil {
+		logger.Debug("kubectl[%s] output read error: %v", serviceName, err)
+	}
+}
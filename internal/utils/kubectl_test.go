@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildPortForwardArgsBindAddress(t *testing.T) {
+	tests := []struct {
+		bindAddress string
+		expected    string // Expected --address value, "" if the flag should be absent
+	}{
+		{"", ""},
+		{"localhost", ""},
+		{"127.0.0.1", ""},
+		{"0.0.0.0", "0.0.0.0"},
+		{"192.168.1.20", "localhost,192.168.1.20"},
+	}
+
+	for _, test := range tests {
+		args := buildPortForwardArgs(PortForwardOptions{
+			Namespace:      "default",
+			Target:         "service/api",
+			LocalPort:      8080,
+			TargetPort:     80,
+			BindAddress:    test.bindAddress,
+			RequestTimeout: 30 * time.Second,
+		})
+		joined := strings.Join(args, " ")
+
+		if test.expected == "" {
+			if strings.Contains(joined, "--address") {
+				t.Errorf("bindAddress %q: expected no --address flag, got %q", test.bindAddress, joined)
+			}
+			continue
+		}
+		if !strings.HasSuffix(joined, "--address "+test.expected) {
+			t.Errorf("bindAddress %q: expected --address %s, got %q", test.bindAddress, test.expected, joined)
+		}
+	}
+}
+
+func TestBuildPortForwardArgsExtraPorts(t *testing.T) {
+	args := strings.Join(buildPortForwardArgs(PortForwardOptions{
+		Namespace:  "data",
+		Target:     "service/postgres",
+		LocalPort:  5432,
+		TargetPort: 5432,
+		ExtraPorts: []PortPair{{Local: 9187, Target: 9187}, {Local: 9188, Target: 8008}},
+	}), " ")
+	if !strings.Contains(args, "service/postgres 5432:5432 9187:9187 9188:8008 --request-timeout") {
+		t.Errorf("Expected every port mapping after the target, got %q", args)
+	}
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	for _, address := range []string{"", "localhost", "127.0.0.1", "::1"} {
+		if !IsLoopbackAddress(address) {
+			t.Errorf("Expected %q to be loopback", address)
+		}
+	}
+	for _, address := range []string{"0.0.0.0", "::", "10.0.0.5"} {
+		if IsLoopbackAddress(address) {
+			t.Errorf("Expected %q to not be loopback", address)
+		}
+	}
+}
+
+func TestBuildPortForwardArgsRequestTimeout(t *testing.T) {
+	tests := []struct {
+		timeout  time.Duration
+		expected string
+	}{
+		{30 * time.Second, "--request-timeout=30s"},
+		{5 * time.Minute, "--request-timeout=300s"},
+		{NoRequestTimeout, "--request-timeout=0"},
+	}
+
+	for _, test := range tests {
+		args := buildPortForwardArgs(PortForwardOptions{Namespace: "default", Target: "service/api", RequestTimeout: test.timeout})
+		if joined := strings.Join(args, " "); !strings.Contains(joined, test.expected) {
+			t.Errorf("timeout %v: expected %s, got %q", test.timeout, test.expected, joined)
+		}
+	}
+}
+
+func TestPortForwardEnvStreaming(t *testing.T) {
+	if env := portForwardEnv(PortForwardOptions{}); env != nil {
+		t.Error("Expected default streaming to inherit the environment unchanged")
+	}
+
+	env := portForwardEnv(PortForwardOptions{Streaming: "spdy"})
+	if len(env) == 0 || env[len(env)-1] != "KUBECTL_PORT_FORWARD_WEBSOCKETS=false" {
+		t.Errorf("Expected spdy to disable websockets, got %v", env)
+	}
+}
+
+func TestParseKubectlVersion(t *testing.T) {
+	data := `{
+  "clientVersion": {"major": "1", "minor": "26", "gitVersion": "v1.26.3"},
+  "kustomizeVersion": "v4.5.7",
+  "serverVersion": {"major": "1", "minor": "29+", "gitVersion": "v1.29.1-eks-b9c9ed7"}
+}`
+	version, err := parseKubectlVersion([]byte(data))
+	if err != nil {
+		t.Fatalf("Failed to parse version: %v", err)
+	}
+	if version.ClientMinor != 26 || version.ServerMinor != 29 {
+		t.Errorf("Expected 1.26 client and 1.29 server, got %+v", version)
+	}
+	if warnings := version.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "v1.26.3") {
+		t.Errorf("Expected a version skew warning, got %v", warnings)
+	}
+
+	capabilities := version.Capabilities()
+	if !capabilities.Address || capabilities.WebSocketStreaming {
+		t.Errorf("Unexpected capabilities for 1.26: %+v", capabilities)
+	}
+
+	if _, err := parseKubectlVersion([]byte(`{"serverVersion": {}}`)); err == nil {
+		t.Error("Expected error without client version")
+	}
+}
+
+func TestBuildPortForwardArgsOldKubectl(t *testing.T) {
+	defer SetKubectlCapabilities(GetKubectlCapabilities())
+	SetKubectlCapabilities(KubectlVersion{Client: "v1.12.0", ClientMinor: 12}.Capabilities())
+
+	args := strings.Join(buildPortForwardArgs(PortForwardOptions{
+		Namespace:         "default",
+		Target:            "service/api",
+		BindAddress:       "0.0.0.0",
+		PodRunningTimeout: time.Minute,
+	}), " ")
+
+	if strings.Contains(args, "--address") || strings.Contains(args, "--pod-running-timeout") {
+		t.Errorf("Expected unsupported flags to be left out, got %q", args)
+	}
+	if env := portForwardEnv(PortForwardOptions{Streaming: "spdy"}); env != nil {
+		t.Error("Expected streaming selection to be skipped on old kubectl")
+	}
+}
+
+func TestBuildPortForwardArgsContext(t *testing.T) {
+	args := strings.Join(buildPortForwardArgs(PortForwardOptions{Namespace: "default", Target: "service/api"}), " ")
+	if strings.Contains(args, "--context") {
+		t.Errorf("Expected no --context without a context, got %q", args)
+	}
+
+	args = strings.Join(buildPortForwardArgs(PortForwardOptions{KubeContext: "staging", Namespace: "default", Target: "service/api"}), " ")
+	if !strings.Contains(args, "--context staging") {
+		t.Errorf("Expected --context staging, got %q", args)
+	}
+}
+
+func TestBuildPortForwardArgsTargetPortName(t *testing.T) {
+	args := strings.Join(buildPortForwardArgs(PortForwardOptions{Namespace: "default", Target: "pod/api-1", LocalPort: 9090, TargetPort: 80, TargetPortName: "grpc"}), " ")
+	if !strings.Contains(args, "pod/api-1 9090:grpc") {
+		t.Errorf("Expected the named port to be forwarded, got %q", args)
+	}
+}
+
+func TestParseKubeContexts(t *testing.T) {
+	contexts := parseKubeContexts("staging\n  prod-eu \n\nkind-local\n")
+	if strings.Join(contexts, ",") != "staging,prod-eu,kind-local" {
+		t.Errorf("Expected three contexts, got %v", contexts)
+	}
+	if contexts := parseKubeContexts(""); len(contexts) != 0 {
+		t.Errorf("Expected no contexts, got %v", contexts)
+	}
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// KubectlVersion is the client and (if reachable) server version reported by kubectl
+type KubectlVersion struct {
+	Client      string
+	ClientMinor int
+	Server      string
+	ServerMinor int
+}
+
+// KubectlCapabilities are the port-forward features the installed kubectl supports
+type KubectlCapabilities struct {
+	Address            bool // --address (1.13+)
+	PodRunningTimeout  bool // --pod-running-timeout (1.13+)
+	WebSocketStreaming bool // KUBECTL_PORT_FORWARD_WEBSOCKETS (1.30+)
+}
+
+var (
+	kubectlCapabilities      = KubectlCapabilities{Address: true, PodRunningTimeout: true, WebSocketStreaming: true}
+	kubectlCapabilitiesMutex sync.RWMutex
+)
+
+// SetKubectlCapabilities sets the features used when building kubectl arguments
+func SetKubectlCapabilities(capabilities KubectlCapabilities) {
+	kubectlCapabilitiesMutex.Lock()
+	defer kubectlCapabilitiesMutex.Unlock()
+	kubectlCapabilities = capabilities
+}
+
+// GetKubectlCapabilities returns the features used when building kubectl arguments.
+// Until a version was detected every feature is assumed to be available.
+func GetKubectlCapabilities() KubectlCapabilities {
+	kubectlCapabilitiesMutex.RLock()
+	defer kubectlCapabilitiesMutex.RUnlock()
+	return kubectlCapabilities
+}
+
+// DetectKubectlVersion asks kubectl for its client and server versions. A missing
+// server version (cluster unreachable) is not an error.
+func DetectKubectlVersion(ctx context.Context) (KubectlVersion, error) {
+	// kubectl exits non-zero when the server is unreachable but still prints the client version
+	output, err := exec.CommandContext(ctx, "kubectl", "version", "-o", "json", "--request-timeout=5s").Output()
+	if len(output) == 0 {
+		return KubectlVersion{}, fmt.Errorf("failed to run kubectl version: %w", err)
+	}
+	return parseKubectlVersion(output)
+}
+
+// parseKubectlVersion parses the output of kubectl version -o json
+func parseKubectlVersion(data []byte) (KubectlVersion, error) {
+	type versionInfo struct {
+		Minor      string `json:"minor"`
+		GitVersion string `json:"gitVersion"`
+	}
+	var raw struct {
+		ClientVersion *versionInfo `json:"clientVersion"`
+		ServerVersion *versionInfo `json:"serverVersion"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return KubectlVersion{}, fmt.Errorf("failed to parse kubectl version: %w", err)
+	}
+	if raw.ClientVersion == nil {
+		return KubectlVersion{}, fmt.Errorf("kubectl version reported no client version")
+	}
+
+	version := KubectlVersion{
+		Client:      raw.ClientVersion.GitVersion,
+		ClientMinor: parseMinor(raw.ClientVersion.Minor),
+	}
+	if raw.ServerVersion != nil {
+		version.Server = raw.ServerVersion.GitVersion
+		version.ServerMinor = parseMinor(raw.ServerVersion.Minor)
+	}
+	return version, nil
+}
+
+// parseMinor parses a minor version, ignoring provider suffixes such as "28+"
+func parseMinor(minor string) int {
+	value, _ := strconv.Atoi(strings.TrimRight(minor, "+"))
+	return value
+}
+
+// Capabilities returns the port-forward features supported by the client version.
+// An unknown version keeps every feature enabled.
+func (v KubectlVersion) Capabilities() KubectlCapabilities {
+	if v.ClientMinor == 0 {
+		return KubectlCapabilities{Address: true, PodRunningTimeout: true, WebSocketStreaming: true}
+	}
+	return KubectlCapabilities{
+		Address:            v.ClientMinor >= 13,
+		PodRunningTimeout:  v.ClientMinor >= 13,
+		WebSocketStreaming: v.ClientMinor >= 30,
+	}
+}
+
+// Warnings describes known problems with the installed kubectl
+func (v KubectlVersion) Warnings() []string {
+	var warnings []string
+
+	// kubectl is supported within one minor version of the API server
+	if v.ClientMinor > 0 && v.ServerMinor > 0 {
+		skew := v.ClientMinor - v.ServerMinor
+		if skew > 1 || skew < -1 {
+			warnings = append(warnings, fmt.Sprintf(
+				"kubectl %s is more than one minor version away from the cluster (%s); port-forwards may fail - upgrade kubectl",
+				v.Client, v.Server))
+		}
+	}
+
+	if v.ClientMinor > 0 && v.ClientMinor < 13 {
+		warnings = append(warnings, fmt.Sprintf(
+			"kubectl %s does not support --address or --pod-running-timeout; bindAddress is ignored", v.Client))
+	}
+
+	return warnings
+}
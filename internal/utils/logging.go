@@ -1,19 +1,34 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"sync"
 	"time"
+
+	"github.com/victorkazakov/kportforward/internal/common"
 )
 
+// recentLogLines is how many log lines are kept in memory for diagnostic bundles
+const recentLogLines = 500
+
 // Logger represents a simple logger for the application
 type Logger struct {
 	*log.Logger
 	level   LogLevel
 	output  io.Writer
-	logFile *os.File // Keep reference to close file if needed
+	logFile io.Closer // Keep reference to close file if needed
+	parent  *Logger   // Also logs every line, see WithOutput
+	service string    // Service the lines are about, see ForService
+	format  string    // LogFormatText or LogFormatJSON; only used on the root logger
+
+	// Last lines logged at or above the level, kept even when output is discarded
+	recentMutex sync.Mutex
+	recent      []string
+	recentNext  int
 }
 
 // LogLevel represents different logging levels
@@ -26,6 +41,22 @@ const (
 	LevelError
 )
 
+// Log formats for SetFormat
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// logEntry is a log line in the JSON format
+type logEntry struct {
+	Time       string `json:"time"`
+	Level      string `json:"level"`
+	Service    string `json:"service,omitempty"`
+	Event      string `json:"event,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+	Message    string `json:"message"`
+}
+
 var logLevelNames = map[LogLevel]string{
 	LevelDebug: "DEBUG",
 	LevelInfo:  "INFO",
@@ -62,17 +93,150 @@ func NewLoggerWithFile(level LogLevel, filePath string) (*Logger, error) {
 	}, nil
 }
 
-// logf formats and logs a message at the specified level
+// NewLoggerWithRotation creates a new logger instance that writes to a file rotated
+// according to options
+func NewLoggerWithRotation(level LogLevel, filePath string, options RotationOptions) (*Logger, error) {
+	file, err := OpenRotatingFile(filePath, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Logger{
+		Logger:  log.New(file, "", 0),
+		level:   level,
+		output:  file,
+		logFile: file,
+	}, nil
+}
+
+// WithOutput returns a logger at the same level that writes to output in addition to
+// everything l writes to, e.g. a service's own log file next to the main log. Closing
+// it closes output if it is an io.Closer.
+func (l *Logger) WithOutput(output io.Writer) *Logger {
+	child := &Logger{
+		Logger:  log.New(output, "", 0),
+		level:   l.level,
+		output:  output,
+		parent:  l,
+		service: l.service,
+	}
+	if closer, ok := output.(io.Closer); ok {
+		child.logFile = closer
+	}
+	return child
+}
+
+// ForService returns a logger at the same level whose lines are about a service. It
+// writes through l and only differs in the service field of the JSON format.
+func (l *Logger) ForService(name string) *Logger {
+	return &Logger{
+		Logger:  log.New(io.Discard, "", 0),
+		level:   l.level,
+		output:  io.Discard,
+		parent:  l,
+		service: name,
+	}
+}
+
+// SetFormat selects LogFormatText or LogFormatJSON for l and the loggers derived from it
+func (l *Logger) SetFormat(format string) error {
+	switch format {
+	case "", LogFormatText:
+		l.root().format = LogFormatText
+	case LogFormatJSON:
+		l.root().format = LogFormatJSON
+	default:
+		return fmt.Errorf("unknown log format %q (expected text or json)", format)
+	}
+	return nil
+}
+
+// root returns the logger at the top of the parent chain
+func (l *Logger) root() *Logger {
+	for l.parent != nil {
+		l = l.parent
+	}
+	return l
+}
+
+// logf formats and logs a message at the specified level. The error class is that
+// of the first error among args.
 func (l *Logger) logf(level LogLevel, format string, args ...interface{}) {
+	l.logEvent(level, "", format, args...)
+}
+
+// logEvent formats and logs a message at the specified level with an event type
+func (l *Logger) logEvent(level LogLevel, event, format string, args ...interface{}) {
 	if level < l.level {
 		return
 	}
 
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	now := time.Now()
 	levelName := logLevelNames[level]
 	message := fmt.Sprintf(format, args...)
 
-	l.Printf("[%s] %s: %s", timestamp, levelName, message)
+	if l.root().format != LogFormatJSON {
+		l.write(fmt.Sprintf("[%s] %s: %s", now.Format("2006-01-02 15:04:05"), levelName, message))
+		return
+	}
+
+	entry := logEntry{
+		Time:    now.Format(time.RFC3339Nano),
+		Level:   levelName,
+		Service: l.service,
+		Event:   event,
+		Message: message,
+	}
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			entry.ErrorClass = common.KindName(err)
+			break
+		}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		data = []byte(fmt.Sprintf(`{"level":"ERROR","message":%q}`, err.Error()))
+	}
+	l.write(string(data))
+}
+
+// Event logs a message at the specified level with an event type, such as
+// "service_started", which is a field of its own in the JSON format
+func (l *Logger) Event(level LogLevel, event, format string, args ...interface{}) {
+	l.logEvent(level, event, format, args...)
+}
+
+// write outputs a formatted line here and in the parent, which remembers it
+func (l *Logger) write(line string) {
+	l.Print(line)
+	if l.parent != nil {
+		l.parent.write(line)
+		return
+	}
+	l.remember(line)
+}
+
+// remember adds a line to the in-memory ring of recent lines
+func (l *Logger) remember(line string) {
+	l.recentMutex.Lock()
+	defer l.recentMutex.Unlock()
+
+	if len(l.recent) < recentLogLines {
+		l.recent = append(l.recent, line)
+		return
+	}
+	l.recent[l.recentNext] = line
+	l.recentNext = (l.recentNext + 1) % recentLogLines
+}
+
+// RecentLines returns the most recently logged lines, oldest first
+func (l *Logger) RecentLines() []string {
+	l.recentMutex.Lock()
+	defer l.recentMutex.Unlock()
+
+	lines := make([]string, 0, len(l.recent))
+	lines = append(lines, l.recent[l.recentNext:]...)
+	return append(lines, l.recent[:l.recentNext]...)
 }
 
 // Debug logs a debug message
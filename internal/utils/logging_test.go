@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -239,3 +243,58 @@ func TestLoggerClose(t *testing.T) {
 		t.Errorf("Unexpected error closing already closed logger: %v", err)
 	}
 }
+
+func TestLoggerRecentLines(t *testing.T) {
+	logger := NewLoggerWithOutput(LevelInfo, io.Discard)
+	logger.Debug("below level")
+	for i := 0; i < recentLogLines+2; i++ {
+		logger.Info("line %d", i)
+	}
+
+	lines := logger.RecentLines()
+	if len(lines) != recentLogLines {
+		t.Fatalf("Expected %d lines, got %d", recentLogLines, len(lines))
+	}
+	if !strings.HasSuffix(lines[0], "INFO: line 2") || !strings.HasSuffix(lines[len(lines)-1], fmt.Sprintf("INFO: line %d", recentLogLines+1)) {
+		t.Errorf("Expected the oldest lines to be dropped, got %q ... %q", lines[0], lines[len(lines)-1])
+	}
+}
+
+func TestLoggerJSONFormat(t *testing.T) {
+	var output strings.Builder
+	logger := NewLoggerWithOutput(LevelInfo, &output)
+	if err := logger.SetFormat("xml"); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+	if err := logger.SetFormat(LogFormatJSON); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	service := logger.ForService("api")
+	service.Event(LevelInfo, "started", "Started port-forward for %s", "api")
+	service.Warn("Failed to start: %v", errors.New("dial tcp 10.0.0.1:443: connect: connection refused"))
+	logger.Info("Shutdown complete")
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", output.String())
+	}
+	var entries []logEntry
+	for _, line := range lines {
+		var entry logEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Expected JSON, got %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if entries[0].Service != "api" || entries[0].Event != "started" || entries[0].Level != "INFO" || entries[0].Message != "Started port-forward for api" {
+		t.Errorf("Unexpected event entry: %+v", entries[0])
+	}
+	if entries[1].ErrorClass != "network" || entries[1].Level != "WARN" {
+		t.Errorf("Expected a network error class, got %+v", entries[1])
+	}
+	if entries[2].Service != "" || entries[2].Event != "" || entries[2].ErrorClass != "" {
+		t.Errorf("Expected no extra fields on a plain line, got %+v", entries[2])
+	}
+}
@@ -0,0 +1,419 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"k8s.io/klog/v2"
+)
+
+// nativeStopTimeout limits how long Stop waits for the local ports to be released
+const nativeStopTimeout = 5 * time.Second
+
+// defaultPodRunningTimeout is how long to wait for the pod to be running, as kubectl does
+const defaultPodRunningTimeout = time.Minute
+
+// podRunningPollInterval is how often the pod is checked while waiting for it to run
+const podRunningPollInterval = time.Second
+
+// silenceKlog is done once, see StartNativePortForward
+var silenceKlog sync.Once
+
+// NativePortForward is a port-forward run in-process with client-go in place of a
+// kubectl process. Like the process, it runs from the start, while the target is
+// resolved and the tunnel opened, until the tunnel breaks or it is stopped.
+type NativePortForward struct {
+	cancel   context.CancelFunc
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// StartNativePortForward forwards the ports described by opts the way kubectl
+// port-forward does: the target is resolved to a pod, service ports are translated into
+// container ports and the tunnel is opened over SPDY once the pod is running. Output and
+// errors are passed to opts.OnOutput in kubectl's words so they are handled the same.
+// Only loading the kubeconfig fails here, the rest happens in the background.
+func StartNativePortForward(opts PortForwardOptions, logger *Logger, serviceName string) (*NativePortForward, error) {
+	silenceKlog.Do(func() {
+		// client-go logs every failed connection through klog, which writes to stderr
+		// and would draw over the TUI; a broken tunnel ends ForwardPorts instead
+		klog.LogToStderr(false)
+		klog.SetOutput(io.Discard)
+	})
+
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: opts.KubeContext},
+	)
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		if namespace, _, err = clientConfig.Namespace(); err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+		}
+	}
+
+	// The request timeout applies to looking up the target, not to the tunnel itself
+	apiConfig := rest.CopyConfig(restConfig)
+	switch {
+	case opts.RequestTimeout == 0:
+		apiConfig.Timeout = 30 * time.Second
+	case opts.RequestTimeout > 0:
+		apiConfig.Timeout = opts.RequestTimeout
+	}
+	client, err := kubernetes.NewForConfig(apiConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	forward := &NativePortForward{
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	stdoutReader, stdout := io.Pipe()
+	stderrReader, stderr := io.Pipe()
+	// The output is read to the end before done is closed, so the last error of a
+	// broken port-forward is known by the time it is noticed
+	var output sync.WaitGroup
+	output.Add(2)
+	go func() {
+		defer output.Done()
+		streamKubectlOutput(stdoutReader, logger, serviceName, false, opts.OnOutput)
+	}()
+	go func() {
+		defer output.Done()
+		streamKubectlOutput(stderrReader, logger, serviceName, true, opts.OnOutput)
+	}()
+
+	go func() {
+		defer close(forward.done)
+		err := forward.run(ctx, restConfig, client, namespace, opts, stdout, stderr)
+		select {
+		case <-forward.stop:
+			// Stopped on purpose; whatever was interrupted is no error
+		default:
+			if err != nil {
+				fmt.Fprintf(stderr, "error: %s\n", kubectlError(err))
+			}
+		}
+		stdout.Close()
+		stderr.Close()
+		output.Wait()
+	}()
+
+	return forward, nil
+}
+
+// run resolves the target and forwards its ports until the tunnel breaks or f is stopped
+func (f *NativePortForward) run(ctx context.Context, restConfig *rest.Config, client kubernetes.Interface, namespace string, opts PortForwardOptions, stdout, stderr io.Writer) error {
+	pod, ports, err := resolveNativeTarget(ctx, client, namespace, opts)
+	if err != nil {
+		return err
+	}
+	if err := waitForPodRunning(ctx, client, namespace, pod, opts.PodRunningTimeout); err != nil {
+		return err
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create SPDY transport: %w", err)
+	}
+	url := client.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(namespace).Name(pod).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	addresses := []string{"localhost"}
+	if address := listenAddresses(opts.BindAddress); address != "" {
+		addresses = strings.Split(address, ",")
+	}
+	forwarder, err := portforward.NewOnAddresses(dialer, addresses, ports, f.stop, nil, stdout, stderr)
+	if err != nil {
+		return err
+	}
+	return forwarder.ForwardPorts()
+}
+
+// Running reports whether the port-forward has not ended yet
+func (f *NativePortForward) Running() bool {
+	select {
+	case <-f.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Done is closed once the port-forward has ended and its local ports are released
+func (f *NativePortForward) Done() <-chan struct{} {
+	return f.done
+}
+
+// Stop ends the port-forward and waits for its local ports to be released
+func (f *NativePortForward) Stop() error {
+	f.stopOnce.Do(func() {
+		close(f.stop)
+		f.cancel()
+	})
+	select {
+	case <-f.done:
+		return nil
+	case <-time.After(nativeStopTimeout):
+		return fmt.Errorf("port-forward did not stop within %v", nativeStopTimeout)
+	}
+}
+
+// resolveNativeTarget picks the pod to forward to for opts.Target and returns the
+// port specs for it, with service ports translated into container ports
+func resolveNativeTarget(ctx context.Context, client kubernetes.Interface, namespace string, opts PortForwardOptions) (string, []string, error) {
+	kind, name := splitTarget(opts.Target)
+	pods := client.CoreV1().Pods(namespace)
+
+	var pod *corev1.Pod
+	var service *corev1.Service
+	var selector *metav1.LabelSelector
+	var err error
+	switch kind {
+	case "pod":
+		pod, err = pods.Get(ctx, name, metav1.GetOptions{})
+	case "service":
+		if service, err = client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			selector = &metav1.LabelSelector{MatchLabels: service.Spec.Selector}
+		}
+	default:
+		selector, err = workloadSelector(ctx, client, namespace, kind, name)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if pod == nil {
+		labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+		if selector == nil || err != nil || labelSelector.Empty() {
+			return "", nil, fmt.Errorf("%s %s has no label selector", kind, name)
+		}
+		list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: labelSelector.String()})
+		if err != nil {
+			return "", nil, err
+		}
+		if pod = bestPod(list.Items); pod == nil {
+			return "", nil, fmt.Errorf("no pods found for %s", opts.Target)
+		}
+	}
+
+	pairs := append([]PortPair{{Local: opts.LocalPort, Target: opts.TargetPort}}, opts.ExtraPorts...)
+	ports := make([]string, 0, len(pairs))
+	for i, pair := range pairs {
+		portName := ""
+		if i == 0 {
+			portName = opts.TargetPortName
+		}
+		target, err := containerPort(pod, service, pair.Target, portName)
+		if err != nil {
+			return "", nil, err
+		}
+		ports = append(ports, fmt.Sprintf("%d:%d", pair.Local, target))
+	}
+	return pod.Name, ports, nil
+}
+
+// workloadSelector returns the pod selector of a deployment, statefulset, replicaset
+// or daemonset
+func workloadSelector(ctx context.Context, client kubernetes.Interface, namespace, kind, name string) (*metav1.LabelSelector, error) {
+	apps := client.AppsV1()
+	switch kind {
+	case "deployment":
+		deployment, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return deployment.Spec.Selector, nil
+	case "statefulset":
+		statefulSet, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return statefulSet.Spec.Selector, nil
+	case "replicaset":
+		replicaSet, err := apps.ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return replicaSet.Spec.Selector, nil
+	case "daemonset":
+		daemonSet, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return daemonSet.Spec.Selector, nil
+	}
+	return nil, fmt.Errorf("cannot forward to %s/%s with the native backend (expected a pod, service or workload)", kind, name)
+}
+
+// splitTarget splits a kubectl port-forward target such as svc/api into the singular
+// resource kind and the name. A bare name is a pod, as for kubectl.
+func splitTarget(target string) (kind, name string) {
+	resource, name, found := strings.Cut(target, "/")
+	if !found {
+		return "pod", target
+	}
+	// deployment.apps/api is a deployment
+	resource, _, _ = strings.Cut(strings.ToLower(resource), ".")
+	switch resource {
+	case "po", "pod", "pods":
+		return "pod", name
+	case "svc", "service", "services":
+		return "service", name
+	case "deploy", "deployment", "deployments":
+		return "deployment", name
+	case "sts", "statefulset", "statefulsets":
+		return "statefulset", name
+	case "rs", "replicaset", "replicasets":
+		return "replicaset", name
+	case "ds", "daemonset", "daemonsets":
+		return "daemonset", name
+	}
+	return resource, name
+}
+
+// bestPod picks the pod kubectl would: not terminating, running and ready if possible,
+// by name among equals. It returns nil if all pods are terminating.
+func bestPod(pods []corev1.Pod) *corev1.Pod {
+	rank := func(pod *corev1.Pod) int {
+		switch {
+		case pod.Status.Phase != corev1.PodRunning:
+			return 0
+		case !podIsReady(pod):
+			return 1
+		}
+		return 2
+	}
+
+	candidates := make([]*corev1.Pod, 0, len(pods))
+	for i := range pods {
+		if pods[i].DeletionTimestamp == nil {
+			candidates = append(candidates, &pods[i])
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if rank(candidates[i]) != rank(candidates[j]) {
+			return rank(candidates[i]) > rank(candidates[j])
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[0]
+}
+
+// podIsReady reports whether the pod's Ready condition is true
+func podIsReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// containerPort translates port into the pod's container port. For a service it is a
+// service port whose targetPort is used, which may name a container port; portName
+// names a container port directly, as for pods tracked by ResolvePodTarget.
+func containerPort(pod *corev1.Pod, service *corev1.Service, port int, portName string) (int, error) {
+	if service != nil && portName == "" {
+		found := false
+		for _, servicePort := range service.Spec.Ports {
+			if int(servicePort.Port) != port {
+				continue
+			}
+			found = true
+			if servicePort.TargetPort.Type == intstr.String {
+				portName = servicePort.TargetPort.StrVal
+			} else if servicePort.TargetPort.IntVal != 0 {
+				port = int(servicePort.TargetPort.IntVal)
+			}
+			break
+		}
+		if !found {
+			return 0, fmt.Errorf("service %s does not have a service port %d", service.Name, port)
+		}
+	}
+	if portName == "" {
+		return port, nil
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			if containerPort.Name == portName {
+				return int(containerPort.ContainerPort), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("pod %s does not have a named port %q", pod.Name, portName)
+}
+
+// waitForPodRunning waits until the pod runs, for at most timeout (0 = a minute, as
+// kubectl waits)
+func waitForPodRunning(ctx context.Context, client kubernetes.Interface, namespace, name string, timeout time.Duration) error {
+	if timeout == 0 {
+		timeout = defaultPodRunningTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		pod, err := client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		switch pod.Status.Phase {
+		case corev1.PodRunning:
+			return nil
+		case corev1.PodSucceeded, corev1.PodFailed:
+			return fmt.Errorf("unable to forward port because pod is not running. Current status=%v", pod.Status.Phase)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for pod %s to be running. Current status=%v", name, pod.Status.Phase)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(podRunningPollInterval):
+		}
+	}
+}
+
+// kubectlError words API errors the way kubectl prints them, e.g.
+// `Error from server (NotFound): services "api" not found`, so they are classified and
+// get "did you mean" hints like kubectl's
+func kubectlError(err error) string {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		if reason := apierrors.ReasonForError(err); reason != metav1.StatusReasonUnknown {
+			return fmt.Sprintf("Error from server (%s): %v", reason, err)
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,283 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testPod returns a pod labelled app=api with a container port named http
+func testPod(name string, phase corev1.PodPhase, ready bool) *corev1.Pod {
+	condition := corev1.ConditionFalse
+	if ready {
+		condition = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "api"}},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "api",
+			Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+		}}},
+		Status: corev1.PodStatus{
+			Phase:      phase,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: condition}},
+		},
+	}
+}
+
+func TestSplitTarget(t *testing.T) {
+	tests := []struct {
+		target, kind, name string
+	}{
+		{"api-0", "pod", "api-0"},
+		{"pod/api-0", "pod", "api-0"},
+		{"svc/api", "service", "api"},
+		{"service/api", "service", "api"},
+		{"deploy/api", "deployment", "api"},
+		{"deployment.apps/api", "deployment", "api"},
+		{"sts/db", "statefulset", "db"},
+		{"job/migrate", "job", "migrate"},
+	}
+
+	for _, tt := range tests {
+		kind, name := splitTarget(tt.target)
+		if kind != tt.kind || name != tt.name {
+			t.Errorf("splitTarget(%q) = %q, %q, expected %q, %q", tt.target, kind, name, tt.kind, tt.name)
+		}
+	}
+}
+
+func TestResolveNativeTarget(t *testing.T) {
+	terminating := testPod("api-a", corev1.PodRunning, true)
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "api"},
+			Ports: []corev1.ServicePort{
+				{Port: 80, TargetPort: intstr.FromString("http")},
+				{Port: 9090, TargetPort: intstr.FromInt(9091)},
+			},
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}},
+	}
+	client := fake.NewSimpleClientset(
+		terminating,
+		testPod("api-b", corev1.PodRunning, false),
+		testPod("api-c", corev1.PodRunning, true),
+		service,
+		deployment,
+	)
+
+	tests := []struct {
+		name    string
+		opts    PortForwardOptions
+		pod     string
+		ports   []string
+		wantErr string
+	}{
+		{
+			name:  "service ports are translated",
+			opts:  PortForwardOptions{Target: "service/api", LocalPort: 8000, TargetPort: 80, ExtraPorts: []PortPair{{Local: 9000, Target: 9090}}},
+			pod:   "api-c",
+			ports: []string{"8000:8080", "9000:9091"},
+		},
+		{
+			name:  "workload ports are container ports",
+			opts:  PortForwardOptions{Target: "deploy/api", LocalPort: 8000, TargetPort: 8080},
+			pod:   "api-c",
+			ports: []string{"8000:8080"},
+		},
+		{
+			name:  "named pod port",
+			opts:  PortForwardOptions{Target: "pod/api-b", LocalPort: 8000, TargetPortName: "http"},
+			pod:   "api-b",
+			ports: []string{"8000:8080"},
+		},
+		{
+			name:    "unknown service port",
+			opts:    PortForwardOptions{Target: "svc/api", LocalPort: 8000, TargetPort: 443},
+			wantErr: "does not have a service port 443",
+		},
+		{
+			name:    "missing service",
+			opts:    PortForwardOptions{Target: "svc/web", LocalPort: 8000, TargetPort: 80},
+			wantErr: `services "web" not found`,
+		},
+		{
+			name:    "unsupported kind",
+			opts:    PortForwardOptions{Target: "job/migrate", LocalPort: 8000, TargetPort: 80},
+			wantErr: "cannot forward to job/migrate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod, ports, err := resolveNativeTarget(context.Background(), client, "default", tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if pod != tt.pod || !reflect.DeepEqual(ports, tt.ports) {
+				t.Errorf("Expected %s %v, got %s %v", tt.pod, tt.ports, pod, ports)
+			}
+		})
+	}
+}
+
+func TestWaitForPodRunning(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		testPod("running", corev1.PodRunning, true),
+		testPod("done", corev1.PodSucceeded, false),
+		testPod("pending", corev1.PodPending, false),
+	)
+	ctx := context.Background()
+
+	if err := waitForPodRunning(ctx, client, "default", "running", time.Second); err != nil {
+		t.Errorf("Expected a running pod to pass, got %v", err)
+	}
+	if err := waitForPodRunning(ctx, client, "default", "done", time.Second); err == nil || !strings.Contains(err.Error(), "pod is not running") {
+		t.Errorf("Expected a finished pod to fail, got %v", err)
+	}
+	if err := waitForPodRunning(ctx, client, "default", "pending", time.Millisecond); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected a pending pod to time out, got %v", err)
+	}
+}
+
+func TestKubectlError(t *testing.T) {
+	err := apierrors.NewNotFound(schema.GroupResource{Resource: "services"}, "stagin-api")
+	message := kubectlError(err)
+	if message != `Error from server (NotFound): services "stagin-api" not found` {
+		t.Errorf("Expected kubectl's wording, got %q", message)
+	}
+	if resource, name, ok := ParseNotFound(message); !ok || resource != "services" || name != "stagin-api" {
+		t.Errorf("Expected the error to parse as NotFound, got %q %q %v", resource, name, ok)
+	}
+}
+
+// writeKubeconfig points KUBECONFIG at a config for an API server at server
+func writeKubeconfig(t *testing.T, server string) {
+	t.Helper()
+	kubeconfig := "apiVersion: v1\nkind: Config\ncurrent-context: test\n" +
+		"clusters:\n- name: test\n  cluster:\n    server: " + server + "\n" +
+		"contexts:\n- name: test\n  context:\n    cluster: test\n    user: test\n" +
+		"users:\n- name: test\n  user: {}\n"
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(kubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", path)
+}
+
+func TestNativePortForwardReportsFailure(t *testing.T) {
+	// Nothing listens on the port once the listener is closed
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.Close()
+	writeKubeconfig(t, "http://"+listener.Addr().String())
+
+	lines := make(chan string, 10)
+	forward, err := StartNativePortForward(PortForwardOptions{
+		Namespace:      "default",
+		Target:         "svc/api",
+		LocalPort:      8000,
+		TargetPort:     80,
+		RequestTimeout: time.Second,
+		OnOutput: func(line string, isErr bool) {
+			if isErr {
+				lines <- line
+			}
+		},
+	}, nil, "api")
+	if err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+
+	select {
+	case <-forward.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the port-forward to end when the API server cannot be reached")
+	}
+	if forward.Running() {
+		t.Error("Expected the port-forward to no longer be running")
+	}
+	// The output is read to the end by the time Done is closed
+	select {
+	case line := <-lines:
+		if !strings.HasPrefix(line, "error: ") {
+			t.Errorf("Expected an error line like kubectl's, got %q", line)
+		}
+	default:
+		t.Error("Expected the failure on stderr before the port-forward ended")
+	}
+}
+
+func TestNativePortForwardStop(t *testing.T) {
+	// An API server that never answers keeps the port-forward resolving its target
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	writeKubeconfig(t, "http://"+listener.Addr().String())
+
+	lines := make(chan string, 10)
+	forward, err := StartNativePortForward(PortForwardOptions{
+		Namespace:      "default",
+		Target:         "svc/api",
+		LocalPort:      8000,
+		TargetPort:     80,
+		RequestTimeout: NoRequestTimeout,
+		OnOutput:       func(line string, isErr bool) { lines <- line },
+	}, nil, "api")
+	if err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	if !forward.Running() {
+		t.Fatal("Expected the port-forward to run while resolving its target")
+	}
+
+	if err := forward.Stop(); err != nil {
+		t.Fatalf("Failed to stop: %v", err)
+	}
+	if forward.Running() {
+		t.Error("Expected the port-forward to have ended")
+	}
+	select {
+	case line := <-lines:
+		t.Errorf("Expected no output when stopped, got %q", line)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
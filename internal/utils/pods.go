@@ -0,0 +1,316 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// PodReadiness is the number of ready containers in the pod a target resolves to
+type PodReadiness struct {
+	Pod   string
+	Ready int
+	Total int
+}
+
+// IsReady reports whether every container of the pod is ready
+func (r PodReadiness) IsReady() bool {
+	return r.Total > 0 && r.Ready == r.Total
+}
+
+// String formats the readiness like kubectl's READY column
+func (r PodReadiness) String() string {
+	return fmt.Sprintf("%d/%d", r.Ready, r.Total)
+}
+
+// PodTarget is a single pod to forward to in place of a service or workload target
+type PodTarget struct {
+	Pod      string
+	Port     int    // Container port, if numeric
+	PortName string // Named container port a service's targetPort refers to
+}
+
+// Target returns the kubectl port-forward target of the pod
+func (p PodTarget) Target() string {
+	return "pod/" + p.Pod
+}
+
+// kubeObject holds the fields needed to resolve a port-forward target to its pods
+type kubeObject struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Selector json.RawMessage `json:"selector"`
+		Ports    []struct {
+			Name       string          `json:"name"`
+			Protocol   string          `json:"protocol"`
+			Port       int             `json:"port"`
+			TargetPort json.RawMessage `json:"targetPort"`
+		} `json:"ports"`
+	} `json:"spec"`
+	Metadata struct {
+		Name              string            `json:"name"`
+		Namespace         string            `json:"namespace"`
+		Annotations       map[string]string `json:"annotations"`
+		DeletionTimestamp string            `json:"deletionTimestamp"`
+	} `json:"metadata"`
+	Status struct {
+		ContainerStatuses []struct {
+			Ready bool `json:"ready"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+	Endpoints []struct {
+		Conditions struct {
+			Ready *bool `json:"ready"`
+		} `json:"conditions"`
+		TargetRef struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"targetRef"`
+	} `json:"endpoints"` // Of an EndpointSlice
+	Items []kubeObject `json:"items"`
+}
+
+// GetPodReadiness returns the readiness of the pod kubectl would forward to for target
+// (pod/x, service/x, deployment/x, ...). For targets backed by several pods the most
+// ready one is reported, since kubectl port-forward picks a running pod.
+func GetPodReadiness(ctx context.Context, kubeContext, namespace, target string) (PodReadiness, error) {
+	object, err := kubectlGetJSON(ctx, kubeContext, namespace, target)
+	if err != nil {
+		return PodReadiness{}, err
+	}
+
+	if object.Kind == "Pod" {
+		return podReadiness(object), nil
+	}
+
+	selector, err := labelSelector(object)
+	if err != nil {
+		return PodReadiness{}, fmt.Errorf("failed to resolve pods for %s: %w", target, err)
+	}
+
+	pods, err := kubectlGetJSON(ctx, kubeContext, namespace, "pods", "-l", selector)
+	if err != nil {
+		return PodReadiness{}, err
+	}
+	return bestPodReadiness(pods.Items), nil
+}
+
+// ResolvePodTarget picks a ready pod that is not terminating behind target and
+// translates port into its container port, like kubectl port-forward does for services
+// and workloads. Pod targets resolve to themselves.
+func ResolvePodTarget(ctx context.Context, kubeContext, namespace, target string, port int) (PodTarget, error) {
+	object, err := kubectlGetJSON(ctx, kubeContext, namespace, target)
+	if err != nil {
+		return PodTarget{}, err
+	}
+	if object.Kind == "Pod" {
+		return PodTarget{Pod: object.Metadata.Name, Port: port}, nil
+	}
+
+	podTarget := PodTarget{Port: port}
+	if object.Kind == "Service" {
+		if podTarget, err = servicePodPort(object, port); err != nil {
+			return PodTarget{}, err
+		}
+	}
+
+	selector, err := labelSelector(object)
+	if err != nil {
+		return PodTarget{}, fmt.Errorf("failed to resolve pods for %s: %w", target, err)
+	}
+	pods, err := kubectlGetJSON(ctx, kubeContext, namespace, "pods", "-l", selector)
+	if err != nil {
+		return PodTarget{}, err
+	}
+	podTarget.Pod = servingPod(pods.Items)
+	if podTarget.Pod == "" {
+		return PodTarget{}, fmt.Errorf("no ready pod for %s", target)
+	}
+	return podTarget, nil
+}
+
+// CheckPodServing returns why a pod can no longer serve a port-forward: it is gone,
+// terminating or not ready. It returns "" while the pod is fine.
+func CheckPodServing(ctx context.Context, kubeContext, namespace, pod string) (string, error) {
+	object, err := kubectlGetJSON(ctx, kubeContext, namespace, "pod/"+pod)
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFound") {
+			return "deleted", nil
+		}
+		return "", err
+	}
+	if object.Metadata.DeletionTimestamp != "" {
+		return "terminating", nil
+	}
+	if readiness := podReadiness(object); !readiness.IsReady() {
+		return fmt.Sprintf("not ready (%s)", readiness), nil
+	}
+	return "", nil
+}
+
+// GetEndpointPods returns the ready pods behind a service, sorted by name, as listed in
+// its EndpointSlices. The list changes as soon as a rollout takes a pod out of service.
+func GetEndpointPods(ctx context.Context, kubeContext, namespace, service string) ([]string, error) {
+	slices, err := kubectlGetJSON(ctx, kubeContext, namespace, "endpointslices", "-l", "kubernetes.io/service-name="+service)
+	if err != nil {
+		return nil, err
+	}
+	return endpointPods(slices.Items), nil
+}
+
+// ListServingPods returns the pods in namespace matching selector that are ready and
+// not terminating, sorted by name
+func ListServingPods(ctx context.Context, kubeContext, namespace, selector string) ([]string, error) {
+	pods, err := kubectlGetJSON(ctx, kubeContext, namespace, "pods", "-l", selector)
+	if err != nil {
+		return nil, err
+	}
+	return servingPods(pods.Items), nil
+}
+
+// endpointPods returns the ready pods of EndpointSlices, sorted by name without duplicates
+func endpointPods(slices []kubeObject) []string {
+	seen := make(map[string]bool)
+	var pods []string
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			// A missing ready condition means ready
+			ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+			name := endpoint.TargetRef.Name
+			if !ready || endpoint.TargetRef.Kind != "Pod" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			pods = append(pods, name)
+		}
+	}
+	sort.Strings(pods)
+	return pods
+}
+
+// servicePodPort translates a service port into the container port of its pods
+func servicePodPort(service kubeObject, port int) (PodTarget, error) {
+	for _, servicePort := range service.Spec.Ports {
+		if servicePort.Port != port {
+			continue
+		}
+		if len(servicePort.TargetPort) == 0 {
+			return PodTarget{Port: port}, nil
+		}
+		var number int
+		if err := json.Unmarshal(servicePort.TargetPort, &number); err == nil {
+			return PodTarget{Port: number}, nil
+		}
+		var name string
+		if err := json.Unmarshal(servicePort.TargetPort, &name); err != nil {
+			return PodTarget{}, fmt.Errorf("invalid targetPort of service %s: %s", service.Metadata.Name, servicePort.TargetPort)
+		}
+		return PodTarget{PortName: name}, nil
+	}
+	return PodTarget{}, fmt.Errorf("service %s has no port %d", service.Metadata.Name, port)
+}
+
+// servingPod returns the first pod by name that is ready and not terminating, or ""
+func servingPod(pods []kubeObject) string {
+	names := servingPods(pods)
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+// servingPods returns the pods that are ready and not terminating, sorted by name
+func servingPods(pods []kubeObject) []string {
+	var names []string
+	for _, pod := range pods {
+		if pod.Metadata.DeletionTimestamp == "" && podReadiness(pod).IsReady() {
+			names = append(names, pod.Metadata.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// kubectlGetJSON runs kubectl get with JSON output and decodes the result. namespace is
+// "" for cluster-scoped objects.
+func kubectlGetJSON(ctx context.Context, kubeContext, namespace string, args ...string) (kubeObject, error) {
+	cmdArgs := []string{"get", "-o", "json", "--request-timeout=5s"}
+	if namespace != "" {
+		cmdArgs = append(cmdArgs, "-n", namespace)
+	}
+	if kubeContext != "" {
+		cmdArgs = append(cmdArgs, "--context", kubeContext)
+	}
+	cmdArgs = append(cmdArgs, args...)
+	output, err := exec.CommandContext(ctx, "kubectl", cmdArgs...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return kubeObject{}, fmt.Errorf("kubectl get %s failed: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return kubeObject{}, fmt.Errorf("kubectl get %s failed: %w", strings.Join(args, " "), err)
+	}
+
+	var object kubeObject
+	if err := json.Unmarshal(output, &object); err != nil {
+		return kubeObject{}, fmt.Errorf("failed to parse kubectl output: %w", err)
+	}
+	return object, nil
+}
+
+// labelSelector builds a -l selector from a service selector or a workload's matchLabels
+func labelSelector(object kubeObject) (string, error) {
+	var labels map[string]string
+	if object.Kind == "Service" {
+		if err := json.Unmarshal(object.Spec.Selector, &labels); err != nil {
+			return "", fmt.Errorf("invalid service selector: %w", err)
+		}
+	} else {
+		var selector struct {
+			MatchLabels map[string]string `json:"matchLabels"`
+		}
+		if err := json.Unmarshal(object.Spec.Selector, &selector); err != nil {
+			return "", fmt.Errorf("invalid %s selector: %w", strings.ToLower(object.Kind), err)
+		}
+		labels = selector.MatchLabels
+	}
+
+	if len(labels) == 0 {
+		return "", fmt.Errorf("%s %s has no label selector", strings.ToLower(object.Kind), object.Metadata.Name)
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ","), nil
+}
+
+// podReadiness counts the ready containers of a single pod
+func podReadiness(pod kubeObject) PodReadiness {
+	readiness := PodReadiness{Pod: pod.Metadata.Name, Total: len(pod.Status.ContainerStatuses)}
+	for _, container := range pod.Status.ContainerStatuses {
+		if container.Ready {
+			readiness.Ready++
+		}
+	}
+	return readiness
+}
+
+// bestPodReadiness returns the first fully ready pod, or the one closest to ready
+func bestPodReadiness(pods []kubeObject) PodReadiness {
+	var best PodReadiness
+	for _, pod := range pods {
+		readiness := podReadiness(pod)
+		if readiness.IsReady() {
+			return readiness
+		}
+		if best.Pod == "" || readiness.Ready > best.Ready {
+			best = readiness
+		}
+	}
+	return best
+}
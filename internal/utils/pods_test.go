@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLabelSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		object   string
+		expected string
+	}{
+		{"service", `{"kind":"Service","spec":{"selector":{"app":"api","tier":"backend"}}}`, "app=api,tier=backend"},
+		{"deployment", `{"kind":"Deployment","spec":{"selector":{"matchLabels":{"app":"api"}}}}`, "app=api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var object kubeObject
+			if err := json.Unmarshal([]byte(tt.object), &object); err != nil {
+				t.Fatalf("Failed to parse fixture: %v", err)
+			}
+			selector, err := labelSelector(object)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if selector != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, selector)
+			}
+		})
+	}
+
+	if _, err := labelSelector(kubeObject{Kind: "Service"}); err == nil {
+		t.Error("Expected error for service without selector")
+	}
+}
+
+func TestBestPodReadiness(t *testing.T) {
+	var list kubeObject
+	data := `{"items":[
+		{"metadata":{"name":"api-1"},"status":{"containerStatuses":[{"ready":false},{"ready":false}]}},
+		{"metadata":{"name":"api-2"},"status":{"containerStatuses":[{"ready":true},{"ready":false}]}}
+	]}`
+	if err := json.Unmarshal([]byte(data), &list); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	readiness := bestPodReadiness(list.Items)
+	if readiness.Pod != "api-2" || readiness.String() != "1/2" || readiness.IsReady() {
+		t.Errorf("Expected api-2 at 1/2 and not ready, got %s at %s", readiness.Pod, readiness)
+	}
+
+	if (PodReadiness{}).IsReady() {
+		t.Error("Expected no pods to never be ready")
+	}
+}
+
+func TestServicePodPort(t *testing.T) {
+	var service kubeObject
+	data := `{"kind":"Service","metadata":{"name":"api"},"spec":{"ports":[
+		{"port":80,"targetPort":8080},
+		{"port":9090,"targetPort":"grpc"},
+		{"port":5432}
+	]}}`
+	if err := json.Unmarshal([]byte(data), &service); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	tests := []struct {
+		port     int
+		expected PodTarget
+	}{
+		{80, PodTarget{Port: 8080}},
+		{9090, PodTarget{PortName: "grpc"}},
+		{5432, PodTarget{Port: 5432}},
+	}
+	for _, tt := range tests {
+		target, err := servicePodPort(service, tt.port)
+		if err != nil {
+			t.Fatalf("Unexpected error for port %d: %v", tt.port, err)
+		}
+		if target != tt.expected {
+			t.Errorf("Expected %+v for port %d, got %+v", tt.expected, tt.port, target)
+		}
+	}
+
+	if _, err := servicePodPort(service, 443); err == nil {
+		t.Error("Expected error for a port the service does not have")
+	}
+}
+
+func TestServingPod(t *testing.T) {
+	var list kubeObject
+	data := `{"items":[
+		{"metadata":{"name":"api-3"},"status":{"containerStatuses":[{"ready":true}]}},
+		{"metadata":{"name":"api-1","deletionTimestamp":"2024-01-01T00:00:00Z"},"status":{"containerStatuses":[{"ready":true}]}},
+		{"metadata":{"name":"api-2"},"status":{"containerStatuses":[{"ready":false}]}},
+		{"metadata":{"name":"api-4"},"status":{"containerStatuses":[{"ready":true}]}}
+	]}`
+	if err := json.Unmarshal([]byte(data), &list); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	if pod := servingPod(list.Items); pod != "api-3" {
+		t.Errorf("Expected the first ready pod that is not terminating, got %q", pod)
+	}
+	if pod := servingPod(list.Items[1:3]); pod != "" {
+		t.Errorf("Expected no pod, got %q", pod)
+	}
+}
+
+func TestEndpointPods(t *testing.T) {
+	var list kubeObject
+	data := `{"items":[
+		{"endpoints":[
+			{"conditions":{"ready":true},"targetRef":{"kind":"Pod","name":"api-2"}},
+			{"conditions":{"ready":false},"targetRef":{"kind":"Pod","name":"api-1"}}
+		]},
+		{"endpoints":[
+			{"targetRef":{"kind":"Pod","name":"api-3"}},
+			{"conditions":{"ready":true},"targetRef":{"kind":"Pod","name":"api-2"}},
+			{"conditions":{"ready":true}}
+		]}
+	]}`
+	if err := json.Unmarshal([]byte(data), &list); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	if pods := endpointPods(list.Items); !reflect.DeepEqual(pods, []string{"api-2", "api-3"}) {
+		t.Errorf("Expected the ready pods without duplicates, got %v", pods)
+	}
+}
@@ -154,3 +154,11 @@ func ReleasePort(port int) {
 	defer portMutex.Unlock()
 	delete(allocatedPorts, port)
 }
+
+// String names a process as "command (pid N)", or "pid N" if its command is unknown
+func (p *ProcessInfo) String() string {
+	if p.Command == "" {
+		return fmt.Sprintf("pid %d", p.PID)
+	}
+	return fmt.Sprintf("%s (pid %d)", p.Command, p.PID)
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// kafkaProbeCorrelationID identifies the ApiVersions request sent by ProbeProtocol
+const kafkaProbeCorrelationID = 0x6b7066 // "kpf"
+
+// ProbeProtocol connects to the local port and performs a minimal handshake of protocol
+// (postgres, redis or kafka) within timeout. kubectl accepts connections on the local
+// port even when the pod behind it is unreachable, so only an answer from the server
+// proves the forward works. Any other protocol only checks that the port accepts
+// connections.
+func ProbeProtocol(port int, protocol string, timeout time.Duration) error {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	switch protocol {
+	case "postgres":
+		return probePostgres(conn)
+	case "redis":
+		return probeRedis(conn)
+	case "kafka":
+		return probeKafka(conn)
+	default:
+		return nil
+	}
+}
+
+// probePostgres sends an SSLRequest, which the server answers with S or N before any
+// authentication, so it works without credentials and leaves no failed login behind
+func probePostgres(conn net.Conn) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], 80877103)
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("postgres probe failed: %w", err)
+	}
+
+	answer := make([]byte, 1)
+	if _, err := io.ReadFull(conn, answer); err != nil {
+		return fmt.Errorf("postgres probe got no answer: %w", err)
+	}
+	if answer[0] != 'S' && answer[0] != 'N' {
+		return fmt.Errorf("postgres probe got unexpected answer %q", answer[0])
+	}
+	return nil
+}
+
+// probeRedis sends PING. An error reply, such as NOAUTH, also comes from the server.
+func probeRedis(conn net.Conn) error {
+	if _, err := conn.Write([]byte("PING\r\n")); err != nil {
+		return fmt.Errorf("redis probe failed: %w", err)
+	}
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("redis probe got no answer: %w", err)
+	}
+	if line[0] != '+' && line[0] != '-' {
+		return fmt.Errorf("redis probe got unexpected answer %q", line)
+	}
+	return nil
+}
+
+// probeKafka sends an ApiVersions v0 request, which brokers answer without authentication,
+// and checks that the answer carries the request's correlation ID
+func probeKafka(conn net.Conn) error {
+	clientID := "kportforward"
+	request := make([]byte, 4+2+2+4+2+len(clientID))
+	binary.BigEndian.PutUint32(request[0:4], uint32(len(request)-4))
+	binary.BigEndian.PutUint16(request[4:6], 18) // ApiVersions
+	binary.BigEndian.PutUint16(request[6:8], 0)
+	binary.BigEndian.PutUint32(request[8:12], kafkaProbeCorrelationID)
+	binary.BigEndian.PutUint16(request[12:14], uint16(len(clientID)))
+	copy(request[14:], clientID)
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("kafka probe failed: %w", err)
+	}
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf("kafka probe got no answer: %w", err)
+	}
+	if correlationID := binary.BigEndian.Uint32(header[4:8]); correlationID != kafkaProbeCorrelationID {
+		return fmt.Errorf("kafka probe got an answer for correlation ID %d", correlationID)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// serveOnce accepts connections on a local port and answers each with respond
+func serveOnce(t *testing.T, respond func(conn net.Conn)) int {
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			respond(conn)
+			conn.Close()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestProbeProtocol(t *testing.T) {
+	tests := []struct {
+		protocol string
+		request  int // Bytes read before answering
+		answer   []byte
+		healthy  bool
+	}{
+		{"postgres", 8, []byte("N"), true},
+		{"postgres", 8, []byte("HTTP/1.1 400"), false},
+		{"redis", 6, []byte("+PONG\r\n"), true},
+		{"redis", 6, []byte("-NOAUTH Authentication required.\r\n"), true},
+		{"kafka", 26, binary.BigEndian.AppendUint32([]byte{0, 0, 0, 10}, kafkaProbeCorrelationID), true},
+		{"kafka", 26, []byte{0, 0, 0, 10, 0, 0, 0, 1}, false},
+		{"tcp", 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		port := serveOnce(t, func(conn net.Conn) {
+			io.ReadFull(conn, make([]byte, tt.request))
+			conn.Write(tt.answer)
+		})
+		if err := ProbeProtocol(port, tt.protocol, time.Second); (err == nil) != tt.healthy {
+			t.Errorf("%s answering %q: expected healthy=%v, got %v", tt.protocol, tt.answer, tt.healthy, err)
+		}
+	}
+
+	// kubectl accepts the connection even when the pod is unreachable, then closes it
+	port := serveOnce(t, func(conn net.Conn) {})
+	if err := ProbeProtocol(port, "redis", time.Second); err == nil {
+		t.Error("Expected a closed connection to fail the redis probe")
+	}
+}
@@ -3,9 +3,7 @@
 package utils
 
 import (
-	"bufio"
 	"fmt"
-	"io"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -20,15 +18,24 @@ func StartKubectlPortForward(namespace, target string, localPort, targetPort int
 
 // StartKubectlPortForwardWithTimeout starts a kubectl port-forward process with a timeout
 func StartKubectlPortForwardWithTimeout(namespace, target string, localPort, targetPort int, timeout time.Duration, logger *Logger, serviceName string) (*exec.Cmd, error) {
-	args := []string{
-		"port-forward",
-		"-n", namespace,
-		target,
-		fmt.Sprintf("%d:%d", localPort, targetPort),
-		"--request-timeout=" + fmt.Sprintf("%.0fs", timeout.Seconds()),
+	return StartKubectlPortForwardWithOptions(PortForwardOptions{
+		Namespace:      namespace,
+		Target:         target,
+		LocalPort:      localPort,
+		TargetPort:     targetPort,
+		RequestTimeout: timeout,
+	}, logger, serviceName)
+}
+
+// StartKubectlPortForwardWithOptions starts a kubectl port-forward process described by opts
+func StartKubectlPortForwardWithOptions(opts PortForwardOptions, logger *Logger, serviceName string) (*exec.Cmd, error) {
+	if opts.RequestTimeout == 0 {
+		opts.RequestTimeout = 30 * time.Second
 	}
+	args := buildPortForwardArgs(opts)
 
 	cmd := exec.Command("kubectl", args...)
+	cmd.Env = portForwardEnv(opts)
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
 	stdout, err := cmd.StdoutPipe()
@@ -44,8 +51,8 @@ func StartKubectlPortForwardWithTimeout(namespace, target string, localPort, tar
 		return nil, fmt.Errorf("failed to start kubectl port-forward: %w", err)
 	}
 
-	go streamKubectlOutput(stdout, logger, serviceName, false)
-	go streamKubectlOutput(stderr, logger, serviceName, true)
+	go streamKubectlOutput(stdout, logger, serviceName, false, opts.OnOutput)
+	go streamKubectlOutput(stderr, logger, serviceName, true, opts.OnOutput)
 
 	go func() {
 		err := cmd.Wait()
@@ -57,6 +64,34 @@ func StartKubectlPortForwardWithTimeout(namespace, target string, localPort, tar
 	return cmd, nil
 }
 
+// DetachProcess makes cmd run in its own session so it outlives the terminal that started it
+func DetachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// FindProcessOnPort returns the process listening on the given TCP port, found with lsof
+func FindProcessOnPort(port int) (*ProcessInfo, error) {
+	output, err := exec.Command("lsof", "-nP", fmt.Sprintf("-iTCP:%d", port), "-sTCP:LISTEN", "-Fpc").Output()
+	if err != nil || len(output) == 0 {
+		return nil, fmt.Errorf("no process found listening on port %d", port)
+	}
+
+	// -F prints one field per line: p<pid>, then c<command>
+	var info ProcessInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		switch {
+		case strings.HasPrefix(line, "p") && info.PID == 0:
+			info.PID, _ = strconv.Atoi(line[1:])
+		case strings.HasPrefix(line, "c") && info.Command == "":
+			info.Command = line[1:]
+		}
+	}
+	if info.PID <= 0 {
+		return nil, fmt.Errorf("no process found listening on port %d", port)
+	}
+	return &info, nil
+}
+
 // KillProcessOnPort finds and kills any process listening on the given TCP port.
 // This is used to clean up zombie kubectl processes that survived a previous shutdown.
 func KillProcessOnPort(port int) error {
@@ -82,21 +117,3 @@ func KillProcessOnPort(port int) error {
 	}
 	return nil
 }
-
-func streamKubectlOutput(r io.Reader, logger *Logger, serviceName string, isErr bool) {
-	if logger == nil {
-		return
-	}
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if isErr {
-			logger.Warn("kubectl[%s] %s", serviceName, line)
-		} else {
-			logger.Debug("kubectl[%s] %s", serviceName, line)
-		}
-	}
-	if err := scanner.Err(); err != nil {
-		logger.Debug("kubectl[%s] output read error: %v", serviceName, err)
-	}
-}
@@ -3,13 +3,12 @@
 package utils
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
-	"io"
 	"os/exec"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -27,15 +26,24 @@ func StartKubectlPortForward(namespace, target string, localPort, targetPort int
 
 // StartKubectlPortForwardWithTimeout starts a kubectl port-forward process with a timeout on Windows
 func StartKubectlPortForwardWithTimeout(namespace, target string, localPort, targetPort int, timeout time.Duration, logger *Logger, serviceName string) (*exec.Cmd, error) {
-	args := []string{
-		"port-forward",
-		"-n", namespace,
-		target,
-		fmt.Sprintf("%d:%d", localPort, targetPort),
-		"--request-timeout=" + fmt.Sprintf("%.0fs", timeout.Seconds()),
+	return StartKubectlPortForwardWithOptions(PortForwardOptions{
+		Namespace:      namespace,
+		Target:         target,
+		LocalPort:      localPort,
+		TargetPort:     targetPort,
+		RequestTimeout: timeout,
+	}, logger, serviceName)
+}
+
+// StartKubectlPortForwardWithOptions starts a kubectl port-forward process described by opts on Windows
+func StartKubectlPortForwardWithOptions(opts PortForwardOptions, logger *Logger, serviceName string) (*exec.Cmd, error) {
+	if opts.RequestTimeout == 0 {
+		opts.RequestTimeout = 30 * time.Second
 	}
+	args := buildPortForwardArgs(opts)
 
 	cmd := exec.Command("kubectl", args...)
+	cmd.Env = portForwardEnv(opts)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -50,19 +58,35 @@ func StartKubectlPortForwardWithTimeout(namespace, target string, localPort, tar
 		return nil, fmt.Errorf("failed to start kubectl port-forward: %w", err)
 	}
 
-	go streamKubectlOutput(stdout, logger, serviceName, false)
-	go streamKubectlOutput(stderr, logger, serviceName, true)
+	// Without a job object, stopping falls back to taskkill /T, which misses children
+	// whose parent has already exited
+	if err := trackProcessJob(cmd.Process.Pid); err != nil && logger != nil {
+		logger.Debug("Stopping kubectl for %s (PID %d) will fall back to taskkill: %v", serviceName, cmd.Process.Pid, err)
+	}
+
+	go streamKubectlOutput(stdout, logger, serviceName, false, opts.OnOutput)
+	go streamKubectlOutput(stderr, logger, serviceName, true, opts.OnOutput)
 
 	go func() {
 		err := cmd.Wait()
 		if err != nil && logger != nil {
 			logger.Debug("kubectl port-forward exited for %s: %v", serviceName, err)
 		}
+		// End anything kubectl left behind and release the job
+		closeProcessJob(cmd.Process.Pid)
 	}()
 
 	return cmd, nil
 }
 
+// detachedProcess is the DETACHED_PROCESS creation flag: no console is inherited
+const detachedProcess = 0x00000008
+
+// DetachProcess makes cmd run without a console so it outlives the terminal that started it
+func DetachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: detachedProcess | syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
 // IsProcessRunning checks if a process is running on Windows
 func IsProcessRunning(pid int) bool {
 	if pid <= 0 {
@@ -113,12 +137,16 @@ func KillProcess(pid int) error {
 	return runTaskkill("/F", "/PID", strconv.Itoa(pid))
 }
 
-// KillProcessGroup terminates a process tree
+// KillProcessGroup terminates a process tree. Processes started by kportforward are
+// ended through their job object; others with taskkill /T.
 func KillProcessGroup(pid int) error {
 	if pid <= 0 {
 		return fmt.Errorf("invalid PID: %d", pid)
 	}
 
+	if tracked, err := closeProcessJob(pid); tracked {
+		return err
+	}
 	return runTaskkill("/F", "/T", "/PID", strconv.Itoa(pid))
 }
 
@@ -135,6 +163,37 @@ func GetProcessInfo(pid int) (*ProcessInfo, error) {
 	}, nil
 }
 
+// FindProcessOnPort returns the process listening on the given TCP port, found with
+// netstat and tasklist
+func FindProcessOnPort(port int) (*ProcessInfo, error) {
+	cmd := exec.Command("cmd", "/C", fmt.Sprintf("netstat -ano | findstr \"LISTENING\" | findstr \":%d \"", port))
+	output, err := cmd.Output()
+	if err != nil || len(output) == 0 {
+		return nil, fmt.Errorf("no process found listening on port %d", port)
+	}
+
+	for _, line := range splitLines(string(output)) {
+		fields := splitFields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil || pid <= 0 {
+			continue
+		}
+
+		// tasklist prints "image.exe","pid",... for the process
+		info := &ProcessInfo{PID: pid}
+		if output, err := exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH").Output(); err == nil {
+			if name, _, found := strings.Cut(strings.TrimSpace(string(output)), ","); found {
+				info.Command = strings.Trim(name, "\"")
+			}
+		}
+		return info, nil
+	}
+	return nil, fmt.Errorf("no process found listening on port %d", port)
+}
+
 // KillProcessOnPort finds and kills any process listening on the given TCP port.
 // This is used to clean up zombie kubectl processes that survived a previous shutdown.
 func KillProcessOnPort(port int) error {
@@ -191,21 +250,3 @@ func splitFields(s string) []string {
 	}
 	return fields
 }
-
-func streamKubectlOutput(r io.Reader, logger *Logger, serviceName string, isErr bool) {
-	if logger == nil {
-		return
-	}
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if isErr {
-			logger.Warn("kubectl[%s] %s", serviceName, line)
-		} else {
-			logger.Debug("kubectl[%s] %s", serviceName, line)
-		}
-	}
-	if err := scanner.Err(); err != nil {
-		logger.Debug("kubectl[%s] output read error: %v", serviceName, err)
-	}
-}
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults for RotationOptions
+const (
+	DefaultLogMaxSize    = 10 << 20 // 10 MB
+	DefaultLogMaxBackups = 5
+)
+
+// RotationOptions limits the size and age of a rotating log file
+type RotationOptions struct {
+	MaxSize    int64         // Rotate before the file grows beyond this many bytes (0 = DefaultLogMaxSize)
+	MaxAge     time.Duration // Rotate once the file has been written to for this long (0 = never)
+	MaxBackups int           // Rotated copies to keep (0 = DefaultLogMaxBackups)
+}
+
+// RotatingFile is a log file that is renamed to <path>.<time> and started over once it
+// gets too large or too old. Only the newest MaxBackups rotated copies are kept.
+type RotatingFile struct {
+	path    string
+	options RotationOptions
+
+	mutex  sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// OpenRotatingFile opens path for appending, creating it and its directory if needed
+func OpenRotatingFile(path string, options RotationOptions) (*RotatingFile, error) {
+	if options.MaxSize <= 0 {
+		options.MaxSize = DefaultLogMaxSize
+	}
+	if options.MaxBackups <= 0 {
+		options.MaxBackups = DefaultLogMaxBackups
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	f := &RotatingFile{path: path, options: options}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Path returns the path of the current log file
+func (f *RotatingFile) Path() string {
+	return f.path
+}
+
+// Write appends p, rotating first if it would exceed the size limit or the file is too old
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	tooLarge := f.size > 0 && f.size+int64(len(p)) > f.options.MaxSize
+	tooOld := f.options.MaxAge > 0 && time.Since(f.opened) >= f.options.MaxAge
+	if tooLarge || tooOld {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current log file
+func (f *RotatingFile) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the log file for appending and records its size
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %s: %w", f.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file %s: %w", f.path, err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.opened = time.Now()
+	return nil
+}
+
+// rotate renames the log file aside, starts a new one and removes surplus copies
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file %s: %w", f.path, err)
+	}
+	f.file = nil
+
+	// Millisecond timestamps sort in rotation order and rarely collide
+	rotated := f.path + "." + time.Now().Format("20060102-150405.000")
+	if err := os.Rename(f.path, rotated); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file %s: %w", f.path, err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.removeSurplusBackups()
+	return nil
+}
+
+// removeSurplusBackups removes all but the newest MaxBackups rotated copies
+func (f *RotatingFile) removeSurplusBackups() {
+	backups, err := filepath.Glob(f.path + ".*")
+	if err != nil || len(backups) <= f.options.MaxBackups {
+		return
+	}
+	sort.Strings(backups)
+	for _, backup := range backups[:len(backups)-f.options.MaxBackups] {
+		os.Remove(backup)
+	}
+}
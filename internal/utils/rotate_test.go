@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "api.log")
+	file, err := OpenRotatingFile(path, RotationOptions{MaxSize: 10, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer file.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		// Rotated copies are named by millisecond
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "fourth\n" {
+		t.Errorf("Expected only the last line in the current file, got %q (%v)", data, err)
+	}
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Fatalf("Expected 2 rotated copies, got %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != "second\n" {
+		t.Errorf("Expected the oldest copy to be dropped, got %q", data)
+	}
+}
+
+func TestRotatingFileRotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	file, err := OpenRotatingFile(path, RotationOptions{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer file.Close()
+
+	file.Write([]byte("old\n"))
+	file.opened = file.opened.Add(-2 * time.Hour)
+	file.Write([]byte("new\n"))
+
+	if data, _ := os.ReadFile(path); string(data) != "new\n" {
+		t.Errorf("Expected a fresh file after MaxAge, got %q", data)
+	}
+	if backups, _ := filepath.Glob(path + ".*"); len(backups) != 1 {
+		t.Errorf("Expected one rotated copy, got %v", backups)
+	}
+}
+
+func TestServiceLogs(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "kportforward.log")
+	main, err := NewLoggerWithRotation(LevelInfo, mainPath, RotationOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create main logger: %v", err)
+	}
+	defer main.Close()
+
+	logs, err := NewServiceLogs(dir, RotationOptions{}, main)
+	if err != nil {
+		t.Fatalf("Failed to create service logs: %v", err)
+	}
+	logger := logs.Logger("staging/api")
+	if logs.Logger("staging/api") != logger {
+		t.Error("Expected the same logger for the same service")
+	}
+	logger.Info("Started port-forward")
+	logs.Close()
+
+	servicePath := logs.Path("staging/api")
+	if filepath.Base(servicePath) != "staging_api.log" {
+		t.Errorf("Expected a file name without slashes, got %s", servicePath)
+	}
+	for _, path := range []string{servicePath, mainPath} {
+		if data, _ := os.ReadFile(path); !strings.Contains(string(data), "INFO: Started port-forward") {
+			t.Errorf("Expected the line in %s, got %q", path, data)
+		}
+	}
+	if lines := main.RecentLines(); len(lines) != 1 {
+		t.Errorf("Expected the main logger to remember the line, got %v", lines)
+	}
+}
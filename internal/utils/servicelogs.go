@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// serviceLogNames makes service names, e.g. "profile/service", safe as file names
+var serviceLogNames = strings.NewReplacer("/", "_", "\\", "_", ":", "_")
+
+// ServiceLogs hands out a logger per service that writes to the service's own rotating
+// log file in a directory, in addition to the main log
+type ServiceLogs struct {
+	dir     string
+	options RotationOptions
+	main    *Logger
+
+	mutex   sync.Mutex
+	loggers map[string]*Logger
+}
+
+// NewServiceLogs creates dir if needed and returns service loggers writing there and to main
+func NewServiceLogs(dir string, options RotationOptions, main *Logger) (*ServiceLogs, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory %s: %w", dir, err)
+	}
+	return &ServiceLogs{
+		dir:     dir,
+		options: options,
+		main:    main,
+		loggers: make(map[string]*Logger),
+	}, nil
+}
+
+// Path returns the log file of a service
+func (s *ServiceLogs) Path(service string) string {
+	return filepath.Join(s.dir, serviceLogNames.Replace(service)+".log")
+}
+
+// Logger returns the logger of a service, opening its log file on first use. If the
+// file cannot be opened, the service logs to the main log only.
+func (s *ServiceLogs) Logger(service string) *Logger {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if logger, exists := s.loggers[service]; exists {
+		return logger
+	}
+	file, err := OpenRotatingFile(s.Path(service), s.options)
+	if err != nil {
+		s.main.Warn("Logging %s to the main log only: %v", service, err)
+		return s.main.ForService(service)
+	}
+	logger := s.main.ForService(service).WithOutput(file)
+	s.loggers[service] = logger
+	return logger
+}
+
+// Close closes the log files of all services
+func (s *ServiceLogs) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var firstErr error
+	for service, logger := range s.loggers {
+		if err := logger.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(s.loggers, service)
+	}
+	return firstErr
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"context"
+	"sort"
+)
+
+// ClusterService is a Kubernetes Service found by ListServices
+type ClusterService struct {
+	Name        string
+	Namespace   string
+	Annotations map[string]string
+	Ports       []ServicePort
+}
+
+// ServicePort is a port of a ClusterService
+type ServicePort struct {
+	Name     string
+	Port     int
+	Protocol string // TCP, UDP or SCTP
+}
+
+// ListServices returns the Services in namespace, only those matching selector unless
+// it is empty
+func ListServices(ctx context.Context, kubeContext, namespace, selector string) ([]ClusterService, error) {
+	args := []string{"services"}
+	if selector != "" {
+		args = append(args, "-l", selector)
+	}
+	list, err := kubectlGetJSON(ctx, kubeContext, namespace, args...)
+	if err != nil {
+		return nil, err
+	}
+	return clusterServices(list.Items), nil
+}
+
+// clusterServices converts Services listed by kubectl
+func clusterServices(items []kubeObject) []ClusterService {
+	services := make([]ClusterService, 0, len(items))
+	for _, item := range items {
+		service := ClusterService{
+			Name:        item.Metadata.Name,
+			Namespace:   item.Metadata.Namespace,
+			Annotations: item.Metadata.Annotations,
+		}
+		for _, port := range item.Spec.Ports {
+			service.Ports = append(service.Ports, ServicePort{Name: port.Name, Port: port.Port, Protocol: port.Protocol})
+		}
+		services = append(services, service)
+	}
+	return services
+}
+
+// ListNamespaces returns the names of the namespaces matching selector, or of all
+// namespaces if it is empty, sorted
+func ListNamespaces(ctx context.Context, kubeContext, selector string) ([]string, error) {
+	args := []string{"namespaces"}
+	if selector != "" {
+		args = append(args, "-l", selector)
+	}
+	list, err := kubectlGetJSON(ctx, kubeContext, "", args...)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.Metadata.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
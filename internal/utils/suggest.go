@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// notFoundPattern matches kubectl errors such as
+// `Error from server (NotFound): services "stagin-api" not found`
+var notFoundPattern = regexp.MustCompile(`\(NotFound\): ([a-z]+)(?:\.[a-z0-9.]+)? "([^"]+)" not found`)
+
+// ParseNotFound extracts the resource type and name from a kubectl NotFound error
+func ParseNotFound(message string) (resource, name string, ok bool) {
+	match := notFoundPattern.FindStringSubmatch(message)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
+// SuggestName lists existing resources of the given type and returns the closest match
+// to name, or "" if nothing is close enough. Namespaces are cluster-wide; everything
+// else is looked up in namespace.
+func SuggestName(ctx context.Context, namespace, resource, name string) (string, error) {
+	args := []string{"get", resource, "-o", "name", "--request-timeout=5s"}
+	if resource != "namespaces" {
+		args = append(args, "-n", namespace)
+	}
+
+	output, err := exec.CommandContext(ctx, "kubectl", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list %s: %w", resource, err)
+	}
+
+	var candidates []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		// -o name prints "service/foo"; only the name part is compared
+		if _, candidate, found := strings.Cut(line, "/"); found {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return ClosestMatch(name, candidates), nil
+}
+
+// ClosestMatch returns the candidate with the smallest edit distance to name, as long
+// as the distance is small enough to plausibly be a typo
+func ClosestMatch(name string, candidates []string) string {
+	maxDistance := len(name) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	best, bestDistance := "", maxDistance+1
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		if distance := Levenshtein(name, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// Levenshtein returns the edit distance between a and b
+func Levenshtein(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
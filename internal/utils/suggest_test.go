@@ -0,0 +1,48 @@
+package utils
+
+import "testing"
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"api", "", 3},
+		{"stagin-api", "staging-api", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if distance := Levenshtein(tt.a, tt.b); distance != tt.expected {
+			t.Errorf("Levenshtein(%q, %q) = %d, expected %d", tt.a, tt.b, distance, tt.expected)
+		}
+	}
+}
+
+func TestClosestMatch(t *testing.T) {
+	candidates := []string{"staging-api", "staging-web", "production-api"}
+
+	if match := ClosestMatch("stagin-api", candidates); match != "staging-api" {
+		t.Errorf("Expected staging-api, got %q", match)
+	}
+	if match := ClosestMatch("billing", candidates); match != "" {
+		t.Errorf("Expected no suggestion for unrelated name, got %q", match)
+	}
+}
+
+func TestParseNotFound(t *testing.T) {
+	resource, name, ok := ParseNotFound(`Error from server (NotFound): services "stagin-api" not found`)
+	if !ok || resource != "services" || name != "stagin-api" {
+		t.Errorf("Expected services/stagin-api, got %s/%s (%v)", resource, name, ok)
+	}
+
+	resource, _, ok = ParseNotFound(`Error from server (NotFound): deployments.apps "web" not found`)
+	if !ok || resource != "deployments" {
+		t.Errorf("Expected group suffix to be dropped, got %q (%v)", resource, ok)
+	}
+
+	if _, _, ok := ParseNotFound("error: lost connection to pod"); ok {
+		t.Error("Expected non-NotFound error not to match")
+	}
+}
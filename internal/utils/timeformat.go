@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Uptime formats
+const (
+	UptimeCompact = "compact" // 2h3m, 1d2h (default)
+	UptimeHours   = "hours"   // 26h3m, never rolls over into days
+	UptimeFull    = "full"    // 1 day 2 hours
+	UptimeISO     = "iso"     // P1DT2H3M (ISO 8601 duration)
+)
+
+// Timestamp formats
+const (
+	Clock24h     = "24h" // 2006-01-02 15:04:05 (default)
+	Clock12h     = "12h" // 2006-01-02 3:04:05 PM
+	TimestampISO = "iso" // RFC 3339
+)
+
+// IsValidUptimeFormat reports whether format is a known uptime format ("" is the default)
+func IsValidUptimeFormat(format string) bool {
+	switch format {
+	case "", UptimeCompact, UptimeHours, UptimeFull, UptimeISO:
+		return true
+	}
+	return false
+}
+
+// IsValidTimestampFormat reports whether format is a known timestamp format ("" is the default)
+func IsValidTimestampFormat(format string) bool {
+	switch format {
+	case "", Clock24h, Clock12h, TimestampISO:
+		return true
+	}
+	return false
+}
+
+// FormatUptimeAs formats a duration in the given uptime format
+func FormatUptimeAs(duration time.Duration, format string) string {
+	if duration < 0 {
+		duration = 0
+	}
+
+	days := int(duration.Hours()) / 24
+	hours := int(duration.Hours()) % 24
+	minutes := int(duration.Minutes()) % 60
+	seconds := int(duration.Seconds()) % 60
+
+	switch format {
+	case UptimeHours:
+		if duration < time.Hour {
+			return FormatUptime(duration)
+		}
+		return fmt.Sprintf("%dh%dm", int(duration.Hours()), minutes)
+
+	case UptimeFull:
+		var parts []string
+		for _, unit := range []struct {
+			value int
+			name  string
+		}{{days, "day"}, {hours, "hour"}, {minutes, "minute"}} {
+			if unit.value == 1 {
+				parts = append(parts, "1 "+unit.name)
+			} else if unit.value > 1 {
+				parts = append(parts, fmt.Sprintf("%d %ss", unit.value, unit.name))
+			}
+		}
+		if len(parts) == 0 {
+			return fmt.Sprintf("%d seconds", seconds)
+		}
+		// Two units are enough to read at a glance
+		if len(parts) > 2 {
+			parts = parts[:2]
+		}
+		return strings.Join(parts, " ")
+
+	case UptimeISO:
+		var b strings.Builder
+		b.WriteString("P")
+		if days > 0 {
+			fmt.Fprintf(&b, "%dD", days)
+		}
+		b.WriteString("T")
+		if hours > 0 {
+			fmt.Fprintf(&b, "%dH", hours)
+		}
+		if minutes > 0 {
+			fmt.Fprintf(&b, "%dM", minutes)
+		}
+		if hours == 0 && minutes == 0 {
+			fmt.Fprintf(&b, "%dS", seconds)
+		}
+		return b.String()
+
+	default:
+		return FormatUptime(duration)
+	}
+}
+
+// FormatTimestamp formats a point in time in the given timestamp format
+func FormatTimestamp(t time.Time, format string) string {
+	switch format {
+	case Clock12h:
+		return t.Format("2006-01-02 3:04:05 PM")
+	case TimestampISO:
+		return t.Format(time.RFC3339)
+	default:
+		return t.Format("2006-01-02 15:04:05")
+	}
+}
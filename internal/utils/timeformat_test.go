@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatUptimeAs(t *testing.T) {
+	duration := 26*time.Hour + 5*time.Minute + 7*time.Second
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"", "1d2h"},
+		{UptimeCompact, "1d2h"},
+		{UptimeHours, "26h5m"},
+		{UptimeFull, "1 day 2 hours"},
+		{UptimeISO, "P1DT2H5M"},
+	}
+
+	for _, tt := range tests {
+		if result := FormatUptimeAs(duration, tt.format); result != tt.expected {
+			t.Errorf("FormatUptimeAs(%v, %q) = %q, expected %q", duration, tt.format, result, tt.expected)
+		}
+	}
+
+	if result := FormatUptimeAs(42*time.Second, UptimeISO); result != "PT42S" {
+		t.Errorf("Expected PT42S for short uptimes, got %q", result)
+	}
+	if result := FormatUptimeAs(42*time.Second, UptimeFull); result != "42 seconds" {
+		t.Errorf("Expected seconds for short uptimes, got %q", result)
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	ts := time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC)
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"", "2024-03-05 14:07:09"},
+		{Clock12h, "2024-03-05 2:07:09 PM"},
+		{TimestampISO, "2024-03-05T14:07:09Z"},
+	}
+
+	for _, tt := range tests {
+		if result := FormatTimestamp(ts, tt.format); result != tt.expected {
+			t.Errorf("FormatTimestamp(%q) = %q, expected %q", tt.format, result, tt.expected)
+		}
+	}
+}